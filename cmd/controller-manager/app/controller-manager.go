@@ -24,7 +24,10 @@ import (
 	"net/http"
 	"os"
 	"strings"
+	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/server"
 
@@ -33,19 +36,24 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
+	corev1 "k8s.io/api/core/v1"
 	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/apimachinery/pkg/util/yaml"
 	utilfeature "k8s.io/apiserver/pkg/util/feature"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/component-base/logs"
+	"k8s.io/component-base/tracing"
+	tracingapi "k8s.io/component-base/tracing/api/v1"
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/kubefed/cmd/controller-manager/app/leaderelection"
 	"sigs.k8s.io/kubefed/cmd/controller-manager/app/options"
 	corev1b1 "sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
+	"sigs.k8s.io/kubefed/pkg/apis/core/v1beta1/defaults"
 	"sigs.k8s.io/kubefed/pkg/apis/core/v1beta1/validation"
 	genericclient "sigs.k8s.io/kubefed/pkg/client/generic"
 	"sigs.k8s.io/kubefed/pkg/controller/federatedtypeconfig"
@@ -60,6 +68,13 @@ import (
 const (
 	MetricsDefaultBindAddress = ":9090"
 	HealthzDefaultBindAddress = ":8080"
+
+	// tracingServiceName identifies the controller-manager in
+	// exported traces.
+	tracingServiceName = "kubefed-controller-manager"
+	// tracingShutdownTimeout bounds the time spent exporting pending
+	// spans on exit.
+	tracingShutdownTimeout = 5 * time.Second
 )
 
 var (
@@ -132,6 +147,22 @@ func Run(opts *options.Options, stopChan <-chan struct{}) error {
 		panic(err)
 	}
 
+	tracerProvider, err := newTracerProvider(opts)
+	if err != nil {
+		klog.Fatalf("Error configuring tracing: %v", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), tracingShutdownTimeout)
+		defer cancel()
+		if err := tracerProvider.Shutdown(ctx); err != nil {
+			klog.Errorf("Error shutting down tracing: %v", err)
+		}
+	}()
+	opts.Config.TracerProvider = tracerProvider
+	if opts.MaxTotalSyncReconciles > 0 {
+		opts.Config.SyncReconcileLimiter = utils.NewReconcileLimiter(opts.MaxTotalSyncReconciles)
+	}
+
 	go serveHealthz(healthzAddr)
 	go serveMetrics(opts, metricsAddr)
 	// Register kubefed custom metrics
@@ -196,6 +227,27 @@ func Run(opts *options.Options, stopChan <-chan struct{}) error {
 	return errors.New("lost lease")
 }
 
+// newTracerProvider returns a provider of tracers whose spans are
+// exported to the configured OTLP endpoint, or a no-op provider if no
+// endpoint is configured.
+func newTracerProvider(opts *options.Options) (tracing.TracerProvider, error) {
+	if len(opts.TracingEndpoint) == 0 {
+		return tracing.NewNoopTracerProvider(), nil
+	}
+	klog.Infof("Exporting traces to %q", opts.TracingEndpoint)
+	tracingConfig := &tracingapi.TracingConfiguration{
+		Endpoint:               &opts.TracingEndpoint,
+		SamplingRatePerMillion: &opts.TracingSamplingRatePerMillion,
+	}
+	if errs := tracingapi.ValidateTracingConfiguration(tracingConfig, nil, field.NewPath("tracing")); len(errs) > 0 {
+		return nil, errs.ToAggregate()
+	}
+	resourceOpts := []resource.Option{
+		resource.WithAttributes(attribute.String("service.name", tracingServiceName)),
+	}
+	return tracing.NewProvider(context.Background(), tracingConfig, nil, resourceOpts)
+}
+
 func startControllers(opts *options.Options, stopChan <-chan struct{}) {
 	if err := kubefedcluster.StartClusterController(opts.Config, opts.ClusterHealthCheckConfig, stopChan); err != nil {
 		klog.Fatalf("Error starting cluster controller: %v", err)
@@ -212,10 +264,15 @@ func startControllers(opts *options.Options, stopChan <-chan struct{}) {
 			opts.Config.RawResourceStatusCollection = true
 			klog.Info("Enabling RawResourceStatusCollection for all the enabled federated resources")
 		}
+		opts.Config.StatusFeedback = utilfeature.DefaultFeatureGate.Enabled(features.StatusFeedback)
+		opts.Config.MetadataMerge = utilfeature.DefaultFeatureGate.Enabled(features.MetadataMerge)
 
-		if err := federatedtypeconfig.StartController(opts.Config, stopChan); err != nil {
+		typeConfigController, err := federatedtypeconfig.StartController(opts.Config, stopChan)
+		if err != nil {
 			klog.Fatalf("Error starting federated type config controller: %v", err)
 		}
+		// Served alongside /healthz by the healthz server.
+		http.Handle(federatedtypeconfig.PropagationHealthPath, typeConfigController.PropagationHealthHandler())
 	}
 }
 
@@ -364,6 +421,7 @@ func setOptionsByKubeFedConfig(opts *options.Options) {
 	// file or already existing before the defaulting and validation webhook
 	// was registered e.g. prior to installation, upgrading, or due to issue
 	// https://github.com/kubernetes-sigs/kubefed/issues/983.
+	defaults.SetDefaultKubeFedConfig(fedConfig)
 	errs := validation.ValidateKubeFedConfig(fedConfig, nil)
 	if len(errs) != 0 {
 		klog.Fatalf("Error: invalid KubeFedConfig %q: %v", qualifedName, errs)
@@ -391,6 +449,26 @@ func setOptionsByKubeFedConfig(opts *options.Options) {
 	opts.Config.MaxConcurrentStatusReconciles = *spec.StatusController.MaxConcurrentReconciles
 
 	opts.Config.SkipAdoptingResources = *spec.SyncController.AdoptResources == corev1b1.AdoptResourcesDisabled
+	opts.Config.AdoptionPolicy = spec.SyncController.AdoptionPolicy
+	opts.Config.ApplyOrderGate = utils.NewApplyOrderGate(spec.SyncController.ApplyOrder)
+	opts.Config.ManagedLabels = spec.SyncController.ManagedLabels
+	opts.Config.ManagedAnnotations = spec.SyncController.ManagedAnnotations
+	opts.Config.CreatedNamespaceLabels = spec.SyncController.CreatedNamespaceLabels
+	opts.Config.CreatedNamespaceAnnotations = spec.SyncController.CreatedNamespaceAnnotations
+	opts.Config.NamespaceOptInLabel = spec.SyncController.NamespaceOptInLabel
+	opts.Config.PlacementAnnotation = spec.SyncController.PlacementAnnotation
+	opts.Config.DeleteEmptyNamespaces = spec.SyncController.DeleteEmptyNamespaces != nil &&
+		*spec.SyncController.DeleteEmptyNamespaces == corev1b1.DeleteEmptyNamespacesEnabled
+	opts.Config.PruneRemovedClusters = spec.SyncController.PruneRemovedClusters != nil &&
+		*spec.SyncController.PruneRemovedClusters == corev1b1.PruneRemovedClustersEnabled
+	if eviction := spec.SyncController.ClusterEviction; eviction != nil {
+		taintEffect := corev1.TaintEffectNoSchedule
+		if *eviction.RemoveResources == corev1b1.RemoveEvictedResourcesEnabled {
+			taintEffect = corev1.TaintEffectNoExecute
+		}
+		opts.Config.ClusterFailureTracker = utils.NewClusterFailureTracker(int(*eviction.FailureThresholdPercent),
+			int(*eviction.MinimumApplies), eviction.Window.Duration, int(*eviction.MaxEvictedClusters), taintEffect)
+	}
 
 	var featureGates = make(map[string]bool)
 	for _, v := range fedConfig.Spec.FeatureGates {
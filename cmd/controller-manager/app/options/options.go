@@ -35,6 +35,17 @@ type Options struct {
 	Scope                    apiextv1.ResourceScope
 	LeaderElection           *utils.LeaderElectionConfiguration
 	ClusterHealthCheckConfig *utils.ClusterHealthCheckConfig
+	// TracingEndpoint is the address of the OTLP collector to which
+	// the spans of reconciliations are exported. Spans are not
+	// recorded if empty.
+	TracingEndpoint string
+	// TracingSamplingRatePerMillion is the number of reconciliations
+	// per million whose spans are exported.
+	TracingSamplingRatePerMillion int32
+	// MaxTotalSyncReconciles is the maximum number of reconciles in
+	// flight across the sync controllers of all types. Only the
+	// reconciles of each type are bounded if 0.
+	MaxTotalSyncReconciles int
 }
 
 // AddFlags adds flags to fs and binds them to options.
@@ -51,6 +62,22 @@ func (o *Options) AddFlags(fs *pflag.FlagSet) {
 	// 绑定资源锁类型 (例如: "leases", "configmaps", "endpoints")
 	fs.StringVar((*string)(&o.LeaderElection.ResourceLock), "leader-elect-resource-lock", fedv1b1.LeasesResourceLock,
 		"The type of resource object that will be used to lock during leader election.")
+	// 成员集群客户端限流
+	fs.Float32Var(&o.Config.ClusterClientRateLimits.QPS, "cluster-client-qps", utils.DefaultClusterClientQPS,
+		"Maximum QPS to the api-server of a member cluster from each client of the cluster.")
+	fs.IntVar(&o.Config.ClusterClientRateLimits.Burst, "cluster-client-burst", utils.DefaultClusterClientBurst,
+		"Maximum burst for throttle to the api-server of a member cluster from each client of the cluster.")
+	// 同步合并窗口
+	fs.DurationVar(&o.Config.SyncDebounceWindow, "sync-debounce-window", 0,
+		"The time for which successive changes to a federated resource are coalesced into a single reconcile. Every change is reconciled without delay if 0.")
+	// 同步总并发
+	fs.IntVar(&o.MaxTotalSyncReconciles, "max-total-sync-reconciles", 0,
+		"The maximum number of reconciles in flight across the sync controllers of all types. Only the reconciles of each type are bounded if 0.")
+	// 追踪配置
+	fs.StringVar(&o.TracingEndpoint, "tracing-endpoint", "",
+		"The address of an OTLP gRPC collector to which the spans of reconciliations are exported. Spans are not recorded if empty.")
+	fs.Int32Var(&o.TracingSamplingRatePerMillion, "tracing-sampling-rate", 1000000,
+		"The number of reconciliations per million whose spans are exported.")
 	// 其他配置
 	if o.ClusterHealthCheckConfig != nil {
 		fs.DurationVar(&o.ClusterHealthCheckConfig.Period, "cluster-health-check-period", 10*time.Second, "How often to check the health of cluster.")
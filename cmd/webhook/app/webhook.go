@@ -27,14 +27,23 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
 	"github.com/spf13/cobra"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/component-base/logs"
 	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/manager/signals"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
+	"sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
+	genericscheme "sigs.k8s.io/kubefed/pkg/client/generic/scheme"
+	"sigs.k8s.io/kubefed/pkg/controller/utils"
+	"sigs.k8s.io/kubefed/pkg/controller/webhook/federatedresource"
 	"sigs.k8s.io/kubefed/pkg/controller/webhook/federatedtypeconfig"
 	"sigs.k8s.io/kubefed/pkg/controller/webhook/kubefedcluster"
 	"sigs.k8s.io/kubefed/pkg/controller/webhook/kubefedconfig"
@@ -42,10 +51,11 @@ import (
 )
 
 var (
-	certDir    string
-	kubeconfig string
-	masterURL  string
-	port       = 8443
+	certDir          string
+	kubeconfig       string
+	kubeFedNamespace = utils.DefaultKubeFedSystemNamespace
+	masterURL        string
+	port             = 8443
 )
 
 // NewWebhookCommand creates a *cobra.Command object with default parameters
@@ -73,6 +83,7 @@ func NewWebhookCommand(stopChan <-chan struct{}) *cobra.Command {
 	flags := cmd.Flags()
 	flags.StringVar(&kubeconfig, "kubeconfig", "", "Path to a kubeconfig. Only required if out-of-cluster.")
 	flags.StringVar(&masterURL, "master", "", "The address of the Kubernetes API server. Overrides any value in kubeconfig. Only required if out-of-cluster.")
+	flags.StringVar(&kubeFedNamespace, "kubefed-namespace", kubeFedNamespace, "The namespace the KubeFed control plane is deployed in.")
 	flags.StringVar(&certDir, "cert-dir", "", "The directory where the TLS certs are located.")
 	flags.IntVar(&port, "secure-port", port, "The port on which to serve HTTPS.")
 	flags.BoolVar(&verFlag, "version", false, "Prints the Version info of webhook.")
@@ -101,22 +112,47 @@ func Run(stopChan <-chan struct{}) error {
 		CertDir: certDir,
 	})
 
+	scheme := runtime.NewScheme()
+	utilruntime.Must(genericscheme.AddToScheme(scheme))
+	utilruntime.Must(apiextv1.AddToScheme(scheme))
+
 	mgr, err := manager.New(config, manager.Options{
+		Scheme:        scheme,
 		WebhookServer: webhookServer,
+		Cache: cache.Options{
+			// The federated resource webhook only needs the type
+			// configs of the KubeFed control plane.
+			ByObject: map[runtimeclient.Object]cache.ByObject{
+				&v1beta1.FederatedTypeConfig{}: {
+					Namespaces: map[string]cache.Config{kubeFedNamespace: {}},
+				},
+			},
+		},
 	})
 	if err != nil {
 		klog.Fatalf("error setting up webhook manager: %s", err)
 	}
 	hookServer := mgr.GetWebhookServer()
 
+	// Start the informers up front so that the first admission request
+	// does not wait for them to be created and synced.
+	ctx := signals.SetupSignalHandler()
+	for _, obj := range []runtimeclient.Object{&v1beta1.FederatedTypeConfig{}, &apiextv1.CustomResourceDefinition{}} {
+		if _, err := mgr.GetCache().GetInformer(ctx, obj); err != nil {
+			klog.Fatalf("error setting up webhook informer: %s", err)
+		}
+	}
+	federatedResourceHook := federatedresource.NewAdmissionHook(mgr.GetCache(), kubeFedNamespace)
+
 	hookServer.Register("/validate-federatedtypeconfigs", &webhook.Admission{Handler: &federatedtypeconfig.AdmissionHook{}})
 	hookServer.Register("/validate-kubefedcluster", &webhook.Admission{Handler: &kubefedcluster.AdmissionHook{}})
 	hookServer.Register("/validate-kubefedconfig", &webhook.Admission{Handler: &kubefedconfig.Validator{}})
 	hookServer.Register("/default-kubefedconfig", &webhook.Admission{Handler: &kubefedconfig.KubeFedConfigDefaulter{}})
+	hookServer.Register("/validate-federatedresource", &webhook.Admission{Handler: federatedResourceHook})
 
 	hookServer.WebhookMux().Handle("/readyz/", http.StripPrefix("/readyz/", &healthz.Handler{}))
 
-	if err := mgr.Start(signals.SetupSignalHandler()); err != nil {
+	if err := mgr.Start(ctx); err != nil {
 		klog.Fatalf("unable to run manager: %s", err)
 	}
 
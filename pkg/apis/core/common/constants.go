@@ -26,6 +26,9 @@ const (
 	ClusterOffline ClusterConditionType = "Offline"
 	// ClusterConfigMalformed means the cluster's configuration may be malformed.
 	ClusterConfigMalformed ClusterConditionType = "ConfigMalformed"
+	// ClusterAuthenticationFailed means the cluster rejected the
+	// credentials of its secret.
+	ClusterAuthenticationFailed ClusterConditionType = "AuthenticationFailed"
 )
 
 const (
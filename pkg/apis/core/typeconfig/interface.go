@@ -18,6 +18,8 @@ package typeconfig
 
 import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
 )
 
 // Interface defines how to interact with a FederatedTypeConfig
@@ -30,5 +32,19 @@ type Interface interface {
 	GetStatusType() *metav1.APIResource
 	GetStatusEnabled() bool
 	GetFederatedNamespaced() bool
+	GetTargetNameTemplate() string
+	GetIncludedFields() []string
+	GetServerManagedMetadata() v1beta1.ServerManagedMetadata
+	GetTransformationWebhook() *v1beta1.TransformationWebhook
+	GetPropagationWebhook() *v1beta1.PropagationWebhook
+	GetPruneUnknownFields() bool
+	GetPropagationWindow() *v1beta1.PropagationWindow
+	GetStatusAggregations() []v1beta1.StatusAggregation
+	GetObserveOnly() bool
+	GetQuota() *v1beta1.PlacementQuota
+	GetRemovalStrategy() *v1beta1.RemovalStrategy
+	GetDeletionGracePeriod() *metav1.Duration
+	GetStatusCollectionInterval() *metav1.Duration
+	GetDriftDetectionInterval() *metav1.Duration
 	IsNamespace() bool
 }
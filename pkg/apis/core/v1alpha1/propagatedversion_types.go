@@ -33,6 +33,13 @@ type PropagatedVersionStatus struct {
 	// The last versions produced in each cluster for this resource.
 	// +optional
 	ClusterVersions []ClusterObjectVersion `json:"clusterVersions,omitempty"`
+	// Whether the overrides for a cluster could change without a
+	// change to the override version when the versions were recorded,
+	// e.g. because an override was cluster-local. Otherwise the
+	// versions remain valid as long as the override version is
+	// unchanged.
+	// +optional
+	UnversionedOverrides bool `json:"unversionedOverrides,omitempty"`
 }
 
 type ClusterObjectVersion struct {
@@ -41,6 +48,12 @@ type ClusterObjectVersion struct {
 	// The last version produced for the resource by a KubeFed
 	// operation.
 	Version string `json:"version"`
+	// The version of the overrides for the cluster with which the
+	// version was produced. A version recorded with an override
+	// version remains valid when only the overrides of other clusters
+	// change.
+	// +optional
+	OverrideVersion string `json:"overrideVersion,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -43,8 +43,49 @@ const (
 
 	DefaultSyncControllerMaxConcurrentReconciles   = 1
 	DefaultStatusControllerMaxConcurrentReconciles = 1
+
+	DefaultClusterEvictionFailureThresholdPercent = 50
+	DefaultClusterEvictionMinimumApplies          = 10
+	DefaultClusterEvictionWindow                  = 5 * time.Minute
+	DefaultClusterEvictionMaxEvictedClusters      = 1
 )
 
+// DefaultSyncControllerApplyOrder is the order in which resources are
+// created in a member cluster by default. It is derived from the
+// install order of Helm so that resources are created after the
+// resources they are likely to depend on.
+var DefaultSyncControllerApplyOrder = []string{
+	"Namespace",
+	"NetworkPolicy",
+	"ResourceQuota",
+	"LimitRange",
+	"PodDisruptionBudget",
+	"ServiceAccount",
+	"Secret",
+	"ConfigMap",
+	"StorageClass",
+	"PersistentVolume",
+	"PersistentVolumeClaim",
+	"CustomResourceDefinition",
+	"ClusterRole",
+	"ClusterRoleBinding",
+	"Role",
+	"RoleBinding",
+	"Service",
+	"DaemonSet",
+	"Pod",
+	"ReplicationController",
+	"ReplicaSet",
+	"Deployment",
+	"HorizontalPodAutoscaler",
+	"StatefulSet",
+	"Job",
+	"CronJob",
+	"IngressClass",
+	"Ingress",
+	"APIService",
+}
+
 func SetDefaultKubeFedConfig(fedConfig *v1beta1.KubeFedConfig) {
 	spec := &fedConfig.Spec
 
@@ -99,6 +140,31 @@ func SetDefaultKubeFedConfig(fedConfig *v1beta1.KubeFedConfig) {
 		*spec.SyncController.AdoptResources = v1beta1.AdoptResourcesEnabled
 	}
 
+	if spec.SyncController.DeleteEmptyNamespaces == nil {
+		spec.SyncController.DeleteEmptyNamespaces = new(v1beta1.EmptyNamespaceDeletion)
+		*spec.SyncController.DeleteEmptyNamespaces = v1beta1.DeleteEmptyNamespacesDisabled
+	}
+
+	if spec.SyncController.PruneRemovedClusters == nil {
+		spec.SyncController.PruneRemovedClusters = new(v1beta1.RemovedClusterPruning)
+		*spec.SyncController.PruneRemovedClusters = v1beta1.PruneRemovedClustersDisabled
+	}
+
+	if eviction := spec.SyncController.ClusterEviction; eviction != nil {
+		setInt64(&eviction.FailureThresholdPercent, DefaultClusterEvictionFailureThresholdPercent)
+		setInt64(&eviction.MinimumApplies, DefaultClusterEvictionMinimumApplies)
+		setDuration(&eviction.Window, DefaultClusterEvictionWindow)
+		setInt64(&eviction.MaxEvictedClusters, DefaultClusterEvictionMaxEvictedClusters)
+		if eviction.RemoveResources == nil {
+			eviction.RemoveResources = new(v1beta1.EvictedResourceRemoval)
+			*eviction.RemoveResources = v1beta1.RemoveEvictedResourcesDisabled
+		}
+	}
+
+	if spec.SyncController.ApplyOrder == nil {
+		spec.SyncController.ApplyOrder = append([]string{}, DefaultSyncControllerApplyOrder...)
+	}
+
 	if spec.StatusController == nil {
 		spec.StatusController = &v1beta1.StatusControllerConfig{}
 	}
@@ -139,6 +139,39 @@ func TestSetDefaultKubeFedConfig(t *testing.T) {
 	SetDefaultKubeFedConfig(modifiedAdoptResourcesKFC)
 	successCases["spec.syncController.adoptResources is preserved"] = KubeFedConfigComparison{adoptResourcesKFC, modifiedAdoptResourcesKFC}
 
+	deleteEmptyNamespacesKFC := defaultKubeFedConfig()
+	*deleteEmptyNamespacesKFC.Spec.SyncController.DeleteEmptyNamespaces = v1beta1.DeleteEmptyNamespacesEnabled
+	modifiedDeleteEmptyNamespacesKFC := deleteEmptyNamespacesKFC.DeepCopyObject().(*v1beta1.KubeFedConfig)
+	SetDefaultKubeFedConfig(modifiedDeleteEmptyNamespacesKFC)
+	successCases["spec.syncController.deleteEmptyNamespaces is preserved"] = KubeFedConfigComparison{deleteEmptyNamespacesKFC, modifiedDeleteEmptyNamespacesKFC}
+
+	pruneRemovedClustersKFC := defaultKubeFedConfig()
+	*pruneRemovedClustersKFC.Spec.SyncController.PruneRemovedClusters = v1beta1.PruneRemovedClustersEnabled
+	modifiedPruneRemovedClustersKFC := pruneRemovedClustersKFC.DeepCopyObject().(*v1beta1.KubeFedConfig)
+	SetDefaultKubeFedConfig(modifiedPruneRemovedClustersKFC)
+	successCases["spec.syncController.pruneRemovedClusters is preserved"] = KubeFedConfigComparison{pruneRemovedClustersKFC, modifiedPruneRemovedClustersKFC}
+
+	clusterEvictionKFC := defaultKubeFedConfig()
+	clusterEvictionKFC.Spec.SyncController.ClusterEviction = &v1beta1.ClusterEvictionConfig{}
+	SetDefaultKubeFedConfig(clusterEvictionKFC)
+	if eviction := clusterEvictionKFC.Spec.SyncController.ClusterEviction; *eviction.FailureThresholdPercent != DefaultClusterEvictionFailureThresholdPercent ||
+		*eviction.MinimumApplies != DefaultClusterEvictionMinimumApplies || eviction.Window.Duration != DefaultClusterEvictionWindow ||
+		*eviction.MaxEvictedClusters != DefaultClusterEvictionMaxEvictedClusters ||
+		*eviction.RemoveResources != v1beta1.RemoveEvictedResourcesDisabled {
+		t.Errorf("Expected spec.syncController.clusterEviction to be defaulted, got %+v", *eviction)
+	}
+	*clusterEvictionKFC.Spec.SyncController.ClusterEviction.FailureThresholdPercent = DefaultClusterEvictionFailureThresholdPercent + 20
+	*clusterEvictionKFC.Spec.SyncController.ClusterEviction.RemoveResources = v1beta1.RemoveEvictedResourcesEnabled
+	modifiedClusterEvictionKFC := clusterEvictionKFC.DeepCopyObject().(*v1beta1.KubeFedConfig)
+	SetDefaultKubeFedConfig(modifiedClusterEvictionKFC)
+	successCases["spec.syncController.clusterEviction is preserved"] = KubeFedConfigComparison{clusterEvictionKFC, modifiedClusterEvictionKFC}
+
+	applyOrderKFC := defaultKubeFedConfig()
+	applyOrderKFC.Spec.SyncController.ApplyOrder = []string{"ConfigMap", "Namespace"}
+	modifiedApplyOrderKFC := applyOrderKFC.DeepCopyObject().(*v1beta1.KubeFedConfig)
+	SetDefaultKubeFedConfig(modifiedApplyOrderKFC)
+	successCases["spec.syncController.applyOrder is preserved"] = KubeFedConfigComparison{applyOrderKFC, modifiedApplyOrderKFC}
+
 	// StatusController
 	statusControllerMaxConcurrentReconcilesKFC := defaultKubeFedConfig()
 	statusControllerMaxConcurrentReconciles := int64(DefaultStatusControllerMaxConcurrentReconciles + 3)
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"strings"
 
+	apiv1 "k8s.io/api/core/v1"
 	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
@@ -47,6 +48,231 @@ type FederatedTypeConfigSpec struct {
 	// Whether or not Status object should be populated.
 	// +optional
 	StatusCollection *StatusCollectionMode `json:"statusCollection,omitempty"`
+	// The interval at which the status of resources in member clusters
+	// is collected. Changes of the resources are collected at most once
+	// per interval, and the status is collected again at the interval
+	// even if the resources do not change. Must be at least one second.
+	// If not provided, status is collected whenever the resources change.
+	// +optional
+	StatusCollectionInterval *metav1.Duration `json:"statusCollectionInterval,omitempty"`
+	// The interval at which the managed resources in member clusters
+	// are compared with the content of their federated resources to
+	// detect out-of-band modifications, which are reported by the
+	// DriftDetected condition of each cluster in the status of the
+	// federated resources. Must be at least one second. If not
+	// provided, drift is not detected.
+	// +optional
+	DriftDetectionInterval *metav1.Duration `json:"driftDetectionInterval,omitempty"`
+	// A go template evaluated against the labels of a federated resource
+	// to compute the name of the resources managed in member clusters
+	// (e.g. `{{ index . "tenant" }}-config`). If not provided, managed
+	// resources have the same name as their federated resource.
+	// +optional
+	TargetNameTemplate string `json:"targetNameTemplate,omitempty"`
+	// The dot-separated paths of the fields (e.g. `spec.replicas`) of
+	// existing resources in member clusters that should be managed by
+	// KubeFed. If provided, updates to existing resources only modify
+	// these fields and leave the remaining fields to other controllers.
+	// Resources are created from the full template.
+	// +optional
+	IncludedFields []string `json:"includedFields,omitempty"`
+	// Metadata of resources in member clusters that is populated by
+	// the API server or other controllers of the clusters rather than
+	// propagated by KubeFed (e.g. defaulted finalizers). It is
+	// preserved when resources are updated and is not reported as
+	// drift. The `kubernetes.io/metadata.name` label of namespaces is
+	// always considered server-managed.
+	// +optional
+	ServerManagedMetadata *ServerManagedMetadata `json:"serverManagedMetadata,omitempty"`
+	// A webhook that transforms the object computed for each member
+	// cluster, after overrides have been applied, before the object is
+	// applied to the cluster. Propagation to a cluster fails if the
+	// webhook cannot be called or rejects the object.
+	// +optional
+	TransformationWebhook *TransformationWebhook `json:"transformationWebhook,omitempty"`
+	// A webhook that is notified when propagation of a federated
+	// resource of this type completes for a generation. If not
+	// provided, no notifications are sent.
+	// +optional
+	PropagationWebhook *PropagationWebhook `json:"propagationWebhook,omitempty"`
+	// Whether fields of the objects propagated to member clusters that
+	// are not described by the OpenAPI schema of the target CRD should
+	// be removed before the objects are applied. This keeps
+	// propagation working when a new version of the CRD drops a field
+	// still present in federated resources. Only supported for target
+	// types defined by a CRD. Defaults to false.
+	// +optional
+	PruneUnknownFields bool `json:"pruneUnknownFields,omitempty"`
+	// A recurring window during which updates of existing resources
+	// in member clusters are propagated. Outside of the window such
+	// updates are deferred until the window next opens, while
+	// creation and removal of resources are propagated immediately.
+	// If not provided, updates are propagated immediately.
+	// +optional
+	PropagationWindow *PropagationWindow `json:"propagationWindow,omitempty"`
+	// Feature gates that are enabled or disabled for the controllers
+	// of this type only, overriding the feature gates of the control
+	// plane. Only the RawResourceStatusCollection, StatusFeedback and
+	// MetadataMerge gates may be overridden.
+	// +optional
+	FeatureGates []FeatureGatesConfig `json:"featureGates,omitempty"`
+	// Numeric fields of the status of target resources to aggregate
+	// across member clusters into `status.aggregatedMetrics` of
+	// federated resources. Only effective when status collection is
+	// enabled for the type and the RawResourceStatusCollection feature
+	// is enabled.
+	// +optional
+	StatusAggregations []StatusAggregation `json:"statusAggregations,omitempty"`
+	// Whether resources of the target type in member clusters are only
+	// observed. If true, placement is computed and the status of the
+	// resources in placed clusters is collected, but resources in member
+	// clusters are never created, updated or deleted. Defaults to false.
+	// +optional
+	ObserveOnly bool `json:"observeOnly,omitempty"`
+	// Limits on the number of federated resources of this type that
+	// may be propagated to each member cluster. Placement of a
+	// federated resource to a cluster whose quota is exhausted is
+	// blocked until the quota allows it. If not provided, the number
+	// of resources is not limited.
+	// +optional
+	Quota *PlacementQuota `json:"quota,omitempty"`
+	// How resources in member clusters are drained before they are
+	// deleted because their cluster is no longer selected by
+	// placement. A federated resource may replace it with its own
+	// `spec.removalStrategy`. If not provided, resources are deleted
+	// as soon as their cluster is no longer selected.
+	// +optional
+	RemovalStrategy *RemovalStrategy `json:"removalStrategy,omitempty"`
+	// How long resources in member clusters are kept after their
+	// federated resource is deleted. Recreating the federated resource
+	// within this period cancels their deletion. A federated resource
+	// may replace it with the `kubefed.io/deletion-grace-period`
+	// annotation. If not provided, resources are deleted as soon as
+	// their federated resource is deleted.
+	// +optional
+	DeletionGracePeriod *metav1.Duration `json:"deletionGracePeriod,omitempty"`
+}
+
+// RemovalStrategy defines how a resource in a member cluster is
+// drained before it is deleted on placement shrink. The patch is
+// applied first, and the resource is deleted once the drain duration
+// has elapsed since the patch was applied.
+type RemovalStrategy struct {
+	// A JSON merge patch applied to the resource before it is deleted
+	// (e.g. `{"spec": {"replicas": 0}}`). If not provided, the
+	// resource is left unchanged while it drains.
+	// +optional
+	// +kubebuilder:pruning:PreserveUnknownFields
+	PreDeletePatch *apiextv1.JSON `json:"preDeletePatch,omitempty"`
+	// How long to wait after the patch has been applied before the
+	// resource is deleted. Defaults to 0.
+	// +optional
+	DrainDuration metav1.Duration `json:"drainDuration,omitempty"`
+}
+
+// ServerManagedMetadata identifies the metadata of resources in member
+// clusters that is populated in the clusters rather than by KubeFed.
+type ServerManagedMetadata struct {
+	// The keys of the server-managed labels.
+	// +optional
+	Labels []string `json:"labels,omitempty"`
+	// The keys of the server-managed annotations.
+	// +optional
+	Annotations []string `json:"annotations,omitempty"`
+	// The server-managed finalizers.
+	// +optional
+	Finalizers []string `json:"finalizers,omitempty"`
+}
+
+// PlacementQuota limits the number of federated resources of a type
+// that are propagated to each member cluster. Resources are admitted
+// in the order in which they were created, and resources already
+// present in a cluster are never removed to satisfy a quota.
+type PlacementQuota struct {
+	// The maximum number of resources of the type in each member
+	// cluster.
+	// +optional
+	MaxPerCluster *int32 `json:"maxPerCluster,omitempty"`
+	// The maximum number of resources of the type in each namespace
+	// of each member cluster. Ignored for cluster-scoped types.
+	// +optional
+	MaxPerNamespace *int32 `json:"maxPerNamespace,omitempty"`
+}
+
+// AggregationFunction defines how the values of a field reported by
+// member clusters are combined.
+type AggregationFunction string
+
+const (
+	AggregationSum AggregationFunction = "Sum"
+	AggregationMin AggregationFunction = "Min"
+	AggregationMax AggregationFunction = "Max"
+)
+
+// StatusAggregation defines the aggregation of a numeric field of the
+// status of target resources across member clusters.
+type StatusAggregation struct {
+	// The name of the aggregate in `status.aggregatedMetrics` of
+	// federated resources (e.g. totalReadyReplicas).
+	Name string `json:"name"`
+	// The dot-separated path of the field relative to the status of
+	// target resources (e.g. readyReplicas). The field is considered
+	// to be zero for a cluster whose reported status omits it.
+	Field string `json:"field"`
+	// The function combining the values reported by member clusters.
+	Function AggregationFunction `json:"function"`
+}
+
+// TransformationWebhook defines how to call a webhook that transforms
+// the objects propagated to member clusters.
+type TransformationWebhook struct {
+	// The URL of the webhook in the form `https://host[:port]/path`.
+	URL string `json:"url"`
+	// A PEM encoded CA bundle used to validate the serving certificate
+	// of the webhook. If not provided, the system trust roots are used.
+	// +optional
+	CABundle []byte `json:"caBundle,omitempty"`
+	// The number of seconds to wait for the webhook to respond.
+	// Defaults to 10.
+	// +optional
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
+}
+
+// PropagationWebhook defines how to notify a webhook that propagation
+// of a federated resource has completed.
+type PropagationWebhook struct {
+	// The URL of the webhook in the form `https://host[:port]/path`.
+	URL string `json:"url"`
+	// A PEM encoded CA bundle used to validate the serving certificate
+	// of the webhook. If not provided, the system trust roots are used.
+	// +optional
+	CABundle []byte `json:"caBundle,omitempty"`
+	// The number of seconds to wait for the webhook to respond to each
+	// attempt. Defaults to 10.
+	// +optional
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
+	// A secret in the KubeFed system namespace whose `secret` key
+	// holds the shared secret used to sign notifications with
+	// HMAC-SHA256. If not provided, notifications are not signed.
+	// +optional
+	SigningSecretRef *LocalSecretReference `json:"signingSecretRef,omitempty"`
+	// The number of times a notification is retried with exponential
+	// backoff if the webhook cannot be reached or responds with a
+	// server error. Defaults to 3.
+	// +optional
+	Retries *int32 `json:"retries,omitempty"`
+}
+
+// PropagationWindow defines a recurring window of time in UTC.
+type PropagationWindow struct {
+	// The days of the week on which the window opens, as three-letter
+	// abbreviations (e.g. `Sat`). Defaults to every day.
+	// +optional
+	Days []string `json:"days,omitempty"`
+	// The time of day at which the window opens in the format `HH:MM`.
+	Start string `json:"start"`
+	// How long the window remains open. Must not exceed 7 days.
+	Duration metav1.Duration `json:"duration"`
 }
 
 // APIResource defines how to configure the dynamic client for an API resource.
@@ -95,6 +321,34 @@ const (
 	ControllerStatusNotRunning ControllerStatus = "NotRunning"
 )
 
+// TypeConfigConditionType is the type of a condition of a
+// FederatedTypeConfig.
+type TypeConfigConditionType string
+
+const (
+	// TargetTypeServed indicates whether the target type is served at
+	// its configured version by all ready member clusters.
+	TargetTypeServed TypeConfigConditionType = "TargetTypeServed"
+)
+
+// TypeConfigCondition describes the current state of a
+// FederatedTypeConfig.
+type TypeConfigCondition struct {
+	// Type of the condition.
+	Type TypeConfigConditionType `json:"type"`
+	// Status of the condition, one of True, False, Unknown.
+	Status apiv1.ConditionStatus `json:"status"`
+	// Last time the condition transitioned from one status to another.
+	// +optional
+	LastTransitionTime *metav1.Time `json:"lastTransitionTime,omitempty"`
+	// (brief) reason for the condition's last transition.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// Human-readable message indicating details about the condition.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
 // FederatedTypeConfigStatus defines the observed state of FederatedTypeConfig
 type FederatedTypeConfigStatus struct {
 	// ObservedGeneration is the generation as observed by the controller consuming the FederatedTypeConfig.
@@ -104,6 +358,14 @@ type FederatedTypeConfigStatus struct {
 	// StatusController tracks the status of the status controller.
 	// +optional
 	StatusController *ControllerStatus `json:"statusController,omitempty"`
+	// PropagationPaused indicates that the sync controller is running
+	// but that propagation to member clusters has been paused for the
+	// control plane.
+	// +optional
+	PropagationPaused bool `json:"propagationPaused,omitempty"`
+	// Conditions are the current conditions of the type.
+	// +optional
+	Conditions []TypeConfigCondition `json:"conditions,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -240,6 +502,72 @@ func (f *FederatedTypeConfig) GetFederatedNamespaced() bool {
 	return f.GetNamespaced()
 }
 
+func (f *FederatedTypeConfig) GetTargetNameTemplate() string {
+	return f.Spec.TargetNameTemplate
+}
+
+func (f *FederatedTypeConfig) GetIncludedFields() []string {
+	return f.Spec.IncludedFields
+}
+
+// GetServerManagedMetadata returns the metadata of target resources
+// that is populated in member clusters rather than by KubeFed,
+// including the label that the API server adds to every namespace.
+func (f *FederatedTypeConfig) GetServerManagedMetadata() ServerManagedMetadata {
+	var metadata ServerManagedMetadata
+	if f.Spec.ServerManagedMetadata != nil {
+		f.Spec.ServerManagedMetadata.DeepCopyInto(&metadata)
+	}
+	if f.IsNamespace() {
+		metadata.Labels = append(metadata.Labels, apiv1.LabelMetadataName)
+	}
+	return metadata
+}
+
+func (f *FederatedTypeConfig) GetTransformationWebhook() *TransformationWebhook {
+	return f.Spec.TransformationWebhook
+}
+
+func (f *FederatedTypeConfig) GetPropagationWebhook() *PropagationWebhook {
+	return f.Spec.PropagationWebhook
+}
+
+func (f *FederatedTypeConfig) GetPruneUnknownFields() bool {
+	return f.Spec.PruneUnknownFields
+}
+
+func (f *FederatedTypeConfig) GetPropagationWindow() *PropagationWindow {
+	return f.Spec.PropagationWindow
+}
+
+func (f *FederatedTypeConfig) GetStatusAggregations() []StatusAggregation {
+	return f.Spec.StatusAggregations
+}
+
+func (f *FederatedTypeConfig) GetObserveOnly() bool {
+	return f.Spec.ObserveOnly
+}
+
+func (f *FederatedTypeConfig) GetQuota() *PlacementQuota {
+	return f.Spec.Quota
+}
+
+func (f *FederatedTypeConfig) GetRemovalStrategy() *RemovalStrategy {
+	return f.Spec.RemovalStrategy
+}
+
+func (f *FederatedTypeConfig) GetDeletionGracePeriod() *metav1.Duration {
+	return f.Spec.DeletionGracePeriod
+}
+
+func (f *FederatedTypeConfig) GetStatusCollectionInterval() *metav1.Duration {
+	return f.Spec.StatusCollectionInterval
+}
+
+func (f *FederatedTypeConfig) GetDriftDetectionInterval() *metav1.Duration {
+	return f.Spec.DriftDetectionInterval
+}
+
 func (f *FederatedTypeConfig) IsNamespace() bool {
 	return f.Name == common.NamespaceName
 }
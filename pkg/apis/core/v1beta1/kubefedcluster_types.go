@@ -55,6 +55,19 @@ type KubeFedClusterSpec struct {
 	// ProxyURL allows to set proxy URL for the cluster.
 	// +optional
 	ProxyURL string `json:"proxyURL"`
+
+	// Taints prevent federated resources from being placed in the
+	// cluster unless their placement tolerates them. A NoSchedule
+	// taint only prevents new placement, while a NoExecute taint also
+	// removes existing placement.
+	// +optional
+	Taints []apiv1.Taint `json:"taints,omitempty"`
+
+	// Parameters are values specific to the member cluster that the
+	// templated overrides of federated resources can reference as
+	// {{ .Params.<key> }}.
+	// +optional
+	Parameters map[string]string `json:"parameters,omitempty"`
 }
 
 // LocalSecretReference is a reference to a secret within the enclosing
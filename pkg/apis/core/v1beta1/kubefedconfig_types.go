@@ -120,6 +120,65 @@ type SyncControllerConfig struct {
 	// "Enabled".
 	// +optional
 	AdoptResources *ResourceAdoption `json:"adoptResources,omitempty"`
+	// Restricts which pre-existing resources in member clusters are
+	// adopted when adoption is enabled. All pre-existing resources are
+	// adopted if not set.
+	// +optional
+	AdoptionPolicy *ResourceAdoptionPolicy `json:"adoptionPolicy,omitempty"`
+	// The order in which resources are created in a member cluster, by
+	// kind. Resources of kinds that are not listed are created after
+	// those that are. Defaults to an order similar to the install order
+	// of Helm.
+	// +optional
+	ApplyOrder []string `json:"applyOrder,omitempty"`
+	// Labels added to every resource managed in a member cluster in
+	// addition to the managed label.
+	// +optional
+	ManagedLabels map[string]string `json:"managedLabels,omitempty"`
+	// Annotations added to every resource managed in a member cluster.
+	// +optional
+	ManagedAnnotations map[string]string `json:"managedAnnotations,omitempty"`
+	// Labels added to a namespace that KubeFed creates in a member
+	// cluster, in addition to those propagated from its
+	// FederatedNamespace. Namespaces that KubeFed adopts are not
+	// labeled.
+	// +optional
+	CreatedNamespaceLabels map[string]string `json:"createdNamespaceLabels,omitempty"`
+	// Annotations added to a namespace that KubeFed creates in a
+	// member cluster, in addition to those propagated from its
+	// FederatedNamespace. Namespaces that KubeFed adopts are not
+	// annotated.
+	// +optional
+	CreatedNamespaceAnnotations map[string]string `json:"createdNamespaceAnnotations,omitempty"`
+	// The key of a label that a namespace of a member cluster must
+	// have with the value "true" for resources to be created in or
+	// adopted from it. Resources can be created in any namespace if
+	// not set.
+	// +optional
+	NamespaceOptInLabel string `json:"namespaceOptInLabel,omitempty"`
+	// The key of an annotation added to every resource managed in a
+	// member cluster that lists the clusters its federated resource is
+	// propagated to, separated by commas. Not added if not set.
+	// +optional
+	PlacementAnnotation string `json:"placementAnnotation,omitempty"`
+	// Whether to delete a namespace that KubeFed created in a member
+	// cluster once the last resource managed in it has been removed,
+	// provided that the namespace is no longer propagated by a
+	// FederatedNamespace. Defaults to "Disabled".
+	// +optional
+	DeleteEmptyNamespaces *EmptyNamespaceDeletion `json:"deleteEmptyNamespaces,omitempty"`
+	// Whether to remove a member cluster whose KubeFedCluster is
+	// deleted from the clusters listed in the placement of federated
+	// resources. A placement that lists a removed cluster is otherwise
+	// only reported with an event. Defaults to "Disabled".
+	// +optional
+	PruneRemovedClusters *RemovedClusterPruning `json:"pruneRemovedClusters,omitempty"`
+	// Taints a member cluster once the applies of resources to it
+	// fail at a rate above a threshold, so that a failing cluster
+	// does not hold up the propagation of federated resources.
+	// Clusters are not tainted if not set.
+	// +optional
+	ClusterEviction *ClusterEvictionConfig `json:"clusterEviction,omitempty"`
 }
 
 type ResourceAdoption string
@@ -129,6 +188,74 @@ const (
 	AdoptResourcesDisabled ResourceAdoption = "Disabled"
 )
 
+type EmptyNamespaceDeletion string
+
+const (
+	DeleteEmptyNamespacesEnabled  EmptyNamespaceDeletion = "Enabled"
+	DeleteEmptyNamespacesDisabled EmptyNamespaceDeletion = "Disabled"
+)
+
+type RemovedClusterPruning string
+
+const (
+	PruneRemovedClustersEnabled  RemovedClusterPruning = "Enabled"
+	PruneRemovedClustersDisabled RemovedClusterPruning = "Disabled"
+)
+
+type EvictedResourceRemoval string
+
+const (
+	RemoveEvictedResourcesEnabled  EvictedResourceRemoval = "Enabled"
+	RemoveEvictedResourcesDisabled EvictedResourceRemoval = "Disabled"
+)
+
+// ClusterEvictionConfig defines when a member cluster is tainted for
+// failing applies. Applies that the cluster rejects as invalid or
+// forbidden are not counted since they indicate a problem with the
+// resource rather than with the cluster.
+type ClusterEvictionConfig struct {
+	// The percentage of the applies to a cluster within the window
+	// that must fail for the cluster to be tainted. Defaults to 50.
+	// +optional
+	FailureThresholdPercent *int64 `json:"failureThresholdPercent,omitempty"`
+	// The minimum number of applies to a cluster within the window
+	// for its failure rate to be evaluated. Defaults to 10.
+	// +optional
+	MinimumApplies *int64 `json:"minimumApplies,omitempty"`
+	// The period over which the failure rate of a cluster is
+	// evaluated. Defaults to 5m.
+	// +optional
+	Window *metav1.Duration `json:"window,omitempty"`
+	// The maximum number of clusters that may be tainted for failing
+	// applies at the same time. A cluster is also not tainted if no
+	// other ready cluster would remain untainted. Defaults to 1.
+	// +optional
+	MaxEvictedClusters *int64 `json:"maxEvictedClusters,omitempty"`
+	// Whether resources already propagated to a tainted cluster are
+	// removed from it. The cluster is tainted with the NoExecute
+	// effect if "Enabled", and with the NoSchedule effect otherwise,
+	// which only prevents new placements. Defaults to "Disabled".
+	// +optional
+	RemoveResources *EvictedResourceRemoval `json:"removeResources,omitempty"`
+}
+
+// ResourceAdoptionPolicy defines the criteria that a pre-existing
+// resource in a member cluster must satisfy to be adopted. A resource
+// is only adopted if it satisfies all of the criteria that are set.
+type ResourceAdoptionPolicy struct {
+	// The key of an annotation that a resource must have to be
+	// adopted.
+	// +optional
+	RequiredAnnotation string `json:"requiredAnnotation,omitempty"`
+	// The value that the required annotation must have. Any value is
+	// accepted if not set.
+	// +optional
+	RequiredAnnotationValue string `json:"requiredAnnotationValue,omitempty"`
+	// The prefix that the name of a resource must have to be adopted.
+	// +optional
+	NamePrefix string `json:"namePrefix,omitempty"`
+}
+
 type StatusControllerConfig struct {
 	// The maximum number of concurrent Reconciles of status controller which can be run.
 	// Defaults to 1.
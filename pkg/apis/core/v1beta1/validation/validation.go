@@ -17,17 +17,21 @@ limitations under the License.
 package validation
 
 import (
+	"crypto/x509"
 	"fmt"
 	"net/url"
 	"strconv"
 	"strings"
+	"text/template"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apimachineryval "k8s.io/apimachinery/pkg/api/validation"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
 	valutil "k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/client-go/rest"
@@ -36,11 +40,20 @@ import (
 	"sigs.k8s.io/kubefed/pkg/apis/core/common"
 	"sigs.k8s.io/kubefed/pkg/apis/core/typeconfig"
 	"sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
+	"sigs.k8s.io/kubefed/pkg/controller/utils"
 	"sigs.k8s.io/kubefed/pkg/features"
 )
 
 const (
 	federatedTypeConfigNameErrorMsg string = "name must be 'TARGET_PLURAL_NAME(.TARGET_GROUP_NAME)'"
+
+	// The shortest interval at which the status of resources may be
+	// collected, to bound the load on member clusters.
+	minStatusCollectionInterval = time.Second
+
+	// The shortest interval at which managed resources may be checked
+	// for drift, to bound the load on the host cluster.
+	minDriftDetectionInterval = time.Second
 )
 
 func ValidateFederatedTypeConfig(obj *v1beta1.FederatedTypeConfig, statusSubResource bool) field.ErrorList {
@@ -74,6 +87,207 @@ func ValidateFederatedTypeConfigSpec(spec *v1beta1.FederatedTypeConfigSpec, fldP
 		allErrs = append(allErrs, validateEnumStrings(fldPath.Child("statusCollection"), string(*spec.StatusCollection), []string{string(v1beta1.StatusCollectionEnabled), string(v1beta1.StatusCollectionDisabled)})...)
 	}
 
+	if spec.StatusCollectionInterval != nil && spec.StatusCollectionInterval.Duration < minStatusCollectionInterval {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("statusCollectionInterval"), spec.StatusCollectionInterval.Duration.String(),
+			fmt.Sprintf("must be at least %v", minStatusCollectionInterval)))
+	}
+
+	if spec.DriftDetectionInterval != nil && spec.DriftDetectionInterval.Duration < minDriftDetectionInterval {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("driftDetectionInterval"), spec.DriftDetectionInterval.Duration.String(),
+			fmt.Sprintf("must be at least %v", minDriftDetectionInterval)))
+	}
+
+	if len(spec.TargetNameTemplate) > 0 {
+		allErrs = append(allErrs, validateTargetNameTemplate(spec, fldPath.Child("targetNameTemplate"))...)
+	}
+
+	for i, path := range spec.IncludedFields {
+		allErrs = append(allErrs, validateIncludedField(path, fldPath.Child("includedFields").Index(i))...)
+	}
+
+	if spec.ServerManagedMetadata != nil {
+		allErrs = append(allErrs, validateServerManagedMetadata(spec.ServerManagedMetadata, fldPath.Child("serverManagedMetadata"))...)
+	}
+
+	if spec.TransformationWebhook != nil {
+		allErrs = append(allErrs, validateTransformationWebhook(spec.TransformationWebhook, fldPath.Child("transformationWebhook"))...)
+	}
+
+	if spec.PropagationWebhook != nil {
+		allErrs = append(allErrs, validatePropagationWebhook(spec.PropagationWebhook, fldPath.Child("propagationWebhook"))...)
+	}
+
+	if spec.PropagationWindow != nil {
+		if _, err := utils.ParsePropagationWindow(spec.PropagationWindow); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("propagationWindow"), *spec.PropagationWindow, err.Error()))
+		}
+	}
+
+	allErrs = append(allErrs, validateTypeFeatureGates(spec.FeatureGates, fldPath.Child("featureGates"))...)
+
+	allErrs = append(allErrs, validateStatusAggregations(spec.StatusAggregations, fldPath.Child("statusAggregations"))...)
+
+	if spec.Quota != nil {
+		allErrs = append(allErrs, validatePlacementQuota(spec.Quota, fldPath.Child("quota"))...)
+	}
+
+	if spec.RemovalStrategy != nil {
+		if err := utils.ValidateRemovalStrategy(spec.RemovalStrategy); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("removalStrategy"), *spec.RemovalStrategy, err.Error()))
+		}
+	}
+
+	if spec.DeletionGracePeriod != nil && spec.DeletionGracePeriod.Duration < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("deletionGracePeriod"), spec.DeletionGracePeriod.Duration.String(), "must not be negative"))
+	}
+
+	return allErrs
+}
+
+func validateServerManagedMetadata(metadata *v1beta1.ServerManagedMetadata, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	for i, key := range metadata.Labels {
+		allErrs = append(allErrs, metav1validation.ValidateLabelName(key, fldPath.Child("labels").Index(i))...)
+	}
+	for i, key := range metadata.Annotations {
+		// Annotation keys have the same syntax as label names.
+		allErrs = append(allErrs, metav1validation.ValidateLabelName(key, fldPath.Child("annotations").Index(i))...)
+	}
+	for i, finalizer := range metadata.Finalizers {
+		allErrs = append(allErrs, apimachineryval.ValidateFinalizerName(finalizer, fldPath.Child("finalizers").Index(i))...)
+	}
+	return allErrs
+}
+
+func validatePlacementQuota(quota *v1beta1.PlacementQuota, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if quota.MaxPerCluster != nil {
+		allErrs = append(allErrs, apimachineryval.ValidateNonnegativeField(int64(*quota.MaxPerCluster), fldPath.Child("maxPerCluster"))...)
+	}
+	if quota.MaxPerNamespace != nil {
+		allErrs = append(allErrs, apimachineryval.ValidateNonnegativeField(int64(*quota.MaxPerNamespace), fldPath.Child("maxPerNamespace"))...)
+	}
+	return allErrs
+}
+
+func validateStatusAggregations(aggregations []v1beta1.StatusAggregation, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	existingNames := make(map[string]bool)
+	for i, aggregation := range aggregations {
+		aggregationPath := fldPath.Index(i)
+		namePath := aggregationPath.Child("name")
+		if len(aggregation.Name) == 0 {
+			allErrs = append(allErrs, field.Required(namePath, ""))
+		} else if existingNames[aggregation.Name] {
+			allErrs = append(allErrs, field.Duplicate(namePath, aggregation.Name))
+		}
+		existingNames[aggregation.Name] = true
+
+		fieldPath := aggregationPath.Child("field")
+		if len(aggregation.Field) == 0 {
+			allErrs = append(allErrs, field.Required(fieldPath, ""))
+		} else {
+			for _, part := range strings.Split(aggregation.Field, ".") {
+				if len(part) == 0 {
+					allErrs = append(allErrs, field.Invalid(fieldPath, aggregation.Field, "must be a dot-separated path without empty elements"))
+					break
+				}
+			}
+		}
+
+		allErrs = append(allErrs, validateEnumStrings(aggregationPath.Child("function"), string(aggregation.Function),
+			[]string{string(v1beta1.AggregationSum), string(v1beta1.AggregationMin), string(v1beta1.AggregationMax)})...)
+	}
+	return allErrs
+}
+
+func validateTypeFeatureGates(gates []v1beta1.FeatureGatesConfig, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	var overridableNames []string
+	for _, gate := range features.TypeOverridableFeatureGates {
+		overridableNames = append(overridableNames, string(gate))
+	}
+	existingNames := make(map[string]bool)
+	for i, gate := range gates {
+		gatePath := fldPath.Index(i)
+		if existingNames[gate.Name] {
+			allErrs = append(allErrs, field.Duplicate(gatePath.Child("name"), gate.Name))
+			continue
+		}
+		existingNames[gate.Name] = true
+
+		allErrs = append(allErrs, validateEnumStrings(gatePath.Child("name"), gate.Name, overridableNames)...)
+
+		allErrs = append(allErrs, validateEnumStrings(gatePath.Child("configuration"), string(gate.Configuration),
+			[]string{string(v1beta1.ConfigurationEnabled), string(v1beta1.ConfigurationDisabled)})...)
+	}
+	return allErrs
+}
+
+func validateTransformationWebhook(webhook *v1beta1.TransformationWebhook, fldPath *field.Path) field.ErrorList {
+	allErrs := validateWebhookURL(webhook.URL, fldPath.Child("url"))
+	allErrs = append(allErrs, validateWebhookTimeout(webhook.TimeoutSeconds, fldPath.Child("timeoutSeconds"))...)
+	return allErrs
+}
+
+func validatePropagationWebhook(webhook *v1beta1.PropagationWebhook, fldPath *field.Path) field.ErrorList {
+	allErrs := validateWebhookURL(webhook.URL, fldPath.Child("url"))
+	allErrs = append(allErrs, validateWebhookTimeout(webhook.TimeoutSeconds, fldPath.Child("timeoutSeconds"))...)
+	if len(webhook.CABundle) > 0 && !x509.NewCertPool().AppendCertsFromPEM(webhook.CABundle) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("caBundle"), field.OmitValueType{}, "must contain at least one PEM encoded certificate"))
+	}
+	if webhook.SigningSecretRef != nil && len(webhook.SigningSecretRef.Name) == 0 {
+		allErrs = append(allErrs, field.Required(fldPath.Child("signingSecretRef", "name"), ""))
+	}
+	if webhook.Retries != nil && (*webhook.Retries < 0 || *webhook.Retries > 10) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("retries"), *webhook.Retries, "must be between 0 and 10"))
+	}
+	return allErrs
+}
+
+func validateWebhookURL(webhookURL string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if len(webhookURL) == 0 {
+		allErrs = append(allErrs, field.Required(fldPath, ""))
+	} else if u, err := url.Parse(webhookURL); err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath, webhookURL, err.Error()))
+	} else if u.Scheme != "https" || len(u.Host) == 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath, webhookURL, "must be an https URL with a host"))
+	}
+	return allErrs
+}
+
+func validateWebhookTimeout(timeoutSeconds *int32, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if timeoutSeconds != nil && (*timeoutSeconds < 1 || *timeoutSeconds > 30) {
+		allErrs = append(allErrs, field.Invalid(fldPath, *timeoutSeconds, "must be between 1 and 30 seconds"))
+	}
+	return allErrs
+}
+
+func validateIncludedField(path string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	fields := strings.Split(path, ".")
+	for _, f := range fields {
+		if len(f) == 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath, path, "must be a dot-separated path without empty segments"))
+			return allErrs
+		}
+	}
+	if fields[0] == "metadata" || fields[0] == "apiVersion" || fields[0] == "kind" {
+		allErrs = append(allErrs, field.Invalid(fldPath, path, "must not refer to the type or metadata of a resource"))
+	}
+	return allErrs
+}
+
+func validateTargetNameTemplate(spec *v1beta1.FederatedTypeConfigSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if spec.TargetType.Kind == "Namespace" && len(spec.TargetType.Group) == 0 {
+		allErrs = append(allErrs, field.Forbidden(fldPath, "the name of a federated namespace must match its containing namespace"))
+	}
+	if _, err := template.New("targetName").Parse(spec.TargetNameTemplate); err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath, spec.TargetNameTemplate, err.Error()))
+	}
 	return allErrs
 }
 
@@ -170,6 +384,7 @@ func validateKubeFedClusterSpec(spec *v1beta1.KubeFedClusterSpec, path *field.Pa
 	if spec.ProxyURL != "" {
 		allErrs = append(allErrs, validateProxyURL(spec.ProxyURL, path.Child("proxyURL"))...)
 	}
+	allErrs = append(allErrs, validateClusterTaints(spec.Taints, path.Child("taints"))...)
 	return allErrs
 }
 
@@ -255,6 +470,29 @@ func validateDisabledTLSValidations(disabledTLSValidations []v1beta1.TLSValidati
 	return allErrs
 }
 
+// validateClusterTaints ensures that the given taints have valid keys
+// and values, and effects that are supported for placement. A key and
+// effect may only be used by a single taint.
+func validateClusterTaints(taints []corev1.Taint, path *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	keysAndEffects := sets.NewString()
+	for i, taint := range taints {
+		taintPath := path.Index(i)
+		allErrs = append(allErrs, metav1validation.ValidateLabelName(taint.Key, taintPath.Child("key"))...)
+		for _, msg := range valutil.IsValidLabelValue(taint.Value) {
+			allErrs = append(allErrs, field.Invalid(taintPath.Child("value"), taint.Value, msg))
+		}
+		allErrs = append(allErrs, validateEnumStrings(taintPath.Child("effect"), string(taint.Effect),
+			[]string{string(corev1.TaintEffectNoSchedule), string(corev1.TaintEffectNoExecute)})...)
+		keyAndEffect := taint.Key + ":" + string(taint.Effect)
+		if keysAndEffects.Has(keyAndEffect) {
+			allErrs = append(allErrs, field.Duplicate(taintPath, keyAndEffect))
+		}
+		keysAndEffects.Insert(keyAndEffect)
+	}
+	return allErrs
+}
+
 func validateClusterCondition(cc *v1beta1.ClusterCondition, path *field.Path) field.ErrorList {
 	var allErrs field.ErrorList
 
@@ -336,7 +574,7 @@ func ValidateKubeFedConfig(kubeFedConfig, oldKubeFedConfig *v1beta1.KubeFedConfi
 			existingNames[gate.Name] = true
 
 			allErrs = append(allErrs, validateEnumStrings(gatesPath.Child("name"), gate.Name,
-				[]string{string(features.PushReconciler), string(features.RawResourceStatusCollection), string(features.SchedulerPreferences)})...)
+				[]string{string(features.PushReconciler), string(features.RawResourceStatusCollection), string(features.SchedulerPreferences), string(features.StatusFeedback), string(features.MetadataMerge)})...)
 
 			allErrs = append(allErrs, validateEnumStrings(gatesPath.Child("configuration"), string(gate.Configuration),
 				[]string{string(v1beta1.ConfigurationEnabled), string(v1beta1.ConfigurationDisabled)})...)
@@ -366,6 +604,29 @@ func ValidateKubeFedConfig(kubeFedConfig, oldKubeFedConfig *v1beta1.KubeFedConfi
 		allErrs = append(allErrs, validateIntPtrGreaterThan0(syncPath.Child("maxConcurrentReconciles"), sync.MaxConcurrentReconciles)...)
 		allErrs = append(allErrs, validateEnumStrings(adoptPath, string(*sync.AdoptResources),
 			[]string{string(v1beta1.AdoptResourcesEnabled), string(v1beta1.AdoptResourcesDisabled)})...)
+		allErrs = append(allErrs, validateApplyOrder(syncPath.Child("applyOrder"), sync.ApplyOrder)...)
+		allErrs = append(allErrs, validateManagedLabels(syncPath.Child("managedLabels"), sync.ManagedLabels)...)
+		allErrs = append(allErrs, apimachineryval.ValidateAnnotations(sync.ManagedAnnotations, syncPath.Child("managedAnnotations"))...)
+		allErrs = append(allErrs, validateManagedLabels(syncPath.Child("createdNamespaceLabels"), sync.CreatedNamespaceLabels)...)
+		allErrs = append(allErrs, apimachineryval.ValidateAnnotations(sync.CreatedNamespaceAnnotations, syncPath.Child("createdNamespaceAnnotations"))...)
+		allErrs = append(allErrs, validateAdoptionPolicy(syncPath.Child("adoptionPolicy"), sync.AdoptionPolicy)...)
+		allErrs = append(allErrs, validateClusterEviction(syncPath.Child("clusterEviction"), sync.ClusterEviction)...)
+		if len(sync.NamespaceOptInLabel) > 0 {
+			allErrs = append(allErrs, metav1validation.ValidateLabelName(sync.NamespaceOptInLabel, syncPath.Child("namespaceOptInLabel"))...)
+		}
+		if sync.DeleteEmptyNamespaces != nil {
+			allErrs = append(allErrs, validateEnumStrings(syncPath.Child("deleteEmptyNamespaces"), string(*sync.DeleteEmptyNamespaces),
+				[]string{string(v1beta1.DeleteEmptyNamespacesEnabled), string(v1beta1.DeleteEmptyNamespacesDisabled)})...)
+		}
+		if sync.PruneRemovedClusters != nil {
+			allErrs = append(allErrs, validateEnumStrings(syncPath.Child("pruneRemovedClusters"), string(*sync.PruneRemovedClusters),
+				[]string{string(v1beta1.PruneRemovedClustersEnabled), string(v1beta1.PruneRemovedClustersDisabled)})...)
+		}
+		if len(sync.PlacementAnnotation) > 0 {
+			for _, msg := range valutil.IsQualifiedName(strings.ToLower(sync.PlacementAnnotation)) {
+				allErrs = append(allErrs, field.Invalid(syncPath.Child("placementAnnotation"), sync.PlacementAnnotation, msg))
+			}
+		}
 	}
 
 	statusController := spec.StatusController
@@ -379,6 +640,75 @@ func ValidateKubeFedConfig(kubeFedConfig, oldKubeFedConfig *v1beta1.KubeFedConfi
 	return allErrs
 }
 
+func validateApplyOrder(path *field.Path, applyOrder []string) field.ErrorList {
+	errs := field.ErrorList{}
+	kinds := sets.NewString()
+	for i, kind := range applyOrder {
+		switch {
+		case len(kind) == 0:
+			errs = append(errs, field.Required(path.Index(i), ""))
+		case kinds.Has(kind):
+			errs = append(errs, field.Duplicate(path.Index(i), kind))
+		}
+		kinds.Insert(kind)
+	}
+	return errs
+}
+
+// validateManagedLabels ensures that the given labels are valid and
+// do not include the managed label, whose value is reserved for the
+// sync controller.
+func validateManagedLabels(path *field.Path, labels map[string]string) field.ErrorList {
+	errs := metav1validation.ValidateLabels(labels, path)
+	if _, ok := labels[utils.ManagedByKubeFedLabelKey]; ok {
+		errs = append(errs, field.Forbidden(path.Key(utils.ManagedByKubeFedLabelKey), "the managed label is set by the sync controller"))
+	}
+	return errs
+}
+
+// validateAdoptionPolicy ensures that the required annotation of the
+// given policy is a valid annotation key and that a value is only
+// required together with the annotation.
+func validateAdoptionPolicy(path *field.Path, policy *v1beta1.ResourceAdoptionPolicy) field.ErrorList {
+	errs := field.ErrorList{}
+	if policy == nil {
+		return errs
+	}
+	annotationPath := path.Child("requiredAnnotation")
+	if len(policy.RequiredAnnotation) > 0 {
+		for _, msg := range valutil.IsQualifiedName(strings.ToLower(policy.RequiredAnnotation)) {
+			errs = append(errs, field.Invalid(annotationPath, policy.RequiredAnnotation, msg))
+		}
+	} else if len(policy.RequiredAnnotationValue) > 0 {
+		errs = append(errs, field.Required(annotationPath, "must be set if requiredAnnotationValue is set"))
+	}
+	return errs
+}
+
+func validateClusterEviction(path *field.Path, eviction *v1beta1.ClusterEvictionConfig) field.ErrorList {
+	errs := field.ErrorList{}
+	if eviction == nil {
+		return errs
+	}
+	thresholdPath := path.Child("failureThresholdPercent")
+	if eviction.FailureThresholdPercent == nil {
+		errs = append(errs, field.Required(thresholdPath, ""))
+	} else if threshold := *eviction.FailureThresholdPercent; threshold < 1 || threshold > 100 {
+		errs = append(errs, field.Invalid(thresholdPath, threshold, "should be between 1 and 100"))
+	}
+	errs = append(errs, validateIntPtrGreaterThan0(path.Child("minimumApplies"), eviction.MinimumApplies)...)
+	errs = append(errs, validateDurationGreaterThan0(path.Child("window"), eviction.Window)...)
+	errs = append(errs, validateIntPtrGreaterThan0(path.Child("maxEvictedClusters"), eviction.MaxEvictedClusters)...)
+	removePath := path.Child("removeResources")
+	if eviction.RemoveResources == nil {
+		errs = append(errs, field.Required(removePath, ""))
+	} else {
+		errs = append(errs, validateEnumStrings(removePath, string(*eviction.RemoveResources),
+			[]string{string(v1beta1.RemoveEvictedResourcesEnabled), string(v1beta1.RemoveEvictedResourcesDisabled)})...)
+	}
+	return errs
+}
+
 func validateDurationGreaterThan0(path *field.Path, duration *metav1.Duration) field.ErrorList {
 	errs := field.ErrorList{}
 	if duration == nil {
@@ -26,6 +26,7 @@ import (
 	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
 
 	"sigs.k8s.io/kubefed/pkg/apis/core/common"
 	"sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
@@ -120,6 +121,118 @@ func TestValidateFederatedTypeConfigSpec(t *testing.T) {
 	invalidStatusCollection.Spec.StatusCollection = &invalidStatusCollectionMode
 	errorCases["spec.statusCollection: Unsupported value"] = invalidStatusCollection
 
+	invalidTargetNameTemplate := validFederatedTypeConfig()
+	invalidTargetNameTemplate.Spec.TargetNameTemplate = "{{ .tenant"
+	errorCases["spec.targetNameTemplate: Invalid value"] = invalidTargetNameTemplate
+
+	namespaceTargetNameTemplate := validFederatedTypeConfig()
+	namespaceTargetNameTemplate.Spec.TargetType.Kind = "Namespace"
+	namespaceTargetNameTemplate.Spec.TargetType.Group = ""
+	namespaceTargetNameTemplate.Spec.TargetNameTemplate = "{{ .tenant }}"
+	errorCases["spec.targetNameTemplate: Forbidden"] = namespaceTargetNameTemplate
+
+	emptyIncludedFieldSegment := validFederatedTypeConfig()
+	emptyIncludedFieldSegment.Spec.IncludedFields = []string{"spec..replicas"}
+	errorCases["spec.includedFields[0]: Invalid value"] = emptyIncludedFieldSegment
+
+	metadataIncludedField := validFederatedTypeConfig()
+	metadataIncludedField.Spec.IncludedFields = []string{"metadata.name"}
+	errorCases["must not refer to the type or metadata of a resource"] = metadataIncludedField
+
+	invalidServerManagedLabel := validFederatedTypeConfig()
+	invalidServerManagedLabel.Spec.ServerManagedMetadata = &v1beta1.ServerManagedMetadata{Labels: []string{"example.com/"}}
+	errorCases["spec.serverManagedMetadata.labels[0]: Invalid value"] = invalidServerManagedLabel
+
+	invalidServerManagedFinalizer := validFederatedTypeConfig()
+	invalidServerManagedFinalizer.Spec.ServerManagedMetadata = &v1beta1.ServerManagedMetadata{Finalizers: []string{"example.com/"}}
+	errorCases["spec.serverManagedMetadata.finalizers[0]: Invalid value"] = invalidServerManagedFinalizer
+
+	missingWebhookURL := validFederatedTypeConfig()
+	missingWebhookURL.Spec.TransformationWebhook = &v1beta1.TransformationWebhook{}
+	errorCases["spec.transformationWebhook.url: Required value"] = missingWebhookURL
+
+	insecureWebhookURL := validFederatedTypeConfig()
+	insecureWebhookURL.Spec.TransformationWebhook = &v1beta1.TransformationWebhook{URL: "http://transformer.example.com/transform"}
+	errorCases["must be an https URL with a host"] = insecureWebhookURL
+
+	invalidWebhookTimeout := validFederatedTypeConfig()
+	webhookTimeout := int32(60)
+	invalidWebhookTimeout.Spec.TransformationWebhook = &v1beta1.TransformationWebhook{URL: "https://transformer.example.com/transform", TimeoutSeconds: &webhookTimeout}
+	errorCases["spec.transformationWebhook.timeoutSeconds: Invalid value"] = invalidWebhookTimeout
+
+	insecurePropagationWebhookURL := validFederatedTypeConfig()
+	insecurePropagationWebhookURL.Spec.PropagationWebhook = &v1beta1.PropagationWebhook{URL: "http://receiver.example.com/notify"}
+	errorCases["spec.propagationWebhook.url: Invalid value"] = insecurePropagationWebhookURL
+
+	invalidPropagationWebhookRetries := validFederatedTypeConfig()
+	webhookRetries := int32(11)
+	invalidPropagationWebhookRetries.Spec.PropagationWebhook = &v1beta1.PropagationWebhook{URL: "https://receiver.example.com/notify", Retries: &webhookRetries}
+	errorCases["spec.propagationWebhook.retries: Invalid value"] = invalidPropagationWebhookRetries
+
+	invalidPropagationWebhookCABundle := validFederatedTypeConfig()
+	invalidPropagationWebhookCABundle.Spec.PropagationWebhook = &v1beta1.PropagationWebhook{URL: "https://receiver.example.com/notify", CABundle: []byte("not a certificate")}
+	errorCases["spec.propagationWebhook.caBundle: Invalid value"] = invalidPropagationWebhookCABundle
+
+	missingSigningSecretName := validFederatedTypeConfig()
+	missingSigningSecretName.Spec.PropagationWebhook = &v1beta1.PropagationWebhook{URL: "https://receiver.example.com/notify", SigningSecretRef: &v1beta1.LocalSecretReference{}}
+	errorCases["spec.propagationWebhook.signingSecretRef.name: Required value"] = missingSigningSecretName
+
+	invalidWindowStart := validFederatedTypeConfig()
+	invalidWindowStart.Spec.PropagationWindow = &v1beta1.PropagationWindow{Start: "25:00", Duration: metav1.Duration{Duration: time.Hour}}
+	errorCases["start must be a time of day in the format HH:MM"] = invalidWindowStart
+
+	invalidWindowDay := validFederatedTypeConfig()
+	invalidWindowDay.Spec.PropagationWindow = &v1beta1.PropagationWindow{Days: []string{"Saturday"}, Start: "02:00", Duration: metav1.Duration{Duration: time.Hour}}
+	errorCases["days must be three-letter abbreviations"] = invalidWindowDay
+
+	invalidWindowDuration := validFederatedTypeConfig()
+	invalidWindowDuration.Spec.PropagationWindow = &v1beta1.PropagationWindow{Start: "02:00"}
+	errorCases["spec.propagationWindow: Invalid value"] = invalidWindowDuration
+
+	unsupportedFeatureGate := validFederatedTypeConfig()
+	unsupportedFeatureGate.Spec.FeatureGates = []v1beta1.FeatureGatesConfig{{Name: string(features.PushReconciler), Configuration: v1beta1.ConfigurationEnabled}}
+	errorCases["spec.featureGates[0].name: Unsupported value"] = unsupportedFeatureGate
+
+	duplicateFeatureGate := validFederatedTypeConfig()
+	duplicateFeatureGate.Spec.FeatureGates = []v1beta1.FeatureGatesConfig{
+		{Name: string(features.StatusFeedback), Configuration: v1beta1.ConfigurationEnabled},
+		{Name: string(features.StatusFeedback), Configuration: v1beta1.ConfigurationDisabled},
+	}
+	errorCases["spec.featureGates[1].name: Duplicate value"] = duplicateFeatureGate
+
+	invalidAggregationField := validFederatedTypeConfig()
+	invalidAggregationField.Spec.StatusAggregations = []v1beta1.StatusAggregation{{Name: "totalReadyReplicas", Field: "readyReplicas.", Function: v1beta1.AggregationSum}}
+	errorCases["spec.statusAggregations[0].field: Invalid value"] = invalidAggregationField
+
+	unsupportedAggregationFunction := validFederatedTypeConfig()
+	unsupportedAggregationFunction.Spec.StatusAggregations = []v1beta1.StatusAggregation{{Name: "averageReadyReplicas", Field: "readyReplicas", Function: "Average"}}
+	errorCases["spec.statusAggregations[0].function: Unsupported value"] = unsupportedAggregationFunction
+
+	negativeQuota := validFederatedTypeConfig()
+	maxPerNamespace := int32(-1)
+	negativeQuota.Spec.Quota = &v1beta1.PlacementQuota{MaxPerNamespace: &maxPerNamespace}
+	errorCases["spec.quota.maxPerNamespace: Invalid value"] = negativeQuota
+
+	shortStatusCollectionInterval := validFederatedTypeConfig()
+	shortStatusCollectionInterval.Spec.StatusCollectionInterval = &metav1.Duration{Duration: 100 * time.Millisecond}
+	errorCases["spec.statusCollectionInterval: Invalid value"] = shortStatusCollectionInterval
+
+	shortDriftDetectionInterval := validFederatedTypeConfig()
+	shortDriftDetectionInterval.Spec.DriftDetectionInterval = &metav1.Duration{Duration: 100 * time.Millisecond}
+	errorCases["spec.driftDetectionInterval: Invalid value"] = shortDriftDetectionInterval
+
+	negativeDrainDuration := validFederatedTypeConfig()
+	negativeDrainDuration.Spec.RemovalStrategy = &v1beta1.RemovalStrategy{DrainDuration: metav1.Duration{Duration: -time.Second}}
+	errorCases["drainDuration must not be negative"] = negativeDrainDuration
+
+	invalidPreDeletePatch := validFederatedTypeConfig()
+	invalidPreDeletePatch.Spec.RemovalStrategy = &v1beta1.RemovalStrategy{PreDeletePatch: &apiextv1.JSON{Raw: []byte(`[{"op": "remove", "path": "/spec"}]`)}}
+	errorCases["preDeletePatch must be a JSON object"] = invalidPreDeletePatch
+
+	negativeDeletionGracePeriod := validFederatedTypeConfig()
+	negativeDeletionGracePeriod.Spec.DeletionGracePeriod = &metav1.Duration{Duration: -time.Minute}
+	errorCases["spec.deletionGracePeriod: Invalid value"] = negativeDeletionGracePeriod
+
 	for k, v := range errorCases {
 		errs := ValidateFederatedTypeConfigSpec(&v.Spec, field.NewPath("spec"))
 		if len(errs) == 0 {
@@ -260,6 +373,7 @@ func successCasesForFederatedTypeConfig() []*v1beta1.FederatedTypeConfig {
 	return []*v1beta1.FederatedTypeConfig{
 		federatedTypeConfig(apiResourceWithEmptyGroup()),
 		federatedTypeConfig(apiResourceWithNonEmptyGroup()),
+		federatedTypeConfigWithStatusCollectionInterval(apiResourceWithEmptyGroup(), time.Minute),
 	}
 }
 
@@ -319,6 +433,12 @@ func federatedTypeConfig(apiResource *metav1.APIResource) *v1beta1.FederatedType
 	return ftc
 }
 
+func federatedTypeConfigWithStatusCollectionInterval(apiResource *metav1.APIResource, interval time.Duration) *v1beta1.FederatedTypeConfig {
+	ftc := federatedTypeConfig(apiResource)
+	ftc.Spec.StatusCollectionInterval = &metav1.Duration{Duration: interval}
+	return ftc
+}
+
 func validAPIResource() *v1beta1.APIResource {
 	return apiResource(apiResourceWithNonEmptyGroup())
 }
@@ -376,6 +496,58 @@ func TestValidateKubeFedCluster(t *testing.T) {
 	}
 }
 
+func TestValidateClusterTaints(t *testing.T) {
+	testCases := map[string]struct {
+		taints         []corev1.Taint
+		expectedErrMsg string
+	}{
+		"no taints": {},
+		"valid taints": {
+			taints: []corev1.Taint{
+				{Key: "example.com/draining", Effect: corev1.TaintEffectNoSchedule},
+				{Key: "example.com/draining", Value: "true", Effect: corev1.TaintEffectNoExecute},
+			},
+		},
+		"invalid key": {
+			taints:         []corev1.Taint{{Key: "not a key", Effect: corev1.TaintEffectNoSchedule}},
+			expectedErrMsg: "taints[0].key: Invalid value",
+		},
+		"invalid value": {
+			taints:         []corev1.Taint{{Key: "draining", Value: "not a value", Effect: corev1.TaintEffectNoSchedule}},
+			expectedErrMsg: "taints[0].value: Invalid value",
+		},
+		"unsupported effect": {
+			taints:         []corev1.Taint{{Key: "draining", Effect: corev1.TaintEffectPreferNoSchedule}},
+			expectedErrMsg: "taints[0].effect: Unsupported value",
+		},
+		"duplicate key and effect": {
+			taints: []corev1.Taint{
+				{Key: "draining", Effect: corev1.TaintEffectNoSchedule},
+				{Key: "draining", Value: "true", Effect: corev1.TaintEffectNoSchedule},
+			},
+			expectedErrMsg: "taints[1]: Duplicate value",
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			kfc := testcommon.ValidKubeFedCluster()
+			kfc.Spec.Taints = tc.taints
+			errs := ValidateKubeFedCluster(kfc, false)
+			if len(tc.expectedErrMsg) == 0 {
+				if len(errs) != 0 {
+					t.Errorf("expected success: %v", errs)
+				}
+				return
+			}
+			if len(errs) == 0 {
+				t.Errorf("expected failure")
+			} else if !strings.Contains(errs[0].Error(), tc.expectedErrMsg) {
+				t.Errorf("unexpected error: %q, expected: %q", errs[0].Error(), tc.expectedErrMsg)
+			}
+		})
+	}
+}
+
 func TestValidateAPIEndpoint(t *testing.T) {
 	successProtocolSchemes := []string{
 		"",
@@ -702,6 +874,12 @@ func TestValidateKubeFedConfig(t *testing.T) {
 		t.Errorf("expected success: %v", errs)
 	}
 
+	clusterEviction := testcommon.ValidKubeFedConfig()
+	clusterEviction.Spec.SyncController.ClusterEviction = validClusterEviction()
+	if errs := ValidateKubeFedConfig(clusterEviction, nil); len(errs) != 0 {
+		t.Errorf("expected success: %v", errs)
+	}
+
 	errorCases := map[string]*v1beta1.KubeFedConfig{}
 
 	invalidScope := testcommon.ValidKubeFedConfig()
@@ -869,6 +1047,80 @@ func TestValidateKubeFedConfig(t *testing.T) {
 	invalidAdoptResources.Spec.SyncController.AdoptResources = &invalidAdoptResourcesValue
 	errorCases["spec.syncController.adoptResources: Unsupported value"] = invalidAdoptResources
 
+	invalidApplyOrderEmptyKind := testcommon.ValidKubeFedConfig()
+	invalidApplyOrderEmptyKind.Spec.SyncController.ApplyOrder = []string{"Namespace", ""}
+	errorCases["spec.syncController.applyOrder[1]: Required value"] = invalidApplyOrderEmptyKind
+
+	invalidApplyOrderDuplicateKind := testcommon.ValidKubeFedConfig()
+	invalidApplyOrderDuplicateKind.Spec.SyncController.ApplyOrder = []string{"Namespace", "ConfigMap", "Namespace"}
+	errorCases["spec.syncController.applyOrder[2]: Duplicate value"] = invalidApplyOrderDuplicateKind
+
+	invalidManagedLabelsReserved := testcommon.ValidKubeFedConfig()
+	invalidManagedLabelsReserved.Spec.SyncController.ManagedLabels = map[string]string{"kubefed.io/managed": "false"}
+	errorCases["spec.syncController.managedLabels[kubefed.io/managed]: Forbidden"] = invalidManagedLabelsReserved
+
+	invalidNamespaceOptInLabel := testcommon.ValidKubeFedConfig()
+	invalidNamespaceOptInLabel.Spec.SyncController.NamespaceOptInLabel = "opt in"
+	errorCases["spec.syncController.namespaceOptInLabel: Invalid value"] = invalidNamespaceOptInLabel
+
+	invalidDeleteEmptyNamespaces := testcommon.ValidKubeFedConfig()
+	invalidDeleteEmptyNamespacesValue := v1beta1.EmptyNamespaceDeletion("NeitherEnableOrDisable")
+	invalidDeleteEmptyNamespaces.Spec.SyncController.DeleteEmptyNamespaces = &invalidDeleteEmptyNamespacesValue
+	errorCases["spec.syncController.deleteEmptyNamespaces: Unsupported value"] = invalidDeleteEmptyNamespaces
+
+	invalidPruneRemovedClusters := testcommon.ValidKubeFedConfig()
+	invalidPruneRemovedClustersValue := v1beta1.RemovedClusterPruning("NeitherEnableOrDisable")
+	invalidPruneRemovedClusters.Spec.SyncController.PruneRemovedClusters = &invalidPruneRemovedClustersValue
+	errorCases["spec.syncController.pruneRemovedClusters: Unsupported value"] = invalidPruneRemovedClusters
+
+	invalidClusterEvictionThreshold := testcommon.ValidKubeFedConfig()
+	invalidClusterEvictionThreshold.Spec.SyncController.ClusterEviction = validClusterEviction()
+	*invalidClusterEvictionThreshold.Spec.SyncController.ClusterEviction.FailureThresholdPercent = 101
+	errorCases["spec.syncController.clusterEviction.failureThresholdPercent: Invalid value"] = invalidClusterEvictionThreshold
+
+	invalidClusterEvictionWindow := testcommon.ValidKubeFedConfig()
+	invalidClusterEvictionWindow.Spec.SyncController.ClusterEviction = validClusterEviction()
+	invalidClusterEvictionWindow.Spec.SyncController.ClusterEviction.Window = nil
+	errorCases["spec.syncController.clusterEviction.window: Required value"] = invalidClusterEvictionWindow
+
+	invalidClusterEvictionMaxEvicted := testcommon.ValidKubeFedConfig()
+	invalidClusterEvictionMaxEvicted.Spec.SyncController.ClusterEviction = validClusterEviction()
+	*invalidClusterEvictionMaxEvicted.Spec.SyncController.ClusterEviction.MaxEvictedClusters = 0
+	errorCases["spec.syncController.clusterEviction.maxEvictedClusters: Invalid value"] = invalidClusterEvictionMaxEvicted
+
+	invalidClusterEvictionRemoveResources := testcommon.ValidKubeFedConfig()
+	invalidClusterEvictionRemoveResources.Spec.SyncController.ClusterEviction = validClusterEviction()
+	*invalidClusterEvictionRemoveResources.Spec.SyncController.ClusterEviction.RemoveResources = "NeitherEnableOrDisable"
+	errorCases["spec.syncController.clusterEviction.removeResources: Unsupported value"] = invalidClusterEvictionRemoveResources
+
+	invalidPlacementAnnotation := testcommon.ValidKubeFedConfig()
+	invalidPlacementAnnotation.Spec.SyncController.PlacementAnnotation = "not a valid key"
+	errorCases["spec.syncController.placementAnnotation: Invalid value"] = invalidPlacementAnnotation
+
+	invalidManagedLabelsValue := testcommon.ValidKubeFedConfig()
+	invalidManagedLabelsValue.Spec.SyncController.ManagedLabels = map[string]string{"app.kubernetes.io/managed-by": "not a valid value"}
+	errorCases["spec.syncController.managedLabels: Invalid value"] = invalidManagedLabelsValue
+
+	invalidManagedAnnotationsKey := testcommon.ValidKubeFedConfig()
+	invalidManagedAnnotationsKey.Spec.SyncController.ManagedAnnotations = map[string]string{"not a valid key": "value"}
+	errorCases["spec.syncController.managedAnnotations: Invalid value"] = invalidManagedAnnotationsKey
+
+	invalidCreatedNamespaceLabelsReserved := testcommon.ValidKubeFedConfig()
+	invalidCreatedNamespaceLabelsReserved.Spec.SyncController.CreatedNamespaceLabels = map[string]string{"kubefed.io/managed": "false"}
+	errorCases["spec.syncController.createdNamespaceLabels[kubefed.io/managed]: Forbidden"] = invalidCreatedNamespaceLabelsReserved
+
+	invalidCreatedNamespaceAnnotationsKey := testcommon.ValidKubeFedConfig()
+	invalidCreatedNamespaceAnnotationsKey.Spec.SyncController.CreatedNamespaceAnnotations = map[string]string{"not a valid key": "value"}
+	errorCases["spec.syncController.createdNamespaceAnnotations: Invalid value"] = invalidCreatedNamespaceAnnotationsKey
+
+	invalidAdoptionPolicyAnnotation := testcommon.ValidKubeFedConfig()
+	invalidAdoptionPolicyAnnotation.Spec.SyncController.AdoptionPolicy = &v1beta1.ResourceAdoptionPolicy{RequiredAnnotation: "not a valid key"}
+	errorCases["spec.syncController.adoptionPolicy.requiredAnnotation: Invalid value"] = invalidAdoptionPolicyAnnotation
+
+	invalidAdoptionPolicyValueWithoutAnnotation := testcommon.ValidKubeFedConfig()
+	invalidAdoptionPolicyValueWithoutAnnotation.Spec.SyncController.AdoptionPolicy = &v1beta1.ResourceAdoptionPolicy{RequiredAnnotationValue: "true"}
+	errorCases["spec.syncController.adoptionPolicy.requiredAnnotation: Required value"] = invalidAdoptionPolicyValueWithoutAnnotation
+
 	invalidStatusControllerNil := testcommon.ValidKubeFedConfig()
 	invalidStatusControllerNil.Spec.StatusController = nil
 	errorCases["spec.statusController: Required value"] = invalidStatusControllerNil
@@ -890,3 +1142,13 @@ func TestValidateKubeFedConfig(t *testing.T) {
 		}
 	}
 }
+
+func validClusterEviction() *v1beta1.ClusterEvictionConfig {
+	return &v1beta1.ClusterEvictionConfig{
+		FailureThresholdPercent: ptr.To[int64](50),
+		MinimumApplies:          ptr.To[int64](10),
+		Window:                  &metav1.Duration{Duration: 5 * time.Minute},
+		MaxEvictedClusters:      ptr.To[int64](1),
+		RemoveResources:         ptr.To(v1beta1.RemoveEvictedResourcesDisabled),
+	}
+}
@@ -21,6 +21,8 @@ limitations under the License.
 package v1beta1
 
 import (
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
@@ -70,6 +72,46 @@ func (in *ClusterCondition) DeepCopy() *ClusterCondition {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterEvictionConfig) DeepCopyInto(out *ClusterEvictionConfig) {
+	*out = *in
+	if in.FailureThresholdPercent != nil {
+		in, out := &in.FailureThresholdPercent, &out.FailureThresholdPercent
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MinimumApplies != nil {
+		in, out := &in.MinimumApplies, &out.MinimumApplies
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Window != nil {
+		in, out := &in.Window, &out.Window
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.MaxEvictedClusters != nil {
+		in, out := &in.MaxEvictedClusters, &out.MaxEvictedClusters
+		*out = new(int64)
+		**out = **in
+	}
+	if in.RemoveResources != nil {
+		in, out := &in.RemoveResources, &out.RemoveResources
+		*out = new(EvictedResourceRemoval)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterEvictionConfig.
+func (in *ClusterEvictionConfig) DeepCopy() *ClusterEvictionConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterEvictionConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterHealthCheckConfig) DeepCopyInto(out *ClusterHealthCheckConfig) {
 	*out = *in
@@ -224,6 +266,66 @@ func (in *FederatedTypeConfigSpec) DeepCopyInto(out *FederatedTypeConfigSpec) {
 		*out = new(StatusCollectionMode)
 		**out = **in
 	}
+	if in.StatusCollectionInterval != nil {
+		in, out := &in.StatusCollectionInterval, &out.StatusCollectionInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.DriftDetectionInterval != nil {
+		in, out := &in.DriftDetectionInterval, &out.DriftDetectionInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.IncludedFields != nil {
+		in, out := &in.IncludedFields, &out.IncludedFields
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ServerManagedMetadata != nil {
+		in, out := &in.ServerManagedMetadata, &out.ServerManagedMetadata
+		*out = new(ServerManagedMetadata)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TransformationWebhook != nil {
+		in, out := &in.TransformationWebhook, &out.TransformationWebhook
+		*out = new(TransformationWebhook)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PropagationWebhook != nil {
+		in, out := &in.PropagationWebhook, &out.PropagationWebhook
+		*out = new(PropagationWebhook)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PropagationWindow != nil {
+		in, out := &in.PropagationWindow, &out.PropagationWindow
+		*out = new(PropagationWindow)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.FeatureGates != nil {
+		in, out := &in.FeatureGates, &out.FeatureGates
+		*out = make([]FeatureGatesConfig, len(*in))
+		copy(*out, *in)
+	}
+	if in.StatusAggregations != nil {
+		in, out := &in.StatusAggregations, &out.StatusAggregations
+		*out = make([]StatusAggregation, len(*in))
+		copy(*out, *in)
+	}
+	if in.Quota != nil {
+		in, out := &in.Quota, &out.Quota
+		*out = new(PlacementQuota)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RemovalStrategy != nil {
+		in, out := &in.RemovalStrategy, &out.RemovalStrategy
+		*out = new(RemovalStrategy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DeletionGracePeriod != nil {
+		in, out := &in.DeletionGracePeriod, &out.DeletionGracePeriod
+		*out = new(v1.Duration)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FederatedTypeConfigSpec.
@@ -244,6 +346,13 @@ func (in *FederatedTypeConfigStatus) DeepCopyInto(out *FederatedTypeConfigStatus
 		*out = new(ControllerStatus)
 		**out = **in
 	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]TypeConfigCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FederatedTypeConfigStatus.
@@ -329,6 +438,20 @@ func (in *KubeFedClusterSpec) DeepCopyInto(out *KubeFedClusterSpec) {
 		*out = make([]TLSValidation, len(*in))
 		copy(*out, *in)
 	}
+	if in.Taints != nil {
+		in, out := &in.Taints, &out.Taints
+		*out = make([]corev1.Taint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubeFedClusterSpec.
@@ -526,6 +649,168 @@ func (in *LocalSecretReference) DeepCopy() *LocalSecretReference {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PlacementQuota) DeepCopyInto(out *PlacementQuota) {
+	*out = *in
+	if in.MaxPerCluster != nil {
+		in, out := &in.MaxPerCluster, &out.MaxPerCluster
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxPerNamespace != nil {
+		in, out := &in.MaxPerNamespace, &out.MaxPerNamespace
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlacementQuota.
+func (in *PlacementQuota) DeepCopy() *PlacementQuota {
+	if in == nil {
+		return nil
+	}
+	out := new(PlacementQuota)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PropagationWebhook) DeepCopyInto(out *PropagationWebhook) {
+	*out = *in
+	if in.CABundle != nil {
+		in, out := &in.CABundle, &out.CABundle
+		*out = make([]byte, len(*in))
+		copy(*out, *in)
+	}
+	if in.TimeoutSeconds != nil {
+		in, out := &in.TimeoutSeconds, &out.TimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.SigningSecretRef != nil {
+		in, out := &in.SigningSecretRef, &out.SigningSecretRef
+		*out = new(LocalSecretReference)
+		**out = **in
+	}
+	if in.Retries != nil {
+		in, out := &in.Retries, &out.Retries
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PropagationWebhook.
+func (in *PropagationWebhook) DeepCopy() *PropagationWebhook {
+	if in == nil {
+		return nil
+	}
+	out := new(PropagationWebhook)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PropagationWindow) DeepCopyInto(out *PropagationWindow) {
+	*out = *in
+	if in.Days != nil {
+		in, out := &in.Days, &out.Days
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	out.Duration = in.Duration
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PropagationWindow.
+func (in *PropagationWindow) DeepCopy() *PropagationWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(PropagationWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemovalStrategy) DeepCopyInto(out *RemovalStrategy) {
+	*out = *in
+	if in.PreDeletePatch != nil {
+		in, out := &in.PreDeletePatch, &out.PreDeletePatch
+		*out = new(apiextensionsv1.JSON)
+		(*in).DeepCopyInto(*out)
+	}
+	out.DrainDuration = in.DrainDuration
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemovalStrategy.
+func (in *RemovalStrategy) DeepCopy() *RemovalStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(RemovalStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceAdoptionPolicy) DeepCopyInto(out *ResourceAdoptionPolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceAdoptionPolicy.
+func (in *ResourceAdoptionPolicy) DeepCopy() *ResourceAdoptionPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceAdoptionPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServerManagedMetadata) DeepCopyInto(out *ServerManagedMetadata) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Finalizers != nil {
+		in, out := &in.Finalizers, &out.Finalizers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServerManagedMetadata.
+func (in *ServerManagedMetadata) DeepCopy() *ServerManagedMetadata {
+	if in == nil {
+		return nil
+	}
+	out := new(ServerManagedMetadata)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StatusAggregation) DeepCopyInto(out *StatusAggregation) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StatusAggregation.
+func (in *StatusAggregation) DeepCopy() *StatusAggregation {
+	if in == nil {
+		return nil
+	}
+	out := new(StatusAggregation)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StatusControllerConfig) DeepCopyInto(out *StatusControllerConfig) {
 	*out = *in
@@ -559,6 +844,59 @@ func (in *SyncControllerConfig) DeepCopyInto(out *SyncControllerConfig) {
 		*out = new(ResourceAdoption)
 		**out = **in
 	}
+	if in.AdoptionPolicy != nil {
+		in, out := &in.AdoptionPolicy, &out.AdoptionPolicy
+		*out = new(ResourceAdoptionPolicy)
+		**out = **in
+	}
+	if in.ApplyOrder != nil {
+		in, out := &in.ApplyOrder, &out.ApplyOrder
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ManagedLabels != nil {
+		in, out := &in.ManagedLabels, &out.ManagedLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ManagedAnnotations != nil {
+		in, out := &in.ManagedAnnotations, &out.ManagedAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.CreatedNamespaceLabels != nil {
+		in, out := &in.CreatedNamespaceLabels, &out.CreatedNamespaceLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.CreatedNamespaceAnnotations != nil {
+		in, out := &in.CreatedNamespaceAnnotations, &out.CreatedNamespaceAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.DeleteEmptyNamespaces != nil {
+		in, out := &in.DeleteEmptyNamespaces, &out.DeleteEmptyNamespaces
+		*out = new(EmptyNamespaceDeletion)
+		**out = **in
+	}
+	if in.PruneRemovedClusters != nil {
+		in, out := &in.PruneRemovedClusters, &out.PruneRemovedClusters
+		*out = new(RemovedClusterPruning)
+		**out = **in
+	}
+	if in.ClusterEviction != nil {
+		in, out := &in.ClusterEviction, &out.ClusterEviction
+		*out = new(ClusterEvictionConfig)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyncControllerConfig.
@@ -570,3 +908,47 @@ func (in *SyncControllerConfig) DeepCopy() *SyncControllerConfig {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TransformationWebhook) DeepCopyInto(out *TransformationWebhook) {
+	*out = *in
+	if in.CABundle != nil {
+		in, out := &in.CABundle, &out.CABundle
+		*out = make([]byte, len(*in))
+		copy(*out, *in)
+	}
+	if in.TimeoutSeconds != nil {
+		in, out := &in.TimeoutSeconds, &out.TimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TransformationWebhook.
+func (in *TransformationWebhook) DeepCopy() *TransformationWebhook {
+	if in == nil {
+		return nil
+	}
+	out := new(TransformationWebhook)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TypeConfigCondition) DeepCopyInto(out *TypeConfigCondition) {
+	*out = *in
+	if in.LastTransitionTime != nil {
+		in, out := &in.LastTransitionTime, &out.LastTransitionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TypeConfigCondition.
+func (in *TypeConfigCondition) DeepCopy() *TypeConfigCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(TypeConfigCondition)
+	in.DeepCopyInto(out)
+	return out
+}
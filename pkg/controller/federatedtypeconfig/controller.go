@@ -23,10 +23,14 @@ import (
 
 	"github.com/pkg/errors"
 
+	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
 	restclient "k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/component-base/featuregate"
 	"k8s.io/klog/v2"
 	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -36,11 +40,19 @@ import (
 	statuscontroller "sigs.k8s.io/kubefed/pkg/controller/status"
 	synccontroller "sigs.k8s.io/kubefed/pkg/controller/sync"
 	"sigs.k8s.io/kubefed/pkg/controller/utils"
+	"sigs.k8s.io/kubefed/pkg/features"
 	"sigs.k8s.io/kubefed/pkg/metrics"
 )
 
 const finalizer string = "core.kubefed.io/federated-type-config"
 
+// namespaceFTCGracePeriod is how long sync controllers of namespaced
+// types continue to run with the last known federated namespace type
+// after the FederatedTypeConfig for namespaces goes missing, so that
+// transient removal of the FederatedTypeConfig (e.g. during an
+// upgrade) does not stop propagation of all namespaced types.
+const namespaceFTCGracePeriod = 2 * time.Minute
+
 // Controller The FederatedTypeConfig controller configures sync and status
 // controllers in response to FederatedTypeConfig resources in the
 // KubeFed system namespace.
@@ -54,11 +66,50 @@ type Controller struct {
 	stopChannels map[string]chan struct{}
 	lock         sync.RWMutex
 
+	// Map of running sync controllers keyed by the name of their
+	// FederatedTypeConfig
+	syncControllers map[string]*synccontroller.KubeFedSyncController
+
+	// Determines whether a member cluster serves the target type of a
+	// FederatedTypeConfig. The preflight of target types is skipped if
+	// nil.
+	targetTypeServed TargetTypeServedFunc
+	// Preflights of target types keyed by the name of the
+	// FederatedTypeConfig
+	targetTypePreflights map[string]*targetTypePreflight
+	// Names of the member clusters known to be ready
+	readyClusters sets.Set[string]
+
+	// Map of the last handled value of the reconcile-all annotation
+	// keyed by the name of the FederatedTypeConfig
+	reconcileAllRequests map[string]string
+
+	// The federated namespace type last found in the
+	// FederatedTypeConfig for namespaces and the time since which the
+	// FederatedTypeConfig has been missing, if it is missing.
+	namespaceAPIResource     *metav1.APIResource
+	namespaceFTCMissingSince time.Time
+	namespaceFTCGracePeriod  time.Duration
+
 	// Store for the FederatedTypeConfig objects
 	store cache.Store
 	// Informer for the FederatedTypeConfig objects
 	controller cache.Controller
 
+	// Whether propagation is paused for the control plane, as
+	// indicated by the annotation of its KubeFedConfig. Shared with
+	// the sync controllers.
+	propagationPause *utils.PropagationPause
+	// Store for the member clusters
+	clusterStore cache.Store
+	// Informer for the member clusters
+	clusterController cache.Controller
+
+	// Store for the KubeFedConfig of the control plane
+	kubeFedConfigStore cache.Store
+	// Informer for the KubeFedConfig of the control plane
+	kubeFedConfigController cache.Controller
+
 	worker utils.ReconcileWorker
 	// ctx is the context that governs the Manager's operations, allowing for graceful shutdowns or cancellations.
 	ctx context.Context
@@ -67,14 +118,14 @@ type Controller struct {
 }
 
 // StartController starts the Controller for managing FederatedTypeConfig objects.
-func StartController(config *utils.ControllerConfig, stopChan <-chan struct{}) error {
+func StartController(config *utils.ControllerConfig, stopChan <-chan struct{}) (*Controller, error) {
 	controller, err := newController(config)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	klog.Infof("Starting FederatedTypeConfig controller")
 	controller.Run(stopChan)
-	return nil
+	return controller, nil
 }
 
 // newController returns a new controller to manage FederatedTypeConfig objects.
@@ -88,9 +139,16 @@ func newController(config *utils.ControllerConfig) (*Controller, error) {
 	}
 
 	c := &Controller{
-		controllerConfig: config,
-		client:           genericClient,
-		stopChannels:     make(map[string]chan struct{}),
+		controllerConfig:        config,
+		client:                  genericClient,
+		stopChannels:            make(map[string]chan struct{}),
+		syncControllers:         make(map[string]*synccontroller.KubeFedSyncController),
+		reconcileAllRequests:    make(map[string]string),
+		namespaceFTCGracePeriod: namespaceFTCGracePeriod,
+		propagationPause:        &utils.PropagationPause{},
+		targetTypeServed:        NewTargetTypeServedFunc(genericClient, config.KubeFedNamespace, config.ClusterClientRateLimits),
+		targetTypePreflights:    make(map[string]*targetTypePreflight),
+		readyClusters:           sets.New[string](),
 	}
 
 	c.worker = utils.NewReconcileWorker("federatedtypeconfig", c.reconcile, utils.WorkerOptions{})
@@ -109,15 +167,46 @@ func newController(config *utils.ControllerConfig) (*Controller, error) {
 		return nil, err
 	}
 
+	c.clusterStore, c.clusterController, err = utils.NewGenericInformer(
+		kubeConfig,
+		config.KubeFedNamespace,
+		&corev1b1.KubeFedCluster{},
+		utils.NoResyncPeriod,
+		c.handleClusterChange,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	c.kubeFedConfigStore, c.kubeFedConfigController, err = utils.NewGenericInformer(
+		kubeConfig,
+		config.KubeFedNamespace,
+		&corev1b1.KubeFedConfig{},
+		utils.NoResyncPeriod,
+		func(obj runtimeclient.Object) {
+			if obj.GetName() == utils.KubeFedConfigName {
+				c.updatePropagationPause()
+			}
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
 	return c, nil
 }
 
-// Run runs the Controller.
+// Run runs the Controller. The sync controllers it starts and the
+// operations initiated by reconciliation are cancelled when the stop
+// channel is closed.
 func (c *Controller) Run(stopChan <-chan struct{}) {
+	c.ctx = wait.ContextForChannel(stopChan)
 	go c.controller.Run(stopChan)
+	go c.clusterController.Run(stopChan)
+	go c.kubeFedConfigController.Run(stopChan)
 
 	// wait for the caches to synchronize before starting the worker
-	if !cache.WaitForCacheSync(stopChan, c.controller.HasSynced) {
+	if !cache.WaitForCacheSync(stopChan, c.controller.HasSynced, c.clusterController.HasSynced, c.kubeFedConfigController.HasSynced) {
 		runtime.HandleError(errors.New("Timed out waiting for cache to sync"))
 		return
 	}
@@ -143,9 +232,15 @@ func (c *Controller) reconcile(qualifiedName utils.QualifiedName) utils.Reconcil
 	}
 
 	if cachedObj == nil {
+		if qualifiedName.Name == utils.NamespaceName {
+			// Ensure that the sync controllers of namespaced types
+			// observe the removal of the namespace FTC.
+			c.reconcileOnNamespaceFTCUpdate()
+		}
 		return utils.StatusAllOK
 	}
 	typeConfig := cachedObj.(*corev1b1.FederatedTypeConfig)
+	originalStatus := typeConfig.Status.DeepCopy()
 
 	// TODO(marun) Perform this defaulting in a webhook
 	corev1b1.SetFederatedTypeConfigDefaults(typeConfig)
@@ -154,7 +249,7 @@ func (c *Controller) reconcile(qualifiedName utils.QualifiedName) utils.Reconcil
 	// NOTE (Hector): RawResourceStatusCollection is a new feature and is
 	// Disabled by default. When RawResourceStatusCollection is enabled,
 	// the old mechanism to collect the service status of FederatedServices would be disabled.
-	statusControllerEnabled := !c.controllerConfig.RawResourceStatusCollection && c.isEnabledFederatedServiceStatusCollection(typeConfig)
+	statusControllerEnabled := !c.controllerConfigForType(typeConfig).RawResourceStatusCollection && c.isEnabledFederatedServiceStatusCollection(typeConfig)
 
 	limitedScope := c.controllerConfig.TargetNamespace != metav1.NamespaceAll
 	if limitedScope && syncEnabled && !typeConfig.GetNamespaced() {
@@ -174,7 +269,7 @@ func (c *Controller) reconcile(qualifiedName utils.QualifiedName) utils.Reconcil
 			typeConfig.Status.StatusController = new(corev1b1.ControllerStatus)
 		}
 		*typeConfig.Status.StatusController = corev1b1.ControllerStatusNotRunning
-		err = c.client.UpdateStatus(context.TODO(), typeConfig)
+		err = c.updateStatus(typeConfig, originalStatus)
 		if err != nil {
 			runtime.HandleError(errors.Wrapf(err, "Could not update status fields of the CRD: %q", key))
 			return utils.StatusError
@@ -222,6 +317,32 @@ func (c *Controller) reconcile(qualifiedName utils.QualifiedName) utils.Reconcil
 
 	startNewSyncController := !syncRunning && syncEnabled
 	stopSyncController := syncRunning && (!syncEnabled || (typeConfig.GetNamespaced() && !c.namespaceFTCExists()))
+	syncControllerRunning := startNewSyncController || (syncRunning && !stopSyncController)
+	reconcileAllRequested := syncRunning && !stopSyncController && c.reconcileAllRequested(typeConfig)
+
+	// Verify that member clusters serve the target type when the sync
+	// controller is started or its configuration changes, so that
+	// version skew is reported for the type rather than by the
+	// failure to propagate each resource. A request to reconcile all
+	// resources of the type also repeats the verification. The
+	// verification runs in the background and its result is recorded
+	// when the FederatedTypeConfig is reconciled again.
+	if !syncControllerRunning {
+		c.discardTargetTypePreflight(typeConfig.Name)
+		removeTypeConfigCondition(&typeConfig.Status, corev1b1.TargetTypeServed)
+	} else if c.targetTypeServed != nil {
+		c.recordTargetTypePreflight(typeConfig)
+		if startNewSyncController || reconcileAllRequested ||
+			typeConfig.Status.ObservedGeneration != typeConfig.Generation ||
+			getTypeConfigCondition(&typeConfig.Status, corev1b1.TargetTypeServed) == nil {
+			c.requestTargetTypePreflight(typeConfig)
+		}
+	}
+
+	if syncControllerRunning && typeConfig.GetNamespaced() && !typeConfig.IsNamespace() {
+		c.reconcileAfterNamespaceFTCGracePeriod(typeConfig)
+	}
+
 	if startNewSyncController {
 		if err = c.startSyncController(c.ctx, c.immediate, typeConfig); err != nil {
 			runtime.HandleError(err)
@@ -240,6 +361,12 @@ func (c *Controller) reconcile(qualifiedName utils.QualifiedName) utils.Reconcil
 		}
 	} else if stopStatusController {
 		c.stopController(statusKey, statusStopChan)
+	} else if statusRunning && typeConfig.Status.ObservedGeneration != typeConfig.Generation {
+		// The feature gates of the type may have changed.
+		if err = c.refreshStatusController(statusKey, typeConfig); err != nil {
+			runtime.HandleError(err)
+			return utils.StatusError
+		}
 	}
 
 	if !startNewSyncController && !stopSyncController &&
@@ -250,13 +377,17 @@ func (c *Controller) reconcile(qualifiedName utils.QualifiedName) utils.Reconcil
 		}
 	}
 
+	if syncControllerRunning {
+		c.handleReconcileAllRequest(typeConfig)
+	}
+
 	typeConfig.Status.ObservedGeneration = typeConfig.Generation
-	syncControllerRunning := startNewSyncController || (syncRunning && !stopSyncController)
 	if syncControllerRunning {
 		typeConfig.Status.PropagationController = corev1b1.ControllerStatusRunning
 	} else {
 		typeConfig.Status.PropagationController = corev1b1.ControllerStatusNotRunning
 	}
+	typeConfig.Status.PropagationPaused = syncControllerRunning && c.propagationPause.Paused()
 
 	if typeConfig.Status.StatusController == nil {
 		typeConfig.Status.StatusController = new(corev1b1.ControllerStatus)
@@ -268,7 +399,7 @@ func (c *Controller) reconcile(qualifiedName utils.QualifiedName) utils.Reconcil
 	} else {
 		*typeConfig.Status.StatusController = corev1b1.ControllerStatusNotRunning
 	}
-	err = c.client.UpdateStatus(context.TODO(), typeConfig)
+	err = c.updateStatus(typeConfig, originalStatus)
 	if err != nil {
 		runtime.HandleError(errors.Wrapf(err, "Could not update status fields of the CRD: %q", key))
 		return utils.StatusError
@@ -276,6 +407,17 @@ func (c *Controller) reconcile(qualifiedName utils.QualifiedName) utils.Reconcil
 	return utils.StatusAllOK
 }
 
+// updateStatus writes the status of the given FederatedTypeConfig
+// unless it is unchanged from the original status, so that resyncs of
+// a stable FederatedTypeConfig do not result in needless writes.
+func (c *Controller) updateStatus(tc *corev1b1.FederatedTypeConfig, originalStatus *corev1b1.FederatedTypeConfigStatus) error {
+	if equality.Semantic.DeepEqual(&tc.Status, originalStatus) {
+		klog.V(4).Infof("Status of FederatedTypeConfig %q is unchanged, skipping update", tc.Name)
+		return nil
+	}
+	return c.client.UpdateStatus(c.ctx, tc)
+}
+
 func (c *Controller) objCopyFromCache(key string) (runtimeclient.Object, error) {
 	cachedObj, exist, err := c.store.GetByKey(key)
 	if err != nil {
@@ -331,7 +473,7 @@ func (c *Controller) startSyncController(ctx context.Context, immediate bool, tc
 	}
 
 	stopChan := make(chan struct{})
-	err := synccontroller.StartKubeFedSyncController(ctx, immediate, c.controllerConfig, stopChan, ftc, fedNamespaceAPIResource)
+	syncController, err := synccontroller.StartKubeFedSyncController(ctx, immediate, c.controllerConfigForType(ftc), stopChan, ftc, fedNamespaceAPIResource)
 	if err != nil {
 		close(stopChan)
 		return errors.Wrapf(err, "Error starting sync controller for %q", kind)
@@ -340,6 +482,10 @@ func (c *Controller) startSyncController(ctx context.Context, immediate bool, tc
 	c.lock.Lock()
 	defer c.lock.Unlock()
 	c.stopChannels[ftc.Name] = stopChan
+	c.syncControllers[ftc.Name] = syncController
+	// A newly started sync controller reconciles all federated
+	// resources of the type, so a pending request is satisfied.
+	c.reconcileAllRequests[ftc.Name] = utils.GetReconcileAllRequest(ftc)
 	return nil
 }
 
@@ -347,7 +493,7 @@ func (c *Controller) startStatusController(statusKey string, tc *corev1b1.Federa
 	kind := tc.Spec.FederatedType.Kind
 	stopChan := make(chan struct{})
 	ftc := tc.DeepCopyObject().(*corev1b1.FederatedTypeConfig)
-	err := statuscontroller.StartKubeFedStatusController(c.controllerConfig, stopChan, ftc)
+	err := statuscontroller.StartKubeFedStatusController(c.controllerConfigForType(ftc), stopChan, ftc)
 	if err != nil {
 		close(stopChan)
 		return errors.Wrapf(err, "Error starting status controller for %q", kind)
@@ -365,6 +511,40 @@ func (c *Controller) stopController(key string, stopChan chan struct{}) {
 	c.lock.Lock()
 	defer c.lock.Unlock()
 	delete(c.stopChannels, key)
+	delete(c.syncControllers, key)
+	delete(c.reconcileAllRequests, key)
+}
+
+// handleReconcileAllRequest triggers reconciliation of all federated
+// resources of the type if the value of the reconcile-all annotation
+// of the FederatedTypeConfig has changed since it was last handled.
+func (c *Controller) handleReconcileAllRequest(tc *corev1b1.FederatedTypeConfig) {
+	request := utils.GetReconcileAllRequest(tc)
+
+	c.lock.Lock()
+	syncController, ok := c.syncControllers[tc.Name]
+	if !ok || request == c.reconcileAllRequests[tc.Name] {
+		c.lock.Unlock()
+		return
+	}
+	c.reconcileAllRequests[tc.Name] = request
+	c.lock.Unlock()
+
+	if len(request) == 0 {
+		return
+	}
+	count := syncController.ReconcileAll()
+	klog.Infof("Enqueued %d %s resources for reconciliation as requested by the %q annotation", count, tc.GetFederatedType().Kind, utils.ReconcileAllAnnotation)
+}
+
+// reconcileAllRequested returns whether the value of the reconcile-all
+// annotation of the FederatedTypeConfig has changed since it was last
+// handled.
+func (c *Controller) reconcileAllRequested(tc *corev1b1.FederatedTypeConfig) bool {
+	request := utils.GetReconcileAllRequest(tc)
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return len(request) > 0 && request != c.reconcileAllRequests[tc.Name]
 }
 
 func (c *Controller) refreshSyncController(ctx context.Context, immediate bool, tc *corev1b1.FederatedTypeConfig) error {
@@ -378,6 +558,91 @@ func (c *Controller) refreshSyncController(ctx context.Context, immediate bool,
 	return c.startSyncController(ctx, immediate, tc)
 }
 
+func (c *Controller) refreshStatusController(statusKey string, tc *corev1b1.FederatedTypeConfig) error {
+	klog.Infof("refreshing status controller for %q", tc.Name)
+
+	statusStopChan, ok := c.getStopChannel(statusKey)
+	if ok {
+		c.stopController(statusKey, statusStopChan)
+	}
+
+	return c.startStatusController(statusKey, tc)
+}
+
+// updatePropagationPause records whether propagation is paused for the
+// control plane according to its KubeFedConfig. A change is reflected
+// in the status of all FederatedTypeConfigs, and all federated
+// resources are reconciled when propagation is resumed.
+func (c *Controller) updatePropagationPause() {
+	qualifiedName := utils.QualifiedName{
+		Namespace: c.controllerConfig.KubeFedNamespace,
+		Name:      utils.KubeFedConfigName,
+	}
+	key := qualifiedName.String()
+	cachedObj, exists, err := c.kubeFedConfigStore.GetByKey(key)
+	if err != nil {
+		runtime.HandleError(errors.Wrapf(err, "Failed to query KubeFedConfig store for %q", key))
+		return
+	}
+	paused := exists && utils.IsPropagationPaused(cachedObj.(*corev1b1.KubeFedConfig))
+	if !c.propagationPause.Set(paused) {
+		return
+	}
+
+	if paused {
+		klog.Infof("Propagation to member clusters has been paused by the %q annotation of KubeFedConfig %q", utils.PropagationPausedAnnotation, key)
+	} else {
+		klog.Infof("Propagation to member clusters has been resumed")
+		c.lock.RLock()
+		syncControllers := make([]*synccontroller.KubeFedSyncController, 0, len(c.syncControllers))
+		for _, syncController := range c.syncControllers {
+			syncControllers = append(syncControllers, syncController)
+		}
+		c.lock.RUnlock()
+		for _, syncController := range syncControllers {
+			syncController.ReconcileAll()
+		}
+	}
+
+	for _, cachedObj := range c.store.List() {
+		c.worker.EnqueueObject(cachedObj.(*corev1b1.FederatedTypeConfig))
+	}
+}
+
+// controllerConfigForType returns the configuration of the controllers
+// of the given type, with the feature gates overridden by the type
+// taking precedence over those of the control plane.
+func (c *Controller) controllerConfigForType(tc *corev1b1.FederatedTypeConfig) *utils.ControllerConfig {
+	controllerConfig := ControllerConfigForType(c.controllerConfig, tc)
+	controllerConfig.PropagationPause = c.propagationPause
+	return controllerConfig
+}
+
+// ControllerConfigForType returns a copy of the given configuration
+// with the settings and feature gates configured by the given type
+// taking precedence.
+func ControllerConfigForType(config *utils.ControllerConfig, tc *corev1b1.FederatedTypeConfig) *utils.ControllerConfig {
+	controllerConfig := *config
+	if interval := tc.GetStatusCollectionInterval(); interval != nil {
+		controllerConfig.StatusCollectionInterval = interval.Duration
+	}
+	if interval := tc.GetDriftDetectionInterval(); interval != nil {
+		controllerConfig.DriftDetectionInterval = interval.Duration
+	}
+	for _, gate := range tc.Spec.FeatureGates {
+		enabled := gate.Configuration == corev1b1.ConfigurationEnabled
+		switch featuregate.Feature(gate.Name) {
+		case features.RawResourceStatusCollection:
+			controllerConfig.RawResourceStatusCollection = enabled
+		case features.StatusFeedback:
+			controllerConfig.StatusFeedback = enabled
+		case features.MetadataMerge:
+			controllerConfig.MetadataMerge = enabled
+		}
+	}
+	return &controllerConfig
+}
+
 func (c *Controller) ensureFinalizer(tc *corev1b1.FederatedTypeConfig) (bool, error) {
 	if controllerutil.ContainsFinalizer(tc, finalizer) {
 		return false, nil
@@ -385,7 +650,7 @@ func (c *Controller) ensureFinalizer(tc *corev1b1.FederatedTypeConfig) (bool, er
 
 	patch := runtimeclient.MergeFrom(tc.DeepCopy())
 	controllerutil.AddFinalizer(tc, finalizer)
-	return true, c.client.Patch(context.TODO(), tc, patch)
+	return true, c.client.Patch(c.ctx, tc, patch)
 }
 
 func (c *Controller) removeFinalizer(tc *corev1b1.FederatedTypeConfig) error {
@@ -395,7 +660,7 @@ func (c *Controller) removeFinalizer(tc *corev1b1.FederatedTypeConfig) error {
 
 	patch := runtimeclient.MergeFrom(tc.DeepCopy())
 	controllerutil.RemoveFinalizer(tc, finalizer)
-	return c.client.Patch(context.TODO(), tc, patch)
+	return c.client.Patch(c.ctx, tc, patch)
 }
 
 func (c *Controller) namespaceFTCExists() bool {
@@ -403,6 +668,10 @@ func (c *Controller) namespaceFTCExists() bool {
 	return err == nil
 }
 
+// getFederatedNamespaceAPIResource returns the federated type of the
+// FederatedTypeConfig for namespaces. If the FederatedTypeConfig is
+// missing, the last known federated type is returned until the grace
+// period since the FederatedTypeConfig went missing has elapsed.
 func (c *Controller) getFederatedNamespaceAPIResource() (*metav1.APIResource, error) {
 	qualifiedName := utils.QualifiedName{
 		Namespace: c.controllerConfig.KubeFedNamespace,
@@ -413,12 +682,36 @@ func (c *Controller) getFederatedNamespaceAPIResource() (*metav1.APIResource, er
 	if err != nil {
 		return nil, errors.Wrapf(err, "Error retrieving %q from the informer cache", key)
 	}
-	if !exists {
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if exists {
+		if !c.namespaceFTCMissingSince.IsZero() {
+			klog.Infof("FederatedTypeConfig %q is available again", key)
+			c.namespaceFTCMissingSince = time.Time{}
+		}
+		namespaceTypeConfig := cachedObj.(*corev1b1.FederatedTypeConfig)
+		apiResource := namespaceTypeConfig.GetFederatedType()
+		c.namespaceAPIResource = &apiResource
+		return &apiResource, nil
+	}
+
+	if c.namespaceAPIResource == nil {
 		return nil, errors.Errorf("Unable to find %q in the informer cache", key)
 	}
-	namespaceTypeConfig := cachedObj.(*corev1b1.FederatedTypeConfig)
-	apiResource := namespaceTypeConfig.GetFederatedType()
-	return &apiResource, nil
+	if c.namespaceFTCMissingSince.IsZero() {
+		c.namespaceFTCMissingSince = time.Now()
+		klog.Warningf("Unable to find %q in the informer cache. Sync controllers of namespaced types will continue to run for up to %v before being stopped.", key, c.namespaceFTCGracePeriod)
+		// Ensure that the sync controllers of namespaced types are
+		// stopped if the FederatedTypeConfig is still missing once
+		// the grace period has elapsed.
+		c.reconcileNamespacedTypesAfter(c.namespaceFTCGracePeriod)
+	}
+	if time.Since(c.namespaceFTCMissingSince) < c.namespaceFTCGracePeriod {
+		return c.namespaceAPIResource, nil
+	}
+	return nil, errors.Errorf("Unable to find %q in the informer cache for longer than %v", key, c.namespaceFTCGracePeriod)
 }
 
 func (c *Controller) reconcileOnNamespaceFTCUpdate() {
@@ -430,6 +723,34 @@ func (c *Controller) reconcileOnNamespaceFTCUpdate() {
 	}
 }
 
+func (c *Controller) reconcileNamespacedTypesAfter(delay time.Duration) {
+	for _, cachedObj := range c.store.List() {
+		typeConfig := cachedObj.(*corev1b1.FederatedTypeConfig)
+		if typeConfig.GetNamespaced() && !typeConfig.IsNamespace() {
+			c.worker.EnqueueWithDelay(utils.NewQualifiedName(typeConfig), delay)
+		}
+	}
+}
+
+// reconcileAfterNamespaceFTCGracePeriod ensures that the given
+// FederatedTypeConfig of a namespaced type is reconciled once the grace
+// period of the missing FederatedTypeConfig for namespaces has
+// elapsed, if it is missing. Since any enqueue of the
+// FederatedTypeConfig replaces its delayed reconciliation, the
+// reconciliation is scheduled again each time the FederatedTypeConfig
+// is reconciled during the grace period.
+func (c *Controller) reconcileAfterNamespaceFTCGracePeriod(tc *corev1b1.FederatedTypeConfig) {
+	c.lock.RLock()
+	missingSince := c.namespaceFTCMissingSince
+	c.lock.RUnlock()
+	if missingSince.IsZero() {
+		return
+	}
+	if remaining := c.namespaceFTCGracePeriod - time.Since(missingSince); remaining > 0 {
+		c.worker.EnqueueWithDelay(utils.NewQualifiedName(tc), remaining)
+	}
+}
+
 func (c *Controller) isEnabledFederatedServiceStatusCollection(tc *corev1b1.FederatedTypeConfig) bool {
 	if tc.GetStatusEnabled() && tc.Name == "services" {
 		federatedAPIResource := tc.GetFederatedType()
@@ -0,0 +1,377 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package federatedtypeconfig
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	corev1b1 "sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
+	genericclient "sigs.k8s.io/kubefed/pkg/client/generic"
+	synccontroller "sigs.k8s.io/kubefed/pkg/controller/sync"
+	"sigs.k8s.io/kubefed/pkg/controller/utils"
+	"sigs.k8s.io/kubefed/pkg/features"
+)
+
+// delayRecordingWorker records the delays of the reconciliations
+// enqueued through it. Methods that are not overridden panic via the
+// nil embedded interface.
+type delayRecordingWorker struct {
+	utils.ReconcileWorker
+	delays map[utils.QualifiedName]time.Duration
+}
+
+func (w *delayRecordingWorker) EnqueueWithDelay(qualifiedName utils.QualifiedName, delay time.Duration) {
+	w.delays[qualifiedName] = delay
+}
+
+// enqueueRecordingWorker records the objects enqueued through it.
+// Methods that are not overridden panic via the nil embedded
+// interface.
+type enqueueRecordingWorker struct {
+	utils.ReconcileWorker
+	enqueued []utils.QualifiedName
+}
+
+func (w *enqueueRecordingWorker) EnqueueObject(obj runtimeclient.Object) {
+	w.enqueued = append(w.enqueued, utils.NewQualifiedName(obj))
+}
+
+// statusUpdateRecordingClient records the status updates made through
+// it. Methods that are not overridden panic via the nil embedded
+// interface.
+type statusUpdateRecordingClient struct {
+	genericclient.Client
+	statusUpdates int
+}
+
+func (c *statusUpdateRecordingClient) UpdateStatus(ctx context.Context, obj runtimeclient.Object) error {
+	c.statusUpdates++
+	return nil
+}
+
+func TestFederatedNamespaceAPIResourceGracePeriod(t *testing.T) {
+	store := cache.NewStore(cache.MetaNamespaceKeyFunc)
+	namespaceTypeConfig := newTypeConfig(utils.NamespaceName, "FederatedNamespace", apiextv1.ClusterScoped, 1, corev1b1.FederatedTypeConfigStatus{})
+	configMapTypeConfig := newTypeConfig("configmaps", "FederatedConfigMap", apiextv1.NamespaceScoped, 1, corev1b1.FederatedTypeConfigStatus{})
+	for _, typeConfig := range []*corev1b1.FederatedTypeConfig{namespaceTypeConfig, configMapTypeConfig} {
+		if err := store.Add(typeConfig); err != nil {
+			t.Fatalf("Unexpected error adding to store: %v", err)
+		}
+	}
+	worker := &delayRecordingWorker{delays: make(map[utils.QualifiedName]time.Duration)}
+	c := &Controller{
+		controllerConfig: &utils.ControllerConfig{
+			KubeFedNamespaces: utils.KubeFedNamespaces{KubeFedNamespace: "kube-federation-system"},
+		},
+		store:                   store,
+		worker:                  worker,
+		namespaceFTCGracePeriod: time.Hour,
+	}
+
+	apiResource, err := c.getFederatedNamespaceAPIResource()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if apiResource.Kind != "FederatedNamespace" {
+		t.Fatalf("Expected kind %q, got %q", "FederatedNamespace", apiResource.Kind)
+	}
+
+	// Removal of the namespace FTC within the grace period
+	if err := store.Delete(namespaceTypeConfig); err != nil {
+		t.Fatalf("Unexpected error removing from store: %v", err)
+	}
+	apiResource, err = c.getFederatedNamespaceAPIResource()
+	if err != nil {
+		t.Fatalf("Expected the last known API resource during the grace period, got error: %v", err)
+	}
+	if apiResource.Kind != "FederatedNamespace" {
+		t.Fatalf("Expected kind %q, got %q", "FederatedNamespace", apiResource.Kind)
+	}
+	configMapName := utils.NewQualifiedName(configMapTypeConfig)
+	if delay, ok := worker.delays[configMapName]; !ok || delay != time.Hour {
+		t.Fatalf("Expected %q to be enqueued for reconciliation after the grace period, got %v", configMapName, worker.delays)
+	}
+
+	// An enqueue of the FTC replaces its delayed reconciliation, which
+	// is scheduled again when the FTC is reconciled within the grace
+	// period.
+	delete(worker.delays, configMapName)
+	c.reconcileAfterNamespaceFTCGracePeriod(configMapTypeConfig)
+	if delay, ok := worker.delays[configMapName]; !ok || delay <= 0 || delay > time.Hour {
+		t.Fatalf("Expected %q to be enqueued for reconciliation once the grace period elapses, got %v", configMapName, worker.delays)
+	}
+
+	// Reappearance of the namespace FTC
+	if err := store.Add(namespaceTypeConfig); err != nil {
+		t.Fatalf("Unexpected error adding to store: %v", err)
+	}
+	if !c.namespaceFTCExists() {
+		t.Fatalf("Expected the namespace FTC to exist after reappearing")
+	}
+	if !c.namespaceFTCMissingSince.IsZero() {
+		t.Fatalf("Expected the grace period to be reset after the namespace FTC reappeared")
+	}
+	delete(worker.delays, configMapName)
+	c.reconcileAfterNamespaceFTCGracePeriod(configMapTypeConfig)
+	if delay, ok := worker.delays[configMapName]; ok {
+		t.Fatalf("Expected no reconciliation to be scheduled while the namespace FTC exists, got %v", delay)
+	}
+
+	// Removal of the namespace FTC beyond the grace period
+	c.namespaceFTCGracePeriod = 10 * time.Millisecond
+	if err := store.Delete(namespaceTypeConfig); err != nil {
+		t.Fatalf("Unexpected error removing from store: %v", err)
+	}
+	if !c.namespaceFTCExists() {
+		t.Fatalf("Expected the namespace FTC to be considered to exist during the grace period")
+	}
+	time.Sleep(2 * c.namespaceFTCGracePeriod)
+	if _, err := c.getFederatedNamespaceAPIResource(); err == nil {
+		t.Fatalf("Expected an error once the grace period has elapsed")
+	}
+	if c.namespaceFTCExists() {
+		t.Fatalf("Expected the namespace FTC to be considered missing once the grace period has elapsed")
+	}
+}
+
+func TestControllerConfigForType(t *testing.T) {
+	c := &Controller{
+		controllerConfig: &utils.ControllerConfig{
+			KubeFedNamespaces: utils.KubeFedNamespaces{KubeFedNamespace: "kube-federation-system"},
+			StatusFeedback:    true,
+		},
+	}
+	configMapTypeConfig := newTypeConfig("configmaps", "FederatedConfigMap", apiextv1.NamespaceScoped, 1, corev1b1.FederatedTypeConfigStatus{})
+	deploymentTypeConfig := newTypeConfig("deployments.apps", "FederatedDeployment", apiextv1.NamespaceScoped, 1, corev1b1.FederatedTypeConfigStatus{})
+	deploymentTypeConfig.Spec.FeatureGates = []corev1b1.FeatureGatesConfig{
+		{Name: string(features.RawResourceStatusCollection), Configuration: corev1b1.ConfigurationEnabled},
+		{Name: string(features.StatusFeedback), Configuration: corev1b1.ConfigurationDisabled},
+	}
+	deploymentTypeConfig.Spec.StatusCollectionInterval = &metav1.Duration{Duration: time.Minute}
+	deploymentTypeConfig.Spec.DriftDetectionInterval = &metav1.Duration{Duration: 5 * time.Minute}
+
+	testCases := map[string]struct {
+		typeConfig                  *corev1b1.FederatedTypeConfig
+		rawResourceStatusCollection bool
+		statusFeedback              bool
+		statusCollectionInterval    time.Duration
+		driftDetectionInterval      time.Duration
+	}{
+		"type without overrides": {
+			typeConfig:     configMapTypeConfig,
+			statusFeedback: true,
+		},
+		"type with overrides": {
+			typeConfig:                  deploymentTypeConfig,
+			rawResourceStatusCollection: true,
+			statusCollectionInterval:    time.Minute,
+			driftDetectionInterval:      5 * time.Minute,
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			controllerConfig := c.controllerConfigForType(tc.typeConfig)
+			if controllerConfig.RawResourceStatusCollection != tc.rawResourceStatusCollection {
+				t.Fatalf("Expected RawResourceStatusCollection to be %v", tc.rawResourceStatusCollection)
+			}
+			if controllerConfig.StatusFeedback != tc.statusFeedback {
+				t.Fatalf("Expected StatusFeedback to be %v", tc.statusFeedback)
+			}
+			if controllerConfig.StatusCollectionInterval != tc.statusCollectionInterval {
+				t.Fatalf("Expected StatusCollectionInterval to be %v, got %v", tc.statusCollectionInterval, controllerConfig.StatusCollectionInterval)
+			}
+			if controllerConfig.DriftDetectionInterval != tc.driftDetectionInterval {
+				t.Fatalf("Expected DriftDetectionInterval to be %v, got %v", tc.driftDetectionInterval, controllerConfig.DriftDetectionInterval)
+			}
+			if controllerConfig.KubeFedNamespace != c.controllerConfig.KubeFedNamespace {
+				t.Fatalf("Expected the remaining configuration to be that of the control plane")
+			}
+		})
+	}
+	if c.controllerConfig.RawResourceStatusCollection || !c.controllerConfig.StatusFeedback {
+		t.Fatalf("Expected the configuration of the control plane not to be modified")
+	}
+}
+
+func TestReconcileSkipsUnchangedStatus(t *testing.T) {
+	notRunning := corev1b1.ControllerStatusNotRunning
+	stableStatus := corev1b1.FederatedTypeConfigStatus{
+		ObservedGeneration:    2,
+		PropagationController: corev1b1.ControllerStatusNotRunning,
+		StatusController:      &notRunning,
+	}
+
+	testCases := map[string]struct {
+		scope           apiextv1.ResourceScope
+		propagation     corev1b1.PropagationMode
+		targetNamespace string
+		status          corev1b1.FederatedTypeConfigStatus
+		expectedUpdates int
+	}{
+		"no-op reconcile of a stable type": {
+			scope:           apiextv1.NamespaceScoped,
+			propagation:     corev1b1.PropagationDisabled,
+			status:          stableStatus,
+			expectedUpdates: 0,
+		},
+		"no-op reconcile of a cluster-scoped type in a namespaced control plane": {
+			scope:           apiextv1.ClusterScoped,
+			propagation:     corev1b1.PropagationEnabled,
+			targetNamespace: "foo",
+			status:          stableStatus,
+			expectedUpdates: 0,
+		},
+		"new generation": {
+			scope:           apiextv1.ClusterScoped,
+			propagation:     corev1b1.PropagationEnabled,
+			targetNamespace: "foo",
+			status: corev1b1.FederatedTypeConfigStatus{
+				ObservedGeneration:    1,
+				PropagationController: corev1b1.ControllerStatusNotRunning,
+				StatusController:      &notRunning,
+			},
+			expectedUpdates: 1,
+		},
+		"change of controller running-state": {
+			scope:       apiextv1.NamespaceScoped,
+			propagation: corev1b1.PropagationDisabled,
+			status: corev1b1.FederatedTypeConfigStatus{
+				ObservedGeneration:    2,
+				PropagationController: corev1b1.ControllerStatusRunning,
+				StatusController:      &notRunning,
+			},
+			expectedUpdates: 1,
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			typeConfig := newTypeConfig("configmaps", "FederatedConfigMap", tc.scope, 2, tc.status)
+			typeConfig.Spec.Propagation = tc.propagation
+			typeConfig.Finalizers = []string{finalizer}
+			store := cache.NewStore(cache.MetaNamespaceKeyFunc)
+			if err := store.Add(typeConfig); err != nil {
+				t.Fatalf("Unexpected error adding to store: %v", err)
+			}
+			client := &statusUpdateRecordingClient{}
+			c := &Controller{
+				controllerConfig: &utils.ControllerConfig{
+					KubeFedNamespaces: utils.KubeFedNamespaces{
+						KubeFedNamespace: "kube-federation-system",
+						TargetNamespace:  tc.targetNamespace,
+					},
+				},
+				client:       client,
+				stopChannels: make(map[string]chan struct{}),
+				store:        store,
+				ctx:          context.Background(),
+			}
+			if len(tc.targetNamespace) > 0 {
+				// The placeholder recorded when a cluster-scoped type
+				// was first skipped in a namespaced control plane.
+				c.stopChannels[typeConfig.Name] = make(chan struct{})
+			}
+
+			if status := c.reconcile(utils.NewQualifiedName(typeConfig)); status != utils.StatusAllOK {
+				t.Fatalf("Expected reconcile to succeed, got %v", status)
+			}
+			if client.statusUpdates != tc.expectedUpdates {
+				t.Fatalf("Expected %d status updates, got %d", tc.expectedUpdates, client.statusUpdates)
+			}
+		})
+	}
+}
+
+func TestUpdatePropagationPause(t *testing.T) {
+	store := cache.NewStore(cache.MetaNamespaceKeyFunc)
+	typeConfig := newTypeConfig("configmaps", "FederatedConfigMap", apiextv1.NamespaceScoped, 1, corev1b1.FederatedTypeConfigStatus{})
+	if err := store.Add(typeConfig); err != nil {
+		t.Fatalf("Unexpected error adding to store: %v", err)
+	}
+	kubeFedConfigStore := cache.NewStore(cache.MetaNamespaceKeyFunc)
+	kubeFedConfig := &corev1b1.KubeFedConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      utils.KubeFedConfigName,
+			Namespace: "kube-federation-system",
+		},
+	}
+	if err := kubeFedConfigStore.Add(kubeFedConfig); err != nil {
+		t.Fatalf("Unexpected error adding to store: %v", err)
+	}
+	worker := &enqueueRecordingWorker{}
+	c := &Controller{
+		controllerConfig: &utils.ControllerConfig{
+			KubeFedNamespaces: utils.KubeFedNamespaces{KubeFedNamespace: "kube-federation-system"},
+		},
+		syncControllers:    make(map[string]*synccontroller.KubeFedSyncController),
+		store:              store,
+		worker:             worker,
+		propagationPause:   &utils.PropagationPause{},
+		kubeFedConfigStore: kubeFedConfigStore,
+	}
+
+	steps := []struct {
+		description      string
+		annotations      map[string]string
+		expectedPaused   bool
+		expectedEnqueued int
+	}{
+		{
+			description:      "not paused without the annotation",
+			expectedEnqueued: 0,
+		},
+		{
+			description:      "paused by the annotation",
+			annotations:      map[string]string{utils.PropagationPausedAnnotation: utils.PropagationPausedValue},
+			expectedPaused:   true,
+			expectedEnqueued: 1,
+		},
+		{
+			description:      "unchanged pause",
+			annotations:      map[string]string{utils.PropagationPausedAnnotation: utils.PropagationPausedValue},
+			expectedPaused:   true,
+			expectedEnqueued: 1,
+		},
+		{
+			description:      "resumed by removal of the annotation",
+			expectedEnqueued: 2,
+		},
+	}
+	for _, step := range steps {
+		kubeFedConfig.SetAnnotations(step.annotations)
+		if err := kubeFedConfigStore.Update(kubeFedConfig.DeepCopy()); err != nil {
+			t.Fatalf("Unexpected error updating store: %v", err)
+		}
+		c.updatePropagationPause()
+		if c.propagationPause.Paused() != step.expectedPaused {
+			t.Fatalf("%s: expected paused to be %v", step.description, step.expectedPaused)
+		}
+		if len(worker.enqueued) != step.expectedEnqueued {
+			t.Fatalf("%s: expected %d FederatedTypeConfigs to have been enqueued for a status update, got %d", step.description, step.expectedEnqueued, len(worker.enqueued))
+		}
+	}
+
+	if controllerConfig := c.controllerConfigForType(typeConfig); controllerConfig.PropagationPause != c.propagationPause {
+		t.Fatalf("Expected the pause to be shared with the controllers of the type")
+	}
+}
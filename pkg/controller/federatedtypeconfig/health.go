@@ -0,0 +1,212 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package federatedtypeconfig
+
+import (
+	"encoding/json"
+	"net/http"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/kubefed/pkg/controller/sync/status"
+)
+
+// PropagationHealthPath is the path at which the controller manager
+// serves the propagation health of federated types.
+const PropagationHealthPath = "/propagation-health"
+
+// PropagationHealthCounts are the numbers of federated resources of a
+// type by health.
+type PropagationHealthCounts struct {
+	// Healthy is the number of resources whose propagation succeeded.
+	Healthy int `json:"healthy"`
+	// Failing is the number of resources whose propagation failed.
+	Failing int `json:"failing"`
+	// Pending is the number of resources whose propagation has not
+	// yet been reported.
+	Pending int `json:"pending"`
+}
+
+// TypePropagationHealth summarizes the propagation health of the
+// federated resources of a type.
+type TypePropagationHealth struct {
+	// Name is the name of the FederatedTypeConfig.
+	Name string `json:"name"`
+	// Kind is the kind of the federated type.
+	Kind string `json:"kind"`
+	// PropagationController is the state of the sync controller.
+	PropagationController string `json:"propagationController"`
+	// PropagationPaused indicates whether propagation is paused for
+	// the control plane.
+	PropagationPaused bool `json:"propagationPaused"`
+	// Resources counts the federated resources of the type according
+	// to their Propagation condition.
+	Resources PropagationHealthCounts `json:"resources"`
+	// Clusters counts the federated resources of the type placed in
+	// each cluster according to the propagation status reported for
+	// the cluster. Resources are not counted as pending for a cluster.
+	Clusters map[string]PropagationHealthCounts `json:"clusters"`
+}
+
+// PropagationHealth is the propagation health of all federated types.
+type PropagationHealth struct {
+	Types []TypePropagationHealth `json:"types"`
+}
+
+// FederatedResourceSource provides the cached federated resources of
+// the type configured by a FederatedTypeConfig.
+type FederatedResourceSource interface {
+	// VisitFederatedResources calls the given function for each
+	// federated resource of the named FederatedTypeConfig and returns
+	// whether the resources of the type are available.
+	VisitFederatedResources(typeConfigName string, visitFunc func(obj interface{})) bool
+}
+
+// VisitFederatedResources visits the federated resources cached by the
+// sync controller of the named FederatedTypeConfig, if it is running.
+func (c *Controller) VisitFederatedResources(typeConfigName string, visitFunc func(obj interface{})) bool {
+	c.lock.RLock()
+	syncController, ok := c.syncControllers[typeConfigName]
+	c.lock.RUnlock()
+	if !ok {
+		return false
+	}
+	syncController.VisitFederatedResources(visitFunc)
+	return true
+}
+
+// GetPropagationHealth returns the propagation health of every
+// FederatedTypeConfig in the given store, ordered by name, computed
+// from the status of the federated resources provided by the given
+// source. It reads from caches only and does not make any API calls.
+func GetPropagationHealth(store cache.Store, source FederatedResourceSource) *PropagationHealth {
+	health := &PropagationHealth{Types: []TypePropagationHealth{}}
+	for _, typeStatus := range ListFederatedTypeStatus(store) {
+		typeHealth := TypePropagationHealth{
+			Name:                  typeStatus.Name,
+			Kind:                  typeStatus.Kind,
+			PropagationController: string(typeStatus.PropagationController),
+			PropagationPaused:     typeStatus.PropagationPaused,
+			Clusters:              map[string]PropagationHealthCounts{},
+		}
+		source.VisitFederatedResources(typeStatus.Name, func(obj interface{}) {
+			fedObject, ok := obj.(*unstructured.Unstructured)
+			if !ok {
+				return
+			}
+			addResourceHealth(&typeHealth, fedObject)
+		})
+		health.Types = append(health.Types, typeHealth)
+	}
+	return health
+}
+
+func addResourceHealth(typeHealth *TypePropagationHealth, fedObject *unstructured.Unstructured) {
+	resource, err := status.DecodeGenericFederatedResource(fedObject)
+	if err != nil || resource.Status == nil {
+		typeHealth.Resources.Pending++
+		return
+	}
+
+	var propagationCondition *status.GenericCondition
+	canaryFailed := false
+	for _, condition := range resource.Status.Conditions {
+		if condition == nil {
+			continue
+		}
+		switch condition.Type {
+		case status.PropagationConditionType:
+			propagationCondition = condition
+		case status.CanaryHealthyConditionType:
+			canaryFailed = condition.Reason == status.CanaryFailed
+		}
+	}
+	switch {
+	case propagationCondition == nil || resource.Status.ObservedGeneration < fedObject.GetGeneration():
+		typeHealth.Resources.Pending++
+	case propagationCondition.Status == apiv1.ConditionTrue:
+		typeHealth.Resources.Healthy++
+	default:
+		typeHealth.Resources.Failing++
+	}
+
+	for _, cluster := range resource.Status.Clusters {
+		counts := typeHealth.Clusters[cluster.Name]
+		if clusterHealthy(cluster.Status, canaryFailed) {
+			counts.Healthy++
+		} else {
+			counts.Failing++
+		}
+		typeHealth.Clusters[cluster.Name] = counts
+	}
+}
+
+// clusterHealthy returns whether the given propagation status of a
+// cluster reflects the intended state of the resource in the cluster.
+// Statuses indicating that propagation was intentionally withheld are
+// not considered failures, except for waiting on canary clusters in
+// which the rollout failed since it will not proceed on its own.
+func clusterHealthy(propagationStatus status.PropagationStatus, canaryFailed bool) bool {
+	switch propagationStatus {
+	case status.ClusterPropagationOK, status.PlacementOnly, status.Maintenance, status.Deferred, status.Paused:
+		return true
+	case status.WaitingForCanary:
+		return !canaryFailed
+	default:
+		return false
+	}
+}
+
+type propagationHealthHandler struct {
+	store  cache.Store
+	source FederatedResourceSource
+}
+
+// NewPropagationHealthHandler returns a read-only http.Handler that
+// serves the propagation health of the FederatedTypeConfigs in the
+// given store as JSON, for monitoring that prefers polling an endpoint
+// over scraping metrics.
+func NewPropagationHealthHandler(store cache.Store, source FederatedResourceSource) http.Handler {
+	return &propagationHealthHandler{
+		store:  store,
+		source: source,
+	}
+}
+
+// PropagationHealthHandler returns a handler serving the propagation
+// health of the types known to the controller.
+func (c *Controller) PropagationHealthHandler() http.Handler {
+	return NewPropagationHealthHandler(c.store, c)
+}
+
+func (h *propagationHealthHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := json.Marshal(GetPropagationHealth(h.store, h.source))
+	if err != nil {
+		klog.Errorf("Error encoding propagation health: %v", err)
+		http.Error(w, "error encoding propagation health", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(body)
+}
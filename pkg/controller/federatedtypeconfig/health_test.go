@@ -0,0 +1,174 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package federatedtypeconfig
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/cache"
+
+	corev1b1 "sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
+)
+
+type fakeFederatedResourceSource map[string][]interface{}
+
+func (s fakeFederatedResourceSource) VisitFederatedResources(typeConfigName string, visitFunc func(obj interface{})) bool {
+	objs, ok := s[typeConfigName]
+	for _, obj := range objs {
+		visitFunc(obj)
+	}
+	return ok
+}
+
+func newFederatedResource(name string, generation int64, status map[string]interface{}) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "types.kubefed.io/v1beta1",
+		"kind":       "FederatedConfigMap",
+		"metadata": map[string]interface{}{
+			"name":       name,
+			"namespace":  "ns",
+			"generation": generation,
+		},
+	}}
+	if status != nil {
+		obj.Object["status"] = status
+	}
+	return obj
+}
+
+func propagationStatus(conditionStatus string, clusters ...map[string]interface{}) map[string]interface{} {
+	clusterList := []interface{}{}
+	for _, cluster := range clusters {
+		clusterList = append(clusterList, cluster)
+	}
+	return map[string]interface{}{
+		"observedGeneration": int64(1),
+		"conditions": []interface{}{
+			map[string]interface{}{"type": "Propagation", "status": conditionStatus},
+		},
+		"clusters": clusterList,
+	}
+}
+
+// canaryFailedStatus returns the status of a federated resource whose
+// rollout was halted by a failure in a canary cluster.
+func canaryFailedStatus(clusters ...map[string]interface{}) map[string]interface{} {
+	resourceStatus := propagationStatus("False", clusters...)
+	resourceStatus["conditions"] = append(resourceStatus["conditions"].([]interface{}),
+		map[string]interface{}{"type": "CanaryHealthy", "status": "False", "reason": "CanaryFailed"},
+	)
+	return resourceStatus
+}
+
+func TestPropagationHealthHandler(t *testing.T) {
+	store := cache.NewStore(cache.MetaNamespaceKeyFunc)
+	typeConfigs := []*corev1b1.FederatedTypeConfig{
+		newTypeConfig("configmaps", "FederatedConfigMap", apiextv1.NamespaceScoped, 1, corev1b1.FederatedTypeConfigStatus{
+			ObservedGeneration:    1,
+			PropagationController: corev1b1.ControllerStatusRunning,
+		}),
+		// The sync controller is not running
+		newTypeConfig("secrets", "FederatedSecret", apiextv1.NamespaceScoped, 1, corev1b1.FederatedTypeConfigStatus{
+			ObservedGeneration: 1,
+			PropagationPaused:  true,
+		}),
+	}
+	for _, typeConfig := range typeConfigs {
+		if err := store.Add(typeConfig); err != nil {
+			t.Fatalf("Unexpected error adding to store: %v", err)
+		}
+	}
+	source := fakeFederatedResourceSource{
+		"configmaps": {
+			newFederatedResource("healthy", 1, propagationStatus("True",
+				map[string]interface{}{"name": "cluster1"},
+				map[string]interface{}{"name": "cluster2"},
+			)),
+			newFederatedResource("failing", 1, propagationStatus("False",
+				map[string]interface{}{"name": "cluster1"},
+				map[string]interface{}{"name": "cluster2", "status": "CreationFailed"},
+			)),
+			newFederatedResource("deferred", 1, propagationStatus("True",
+				map[string]interface{}{"name": "cluster2", "status": "Deferred"},
+			)),
+			newFederatedResource("canaryFailed", 1, canaryFailedStatus(
+				map[string]interface{}{"name": "cluster1", "status": "CreationFailed"},
+				map[string]interface{}{"name": "cluster2", "status": "WaitingForCanary"},
+			)),
+			newFederatedResource("canaryPending", 1, propagationStatus("True",
+				map[string]interface{}{"name": "cluster1"},
+				map[string]interface{}{"name": "cluster2", "status": "WaitingForCanary"},
+			)),
+			newFederatedResource("unreconciled", 1, nil),
+			newFederatedResource("updated", 2, propagationStatus("True",
+				map[string]interface{}{"name": "cluster1"},
+			)),
+		},
+	}
+
+	handler := NewPropagationHealthHandler(store, source)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, PropagationHealthPath, nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, recorder.Code, recorder.Body.String())
+	}
+	if contentType := recorder.Header().Get("Content-Type"); contentType != "application/json" {
+		t.Fatalf("Expected content type %q, got %q", "application/json", contentType)
+	}
+
+	var health PropagationHealth
+	if err := json.Unmarshal(recorder.Body.Bytes(), &health); err != nil {
+		t.Fatalf("Unexpected error decoding response: %v", err)
+	}
+	expected := PropagationHealth{
+		Types: []TypePropagationHealth{
+			{
+				Name:                  "configmaps",
+				Kind:                  "FederatedConfigMap",
+				PropagationController: string(corev1b1.ControllerStatusRunning),
+				Resources:             PropagationHealthCounts{Healthy: 3, Failing: 2, Pending: 2},
+				Clusters: map[string]PropagationHealthCounts{
+					"cluster1": {Healthy: 4, Failing: 1},
+					"cluster2": {Healthy: 3, Failing: 2},
+				},
+			},
+			{
+				Name:                  "secrets",
+				Kind:                  "FederatedSecret",
+				PropagationController: string(corev1b1.ControllerStatusNotRunning),
+				PropagationPaused:     true,
+				Clusters:              map[string]PropagationHealthCounts{},
+			},
+		},
+	}
+	if !reflect.DeepEqual(expected, health) {
+		t.Fatalf("Expected health %#v, got %#v", expected, health)
+	}
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, PropagationHealthPath, nil))
+	if recorder.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("Expected status %d, got %d", http.StatusMethodNotAllowed, recorder.Code)
+	}
+}
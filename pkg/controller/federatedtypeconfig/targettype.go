@@ -0,0 +1,347 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package federatedtypeconfig
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	restclient "k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	corev1b1 "sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
+	genericclient "sigs.k8s.io/kubefed/pkg/client/generic"
+	"sigs.k8s.io/kubefed/pkg/controller/utils"
+)
+
+// TargetTypeServedFunc determines whether the given member cluster
+// serves the given target type.
+type TargetTypeServedFunc func(cluster *corev1b1.KubeFedCluster, apiResource metav1.APIResource) (bool, error)
+
+// NewTargetTypeServedFunc returns a function that determines whether a
+// member cluster serves a target type by discovery, using the
+// credentials of the cluster in the given KubeFed namespace and the
+// given rate limits. The configuration of a cluster is reused until
+// its spec changes or discovery with it fails, e.g. because its
+// credentials were rotated.
+func NewTargetTypeServedFunc(client genericclient.Client, kubeFedNamespace string, rateLimits utils.ClientRateLimits) TargetTypeServedFunc {
+	configs := &clusterConfigCache{configs: make(map[string]*cachedClusterConfig)}
+	return func(cluster *corev1b1.KubeFedCluster, apiResource metav1.APIResource) (bool, error) {
+		config, err := configs.get(cluster, func() (*restclient.Config, error) {
+			return utils.BuildRateLimitedClusterConfig(cluster, client, kubeFedNamespace, rateLimits)
+		})
+		if err != nil {
+			return false, err
+		}
+		served, err := utils.TargetTypeServed(config, apiResource, utils.DefaultPreflightTimeout)
+		if err != nil {
+			configs.invalidate(cluster.Name)
+		}
+		return served, err
+	}
+}
+
+// clusterConfigCache caches the client configuration of member
+// clusters by name.
+type clusterConfigCache struct {
+	lock    sync.Mutex
+	configs map[string]*cachedClusterConfig
+}
+
+type cachedClusterConfig struct {
+	uid        types.UID
+	generation int64
+	config     *restclient.Config
+}
+
+// get returns the cached configuration of the given cluster, building
+// it with the given function if none is cached for the current spec of
+// the cluster.
+func (c *clusterConfigCache) get(cluster *corev1b1.KubeFedCluster, buildFunc func() (*restclient.Config, error)) (*restclient.Config, error) {
+	c.lock.Lock()
+	cached, ok := c.configs[cluster.Name]
+	c.lock.Unlock()
+	if ok && cached.uid == cluster.UID && cached.generation == cluster.Generation {
+		return cached.config, nil
+	}
+
+	config, err := buildFunc()
+	if err != nil {
+		return nil, err
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.configs[cluster.Name] = &cachedClusterConfig{
+		uid:        cluster.UID,
+		generation: cluster.Generation,
+		config:     config,
+	}
+	return config, nil
+}
+
+func (c *clusterConfigCache) invalidate(clusterName string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	delete(c.configs, clusterName)
+}
+
+// PreflightTargetType returns the names of the ready member clusters
+// in the given KubeFed namespace that do not serve the target type of
+// the given FederatedTypeConfig at its configured version, and of
+// those whose discovery failed, each ordered by name. Clusters are
+// checked concurrently.
+func PreflightTargetType(ctx context.Context, client genericclient.Client, kubeFedNamespace string, tc *corev1b1.FederatedTypeConfig, servedFunc TargetTypeServedFunc) (unservedClusters, unreachableClusters []string, err error) {
+	clusterList := &corev1b1.KubeFedClusterList{}
+	if err := client.List(ctx, clusterList, kubeFedNamespace); err != nil {
+		return nil, nil, errors.Wrap(err, "Failed to list member clusters")
+	}
+
+	apiResource := tc.GetTargetType()
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+	unservedClusters = []string{}
+	unreachableClusters = []string{}
+	for i := range clusterList.Items {
+		cluster := &clusterList.Items[i]
+		if !utils.IsClusterReady(&cluster.Status) {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			served, err := servedFunc(cluster, apiResource)
+			lock.Lock()
+			defer lock.Unlock()
+			switch {
+			case err != nil:
+				klog.V(2).Infof("Unable to determine whether cluster %q serves %s: %v", cluster.Name, targetTypeString(apiResource), err)
+				unreachableClusters = append(unreachableClusters, cluster.Name)
+			case !served:
+				unservedClusters = append(unservedClusters, cluster.Name)
+			}
+		}()
+	}
+	wg.Wait()
+	sort.Strings(unservedClusters)
+	sort.Strings(unreachableClusters)
+	return unservedClusters, unreachableClusters, nil
+}
+
+// targetTypeServedCondition returns the TargetTypeServed condition
+// resulting from the preflight of the target type of the given
+// FederatedTypeConfig. The condition is Unknown if it could not be
+// determined whether a cluster serves the type, unless another
+// cluster is known not to.
+func (c *Controller) targetTypeServedCondition(tc *corev1b1.FederatedTypeConfig) corev1b1.TypeConfigCondition {
+	unservedClusters, unreachableClusters, err := PreflightTargetType(c.ctx, c.client, c.controllerConfig.KubeFedNamespace, tc, c.targetTypeServed)
+	condition := corev1b1.TypeConfigCondition{
+		Type:   corev1b1.TargetTypeServed,
+		Status: apiv1.ConditionTrue,
+	}
+	switch {
+	case err != nil:
+		condition.Status = apiv1.ConditionUnknown
+		condition.Reason = "PreflightFailed"
+		condition.Message = err.Error()
+	case len(unservedClusters) > 0:
+		condition.Status = apiv1.ConditionFalse
+		condition.Reason = "UnservedClusters"
+		condition.Message = fmt.Sprintf("%s is not served by clusters: %s", targetTypeString(tc.GetTargetType()), strings.Join(unservedClusters, ", "))
+		if len(unreachableClusters) > 0 {
+			condition.Message += fmt.Sprintf("; unable to reach clusters: %s", strings.Join(unreachableClusters, ", "))
+		}
+		klog.Warningf("FederatedTypeConfig %q: %s", tc.Name, condition.Message)
+	case len(unreachableClusters) > 0:
+		condition.Status = apiv1.ConditionUnknown
+		condition.Reason = "UnreachableClusters"
+		condition.Message = fmt.Sprintf("Unable to determine whether %s is served by clusters: %s", targetTypeString(tc.GetTargetType()), strings.Join(unreachableClusters, ", "))
+	}
+	return condition
+}
+
+// targetTypePreflight is a preflight of the target type of a
+// FederatedTypeConfig running in the background.
+type targetTypePreflight struct {
+	// Generation of the FederatedTypeConfig when the preflight was
+	// started.
+	generation int64
+	// The resulting condition. Nil until the preflight completes.
+	condition *corev1b1.TypeConfigCondition
+}
+
+// requestTargetTypePreflight starts the preflight of the target type of
+// the given FederatedTypeConfig unless one is already running for its
+// current generation.
+func (c *Controller) requestTargetTypePreflight(tc *corev1b1.FederatedTypeConfig) {
+	c.lock.RLock()
+	preflight, ok := c.targetTypePreflights[tc.Name]
+	c.lock.RUnlock()
+	if ok && preflight.condition == nil && preflight.generation == tc.Generation {
+		return
+	}
+	c.startTargetTypePreflight(tc)
+}
+
+// startTargetTypePreflight starts the preflight of the target type of
+// the given FederatedTypeConfig in the background, superseding any
+// preflight already running for it, so that discovery in member
+// clusters does not block the worker. The FederatedTypeConfig is
+// enqueued once the preflight completes so that its result is
+// recorded by reconciliation.
+func (c *Controller) startTargetTypePreflight(tc *corev1b1.FederatedTypeConfig) {
+	tc = tc.DeepCopy()
+	preflight := &targetTypePreflight{generation: tc.Generation}
+	c.lock.Lock()
+	c.targetTypePreflights[tc.Name] = preflight
+	c.lock.Unlock()
+
+	go func() {
+		condition := c.targetTypeServedCondition(tc)
+		c.lock.Lock()
+		if c.targetTypePreflights[tc.Name] != preflight {
+			// Superseded by another preflight.
+			c.lock.Unlock()
+			return
+		}
+		preflight.condition = &condition
+		c.lock.Unlock()
+		c.worker.EnqueueObject(tc)
+	}()
+}
+
+// recordTargetTypePreflight sets the TargetTypeServed condition of the
+// given FederatedTypeConfig to the result of its completed preflight,
+// if any.
+func (c *Controller) recordTargetTypePreflight(tc *corev1b1.FederatedTypeConfig) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	preflight, ok := c.targetTypePreflights[tc.Name]
+	if !ok || preflight.condition == nil {
+		return
+	}
+	delete(c.targetTypePreflights, tc.Name)
+	setTypeConfigCondition(&tc.Status, *preflight.condition)
+}
+
+// discardTargetTypePreflight discards the preflight of the target type
+// of the named FederatedTypeConfig, if any.
+func (c *Controller) discardTargetTypePreflight(name string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	delete(c.targetTypePreflights, name)
+}
+
+// handleClusterChange repeats the preflight of the target type of each
+// FederatedTypeConfig whose sync controller is running when a member
+// cluster joins, i.e. becomes ready, so that the TargetTypeServed
+// condition reflects the new cluster.
+func (c *Controller) handleClusterChange(obj runtimeclient.Object) {
+	if c.targetTypeServed == nil || !c.clusterJoined(obj) {
+		return
+	}
+	klog.V(2).Infof("Verifying that cluster %q serves the target types of running sync controllers", obj.GetName())
+	for _, cachedObj := range c.store.List() {
+		tc := cachedObj.(*corev1b1.FederatedTypeConfig)
+		c.lock.RLock()
+		_, running := c.syncControllers[tc.Name]
+		c.lock.RUnlock()
+		if running {
+			c.startTargetTypePreflight(tc)
+		}
+	}
+}
+
+// clusterJoined records whether the given member cluster is ready and
+// returns whether it was not ready or not known before.
+func (c *Controller) clusterJoined(obj runtimeclient.Object) bool {
+	cluster, ok := obj.(*corev1b1.KubeFedCluster)
+	if !ok {
+		return false
+	}
+	_, exists, err := c.clusterStore.Get(cluster)
+	ready := err == nil && exists && utils.IsClusterReady(&cluster.Status)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if !ready {
+		c.readyClusters.Delete(cluster.Name)
+		return false
+	}
+	joined := !c.readyClusters.Has(cluster.Name)
+	c.readyClusters.Insert(cluster.Name)
+	return joined
+}
+
+// setTypeConfigCondition sets the given condition in the given status,
+// retaining the time of the last transition if its status is
+// unchanged.
+func setTypeConfigCondition(status *corev1b1.FederatedTypeConfigStatus, condition corev1b1.TypeConfigCondition) {
+	for i := range status.Conditions {
+		existing := &status.Conditions[i]
+		if existing.Type != condition.Type {
+			continue
+		}
+		condition.LastTransitionTime = existing.LastTransitionTime
+		if existing.Status != condition.Status || condition.LastTransitionTime == nil {
+			now := metav1.Now()
+			condition.LastTransitionTime = &now
+		}
+		*existing = condition
+		return
+	}
+	now := metav1.Now()
+	condition.LastTransitionTime = &now
+	status.Conditions = append(status.Conditions, condition)
+}
+
+// removeTypeConfigCondition removes the condition of the given type
+// from the given status.
+func removeTypeConfigCondition(status *corev1b1.FederatedTypeConfigStatus, conditionType corev1b1.TypeConfigConditionType) {
+	var conditions []corev1b1.TypeConfigCondition
+	for _, condition := range status.Conditions {
+		if condition.Type != conditionType {
+			conditions = append(conditions, condition)
+		}
+	}
+	status.Conditions = conditions
+}
+
+// getTypeConfigCondition returns the condition of the given type in
+// the given status, or nil if it is not present.
+func getTypeConfigCondition(status *corev1b1.FederatedTypeConfigStatus, conditionType corev1b1.TypeConfigConditionType) *corev1b1.TypeConfigCondition {
+	for i := range status.Conditions {
+		if status.Conditions[i].Type == conditionType {
+			return &status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+func targetTypeString(apiResource metav1.APIResource) string {
+	groupVersion := schema.GroupVersion{Group: apiResource.Group, Version: apiResource.Version}
+	return fmt.Sprintf("%s %s", groupVersion, apiResource.Kind)
+}
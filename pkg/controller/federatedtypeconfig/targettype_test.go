@@ -0,0 +1,292 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package federatedtypeconfig
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+
+	apiv1 "k8s.io/api/core/v1"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	restclient "k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/kubefed/pkg/apis/core/common"
+	corev1b1 "sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
+	genericclient "sigs.k8s.io/kubefed/pkg/client/generic"
+	"sigs.k8s.io/kubefed/pkg/controller/utils"
+)
+
+// clusterListingClient lists the given member clusters. Methods that
+// are not overridden panic via the nil embedded interface.
+type clusterListingClient struct {
+	genericclient.Client
+	clusters []corev1b1.KubeFedCluster
+}
+
+func (c *clusterListingClient) List(ctx context.Context, obj runtimeclient.ObjectList, namespace string, opts ...runtimeclient.ListOption) error {
+	obj.(*corev1b1.KubeFedClusterList).Items = c.clusters
+	return nil
+}
+
+func newCluster(name string, ready bool) corev1b1.KubeFedCluster {
+	status := apiv1.ConditionFalse
+	if ready {
+		status = apiv1.ConditionTrue
+	}
+	return corev1b1.KubeFedCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "kube-federation-system"},
+		Status: corev1b1.KubeFedClusterStatus{
+			Conditions: []corev1b1.ClusterCondition{{Type: common.ClusterReady, Status: status}},
+		},
+	}
+}
+
+func TestPreflightTargetType(t *testing.T) {
+	client := &clusterListingClient{
+		clusters: []corev1b1.KubeFedCluster{
+			newCluster("cluster1", true),
+			newCluster("cluster2", true),
+			newCluster("cluster3", true),
+			newCluster("cluster4", false),
+		},
+	}
+	// cluster2 lacks the type, cluster3 cannot be reached and cluster4
+	// is not ready.
+	servedFunc := func(cluster *corev1b1.KubeFedCluster, apiResource metav1.APIResource) (bool, error) {
+		switch cluster.Name {
+		case "cluster1":
+			return true, nil
+		case "cluster3":
+			return false, errors.New("unreachable")
+		case "cluster4":
+			t.Errorf("Expected the cluster that is not ready not to be checked")
+		}
+		return false, nil
+	}
+	typeConfig := newTypeConfig("configmaps", "FederatedConfigMap", apiextv1.NamespaceScoped, 1, corev1b1.FederatedTypeConfigStatus{})
+	typeConfig.Spec.TargetType.Kind = "ConfigMap"
+	c := &Controller{
+		controllerConfig: &utils.ControllerConfig{
+			KubeFedNamespaces: utils.KubeFedNamespaces{KubeFedNamespace: "kube-federation-system"},
+		},
+		client:           client,
+		targetTypeServed: servedFunc,
+		ctx:              context.Background(),
+	}
+
+	unservedClusters, unreachableClusters, err := PreflightTargetType(c.ctx, client, "kube-federation-system", typeConfig, servedFunc)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := []string{"cluster2"}; !reflect.DeepEqual(unservedClusters, expected) {
+		t.Fatalf("Expected unserved clusters %v, got %v", expected, unservedClusters)
+	}
+	if expected := []string{"cluster3"}; !reflect.DeepEqual(unreachableClusters, expected) {
+		t.Fatalf("Expected unreachable clusters %v, got %v", expected, unreachableClusters)
+	}
+
+	setTypeConfigCondition(&typeConfig.Status, c.targetTypeServedCondition(typeConfig))
+	condition := getTypeConfigCondition(&typeConfig.Status, corev1b1.TargetTypeServed)
+	if condition == nil || condition.Status != apiv1.ConditionFalse || condition.LastTransitionTime == nil {
+		t.Fatalf("Expected the TargetTypeServed condition to be False, got %+v", condition)
+	}
+	if expected := "v1 ConfigMap is not served by clusters: cluster2; unable to reach clusters: cluster3"; condition.Message != expected {
+		t.Fatalf("Expected message %q, got %q", expected, condition.Message)
+	}
+	transitionTime := condition.LastTransitionTime
+
+	// The time of the last transition is retained while the status of
+	// the condition is unchanged.
+	setTypeConfigCondition(&typeConfig.Status, c.targetTypeServedCondition(typeConfig))
+	if condition := getTypeConfigCondition(&typeConfig.Status, corev1b1.TargetTypeServed); condition.LastTransitionTime != transitionTime {
+		t.Fatalf("Expected the time of the last transition to be retained")
+	}
+
+	// Whether the type is served is unknown while a cluster cannot be
+	// reached.
+	client.clusters = []corev1b1.KubeFedCluster{newCluster("cluster1", true), newCluster("cluster3", true)}
+	setTypeConfigCondition(&typeConfig.Status, c.targetTypeServedCondition(typeConfig))
+	condition = getTypeConfigCondition(&typeConfig.Status, corev1b1.TargetTypeServed)
+	if condition.Status != apiv1.ConditionUnknown || condition.Reason != "UnreachableClusters" {
+		t.Fatalf("Expected the TargetTypeServed condition to be Unknown, got %+v", condition)
+	}
+	if expected := "Unable to determine whether v1 ConfigMap is served by clusters: cluster3"; condition.Message != expected {
+		t.Fatalf("Expected message %q, got %q", expected, condition.Message)
+	}
+
+	client.clusters = client.clusters[:1]
+	setTypeConfigCondition(&typeConfig.Status, c.targetTypeServedCondition(typeConfig))
+	if len(typeConfig.Status.Conditions) != 1 {
+		t.Fatalf("Expected a single condition, got %+v", typeConfig.Status.Conditions)
+	}
+	if condition := getTypeConfigCondition(&typeConfig.Status, corev1b1.TargetTypeServed); condition.Status != apiv1.ConditionTrue || len(condition.Message) > 0 {
+		t.Fatalf("Expected the TargetTypeServed condition to be True, got %+v", condition)
+	}
+
+	removeTypeConfigCondition(&typeConfig.Status, corev1b1.TargetTypeServed)
+	if len(typeConfig.Status.Conditions) != 0 {
+		t.Fatalf("Expected the condition to be removed, got %+v", typeConfig.Status.Conditions)
+	}
+}
+
+// channelWorker sends the objects enqueued through it to a channel.
+// Methods that are not overridden panic via the nil embedded interface.
+type channelWorker struct {
+	utils.ReconcileWorker
+	enqueued chan utils.QualifiedName
+}
+
+func (w *channelWorker) EnqueueObject(obj runtimeclient.Object) {
+	w.enqueued <- utils.NewQualifiedName(obj)
+}
+
+func TestTargetTypePreflightRunsInBackground(t *testing.T) {
+	client := &clusterListingClient{
+		clusters: []corev1b1.KubeFedCluster{newCluster("cluster1", true)},
+	}
+	discovered := make(chan struct{})
+	servedFunc := func(cluster *corev1b1.KubeFedCluster, apiResource metav1.APIResource) (bool, error) {
+		<-discovered
+		return false, nil
+	}
+	typeConfig := newTypeConfig("configmaps", "FederatedConfigMap", apiextv1.NamespaceScoped, 1, corev1b1.FederatedTypeConfigStatus{})
+	worker := &channelWorker{enqueued: make(chan utils.QualifiedName, 2)}
+	c := &Controller{
+		controllerConfig: &utils.ControllerConfig{
+			KubeFedNamespaces: utils.KubeFedNamespaces{KubeFedNamespace: "kube-federation-system"},
+		},
+		client:               client,
+		targetTypeServed:     servedFunc,
+		targetTypePreflights: make(map[string]*targetTypePreflight),
+		worker:               worker,
+		ctx:                  context.Background(),
+	}
+
+	// The preflight does not block while discovery is pending, and a
+	// request for the same generation does not start another one.
+	c.requestTargetTypePreflight(typeConfig)
+	c.requestTargetTypePreflight(typeConfig)
+	c.recordTargetTypePreflight(typeConfig)
+	if condition := getTypeConfigCondition(&typeConfig.Status, corev1b1.TargetTypeServed); condition != nil {
+		t.Fatalf("Expected no condition while the preflight is running, got %+v", condition)
+	}
+
+	close(discovered)
+	select {
+	case qualifiedName := <-worker.enqueued:
+		if qualifiedName != utils.NewQualifiedName(typeConfig) {
+			t.Fatalf("Expected %q to be enqueued, got %q", utils.NewQualifiedName(typeConfig), qualifiedName)
+		}
+	case <-time.After(wait.ForeverTestTimeout):
+		t.Fatalf("Expected the FederatedTypeConfig to be enqueued once the preflight completed")
+	}
+	select {
+	case qualifiedName := <-worker.enqueued:
+		t.Fatalf("Expected a single preflight, got another result for %q", qualifiedName)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	c.recordTargetTypePreflight(typeConfig)
+	if condition := getTypeConfigCondition(&typeConfig.Status, corev1b1.TargetTypeServed); condition == nil || condition.Status != apiv1.ConditionFalse {
+		t.Fatalf("Expected the TargetTypeServed condition to be False, got %+v", condition)
+	}
+	if len(c.targetTypePreflights) != 0 {
+		t.Fatalf("Expected the recorded preflight to be removed, got %v", c.targetTypePreflights)
+	}
+}
+
+func TestClusterJoined(t *testing.T) {
+	clusterStore := cache.NewStore(cache.MetaNamespaceKeyFunc)
+	c := &Controller{
+		clusterStore:  clusterStore,
+		readyClusters: sets.New[string](),
+	}
+	cluster := newCluster("cluster1", false)
+
+	steps := []struct {
+		description string
+		ready       bool
+		deleted     bool
+		joined      bool
+	}{
+		{description: "a cluster that is not ready has not joined"},
+		{description: "a cluster that becomes ready joins", ready: true, joined: true},
+		{description: "a ready cluster does not join again", ready: true},
+		{description: "a deleted cluster leaves", ready: true, deleted: true},
+		{description: "a cluster that is added again joins", ready: true, joined: true},
+	}
+	for _, step := range steps {
+		cluster = newCluster("cluster1", step.ready)
+		var err error
+		if step.deleted {
+			err = clusterStore.Delete(&cluster)
+		} else {
+			err = clusterStore.Add(&cluster)
+		}
+		if err != nil {
+			t.Fatalf("Unexpected error updating store: %v", err)
+		}
+		if joined := c.clusterJoined(&cluster); joined != step.joined {
+			t.Fatalf("%s: expected joined to be %v", step.description, step.joined)
+		}
+	}
+}
+
+func TestClusterConfigCache(t *testing.T) {
+	configs := &clusterConfigCache{configs: make(map[string]*cachedClusterConfig)}
+	builds := 0
+	buildFunc := func() (*restclient.Config, error) {
+		builds++
+		return &restclient.Config{}, nil
+	}
+	cluster := newCluster("cluster1", true)
+	cluster.Generation = 1
+
+	for i := 0; i < 2; i++ {
+		if _, err := configs.get(&cluster, buildFunc); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if builds != 1 {
+		t.Fatalf("Expected the configuration to be reused, got %d builds", builds)
+	}
+
+	cluster.Generation = 2
+	if _, err := configs.get(&cluster, buildFunc); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if builds != 2 {
+		t.Fatalf("Expected the configuration to be built again for a new spec, got %d builds", builds)
+	}
+
+	configs.invalidate(cluster.Name)
+	if _, err := configs.get(&cluster, buildFunc); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if builds != 3 {
+		t.Fatalf("Expected the configuration to be built again once invalidated, got %d builds", builds)
+	}
+}
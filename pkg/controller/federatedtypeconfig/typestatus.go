@@ -0,0 +1,93 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package federatedtypeconfig
+
+import (
+	"sort"
+
+	"k8s.io/client-go/tools/cache"
+
+	corev1b1 "sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
+)
+
+// FederatedTypeStatus summarizes the propagation readiness of a
+// federated type as observed by the FederatedTypeConfig controller.
+type FederatedTypeStatus struct {
+	// Name is the name of the FederatedTypeConfig.
+	Name string
+	// Kind is the kind of the federated type.
+	Kind string
+	// Namespaced indicates whether the target type is namespaced.
+	Namespaced bool
+	// PropagationController is the state of the sync controller.
+	PropagationController corev1b1.ControllerStatus
+	// PropagationPaused indicates whether the sync controller is
+	// running with propagation paused for the control plane.
+	PropagationPaused bool
+	// StatusController is the state of the status controller.
+	StatusController corev1b1.ControllerStatus
+	// ObservedGenerationLag is the number of generations of the
+	// FederatedTypeConfig that have not yet been observed by the
+	// controller.
+	ObservedGenerationLag int64
+}
+
+// ListFederatedTypeStatus returns the status of every
+// FederatedTypeConfig in the given store, ordered by name. It reads
+// from the store only and does not make any API calls. A controller
+// whose state has not yet been recorded is reported as not running.
+func ListFederatedTypeStatus(store cache.Store) []FederatedTypeStatus {
+	var statuses []FederatedTypeStatus
+	for _, obj := range store.List() {
+		typeConfig, ok := obj.(*corev1b1.FederatedTypeConfig)
+		if !ok {
+			continue
+		}
+		statuses = append(statuses, federatedTypeStatus(typeConfig))
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+	return statuses
+}
+
+// ListFederatedTypeStatus returns the status of every
+// FederatedTypeConfig known to the controller.
+func (c *Controller) ListFederatedTypeStatus() []FederatedTypeStatus {
+	return ListFederatedTypeStatus(c.store)
+}
+
+func federatedTypeStatus(typeConfig *corev1b1.FederatedTypeConfig) FederatedTypeStatus {
+	status := FederatedTypeStatus{
+		Name:                  typeConfig.Name,
+		Kind:                  typeConfig.GetFederatedType().Kind,
+		Namespaced:            typeConfig.GetNamespaced(),
+		PropagationController: corev1b1.ControllerStatusNotRunning,
+		StatusController:      corev1b1.ControllerStatusNotRunning,
+		PropagationPaused:     typeConfig.Status.PropagationPaused,
+	}
+	if typeConfig.Status.PropagationController != "" {
+		status.PropagationController = typeConfig.Status.PropagationController
+	}
+	if typeConfig.Status.StatusController != nil && *typeConfig.Status.StatusController != "" {
+		status.StatusController = *typeConfig.Status.StatusController
+	}
+	if lag := typeConfig.Generation - typeConfig.Status.ObservedGeneration; lag > 0 {
+		status.ObservedGenerationLag = lag
+	}
+	return status
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package federatedtypeconfig
+
+import (
+	"reflect"
+	"testing"
+
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/utils/ptr"
+
+	corev1b1 "sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
+)
+
+func newTypeConfig(name, kind string, scope apiextv1.ResourceScope, generation int64, status corev1b1.FederatedTypeConfigStatus) *corev1b1.FederatedTypeConfig {
+	return &corev1b1.FederatedTypeConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       name,
+			Namespace:  "kube-federation-system",
+			Generation: generation,
+		},
+		Spec: corev1b1.FederatedTypeConfigSpec{
+			FederatedType: corev1b1.APIResource{
+				Group:   "types.kubefed.io",
+				Version: "v1beta1",
+				Kind:    kind,
+				Scope:   scope,
+			},
+			TargetType: corev1b1.APIResource{
+				Version: "v1",
+				Scope:   scope,
+			},
+		},
+		Status: status,
+	}
+}
+
+func TestListFederatedTypeStatus(t *testing.T) {
+	store := cache.NewStore(cache.MetaNamespaceKeyFunc)
+	typeConfigs := []*corev1b1.FederatedTypeConfig{
+		newTypeConfig("services", "FederatedService", apiextv1.NamespaceScoped, 2, corev1b1.FederatedTypeConfigStatus{
+			ObservedGeneration:    2,
+			PropagationController: corev1b1.ControllerStatusRunning,
+			StatusController:      ptr.To(corev1b1.ControllerStatusRunning),
+		}),
+		newTypeConfig("clusterroles.rbac.authorization.k8s.io", "FederatedClusterRole", apiextv1.ClusterScoped, 3, corev1b1.FederatedTypeConfigStatus{
+			ObservedGeneration:    1,
+			PropagationController: corev1b1.ControllerStatusNotRunning,
+			StatusController:      ptr.To(corev1b1.ControllerStatusNotRunning),
+		}),
+		// Not yet reconciled by the controller
+		newTypeConfig("configmaps", "FederatedConfigMap", apiextv1.NamespaceScoped, 1, corev1b1.FederatedTypeConfigStatus{}),
+	}
+	for _, typeConfig := range typeConfigs {
+		if err := store.Add(typeConfig); err != nil {
+			t.Fatalf("Unexpected error adding to store: %v", err)
+		}
+	}
+
+	expected := []FederatedTypeStatus{
+		{
+			Name:                  "clusterroles.rbac.authorization.k8s.io",
+			Kind:                  "FederatedClusterRole",
+			Namespaced:            false,
+			PropagationController: corev1b1.ControllerStatusNotRunning,
+			StatusController:      corev1b1.ControllerStatusNotRunning,
+			ObservedGenerationLag: 2,
+		},
+		{
+			Name:                  "configmaps",
+			Kind:                  "FederatedConfigMap",
+			Namespaced:            true,
+			PropagationController: corev1b1.ControllerStatusNotRunning,
+			StatusController:      corev1b1.ControllerStatusNotRunning,
+			ObservedGenerationLag: 1,
+		},
+		{
+			Name:                  "services",
+			Kind:                  "FederatedService",
+			Namespaced:            true,
+			PropagationController: corev1b1.ControllerStatusRunning,
+			StatusController:      corev1b1.ControllerStatusRunning,
+			ObservedGenerationLag: 0,
+		},
+	}
+	actual := ListFederatedTypeStatus(store)
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected %#v, got %#v", expected, actual)
+	}
+}
@@ -57,6 +57,8 @@ const (
 	ClusterReachableMsg          = "cluster is reachable"
 	ClusterConfigMalformedReason = "ClusterConfigMalformed"
 	ClusterConfigMalformedMsg    = "cluster's configuration may be malformed"
+	ClusterUnauthorizedReason    = "ClusterUnauthorized"
+	ClusterUnauthorizedMsg       = "cluster rejected the credentials of its secret"
 )
 
 // ClusterClient provides methods for determining the status and zones of a
@@ -68,10 +70,11 @@ type ClusterClient struct {
 
 // NewClusterClientSet returns a ClusterClient for the given KubeFedCluster.
 // The kubeClient is used to configure the ClusterClient's internal client
-// with information from a kubeconfig stored in a kubernetes secret.
-func NewClusterClientSet(c *fedv1b1.KubeFedCluster, client generic.Client, fedNamespace string, timeout time.Duration) (*ClusterClient, error) {
+// with information from a kubeconfig stored in a kubernetes secret. The
+// requests of the internal client are limited by the given rate limits.
+func NewClusterClientSet(c *fedv1b1.KubeFedCluster, client generic.Client, fedNamespace string, rateLimits utils.ClientRateLimits, timeout time.Duration) (*ClusterClient, error) {
 	var clusterClientSet = ClusterClient{clusterName: c.Name}
-	clusterConfig, err := utils.BuildClusterConfig(c, client, fedNamespace)
+	clusterConfig, err := utils.BuildRateLimitedClusterConfig(c, client, fedNamespace, rateLimits)
 	if err != nil {
 		return &clusterClientSet, err
 	}
@@ -134,6 +137,14 @@ func (c *ClusterClient) GetClusterStatus() (*fedv1b1.KubeFedClusterStatus, error
 		LastProbeTime:      currentTime,
 		LastTransitionTime: &currentTime,
 	}
+	clusterUnauthorizedReason := ClusterUnauthorizedReason
+	newClusterAuthenticationFailedCondition := fedv1b1.ClusterCondition{
+		Type:               fedcommon.ClusterAuthenticationFailed,
+		Status:             corev1.ConditionTrue,
+		Reason:             &clusterUnauthorizedReason,
+		LastProbeTime:      currentTime,
+		LastTransitionTime: &currentTime,
+	}
 	if c.kubeClient == nil {
 		clusterStatus.Conditions = append(clusterStatus.Conditions, newClusterConfigMalformedCondition)
 		metrics.RegisterKubefedClusterTotal(metrics.ClusterNotReady, c.clusterName)
@@ -145,6 +156,11 @@ func (c *ClusterClient) GetClusterStatus() (*fedv1b1.KubeFedClusterStatus, error
 		msg := fmt.Sprintf("%s: %v", ClusterNotReachableMsg, err)
 		newClusterOfflineCondition.Message = &msg
 		clusterStatus.Conditions = append(clusterStatus.Conditions, newClusterOfflineCondition)
+		if utils.IsAuthenticationError(err) {
+			msg := fmt.Sprintf("%s: %v", ClusterUnauthorizedMsg, err)
+			newClusterAuthenticationFailedCondition.Message = &msg
+			clusterStatus.Conditions = append(clusterStatus.Conditions, newClusterAuthenticationFailedCondition)
+		}
 		metrics.RegisterKubefedClusterTotal(metrics.ClusterOffline, c.clusterName)
 	} else {
 		if !strings.EqualFold(string(body), "ok") {
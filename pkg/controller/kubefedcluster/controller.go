@@ -74,10 +74,18 @@ type ClusterController struct {
 	// for events on KubeFedClusters.
 	clusterController cache.Controller
 
+	// secretController is the cache.Controller where callbacks are
+	// registered for events on the secrets of KubeFedClusters.
+	secretController cache.Controller
+
 	// fedNamespace is the name of the namespace containing
 	// KubeFedCluster resources and their associated secrets.
 	fedNamespace string
 
+	// clientRateLimits limits the rate of the requests of the clients
+	// used to check the health of clusters.
+	clientRateLimits utils.ClientRateLimits
+
 	eventRecorder record.EventRecorder
 }
 
@@ -104,6 +112,7 @@ func newClusterController(config *utils.ControllerConfig, clusterHealthCheckConf
 		clusterHealthCheckConfig: clusterHealthCheckConfig,
 		clusterDataMap:           make(map[string]*ClusterData),
 		fedNamespace:             config.KubeFedNamespace,
+		clientRateLimits:         config.ClusterClientRateLimits,
 	}
 
 	kubeClient := kubeclient.NewForConfigOrDie(kubeConfig)
@@ -160,9 +169,74 @@ func newClusterController(config *utils.ControllerConfig, clusterHealthCheckConf
 			},
 		},
 	)
+	if err != nil {
+		return nil, err
+	}
+
+	_, cc.secretController, err = utils.NewGenericInformerWithEventHandler(
+		config.KubeConfig,
+		config.KubeFedNamespace,
+		&corev1.Secret{},
+		utils.NoResyncPeriod,
+		&cache.ResourceEventHandlerFuncs{
+			UpdateFunc: func(oldObj, newObj interface{}) {
+				oldSecret, ok := oldObj.(*corev1.Secret)
+				if !ok {
+					return
+				}
+				secret, ok := newObj.(*corev1.Secret)
+				if !ok || equality.Semantic.DeepEqual(oldSecret.Data, secret.Data) {
+					return
+				}
+				cc.secretChanged(secret)
+			},
+		},
+	)
 	return cc, err
 }
 
+// secretChanged records the version of the given secret on the
+// clusters whose credentials it holds. The update of the clusters
+// causes their clients to be rebuilt with the current credentials.
+func (cc *ClusterController) secretChanged(secret *corev1.Secret) {
+	cc.mu.RLock()
+	var clusters []*fedv1b1.KubeFedCluster
+	for _, clusterData := range cc.clusterDataMap {
+		cluster := clusterData.cachedObj
+		if utils.IsClusterSecret(cluster, secret) && utils.ClusterCredentialsOutdated(cluster, secret) {
+			clusters = append(clusters, cluster.DeepCopy())
+		}
+	}
+	cc.mu.RUnlock()
+
+	for _, cluster := range clusters {
+		klog.V(1).Infof("ClusterController observed a change of the credentials of cluster %q", cluster.Name)
+		original := cluster.DeepCopy()
+		annotations := cluster.GetAnnotations()
+		if annotations == nil {
+			annotations = make(map[string]string)
+		}
+		annotations[utils.ClusterCredentialsVersionAnnotation] = secret.ResourceVersion
+		cluster.SetAnnotations(annotations)
+		if err := cc.client.Patch(context.TODO(), cluster, runtimeclient.MergeFrom(original)); err != nil {
+			klog.Errorf("Failed to record the credentials version of cluster %q: %v", cluster.Name, err)
+		}
+	}
+}
+
+// refreshClusterClient rebuilds the client of the given cluster from
+// the current credentials of the cluster.
+func (cc *ClusterController) refreshClusterClient(cluster *fedv1b1.KubeFedCluster, storedData *ClusterData) {
+	restClient, err := NewClusterClientSet(cluster, cc.client, cc.fedNamespace, cc.clientRateLimits, cc.clusterHealthCheckConfig.Timeout)
+	if err != nil || restClient.kubeClient == nil {
+		klog.Errorf("Failed to refresh the client of cluster %q: %v", cluster.Name, err)
+		return
+	}
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	storedData.clusterKubeClient = restClient
+}
+
 // delFromClusterSet removes a cluster from the cluster data map
 func (cc *ClusterController) delFromClusterSet(obj *fedv1b1.KubeFedCluster) {
 	cc.mu.Lock()
@@ -183,7 +257,7 @@ func (cc *ClusterController) addToClusterSet(obj *fedv1b1.KubeFedCluster) {
 	klog.V(1).Infof("ClusterController observed a new cluster: %v", obj.Name)
 
 	// create the restClient of cluster
-	restClient, err := NewClusterClientSet(obj, cc.client, cc.fedNamespace, cc.clusterHealthCheckConfig.Timeout)
+	restClient, err := NewClusterClientSet(obj, cc.client, cc.fedNamespace, cc.clientRateLimits, cc.clusterHealthCheckConfig.Timeout)
 	if err != nil || restClient.kubeClient == nil {
 		cc.RecordError(obj, "MalformedClusterConfig", errors.Wrap(err, "The configuration for this cluster may be malformed"))
 		klog.Errorf("The configuration for cluster %q may be malformed: %v", obj.Name, err)
@@ -195,6 +269,7 @@ func (cc *ClusterController) addToClusterSet(obj *fedv1b1.KubeFedCluster) {
 func (cc *ClusterController) Run(stopChan <-chan struct{}) {
 	defer utilruntime.HandleCrash()
 	go cc.clusterController.Run(stopChan)
+	go cc.secretController.Run(stopChan)
 	// monitor cluster status periodically, in phase 1 we just get the health state from "/healthz"
 	go wait.Until(func() {
 		if err := cc.updateClusterStatus(); err != nil {
@@ -248,6 +323,11 @@ func (cc *ClusterController) updateIndividualClusterStatus(cluster *fedv1b1.Kube
 		cc.RecordError(cluster, "RetrievingClusterHealthFailed", errors.Wrap(err, "Failed to retrieve health of the cluster"))
 		klog.Errorf("Failed to retrieve health of the cluster %s: %v", cluster.Name, err)
 	}
+	if utils.IsAuthenticationError(err) {
+		// The credentials may have been rotated since the client was
+		// built, in which case the next check uses the current ones.
+		cc.refreshClusterClient(cluster, storedData)
+	}
 
 	currentClusterStatus = thresholdAdjustedClusterStatus(currentClusterStatus, storedData, cc.clusterHealthCheckConfig)
 
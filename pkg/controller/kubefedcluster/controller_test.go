@@ -17,15 +17,23 @@ limitations under the License.
 package kubefedcluster
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"testing"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubeclientset "k8s.io/client-go/kubernetes"
+	restclient "k8s.io/client-go/rest"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 
 	"sigs.k8s.io/kubefed/pkg/apis/core/common"
 	fedv1b1 "sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
+	genericclient "sigs.k8s.io/kubefed/pkg/client/generic"
 	"sigs.k8s.io/kubefed/pkg/controller/utils"
 )
 
@@ -112,3 +120,89 @@ func clusterStatus(status corev1.ConditionStatus, lastProbeTime, lastTransitionT
 		}},
 	}
 }
+
+func TestClusterStatusOfRejectedCredentials(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer current" {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"Unauthorized","code":401}`))
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	testCases := map[string]struct {
+		token                       string
+		expectedAuthenticationFails bool
+	}{
+		"rotated token is rejected": {
+			token:                       "rotated",
+			expectedAuthenticationFails: true,
+		},
+		"current token is accepted": {
+			token: "current",
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			kubeClient, err := kubeclientset.NewForConfig(&restclient.Config{Host: server.URL, BearerToken: tc.token})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			clusterClient := &ClusterClient{kubeClient: kubeClient, clusterName: "cluster1"}
+			clusterStatus, err := clusterClient.GetClusterStatus()
+			if tc.expectedAuthenticationFails != utils.IsAuthenticationError(err) {
+				t.Fatalf("Expected authentication error %v, got %v", tc.expectedAuthenticationFails, err)
+			}
+			authenticationFailed := false
+			for _, condition := range clusterStatus.Conditions {
+				if condition.Type == common.ClusterAuthenticationFailed && condition.Status == corev1.ConditionTrue {
+					authenticationFailed = true
+				}
+			}
+			if authenticationFailed != tc.expectedAuthenticationFails {
+				t.Fatalf("Expected AuthenticationFailed condition %v, got conditions %v", tc.expectedAuthenticationFails, clusterStatus.Conditions)
+			}
+		})
+	}
+}
+
+// secretClient serves the given secret and no other resource.
+type secretClient struct {
+	genericclient.Client
+	secret *corev1.Secret
+}
+
+func (c *secretClient) Get(ctx context.Context, obj runtimeclient.Object, namespace, name string) error {
+	secret, ok := obj.(*corev1.Secret)
+	if !ok || namespace != c.secret.Namespace || name != c.secret.Name {
+		return apierrors.NewNotFound(corev1.Resource("secrets"), name)
+	}
+	c.secret.DeepCopyInto(secret)
+	return nil
+}
+
+func TestClusterClientRateLimits(t *testing.T) {
+	client := &secretClient{secret: &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "kube-federation-system", Name: "cluster1-secret"},
+		Data:       map[string][]byte{utils.TokenKey: []byte("token")},
+	}}
+	cluster := &fedv1b1.KubeFedCluster{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "kube-federation-system", Name: "cluster1"},
+		Spec: fedv1b1.KubeFedClusterSpec{
+			APIEndpoint: "https://cluster1.example.com",
+			SecretRef:   fedv1b1.LocalSecretReference{Name: "cluster1-secret"},
+		},
+	}
+	rateLimits := utils.ClientRateLimits{QPS: 200, Burst: 400}
+
+	clusterClient, err := NewClusterClientSet(cluster, client, "kube-federation-system", rateLimits, time.Second)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if qps := clusterClient.kubeClient.CoreV1().RESTClient().GetRateLimiter().QPS(); qps != rateLimits.QPS {
+		t.Fatalf("Expected the health check client to be limited to %v QPS, got %v", rateLimits.QPS, qps)
+	}
+}
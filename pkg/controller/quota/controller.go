@@ -0,0 +1,292 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package quota
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+
+	fedv1b1 "sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
+	"sigs.k8s.io/kubefed/pkg/controller/utils"
+)
+
+// evaluationPeriod is the interval at which quota usage is
+// recomputed so that capacity freed by the removal of federated
+// resources from placement is handed to blocked resources.
+const evaluationPeriod = 30 * time.Second
+
+// Placement is the placement of a federated resource considered for
+// quota.
+type Placement struct {
+	Name              utils.QualifiedName
+	CreationTimestamp metav1.Time
+	// Clusters selected by placement to which the target resource is
+	// propagated.
+	Clusters sets.Set[string]
+	// Clusters in which the target resource is already present. These
+	// always count against the quota and are never blocked.
+	Propagated sets.Set[string]
+}
+
+// PlacementsFunc returns the placements of all federated resources of
+// a type.
+type PlacementsFunc func() ([]Placement, error)
+
+// Evaluate returns the clusters to which placement of each federated
+// resource is blocked by the given quota. Resources already present
+// in a cluster count against its quota first, and the remaining
+// capacity is admitted to the other resources in the order in which
+// they were created. Resources whose placement is not blocked are
+// omitted from the result.
+func Evaluate(quota *fedv1b1.PlacementQuota, placements []Placement) map[utils.QualifiedName]sets.Set[string] {
+	blocked, _ := evaluate(quota, placements)
+	return blocked
+}
+
+// evaluate returns the clusters to which placement of each federated
+// resource is blocked by the given quota, and the resulting usage.
+func evaluate(quota *fedv1b1.PlacementQuota, placements []Placement) (map[utils.QualifiedName]sets.Set[string], *usage) {
+	blocked := make(map[utils.QualifiedName]sets.Set[string])
+	usage := newUsage()
+	if quota == nil {
+		return blocked, usage
+	}
+
+	sorted := make([]Placement, len(placements))
+	copy(sorted, placements)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if !sorted[i].CreationTimestamp.Equal(&sorted[j].CreationTimestamp) {
+			return sorted[i].CreationTimestamp.Before(&sorted[j].CreationTimestamp)
+		}
+		return sorted[i].Name.String() < sorted[j].Name.String()
+	})
+
+	for _, placement := range sorted {
+		for clusterName := range placement.Clusters.Intersection(placement.Propagated) {
+			usage.add(clusterName, placement.Name.Namespace)
+		}
+	}
+	for _, placement := range sorted {
+		for _, clusterName := range sets.List(placement.Clusters.Difference(placement.Propagated)) {
+			if usage.allows(quota, clusterName, placement.Name.Namespace) {
+				usage.add(clusterName, placement.Name.Namespace)
+				continue
+			}
+			if _, ok := blocked[placement.Name]; !ok {
+				blocked[placement.Name] = sets.New[string]()
+			}
+			blocked[placement.Name].Insert(clusterName)
+		}
+	}
+	return blocked, usage
+}
+
+// usage counts the resources admitted to each cluster and to each
+// namespace of each cluster.
+type usage struct {
+	clusters   map[string]int32
+	namespaces map[string]map[string]int32
+}
+
+func newUsage() *usage {
+	return &usage{
+		clusters:   make(map[string]int32),
+		namespaces: make(map[string]map[string]int32),
+	}
+}
+
+func (u *usage) add(clusterName, namespace string) {
+	u.clusters[clusterName]++
+	if _, ok := u.namespaces[clusterName]; !ok {
+		u.namespaces[clusterName] = make(map[string]int32)
+	}
+	u.namespaces[clusterName][namespace]++
+}
+
+func (u *usage) allows(quota *fedv1b1.PlacementQuota, clusterName, namespace string) bool {
+	if quota.MaxPerCluster != nil && u.clusters[clusterName] >= *quota.MaxPerCluster {
+		return false
+	}
+	if quota.MaxPerNamespace != nil && len(namespace) > 0 && u.namespaces[clusterName][namespace] >= *quota.MaxPerNamespace {
+		return false
+	}
+	return true
+}
+
+// Controller tracks the clusters to which placement of the federated
+// resources of a type is blocked by the quota of the type. Usage is
+// recomputed from the placements of all federated resources
+// periodically and on request, and federated resources whose blocked
+// clusters change are enqueued for reconciliation. Between
+// evaluations, clusters newly selected for a federated resource are
+// admitted against the usage of each cluster as they are requested.
+type Controller struct {
+	quota      *fedv1b1.PlacementQuota
+	placements PlacementsFunc
+	enqueue    func(utils.QualifiedName)
+
+	// Serializes evaluations.
+	evaluationLock sync.Mutex
+
+	lock sync.RWMutex
+	// Blocked clusters by federated resource.
+	blocked map[utils.QualifiedName]sets.Set[string]
+	// Clusters admitted to or blocked for each federated resource.
+	considered map[utils.QualifiedName]sets.Set[string]
+	// Usage of each cluster by the admitted placements. Nil until
+	// the first evaluation.
+	usage *usage
+
+	trigger chan struct{}
+}
+
+// NewController returns a controller enforcing the given quota for the
+// federated resources whose placements are returned by placements.
+func NewController(quota *fedv1b1.PlacementQuota, placements PlacementsFunc, enqueue func(utils.QualifiedName)) *Controller {
+	return &Controller{
+		quota:      quota,
+		placements: placements,
+		enqueue:    enqueue,
+		blocked:    make(map[utils.QualifiedName]sets.Set[string]),
+		considered: make(map[utils.QualifiedName]sets.Set[string]),
+		trigger:    make(chan struct{}, 1),
+	}
+}
+
+// Run evaluates quota usage periodically and on request until the
+// stop channel is closed.
+func (c *Controller) Run(stopChan <-chan struct{}) {
+	go func() {
+		ticker := time.NewTicker(evaluationPeriod)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stopChan:
+				return
+			case <-c.trigger:
+			case <-ticker.C:
+			}
+			c.evaluate()
+		}
+	}()
+}
+
+// Trigger requests an evaluation of quota usage, e.g. because the
+// removal of a federated resource may have freed capacity.
+func (c *Controller) Trigger() {
+	select {
+	case c.trigger <- struct{}{}:
+	default:
+	}
+}
+
+// BlockedClusters returns those of the given clusters selected for the
+// named federated resource to which its placement is blocked by the
+// quota. Clusters not considered for the resource since the last
+// evaluation, e.g. because the resource was created or its placement
+// changed since, are admitted if the usage of the cluster allows it so
+// that the quota is not exceeded before the next evaluation.
+func (c *Controller) BlockedClusters(qualifiedName utils.QualifiedName, clusterNames sets.Set[string]) (sets.Set[string], error) {
+	if err := c.ensureEvaluated(); err != nil {
+		return nil, err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	considered, ok := c.considered[qualifiedName]
+	if !ok {
+		considered = sets.New[string]()
+		c.considered[qualifiedName] = considered
+	}
+	for _, clusterName := range sets.List(clusterNames.Difference(considered)) {
+		considered.Insert(clusterName)
+		if c.usage.allows(c.quota, clusterName, qualifiedName.Namespace) {
+			c.usage.add(clusterName, qualifiedName.Namespace)
+			continue
+		}
+		if _, ok := c.blocked[qualifiedName]; !ok {
+			c.blocked[qualifiedName] = sets.New[string]()
+		}
+		c.blocked[qualifiedName].Insert(clusterName)
+	}
+	return c.blocked[qualifiedName].Intersection(clusterNames), nil
+}
+
+// ensureEvaluated evaluates quota usage unless it was already
+// evaluated.
+func (c *Controller) ensureEvaluated() error {
+	c.lock.RLock()
+	evaluated := c.usage != nil
+	c.lock.RUnlock()
+	if evaluated {
+		return nil
+	}
+
+	c.evaluationLock.Lock()
+	defer c.evaluationLock.Unlock()
+	c.lock.RLock()
+	evaluated = c.usage != nil
+	c.lock.RUnlock()
+	if evaluated {
+		return nil
+	}
+	return c.evaluateLocked()
+}
+
+func (c *Controller) evaluate() {
+	c.evaluationLock.Lock()
+	defer c.evaluationLock.Unlock()
+	if err := c.evaluateLocked(); err != nil {
+		runtime.HandleError(err)
+	}
+}
+
+func (c *Controller) evaluateLocked() error {
+	placements, err := c.placements()
+	if err != nil {
+		return err
+	}
+	blocked, usage := evaluate(c.quota, placements)
+	considered := make(map[utils.QualifiedName]sets.Set[string], len(placements))
+	for _, placement := range placements {
+		considered[placement.Name] = placement.Clusters.Clone()
+	}
+
+	c.lock.Lock()
+	previous := c.blocked
+	c.blocked = blocked
+	c.considered = considered
+	c.usage = usage
+	c.lock.Unlock()
+
+	// Resources whose blocked clusters changed are reconciled so that
+	// newly admitted placements are propagated and newly blocked
+	// placements are reported.
+	for qualifiedName := range considered {
+		if !previous[qualifiedName].Equal(blocked[qualifiedName]) {
+			klog.V(4).Infof("Clusters blocked by quota for %q changed to %v", qualifiedName, sets.List(blocked[qualifiedName]))
+			c.enqueue(qualifiedName)
+		}
+	}
+	return nil
+}
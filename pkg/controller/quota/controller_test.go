@@ -0,0 +1,191 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package quota
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/utils/ptr"
+
+	fedv1b1 "sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
+	"sigs.k8s.io/kubefed/pkg/controller/utils"
+)
+
+func newPlacement(namespace, name string, age time.Duration, clusters, propagated []string) Placement {
+	return Placement{
+		Name:              utils.QualifiedName{Namespace: namespace, Name: name},
+		CreationTimestamp: metav1.NewTime(time.Unix(0, 0).Add(-age)),
+		Clusters:          sets.New(clusters...),
+		Propagated:        sets.New(propagated...),
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	testCases := map[string]struct {
+		quota           *fedv1b1.PlacementQuota
+		placements      []Placement
+		expectedBlocked map[string][]string
+	}{
+		"nothing is blocked without a quota": {
+			placements: []Placement{
+				newPlacement("ns", "a", 0, []string{"cluster1"}, nil),
+			},
+			expectedBlocked: map[string][]string{},
+		},
+		"placement within the quota of a cluster is allowed": {
+			quota: &fedv1b1.PlacementQuota{MaxPerCluster: ptr.To[int32](2)},
+			placements: []Placement{
+				newPlacement("ns", "a", 2*time.Hour, []string{"cluster1", "cluster2"}, nil),
+				newPlacement("ns", "b", time.Hour, []string{"cluster1"}, nil),
+			},
+			expectedBlocked: map[string][]string{},
+		},
+		"placement exceeding the quota of a cluster is blocked in creation order": {
+			quota: &fedv1b1.PlacementQuota{MaxPerCluster: ptr.To[int32](1)},
+			placements: []Placement{
+				newPlacement("ns", "newer", time.Hour, []string{"cluster1", "cluster2"}, nil),
+				newPlacement("other", "older", 2*time.Hour, []string{"cluster1"}, nil),
+			},
+			expectedBlocked: map[string][]string{
+				"ns/newer": {"cluster1"},
+			},
+		},
+		"resources present in a cluster are admitted first and never blocked": {
+			quota: &fedv1b1.PlacementQuota{MaxPerCluster: ptr.To[int32](1)},
+			placements: []Placement{
+				newPlacement("ns", "older", 2*time.Hour, []string{"cluster1"}, nil),
+				newPlacement("ns", "present-1", time.Hour, []string{"cluster1"}, []string{"cluster1"}),
+				newPlacement("ns", "present-2", time.Hour, []string{"cluster1"}, []string{"cluster1"}),
+			},
+			expectedBlocked: map[string][]string{
+				"ns/older": {"cluster1"},
+			},
+		},
+		"placement exceeding the quota of a namespace is blocked": {
+			quota: &fedv1b1.PlacementQuota{MaxPerNamespace: ptr.To[int32](1)},
+			placements: []Placement{
+				newPlacement("ns1", "a", 3*time.Hour, []string{"cluster1", "cluster2"}, nil),
+				newPlacement("ns1", "b", 2*time.Hour, []string{"cluster1"}, nil),
+				newPlacement("ns2", "c", time.Hour, []string{"cluster1"}, nil),
+			},
+			expectedBlocked: map[string][]string{
+				"ns1/b": {"cluster1"},
+			},
+		},
+		"the quota of a namespace is ignored for cluster-scoped resources": {
+			quota: &fedv1b1.PlacementQuota{MaxPerNamespace: ptr.To[int32](1)},
+			placements: []Placement{
+				newPlacement("", "a", 2*time.Hour, []string{"cluster1"}, nil),
+				newPlacement("", "b", time.Hour, []string{"cluster1"}, nil),
+			},
+			expectedBlocked: map[string][]string{},
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			blocked := Evaluate(tc.quota, tc.placements)
+			actual := make(map[string][]string)
+			for qualifiedName, clusterNames := range blocked {
+				actual[qualifiedName.String()] = sets.List(clusterNames)
+			}
+			assert.Equal(t, tc.expectedBlocked, actual)
+		})
+	}
+}
+
+func TestControllerEnqueuesResourcesWhoseBlockedClustersChange(t *testing.T) {
+	older := newPlacement("ns", "older", 2*time.Hour, []string{"cluster1"}, nil)
+	newer := newPlacement("ns", "newer", time.Hour, []string{"cluster1"}, nil)
+	placements := []Placement{older, newer}
+	var enqueued []utils.QualifiedName
+	c := NewController(
+		&fedv1b1.PlacementQuota{MaxPerCluster: ptr.To[int32](1)},
+		func() ([]Placement, error) { return placements, nil },
+		func(qualifiedName utils.QualifiedName) { enqueued = append(enqueued, qualifiedName) },
+	)
+
+	// Usage is evaluated on demand before the first evaluation.
+	blocked, err := c.BlockedClusters(newer.Name, newer.Clusters)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cluster1"}, sets.List(blocked))
+	blocked, err = c.BlockedClusters(older.Name, older.Clusters)
+	require.NoError(t, err)
+	assert.Empty(t, blocked)
+	assert.Equal(t, []utils.QualifiedName{newer.Name}, enqueued)
+
+	// Removal of the older resource frees the quota for the newer one.
+	enqueued = nil
+	placements = []Placement{newer}
+	c.evaluate()
+	blocked, err = c.BlockedClusters(newer.Name, newer.Clusters)
+	require.NoError(t, err)
+	assert.Empty(t, blocked)
+	assert.Equal(t, []utils.QualifiedName{newer.Name}, enqueued)
+
+	// An unchanged evaluation enqueues nothing.
+	enqueued = nil
+	c.evaluate()
+	assert.Empty(t, enqueued)
+}
+
+func TestControllerAdmitsClustersBetweenEvaluations(t *testing.T) {
+	existing := newPlacement("ns", "existing", 2*time.Hour, []string{"cluster1"}, nil)
+	evaluations := 0
+	c := NewController(
+		&fedv1b1.PlacementQuota{MaxPerCluster: ptr.To[int32](1)},
+		func() ([]Placement, error) {
+			evaluations++
+			return []Placement{existing}, nil
+		},
+		func(utils.QualifiedName) {},
+	)
+	blocked, err := c.BlockedClusters(existing.Name, existing.Clusters)
+	require.NoError(t, err)
+	assert.Empty(t, blocked)
+
+	// A resource created since the evaluation is admitted against the
+	// usage of each cluster without evaluating the quota again.
+	created := utils.QualifiedName{Namespace: "ns", Name: "created"}
+	blocked, err = c.BlockedClusters(created, sets.New("cluster2"))
+	require.NoError(t, err)
+	assert.Empty(t, blocked)
+
+	// Capacity admitted to one resource is not admitted to another.
+	other := utils.QualifiedName{Namespace: "ns", Name: "other"}
+	blocked, err = c.BlockedClusters(other, sets.New("cluster2"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cluster2"}, sets.List(blocked))
+
+	// A cluster added to the placement of an evaluated resource is
+	// admitted against the current usage.
+	blocked, err = c.BlockedClusters(existing.Name, sets.New("cluster1", "cluster2"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cluster2"}, sets.List(blocked))
+
+	// Blocked clusters that are no longer selected are not reported.
+	blocked, err = c.BlockedClusters(existing.Name, sets.New("cluster1"))
+	require.NoError(t, err)
+	assert.Empty(t, blocked)
+
+	assert.Equal(t, 1, evaluations)
+}
@@ -73,12 +73,21 @@ type KubeFedStatusController struct {
 	clusterUnavailableDelay time.Duration
 	smallDelay              time.Duration
 
+	// The interval at which status is collected. Status is collected
+	// whenever resources in member clusters change if zero.
+	collectionInterval time.Duration
+
 	cacheSyncTimeout time.Duration
 
 	typeConfig typeconfig.Interface
 
-	client       genericclient.Client
-	statusClient utils.ResourceClient
+	client              genericclient.Client
+	federatedTypeClient utils.ResourceClient
+	statusClient        utils.ResourceClient
+
+	// Whether to write the status aggregated from member clusters
+	// back to the federated resource.
+	statusFeedback bool
 
 	fedNamespace string
 
@@ -112,12 +121,12 @@ func newKubeFedStatusController(controllerConfig *utils.ControllerConfig, typeCo
 	restclient.AddUserAgent(kubeConfig, userAgent)
 	client := genericclient.NewForConfigOrDie(kubeConfig)
 
-	federatedTypeClient, err := utils.NewResourceClient(kubeConfig, &federatedAPIResource)
+	federatedTypeClient, err := utils.NewResourceClient(kubeConfig, &federatedAPIResource, utils.WithRequestMetrics())
 	if err != nil {
 		return nil, err
 	}
 
-	statusClient, err := utils.NewResourceClient(kubeConfig, statusAPIResource)
+	statusClient, err := utils.NewResourceClient(kubeConfig, statusAPIResource, utils.WithRequestMetrics())
 	if err != nil {
 		return nil, err
 	}
@@ -126,10 +135,13 @@ func newKubeFedStatusController(controllerConfig *utils.ControllerConfig, typeCo
 		clusterAvailableDelay:   controllerConfig.ClusterAvailableDelay,
 		clusterUnavailableDelay: controllerConfig.ClusterUnavailableDelay,
 		smallDelay:              time.Second * 3,
+		collectionInterval:      controllerConfig.StatusCollectionInterval,
 		cacheSyncTimeout:        controllerConfig.CacheSyncTimeout,
 		typeConfig:              typeConfig,
 		client:                  client,
+		federatedTypeClient:     federatedTypeClient,
 		statusClient:            statusClient,
+		statusFeedback:          controllerConfig.StatusFeedback,
 		fedNamespace:            controllerConfig.KubeFedNamespace,
 	}
 
@@ -158,8 +170,7 @@ func newKubeFedStatusController(controllerConfig *utils.ControllerConfig, typeCo
 		client,
 		&targetAPIResource,
 		func(obj runtimeclient.Object) {
-			qualifiedName := utils.NewQualifiedName(obj)
-			s.worker.EnqueueForRetry(qualifiedName)
+			s.enqueueForTargetChange(utils.NewQualifiedName(obj))
 		},
 		&utils.ClusterLifecycleHandlerFuncs{
 			ClusterAvailable: func(cluster *fedv1b1.KubeFedCluster) {
@@ -179,6 +190,17 @@ func newKubeFedStatusController(controllerConfig *utils.ControllerConfig, typeCo
 	return s, nil
 }
 
+// enqueueForTargetChange schedules collection of the status of a
+// resource that changed in a member cluster. With a collection
+// interval, changes within the interval are collected together.
+func (s *KubeFedStatusController) enqueueForTargetChange(qualifiedName utils.QualifiedName) {
+	if s.collectionInterval > 0 {
+		s.worker.EnqueueWithDelay(qualifiedName, s.collectionInterval)
+		return
+	}
+	s.worker.EnqueueForRetry(qualifiedName)
+}
+
 // minimizeLatency reduces delays and timeouts to make the controller more responsive (useful for testing).
 func (s *KubeFedStatusController) minimizeLatency() {
 	s.clusterAvailableDelay = time.Second
@@ -340,6 +362,20 @@ func (s *KubeFedStatusController) reconcile(qualifiedName utils.QualifiedName) u
 		}
 	}
 
+	if s.statusFeedback {
+		err = s.updateAggregatedStatus(fedObject, clusterStatus)
+		if err != nil {
+			runtime.HandleError(errors.Wrapf(err, "Failed to update aggregated status of %s %q", federatedKind, key))
+			return utils.StatusNeedsRecheck
+		}
+	}
+
+	if s.collectionInterval > 0 {
+		// Collect again at the interval to observe changes of the
+		// status that were not signaled by member clusters.
+		s.worker.EnqueueWithDelay(qualifiedName, s.collectionInterval)
+	}
+
 	return utils.StatusAllOK
 }
 
@@ -0,0 +1,69 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"testing"
+	"time"
+
+	"sigs.k8s.io/kubefed/pkg/controller/utils"
+)
+
+// delayRecordingWorker records the delays of the reconciliations
+// enqueued through it. Methods that are not overridden panic via the
+// nil embedded interface.
+type delayRecordingWorker struct {
+	utils.ReconcileWorker
+	delays map[utils.QualifiedName]time.Duration
+}
+
+func (w *delayRecordingWorker) EnqueueWithDelay(qualifiedName utils.QualifiedName, delay time.Duration) {
+	w.delays[qualifiedName] = delay
+}
+
+// EnqueueForRetry is recorded with a zero delay.
+func (w *delayRecordingWorker) EnqueueForRetry(qualifiedName utils.QualifiedName) {
+	w.delays[qualifiedName] = 0
+}
+
+func TestEnqueueForTargetChange(t *testing.T) {
+	qualifiedName := utils.QualifiedName{Namespace: "ns", Name: "foo"}
+
+	testCases := map[string]struct {
+		interval      time.Duration
+		expectedDelay time.Duration
+	}{
+		"changes are collected immediately by default": {},
+		"changes are collected at the configured interval": {
+			interval:      time.Minute,
+			expectedDelay: time.Minute,
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			worker := &delayRecordingWorker{delays: make(map[utils.QualifiedName]time.Duration)}
+			s := &KubeFedStatusController{
+				worker:             worker,
+				collectionInterval: tc.interval,
+			}
+			s.enqueueForTargetChange(qualifiedName)
+			if delay, ok := worker.delays[qualifiedName]; !ok || delay != tc.expectedDelay {
+				t.Fatalf("Expected %q to be enqueued with a delay of %v, got %v", qualifiedName, tc.expectedDelay, worker.delays)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"context"
+	"reflect"
+	"sort"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/json"
+
+	"sigs.k8s.io/kubefed/pkg/controller/utils"
+)
+
+const (
+	// aggregatedStatusField is the field of the status of a federated
+	// resource that the aggregated status is written to. It is
+	// distinct from the remote status reported per cluster.
+	aggregatedStatusField = "aggregatedStatus"
+)
+
+// StatusAggregator computes the value written to the aggregated
+// status of a federated resource from the status of its target
+// resources in member clusters. A nil value indicates that there is
+// nothing to aggregate.
+type StatusAggregator func(clusterStatus []utils.ResourceClusterStatus) (map[string]interface{}, error)
+
+// statusAggregators maps target kinds to the aggregator of their
+// status.
+var statusAggregators = map[string]StatusAggregator{
+	"Service": aggregateServiceStatus,
+}
+
+// aggregateServiceStatus computes the union of the load balancer
+// ingress points assigned to a service across member clusters.
+func aggregateServiceStatus(clusterStatus []utils.ResourceClusterStatus) (map[string]interface{}, error) {
+	ingressByKey := make(map[string]interface{})
+	for _, status := range clusterStatus {
+		ingress, _, err := unstructured.NestedSlice(status.Status, "loadBalancer", "ingress")
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed to read load balancer ingress for cluster %q", status.ClusterName)
+		}
+		for _, point := range ingress {
+			key, err := json.Marshal(point)
+			if err != nil {
+				return nil, errors.Wrapf(err, "Failed to marshal load balancer ingress for cluster %q", status.ClusterName)
+			}
+			ingressByKey[string(key)] = point
+		}
+	}
+	if len(ingressByKey) == 0 {
+		return nil, nil
+	}
+
+	// Order the ingress points so that the aggregated value does not
+	// change with the order in which clusters report them.
+	keys := make([]string, 0, len(ingressByKey))
+	for key := range ingressByKey {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	ingress := make([]interface{}, 0, len(keys))
+	for _, key := range keys {
+		ingress = append(ingress, ingressByKey[key])
+	}
+	return map[string]interface{}{
+		"loadBalancer": map[string]interface{}{
+			"ingress": ingress,
+		},
+	}, nil
+}
+
+// updateAggregatedStatus writes the status aggregated from member
+// clusters to the federated resource. To avoid a write-back loop with
+// the sync controller, the federated resource is only updated when
+// the aggregated value has changed.
+func (s *KubeFedStatusController) updateAggregatedStatus(fedObject *unstructured.Unstructured, clusterStatus []utils.ResourceClusterStatus) error {
+	aggregate, ok := statusAggregators[s.typeConfig.GetTargetType().Kind]
+	if !ok {
+		return nil
+	}
+	value, err := aggregate(clusterStatus)
+	if err != nil {
+		return err
+	}
+	value, err = normalizeAggregatedStatus(value)
+	if err != nil {
+		return err
+	}
+
+	existing, _, err := unstructured.NestedMap(fedObject.Object, utils.StatusField, aggregatedStatusField)
+	if err != nil {
+		return errors.Wrap(err, "Failed to read aggregated status")
+	}
+	if reflect.DeepEqual(existing, value) {
+		return nil
+	}
+
+	if value == nil {
+		unstructured.RemoveNestedField(fedObject.Object, utils.StatusField, aggregatedStatusField)
+	} else {
+		err = unstructured.SetNestedMap(fedObject.Object, value, utils.StatusField, aggregatedStatusField)
+		if err != nil {
+			return errors.Wrap(err, "Failed to set aggregated status")
+		}
+	}
+	_, err = s.federatedTypeClient.Resources(fedObject.GetNamespace()).UpdateStatus(context.Background(), fedObject, metav1.UpdateOptions{})
+	return err
+}
+
+// normalizeAggregatedStatus round-trips the given value through json
+// so that it can be compared with the value read from the API.
+func normalizeAggregatedStatus(value map[string]interface{}) (map[string]interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to marshal aggregated status")
+	}
+	normalized := make(map[string]interface{})
+	err = json.Unmarshal(data, &normalized)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to unmarshal aggregated status")
+	}
+	return normalized, nil
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/fake"
+
+	fedv1b1 "sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
+	"sigs.k8s.io/kubefed/pkg/controller/utils"
+)
+
+type fakeResourceClient struct {
+	client dynamic.Interface
+	gvr    schema.GroupVersionResource
+}
+
+func (c *fakeResourceClient) Resources(namespace string) dynamic.ResourceInterface {
+	return c.client.Resource(c.gvr).Namespace(namespace)
+}
+
+func (c *fakeResourceClient) Kind() string {
+	return "FederatedService"
+}
+
+func loadBalancerStatus(clusterName string, ips ...string) utils.ResourceClusterStatus {
+	var ingress []interface{}
+	for _, ip := range ips {
+		ingress = append(ingress, map[string]interface{}{"ip": ip})
+	}
+	return utils.ResourceClusterStatus{
+		ClusterName: clusterName,
+		Status: map[string]interface{}{
+			"loadBalancer": map[string]interface{}{
+				"ingress": ingress,
+			},
+		},
+	}
+}
+
+func TestUpdateAggregatedStatus(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "types.kubefed.io", Version: "v1beta1", Resource: "federatedservices"}
+	fedObject := &unstructured.Unstructured{}
+	fedObject.SetAPIVersion("types.kubefed.io/v1beta1")
+	fedObject.SetKind("FederatedService")
+	fedObject.SetNamespace("ns")
+	fedObject.SetName("foo")
+
+	dynamicClient := fake.NewSimpleDynamicClient(runtime.NewScheme(), fedObject)
+	s := &KubeFedStatusController{
+		typeConfig: &fedv1b1.FederatedTypeConfig{
+			Spec: fedv1b1.FederatedTypeConfigSpec{
+				TargetType: fedv1b1.APIResource{
+					Version:    "v1",
+					Kind:       "Service",
+					PluralName: "services",
+					Scope:      "Namespaced",
+				},
+			},
+		},
+		federatedTypeClient: &fakeResourceClient{client: dynamicClient, gvr: gvr},
+	}
+
+	clusterStatus := []utils.ResourceClusterStatus{
+		loadBalancerStatus("cluster1", "10.0.0.2", "10.0.0.1"),
+		loadBalancerStatus("cluster2", "10.0.0.1", "10.0.0.3"),
+	}
+	expected := map[string]interface{}{
+		"loadBalancer": map[string]interface{}{
+			"ingress": []interface{}{
+				map[string]interface{}{"ip": "10.0.0.1"},
+				map[string]interface{}{"ip": "10.0.0.2"},
+				map[string]interface{}{"ip": "10.0.0.3"},
+			},
+		},
+	}
+
+	statusUpdates := func() int {
+		count := 0
+		for _, action := range dynamicClient.Actions() {
+			if action.GetVerb() == "update" && action.GetSubresource() == "status" {
+				count++
+			}
+		}
+		return count
+	}
+
+	// Reconciling repeatedly with unchanged cluster status must only
+	// write the aggregated value once.
+	for i := 0; i < 3; i++ {
+		current, err := s.federatedTypeClient.Resources("ns").Get(context.Background(), "foo", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Unexpected error getting federated object: %v", err)
+		}
+		if err := s.updateAggregatedStatus(current, clusterStatus); err != nil {
+			t.Fatalf("Unexpected error updating aggregated status: %v", err)
+		}
+	}
+	if count := statusUpdates(); count != 1 {
+		t.Fatalf("Expected the aggregated status to be written once, got %d writes", count)
+	}
+
+	current, err := s.federatedTypeClient.Resources("ns").Get(context.Background(), "foo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error getting federated object: %v", err)
+	}
+	aggregated, _, err := unstructured.NestedMap(current.Object, utils.StatusField, aggregatedStatusField)
+	if err != nil {
+		t.Fatalf("Unexpected error reading aggregated status: %v", err)
+	}
+	if !reflect.DeepEqual(aggregated, expected) {
+		t.Fatalf("Expected aggregated status %v, got %v", expected, aggregated)
+	}
+}
@@ -18,8 +18,13 @@ package sync
 
 import (
 	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	pkgruntime "k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
@@ -29,6 +34,7 @@ import (
 
 	"sigs.k8s.io/kubefed/pkg/apis/core/typeconfig"
 	genericclient "sigs.k8s.io/kubefed/pkg/client/generic"
+	"sigs.k8s.io/kubefed/pkg/controller/sync/transform"
 	"sigs.k8s.io/kubefed/pkg/controller/sync/version"
 	"sigs.k8s.io/kubefed/pkg/controller/utils"
 )
@@ -40,6 +46,7 @@ type FederatedResourceAccessor interface {
 	HasSynced() bool
 	FederatedResource(qualifiedName utils.QualifiedName) (federatedResource FederatedResource, possibleOrphan bool, err error)
 	VisitFederatedResources(visitFunc func(obj interface{}))
+	ReconcilePriority(eventSource utils.QualifiedName) int
 }
 
 type resourceAccessor struct {
@@ -69,6 +76,56 @@ type resourceAccessor struct {
 	// Manages propagated versions
 	versionManager *version.Manager
 
+	// Transforms objects for member clusters if the type configures
+	// a transformation webhook.
+	transformer transform.Transformer
+
+	// Resolves the ConfigMaps and Secrets referenced by overrides and
+	// reconciles the federated resources that reference them when
+	// they change. Nil for cluster-scoped federated types.
+	overrideSources *overrideSourceWatcher
+
+	// Schema of the target type used to prune unknown fields from
+	// objects for member clusters if the type enables pruning.
+	pruneSchema *apiextv1.JSONSchemaProps
+
+	// Schema of the target type used to validate the values of
+	// overrides, if the target type is defined by a CRD.
+	targetSchema *apiextv1.JSONSchemaProps
+
+	// Retrieves the schema of the target type used to validate the
+	// values of overrides. Only set until the schema has been
+	// retrieved, which is deferred until a resource with overrides is
+	// reconciled if the schema is not kept current by the informer
+	// for the CRD of the target type and not required for pruning.
+	loadTargetSchema func() (*apiextv1.JSONSchemaProps, error)
+
+	// Guards the schemas, which are refreshed when the CRD of the
+	// target type changes.
+	schemaLock sync.RWMutex
+
+	// The informer for the CRD of the target type. Only initialized
+	// if the target type may be defined by a CRD and the control
+	// plane is not limited to a namespace, in which case the schemas
+	// are retrieved once.
+	crdStore      cache.Store
+	crdController cache.Controller
+
+	// Labels and annotations added to managed resources in addition
+	// to the managed label.
+	managedLabels      map[string]string
+	managedAnnotations map[string]string
+	// The label selector fields of the target type, which the managed
+	// labels are kept out of.
+	selectorFields []string
+	// The key of the annotation listing the clusters that a managed
+	// resource is propagated to, if any.
+	placementAnnotation string
+
+	// The retention of clusters last determined for each federated
+	// resource by placement stickiness.
+	retainedClusters *utils.SafeMap
+
 	// Records events on the federated resource
 	eventRecorder record.EventRecorder
 	// ctx is the context that governs the Manager's operations, allowing for graceful shutdowns or cancellations.
@@ -85,23 +142,76 @@ func NewFederatedResourceAccessor(ctx context.Context, immediate bool, controlle
 		fedNamespace:            controllerConfig.KubeFedNamespace,
 		fedNamespaceAPIResource: fedNamespaceAPIResource,
 		eventRecorder:           eventRecorder,
+		retainedClusters:        utils.NewSafeMap(),
+		managedLabels:           controllerConfig.ManagedLabels,
+		managedAnnotations:      controllerConfig.ManagedAnnotations,
+		selectorFields:          utils.SelectorFields(typeConfig),
+		placementAnnotation:     controllerConfig.PlacementAnnotation,
+	}
+
+	var err error
+	a.transformer, err = transform.NewWebhookTransformer(typeConfig.GetTransformationWebhook())
+	if err != nil {
+		return nil, err
+	}
+
+	targetAPIResource := typeConfig.GetTargetType()
+	if len(targetAPIResource.Group) > 0 && !a.limitedScope {
+		// The schemas are kept current with the CRD of the target
+		// type so that an upgrade of the CRD is reflected without a
+		// restart, and resources are reconciled again with them.
+		crdClient, err := utils.NewResourceClient(controllerConfig.KubeConfig, &utils.CustomResourceDefinitionAPIResource, utils.WithRequestMetrics())
+		if err != nil {
+			return nil, err
+		}
+		crdName := utils.TargetCRDName(targetAPIResource)
+		a.crdStore, a.crdController = utils.NewNamedResourceInformer(crdClient, &utils.CustomResourceDefinitionAPIResource, crdName, func(runtimeclient.Object) {
+			a.refreshTargetSchema(crdName)
+			for _, obj := range a.federatedStore.List() {
+				enqueueObj(obj.(runtimeclient.Object))
+			}
+		})
+	} else if typeConfig.GetPruneUnknownFields() {
+		targetSchema, err := utils.GetTargetSchema(controllerConfig.KubeConfig, targetAPIResource)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed to retrieve the schema of %q for pruning of unknown fields", targetAPIResource.Kind)
+		}
+		a.setTargetSchema(targetSchema, nil)
+	} else if len(targetAPIResource.Group) > 0 {
+		a.loadTargetSchema = func() (*apiextv1.JSONSchemaProps, error) {
+			return utils.GetTargetSchema(controllerConfig.KubeConfig, targetAPIResource)
+		}
 	}
 
 	targetNamespace := controllerConfig.TargetNamespace
 
 	federatedTypeAPIResource := typeConfig.GetFederatedType()
-	federatedTypeClient, err := utils.NewResourceClient(controllerConfig.KubeConfig, &federatedTypeAPIResource)
+	federatedTypeClient, err := utils.NewResourceClient(controllerConfig.KubeConfig, &federatedTypeAPIResource, utils.WithRequestMetrics())
 	if err != nil {
 		return nil, err
 	}
-	a.federatedStore, a.federatedController = utils.NewResourceInformer(federatedTypeClient, targetNamespace, &federatedTypeAPIResource, enqueueObj)
+	federatedTrigger := enqueueObj
+	if typeConfig.GetFederatedNamespaced() {
+		federatedKind := federatedTypeAPIResource.Kind
+		a.overrideSources = newOverrideSourceWatcher(ctx, controllerConfig.KubeConfig, targetNamespace, func(fedName utils.QualifiedName) {
+			obj, err := utils.ObjFromCache(a.federatedStore, federatedKind, fedName.String())
+			if err == nil && obj != nil {
+				enqueueObj(obj)
+			}
+		})
+		federatedTrigger = func(obj runtimeclient.Object) {
+			a.indexOverrideSources(obj)
+			enqueueObj(obj)
+		}
+	}
+	a.federatedStore, a.federatedController = utils.NewResourceInformer(federatedTypeClient, targetNamespace, &federatedTypeAPIResource, federatedTrigger)
 
 	if a.targetIsNamespace {
 		// Initialize an informer for namespaces.  The namespace
 		// containing a federated namespace resource is used as the
 		// template for target resources in member clusters.
 		namespaceAPIResource := typeConfig.GetTargetType()
-		namespaceTypeClient, err := utils.NewResourceClient(controllerConfig.KubeConfig, &namespaceAPIResource)
+		namespaceTypeClient, err := utils.NewResourceClient(controllerConfig.KubeConfig, &namespaceAPIResource, utils.WithRequestMetrics())
 		if err != nil {
 			return nil, err
 		}
@@ -128,7 +238,7 @@ func NewFederatedResourceAccessor(ctx context.Context, immediate bool, controlle
 		// Initialize an informer for federated namespaces.  Placement
 		// for a resource is computed as the intersection of resource
 		// and federated namespace placement.
-		fedNamespaceClient, err := utils.NewResourceClient(controllerConfig.KubeConfig, fedNamespaceAPIResource)
+		fedNamespaceClient, err := utils.NewResourceClient(controllerConfig.KubeConfig, fedNamespaceAPIResource, utils.WithRequestMetrics())
 		if err != nil {
 			return nil, err
 		}
@@ -140,9 +250,96 @@ func NewFederatedResourceAccessor(ctx context.Context, immediate bool, controlle
 	return a, nil
 }
 
+// refreshTargetSchema sets the schemas of the target type from the
+// named CRD as last observed by the CRD informer.
+func (a *resourceAccessor) refreshTargetSchema(crdName string) {
+	obj, exists, err := a.crdStore.GetByKey(crdName)
+	if err != nil || !exists {
+		a.setTargetSchema(nil, err)
+		return
+	}
+	crd := &apiextv1.CustomResourceDefinition{}
+	err = pkgruntime.DefaultUnstructuredConverter.FromUnstructured(obj.(*unstructured.Unstructured).Object, crd)
+	if err != nil {
+		a.setTargetSchema(nil, errors.Wrapf(err, "Failed to decode crd %q", crdName))
+		return
+	}
+	a.setTargetSchema(utils.CRDVersionSchema(crd, a.typeConfig.GetTargetType().Version))
+}
+
+// setTargetSchema sets the given schema of the target type, or no
+// schema if it could not be retrieved.
+func (a *resourceAccessor) setTargetSchema(targetSchema *apiextv1.JSONSchemaProps, err error) {
+	kind := a.typeConfig.GetTargetType().Kind
+	if err != nil {
+		// Override values are validated on a best-effort basis
+		// without a schema.
+		klog.Errorf("Failed to retrieve the schema of %q; override values will not be validated against it: %v", kind, err)
+		targetSchema = nil
+	}
+	var pruneSchema *apiextv1.JSONSchemaProps
+	if a.typeConfig.GetPruneUnknownFields() {
+		if targetSchema == nil {
+			klog.Warningf("Pruning of unknown fields is only supported for types defined by a CRD with a schema; fields of %q will not be pruned", kind)
+		}
+		pruneSchema = targetSchema
+	}
+	a.schemaLock.Lock()
+	defer a.schemaLock.Unlock()
+	a.pruneSchema = pruneSchema
+	a.targetSchema = targetSchema
+}
+
+// schemas returns the schemas of the target type for the given
+// federated resource, retrieving the schema used to validate the
+// values of overrides if the resource has overrides and it has yet to
+// be retrieved. Retrieval is attempted again for the next resource
+// with overrides if it fails.
+func (a *resourceAccessor) schemas(resource *unstructured.Unstructured) (*apiextv1.JSONSchemaProps, *apiextv1.JSONSchemaProps) {
+	a.schemaLock.RLock()
+	pruneSchema, targetSchema, loadTargetSchema := a.pruneSchema, a.targetSchema, a.loadTargetSchema
+	a.schemaLock.RUnlock()
+	if loadTargetSchema == nil {
+		return pruneSchema, targetSchema
+	}
+	if _, ok, _ := unstructured.NestedFieldNoCopy(resource.Object, utils.SpecField, utils.OverridesField); !ok {
+		return pruneSchema, targetSchema
+	}
+	targetSchema, err := loadTargetSchema()
+	if err != nil {
+		// Override values are validated on a best-effort basis
+		// without a schema.
+		klog.Errorf("Failed to retrieve the schema of %q; override values will not be validated against it: %v", a.typeConfig.GetTargetType().Kind, err)
+		return pruneSchema, nil
+	}
+	a.schemaLock.Lock()
+	defer a.schemaLock.Unlock()
+	a.targetSchema = targetSchema
+	a.loadTargetSchema = nil
+	return pruneSchema, targetSchema
+}
+
+// indexOverrideSources records the sources referenced by the overrides
+// of the given federated resource, which may have been deleted.
+func (a *resourceAccessor) indexOverrideSources(obj runtimeclient.Object) {
+	fedName := utils.NewQualifiedName(obj)
+	var sources []utils.OverrideSource
+	if _, exists, err := a.federatedStore.Get(obj); err == nil && exists {
+		// Invalid overrides are reported when the resource is
+		// reconciled.
+		if overridesMap, err := utils.GetOverrides(obj.(*unstructured.Unstructured)); err == nil {
+			sources = overridesMap.ValueSources(fedName.Namespace)
+		}
+	}
+	a.overrideSources.SetDependent(fedName, sources)
+}
+
 func (a *resourceAccessor) Run(stopChan <-chan struct{}) {
 	go a.versionManager.Sync(stopChan)
 	go a.federatedController.Run(stopChan)
+	if a.crdController != nil {
+		go a.crdController.Run(stopChan)
+	}
 	if a.namespaceController != nil {
 		go a.namespaceController.Run(stopChan)
 	}
@@ -169,6 +366,10 @@ func (a *resourceAccessor) HasSynced() bool {
 		klog.V(2).Infof("FederatedNamespace informer for %s not synced", kind)
 		return false
 	}
+	if a.crdController != nil && !a.crdController.HasSynced() {
+		klog.V(2).Infof("CRD informer for %s not synced", kind)
+		return false
+	}
 	return true
 }
 
@@ -179,34 +380,7 @@ func (a *resourceAccessor) FederatedResource(eventSource utils.QualifiedName) (F
 	}
 
 	kind := a.typeConfig.GetFederatedType().Kind
-
-	// Most federated resources have the same name as their targets.
-	targetName := utils.QualifiedName{
-		Namespace: eventSource.Namespace,
-		Name:      eventSource.Name,
-	}
-	federatedName := utils.QualifiedName{
-		Namespace: utils.NamespaceForResource(eventSource.Namespace, a.fedNamespace),
-		Name:      eventSource.Name,
-	}
-
-	// A federated type for namespace "foo" is namespaced
-	// (e.g. "foo/foo"). An event sourced from a namespace in the host
-	// or member clusters will have the name "foo", and an event
-	// sourced from a federated resource will have the name "foo/foo".
-	// In order to ensure object retrieval from the informers, it is
-	// necessary to derive the target name and federated name from the
-	// event source.
-	if a.targetIsNamespace {
-		eventSourceIsTarget := eventSource.Namespace == ""
-		if eventSourceIsTarget {
-			// Ensure the federated name is namespace qualified.
-			federatedName.Namespace = federatedName.Name
-		} else {
-			// Ensure the target name is not namespace qualified.
-			targetName.Namespace = ""
-		}
-	}
+	targetName, federatedName := a.namesForEventSource(eventSource)
 
 	key := federatedName.String()
 
@@ -227,6 +401,23 @@ func (a *resourceAccessor) FederatedResource(eventSource utils.QualifiedName) (F
 		return nil, possibleOrphan, nil
 	}
 
+	if nameTemplate := a.typeConfig.GetTargetNameTemplate(); len(nameTemplate) > 0 && !a.targetIsNamespace {
+		// Resources in member clusters are named according to the
+		// template.  A resource being deleted targets the name
+		// recorded in status to ensure removal of the resources that
+		// were actually propagated.
+		recordedName := utils.GetRecordedTargetName(resource)
+		if resource.GetDeletionTimestamp() != nil && len(recordedName) > 0 {
+			targetName.Name = recordedName
+		} else {
+			targetName.Name, err = utils.ComputeTargetName(nameTemplate, resource.GetLabels())
+			if err != nil {
+				a.eventRecorder.Eventf(resource, corev1.EventTypeWarning, "ComputeTargetNameFailed", err.Error())
+				return nil, false, errors.Wrapf(err, "failed to compute target name for %s %q", kind, key)
+			}
+		}
+	}
+
 	var namespace *unstructured.Unstructured
 	if a.targetIsNamespace {
 		if federatedName.Namespace != federatedName.Name {
@@ -258,21 +449,82 @@ func (a *resourceAccessor) FederatedResource(eventSource utils.QualifiedName) (F
 		// will be removed.
 	}
 
+	pruneSchema, targetSchema := a.schemas(resource)
+
 	return &federatedResource{
-		limitedScope:      a.limitedScope,
-		typeConfig:        a.typeConfig,
-		targetIsNamespace: a.targetIsNamespace,
-		targetName:        targetName,
-		federatedKind:     kind,
-		federatedName:     federatedName,
-		federatedResource: resource,
-		versionManager:    a.versionManager,
-		namespace:         namespace,
-		fedNamespace:      fedNamespace,
-		eventRecorder:     a.eventRecorder,
+		limitedScope:        a.limitedScope,
+		typeConfig:          a.typeConfig,
+		targetIsNamespace:   a.targetIsNamespace,
+		targetName:          targetName,
+		federatedKind:       kind,
+		federatedName:       federatedName,
+		federatedResource:   resource,
+		versionManager:      a.versionManager,
+		namespace:           namespace,
+		fedNamespace:        fedNamespace,
+		eventRecorder:       a.eventRecorder,
+		transformer:         a.transformer,
+		pruneSchema:         pruneSchema,
+		targetSchema:        targetSchema,
+		managedLabels:       a.managedLabels,
+		managedAnnotations:  a.managedAnnotations,
+		selectorFields:      a.selectorFields,
+		placementAnnotation: a.placementAnnotation,
+		overrideSources:     a.overrideSources,
+		retainedClusters:    a.retainedClusters,
 	}, false, nil
 }
 
+// namesForEventSource returns the names of the target resource and
+// the federated resource for the given event source.
+func (a *resourceAccessor) namesForEventSource(eventSource utils.QualifiedName) (targetName, federatedName utils.QualifiedName) {
+	// Most federated resources have the same name as their targets.
+	targetName = utils.QualifiedName{
+		Namespace: eventSource.Namespace,
+		Name:      eventSource.Name,
+	}
+	federatedName = utils.QualifiedName{
+		Namespace: utils.NamespaceForResource(eventSource.Namespace, a.fedNamespace),
+		Name:      eventSource.Name,
+	}
+
+	// A federated type for namespace "foo" is namespaced
+	// (e.g. "foo/foo"). An event sourced from a namespace in the host
+	// or member clusters will have the name "foo", and an event
+	// sourced from a federated resource will have the name "foo/foo".
+	// In order to ensure object retrieval from the informers, it is
+	// necessary to derive the target name and federated name from the
+	// event source.
+	if a.targetIsNamespace {
+		eventSourceIsTarget := eventSource.Namespace == ""
+		if eventSourceIsTarget {
+			// Ensure the federated name is namespace qualified.
+			federatedName.Namespace = federatedName.Name
+		} else {
+			// Ensure the target name is not namespace qualified.
+			targetName.Namespace = ""
+		}
+	}
+
+	return targetName, federatedName
+}
+
+// ReconcilePriority returns the reconcile priority of the federated
+// resource for the given event source, or the default priority if the
+// federated resource is not cached.
+func (a *resourceAccessor) ReconcilePriority(eventSource utils.QualifiedName) int {
+	_, federatedName := a.namesForEventSource(eventSource)
+	cachedObj, exist, err := a.federatedStore.GetByKey(federatedName.String())
+	if err != nil || !exist {
+		return utils.DefaultReconcilePriority
+	}
+	priority, err := utils.GetReconcilePriority(cachedObj.(*unstructured.Unstructured))
+	if err != nil {
+		klog.Warningf("Using the default reconcile priority for %s %q: %v", a.typeConfig.GetFederatedType().Kind, federatedName, err)
+	}
+	return priority
+}
+
 func (a *resourceAccessor) VisitFederatedResources(visitFunc func(obj interface{})) {
 	for _, obj := range a.federatedStore.List() {
 		visitFunc(obj)
@@ -0,0 +1,155 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sync
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/cache"
+
+	fedv1b1 "sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
+)
+
+func TestRefreshTargetSchema(t *testing.T) {
+	const crdName = "widgets.example.io"
+	crdWithFields := func(fields ...string) *unstructured.Unstructured {
+		properties := map[string]apiextv1.JSONSchemaProps{}
+		for _, field := range fields {
+			properties[field] = apiextv1.JSONSchemaProps{Type: "string"}
+		}
+		crd := &apiextv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: crdName},
+			Spec: apiextv1.CustomResourceDefinitionSpec{
+				Versions: []apiextv1.CustomResourceDefinitionVersion{{
+					Name: "v1",
+					Schema: &apiextv1.CustomResourceValidation{
+						OpenAPIV3Schema: &apiextv1.JSONSchemaProps{Type: "object", Properties: properties},
+					},
+				}},
+			},
+		}
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(crd)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return &unstructured.Unstructured{Object: content}
+	}
+	a := &resourceAccessor{
+		typeConfig: &fedv1b1.FederatedTypeConfig{
+			Spec: fedv1b1.FederatedTypeConfigSpec{
+				TargetType: fedv1b1.APIResource{
+					Group:      "example.io",
+					Version:    "v1",
+					Kind:       "Widget",
+					PluralName: "widgets",
+				},
+				PruneUnknownFields: true,
+			},
+		},
+		crdStore: cache.NewStore(cache.MetaNamespaceKeyFunc),
+	}
+	expectFields := func(fields ...string) {
+		t.Helper()
+		a.refreshTargetSchema(crdName)
+		if len(fields) == 0 {
+			if a.pruneSchema != nil || a.targetSchema != nil {
+				t.Fatalf("Expected no schema")
+			}
+			return
+		}
+		for _, schema := range []*apiextv1.JSONSchemaProps{a.pruneSchema, a.targetSchema} {
+			if schema == nil || len(schema.Properties) != len(fields) {
+				t.Fatalf("Expected a schema with fields %v, got %v", fields, schema)
+			}
+			for _, field := range fields {
+				if _, ok := schema.Properties[field]; !ok {
+					t.Fatalf("Expected a schema with fields %v, got %v", fields, schema.Properties)
+				}
+			}
+		}
+	}
+
+	if err := a.crdStore.Add(crdWithFields("size")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expectFields("size")
+
+	// An upgrade of the crd is reflected in the schemas.
+	if err := a.crdStore.Update(crdWithFields("size", "color")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expectFields("size", "color")
+
+	if err := a.crdStore.Delete(crdWithFields()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expectFields()
+}
+
+func TestSchemasRetrieveTargetSchemaForResourceWithOverrides(t *testing.T) {
+	loads := 0
+	var loadErr error
+	a := &resourceAccessor{
+		typeConfig: &fedv1b1.FederatedTypeConfig{
+			Spec: fedv1b1.FederatedTypeConfigSpec{
+				TargetType: fedv1b1.APIResource{Group: "example.io", Version: "v1", Kind: "Widget"},
+			},
+		},
+		loadTargetSchema: func() (*apiextv1.JSONSchemaProps, error) {
+			loads++
+			if loadErr != nil {
+				return nil, loadErr
+			}
+			return &apiextv1.JSONSchemaProps{Type: "object"}, nil
+		},
+	}
+	withoutOverrides := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{},
+	}}
+	withOverrides := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{"overrides": []interface{}{}},
+	}}
+	expectSchema := func(resource *unstructured.Unstructured, expectedSchema bool, expectedLoads int) {
+		t.Helper()
+		_, targetSchema := a.schemas(resource)
+		if (targetSchema != nil) != expectedSchema {
+			t.Fatalf("Expected a target schema: %v, got %v", expectedSchema, targetSchema)
+		}
+		if loads != expectedLoads {
+			t.Fatalf("Expected the schema to be retrieved %d times, got %d", expectedLoads, loads)
+		}
+	}
+
+	// The schema is not retrieved until a resource has overrides.
+	expectSchema(withoutOverrides, false, 0)
+
+	// A failed retrieval is attempted again.
+	loadErr = errors.New("unavailable")
+	expectSchema(withOverrides, false, 1)
+	loadErr = nil
+	expectSchema(withOverrides, true, 2)
+
+	// The retrieved schema is reused.
+	expectSchema(withOverrides, true, 2)
+	expectSchema(withoutOverrides, true, 2)
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sync
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	fedv1b1 "sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
+	"sigs.k8s.io/kubefed/pkg/controller/sync/status"
+	"sigs.k8s.io/kubefed/pkg/controller/utils"
+)
+
+// availableCanaryClusters returns the names of the given canary
+// clusters that are ready and not in maintenance. Canary clusters that
+// are unavailable cannot be propagated to, and holding back the rest
+// of the fleet until they recover would block the rollout on an
+// outage unrelated to the resource.
+func availableCanaryClusters(clusters []*fedv1b1.KubeFedCluster, canaryClusterNames sets.Set[string]) sets.Set[string] {
+	available := sets.New[string]()
+	for _, cluster := range clusters {
+		if canaryClusterNames.Has(cluster.Name) && utils.IsClusterReady(&cluster.Status) && !utils.IsClusterInMaintenance(cluster) {
+			available.Insert(cluster.Name)
+		}
+	}
+	return available
+}
+
+// canaryRollout returns the state of the rollout of the given
+// resource to the given canary clusters, or nil if there are none.
+// The resource is healthy in a canary cluster once its current
+// version has been propagated there and the status of the resource
+// in the cluster reports it healthy.
+func (s *KubeFedSyncController) canaryRollout(fedResource FederatedResource, canaryClusterNames sets.Set[string]) (*status.CanaryStatus, error) {
+	if canaryClusterNames.Len() == 0 {
+		return nil, nil
+	}
+
+	key := fedResource.TargetName().String()
+	var pending *status.CanaryStatus
+	for _, clusterName := range sets.List(canaryClusterNames) {
+		// The remaining canary clusters are checked even if one is
+		// pending since a failure in any of them takes precedence.
+		rawClusterObj, _, err := s.informer.GetTargetStore().GetByKey(clusterName, key)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed to retrieve cached cluster object %q", key)
+		}
+		if rawClusterObj == nil {
+			pending = pendingCanary(pending, fmt.Sprintf("Waiting for propagation to canary cluster %q", clusterName))
+			continue
+		}
+		clusterObj := rawClusterObj.(*unstructured.Unstructured)
+		current, err := resourceCurrent(fedResource, clusterName, clusterObj)
+		if err != nil {
+			return nil, err
+		}
+		if !current {
+			pending = pendingCanary(pending, fmt.Sprintf("Waiting for propagation to canary cluster %q", clusterName))
+			continue
+		}
+		healthy, failure := utils.CanaryHealth(clusterObj)
+		if len(failure) > 0 {
+			return &status.CanaryStatus{
+				Reason:  status.CanaryFailed,
+				Message: fmt.Sprintf("Resource failed in canary cluster %q: %s", clusterName, failure),
+			}, nil
+		}
+		if !healthy {
+			pending = pendingCanary(pending, fmt.Sprintf("Waiting for the resource to become healthy in canary cluster %q", clusterName))
+		}
+	}
+	if pending != nil {
+		return pending, nil
+	}
+	return &status.CanaryStatus{}, nil
+}
+
+// pendingCanary returns the given pending state, or a new pending
+// state with the given message if there is none, so that the first
+// canary cluster found pending is reported.
+func pendingCanary(pending *status.CanaryStatus, message string) *status.CanaryStatus {
+	if pending != nil {
+		return pending
+	}
+	return &status.CanaryStatus{Reason: status.CanaryPending, Message: message}
+}
+
+// resourceCurrent returns whether the version of the given resource
+// in the named cluster is the version last propagated to the cluster
+// for the current template and overrides.
+func resourceCurrent(fedResource FederatedResource, clusterName string, clusterObj *unstructured.Unstructured) (bool, error) {
+	if clusterObj == nil {
+		return false, nil
+	}
+	version, err := fedResource.VersionForCluster(clusterName)
+	if err != nil {
+		return false, err
+	}
+	return len(version) > 0 && utils.ObjectVersion(clusterObj) == version, nil
+}
+
+// canaryApplyFailure returns the state of a rollout halted by a
+// failure to apply to one of the given canary clusters, or nil if
+// applying did not fail.
+func canaryApplyFailure(canaryClusterNames sets.Set[string], applyResults map[string]status.ClusterApplyResult) *status.CanaryStatus {
+	for _, clusterName := range sets.List(canaryClusterNames) {
+		applyResult, ok := applyResults[clusterName]
+		if !ok || applyResult.Result != status.ApplyFailed {
+			continue
+		}
+		return &status.CanaryStatus{
+			Reason:  status.CanaryFailed,
+			Message: fmt.Sprintf("Propagation to canary cluster %q failed: %s", clusterName, applyResult.Error),
+		}
+	}
+	return nil
+}
@@ -20,9 +20,14 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
 	restclient "k8s.io/client-go/rest"
 
 	corev1 "k8s.io/api/core/v1"
@@ -45,7 +50,9 @@ import (
 	"sigs.k8s.io/kubefed/pkg/apis/core/typeconfig"
 	fedv1b1 "sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
 	genericclient "sigs.k8s.io/kubefed/pkg/client/generic"
+	"sigs.k8s.io/kubefed/pkg/controller/quota"
 	"sigs.k8s.io/kubefed/pkg/controller/sync/dispatch"
+	"sigs.k8s.io/kubefed/pkg/controller/sync/notify"
 	"sigs.k8s.io/kubefed/pkg/controller/sync/status"
 	"sigs.k8s.io/kubefed/pkg/controller/utils"
 	"sigs.k8s.io/kubefed/pkg/metrics"
@@ -54,6 +61,39 @@ import (
 const (
 	allClustersKey = "ALL_CLUSTERS"
 
+	// federatedObjectMetricsPeriod is the interval at which the number
+	// of federated objects in each phase is recomputed.
+	federatedObjectMetricsPeriod = 30 * time.Second
+
+	// reconcileAllInterval is the delay between the enqueueing of
+	// successive federated objects when reconciliation of all objects
+	// of a type is requested, bounding the rate of the resulting
+	// reconciliations.
+	reconcileAllInterval = 10 * time.Millisecond
+
+	// observeInterval is the delay before a federated resource of an
+	// observe-only type is reconciled again to refresh the status of
+	// the observed resources, which are not cached unless they have
+	// the managed label.
+	observeInterval = time.Minute
+
+	// canaryRecheckInterval is the delay before a federated resource
+	// whose canary clusters are not yet healthy is reconciled again,
+	// in case the version recorded for a canary cluster lags the
+	// change of the resource in the cluster.
+	canaryRecheckInterval = 10 * time.Second
+
+	// forbiddenRetryInterval is the delay before a federated resource
+	// whose creation or update was forbidden by a member cluster is
+	// reconciled again. Such requests only succeed once the cluster
+	// is reconfigured, e.g. by granting authorization, so they are not
+	// retried with the backoff of transient errors.
+	forbiddenRetryInterval = 5 * time.Minute
+
+	// tracerName is the instrumentation name of the tracer that
+	// records the spans of reconciliations.
+	tracerName = "sigs.k8s.io/kubefed/pkg/controller/sync"
+
 	// FinalizerSyncController If this finalizer is present on a federated resource, the sync
 	// controller will have the opportunity to perform pre-deletion operations
 	// (like deleting managed resources from member clusters).
@@ -67,6 +107,11 @@ type KubeFedSyncController struct {
 	// This is a placeholder for a worker that will perform reconciliation tasks.
 	worker utils.ReconcileWorker
 
+	// Serializes the reconciliation of a federated resource by the
+	// worker with that by ReconcileOnce, which runs outside of the
+	// queue of the worker.
+	reconcileLocks utils.KeyedMutex
+
 	// For triggering reconciliation of all target resources. This is
 	// used when a new cluster becomes available.
 	// This allows for a delay in processing to batch handle resource reconciliations.
@@ -76,49 +121,168 @@ type KubeFedSyncController struct {
 	// This is used to monitor changes to resources across member clusters and trigger updates accordingly.
 	informer utils.FederatedInformer
 
+	// Informer for the EndpointSlices of services in member clusters
+	// from which the ready endpoints of federated services are
+	// collected. Nil unless raw status of Services is collected.
+	endpointSliceInformer utils.FederatedInformer
+
 	// For events
 	// This is used to record events related to resource reconciliation and cluster availability.
 	eventRecorder record.EventRecorder
+	// eventBroadcaster is shut down when the controller stops.
+	eventBroadcaster record.EventBroadcaster
+
+	// ctx is cancelled when the controller stops, and governs the
+	// operations initiated by reconciliation.
+	ctx context.Context
 
 	// The following are configurations for delaying processing based on cluster availability.
 	clusterAvailableDelay   time.Duration
 	clusterUnavailableDelay time.Duration
 	smallDelay              time.Duration
 
+	// Defers the creation of resources in a cluster until resources
+	// of the kinds preceding the target kind have been created there.
+	applyOrderGate *utils.ApplyOrderGate
+
 	// Configuration timeout for cache synchronization.
 	cacheSyncTimeout time.Duration
 
 	// Interface for type-specific configurations.
 	typeConfig typeconfig.Interface
 
+	// Window outside of which updates of resources in member clusters
+	// are deferred. Nil if updates are never deferred.
+	propagationWindow *utils.PropagationWindow
+
 	// Provides access to federated resources.
 	fedAccessor FederatedResourceAccessor
 
 	// Client for interacting with the host cluster.
 	hostClusterClient genericclient.Client
 
+	// Namespace of the KubeFedCluster resources, used to build the
+	// configuration of member clusters for the connectivity preflight
+	// and the discovery of their resource types.
+	kubeFedNamespace string
+
+	// Limits the rate of the requests of the clients of member
+	// clusters built for the connectivity preflight and discovery.
+	clusterClientRateLimits utils.ClientRateLimits
+
+	// Errors of the connectivity preflight keyed by the name of the
+	// member clusters that could not be reached.
+	unreachableClusters *utils.SafeMap
+
+	// Verifies that the given member cluster can be reached.
+	preflight func(cluster *fedv1b1.KubeFedCluster) error
+	// The generation of the current connectivity preflight of each
+	// available member cluster. Retries of a preflight that was
+	// superseded or whose cluster became unavailable stop.
+	preflightGenerations map[string]uint64
+	preflightLock        sync.Mutex
+
+	// The name of the CustomResourceDefinition of the target type in
+	// the host cluster, or an empty string if the target type is not
+	// a custom resource. Nil until determined.
+	targetCRD     *string
+	targetCRDLock sync.Mutex
+
+	// The key of the label that a namespace of a member cluster must
+	// have with the value "true" for resources to be created in or
+	// adopted from it. Empty if any namespace may be used.
+	namespaceOptInLabel string
+
+	// Whether propagation is paused for the control plane, in which
+	// case resources in member clusters are left untouched.
+	propagationPause *utils.PropagationPause
+
+	// Enforces the quota of the type. Nil if the type has no quota.
+	quota *quota.Controller
+
 	// Flag to control whether to adopt existing resources in the cluster.
 	skipAdoptingResources bool
 
+	// Criteria that existing resources must satisfy to be adopted.
+	adoptionPolicy *fedv1b1.ResourceAdoptionPolicy
+
+	// Whether to delete a namespace created by KubeFed in a member
+	// cluster once the last managed resource in it has been removed.
+	deleteEmptyNamespaces bool
+
+	// Returns the namespaced resource types of the named member
+	// cluster, whose resources keep a namespace from being deleted.
+	namespacedResourceTypes func(clusterName string) ([]metav1.APIResource, error)
+
+	// The names of the member clusters whose KubeFedCluster was
+	// deleted since the controller started, which the placement of
+	// federated resources may still list.
+	removedClusters *utils.SafeMap
+	// The removed clusters last reported to be listed in the
+	// placement of each federated resource, so that the report is
+	// only repeated when they change.
+	reportedRemovedClusters *utils.SafeMap
+
+	// Whether removed clusters are pruned from the placement of
+	// federated resources rather than only reported.
+	pruneRemovedClusters bool
+
+	// Tracks the outcomes of applies to taint member clusters whose
+	// applies are failing. Nil if clusters are not tainted.
+	clusterFailureTracker *utils.ClusterFailureTracker
+
 	// Flag to indicate whether the scope of resource monitoring is limited.
 	limitedScope bool
 
 	// Flag to indicate whether to collect raw resource status information.
 	rawResourceStatusCollection bool
+
+	// Whether labels and annotations added to managed resources in
+	// member clusters are preserved.
+	mergeMetadata bool
+
+	// Labels and annotations added to namespaces created in member
+	// clusters.
+	createdNamespaceLabels      map[string]string
+	createdNamespaceAnnotations map[string]string
+
+	// The interval at which managed resources are checked for
+	// out-of-band modifications. Drift is not detected if zero.
+	driftDetectionInterval time.Duration
+
+	// Records the spans of reconciliations. A no-op tracer if
+	// tracing is not configured.
+	tracer trace.Tracer
+
+	// Queues the notifications of completed propagation for delivery
+	// to a webhook. Nil if the type does not configure a valid
+	// propagation webhook.
+	notifications *notify.Queue
 }
 
-// StartKubeFedSyncController starts a new sync controller for a type config
-func StartKubeFedSyncController(ctx context.Context, immediate bool, controllerConfig *utils.ControllerConfig, stopChan <-chan struct{}, typeConfig typeconfig.Interface, fedNamespaceAPIResource *metav1.APIResource) error {
+// StartKubeFedSyncController starts a new sync controller for a type
+// config. The controller stops when either the stop channel is closed
+// or the context is cancelled.
+func StartKubeFedSyncController(ctx context.Context, immediate bool, controllerConfig *utils.ControllerConfig, stopChan <-chan struct{}, typeConfig typeconfig.Interface, fedNamespaceAPIResource *metav1.APIResource) (*KubeFedSyncController, error) {
+	ctx, cancel := context.WithCancel(ctx)
 	controller, err := newKubeFedSyncController(ctx, immediate, controllerConfig, typeConfig, fedNamespaceAPIResource)
 	if err != nil {
-		return err
+		cancel()
+		return nil, err
 	}
+	go func() {
+		select {
+		case <-stopChan:
+		case <-ctx.Done():
+		}
+		cancel()
+	}()
 	if controllerConfig.MinimizeLatency {
 		controller.minimizeLatency()
 	}
 	klog.Infof("Starting sync controller for %q", typeConfig.GetFederatedType().Kind)
-	controller.Run(stopChan)
-	return nil
+	controller.Run(ctx.Done())
+	return controller, nil
 }
 
 // newKubeFedSyncController returns a new sync controller for the configuration
@@ -139,20 +303,56 @@ func newKubeFedSyncController(ctx context.Context, immediate bool, controllerCon
 		clusterAvailableDelay:       controllerConfig.ClusterAvailableDelay,
 		clusterUnavailableDelay:     controllerConfig.ClusterUnavailableDelay,
 		smallDelay:                  time.Second * 3,
+		applyOrderGate:              controllerConfig.ApplyOrderGate,
 		cacheSyncTimeout:            controllerConfig.CacheSyncTimeout,
 		eventRecorder:               recorder,
+		eventBroadcaster:            broadcaster,
+		ctx:                         ctx,
 		typeConfig:                  typeConfig,
 		hostClusterClient:           client,
+		kubeFedNamespace:            controllerConfig.KubeFedNamespace,
+		clusterClientRateLimits:     controllerConfig.ClusterClientRateLimits,
+		unreachableClusters:         utils.NewSafeMap(),
 		skipAdoptingResources:       controllerConfig.SkipAdoptingResources,
+		adoptionPolicy:              controllerConfig.AdoptionPolicy,
+		deleteEmptyNamespaces:       controllerConfig.DeleteEmptyNamespaces,
+		removedClusters:             utils.NewSafeMap(),
+		reportedRemovedClusters:     utils.NewSafeMap(),
+		pruneRemovedClusters:        controllerConfig.PruneRemovedClusters,
+		clusterFailureTracker:       controllerConfig.ClusterFailureTracker,
 		limitedScope:                controllerConfig.LimitedScope(),
 		rawResourceStatusCollection: controllerConfig.RawResourceStatusCollection,
+		namespaceOptInLabel:         controllerConfig.NamespaceOptInLabel,
+		propagationPause:            controllerConfig.PropagationPause,
+		driftDetectionInterval:      controllerConfig.DriftDetectionInterval,
+		mergeMetadata:               controllerConfig.MetadataMerge,
+		createdNamespaceLabels:      controllerConfig.CreatedNamespaceLabels,
+		createdNamespaceAnnotations: controllerConfig.CreatedNamespaceAnnotations,
+		tracer:                      controllerConfig.Tracer(tracerName),
+	}
+
+	s.namespacedResourceTypes = s.discoverNamespacedResourceTypes
+	s.preflight = s.preflightClusterConfig
+
+	if window := typeConfig.GetPropagationWindow(); window != nil {
+		var err error
+		s.propagationWindow, err = utils.ParsePropagationWindow(window)
+		if err != nil {
+			broadcaster.Shutdown()
+			return nil, errors.Wrapf(err, "Invalid propagation window for %q", federatedTypeAPIResource.Kind)
+		}
 	}
 
 	s.worker = utils.NewReconcileWorker(strings.ToLower(federatedTypeAPIResource.Kind), s.reconcile, utils.WorkerOptions{
 		WorkerTiming: utils.WorkerTiming{
 			ClusterSyncDelay: s.clusterAvailableDelay,
+			DebounceWindow:   controllerConfig.SyncDebounceWindow,
 		},
 		MaxConcurrentReconciles: int(controllerConfig.MaxConcurrentSyncReconciles),
+		Limiter:                 controllerConfig.SyncReconcileLimiter,
+		Priority: func(qualifiedName utils.QualifiedName) int {
+			return s.fedAccessor.ReconcilePriority(qualifiedName)
+		},
 	})
 
 	// Build deliverer for triggering cluster reconciliations.
@@ -167,29 +367,72 @@ func newKubeFedSyncController(ctx context.Context, immediate bool, controllerCon
 		client,
 		&targetAPIResource,
 		func(obj runtimeclient.Object) {
-			qualifiedName := utils.NewQualifiedName(obj)
+			qualifiedName := utils.FederatedNameForEvent(obj)
 			s.worker.EnqueueForRetry(qualifiedName)
 		},
 		&utils.ClusterLifecycleHandlerFuncs{
 			ClusterAvailable: func(cluster *fedv1b1.KubeFedCluster) {
+				// A cluster joined again under the name of a removed
+				// cluster may be listed in placement.
+				s.removedClusters.Delete(cluster.Name)
+				s.startPreflight(cluster.Name)
 				// When new cluster becomes available process all the target resources again.
 				s.clusterDeliverer.DeliverAt(allClustersKey, nil, time.Now().Add(s.clusterAvailableDelay))
 			},
 			// When a cluster becomes unavailable process all the target resources again.
 			ClusterUnavailable: func(cluster *fedv1b1.KubeFedCluster, _ []interface{}) {
+				s.stopPreflight(cluster.Name)
+				s.unreachableClusters.Delete(cluster.Name)
 				s.clusterDeliverer.DeliverAt(allClustersKey, nil, time.Now().Add(s.clusterUnavailableDelay))
 			},
+			// The reconcile of all the target resources triggered once
+			// a removed cluster becomes unavailable cleans up their
+			// references to the cluster.
+			ClusterRemoved: func(cluster *fedv1b1.KubeFedCluster) {
+				s.removedClusters.Store(cluster.Name, cluster.Name)
+			},
 		},
 	)
 	if err != nil {
+		broadcaster.Shutdown()
 		return nil, err
 	}
 
+	if targetAPIResource.Kind == utils.ServiceKind && typeConfig.GetStatusEnabled() && s.rawResourceStatusCollection {
+		s.endpointSliceInformer, err = utils.NewEndpointSliceFederatedInformer(
+			controllerConfig,
+			client,
+			s.enqueueForEndpointSlice,
+			&utils.ClusterLifecycleHandlerFuncs{},
+		)
+		if err != nil {
+			broadcaster.Shutdown()
+			return nil, err
+		}
+	}
+
 	s.fedAccessor, err = NewFederatedResourceAccessor(ctx, immediate, controllerConfig, typeConfig, fedNamespaceAPIResource, client, s.worker.EnqueueObject, recorder)
 	if err != nil {
+		broadcaster.Shutdown()
 		return nil, err
 	}
 
+	if typeQuota := typeConfig.GetQuota(); typeQuota != nil && !typeConfig.GetObserveOnly() {
+		s.quota = quota.NewController(typeQuota, s.quotaPlacements, s.worker.Enqueue)
+	}
+
+	// Resources of an observe-only type are never propagated.
+	if !typeConfig.GetObserveOnly() {
+		// An invalid webhook only disables notification so that
+		// propagation of the type is not affected.
+		notifier, err := notify.NewWebhookNotifier(typeConfig.GetPropagationWebhook(), client, controllerConfig.KubeFedNamespace)
+		if err != nil {
+			klog.Errorf("Propagation of %q will not be notified due to an invalid propagation webhook: %v", federatedTypeAPIResource.Kind, err)
+		} else if notifier != nil {
+			s.notifications = notify.NewQueue(notifier, notify.DefaultQueueSize)
+		}
+	}
+
 	return s, nil
 }
 
@@ -203,24 +446,348 @@ func (s *KubeFedSyncController) minimizeLatency() {
 
 func (s *KubeFedSyncController) Run(stopChan <-chan struct{}) {
 	s.fedAccessor.Run(stopChan)
+	// Resources of an observe-only type are never created.
+	unregisterApplyOrder := func() {}
+	if !s.typeConfig.GetObserveOnly() {
+		unregisterApplyOrder = s.applyOrderGate.Register(s.typeConfig.GetObjectMeta().Name, s.typeConfig.GetTargetType().Kind, s.pendingCreations)
+	}
 	s.informer.Start()
+	if s.endpointSliceInformer != nil {
+		s.endpointSliceInformer.Start()
+	}
 	s.clusterDeliverer.StartWithHandler(func(_ *utils.DelayingDelivererItem) {
 		s.reconcileOnClusterChange()
 	})
 
 	s.worker.Run(stopChan)
 
+	if s.quota != nil {
+		s.quota.Run(stopChan)
+	}
+
+	if s.notifications != nil {
+		go s.notifications.Run(s.ctx)
+	}
+
+	go wait.Until(s.updateFederatedObjectMetrics, federatedObjectMetricsPeriod, stopChan)
+
+	// Resources of an observe-only type are not expected to have any
+	// particular content.
+	if s.driftDetectionInterval > 0 && !s.typeConfig.GetObserveOnly() {
+		go wait.Until(s.detectDrift, s.driftDetectionInterval, stopChan)
+	}
+
 	// Ensure all goroutines are cleaned up when the stop channel closes
 	go func() {
 		<-stopChan
+		unregisterApplyOrder()
 		s.informer.Stop()
+		if s.endpointSliceInformer != nil {
+			s.endpointSliceInformer.Stop()
+		}
 		s.clusterDeliverer.Stop()
+		s.eventBroadcaster.Shutdown()
+		metrics.DeleteFederatedObjects(s.typeConfig.GetFederatedType().Kind)
 	}()
 }
 
+// updateFederatedObjectMetrics recomputes the number of federated
+// objects in each phase from the full list of cached federated
+// objects so that deleted objects are not reported.
+func (s *KubeFedSyncController) updateFederatedObjectMetrics() {
+	if !s.fedAccessor.HasSynced() {
+		return
+	}
+	var objs []*unstructured.Unstructured
+	s.fedAccessor.VisitFederatedResources(func(obj interface{}) {
+		if fedObject, ok := obj.(*unstructured.Unstructured); ok {
+			objs = append(objs, fedObject)
+		}
+	})
+	metrics.SetFederatedObjects(s.typeConfig.GetFederatedType().Kind, countFederatedObjectPhases(objs))
+}
+
+// quotaPlacements returns the placements of the federated resources of
+// the type that are not being deleted, for the evaluation of the quota
+// of the type.
+func (s *KubeFedSyncController) quotaPlacements() ([]quota.Placement, error) {
+	clusters, err := s.informer.GetClusters()
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to retrieve list of clusters")
+	}
+	var names []utils.QualifiedName
+	s.fedAccessor.VisitFederatedResources(func(obj interface{}) {
+		if fedObject, ok := obj.(*unstructured.Unstructured); ok && fedObject.GetDeletionTimestamp() == nil {
+			names = append(names, utils.NewQualifiedName(fedObject))
+		}
+	})
+
+	placements := make([]quota.Placement, 0, len(names))
+	for _, qualifiedName := range names {
+		fedResource, _, err := s.fedAccessor.FederatedResource(qualifiedName)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed to retrieve federated resource %q", qualifiedName)
+		}
+		if fedResource == nil {
+			continue
+		}
+		selectedClusterNames, err := fedResource.ComputePlacement(clusters)
+		if err != nil {
+			// The resource will fail to be placed.
+			continue
+		}
+		placementOnlyClusterNames, err := fedResource.PlacementOnlyClusters()
+		if err != nil {
+			continue
+		}
+		placement := quota.Placement{
+			Name:              fedResource.FederatedName(),
+			CreationTimestamp: fedResource.Object().GetCreationTimestamp(),
+			Clusters:          selectedClusterNames.Difference(placementOnlyClusterNames),
+			Propagated:        sets.New[string](),
+		}
+		key := fedResource.TargetName().String()
+		for clusterName := range placement.Clusters {
+			clusterObj, _, err := s.informer.GetTargetStore().GetByKey(clusterName, key)
+			if err != nil {
+				return nil, errors.Wrapf(err, "Failed to retrieve cached cluster object %q", key)
+			}
+			if clusterObj != nil {
+				placement.Propagated.Insert(clusterName)
+			}
+		}
+		placements = append(placements, placement)
+	}
+	return placements, nil
+}
+
+// startPreflight starts the connectivity preflight of the named
+// cluster that became available, superseding any earlier preflight of
+// the cluster.
+func (s *KubeFedSyncController) startPreflight(clusterName string) {
+	s.preflightLock.Lock()
+	defer s.preflightLock.Unlock()
+	if s.preflightGenerations == nil {
+		s.preflightGenerations = make(map[string]uint64)
+	}
+	s.preflightGenerations[clusterName]++
+	go s.preflightCluster(clusterName, s.preflightGenerations[clusterName])
+}
+
+// stopPreflight stops retrying the connectivity preflight of the named
+// cluster that became unavailable.
+func (s *KubeFedSyncController) stopPreflight(clusterName string) {
+	s.preflightLock.Lock()
+	defer s.preflightLock.Unlock()
+	if _, ok := s.preflightGenerations[clusterName]; ok {
+		// The generation is kept so that a later preflight does not
+		// reuse the generation of a superseded one.
+		s.preflightGenerations[clusterName]++
+	}
+}
+
+// isCurrentPreflight indicates whether the preflight of the given
+// generation is the current preflight of the named cluster.
+func (s *KubeFedSyncController) isCurrentPreflight(clusterName string, generation uint64) bool {
+	s.preflightLock.Lock()
+	defer s.preflightLock.Unlock()
+	return s.preflightGenerations[clusterName] == generation
+}
+
+// preflightCluster verifies that a newly available cluster can be
+// reached with its credentials so that propagation failures caused by
+// connectivity are reported distinctly from failures to apply
+// resources. The preflight of an unreachable cluster is retried with
+// the current KubeFedCluster until it succeeds, the cluster becomes
+// unavailable or another preflight of the cluster is started.
+func (s *KubeFedSyncController) preflightCluster(clusterName string, generation uint64) {
+	if s.ctx.Err() != nil {
+		// The controller has stopped.
+		return
+	}
+	if !s.isCurrentPreflight(clusterName, generation) {
+		return
+	}
+	cluster, ok, err := s.informer.GetReadyCluster(clusterName)
+	if err == nil && !ok {
+		// The cluster is no longer ready, and its preflight is stopped
+		// once it is reported unavailable.
+		return
+	}
+	if err == nil {
+		err = s.preflight(cluster)
+	}
+	if !s.isCurrentPreflight(clusterName, generation) {
+		// The outcome of a superseded preflight is not recorded.
+		return
+	}
+	if err != nil {
+		runtime.HandleError(errors.Wrapf(err, "Connectivity preflight failed for cluster %q", clusterName))
+		_, wasUnreachable := s.unreachableClusters.Get(clusterName)
+		s.unreachableClusters.Store(clusterName, err)
+		if !wasUnreachable {
+			// Report the cluster as unreachable in resource status.
+			s.clusterDeliverer.DeliverAt(allClustersKey, nil, time.Now())
+		}
+		time.AfterFunc(s.clusterAvailableDelay, func() {
+			s.preflightCluster(clusterName, generation)
+		})
+		return
+	}
+	if _, ok := s.unreachableClusters.Get(clusterName); ok {
+		s.unreachableClusters.Delete(clusterName)
+		s.clusterDeliverer.DeliverAt(allClustersKey, nil, time.Now())
+	}
+}
+
+// preflightClusterConfig verifies that the given cluster can be
+// reached with the configuration built from its KubeFedCluster.
+func (s *KubeFedSyncController) preflightClusterConfig(cluster *fedv1b1.KubeFedCluster) error {
+	config, err := utils.BuildRateLimitedClusterConfig(cluster, s.hostClusterClient, s.kubeFedNamespace, s.clusterClientRateLimits)
+	if err != nil {
+		return err
+	}
+	return utils.PreflightCluster(config, utils.DefaultPreflightTimeout)
+}
+
+// clusterPreflightError returns the error of the connectivity
+// preflight of the named cluster if it could not be reached.
+func (s *KubeFedSyncController) clusterPreflightError(clusterName string) error {
+	value, ok := s.unreachableClusters.Get(clusterName)
+	if !ok {
+		return nil
+	}
+	return value.(error)
+}
+
+// targetCustomResourceDefinition returns the name of the
+// CustomResourceDefinition of the target type in the host cluster, or
+// an empty string if the target type is not a custom resource. The
+// result is retained since the target type of a controller does not
+// change.
+func (s *KubeFedSyncController) targetCustomResourceDefinition() (string, error) {
+	s.targetCRDLock.Lock()
+	defer s.targetCRDLock.Unlock()
+	if s.targetCRD != nil {
+		return *s.targetCRD, nil
+	}
+	crdName := utils.CustomResourceDefinitionName(s.typeConfig.GetTargetType())
+	if len(crdName) > 0 {
+		found, _, err := utils.GetCustomResourceDefinitionEstablished(s.ctx, s.hostClusterClient, crdName)
+		if err != nil {
+			return "", errors.Wrap(err, "Failed to determine whether the target type is a custom resource")
+		}
+		if !found {
+			crdName = ""
+		}
+	}
+	s.targetCRD = &crdName
+	return crdName, nil
+}
+
+// checkCustomResourceDefinitionEstablished returns an error if the
+// named CustomResourceDefinition has not been established in the
+// named cluster.
+func (s *KubeFedSyncController) checkCustomResourceDefinitionEstablished(clusterName, crdName string) error {
+	client, err := s.informer.GetClientForCluster(clusterName)
+	if err != nil {
+		return errors.Wrapf(err, "Failed to get client for cluster %q", clusterName)
+	}
+	found, established, err := utils.GetCustomResourceDefinitionEstablished(s.ctx, client, crdName)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return errors.Errorf("CustomResourceDefinition %q has not been created in cluster %q", crdName, clusterName)
+	}
+	if !established {
+		return errors.Errorf("CustomResourceDefinition %q has not been established in cluster %q", crdName, clusterName)
+	}
+	return nil
+}
+
+// checkNamespaceOptedIn returns an error if the named namespace of
+// the named cluster does not exist or does not have the namespace
+// opt-in label with the value "true", along with the status to record
+// for the cluster. The status is NamespaceNotOptedIn only if the
+// namespace could be retrieved or was not found.
+func (s *KubeFedSyncController) checkNamespaceOptedIn(clusterName, namespace string) (status.PropagationStatus, error) {
+	client, err := s.informer.GetClientForCluster(clusterName)
+	if err != nil {
+		return status.ClientRetrievalFailed, errors.Wrapf(err, "Failed to get client for cluster %q", clusterName)
+	}
+	namespaceObj := &unstructured.Unstructured{}
+	namespaceObj.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind(utils.NamespaceKind))
+	err = client.Get(s.ctx, namespaceObj, "", namespace)
+	if apierrors.IsNotFound(err) {
+		return status.NamespaceNotOptedIn, errors.Errorf("Namespace %q does not exist in cluster %q", namespace, clusterName)
+	}
+	if err != nil {
+		return status.RetrievalFailed, errors.Wrapf(err, "Failed to retrieve namespace %q in cluster %q", namespace, clusterName)
+	}
+	if namespaceObj.GetLabels()[s.namespaceOptInLabel] != "true" {
+		return status.NamespaceNotOptedIn, errors.Errorf("Namespace %q in cluster %q does not have label %s=true", namespace, clusterName, s.namespaceOptInLabel)
+	}
+	return status.ClusterPropagationOK, nil
+}
+
+// pendingCreations returns the names of the federated resources in
+// the given namespace whose resources are placed to the named cluster
+// but have yet to be created there.
+func (s *KubeFedSyncController) pendingCreations(clusterName, namespace string) ([]string, error) {
+	if !s.isSynced() {
+		return nil, errors.Errorf("The caches of the %s controller are not synced", s.typeConfig.GetFederatedType().Kind)
+	}
+	cluster, ok, err := s.informer.GetReadyCluster(clusterName)
+	if err != nil {
+		return nil, err
+	}
+	if !ok || utils.IsClusterInMaintenance(cluster) {
+		// Nothing is created in the cluster.
+		return nil, nil
+	}
+
+	var qualifiedNames []utils.QualifiedName
+	s.fedAccessor.VisitFederatedResources(func(obj interface{}) {
+		if fedObject, ok := obj.(*unstructured.Unstructured); ok && fedObject.GetNamespace() == namespace && fedObject.GetDeletionTimestamp() == nil {
+			qualifiedNames = append(qualifiedNames, utils.NewQualifiedName(fedObject))
+		}
+	})
+
+	var pending []string
+	for _, qualifiedName := range qualifiedNames {
+		fedResource, _, err := s.fedAccessor.FederatedResource(qualifiedName)
+		if err != nil {
+			return nil, err
+		}
+		if fedResource == nil || fedResource.NamespaceNotFederated() {
+			continue
+		}
+		selectedClusterNames, err := fedResource.SelectClusters([]*fedv1b1.KubeFedCluster{cluster})
+		if err != nil {
+			return nil, err
+		}
+		placementOnlyClusterNames, err := fedResource.PlacementOnlyClusters()
+		if err != nil {
+			return nil, err
+		}
+		if !selectedClusterNames.Has(clusterName) || placementOnlyClusterNames.Has(clusterName) {
+			continue
+		}
+		clusterObj, _, err := s.informer.GetTargetStore().GetByKey(clusterName, fedResource.TargetName().String())
+		if err != nil {
+			return nil, err
+		}
+		if clusterObj == nil {
+			pending = append(pending, fedResource.TargetName().String())
+		}
+	}
+	return pending, nil
+}
+
 // Wait until all data stores are in sync for a definitive timeout, and returns if there is an error or a timeout.
-func (s *KubeFedSyncController) waitForSync() error {
-	return wait.PollUntilContextTimeout(context.Background(), utils.SyncedPollPeriod, s.cacheSyncTimeout, true, func(ctx context.Context) (done bool, err error) {
+func (s *KubeFedSyncController) waitForSync(ctx context.Context) error {
+	return wait.PollUntilContextTimeout(ctx, utils.SyncedPollPeriod, s.cacheSyncTimeout, true, func(ctx context.Context) (done bool, err error) {
 		return s.isSynced(), nil
 	})
 }
@@ -262,45 +829,144 @@ func (s *KubeFedSyncController) reconcileOnClusterChange() {
 	})
 }
 
+// ReconcileAll enqueues all federated resources of the type for
+// reconciliation and returns how many were enqueued. Successive
+// resources are enqueued reconcileAllInterval apart to avoid
+// overloading member clusters.
+func (s *KubeFedSyncController) ReconcileAll() int {
+	count := 0
+	s.fedAccessor.VisitFederatedResources(func(obj interface{}) {
+		qualifiedName := utils.NewQualifiedName(obj.(runtimeclient.Object))
+		s.worker.EnqueueWithDelay(qualifiedName, time.Duration(count)*reconcileAllInterval)
+		count++
+	})
+	return count
+}
+
+// VisitFederatedResources calls the given function for each federated
+// resource of the type in the controller's cache.
+func (s *KubeFedSyncController) VisitFederatedResources(visitFunc func(obj interface{})) {
+	s.fedAccessor.VisitFederatedResources(visitFunc)
+}
+
+// ReconcileResult is the outcome of a single reconciliation of a
+// federated resource.
+type ReconcileResult struct {
+	// Status indicates whether reconciliation succeeded or should be
+	// retried.
+	Status utils.ReconciliationStatus
+
+	// PropagationStatus is the propagation status collected from
+	// member clusters, including the per-cluster apply results. It is
+	// nil if the federated resource was not propagated because it was
+	// not found, is being deleted or could not be placed.
+	PropagationStatus *status.CollectedPropagationStatus
+}
+
+// ReconcileOnce synchronously performs a single reconciliation of the
+// given federated resource, as last observed by the controller's
+// cache, and returns its result. It follows the same code path as the
+// reconciliation performed by the controller's workers, and is
+// intended for tests and tooling that need to know the outcome of
+// propagation. A reconciliation of the same resource by a worker
+// completes before it starts, and vice versa. An error is returned if the controller's caches do not
+// sync before the context is done or the cache sync timeout elapses.
+func (s *KubeFedSyncController) ReconcileOnce(ctx context.Context, fedObject runtimeclient.Object) (*ReconcileResult, error) {
+	if err := s.waitForSync(ctx); err != nil {
+		return nil, errors.Wrap(err, "failed to wait for all data stores to sync")
+	}
+	return s.reconcileResource(ctx, utils.NewQualifiedName(fedObject)), nil
+}
+
 func (s *KubeFedSyncController) reconcile(qualifiedName utils.QualifiedName) utils.ReconciliationStatus {
-	if err := s.waitForSync(); err != nil {
+	if err := s.waitForSync(s.ctx); err != nil {
+		if s.ctx.Err() != nil {
+			// The controller is stopping.
+			return utils.StatusNotSynced
+		}
 		klog.Fatalf("failed to wait for all data stores to sync: %v", err)
 	}
+	return s.reconcileResource(s.ctx, qualifiedName).Status
+}
+
+// spanTracer returns the tracer of the controller, or a no-op tracer
+// if the controller was not configured with one.
+func (s *KubeFedSyncController) spanTracer() trace.Tracer {
+	if s.tracer == nil {
+		return noop.NewTracerProvider().Tracer(tracerName)
+	}
+	return s.tracer
+}
 
+// reconcileResource reconciles the named federated resource within a
+// span that is a child of the span in ctx, if any.
+func (s *KubeFedSyncController) reconcileResource(ctx context.Context, qualifiedName utils.QualifiedName) *ReconcileResult {
+	key := qualifiedName.String()
+	s.reconcileLocks.Lock(key)
+	defer s.reconcileLocks.Unlock(key)
+
+	ctx, span := s.spanTracer().Start(ctx, "reconcile", trace.WithAttributes(
+		attribute.String("kubefed.kind", s.typeConfig.GetFederatedType().Kind),
+		attribute.String("kubefed.name", qualifiedName.String()),
+	))
+	defer span.End()
+
+	result := s.reconcileFederatedResource(ctx, qualifiedName)
+	if result.Status == utils.StatusError {
+		span.SetStatus(codes.Error, "reconciliation failed")
+	}
+	return result
+}
+
+func (s *KubeFedSyncController) reconcileFederatedResource(ctx context.Context, qualifiedName utils.QualifiedName) *ReconcileResult {
 	kind := s.typeConfig.GetFederatedType().Kind
 
 	fedResource, possibleOrphan, err := s.fedAccessor.FederatedResource(qualifiedName)
 	if err != nil {
 		runtime.HandleError(errors.Wrapf(err, "Error creating FederatedResource helper for %s %q", kind, qualifiedName))
-		return utils.StatusError
+		return &ReconcileResult{Status: utils.StatusError}
+	}
+	if possibleOrphan && s.typeConfig.GetObserveOnly() {
+		// Resources in member clusters are never modified for an
+		// observe-only type.
+		return &ReconcileResult{Status: utils.StatusAllOK}
+	}
+	if possibleOrphan && s.propagationPause.Paused() {
+		// The managed label cannot be removed while propagation is
+		// paused, and orphans are not reconciled on resumption.
+		return &ReconcileResult{Status: utils.StatusNeedsRecheck}
 	}
 	if possibleOrphan {
 		apiResource := s.typeConfig.GetTargetType()
 		gvk := apiResourceToGVK(&apiResource)
-		klog.V(2).Infof("Ensuring the removal of the label %q from %s %q in member clusters.", utils.ManagedByKubeFedLabelKey, gvk.Kind, qualifiedName)
+		klog.V(2).Infof("Ensuring the removal of the label %q from %s %q in member clusters, or its deletion if pending.", utils.ManagedByKubeFedLabelKey, gvk.Kind, qualifiedName)
 		// We can't compute resource placement, therefore we try to
 		// remove it from all member clusters.
 		clusters, err := s.informer.GetClusters()
 		if err != nil {
 			wrappedErr := errors.Wrap(err, "failed to get member clusters")
 			runtime.HandleError(wrappedErr)
-			return utils.StatusError
+			return &ReconcileResult{Status: utils.StatusError}
 		}
 		clusterNames := sets.Set[string]{}
 		for _, cluster := range clusters {
 			clusterNames = clusterNames.Insert(cluster.Name)
 		}
-		err = s.removeManagedLabel(gvk, qualifiedName, clusterNames)
+		err = s.removeOrphanedResources(ctx, gvk, qualifiedName, clusterNames)
 		if err != nil {
 			wrappedErr := errors.Wrapf(err, "failed to remove the label %q from %s %q in member clusters", utils.ManagedByKubeFedLabelKey, gvk.Kind, qualifiedName)
 			runtime.HandleError(wrappedErr)
-			return utils.StatusError
+			return &ReconcileResult{Status: utils.StatusError}
 		}
 
-		return utils.StatusAllOK
+		return &ReconcileResult{Status: utils.StatusAllOK}
 	}
 	if fedResource == nil {
-		return utils.StatusAllOK
+		if s.quota != nil {
+			// Removal of the federated resource may have freed quota.
+			s.quota.Trigger()
+		}
+		return &ReconcileResult{Status: utils.StatusAllOK}
 	}
 
 	key := fedResource.FederatedName().String()
@@ -313,21 +979,161 @@ func (s *KubeFedSyncController) reconcile(qualifiedName utils.QualifiedName) uti
 	}()
 
 	if fedResource.Object().GetDeletionTimestamp() != nil {
-		return s.ensureDeletion(fedResource)
+		if s.propagationPause.Paused() {
+			// Deletion will be ensured when all resources are
+			// reconciled on resumption of propagation.
+			klog.V(2).Infof("Propagation is paused, deferring deletion of %s %q", kind, key)
+			return &ReconcileResult{Status: utils.StatusAllOK}
+		}
+		return &ReconcileResult{Status: s.ensureDeletion(ctx, fedResource)}
 	}
-	err = s.ensureFinalizer(fedResource)
+	if utils.IsReleaseRequested(fedResource.Object()) {
+		if s.propagationPause.Paused() {
+			// Managed resources cannot be modified while propagation
+			// is paused.
+			klog.V(2).Infof("Propagation is paused, deferring release of %s %q", kind, key)
+			return &ReconcileResult{Status: utils.StatusAllOK}
+		}
+		return &ReconcileResult{Status: s.ensureReleased(ctx, fedResource)}
+	}
+	expired, err := s.deleteIfExpired(fedResource)
 	if err != nil {
-		fedResource.RecordError("EnsureFinalizerError", errors.Wrap(err, "Failed to ensure finalizer"))
-		runtime.HandleError(errors.Wrapf(err, "failed to ensure finalizer"))
-		return utils.StatusError
+		fedResource.RecordError("DeleteExpiredError", errors.Wrap(err, "Failed to delete expired resource"))
+		runtime.HandleError(errors.Wrapf(err, "failed to delete expired %s %q", kind, key))
+		return &ReconcileResult{Status: utils.StatusError}
+	}
+	if expired {
+		// The deletion of the resource will trigger its removal from
+		// member clusters.
+		return &ReconcileResult{Status: utils.StatusAllOK}
+	}
+	// Deletion of an observe-only resource does not require any
+	// operation in member clusters.
+	if !s.typeConfig.GetObserveOnly() {
+		err = s.ensureFinalizer(fedResource)
+		if err != nil {
+			fedResource.RecordError("EnsureFinalizerError", errors.Wrap(err, "Failed to ensure finalizer"))
+			runtime.HandleError(errors.Wrapf(err, "failed to ensure finalizer"))
+			return &ReconcileResult{Status: utils.StatusError}
+		}
+	}
+	err = s.handleRemovedClusters(fedResource)
+	if err != nil {
+		fedResource.RecordError("PruneRemovedClustersError", errors.Wrap(err, "Failed to prune removed clusters from placement"))
+		runtime.HandleError(errors.Wrapf(err, "failed to prune removed clusters from placement of %s %q", kind, key))
+		return &ReconcileResult{Status: utils.StatusError}
+	}
+
+	reconcileStatus, collectedStatus := s.syncToClusters(ctx, fedResource)
+	return &ReconcileResult{Status: reconcileStatus, PropagationStatus: collectedStatus}
+}
+
+// deleteIfExpired deletes the given federated resource if its TTL has
+// elapsed and returns whether it did. Otherwise the resource is
+// enqueued for reconciliation when its TTL elapses.
+func (s *KubeFedSyncController) deleteIfExpired(fedResource FederatedResource) (bool, error) {
+	obj := fedResource.Object()
+	expiration, err := utils.GetExpirationTime(obj)
+	if err != nil {
+		// An invalid TTL is reported but does not prevent propagation.
+		fedResource.RecordError("InvalidTTL", err)
+		return false, nil
+	}
+	if expiration == nil {
+		return false, nil
+	}
+	if remaining := time.Until(*expiration); remaining > 0 {
+		s.worker.EnqueueWithDelay(fedResource.FederatedName(), remaining)
+		return false, nil
+	}
+
+	klog.V(2).Infof("TTL of %s %q elapsed at %v. Deleting it.", fedResource.FederatedKind(), fedResource.FederatedName(), expiration.UTC().Format(time.RFC3339))
+	uid := obj.GetUID()
+	err = s.hostClusterClient.Delete(s.ctx, obj, obj.GetNamespace(), obj.GetName(), runtimeclient.Preconditions{UID: &uid})
+	if apierrors.IsNotFound(err) || apierrors.IsConflict(err) {
+		// The resource was already deleted or replaced.
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	fedResource.RecordEvent("DeleteExpired", "Deleted after the TTL elapsed at %v", expiration.UTC().Format(time.RFC3339))
+	return true, nil
+}
+
+// handleRemovedClusters prunes the clusters whose KubeFedCluster was
+// deleted from the clusters listed in the placement of the given
+// federated resource if pruning is enabled, and otherwise reports
+// that the placement lists removed clusters. Clusters are only pruned
+// once observed to be removed, since placement may list clusters that
+// have yet to join.
+func (s *KubeFedSyncController) handleRemovedClusters(fedResource FederatedResource) error {
+	removedClusterNames := s.removedClusterNames()
+	if removedClusterNames.Len() == 0 {
+		return nil
+	}
+	obj := fedResource.Object()
+	clusterNames, err := utils.GetClusterNames(obj)
+	if err != nil {
+		return err
+	}
+	key := fedResource.FederatedName().String()
+	listedClusterNames := removedClusterNames.Intersection(sets.New[string](clusterNames...))
+	if listedClusterNames.Len() == 0 {
+		s.reportedRemovedClusters.Delete(key)
+		return nil
+	}
+	listed := strings.Join(sets.List(listedClusterNames), ",")
+	if !s.pruneRemovedClusters {
+		if reported, ok := s.reportedRemovedClusters.Get(key); !ok || reported != listed {
+			fedResource.RecordEvent("RemovedClustersInPlacement", "Placement lists clusters that were removed: %s", listed)
+			s.reportedRemovedClusters.Store(key, listed)
+		}
+		return nil
+	}
+
+	// The patch is rejected if the resource changed since it was read,
+	// so that a concurrent change of placement is not overwritten.
+	patch := runtimeclient.MergeFromWithOptions(obj.DeepCopy(), runtimeclient.MergeFromWithOptimisticLock{})
+	if _, err := utils.RemoveClusterNames(obj, listedClusterNames); err != nil {
+		return err
+	}
+	klog.V(2).Infof("Pruning removed clusters %s from the placement of %s %q", listed, fedResource.FederatedKind(), fedResource.FederatedName())
+	if err := s.hostClusterClient.Patch(s.ctx, obj, patch); err != nil {
+		return err
 	}
+	fedResource.RecordEvent("PrunedRemovedClusters", "Pruned clusters that were removed from placement: %s", listed)
+	return nil
+}
 
-	return s.syncToClusters(fedResource)
+// versionsReferenceRemovedClusters returns whether a version is
+// recorded for the given federated resource in a removed cluster.
+func (s *KubeFedSyncController) versionsReferenceRemovedClusters(fedResource FederatedResource) bool {
+	for clusterName := range s.removedClusterNames() {
+		if version, err := fedResource.VersionForCluster(clusterName); err == nil && len(version) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// removedClusterNames returns the names of the clusters whose
+// KubeFedCluster was deleted.
+func (s *KubeFedSyncController) removedClusterNames() sets.Set[string] {
+	names := sets.New[string]()
+	if s.removedClusters == nil {
+		return names
+	}
+	for _, value := range s.removedClusters.GetAll() {
+		names.Insert(value.(string))
+	}
+	return names
 }
 
 // syncToClusters ensures that the state of the given object is
-// synchronized to member clusters.
-func (s *KubeFedSyncController) syncToClusters(fedResource FederatedResource) utils.ReconciliationStatus {
+// synchronized to member clusters and returns the collected
+// propagation status, which is nil if the object could not be placed.
+func (s *KubeFedSyncController) syncToClusters(ctx context.Context, fedResource FederatedResource) (utils.ReconciliationStatus, *status.CollectedPropagationStatus) {
 	// Enable raw resource status collection if the statusCollection is enabled for that type
 	// and the feature is also enabled.
 	enableRawResourceStatusCollection := s.typeConfig.GetStatusEnabled() && s.rawResourceStatusCollection
@@ -336,26 +1142,98 @@ func (s *KubeFedSyncController) syncToClusters(fedResource FederatedResource) ut
 	if err != nil {
 		fedResource.RecordError(string(status.ClusterRetrievalFailed), errors.Wrap(err, "Failed to retrieve list of clusters"))
 		runtime.HandleError(errors.Wrapf(err, "failed to retrieve list of clusters"))
-		return s.setFederatedStatus(fedResource, status.ClusterRetrievalFailed, nil, nil, enableRawResourceStatusCollection)
+		return s.setFederatedStatus(ctx, fedResource, status.ClusterRetrievalFailed, nil, nil, enableRawResourceStatusCollection), nil
 	}
 
-	selectedClusterNames, err := fedResource.ComputePlacement(clusters)
+	selectedClusterNames, placementOnlyClusterNames, quotaBlockedClusterNames, err := s.computePlacement(ctx, fedResource, clusters)
 	if err != nil {
-		fedResource.RecordError(string(status.ComputePlacementFailed), errors.Wrap(err, "Failed to compute placement"))
-		runtime.HandleError(errors.Wrapf(err, "failed to compute placement"))
-		return s.setFederatedStatus(fedResource, status.ComputePlacementFailed, nil, nil, enableRawResourceStatusCollection)
+		fedResource.RecordError(string(status.ComputePlacementFailed), err)
+		runtime.HandleError(err)
+		return s.setFederatedStatus(ctx, fedResource, status.ComputePlacementFailed, nil, nil, enableRawResourceStatusCollection), nil
 	}
 
 	kind := fedResource.TargetKind()
 	key := fedResource.TargetName().String()
-	klog.V(4).Infof("Ensuring %s %q in clusters: %s", kind, key, strings.Join(sets.List[string](selectedClusterNames), ","))
+	klog.V(4).Infof("Ensuring %s %q in clusters: %s", kind, key, strings.Join(sets.List[string](selectedClusterNames.Difference(placementOnlyClusterNames)), ","))
+
+	dispatcher := dispatch.NewManagedDispatcher(ctx, s.informer.GetClientForCluster, fedResource, s.skipAdoptingResources, s.adoptionPolicy, enableRawResourceStatusCollection)
+	if s.mergeMetadata {
+		dispatcher.MergeMetadata()
+	}
+	dispatcher.StampCreatedNamespaces(s.createdNamespaceLabels, s.createdNamespaceAnnotations)
+	dispatcher.Trace(ctx, s.spanTracer())
+	// Clients whose credentials were rotated are rebuilt from the
+	// current secret of the cluster.
+	dispatcher.OnAuthenticationFailure(s.informer.RefreshClientForCluster)
+	observeOnly := s.typeConfig.GetObserveOnly()
+	paused := s.propagationPause.Paused()
+
+	// Clusters from which the resource is deleted, and whose namespace
+	// may therefore no longer contain managed resources.
+	removedClusterNames := sets.New[string]()
+
+	// Updates of existing resources are not urgent and are deferred
+	// while the propagation window of the type is closed.
+	deferredUntil := utils.PropagationDeferredUntil(s.propagationWindow, fedResource.Object(), time.Now())
+	if deferredUntil != nil {
+		dispatcher.DeferUpdates()
+	}
 
-	dispatcher := dispatch.NewManagedDispatcher(s.informer.GetClientForCluster, fedResource, s.skipAdoptingResources, enableRawResourceStatusCollection)
+	// Resources in clusters no longer selected are drained according
+	// to the removal strategy before they are deleted.
+	removalStrategy, removalStrategyErr := fedResource.RemovalStrategy()
+	if removalStrategyErr != nil {
+		fedResource.RecordError(string(status.DrainFailed), removalStrategyErr)
+		runtime.HandleError(removalStrategyErr)
+	}
+	var drainRemaining *time.Duration
+
+	targetCRD, err := s.targetCustomResourceDefinition()
+	if err != nil {
+		// Creation of custom resources is attempted regardless and
+		// will fail if their definition is not established.
+		runtime.HandleError(err)
+	}
+
+	// Resources are only created or updated in the remaining placed
+	// clusters once they are healthy in the canary clusters.
+	canaryClusterNames, err := fedResource.CanaryClusters()
+	if err != nil {
+		fedResource.RecordError(string(status.ComputePlacementFailed), err)
+		runtime.HandleError(err)
+		return s.setFederatedStatus(ctx, fedResource, status.ComputePlacementFailed, nil, nil, enableRawResourceStatusCollection), nil
+	}
+	canaryClusterNames = availableCanaryClusters(clusters, canaryClusterNames.Intersection(selectedClusterNames).Difference(placementOnlyClusterNames))
+	canary, err := s.canaryRollout(fedResource, canaryClusterNames)
+	if err != nil {
+		// The rollout is held back while the state of the canary
+		// clusters cannot be determined.
+		runtime.HandleError(err)
+		canary = &status.CanaryStatus{Reason: status.CanaryPending, Message: err.Error()}
+	}
+	canaryHealthy := canary == nil || canary.Reason == status.AggregateSuccess
 
 	for _, cluster := range clusters {
 		clusterName := cluster.Name
 		selectedCluster := selectedClusterNames.Has(clusterName)
 
+		if placementOnlyClusterNames.Has(clusterName) {
+			// The cluster is reported as placed, but any resource
+			// in the cluster is left untouched.
+			dispatcher.RecordStatus(clusterName, status.PlacementOnly, nil)
+			continue
+		}
+
+		if utils.IsClusterInMaintenance(cluster) {
+			// Neither applies nor deletions are performed for a
+			// cluster in maintenance. Propagation will resume
+			// when the maintenance annotation is removed.
+			if selectedCluster {
+				dispatcher.RecordStatus(clusterName, status.Maintenance, nil)
+			}
+			continue
+		}
+
 		if !utils.IsClusterReady(&cluster.Status) {
 			if selectedCluster {
 				// Cluster state only needs to be reported in resource
@@ -366,6 +1244,24 @@ func (s *KubeFedSyncController) syncToClusters(fedResource FederatedResource) ut
 			continue
 		}
 
+		if err := s.clusterPreflightError(clusterName); err != nil {
+			// Attribute the failure to connectivity rather than
+			// attempting operations that are bound to fail.
+			if selectedCluster {
+				dispatcher.RecordClusterError(status.ClusterNotReachable, clusterName, err)
+			}
+			continue
+		}
+
+		if observeOnly {
+			// Resources are observed in placed clusters and left
+			// untouched in all clusters.
+			if selectedCluster {
+				dispatcher.Observe(clusterName)
+			}
+			continue
+		}
+
 		rawClusterObj, _, err := s.informer.GetTargetStore().GetByKey(clusterName, key)
 		if err != nil {
 			wrappedErr := errors.Wrap(err, "Failed to retrieve cached cluster object")
@@ -378,6 +1274,20 @@ func (s *KubeFedSyncController) syncToClusters(fedResource FederatedResource) ut
 			clusterObj = rawClusterObj.(*unstructured.Unstructured)
 		}
 
+		if paused {
+			// Neither applies nor deletions are performed while
+			// propagation is paused for the control plane, but the
+			// status of existing resources is still collected.
+			if selectedCluster {
+				var resourceStatus interface{}
+				if clusterObj != nil {
+					resourceStatus = clusterObj.Object[utils.StatusField]
+				}
+				dispatcher.RecordStatus(clusterName, status.Paused, resourceStatus)
+			}
+			continue
+		}
+
 		// Resource should not exist in the named cluster
 		if !selectedCluster {
 			if clusterObj == nil {
@@ -393,19 +1303,81 @@ func (s *KubeFedSyncController) syncToClusters(fedResource FederatedResource) ut
 				// Host cluster namespace needs to have the managed
 				// label removed so it won't be cached anymore.
 				dispatcher.RemoveManagedLabel(clusterName, clusterObj)
-			} else {
-				dispatcher.Delete(clusterName)
+				continue
+			}
+			if removalStrategyErr != nil {
+				// The resource is retained rather than deleted
+				// without the drain that may have been intended.
+				dispatcher.RecordStatus(clusterName, status.DrainFailed, clusterObj.Object[utils.StatusField])
+				continue
+			}
+			if removalStrategy != nil {
+				drained, remaining, err := drainForRemoval(dispatcher, removalStrategy, clusterName, clusterObj, time.Now())
+				if err != nil {
+					dispatcher.RecordClusterError(status.DrainFailed, clusterName, err)
+					continue
+				}
+				if !drained {
+					if drainRemaining == nil || remaining < *drainRemaining {
+						drainRemaining = &remaining
+					}
+					continue
+				}
 			}
+			dispatcher.Delete(clusterName)
+			removedClusterNames.Insert(clusterName)
 			continue
 		}
 
 		// Resource should appear in the named cluster
 
+		if !canaryHealthy && !canaryClusterNames.Has(clusterName) {
+			current, err := resourceCurrent(fedResource, clusterName, clusterObj)
+			if err != nil || !current {
+				var resourceStatus interface{}
+				if clusterObj != nil {
+					resourceStatus = clusterObj.Object[utils.StatusField]
+				}
+				dispatcher.RecordStatus(clusterName, status.WaitingForCanary, resourceStatus)
+				continue
+			}
+		}
+
 		// TODO(marun) Consider waiting until the result of resource
 		// creation has reached the target store before attempting
 		// subsequent operations.  Otherwise the object won't be found
 		// but an add operation will fail with AlreadyExists.
 		if clusterObj == nil {
+			if quotaBlockedClusterNames.Has(clusterName) {
+				// Creation is blocked until the quota of the type
+				// allows it, at which point the resource is
+				// reconciled again.
+				err := errors.Errorf("Creation would exceed the quota of %s for the cluster", s.typeConfig.GetObjectMeta().Name)
+				dispatcher.RecordClusterError(status.QuotaExceeded, clusterName, err)
+				continue
+			}
+			if len(s.namespaceOptInLabel) > 0 && len(fedResource.TargetName().Namespace) > 0 {
+				// Resources are only created in or adopted from
+				// namespaces that have opted in to management.
+				if clusterStatus, err := s.checkNamespaceOptedIn(clusterName, fedResource.TargetName().Namespace); err != nil {
+					dispatcher.RecordClusterError(clusterStatus, clusterName, err)
+					continue
+				}
+			}
+			if len(targetCRD) > 0 {
+				// A custom resource cannot be created before its
+				// definition has been established in the cluster.
+				if err := s.checkCustomResourceDefinitionEstablished(clusterName, targetCRD); err != nil {
+					dispatcher.RecordClusterError(status.CustomResourceDefinitionNotEstablished, clusterName, err)
+					continue
+				}
+			}
+			// Resources that the resource may depend on, e.g. its
+			// service account, are created first.
+			if err := s.applyOrderGate.CheckCreation(kind, clusterName, fedResource.TargetName().Namespace); err != nil {
+				dispatcher.RecordClusterError(status.WaitingForApplyOrder, clusterName, err)
+				continue
+			}
 			dispatcher.Create(clusterName)
 		} else {
 			dispatcher.Update(clusterName, clusterObj)
@@ -416,23 +1388,230 @@ func (s *KubeFedSyncController) syncToClusters(fedResource FederatedResource) ut
 		fedResource.RecordError("OperationTimeoutError", timeoutErr)
 		runtime.HandleError(errors.Wrapf(timeoutErr, "operation timeout"))
 	}
-	// Write updated versions to the API.
-	updatedVersionMap := dispatcher.VersionMap()
-	err = fedResource.UpdateVersions(sets.List[string](selectedClusterNames), updatedVersionMap)
+	s.removeEmptyNamespaces(fedResource, removedClusterNames)
+	// Write updated versions to the API. No versions are recorded
+	// for observed resources since they are never updated, nor while
+	// propagation is paused.
+	if !observeOnly && !paused {
+		updatedVersionMap := dispatcher.VersionMap()
+		err = fedResource.UpdateVersions(sets.List[string](selectedClusterNames.Difference(placementOnlyClusterNames)), updatedVersionMap)
+		if err != nil {
+			// Versioning of federated resources is an optimization to
+			// avoid unnecessary updates, and failure to record version
+			// information does not indicate a failure of propagation.
+			runtime.HandleError(err)
+		}
+	} else if !observeOnly && s.versionsReferenceRemovedClusters(fedResource) {
+		// Versions are not recorded while propagation is paused, but
+		// those of removed clusters are pruned. The versions of all
+		// remaining clusters are retained.
+		var clusterNames []string
+		for _, cluster := range clusters {
+			clusterNames = append(clusterNames, cluster.Name)
+		}
+		err = fedResource.UpdateVersions(clusterNames, map[string]string{})
+		if err != nil {
+			runtime.HandleError(err)
+		}
+	}
+
+	collectedStatus, collectedResourceStatus := dispatcher.CollectedStatus()
+	if enableRawResourceStatusCollection {
+		collectedResourceStatus.AggregatedMetrics, collectedResourceStatus.UnreportedMetricsClusters = status.AggregateMetrics(s.typeConfig.GetStatusAggregations(), collectedResourceStatus.StatusMap, selectedClusterNames.Difference(placementOnlyClusterNames))
+		if fedResource.TargetKind() == utils.ServiceKind {
+			var uncollected []string
+			collectedResourceStatus.ReadyEndpoints, uncollected = s.collectReadyEndpoints(fedResource, collectedStatus.StatusMap)
+			if len(uncollected) > 0 {
+				fedResource.RecordEvent("ReadyEndpointsNotCollected", "Ready endpoints are not included for clusters whose EndpointSlices could not be read: %s", strings.Join(uncollected, ", "))
+			}
+		}
+	}
+	for _, applyResult := range collectedStatus.ApplyResults {
+		metrics.RecordApplyResult(s.typeConfig.GetFederatedType().Kind, string(applyResult.Result))
+	}
+	s.recordApplyOutcomes(ctx, key, collectedStatus)
+
+	collectedStatus.MinHealthyClusters, err = fedResource.MinHealthyClusters()
+	if err != nil {
+		// Require all placed clusters to be healthy if the minimum
+		// cannot be determined.
+		runtime.HandleError(errors.Wrap(err, "Failed to determine the minimum number of healthy clusters"))
+	}
+
+	collectedStatus.PropagationDeadline, err = fedResource.PropagationDeadline()
 	if err != nil {
-		// Versioning of federated resources is an optimization to
-		// avoid unnecessary updates, and failure to record version
-		// information does not indicate a failure of propagation.
+		runtime.HandleError(errors.Wrap(err, "Failed to determine the propagation deadline"))
+	}
+
+	collectedStatus.DeferredUntil = deferredUntil
+	collectedStatus.RetainedUntil = fedResource.RetainedClusters()
+	collectedStatus.Canary = canary
+	if failure := canaryApplyFailure(canaryClusterNames, collectedStatus.ApplyResults); failure != nil {
+		collectedStatus.Canary = failure
+	}
+
+	overrideClusterNames, err := fedResource.OverrideClusterNames()
+	if err != nil {
+		// The error will have been reported when overrides were applied.
 		runtime.HandleError(err)
+	} else {
+		// An override for a cluster that is not selected has no
+		// effect and likely indicates a mistake.
+		collectedStatus.UnplacedOverrideClusters = sets.List(overrideClusterNames.Difference(selectedClusterNames))
+	}
+
+	var renameErr error
+	if len(s.typeConfig.GetTargetNameTemplate()) > 0 {
+		collectedStatus.TargetName = fedResource.TargetName().Name
+		if !observeOnly && !paused {
+			renameErr = s.removeRenamedResources(ctx, fedResource)
+		}
+		if renameErr != nil {
+			fedResource.RecordError("RemoveRenamedResourcesError", renameErr)
+			runtime.HandleError(renameErr)
+			// Continue to target the previous name until its
+			// resources have been removed.
+			collectedStatus.TargetName = utils.GetRecordedTargetName(fedResource.Object())
+		}
 	}
 
-	collectedStatus, collectedResourceStatus := dispatcher.CollectedStatus()
 	klog.V(4).Infof("Setting the federated status '%v' for %s %q", collectedResourceStatus, kind, key)
-	return s.setFederatedStatus(fedResource, status.AggregateSuccess, &collectedStatus, &collectedResourceStatus, enableRawResourceStatusCollection)
+	reconcileStatus := s.setFederatedStatus(ctx, fedResource, status.AggregateSuccess, &collectedStatus, &collectedResourceStatus, enableRawResourceStatusCollection)
+	if deadline := collectedStatus.PropagationDeadline; deadline != nil {
+		// Ensure that a deadline is reported as exceeded even if
+		// nothing else triggers reconciliation before it passes.
+		if remaining, ok := status.TimeUntilPropagationDeadline(fedResource.Object(), *deadline); ok {
+			s.worker.EnqueueWithDelay(fedResource.FederatedName(), remaining)
+		}
+	}
+	if deferredUntil != nil {
+		for _, clusterStatus := range collectedStatus.StatusMap {
+			if clusterStatus == status.Deferred {
+				// Ensure that deferred updates are propagated once
+				// the propagation window opens.
+				s.worker.EnqueueWithDelay(fedResource.FederatedName(), time.Until(*deferredUntil))
+				break
+			}
+		}
+	}
+	if retainedUntil := earliestTime(collectedStatus.RetainedUntil); retainedUntil != nil {
+		// Ensure that retained clusters are removed from placement
+		// once their stickiness has expired.
+		s.worker.EnqueueWithDelay(fedResource.FederatedName(), time.Until(*retainedUntil))
+	}
+	if drainRemaining != nil {
+		// Ensure that drained resources are deleted once their drain
+		// duration has elapsed.
+		s.worker.EnqueueWithDelay(fedResource.FederatedName(), *drainRemaining)
+	}
+	if observeOnly {
+		s.worker.EnqueueWithDelay(fedResource.FederatedName(), observeInterval)
+	}
+	if collectedStatus.Canary != nil && collectedStatus.Canary.Reason == status.CanaryPending {
+		s.worker.EnqueueWithDelay(fedResource.FederatedName(), canaryRecheckInterval)
+	}
+	for _, clusterStatus := range collectedStatus.StatusMap {
+		if clusterStatus == status.CreationForbidden || clusterStatus == status.UpdateForbidden {
+			s.worker.EnqueueWithDelay(fedResource.FederatedName(), forbiddenRetryInterval)
+			break
+		}
+	}
+	if renameErr != nil {
+		return utils.StatusError, &collectedStatus
+	}
+	return reconcileStatus, &collectedStatus
 }
 
-func (s *KubeFedSyncController) setFederatedStatus(fedResource FederatedResource,
+// computePlacement returns the names of the clusters selected for the
+// given object, of those selected clusters in which it is only
+// reported as placed, and of the clusters in which its creation is
+// blocked by the quota of the type.
+func (s *KubeFedSyncController) computePlacement(ctx context.Context, fedResource FederatedResource, clusters []*fedv1b1.KubeFedCluster) (selectedClusterNames, placementOnlyClusterNames, quotaBlockedClusterNames sets.Set[string], err error) {
+	_, span := s.spanTracer().Start(ctx, "compute-placement")
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	selectedClusterNames, err = fedResource.ComputePlacement(clusters)
+	if err != nil {
+		return nil, nil, nil, errors.Wrap(err, "Failed to compute placement")
+	}
+
+	placementOnlyClusterNames, err = fedResource.PlacementOnlyClusters()
+	if err != nil {
+		return nil, nil, nil, errors.Wrap(err, "Failed to compute placement-only clusters")
+	}
+	placementOnlyClusterNames = placementOnlyClusterNames.Intersection(selectedClusterNames)
+	fedResource.SetPlacement(selectedClusterNames.Difference(placementOnlyClusterNames))
+
+	if s.quota != nil {
+		quotaBlockedClusterNames, err = s.quota.BlockedClusters(fedResource.FederatedName(), selectedClusterNames.Difference(placementOnlyClusterNames))
+		if err != nil {
+			return nil, nil, nil, errors.Wrap(err, "Failed to evaluate the quota of the type")
+		}
+	}
+	span.SetAttributes(attribute.Int("kubefed.selected_clusters", selectedClusterNames.Len()))
+	return selectedClusterNames, placementOnlyClusterNames, quotaBlockedClusterNames, nil
+}
+
+// removeRenamedResources removes resources propagated under a target
+// name recorded in status that no longer matches the name computed
+// from the labels of the federated resource.
+func (s *KubeFedSyncController) removeRenamedResources(ctx context.Context, fedResource FederatedResource) error {
+	targetName := fedResource.TargetName()
+	recordedName := utils.GetRecordedTargetName(fedResource.Object())
+	if len(recordedName) == 0 || recordedName == targetName.Name {
+		return nil
+	}
+
+	clusters, err := s.informer.GetClusters()
+	if err != nil {
+		return errors.Wrap(err, "failed to get member clusters")
+	}
+	clusterNames := sets.Set[string]{}
+	for _, cluster := range clusters {
+		clusterNames.Insert(cluster.Name)
+	}
+
+	previousName := utils.QualifiedName{Namespace: targetName.Namespace, Name: recordedName}
+	klog.V(2).Infof("Removing %s %q renamed to %q from member clusters", fedResource.TargetKind(), previousName, targetName)
+	ok, err := s.handleDeletionInClusters(ctx, fedResource.TargetGVK(), previousName, clusterNames, func(dispatcher dispatch.UnmanagedDispatcher, clusterName string, clusterObj *unstructured.Unstructured) {
+		if clusterObj.GetDeletionTimestamp() != nil {
+			return
+		}
+		dispatcher.Delete(clusterName)
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to remove renamed %s %q", fedResource.TargetKind(), previousName)
+	}
+	if !ok {
+		return errors.Errorf("failed to remove renamed %s %q from one or more clusters", fedResource.TargetKind(), previousName)
+	}
+	return nil
+}
+
+// earliestTime returns the earliest of the given times, or nil if there
+// are none.
+func earliestTime(times map[string]time.Time) *time.Time {
+	var earliest *time.Time
+	for _, t := range times {
+		if earliest == nil || t.Before(*earliest) {
+			earliest = &t
+		}
+	}
+	return earliest
+}
+
+func (s *KubeFedSyncController) setFederatedStatus(ctx context.Context, fedResource FederatedResource,
 	reason status.AggregateReason, collectedStatus *status.CollectedPropagationStatus, collectedResourceStatus *status.CollectedResourceStatus, resourceStatusCollection bool) utils.ReconciliationStatus {
+	_, span := s.spanTracer().Start(ctx, "status-update", trace.WithAttributes(
+		attribute.String("kubefed.reason", string(reason)),
+	))
+	defer span.End()
+
 	if collectedStatus == nil {
 		collectedStatus = &status.CollectedPropagationStatus{}
 	}
@@ -455,9 +1634,11 @@ func (s *KubeFedSyncController) setFederatedStatus(fedResource FederatedResource
 		}
 	}
 
+	previousGeneration, previouslyCompleted := status.PropagationCompleted(obj)
+
 	// If the underlying resource has changed, attempt to retrieve and
 	// update it repeatedly.
-	err := wait.PollUntilContextTimeout(context.Background(), 1*time.Second, 5*time.Second, true, func(ctx context.Context) (done bool, err error) {
+	err := wait.PollUntilContextTimeout(s.ctx, 1*time.Second, 5*time.Second, true, func(ctx context.Context) (done bool, err error) {
 		if updateRequired, err := status.SetFederatedStatus(obj, reason, *collectedStatus, *collectedResourceStatus, resourceStatusCollection); err != nil {
 			klog.V(4).Infof("Failed to set the status for %s %q", kind, name)
 			return false, errors.Wrapf(err, "failed to set the status")
@@ -466,13 +1647,13 @@ func (s *KubeFedSyncController) setFederatedStatus(fedResource FederatedResource
 			return true, nil
 		}
 		klog.V(4).Infof("Updating status for %s %q", kind, name)
-		err = s.hostClusterClient.UpdateStatus(context.TODO(), obj)
+		err = s.hostClusterClient.UpdateStatus(ctx, obj)
 		if err == nil {
 			return true, nil
 		}
 		if apierrors.IsConflict(err) {
 			klog.V(2).Infof("Failed to set propagation status for %s %q due to conflict (will retry): %v.", kind, name, err)
-			err := s.hostClusterClient.Get(context.TODO(), obj, obj.GetNamespace(), obj.GetName())
+			err := s.hostClusterClient.Get(ctx, obj, obj.GetNamespace(), obj.GetName())
 			if err != nil {
 				return false, errors.Wrapf(err, "failed to retrieve resource")
 			}
@@ -481,10 +1662,18 @@ func (s *KubeFedSyncController) setFederatedStatus(fedResource FederatedResource
 		return false, errors.Wrapf(err, "failed to update resource")
 	})
 	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
 		runtime.HandleError(errors.Wrapf(err, "failed to set propagation status for %s %q", kind, name))
 		return utils.StatusError
 	}
 
+	if s.notifications != nil {
+		// Notify once for each generation whose propagation completes.
+		if generation, completed := status.PropagationCompleted(obj); completed && (!previouslyCompleted || generation != previousGeneration) {
+			s.notifyPropagationCompleted(fedResource, generation, status.PropagatedClusters(collectedStatus.StatusMap))
+		}
+	}
+
 	// return Error to trigger a retry with back off on recoverable propagation failure
 	if reason == status.AggregateSuccess {
 		for _, value := range collectedStatus.StatusMap {
@@ -497,7 +1686,31 @@ func (s *KubeFedSyncController) setFederatedStatus(fedResource FederatedResource
 	return utils.StatusAllOK
 }
 
-func (s *KubeFedSyncController) ensureDeletion(fedResource FederatedResource) utils.ReconciliationStatus {
+// notifyPropagationCompleted queues the notification of the
+// propagation webhook of the type that propagation of the given
+// generation of the federated resource to the given clusters has
+// completed.
+func (s *KubeFedSyncController) notifyPropagationCompleted(fedResource FederatedResource, generation int64, clusterNames []string) {
+	name := fedResource.FederatedName()
+	notification := &notify.Notification{
+		Kind:        fedResource.FederatedKind(),
+		Namespace:   name.Namespace,
+		Name:        name.Name,
+		Generation:  generation,
+		Clusters:    clusterNames,
+		CompletedAt: metav1.Now(),
+	}
+	queued := s.notifications.Enqueue(notification, func(err error) {
+		fedResource.RecordError("PropagationNotificationFailed", err)
+	})
+	if !queued {
+		klog.Errorf("Dead letter: dropped propagation notification for %s %q since %d notifications are waiting to be delivered",
+			notification.Kind, name, notify.DefaultQueueSize)
+		fedResource.RecordError("PropagationNotificationFailed", errors.New("Too many propagation notifications are waiting to be delivered"))
+	}
+}
+
+func (s *KubeFedSyncController) ensureDeletion(ctx context.Context, fedResource FederatedResource) utils.ReconciliationStatus {
 	fedResource.DeleteVersions()
 
 	key := fedResource.FederatedName().String()
@@ -513,6 +1726,20 @@ func (s *KubeFedSyncController) ensureDeletion(fedResource FederatedResource) ut
 		return utils.StatusAllOK
 	}
 
+	if s.typeConfig.GetObserveOnly() {
+		// The finalizer was added before the type became
+		// observe-only, and resources in member clusters are left
+		// untouched.
+		klog.V(2).Infof("%s is observe-only. Removing the finalizer from %s %q.", s.typeConfig.GetObjectMeta().Name, kind, key)
+		err := s.removeFinalizer(fedResource)
+		if err != nil {
+			wrappedErr := errors.Wrapf(err, "failed to remove finalizer %q from %s %q", FinalizerSyncController, kind, key)
+			runtime.HandleError(wrappedErr)
+			return utils.StatusError
+		}
+		return utils.StatusAllOK
+	}
+
 	if utils.IsOrphaningEnabled(obj) {
 		klog.V(2).Infof("Found %q annotation on %s %q. Removing the finalizer.",
 			utils.OrphanManagedResourcesAnnotation, kind, key)
@@ -535,7 +1762,7 @@ func (s *KubeFedSyncController) ensureDeletion(fedResource FederatedResource) ut
 			runtime.HandleError(wrappedErr)
 			return utils.StatusError
 		}
-		err = s.removeManagedLabel(fedResource.TargetGVK(), fedResource.TargetName(), targetClusters)
+		err = s.removeManagedLabel(ctx, fedResource.TargetGVK(), fedResource.TargetName(), targetClusters)
 		if err != nil {
 			wrappedErr := errors.Wrapf(err, "failed to remove the label %q from all resources previously managed by %s %q", utils.ManagedByKubeFedLabelKey, kind, key)
 			runtime.HandleError(wrappedErr)
@@ -544,6 +1771,26 @@ func (s *KubeFedSyncController) ensureDeletion(fedResource FederatedResource) ut
 		return utils.StatusAllOK
 	}
 
+	if utils.IsDeletionBlocked(obj) {
+		klog.V(2).Infof("Found %q annotation on %s %q without a matching %q annotation. Blocking deletion of managed resources.",
+			utils.DeletionConfirmationRequiredAnnotation, kind, key, utils.ConfirmDeletionAnnotation)
+		fedResource.RecordError(string(status.DeletionBlocked), errors.Errorf("Deletion of managed resources requires the %q annotation to be set to %q",
+			utils.ConfirmDeletionAnnotation, obj.GetName()))
+		return s.setFederatedStatus(ctx, fedResource, status.DeletionBlocked, nil, nil, false)
+	}
+
+	gracePeriod, err := fedResource.DeletionGracePeriod()
+	if err != nil {
+		// Deletion is not ensured without the grace period that may
+		// have been intended, and proceeds once it is corrected.
+		fedResource.RecordError("InvalidDeletionGracePeriod", err)
+		runtime.HandleError(errors.Wrapf(err, "failed to determine the deletion grace period of %s %q", kind, key))
+		return utils.StatusError
+	}
+	if gracePeriod > 0 {
+		return s.markDeletionPending(ctx, fedResource, gracePeriod)
+	}
+
 	klog.V(2).Infof("Deserializing delete options of %s %q", kind, key)
 	opts, err := utils.GetDeleteOptions(obj)
 	if err != nil {
@@ -553,7 +1800,7 @@ func (s *KubeFedSyncController) ensureDeletion(fedResource FederatedResource) ut
 	}
 
 	klog.V(2).Infof("Deleting resources managed by %s %q from member clusters.", kind, key)
-	recheckRequired, err := s.deleteFromClusters(fedResource, opts...)
+	recheckRequired, err := s.deleteFromClusters(ctx, fedResource, opts...)
 	if err != nil {
 		wrappedErr := errors.Wrapf(err, "failed to delete %s %q", kind, key)
 		runtime.HandleError(wrappedErr)
@@ -565,10 +1812,115 @@ func (s *KubeFedSyncController) ensureDeletion(fedResource FederatedResource) ut
 	return utils.StatusAllOK
 }
 
+// ensureReleased hands the resources managed for the given federated
+// resource back to the controllers of member clusters by removing the
+// markers of management by KubeFed from them, and then stops managing
+// them by removing the finalizer of the federated resource. The
+// resources are otherwise left untouched. A released resource is not
+// propagated for as long as the release annotation is present.
+func (s *KubeFedSyncController) ensureReleased(ctx context.Context, fedResource FederatedResource) utils.ReconciliationStatus {
+	key := fedResource.FederatedName().String()
+	kind := fedResource.FederatedKind()
+
+	obj := fedResource.Object()
+	if !controllerutil.ContainsFinalizer(obj, FinalizerSyncController) {
+		// The resource was released or was never managed.
+		return utils.StatusAllOK
+	}
+
+	klog.V(2).Infof("Found %q annotation on %s %q. Releasing the resources it manages in member clusters.", utils.ReleaseAnnotation, kind, key)
+	if !s.typeConfig.GetObserveOnly() {
+		clusters, err := s.informer.GetClusters()
+		if err != nil {
+			runtime.HandleError(errors.Wrap(err, "failed to get member clusters"))
+			return utils.StatusError
+		}
+		targetClusters, err := fedResource.ComputePlacement(clusters)
+		if err != nil {
+			runtime.HandleError(errors.Wrapf(err, "failed to compute placement for %s %q", kind, key))
+			return utils.StatusError
+		}
+		ok, err := s.handleDeletionInClusters(ctx, fedResource.TargetGVK(), fedResource.TargetName(), targetClusters, func(dispatcher dispatch.UnmanagedDispatcher, clusterName string, clusterObj *unstructured.Unstructured) {
+			if clusterObj.GetDeletionTimestamp() != nil || !utils.HasManagedLabel(clusterObj) {
+				return
+			}
+			dispatcher.RemoveManagedMetadata(clusterName, clusterObj, fedResource.RemoveManagedMetadata)
+		})
+		if err == nil && !ok {
+			err = errors.New("failed to remove the managed metadata from resources in one or more clusters")
+		}
+		if err != nil {
+			fedResource.RecordError("ReleaseError", errors.Wrap(err, "Failed to release managed resources"))
+			runtime.HandleError(errors.Wrapf(err, "failed to release the resources managed by %s %q", kind, key))
+			return utils.StatusError
+		}
+	}
+
+	// The recorded versions no longer describe managed resources.
+	fedResource.DeleteVersions()
+
+	if err := s.removeFinalizer(fedResource); err != nil {
+		runtime.HandleError(errors.Wrapf(err, "failed to remove finalizer %q from %s %q", FinalizerSyncController, kind, key))
+		return utils.StatusError
+	}
+	fedResource.RecordEvent("Released", "Released the resources managed in member clusters")
+	return utils.StatusAllOK
+}
+
+// removeEmptyNamespaces deletes the namespace of the given federated
+// resource in the named clusters if KubeFed created it and it no
+// longer contains any resources. Failures are reported but do not
+// prevent propagation or deletion of the federated resource.
+func (s *KubeFedSyncController) removeEmptyNamespaces(fedResource FederatedResource, clusterNames sets.Set[string]) {
+	qualifiedName := fedResource.TargetName()
+	if !s.deleteEmptyNamespaces || len(clusterNames) == 0 || len(qualifiedName.Namespace) == 0 || fedResource.TargetKind() == utils.NamespaceKind {
+		return
+	}
+
+	for _, clusterName := range sets.List(clusterNames) {
+		client, err := s.informer.GetClientForCluster(clusterName)
+		if err != nil {
+			runtime.HandleError(errors.Wrapf(err, "Failed to get client for cluster %q", clusterName))
+			continue
+		}
+		resourceTypes, err := s.namespacedResourceTypes(clusterName)
+		if err != nil {
+			runtime.HandleError(errors.Wrapf(err, "Failed to determine the resource types of cluster %q", clusterName))
+			continue
+		}
+		namespace := utils.QualifiedNameForCluster(clusterName, qualifiedName).Namespace
+		deleted, err := utils.DeleteNamespaceIfEmpty(s.ctx, client, namespace, resourceTypes)
+		if err != nil {
+			runtime.HandleError(errors.Wrapf(err, "Failed to delete empty namespace %q in cluster %q", namespace, clusterName))
+			continue
+		}
+		if deleted {
+			fedResource.RecordEvent("DeleteEmptyNamespace", "Deleted namespace %q in cluster %q since it no longer contains resources", namespace, clusterName)
+		}
+	}
+}
+
+// discoverNamespacedResourceTypes discovers the namespaced resource
+// types of the named ready member cluster.
+func (s *KubeFedSyncController) discoverNamespacedResourceTypes(clusterName string) ([]metav1.APIResource, error) {
+	cluster, ok, err := s.informer.GetReadyCluster(clusterName)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errors.Errorf("cluster %q is not ready", clusterName)
+	}
+	config, err := utils.BuildRateLimitedClusterConfig(cluster, s.hostClusterClient, s.kubeFedNamespace, s.clusterClientRateLimits)
+	if err != nil {
+		return nil, err
+	}
+	return utils.NamespacedResourceTypes(config, utils.DefaultPreflightTimeout)
+}
+
 // removeManagedLabel attempts to remove the managed label from
 // resources with the given name in member clusters.
-func (s *KubeFedSyncController) removeManagedLabel(gvk schema.GroupVersionKind, qualifiedName utils.QualifiedName, clusters sets.Set[string]) error {
-	ok, err := s.handleDeletionInClusters(gvk, qualifiedName, clusters, func(dispatcher dispatch.UnmanagedDispatcher, clusterName string, clusterObj *unstructured.Unstructured) {
+func (s *KubeFedSyncController) removeManagedLabel(ctx context.Context, gvk schema.GroupVersionKind, qualifiedName utils.QualifiedName, clusters sets.Set[string]) error {
+	ok, err := s.handleDeletionInClusters(ctx, gvk, qualifiedName, clusters, func(dispatcher dispatch.UnmanagedDispatcher, clusterName string, clusterObj *unstructured.Unstructured) {
 		if clusterObj.GetDeletionTimestamp() != nil {
 			return
 		}
@@ -584,7 +1936,7 @@ func (s *KubeFedSyncController) removeManagedLabel(gvk schema.GroupVersionKind,
 	return nil
 }
 
-func (s *KubeFedSyncController) deleteFromClusters(fedResource FederatedResource, opts ...runtimeclient.DeleteOption) (bool, error) {
+func (s *KubeFedSyncController) deleteFromClusters(ctx context.Context, fedResource FederatedResource, opts ...runtimeclient.DeleteOption) (bool, error) {
 	gvk := fedResource.TargetGVK()
 	qualifiedName := fedResource.TargetName()
 
@@ -598,7 +1950,7 @@ func (s *KubeFedSyncController) deleteFromClusters(fedResource FederatedResource
 	}
 
 	var remainingClusters []string
-	ok, err := s.handleDeletionInClusters(gvk, qualifiedName, targetClusters, func(dispatcher dispatch.UnmanagedDispatcher, clusterName string, clusterObj *unstructured.Unstructured) {
+	ok, err := s.handleDeletionInClusters(ctx, gvk, qualifiedName, targetClusters, func(dispatcher dispatch.UnmanagedDispatcher, clusterName string, clusterObj *unstructured.Unstructured) {
 		// If the containing namespace of a FederatedNamespace is
 		// marked for deletion, it is impossible to require the
 		// removal of the namespace in advance of removal of the sync
@@ -639,10 +1991,11 @@ func (s *KubeFedSyncController) deleteFromClusters(fedResource FederatedResource
 		fedResource.RecordEvent("WaitForRemovalInCluster", "Waiting for managed resources to be removed from the following clusters: %s", remainingClustersStr)
 		return true, nil
 	}
-	err = s.ensureRemovedOrUnmanaged(fedResource)
+	err = s.ensureRemovedOrUnmanaged(ctx, fedResource)
 	if err != nil {
 		return false, errors.Wrapf(err, "failed to verify that managed resources no longer exist in any cluster")
 	}
+	s.removeEmptyNamespaces(fedResource, targetClusters)
 	// Managed resources no longer exist in any member cluster
 	return false, s.removeFinalizer(fedResource)
 }
@@ -652,7 +2005,7 @@ func (s *KubeFedSyncController) deleteFromClusters(fedResource FederatedResource
 // present or labeled as managed.  The checks are performed without
 // the informer to cover the possibility that the resources have not
 // yet been cached.
-func (s *KubeFedSyncController) ensureRemovedOrUnmanaged(fedResource FederatedResource) error {
+func (s *KubeFedSyncController) ensureRemovedOrUnmanaged(ctx context.Context, fedResource FederatedResource) error {
 	// 获取集群雷彪
 	clusters, err := s.informer.GetClusters()
 	if err != nil {
@@ -664,14 +2017,18 @@ func (s *KubeFedSyncController) ensureRemovedOrUnmanaged(fedResource FederatedRe
 		return errors.Wrapf(err, "failed to compute placement for %s %q", fedResource.FederatedKind(), fedResource.FederatedName().Name)
 	}
 
-	dispatcher := dispatch.NewCheckUnmanagedDispatcher(s.informer.GetClientForCluster, fedResource.TargetGVK(), fedResource.TargetName())
+	dispatcher := dispatch.NewCheckUnmanagedDispatcher(ctx, s.informer.GetClientForCluster, fedResource.TargetGVK(), fedResource.TargetName())
 
 	// 定义未就绪集群列表
-	var unreadyClusters []string
+	var unreadyClusters, maintenanceClusters []string
 	for _, cluster := range clusters {
 		if !targetClusters.Has(cluster.Name) {
 			continue
 		}
+		if utils.IsClusterInMaintenance(cluster) {
+			maintenanceClusters = append(maintenanceClusters, cluster.Name)
+			continue
+		}
 		if !utils.IsClusterReady(&cluster.Status) {
 			unreadyClusters = append(unreadyClusters, cluster.Name)
 			continue
@@ -685,6 +2042,9 @@ func (s *KubeFedSyncController) ensureRemovedOrUnmanaged(fedResource FederatedRe
 	if len(unreadyClusters) > 0 {
 		return errors.Errorf("the following clusters were not ready: %s", strings.Join(unreadyClusters, ", "))
 	}
+	if len(maintenanceClusters) > 0 {
+		return errors.Errorf("the following clusters are in maintenance: %s", strings.Join(maintenanceClusters, ", "))
+	}
 	if !ok {
 		return errors.Errorf("one or more checks failed")
 	}
@@ -693,16 +2053,17 @@ func (s *KubeFedSyncController) ensureRemovedOrUnmanaged(fedResource FederatedRe
 
 // handleDeletionInClusters invokes the provided deletion handler for
 // each managed resource in member clusters.
-func (s *KubeFedSyncController) handleDeletionInClusters(gvk schema.GroupVersionKind, qualifiedName utils.QualifiedName, clusters sets.Set[string],
+func (s *KubeFedSyncController) handleDeletionInClusters(ctx context.Context, gvk schema.GroupVersionKind, qualifiedName utils.QualifiedName, clusters sets.Set[string],
 	deletionFunc func(dispatcher dispatch.UnmanagedDispatcher, clusterName string, clusterObj *unstructured.Unstructured)) (bool, error) {
 	memberClusters, err := s.informer.GetClusters()
 	if err != nil {
 		return false, errors.Wrap(err, "failed to get a list of clusters")
 	}
 
-	dispatcher := dispatch.NewUnmanagedDispatcher(s.informer.GetClientForCluster, gvk, qualifiedName)
+	dispatcher := dispatch.NewUnmanagedDispatcher(ctx, s.informer.GetClientForCluster, gvk, qualifiedName)
 	var (
 		unreadyClusters          []string
+		maintenanceClusters      []string
 		retrievalFailureClusters []string
 	)
 	for _, cluster := range memberClusters {
@@ -711,6 +2072,13 @@ func (s *KubeFedSyncController) handleDeletionInClusters(gvk schema.GroupVersion
 			continue
 		}
 
+		if utils.IsClusterInMaintenance(cluster) {
+			// Resources in a cluster in maintenance are left
+			// untouched until the maintenance annotation is removed.
+			maintenanceClusters = append(maintenanceClusters, clusterName)
+			continue
+		}
+
 		if !utils.IsClusterReady(&cluster.Status) {
 			unreadyClusters = append(unreadyClusters, clusterName)
 			continue
@@ -740,6 +2108,9 @@ func (s *KubeFedSyncController) handleDeletionInClusters(gvk schema.GroupVersion
 	if len(unreadyClusters) > 0 {
 		return false, errors.Errorf("the following clusters were not ready: %s", strings.Join(unreadyClusters, ", "))
 	}
+	if len(maintenanceClusters) > 0 {
+		return false, errors.Errorf("the following clusters are in maintenance: %s", strings.Join(maintenanceClusters, ", "))
+	}
 	return ok, nil
 }
 
@@ -752,7 +2123,7 @@ func (s *KubeFedSyncController) ensureFinalizer(fedResource FederatedResource) e
 	patch := runtimeclient.MergeFrom(obj.DeepCopy())
 	controllerutil.AddFinalizer(obj, FinalizerSyncController)
 	klog.V(2).Infof("Adding finalizer %s to %s %q", FinalizerSyncController, fedResource.FederatedKind(), fedResource.FederatedName())
-	return s.hostClusterClient.Patch(context.TODO(), obj, patch)
+	return s.hostClusterClient.Patch(s.ctx, obj, patch)
 }
 
 func (s *KubeFedSyncController) removeFinalizer(fedResource FederatedResource) error {
@@ -764,5 +2135,5 @@ func (s *KubeFedSyncController) removeFinalizer(fedResource FederatedResource) e
 	patch := runtimeclient.MergeFrom(obj.DeepCopy())
 	controllerutil.RemoveFinalizer(obj, FinalizerSyncController)
 	klog.V(2).Infof("Removing finalizer %s from %s %q", FinalizerSyncController, fedResource.FederatedKind(), fedResource.FederatedName())
-	return s.hostClusterClient.Patch(context.TODO(), obj, patch)
+	return s.hostClusterClient.Patch(s.ctx, obj, patch)
 }
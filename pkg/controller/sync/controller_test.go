@@ -0,0 +1,3136 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sync
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+	"go.uber.org/goleak"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/apimachinery/pkg/util/wait"
+	restclient "k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/kubefed/pkg/apis/core/common"
+	fedv1b1 "sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
+	"sigs.k8s.io/kubefed/pkg/client/generic"
+	"sigs.k8s.io/kubefed/pkg/controller/quota"
+	"sigs.k8s.io/kubefed/pkg/controller/sync/status"
+	"sigs.k8s.io/kubefed/pkg/controller/utils"
+)
+
+// fakeAccessor visits a fixed set of federated resources and returns
+// fedResource, or one of otherFedResources, for the federated
+// resource of the same name. Names without a federated resource are
+// reported as possible orphans if possibleOrphan is set.
+type fakeAccessor struct {
+	FederatedResourceAccessor
+	objs              []*unstructured.Unstructured
+	fedResource       FederatedResource
+	otherFedResources []FederatedResource
+	possibleOrphan    bool
+	// hostClient, if set, stores the federated resources that are
+	// returned like an informer of the host cluster would.
+	hostClient *memoryClient
+}
+
+func (a *fakeAccessor) HasSynced() bool {
+	return true
+}
+
+func (a *fakeAccessor) FederatedResource(qualifiedName utils.QualifiedName) (FederatedResource, bool, error) {
+	for _, fedResource := range append([]FederatedResource{a.fedResource}, a.otherFedResources...) {
+		if fedResource != nil && fedResource.FederatedName() == qualifiedName {
+			// Like an informer, each reconciliation is given its own
+			// copy of the federated resource.
+			if fake, ok := fedResource.(*fakeFederatedResource); ok {
+				fake.fedObject = a.object(fake.fedObject)
+			}
+			return fedResource, false, nil
+		}
+	}
+	return nil, a.possibleOrphan, nil
+}
+
+// object returns a copy of the given federated resource as last stored
+// by the host client, if set.
+func (a *fakeAccessor) object(fedObject *unstructured.Unstructured) *unstructured.Unstructured {
+	if a.hostClient != nil {
+		if stored, ok := a.hostClient.objs[utils.NewQualifiedName(fedObject).String()]; ok {
+			return stored.DeepCopy()
+		}
+	}
+	return fedObject.DeepCopy()
+}
+
+func (a *fakeAccessor) VisitFederatedResources(visitFunc func(obj interface{})) {
+	for _, obj := range a.objs {
+		visitFunc(obj)
+	}
+}
+
+// recordingWorker records the delays with which names are enqueued.
+type recordingWorker struct {
+	utils.ReconcileWorker
+	delays map[utils.QualifiedName]time.Duration
+}
+
+func (w *recordingWorker) EnqueueWithDelay(qualifiedName utils.QualifiedName, delay time.Duration) {
+	w.delays[qualifiedName] = delay
+}
+
+// EnqueueForRetry records the name with a delay of zero.
+func (w *recordingWorker) EnqueueForRetry(qualifiedName utils.QualifiedName) {
+	w.delays[qualifiedName] = 0
+}
+
+func TestReconcileAll(t *testing.T) {
+	accessor := &fakeAccessor{}
+	for i := 0; i < 5; i++ {
+		obj := &unstructured.Unstructured{}
+		obj.SetNamespace("foo")
+		obj.SetName(fmt.Sprintf("bar-%d", i))
+		accessor.objs = append(accessor.objs, obj)
+	}
+	worker := &recordingWorker{delays: make(map[utils.QualifiedName]time.Duration)}
+	s := &KubeFedSyncController{
+		worker:      worker,
+		fedAccessor: accessor,
+		tracer:      noop.NewTracerProvider().Tracer(""),
+	}
+
+	count := s.ReconcileAll()
+	if count != len(accessor.objs) {
+		t.Fatalf("Expected %d objects to be enqueued, got %d", len(accessor.objs), count)
+	}
+	if len(worker.delays) != len(accessor.objs) {
+		t.Fatalf("Expected %d distinct objects to be enqueued, got %d", len(accessor.objs), len(worker.delays))
+	}
+	seen := make(map[time.Duration]bool)
+	for _, obj := range accessor.objs {
+		delay, ok := worker.delays[utils.NewQualifiedName(obj)]
+		if !ok {
+			t.Fatalf("Expected %s/%s to be enqueued", obj.GetNamespace(), obj.GetName())
+		}
+		if delay%reconcileAllInterval != 0 || seen[delay] {
+			t.Errorf("Expected enqueueing to be spaced by %v, got a delay of %v for %s", reconcileAllInterval, delay, obj.GetName())
+		}
+		seen[delay] = true
+	}
+}
+
+// fakeFederatedResource places a ConfigMap in all clusters. Methods
+// that are not overridden panic via the nil embedded interface.
+type fakeFederatedResource struct {
+	FederatedResource
+	fedObject  *unstructured.Unstructured
+	targetObj  *unstructured.Unstructured
+	versionMap map[string]string
+	// canaryClusterNames are the clusters that placement references
+	// with the Canary mode.
+	canaryClusterNames []string
+	// selectedClusterNames limit placement to the named clusters if
+	// not nil. All clusters are selected otherwise.
+	selectedClusterNames []string
+	removalStrategy      *fedv1b1.RemovalStrategy
+	// retainedClusters, if not nil, causes placement to be computed
+	// from the placement of fedObject like a federated resource of a
+	// cluster-scoped type, with the retention of clusters by
+	// placement stickiness shared across computations.
+	retainedClusters *utils.SafeMap
+	retainedUntil    map[string]time.Time
+	// eventReasons are the reasons of the recorded events. Events
+	// are recorded concurrently by cluster operations.
+	eventLock    sync.Mutex
+	eventReasons []string
+}
+
+func (f *fakeFederatedResource) FederatedName() utils.QualifiedName {
+	return utils.NewQualifiedName(f.fedObject)
+}
+func (f *fakeFederatedResource) FederatedKind() string { return f.fedObject.GetKind() }
+func (f *fakeFederatedResource) TargetName() utils.QualifiedName {
+	return utils.NewQualifiedName(f.targetObj)
+}
+func (f *fakeFederatedResource) TargetKind() string { return f.targetObj.GetKind() }
+func (f *fakeFederatedResource) TargetGVK() schema.GroupVersionKind {
+	return f.targetObj.GroupVersionKind()
+}
+func (f *fakeFederatedResource) Object() *unstructured.Unstructured           { return f.fedObject }
+func (f *fakeFederatedResource) NamespaceNotFederated() bool                  { return false }
+func (f *fakeFederatedResource) MinHealthyClusters() (*int32, error)          { return nil, nil }
+func (f *fakeFederatedResource) PropagationDeadline() (*time.Duration, error) { return nil, nil }
+func (f *fakeFederatedResource) RemovalStrategy() (*fedv1b1.RemovalStrategy, error) {
+	return f.removalStrategy, nil
+}
+func (f *fakeFederatedResource) DeletionGracePeriod() (time.Duration, error) {
+	return utils.GetDeletionGracePeriod(f.fedObject, nil)
+}
+func (f *fakeFederatedResource) ComputePlacement(clusters []*fedv1b1.KubeFedCluster) (sets.Set[string], error) {
+	if f.retainedClusters != nil {
+		// Like the accessor, a federated resource is created for
+		// each reconciliation.
+		r := &federatedResource{
+			typeConfig:        &fedv1b1.FederatedTypeConfig{},
+			federatedName:     f.FederatedName(),
+			federatedResource: f.fedObject,
+			retainedClusters:  f.retainedClusters,
+		}
+		selectedClusters, err := r.ComputePlacement(clusters)
+		f.retainedUntil = r.RetainedClusters()
+		return selectedClusters, err
+	}
+	return f.SelectClusters(clusters)
+}
+func (f *fakeFederatedResource) SelectClusters(clusters []*fedv1b1.KubeFedCluster) (sets.Set[string], error) {
+	if f.retainedClusters != nil {
+		r := &federatedResource{typeConfig: &fedv1b1.FederatedTypeConfig{}, federatedResource: f.fedObject}
+		return r.SelectClusters(clusters)
+	}
+	clusterNames := sets.New[string]()
+	for _, cluster := range clusters {
+		if f.selectedClusterNames == nil || slices.Contains(f.selectedClusterNames, cluster.Name) {
+			clusterNames.Insert(cluster.Name)
+		}
+	}
+	return clusterNames, nil
+}
+func (f *fakeFederatedResource) RetainedClusters() map[string]time.Time {
+	return f.retainedUntil
+}
+func (f *fakeFederatedResource) PlacementOnlyClusters() (sets.Set[string], error) {
+	return sets.New[string](), nil
+}
+func (f *fakeFederatedResource) CanaryClusters() (sets.Set[string], error) {
+	return sets.New[string](f.canaryClusterNames...), nil
+}
+func (f *fakeFederatedResource) OverrideClusterNames() (sets.Set[string], error) {
+	return sets.New[string](), nil
+}
+func (f *fakeFederatedResource) UpdateVersions(selectedClusters []string, versionMap map[string]string) error {
+	// Like the version manager, the versions of selected clusters that
+	// were not updated are retained.
+	updatedVersionMap := make(map[string]string)
+	for _, clusterName := range selectedClusters {
+		if version, ok := versionMap[clusterName]; ok {
+			updatedVersionMap[clusterName] = version
+		} else if version, ok := f.versionMap[clusterName]; ok {
+			updatedVersionMap[clusterName] = version
+		}
+	}
+	f.versionMap = updatedVersionMap
+	return nil
+}
+func (f *fakeFederatedResource) DeleteVersions() {
+	f.versionMap = nil
+}
+func (f *fakeFederatedResource) VersionForCluster(clusterName string) (string, error) {
+	return f.versionMap[clusterName], nil
+}
+func (f *fakeFederatedResource) ObjectForCluster(string) (*unstructured.Unstructured, error) {
+	return f.targetObj.DeepCopy(), nil
+}
+func (f *fakeFederatedResource) ApplyOverrides(*unstructured.Unstructured, string) error { return nil }
+func (f *fakeFederatedResource) Transform(_ context.Context, obj *unstructured.Unstructured, _ string) (*unstructured.Unstructured, error) {
+	return obj, nil
+}
+func (f *fakeFederatedResource) AddManagedMetadata(obj *unstructured.Unstructured) {
+	utils.AddManagedLabel(obj)
+}
+func (f *fakeFederatedResource) RemoveManagedMetadata(obj *unstructured.Unstructured) {
+	utils.RemoveManagedMetadata(obj, nil, nil, "")
+}
+func (f *fakeFederatedResource) SetPlacement(sets.Set[string]) {}
+func (f *fakeFederatedResource) PlacementAnnotationOutdated(*unstructured.Unstructured) bool {
+	return false
+}
+func (f *fakeFederatedResource) RecordError(errorCode string, _ error) {
+	f.eventLock.Lock()
+	defer f.eventLock.Unlock()
+	f.eventReasons = append(f.eventReasons, errorCode)
+}
+func (f *fakeFederatedResource) RecordEvent(reason string, _ string, _ ...interface{}) {
+	f.eventLock.Lock()
+	defer f.eventLock.Unlock()
+	f.eventReasons = append(f.eventReasons, reason)
+}
+func (f *fakeFederatedResource) IsNamespaceInHostCluster(runtimeclient.Object) bool { return false }
+func (f *fakeFederatedResource) IncludedFields() []string                           { return nil }
+func (f *fakeFederatedResource) SecretOverridePaths(string) []string                { return nil }
+func (f *fakeFederatedResource) ServerManagedMetadata() fedv1b1.ServerManagedMetadata {
+	return fedv1b1.ServerManagedMetadata{}
+}
+
+// memoryClient stores unstructured objects of a single kind by
+// namespace and name. Typed objects are stored in their unstructured
+// form. Methods that are not overridden panic via the nil embedded
+// interface.
+type memoryClient struct {
+	generic.Client
+	objs            map[string]*unstructured.Unstructured
+	resourceVersion int
+	// createErr is returned by Create, if set, instead of creating
+	// the object.
+	createErr error
+	// createStarted, if set, is signaled by Create, which then waits
+	// for releaseCreate before creating the object.
+	createStarted chan struct{}
+	releaseCreate chan struct{}
+	// getErr is returned by Get, if set, instead of the object.
+	getErr error
+	// updateStatusErr is returned by UpdateStatus, if set, instead of
+	// updating the status of the object.
+	updateStatusErr error
+	// deleteOptions are the options of the last deletion of each
+	// object.
+	deleteOptions map[string]*runtimeclient.DeleteOptions
+}
+
+func newMemoryClient() *memoryClient {
+	return &memoryClient{objs: make(map[string]*unstructured.Unstructured)}
+}
+
+func (c *memoryClient) store(obj runtimeclient.Object) {
+	c.resourceVersion++
+	obj.SetResourceVersion(fmt.Sprintf("%d", c.resourceVersion))
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		c.objs[utils.NewQualifiedName(obj).String()] = u.DeepCopy()
+		return
+	}
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		panic(err)
+	}
+	c.objs[utils.NewQualifiedName(obj).String()] = &unstructured.Unstructured{Object: content}
+}
+
+func (c *memoryClient) Create(_ context.Context, obj runtimeclient.Object) error {
+	if c.createErr != nil {
+		return c.createErr
+	}
+	if c.createStarted != nil {
+		c.createStarted <- struct{}{}
+		<-c.releaseCreate
+	}
+	qualifiedName := utils.NewQualifiedName(obj)
+	if _, ok := c.objs[qualifiedName.String()]; ok {
+		return errors.NewAlreadyExists(schema.GroupResource{}, qualifiedName.String())
+	}
+	c.store(obj)
+	return nil
+}
+
+func (c *memoryClient) Get(_ context.Context, obj runtimeclient.Object, namespace, name string) error {
+	if c.getErr != nil {
+		return c.getErr
+	}
+	qualifiedName := utils.QualifiedName{Namespace: namespace, Name: name}
+	stored, ok := c.objs[qualifiedName.String()]
+	if !ok {
+		return errors.NewNotFound(schema.GroupResource{}, qualifiedName.String())
+	}
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		stored.DeepCopyInto(u)
+		return nil
+	}
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(stored.DeepCopy().Object, obj)
+}
+
+// List lists the objects in the given namespace, which must all be of
+// the kind of the items of the list.
+func (c *memoryClient) List(_ context.Context, obj runtimeclient.ObjectList, namespace string, _ ...runtimeclient.ListOption) error {
+	items := []interface{}{}
+	for _, stored := range c.objs {
+		if stored.GetNamespace() == namespace {
+			items = append(items, stored.DeepCopy().Object)
+		}
+	}
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(map[string]interface{}{"items": items}, obj)
+}
+
+func (c *memoryClient) Update(_ context.Context, obj runtimeclient.Object) error {
+	c.store(obj)
+	return nil
+}
+
+func (c *memoryClient) UpdateStatus(_ context.Context, obj runtimeclient.Object) error {
+	if c.updateStatusErr != nil {
+		return c.updateStatusErr
+	}
+	c.store(obj)
+	return nil
+}
+
+// Patch stores the patched object. Like the API server, a patch that
+// carries a resource version is rejected if the stored object has
+// since changed. JSON merge patches are applied to the stored object,
+// which is returned in obj.
+func (c *memoryClient) Patch(_ context.Context, obj runtimeclient.Object, patch runtimeclient.Patch, _ ...runtimeclient.PatchOption) error {
+	data, err := patch.Data(obj)
+	if err != nil {
+		return err
+	}
+	stored, ok := c.objs[utils.NewQualifiedName(obj).String()]
+	patchContent := map[string]interface{}{}
+	if err := json.Unmarshal(data, &patchContent); err == nil {
+		resourceVersion, found, _ := unstructured.NestedString(patchContent, "metadata", "resourceVersion")
+		if found && ok && stored.GetResourceVersion() != resourceVersion {
+			return errors.NewConflict(schema.GroupResource{}, obj.GetName(), nil)
+		}
+	}
+	u, isUnstructured := obj.(*unstructured.Unstructured)
+	if patch.Type() != types.MergePatchType || !ok || !isUnstructured {
+		c.store(obj)
+		return nil
+	}
+	storedContent, err := stored.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	patchedContent, err := jsonpatch.MergePatch(storedContent, data)
+	if err != nil {
+		return err
+	}
+	if err := u.UnmarshalJSON(patchedContent); err != nil {
+		return err
+	}
+	c.store(u)
+	return nil
+}
+
+func (c *memoryClient) Delete(_ context.Context, _ runtimeclient.Object, namespace, name string, opts ...runtimeclient.DeleteOption) error {
+	qualifiedName := utils.QualifiedName{Namespace: namespace, Name: name}
+	if _, ok := c.objs[qualifiedName.String()]; !ok {
+		return errors.NewNotFound(schema.GroupResource{}, qualifiedName.String())
+	}
+	if c.deleteOptions == nil {
+		c.deleteOptions = make(map[string]*runtimeclient.DeleteOptions)
+	}
+	c.deleteOptions[qualifiedName.String()] = (&runtimeclient.DeleteOptions{}).ApplyOptions(opts)
+	delete(c.objs, qualifiedName.String())
+	return nil
+}
+
+// newFakeObjects returns a federated resource named foo/bar and its
+// target resource of the given API version and kind.
+func newFakeObjects(apiVersion, kind string) (fedObject, targetObj *unstructured.Unstructured) {
+	fedObject = &unstructured.Unstructured{}
+	fedObject.SetAPIVersion("types.kubefed.io/v1beta1")
+	fedObject.SetKind("Federated" + kind)
+	fedObject.SetNamespace("foo")
+	fedObject.SetName("bar")
+	targetObj = &unstructured.Unstructured{}
+	targetObj.SetAPIVersion(apiVersion)
+	targetObj.SetKind(kind)
+	targetObj.SetNamespace("foo")
+	targetObj.SetName("bar")
+	return fedObject, targetObj
+}
+
+// newHostClient returns a memory client for the host cluster that
+// stores a copy of the given federated resource.
+func newHostClient(t *testing.T, fedObject *unstructured.Unstructured) *memoryClient {
+	t.Helper()
+	hostClient := newMemoryClient()
+	if err := hostClient.Create(context.Background(), fedObject.DeepCopy()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return hostClient
+}
+
+// fakeInformer provides access to ready member clusters backed by
+// memory clients.
+type fakeInformer struct {
+	utils.FederatedInformer
+	clusters []*fedv1b1.KubeFedCluster
+	clients  map[string]*memoryClient
+	// staleObjs are returned by the target store for the named
+	// clusters in place of the objects of their memory clients, like
+	// a cache that has yet to observe changes.
+	staleObjs map[string]map[string]*unstructured.Unstructured
+	// clusterLock guards clusters for GetReadyCluster, which may be
+	// called concurrently with a test replacing them.
+	clusterLock sync.RWMutex
+}
+
+// newFakeInformer returns an informer for the named ready clusters,
+// each backed by an empty memory client.
+func newFakeInformer(clusterNames ...string) *fakeInformer {
+	informer := &fakeInformer{clients: make(map[string]*memoryClient)}
+	for _, clusterName := range clusterNames {
+		informer.clusters = append(informer.clusters, &fedv1b1.KubeFedCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: clusterName},
+			Status: fedv1b1.KubeFedClusterStatus{
+				Conditions: []fedv1b1.ClusterCondition{{Type: common.ClusterReady, Status: corev1.ConditionTrue}},
+			},
+		})
+		informer.clients[clusterName] = newMemoryClient()
+	}
+	return informer
+}
+
+func (i *fakeInformer) ClustersSynced() bool { return true }
+func (i *fakeInformer) GetClusters() ([]*fedv1b1.KubeFedCluster, error) {
+	return i.clusters, nil
+}
+func (i *fakeInformer) GetReadyClusters() ([]*fedv1b1.KubeFedCluster, error) {
+	return i.clusters, nil
+}
+func (i *fakeInformer) GetReadyCluster(name string) (*fedv1b1.KubeFedCluster, bool, error) {
+	i.clusterLock.RLock()
+	defer i.clusterLock.RUnlock()
+	for _, cluster := range i.clusters {
+		if cluster.Name == name {
+			return cluster, true, nil
+		}
+	}
+	return nil, false, nil
+}
+func (i *fakeInformer) GetClientForCluster(clusterName string) (generic.Client, error) {
+	return i.clients[clusterName], nil
+}
+func (i *fakeInformer) GetTargetStore() utils.FederatedReadOnlyStore {
+	return &fakeTargetStore{informer: i}
+}
+
+// fakeTargetStore reads the objects of the member clusters directly
+// from their memory clients.
+type fakeTargetStore struct {
+	utils.FederatedReadOnlyStore
+	informer *fakeInformer
+}
+
+func (s *fakeTargetStore) ClustersSynced([]*fedv1b1.KubeFedCluster) bool { return true }
+
+// ClusterSynced indicates whether the cluster has a memory client.
+func (s *fakeTargetStore) ClusterSynced(clusterName string) bool {
+	_, ok := s.informer.clients[clusterName]
+	return ok
+}
+func (s *fakeTargetStore) ListFromCluster(clusterName string) ([]interface{}, error) {
+	var objs []interface{}
+	for _, obj := range s.objs(clusterName) {
+		objs = append(objs, obj.DeepCopy())
+	}
+	return objs, nil
+}
+func (s *fakeTargetStore) GetFromAllClusters(key string) ([]utils.FederatedObject, error) {
+	var objs []utils.FederatedObject
+	for _, cluster := range s.informer.clusters {
+		if obj, ok := s.objs(cluster.Name)[key]; ok {
+			objs = append(objs, utils.FederatedObject{Object: obj.DeepCopy(), ClusterName: cluster.Name})
+		}
+	}
+	return objs, nil
+}
+func (s *fakeTargetStore) GetByKey(clusterName string, key string) (interface{}, bool, error) {
+	obj, ok := s.objs(clusterName)[key]
+	if !ok {
+		return nil, false, nil
+	}
+	return obj.DeepCopy(), true, nil
+}
+func (s *fakeTargetStore) objs(clusterName string) map[string]*unstructured.Unstructured {
+	if staleObjs, ok := s.informer.staleObjs[clusterName]; ok {
+		return staleObjs
+	}
+	return s.informer.clients[clusterName].objs
+}
+
+func TestReconcileOnce(t *testing.T) {
+	fedObject, targetObj := newFakeObjects("v1", "ConfigMap")
+
+	hostClient := newHostClient(t, fedObject)
+	informer := newFakeInformer("cluster1", "cluster2")
+	fedResource := &fakeFederatedResource{fedObject: fedObject, targetObj: targetObj}
+	s := &KubeFedSyncController{
+		informer:            informer,
+		fedAccessor:         &fakeAccessor{fedResource: fedResource},
+		hostClusterClient:   hostClient,
+		typeConfig:          &fedv1b1.FederatedTypeConfig{},
+		cacheSyncTimeout:    time.Second,
+		unreachableClusters: utils.NewSafeMap(),
+		limitedScope:        true,
+		ctx:                 context.Background(),
+		// The tracer is left unset so that reconciliation falls back
+		// to a no-op tracer.
+	}
+
+	expectResults := func(result *ReconcileResult, expected status.ApplyResult) {
+		t.Helper()
+		if result.Status != utils.StatusAllOK {
+			t.Fatalf("Expected reconciliation to succeed, got %v", result.Status)
+		}
+		if result.PropagationStatus == nil {
+			t.Fatalf("Expected propagation status to be collected")
+		}
+		for _, cluster := range informer.clusters {
+			applyResult := result.PropagationStatus.ApplyResults[cluster.Name]
+			if applyResult.Result != expected {
+				t.Fatalf("Expected the apply result for %q to be %q, got %q", cluster.Name, expected, applyResult.Result)
+			}
+		}
+	}
+
+	result, err := s.ReconcileOnce(context.Background(), fedObject)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expectResults(result, status.ApplyCreated)
+	for clusterName, client := range informer.clients {
+		if _, ok := client.objs[utils.NewQualifiedName(targetObj).String()]; !ok {
+			t.Fatalf("Expected the ConfigMap to be created in %q", clusterName)
+		}
+	}
+
+	// A subsequent reconciliation finds the clusters current.
+	result, err = s.ReconcileOnce(context.Background(), fedObject)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expectResults(result, status.ApplyUnchanged)
+}
+
+func TestReconcileOnceWaitsForWorker(t *testing.T) {
+	fedObject, targetObj := newFakeObjects("v1", "ConfigMap")
+
+	informer := newFakeInformer("cluster1")
+	client := informer.clients["cluster1"]
+	client.createStarted = make(chan struct{})
+	client.releaseCreate = make(chan struct{})
+	s := &KubeFedSyncController{
+		informer:            informer,
+		fedAccessor:         &fakeAccessor{fedResource: &fakeFederatedResource{fedObject: fedObject, targetObj: targetObj}},
+		hostClusterClient:   newHostClient(t, fedObject),
+		typeConfig:          &fedv1b1.FederatedTypeConfig{},
+		cacheSyncTimeout:    time.Second,
+		unreachableClusters: utils.NewSafeMap(),
+		limitedScope:        true,
+		ctx:                 context.Background(),
+	}
+
+	// A worker is creating the ConfigMap.
+	workerDone := make(chan utils.ReconciliationStatus)
+	go func() {
+		workerDone <- s.reconcile(utils.NewQualifiedName(fedObject))
+	}()
+	<-client.createStarted
+
+	type reconcileOnceResult struct {
+		result *ReconcileResult
+		err    error
+	}
+	reconcileOnceDone := make(chan reconcileOnceResult)
+	reconciledObject := fedObject.DeepCopy()
+	go func() {
+		result, err := s.ReconcileOnce(context.Background(), reconciledObject)
+		reconcileOnceDone <- reconcileOnceResult{result, err}
+	}()
+	select {
+	case <-reconcileOnceDone:
+		t.Fatalf("Expected ReconcileOnce to wait for the reconciliation by the worker")
+	case <-client.createStarted:
+		t.Fatalf("Expected ReconcileOnce not to create the ConfigMap concurrently with the worker")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(client.releaseCreate)
+	if workerStatus := <-workerDone; workerStatus != utils.StatusAllOK {
+		t.Fatalf("Expected reconciliation by the worker to succeed, got %v", workerStatus)
+	}
+	var done reconcileOnceResult
+	select {
+	case done = <-reconcileOnceDone:
+	case <-time.After(wait.ForeverTestTimeout):
+		t.Fatalf("Expected ReconcileOnce to complete once the worker is done")
+	}
+	if done.err != nil {
+		t.Fatalf("Unexpected error: %v", done.err)
+	}
+	// The ConfigMap created by the worker is found current.
+	if applyResult := done.result.PropagationStatus.ApplyResults["cluster1"]; applyResult.Result != status.ApplyUnchanged {
+		t.Fatalf("Expected the apply result to be %q, got %q", status.ApplyUnchanged, applyResult.Result)
+	}
+}
+
+func TestReconcileOnceClassifiesApplyErrors(t *testing.T) {
+	testCases := map[string]struct {
+		createErr      error
+		expectedResult utils.ReconciliationStatus
+		expectedStatus status.PropagationStatus
+		expectedFailed *status.AggregateReason
+		expectedDelay  time.Duration
+	}{
+		"invalid creation is not retried": {
+			createErr:      errors.NewInvalid(schema.GroupKind{Kind: "ConfigMap"}, "bar", field.ErrorList{field.Required(field.NewPath("data"), "")}),
+			expectedResult: utils.StatusAllOK,
+			expectedStatus: status.CreationRejected,
+			expectedFailed: ptr.To(status.ApplyRejected),
+		},
+		"forbidden creation is retried after a long delay": {
+			createErr:      errors.NewForbidden(schema.GroupResource{Resource: "configmaps"}, "bar", fmt.Errorf("cannot create resource")),
+			expectedResult: utils.StatusAllOK,
+			expectedStatus: status.CreationForbidden,
+			expectedDelay:  forbiddenRetryInterval,
+		},
+		"timed out creation is retried": {
+			createErr:      errors.NewServerTimeout(schema.GroupResource{Resource: "configmaps"}, "create", 1),
+			expectedResult: utils.StatusError,
+			expectedStatus: status.CreationFailed,
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			fedObject, targetObj := newFakeObjects("v1", "ConfigMap")
+
+			hostClient := newHostClient(t, fedObject)
+			informer := newFakeInformer("cluster1", "cluster2")
+			informer.clients["cluster2"].createErr = tc.createErr
+			fedResource := &fakeFederatedResource{fedObject: fedObject, targetObj: targetObj}
+			worker := &recordingWorker{delays: make(map[utils.QualifiedName]time.Duration)}
+			s := &KubeFedSyncController{
+				worker:              worker,
+				informer:            informer,
+				fedAccessor:         &fakeAccessor{fedResource: fedResource},
+				hostClusterClient:   hostClient,
+				typeConfig:          &fedv1b1.FederatedTypeConfig{},
+				cacheSyncTimeout:    time.Second,
+				unreachableClusters: utils.NewSafeMap(),
+				limitedScope:        true,
+				ctx:                 context.Background(),
+				tracer:              noop.NewTracerProvider().Tracer(""),
+			}
+
+			result, err := s.ReconcileOnce(context.Background(), fedObject)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result.Status != tc.expectedResult {
+				t.Fatalf("Expected reconciliation to result in %v, got %v", tc.expectedResult, result.Status)
+			}
+			expectedStatus := status.PropagationStatusMap{
+				"cluster1": status.ClusterPropagationOK,
+				"cluster2": tc.expectedStatus,
+			}
+			if !reflect.DeepEqual(expectedStatus, result.PropagationStatus.StatusMap) {
+				t.Fatalf("Expected status %v, got %v", expectedStatus, result.PropagationStatus.StatusMap)
+			}
+			if delay := worker.delays[utils.NewQualifiedName(fedObject)]; delay != tc.expectedDelay {
+				t.Fatalf("Expected the federated resource to be reconciled again after %v, got %v", tc.expectedDelay, delay)
+			}
+
+			resource, err := status.DecodeGenericFederatedResource(hostClient.objs[utils.NewQualifiedName(fedObject).String()])
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			var failedCondition *status.GenericCondition
+			for _, condition := range resource.Status.Conditions {
+				if condition.Type == status.FailedConditionType {
+					failedCondition = condition
+				}
+			}
+			switch {
+			case tc.expectedFailed == nil && failedCondition != nil:
+				t.Fatalf("Expected no Failed condition, got %v", failedCondition)
+			case tc.expectedFailed != nil && (failedCondition == nil || failedCondition.Status != corev1.ConditionTrue || failedCondition.Reason != *tc.expectedFailed):
+				t.Fatalf("Expected a Failed condition with reason %q, got %v", *tc.expectedFailed, failedCondition)
+			case tc.expectedFailed != nil && !strings.Contains(failedCondition.Message, "cluster2"):
+				t.Fatalf("Expected the Failed condition to name the rejecting cluster, got %q", failedCondition.Message)
+			}
+		})
+	}
+}
+
+func TestReconcileOnceEvictsFailingCluster(t *testing.T) {
+	testCases := map[string]struct {
+		taintEffect     corev1.TaintEffect
+		failingClusters []string
+		expectedTainted int
+		expectedEvent   string
+	}{
+		"existing resources are retained": {
+			taintEffect:     corev1.TaintEffectNoSchedule,
+			failingClusters: []string{"cluster2"},
+			expectedTainted: 1,
+			expectedEvent:   "ClusterEvicted",
+		},
+		"existing resources are removed": {
+			taintEffect:     corev1.TaintEffectNoExecute,
+			failingClusters: []string{"cluster2"},
+			expectedTainted: 1,
+			expectedEvent:   "ClusterEvicted",
+		},
+		"the last healthy cluster is not tainted": {
+			taintEffect:     corev1.TaintEffectNoExecute,
+			failingClusters: []string{"cluster1", "cluster2"},
+			expectedTainted: 1,
+			expectedEvent:   "ClusterEvictionSkipped",
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			hostClient := newMemoryClient()
+			accessor := &fakeAccessor{}
+			var fedObjects []*unstructured.Unstructured
+			for i := 0; i < 3; i++ {
+				fedObject := &unstructured.Unstructured{}
+				fedObject.SetAPIVersion("types.kubefed.io/v1beta1")
+				fedObject.SetKind("FederatedConfigMap")
+				fedObject.SetNamespace("foo")
+				fedObject.SetName(fmt.Sprintf("bar-%d", i))
+				targetObj := &unstructured.Unstructured{}
+				targetObj.SetAPIVersion("v1")
+				targetObj.SetKind("ConfigMap")
+				targetObj.SetNamespace("foo")
+				targetObj.SetName(fedObject.GetName())
+				if err := hostClient.Create(context.Background(), fedObject); err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				fedResource := &fakeFederatedResource{fedObject: fedObject, targetObj: targetObj}
+				if accessor.fedResource == nil {
+					accessor.fedResource = fedResource
+				} else {
+					accessor.otherFedResources = append(accessor.otherFedResources, fedResource)
+				}
+				fedObjects = append(fedObjects, fedObject)
+			}
+			informer := &fakeInformer{clients: make(map[string]*memoryClient)}
+			for _, clusterName := range []string{"cluster1", "cluster2"} {
+				cluster := &fedv1b1.KubeFedCluster{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "kube-federation-system", Name: clusterName},
+					Status: fedv1b1.KubeFedClusterStatus{
+						Conditions: []fedv1b1.ClusterCondition{{Type: common.ClusterReady, Status: corev1.ConditionTrue}},
+					},
+				}
+				if err := hostClient.Create(context.Background(), cluster); err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				informer.clusters = append(informer.clusters, cluster)
+				informer.clients[clusterName] = newMemoryClient()
+			}
+			for _, clusterName := range tc.failingClusters {
+				informer.clients[clusterName].createErr = errors.NewServiceUnavailable("overloaded")
+			}
+			recorder := record.NewFakeRecorder(10)
+			s := &KubeFedSyncController{
+				informer:              informer,
+				fedAccessor:           accessor,
+				hostClusterClient:     hostClient,
+				kubeFedNamespace:      "kube-federation-system",
+				typeConfig:            &fedv1b1.FederatedTypeConfig{},
+				cacheSyncTimeout:      time.Second,
+				unreachableClusters:   utils.NewSafeMap(),
+				limitedScope:          true,
+				eventRecorder:         recorder,
+				clusterFailureTracker: utils.NewClusterFailureTracker(50, 3, time.Minute, 1, tc.taintEffect),
+				ctx:                   context.Background(),
+				tracer:                noop.NewTracerProvider().Tracer(""),
+			}
+
+			taints := func(clusterName string) []corev1.Taint {
+				t.Helper()
+				cluster := &fedv1b1.KubeFedCluster{}
+				if err := hostClient.Get(context.Background(), cluster, "kube-federation-system", clusterName); err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				return cluster.Spec.Taints
+			}
+			reconcile := func(fedObject *unstructured.Unstructured) {
+				t.Helper()
+				if _, err := s.ReconcileOnce(context.Background(), fedObject); err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+			}
+
+			// Retries of the same resource only count once towards the
+			// minimum number of applies.
+			for i := 0; i < 3; i++ {
+				reconcile(fedObjects[0])
+			}
+			for _, clusterName := range tc.failingClusters {
+				if clusterTaints := taints(clusterName); len(clusterTaints) > 0 {
+					t.Fatalf("Expected %s not to be tainted for the retries of a single resource, got %v", clusterName, clusterTaints)
+				}
+			}
+			reconcile(fedObjects[1])
+			reconcile(fedObjects[2])
+
+			tainted := 0
+			for _, clusterName := range []string{"cluster1", "cluster2"} {
+				clusterTaints := taints(clusterName)
+				if len(clusterTaints) == 0 {
+					continue
+				}
+				if !slices.Contains(tc.failingClusters, clusterName) {
+					t.Fatalf("Expected %s not to be tainted, got %v", clusterName, clusterTaints)
+				}
+				if len(clusterTaints) != 1 || clusterTaints[0].Key != utils.ClusterEvictionTaintKey || clusterTaints[0].Effect != tc.taintEffect || clusterTaints[0].TimeAdded == nil {
+					t.Fatalf("Expected %s to be tainted with %s:%s, got %v", clusterName, utils.ClusterEvictionTaintKey, tc.taintEffect, clusterTaints)
+				}
+				tainted++
+			}
+			if tainted != tc.expectedTainted {
+				t.Fatalf("Expected %d tainted clusters, got %d", tc.expectedTainted, tainted)
+			}
+			var events []string
+			for len(recorder.Events) > 0 {
+				events = append(events, <-recorder.Events)
+			}
+			found := false
+			for _, event := range events {
+				if strings.Contains(event, tc.expectedEvent) && strings.Contains(event, "3 of 3 applies") {
+					found = true
+				}
+			}
+			if !found {
+				t.Fatalf("Expected a %s event, got %v", tc.expectedEvent, events)
+			}
+		})
+	}
+}
+
+func TestReconcileOnceRecordsSpans(t *testing.T) {
+	fedObject, targetObj := newFakeObjects("v1", "ConfigMap")
+
+	hostClient := newHostClient(t, fedObject)
+	informer := newFakeInformer("cluster1", "cluster2")
+	recorder := tracetest.NewSpanRecorder()
+	controllerConfig := &utils.ControllerConfig{
+		TracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)),
+	}
+	s := &KubeFedSyncController{
+		informer:            informer,
+		fedAccessor:         &fakeAccessor{fedResource: &fakeFederatedResource{fedObject: fedObject, targetObj: targetObj}},
+		hostClusterClient:   hostClient,
+		typeConfig:          &fedv1b1.FederatedTypeConfig{},
+		cacheSyncTimeout:    time.Second,
+		unreachableClusters: utils.NewSafeMap(),
+		limitedScope:        true,
+		ctx:                 context.Background(),
+		tracer:              controllerConfig.Tracer(tracerName),
+	}
+
+	if _, err := s.ReconcileOnce(context.Background(), fedObject); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var root sdktrace.ReadOnlySpan
+	children := make(map[string][]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		if !span.Parent().IsValid() {
+			if root != nil {
+				t.Fatalf("Expected a single root span, got %q and %q", root.Name(), span.Name())
+			}
+			root = span
+			continue
+		}
+		children[span.Name()] = append(children[span.Name()], span)
+	}
+	if root == nil || root.Name() != "reconcile" {
+		t.Fatalf("Expected a root span named %q", "reconcile")
+	}
+	expectedChildren := map[string]int{
+		"compute-placement": 1,
+		"per-cluster-apply": len(informer.clusters),
+		"status-update":     1,
+	}
+	if len(children) != len(expectedChildren) {
+		t.Fatalf("Expected child spans %v, got %v", expectedChildren, children)
+	}
+	for name, count := range expectedChildren {
+		if len(children[name]) != count {
+			t.Fatalf("Expected %d %q spans, got %d", count, name, len(children[name]))
+		}
+		for _, span := range children[name] {
+			if span.Parent().SpanID() != root.SpanContext().SpanID() {
+				t.Fatalf("Expected the %q span to be a child of the %q span", name, root.Name())
+			}
+		}
+	}
+	clusterNames := sets.New[string]()
+	for _, span := range children["per-cluster-apply"] {
+		for _, attr := range span.Attributes() {
+			if attr.Key == "kubefed.cluster" {
+				clusterNames.Insert(attr.Value.AsString())
+			}
+		}
+	}
+	if !clusterNames.Equal(sets.New[string]("cluster1", "cluster2")) {
+		t.Fatalf("Expected a %q span for each cluster, got spans for %v", "per-cluster-apply", sets.List(clusterNames))
+	}
+}
+
+func TestReconcileOnceObserveOnly(t *testing.T) {
+	fedObject, targetObj := newFakeObjects("v1", "ConfigMap")
+
+	hostClient := newHostClient(t, fedObject)
+	informer := newFakeInformer("cluster1", "cluster2")
+	// The resource only exists in cluster1 and is not managed.
+	if err := informer.clients["cluster1"].Create(context.Background(), targetObj.DeepCopy()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	worker := &recordingWorker{delays: make(map[utils.QualifiedName]time.Duration)}
+	fedResource := &fakeFederatedResource{fedObject: fedObject, targetObj: targetObj}
+	s := &KubeFedSyncController{
+		worker:              worker,
+		informer:            informer,
+		fedAccessor:         &fakeAccessor{fedResource: fedResource},
+		hostClusterClient:   hostClient,
+		typeConfig:          &fedv1b1.FederatedTypeConfig{Spec: fedv1b1.FederatedTypeConfigSpec{ObserveOnly: true}},
+		cacheSyncTimeout:    time.Second,
+		unreachableClusters: utils.NewSafeMap(),
+		limitedScope:        true,
+		ctx:                 context.Background(),
+		tracer:              noop.NewTracerProvider().Tracer(""),
+	}
+
+	result, err := s.ReconcileOnce(context.Background(), fedObject)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.PropagationStatus == nil {
+		t.Fatalf("Expected propagation status to be collected")
+	}
+	expectedStatus := status.PropagationStatusMap{
+		"cluster1": status.ClusterPropagationOK,
+		"cluster2": status.ResourceNotFound,
+	}
+	if !reflect.DeepEqual(expectedStatus, result.PropagationStatus.StatusMap) {
+		t.Fatalf("Expected status %v, got %v", expectedStatus, result.PropagationStatus.StatusMap)
+	}
+	// A missing resource is reported rather than retried with backoff.
+	if result.Status != utils.StatusAllOK {
+		t.Fatalf("Expected reconcile status %v, got %v", utils.StatusAllOK, result.Status)
+	}
+
+	key := utils.NewQualifiedName(targetObj).String()
+	if rv := informer.clients["cluster1"].objs[key].GetResourceVersion(); rv != "1" {
+		t.Fatalf("Expected the ConfigMap in %q not to be modified, got resource version %q", "cluster1", rv)
+	}
+	if utils.HasManagedLabel(informer.clients["cluster1"].objs[key]) {
+		t.Fatalf("Expected the ConfigMap in %q not to be labeled as managed", "cluster1")
+	}
+	if _, ok := informer.clients["cluster2"].objs[key]; ok {
+		t.Fatalf("Expected the ConfigMap not to be created in %q", "cluster2")
+	}
+	storedFedObject := hostClient.objs[utils.NewQualifiedName(fedObject).String()]
+	if len(storedFedObject.GetFinalizers()) != 0 {
+		t.Fatalf("Expected no finalizer to be added, got %v", storedFedObject.GetFinalizers())
+	}
+	if delay := worker.delays[utils.NewQualifiedName(fedObject)]; delay != observeInterval {
+		t.Fatalf("Expected the federated resource to be reconciled again after %v, got %v", observeInterval, delay)
+	}
+}
+
+func TestReconcileOncePropagationPaused(t *testing.T) {
+	fedObject, targetObj := newFakeObjects("v1", "ConfigMap")
+
+	hostClient := newHostClient(t, fedObject)
+	informer := newFakeInformer("cluster1", "cluster2")
+	pause := &utils.PropagationPause{}
+	pause.Set(true)
+	fedResource := &fakeFederatedResource{fedObject: fedObject, targetObj: targetObj}
+	s := &KubeFedSyncController{
+		informer:            informer,
+		fedAccessor:         &fakeAccessor{fedResource: fedResource},
+		hostClusterClient:   hostClient,
+		typeConfig:          &fedv1b1.FederatedTypeConfig{},
+		cacheSyncTimeout:    time.Second,
+		unreachableClusters: utils.NewSafeMap(),
+		limitedScope:        true,
+		propagationPause:    pause,
+		ctx:                 context.Background(),
+		tracer:              noop.NewTracerProvider().Tracer(""),
+	}
+
+	key := utils.NewQualifiedName(targetObj).String()
+	result, err := s.ReconcileOnce(context.Background(), fedObject)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.PropagationStatus == nil {
+		t.Fatalf("Expected propagation status to be collected")
+	}
+	expectedStatus := status.PropagationStatusMap{
+		"cluster1": status.Paused,
+		"cluster2": status.Paused,
+	}
+	if !reflect.DeepEqual(expectedStatus, result.PropagationStatus.StatusMap) {
+		t.Fatalf("Expected status %v, got %v", expectedStatus, result.PropagationStatus.StatusMap)
+	}
+	for clusterName, client := range informer.clients {
+		if _, ok := client.objs[key]; ok {
+			t.Fatalf("Expected the ConfigMap not to be created in %q while propagation is paused", clusterName)
+		}
+	}
+	if fedResource.versionMap != nil {
+		t.Fatalf("Expected no versions to be recorded while propagation is paused, got %v", fedResource.versionMap)
+	}
+	storedFedObject := hostClient.objs[utils.NewQualifiedName(fedObject).String()]
+	conditions, _, _ := unstructured.NestedSlice(storedFedObject.Object, "status", "conditions")
+	if len(conditions) != 1 || conditions[0].(map[string]interface{})["reason"] != string(status.PropagationPaused) {
+		t.Fatalf("Expected the federated resource to be reported as paused, got conditions %v", conditions)
+	}
+
+	// Resumption of propagation
+	pause.Set(false)
+	result, err = s.ReconcileOnce(context.Background(), fedObject)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for clusterName, client := range informer.clients {
+		if _, ok := client.objs[key]; !ok {
+			t.Fatalf("Expected the ConfigMap to be created in %q once propagation is resumed", clusterName)
+		}
+		if applyResult := result.PropagationStatus.ApplyResults[clusterName]; applyResult.Result != status.ApplyCreated {
+			t.Fatalf("Expected the apply result for %q to be %q, got %q", clusterName, status.ApplyCreated, applyResult.Result)
+		}
+	}
+}
+
+// preflightCall is a call of the connectivity preflight of a cluster
+// that returns once the test provides its result.
+type preflightCall struct {
+	resourceVersion string
+	result          chan error
+}
+
+func TestPreflightCluster(t *testing.T) {
+	informer := &fakeInformer{clusters: []*fedv1b1.KubeFedCluster{{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster1", ResourceVersion: "1"},
+	}}}
+	calls := make(chan preflightCall)
+	s := &KubeFedSyncController{
+		informer:              informer,
+		unreachableClusters:   utils.NewSafeMap(),
+		clusterDeliverer:      utils.NewDelayingDeliverer(),
+		clusterAvailableDelay: 10 * time.Millisecond,
+		ctx:                   context.Background(),
+		preflight: func(cluster *fedv1b1.KubeFedCluster) error {
+			call := preflightCall{resourceVersion: cluster.ResourceVersion, result: make(chan error)}
+			calls <- call
+			return <-call.result
+		},
+	}
+	nextCall := func(expectedResourceVersion string) preflightCall {
+		t.Helper()
+		select {
+		case call := <-calls:
+			if call.resourceVersion != expectedResourceVersion {
+				t.Fatalf("Expected the preflight of resource version %q, got %q", expectedResourceVersion, call.resourceVersion)
+			}
+			return call
+		case <-time.After(wait.ForeverTestTimeout):
+			t.Fatalf("Timed out waiting for a preflight")
+		}
+		return preflightCall{}
+	}
+	expectNoCall := func() {
+		t.Helper()
+		select {
+		case call := <-calls:
+			call.result <- nil
+			t.Fatalf("Unexpected preflight of resource version %q", call.resourceVersion)
+		case <-time.After(10 * s.clusterAvailableDelay):
+		}
+	}
+	unreachable := fmt.Errorf("unreachable")
+
+	// The cluster becomes available twice, e.g. because its
+	// credentials changed, and only the later preflight is retried
+	// with the current KubeFedCluster.
+	s.startPreflight("cluster1")
+	superseded := nextCall("1")
+	s.startPreflight("cluster1")
+	current := nextCall("1")
+	informer.clusterLock.Lock()
+	informer.clusters = []*fedv1b1.KubeFedCluster{{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster1", ResourceVersion: "2"},
+	}}
+	informer.clusterLock.Unlock()
+	superseded.result <- unreachable
+	current.result <- unreachable
+	retry := nextCall("2")
+	expectNoCall()
+	if err := s.clusterPreflightError("cluster1"); err != unreachable {
+		t.Fatalf("Expected the cluster to be unreachable, got %v", err)
+	}
+
+	// Retries stop once the cluster becomes unavailable.
+	s.stopPreflight("cluster1")
+	retry.result <- unreachable
+	expectNoCall()
+
+	// The cluster is reachable once it becomes available again.
+	s.startPreflight("cluster1")
+	nextCall("2").result <- nil
+	if err := wait.PollUntilContextTimeout(context.Background(), s.clusterAvailableDelay, wait.ForeverTestTimeout, true, func(context.Context) (bool, error) {
+		return s.clusterPreflightError("cluster1") == nil, nil
+	}); err != nil {
+		t.Fatalf("Expected the cluster to be reachable")
+	}
+}
+
+func TestReconcileOnceRetainsDeselectedClusters(t *testing.T) {
+	fedObject, targetObj := newFakeObjects("v1", "ConfigMap")
+	if err := unstructured.SetNestedStringMap(fedObject.Object, map[string]string{"region": "us"}, "spec", "placement", "clusterSelector", "matchLabels"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := utils.SetPlacementStickiness(fedObject, ptr.To[int64](300)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	hostClient := newHostClient(t, fedObject)
+	informer := &fakeInformer{clients: make(map[string]*memoryClient)}
+	for _, clusterName := range []string{"cluster1", "cluster2"} {
+		informer.clusters = append(informer.clusters, &fedv1b1.KubeFedCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: clusterName, Labels: map[string]string{"region": "us"}},
+			Status: fedv1b1.KubeFedClusterStatus{
+				Conditions: []fedv1b1.ClusterCondition{{Type: common.ClusterReady, Status: corev1.ConditionTrue}},
+			},
+		})
+		informer.clients[clusterName] = newMemoryClient()
+	}
+	fedResource := &fakeFederatedResource{fedObject: fedObject, targetObj: targetObj, retainedClusters: utils.NewSafeMap()}
+	worker := &recordingWorker{delays: make(map[utils.QualifiedName]time.Duration)}
+	s := &KubeFedSyncController{
+		worker:              worker,
+		informer:            informer,
+		fedAccessor:         &fakeAccessor{fedResource: fedResource, hostClient: hostClient},
+		hostClusterClient:   hostClient,
+		typeConfig:          &fedv1b1.FederatedTypeConfig{},
+		cacheSyncTimeout:    time.Second,
+		unreachableClusters: utils.NewSafeMap(),
+		limitedScope:        true,
+		ctx:                 context.Background(),
+	}
+	reconcile := func(expectedStatus utils.ReconciliationStatus) {
+		t.Helper()
+		result, err := s.ReconcileOnce(context.Background(), fedObject)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if result.Status != expectedStatus {
+			t.Fatalf("Expected reconciliation status %v, got %v", expectedStatus, result.Status)
+		}
+	}
+	key := utils.NewQualifiedName(targetObj).String()
+	expectPropagated := func(clusterName string, expected bool) {
+		t.Helper()
+		if _, ok := informer.clients[clusterName].objs[key]; ok != expected {
+			t.Fatalf("Expected the ConfigMap to be propagated to %q: %v", clusterName, expected)
+		}
+	}
+
+	reconcile(utils.StatusAllOK)
+	expectPropagated("cluster2", true)
+
+	// cluster2 is no longer selected and is retained even though the
+	// status recording its retention could not be written.
+	informer.clusters[1].Labels = map[string]string{"region": "eu"}
+	hostClient.updateStatusErr = errors.NewInternalError(fmt.Errorf("unavailable"))
+	reconcile(utils.StatusError)
+	expectPropagated("cluster2", true)
+	retainedUntil, ok := fedResource.retainedUntil["cluster2"]
+	if !ok {
+		t.Fatalf("Expected cluster2 to be retained")
+	}
+
+	// The retention is not restarted by the next reconciliation,
+	// which reads the status that was last written.
+	hostClient.updateStatusErr = nil
+	reconcile(utils.StatusAllOK)
+	expectPropagated("cluster2", true)
+	if until := fedResource.retainedUntil["cluster2"]; !until.Equal(retainedUntil) {
+		t.Fatalf("Expected cluster2 to be retained until %v, got %v", retainedUntil, until)
+	}
+	if delay, ok := worker.delays[fedResource.FederatedName()]; !ok || delay <= 0 || delay > 5*time.Minute {
+		t.Fatalf("Expected reconciliation to be requested once the retention elapses, got %v", delay)
+	}
+
+	// A taint that would remove the resource from the cluster ends
+	// its retention.
+	informer.clusters[1].Spec.Taints = []corev1.Taint{{Key: "maintenance", Effect: corev1.TaintEffectNoExecute}}
+	reconcile(utils.StatusAllOK)
+	expectPropagated("cluster2", false)
+	expectPropagated("cluster1", true)
+	if _, ok := fedResource.retainedClusters.Get(fedResource.FederatedName().String()); ok {
+		t.Fatalf("Expected the retention to be forgotten")
+	}
+}
+
+func TestReconcileOnceRemovesStatusOfDeselectedCluster(t *testing.T) {
+	fedObject, targetObj := newFakeObjects("v1", "ConfigMap")
+	// The status of the resource reflects its current generation, so
+	// that updates of the status do not change its version.
+	targetObj.SetGeneration(1)
+
+	hostClient := newHostClient(t, fedObject)
+	informer := newFakeInformer("cluster1", "cluster2", "cluster3")
+	fedResource := &fakeFederatedResource{fedObject: fedObject, targetObj: targetObj}
+	statusCollection := fedv1b1.StatusCollectionEnabled
+	s := &KubeFedSyncController{
+		informer:          informer,
+		fedAccessor:       &fakeAccessor{fedResource: fedResource},
+		hostClusterClient: hostClient,
+		typeConfig: &fedv1b1.FederatedTypeConfig{
+			Spec: fedv1b1.FederatedTypeConfigSpec{
+				StatusCollection: &statusCollection,
+			},
+		},
+		cacheSyncTimeout:            time.Second,
+		unreachableClusters:         utils.NewSafeMap(),
+		limitedScope:                true,
+		rawResourceStatusCollection: true,
+		ctx:                         context.Background(),
+	}
+	reconcile := func() map[string]status.GenericClusterStatus {
+		t.Helper()
+		if _, err := s.ReconcileOnce(context.Background(), fedObject); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		fedStatus, err := status.DecodeGenericFederatedResource(hostClient.objs[utils.NewQualifiedName(fedObject).String()])
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if fedStatus.Status == nil {
+			t.Fatalf("Expected the status to be written")
+		}
+		clusterStatuses := make(map[string]status.GenericClusterStatus)
+		for _, clusterStatus := range fedStatus.Status.Clusters {
+			clusterStatuses[clusterStatus.Name] = clusterStatus
+		}
+		return clusterStatuses
+	}
+	expectRemoteStatus := func(clusterStatuses map[string]status.GenericClusterStatus, clusterNames ...string) {
+		t.Helper()
+		for _, clusterName := range clusterNames {
+			if clusterStatuses[clusterName].RemoteStatus == nil {
+				t.Fatalf("Expected the remote status of %q to be collected, got %v", clusterName, clusterStatuses)
+			}
+		}
+	}
+
+	reconcile()
+	key := utils.NewQualifiedName(targetObj).String()
+	for _, client := range informer.clients {
+		clusterObj := client.objs[key].DeepCopy()
+		clusterObj.Object["status"] = map[string]interface{}{"phase": "Ready"}
+		if err := client.UpdateStatus(context.Background(), clusterObj); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	clusterStatuses := reconcile()
+	expectRemoteStatus(clusterStatuses, "cluster1", "cluster2", "cluster3")
+
+	// The resource is removed from a cluster that is no longer
+	// selected, and its status is reported as waiting for removal.
+	fedResource.selectedClusterNames = []string{"cluster1", "cluster2"}
+	clusterStatuses = reconcile()
+	if _, ok := informer.clients["cluster3"].objs[key]; ok {
+		t.Fatalf("Expected the ConfigMap to be removed from %q", "cluster3")
+	}
+	if clusterStatus := clusterStatuses["cluster3"]; clusterStatus.Status != status.WaitingForRemoval {
+		t.Fatalf("Expected %q to be waiting for removal, got %v", "cluster3", clusterStatus)
+	}
+
+	// Once the resource is gone, the status of the cluster is removed
+	// while that of the others remains.
+	clusterStatuses = reconcile()
+	if clusterStatus, ok := clusterStatuses["cluster3"]; ok {
+		t.Fatalf("Expected the status of %q to be removed, got %v", "cluster3", clusterStatus)
+	}
+	if len(clusterStatuses) != 2 {
+		t.Fatalf("Expected the status of 2 clusters, got %v", clusterStatuses)
+	}
+	expectRemoteStatus(clusterStatuses, "cluster1", "cluster2")
+}
+
+func TestReconcileOnceCleansUpRemovedCluster(t *testing.T) {
+	for _, prune := range []bool{false, true} {
+		t.Run(fmt.Sprintf("prune=%v", prune), func(t *testing.T) {
+			// The resource is placed in cluster1 and in cluster2,
+			// whose KubeFedCluster was deleted after the resource was
+			// propagated to it.
+			fedObject, targetObj := newFakeObjects("v1", "ConfigMap")
+			if err := utils.SetClusterNames(fedObject, []string{"cluster1", "cluster2"}); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			fedObject.Object["status"] = map[string]interface{}{
+				"clusters": []interface{}{
+					map[string]interface{}{"name": "cluster1"},
+					map[string]interface{}{"name": "cluster2"},
+				},
+			}
+
+			hostClient := newHostClient(t, fedObject)
+			informer := &fakeInformer{
+				clusters: []*fedv1b1.KubeFedCluster{{
+					ObjectMeta: metav1.ObjectMeta{Name: "cluster1"},
+					Status: fedv1b1.KubeFedClusterStatus{
+						Conditions: []fedv1b1.ClusterCondition{{Type: common.ClusterReady, Status: corev1.ConditionTrue}},
+					},
+				}},
+				clients: map[string]*memoryClient{"cluster1": newMemoryClient()},
+			}
+			removedClusters := utils.NewSafeMap()
+			removedClusters.Store("cluster2", "cluster2")
+			// Versions of removed clusters are pruned even while
+			// propagation is paused.
+			pause := &utils.PropagationPause{}
+			pause.Set(true)
+			fedResource := &fakeFederatedResource{
+				fedObject:  fedObject,
+				targetObj:  targetObj,
+				versionMap: map[string]string{"cluster1": "1", "cluster2": "2"},
+			}
+			s := &KubeFedSyncController{
+				informer:                informer,
+				fedAccessor:             &fakeAccessor{fedResource: fedResource},
+				hostClusterClient:       hostClient,
+				typeConfig:              &fedv1b1.FederatedTypeConfig{},
+				cacheSyncTimeout:        time.Second,
+				unreachableClusters:     utils.NewSafeMap(),
+				removedClusters:         removedClusters,
+				reportedRemovedClusters: utils.NewSafeMap(),
+				pruneRemovedClusters:    prune,
+				limitedScope:            true,
+				propagationPause:        pause,
+				ctx:                     context.Background(),
+				tracer:                  noop.NewTracerProvider().Tracer(""),
+			}
+
+			result, err := s.ReconcileOnce(context.Background(), fedObject)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			// The removed clusters listed in placement are only
+			// reported once.
+			if _, err := s.ReconcileOnce(context.Background(), fedObject); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			expectedReasons := []string{"RemovedClustersInPlacement"}
+			if prune {
+				expectedReasons = []string{"PrunedRemovedClusters"}
+			}
+			if !reflect.DeepEqual(fedResource.eventReasons, expectedReasons) {
+				t.Fatalf("Expected events %v, got %v", expectedReasons, fedResource.eventReasons)
+			}
+			if _, ok := result.PropagationStatus.StatusMap["cluster2"]; ok {
+				t.Fatalf("Expected no status to be collected for the removed cluster, got %v", result.PropagationStatus.StatusMap)
+			}
+			if expected := map[string]string{"cluster1": "1"}; !reflect.DeepEqual(fedResource.versionMap, expected) {
+				t.Fatalf("Expected versions %v, got %v", expected, fedResource.versionMap)
+			}
+			storedFedObject := hostClient.objs[utils.NewQualifiedName(fedObject).String()]
+			clusters, _, _ := unstructured.NestedSlice(storedFedObject.Object, "status", "clusters")
+			for _, cluster := range clusters {
+				if cluster.(map[string]interface{})["name"] == "cluster2" {
+					t.Fatalf("Expected the removed cluster to be removed from status, got %v", clusters)
+				}
+			}
+			clusterNames, err := utils.GetClusterNames(storedFedObject)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			expectedClusterNames := []string{"cluster1", "cluster2"}
+			if prune {
+				expectedClusterNames = []string{"cluster1"}
+			}
+			if !reflect.DeepEqual(clusterNames, expectedClusterNames) {
+				t.Fatalf("Expected placement of clusters %v, got %v", expectedClusterNames, clusterNames)
+			}
+		})
+	}
+}
+
+func TestReconcileOnceDoesNotPruneConcurrentlyChangedPlacement(t *testing.T) {
+	fedObject, targetObj := newFakeObjects("v1", "ConfigMap")
+	fedObject.SetFinalizers([]string{FinalizerSyncController})
+	if err := utils.SetClusterNames(fedObject, []string{"cluster1", "cluster2"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	hostClient := newHostClient(t, fedObject)
+	// The placement is changed after the cached resource was read.
+	changed := fedObject.DeepCopy()
+	if err := utils.SetClusterNames(changed, []string{"cluster1", "cluster2", "cluster3"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := hostClient.Update(context.Background(), changed); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	removedClusters := utils.NewSafeMap()
+	removedClusters.Store("cluster2", "cluster2")
+	fedResource := &fakeFederatedResource{fedObject: fedObject, targetObj: targetObj}
+	s := &KubeFedSyncController{
+		informer:                &fakeInformer{},
+		fedAccessor:             &fakeAccessor{fedResource: fedResource},
+		hostClusterClient:       hostClient,
+		typeConfig:              &fedv1b1.FederatedTypeConfig{},
+		cacheSyncTimeout:        time.Second,
+		unreachableClusters:     utils.NewSafeMap(),
+		removedClusters:         removedClusters,
+		reportedRemovedClusters: utils.NewSafeMap(),
+		pruneRemovedClusters:    true,
+		limitedScope:            true,
+		propagationPause:        &utils.PropagationPause{},
+		ctx:                     context.Background(),
+		tracer:                  noop.NewTracerProvider().Tracer(""),
+	}
+
+	result, err := s.ReconcileOnce(context.Background(), fedObject)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Status != utils.StatusError {
+		t.Fatalf("Expected the reconcile to be retried, got status %v", result.Status)
+	}
+	clusterNames, err := utils.GetClusterNames(hostClient.objs[utils.NewQualifiedName(fedObject).String()])
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := []string{"cluster1", "cluster2", "cluster3"}; !reflect.DeepEqual(clusterNames, expected) {
+		t.Fatalf("Expected the concurrently changed placement %v to be retained, got %v", expected, clusterNames)
+	}
+}
+
+func TestReconcileOnceDeletesExpiredResource(t *testing.T) {
+	testCases := map[string]struct {
+		age             time.Duration
+		ttlStart        string
+		expectedDeleted bool
+	}{
+		"resource is propagated before its TTL elapses": {
+			age: 30 * time.Minute,
+		},
+		"resource is deleted once its TTL elapses": {
+			age:             2 * time.Hour,
+			expectedDeleted: true,
+		},
+		"TTL elapses from the specified start": {
+			age:      2 * time.Hour,
+			ttlStart: time.Now().Add(-30 * time.Minute).UTC().Format(time.RFC3339),
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			fedObject, targetObj := newFakeObjects("v1", "ConfigMap")
+			fedObject.SetCreationTimestamp(metav1.NewTime(time.Now().Add(-tc.age)))
+			annotations := map[string]string{utils.TTLAnnotation: "1h"}
+			if len(tc.ttlStart) > 0 {
+				annotations[utils.TTLStartAnnotation] = tc.ttlStart
+			}
+			fedObject.SetAnnotations(annotations)
+
+			hostClient := newHostClient(t, fedObject)
+			informer := &fakeInformer{clients: make(map[string]*memoryClient)}
+			informer.clusters = append(informer.clusters, &fedv1b1.KubeFedCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster1"},
+				Status: fedv1b1.KubeFedClusterStatus{
+					Conditions: []fedv1b1.ClusterCondition{{Type: common.ClusterReady, Status: corev1.ConditionTrue}},
+				},
+			})
+			informer.clients["cluster1"] = newMemoryClient()
+			worker := &recordingWorker{delays: make(map[utils.QualifiedName]time.Duration)}
+			s := &KubeFedSyncController{
+				worker:              worker,
+				informer:            informer,
+				fedAccessor:         &fakeAccessor{fedResource: &fakeFederatedResource{fedObject: fedObject, targetObj: targetObj}},
+				hostClusterClient:   hostClient,
+				typeConfig:          &fedv1b1.FederatedTypeConfig{},
+				cacheSyncTimeout:    time.Second,
+				unreachableClusters: utils.NewSafeMap(),
+				limitedScope:        true,
+				ctx:                 context.Background(),
+				tracer:              noop.NewTracerProvider().Tracer(""),
+			}
+
+			result, err := s.ReconcileOnce(context.Background(), fedObject)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result.Status != utils.StatusAllOK {
+				t.Fatalf("Expected reconciliation to succeed, got %v", result.Status)
+			}
+
+			fedName := utils.NewQualifiedName(fedObject)
+			_, stored := hostClient.objs[fedName.String()]
+			_, propagated := informer.clients["cluster1"].objs[utils.NewQualifiedName(targetObj).String()]
+			if tc.expectedDeleted {
+				if stored {
+					t.Fatalf("Expected the federated resource to be deleted")
+				}
+				if propagated {
+					t.Fatalf("Expected the expired resource not to be propagated")
+				}
+				return
+			}
+			if !stored || !propagated {
+				t.Fatalf("Expected the federated resource to be retained and propagated")
+			}
+			// Reconciliation is scheduled for when the TTL elapses.
+			delay, ok := worker.delays[fedName]
+			if !ok || delay <= 0 || delay > 30*time.Minute {
+				t.Fatalf("Expected %q to be enqueued for when its TTL elapses, got %v", fedName, worker.delays)
+			}
+		})
+	}
+}
+
+func TestReconcileOnceReleasesManagedResources(t *testing.T) {
+	fedObject, targetObj := newFakeObjects("v1", "ConfigMap")
+	fedObject.SetFinalizers([]string{FinalizerSyncController})
+	fedObject.SetAnnotations(map[string]string{utils.ReleaseAnnotation: utils.ReleasedValue})
+
+	hostClient := newHostClient(t, fedObject)
+	informer := &fakeInformer{clients: make(map[string]*memoryClient)}
+	informer.clusters = append(informer.clusters, &fedv1b1.KubeFedCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster1"},
+		Status: fedv1b1.KubeFedClusterStatus{
+			Conditions: []fedv1b1.ClusterCondition{{Type: common.ClusterReady, Status: corev1.ConditionTrue}},
+		},
+	})
+	informer.clients["cluster1"] = newMemoryClient()
+	clusterObj := targetObj.DeepCopy()
+	utils.AddManagedLabel(clusterObj)
+	clusterObj.Object["data"] = map[string]interface{}{"key": "value"}
+	// The annotations recorded by the sync controller while the
+	// resource was managed are removed, while those of others are
+	// retained.
+	clusterObj.SetAnnotations(map[string]string{
+		utils.FederatedNameAnnotation:          "bar",
+		utils.AppliedOverridePathsAnnotation:   "/data/key",
+		utils.DeclaredMetadataAnnotation:       `{"labels":["app"]}`,
+		utils.DrainStartedAnnotation:           "2024-01-01T00:00:00Z",
+		utils.DeletionPendingAnnotation:        "2024-01-01T00:00:00Z",
+		utils.DeletionPendingOptionsAnnotation: `{"propagationPolicy":"Orphan"}`,
+		"example.com/note":                     "kept",
+	})
+	if err := informer.clients["cluster1"].Create(context.Background(), clusterObj); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	fedResource := &fakeFederatedResource{fedObject: fedObject, targetObj: targetObj, versionMap: map[string]string{"cluster1": "rv:1"}}
+	s := &KubeFedSyncController{
+		informer:            informer,
+		fedAccessor:         &fakeAccessor{fedResource: fedResource},
+		hostClusterClient:   hostClient,
+		typeConfig:          &fedv1b1.FederatedTypeConfig{},
+		cacheSyncTimeout:    time.Second,
+		unreachableClusters: utils.NewSafeMap(),
+		limitedScope:        true,
+		ctx:                 context.Background(),
+		tracer:              noop.NewTracerProvider().Tracer(""),
+	}
+
+	// Reconciling a released resource again leaves its resources
+	// untouched.
+	for i := 0; i < 2; i++ {
+		result, err := s.ReconcileOnce(context.Background(), fedObject)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if result.Status != utils.StatusAllOK {
+			t.Fatalf("Expected reconciliation to succeed, got %v", result.Status)
+		}
+	}
+
+	releasedObj, ok := informer.clients["cluster1"].objs[utils.NewQualifiedName(targetObj).String()]
+	if !ok {
+		t.Fatalf("Expected the released resource to be retained")
+	}
+	if utils.HasManagedLabel(releasedObj) {
+		t.Fatalf("Expected the managed label to be removed from the released resource")
+	}
+	if expected := map[string]string{"example.com/note": "kept"}; !reflect.DeepEqual(expected, releasedObj.GetAnnotations()) {
+		t.Fatalf("Expected the annotations of the released resource to be %v, got %v", expected, releasedObj.GetAnnotations())
+	}
+	if value, _, _ := unstructured.NestedString(releasedObj.Object, "data", "key"); value != "value" {
+		t.Fatalf("Expected the content of the released resource to be unchanged")
+	}
+	if releasedObj.GetResourceVersion() != "2" {
+		t.Fatalf("Expected the released resource to be updated once, got resource version %q", releasedObj.GetResourceVersion())
+	}
+	storedFedObject := hostClient.objs[utils.NewQualifiedName(fedObject).String()]
+	if len(storedFedObject.GetFinalizers()) > 0 {
+		t.Fatalf("Expected the finalizer to be removed from the released federated resource")
+	}
+	if fedResource.versionMap != nil {
+		t.Fatalf("Expected the recorded versions of the released resource to be deleted")
+	}
+}
+
+func TestReconcileOnceDrainsDeselectedCluster(t *testing.T) {
+	fedObject, targetObj := newFakeObjects("v1", "ConfigMap")
+
+	hostClient := newHostClient(t, fedObject)
+	informer := newFakeInformer("cluster1", "cluster2")
+	worker := &recordingWorker{delays: make(map[utils.QualifiedName]time.Duration)}
+	fedResource := &fakeFederatedResource{
+		fedObject: fedObject,
+		targetObj: targetObj,
+		removalStrategy: &fedv1b1.RemovalStrategy{
+			PreDeletePatch: &apiextv1.JSON{Raw: []byte(`{"data":{"draining":"true"}}`)},
+			DrainDuration:  metav1.Duration{Duration: time.Hour},
+		},
+	}
+	s := &KubeFedSyncController{
+		worker:              worker,
+		informer:            informer,
+		fedAccessor:         &fakeAccessor{fedResource: fedResource},
+		hostClusterClient:   hostClient,
+		typeConfig:          &fedv1b1.FederatedTypeConfig{},
+		cacheSyncTimeout:    time.Second,
+		unreachableClusters: utils.NewSafeMap(),
+		limitedScope:        true,
+		ctx:                 context.Background(),
+	}
+	fedName := utils.NewQualifiedName(fedObject)
+	key := utils.NewQualifiedName(targetObj).String()
+	reconcile := func(expectedStatus utils.ReconciliationStatus, expectedClusterStatus status.PropagationStatus) {
+		t.Helper()
+		result, err := s.ReconcileOnce(context.Background(), fedObject)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if result.Status != expectedStatus {
+			t.Fatalf("Expected reconciliation status %v, got %v", expectedStatus, result.Status)
+		}
+		if clusterStatus := result.PropagationStatus.StatusMap["cluster2"]; clusterStatus != expectedClusterStatus {
+			t.Fatalf("Expected status %q for %q, got %q", expectedClusterStatus, "cluster2", clusterStatus)
+		}
+	}
+	drainStarted := func() (time.Time, bool) {
+		t.Helper()
+		clusterObj, ok := informer.clients["cluster2"].objs[key]
+		if !ok {
+			t.Fatalf("Expected the ConfigMap to be retained in %q while draining", "cluster2")
+		}
+		value, ok := clusterObj.GetAnnotations()[utils.DrainStartedAnnotation]
+		if !ok {
+			return time.Time{}, false
+		}
+		startedAt, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return startedAt, true
+	}
+	draining := func() bool {
+		value, _, _ := unstructured.NestedString(informer.clients["cluster2"].objs[key].Object, "data", "draining")
+		return value == "true"
+	}
+
+	reconcile(utils.StatusAllOK, status.ClusterPropagationOK)
+
+	// The resource in a deselected cluster is patched and retained
+	// until the drain duration elapses.
+	fedResource.selectedClusterNames = []string{"cluster1"}
+	reconcile(utils.StatusAllOK, status.Draining)
+	if _, ok := drainStarted(); !ok || !draining() {
+		t.Fatalf("Expected the pre-delete patch to be applied in %q", "cluster2")
+	}
+	if delay := worker.delays[fedName]; delay <= 59*time.Minute || delay > time.Hour {
+		t.Fatalf("Expected %q to be enqueued for when the drain duration elapses, got %v", fedName, delay)
+	}
+
+	// A cache that has not yet observed the patch does not restart
+	// draining.
+	startedAt := time.Now().Add(-10 * time.Minute).Truncate(time.Second)
+	clusterObj := informer.clients["cluster2"].objs[key]
+	clusterObj.SetAnnotations(map[string]string{utils.DrainStartedAnnotation: startedAt.Format(time.RFC3339)})
+	staleObj := clusterObj.DeepCopy()
+	utils.RemoveDrainStarted(staleObj)
+	staleObj.SetResourceVersion("1")
+	informer.staleObjs = map[string]map[string]*unstructured.Unstructured{"cluster2": {key: staleObj}}
+	reconcile(utils.StatusError, status.DrainFailed)
+	informer.staleObjs = nil
+	if restartedAt, _ := drainStarted(); !restartedAt.Equal(startedAt) {
+		t.Fatalf("Expected draining to have started at %v, got %v", startedAt, restartedAt)
+	}
+
+	// Selecting the cluster again abandons draining and updates the
+	// resource from the template.
+	fedResource.selectedClusterNames = nil
+	reconcile(utils.StatusAllOK, status.ClusterPropagationOK)
+	if _, ok := drainStarted(); ok || draining() {
+		t.Fatalf("Expected draining to be abandoned in %q", "cluster2")
+	}
+
+	// The resource is deleted once the drain duration elapsed.
+	fedResource.selectedClusterNames = []string{"cluster1"}
+	reconcile(utils.StatusAllOK, status.Draining)
+	clusterObj = informer.clients["cluster2"].objs[key]
+	clusterObj.SetAnnotations(map[string]string{
+		utils.DrainStartedAnnotation: time.Now().Add(-time.Hour).Format(time.RFC3339),
+	})
+	reconcile(utils.StatusAllOK, status.WaitingForRemoval)
+	if _, ok := informer.clients["cluster2"].objs[key]; ok {
+		t.Fatalf("Expected the ConfigMap to be deleted from %q once drained", "cluster2")
+	}
+}
+
+func TestReconcileOnceDeletesEmptyNamespaces(t *testing.T) {
+	fedObject, targetObj := newFakeObjects("v1", "ConfigMap")
+
+	hostClient := newHostClient(t, fedObject)
+	// The namespace was created by KubeFed in all clusters but
+	// cluster3 and contains a resource of a user in cluster2. It is
+	// only expected to be deleted in cluster1.
+	informer := &fakeInformer{clients: make(map[string]*memoryClient)}
+	for _, clusterName := range []string{"cluster1", "cluster2", "cluster3"} {
+		informer.clusters = append(informer.clusters, &fedv1b1.KubeFedCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: clusterName},
+			Status: fedv1b1.KubeFedClusterStatus{
+				Conditions: []fedv1b1.ClusterCondition{{Type: common.ClusterReady, Status: corev1.ConditionTrue}},
+			},
+		})
+		client := newMemoryClient()
+		informer.clients[clusterName] = client
+		namespace := &unstructured.Unstructured{}
+		namespace.SetAPIVersion("v1")
+		namespace.SetKind(utils.NamespaceKind)
+		namespace.SetName("foo")
+		if clusterName != "cluster3" {
+			utils.MarkCreatedNamespace(namespace)
+		}
+		if err := client.Create(context.Background(), namespace); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	userObj := targetObj.DeepCopy()
+	userObj.SetName("user")
+	if err := informer.clients["cluster2"].Create(context.Background(), userObj); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	fedResource := &fakeFederatedResource{fedObject: fedObject, targetObj: targetObj}
+	s := &KubeFedSyncController{
+		worker:                &recordingWorker{delays: make(map[utils.QualifiedName]time.Duration)},
+		informer:              informer,
+		fedAccessor:           &fakeAccessor{fedResource: fedResource},
+		hostClusterClient:     hostClient,
+		typeConfig:            &fedv1b1.FederatedTypeConfig{},
+		cacheSyncTimeout:      time.Second,
+		unreachableClusters:   utils.NewSafeMap(),
+		limitedScope:          true,
+		ctx:                   context.Background(),
+		deleteEmptyNamespaces: true,
+		namespacedResourceTypes: func(string) ([]metav1.APIResource, error) {
+			return []metav1.APIResource{{Version: "v1", Kind: "ConfigMap", Namespaced: true}}, nil
+		},
+	}
+	reconcile := func() {
+		t.Helper()
+		result, err := s.ReconcileOnce(context.Background(), fedObject)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if result.Status != utils.StatusAllOK {
+			t.Fatalf("Expected reconciliation to succeed, got %v", result.Status)
+		}
+	}
+
+	reconcile()
+	fedResource.selectedClusterNames = []string{}
+	reconcile()
+
+	key := utils.NewQualifiedName(targetObj).String()
+	for clusterName, client := range informer.clients {
+		if _, ok := client.objs[key]; ok {
+			t.Fatalf("Expected the ConfigMap to be removed from %q", clusterName)
+		}
+		_, retained := client.objs["foo"]
+		if expected := clusterName != "cluster1"; retained != expected {
+			t.Errorf("Expected the namespace to be retained in %q: %v", clusterName, expected)
+		}
+	}
+	if !slices.Contains(fedResource.eventReasons, "DeleteEmptyNamespace") {
+		t.Fatalf("Expected the deletion of the namespace to be recorded, got %v", fedResource.eventReasons)
+	}
+}
+
+func TestReconcileOnceStampsCreatedNamespaces(t *testing.T) {
+	fedObject := &unstructured.Unstructured{}
+	fedObject.SetAPIVersion("types.kubefed.io/v1beta1")
+	fedObject.SetKind("FederatedNamespace")
+	fedObject.SetNamespace("foo")
+	fedObject.SetName("foo")
+	// The declared label takes precedence over the stamped label with
+	// the same key.
+	targetObj := newNamespace("foo", map[string]string{"example.com/owner": "app-team"})
+
+	hostClient := newHostClient(t, fedObject)
+	// The namespace is created in cluster1 and adopted in cluster2.
+	informer := newFakeInformer("cluster1", "cluster2")
+	if err := informer.clients["cluster2"].Create(context.Background(), newNamespace("foo", nil)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	fedResource := &fakeFederatedResource{fedObject: fedObject, targetObj: targetObj}
+	s := &KubeFedSyncController{
+		informer:                    informer,
+		fedAccessor:                 &fakeAccessor{fedResource: fedResource},
+		hostClusterClient:           hostClient,
+		typeConfig:                  &fedv1b1.FederatedTypeConfig{},
+		cacheSyncTimeout:            time.Second,
+		unreachableClusters:         utils.NewSafeMap(),
+		limitedScope:                true,
+		ctx:                         context.Background(),
+		createdNamespaceLabels:      map[string]string{"example.com/provisioned-by": "kubefed", "example.com/owner": "platform-team"},
+		createdNamespaceAnnotations: map[string]string{"example.com/contact": "platform-team"},
+	}
+	reconcile := func() {
+		t.Helper()
+		result, err := s.ReconcileOnce(context.Background(), fedObject)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if result.Status != utils.StatusAllOK {
+			t.Fatalf("Expected reconciliation to succeed, got %v", result.Status)
+		}
+	}
+	expectMetadata := func(clusterName string, expectedLabels map[string]string, expectedContact string) {
+		t.Helper()
+		namespace, ok := informer.clients[clusterName].objs["foo"]
+		if !ok {
+			t.Fatalf("Expected the namespace to exist in %q", clusterName)
+		}
+		labels := namespace.GetLabels()
+		delete(labels, utils.ManagedByKubeFedLabelKey)
+		if !reflect.DeepEqual(expectedLabels, labels) {
+			t.Fatalf("Expected labels %v in %q, got %v", expectedLabels, clusterName, labels)
+		}
+		if contact := namespace.GetAnnotations()["example.com/contact"]; contact != expectedContact {
+			t.Fatalf("Expected the contact annotation %q in %q, got %q", expectedContact, clusterName, contact)
+		}
+	}
+
+	reconcile()
+	createdLabels := map[string]string{"example.com/provisioned-by": "kubefed", "example.com/owner": "app-team"}
+	expectMetadata("cluster1", createdLabels, "platform-team")
+	expectMetadata("cluster2", map[string]string{"example.com/owner": "app-team"}, "")
+	if !utils.IsCreatedNamespace(informer.clients["cluster1"].objs["foo"]) || utils.IsCreatedNamespace(informer.clients["cluster2"].objs["foo"]) {
+		t.Fatalf("Expected only the namespace in cluster1 to be marked as created")
+	}
+	if !slices.Contains(fedResource.eventReasons, "CreatedNamespaceMetadataConflict") {
+		t.Fatalf("Expected the conflicting label to be reported, got %v", fedResource.eventReasons)
+	}
+
+	// The stamp is kept when the created namespace is updated.
+	// The fake does not version the template, so the recorded versions
+	// are discarded for the changed template to be propagated.
+	targetObj.SetLabels(map[string]string{"example.com/owner": "app-team", "example.com/tier": "gold"})
+	fedResource.versionMap = nil
+	reconcile()
+	createdLabels["example.com/tier"] = "gold"
+	expectMetadata("cluster1", createdLabels, "platform-team")
+	expectMetadata("cluster2", map[string]string{"example.com/owner": "app-team", "example.com/tier": "gold"}, "")
+}
+
+func TestReconcileOnceRetainsResourcesForDeletionGracePeriod(t *testing.T) {
+	deletionTimestamp := metav1.NewTime(time.Now().Truncate(time.Second))
+	fedObject, targetObj := newFakeObjects("v1", "ConfigMap")
+	fedObject.SetFinalizers([]string{FinalizerSyncController})
+	fedObject.SetDeletionTimestamp(&deletionTimestamp)
+	fedObject.SetAnnotations(map[string]string{
+		utils.DeletionGracePeriodAnnotation: "1h",
+		utils.DeleteOptionAnnotation:        `{"propagationPolicy":"Foreground"}`,
+	})
+
+	hostClient := newHostClient(t, fedObject)
+	informer := &fakeInformer{clients: make(map[string]*memoryClient)}
+	informer.clusters = append(informer.clusters, &fedv1b1.KubeFedCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster1"},
+		Status: fedv1b1.KubeFedClusterStatus{
+			Conditions: []fedv1b1.ClusterCondition{{Type: common.ClusterReady, Status: corev1.ConditionTrue}},
+		},
+	})
+	informer.clients["cluster1"] = newMemoryClient()
+	clusterObj := targetObj.DeepCopy()
+	utils.AddManagedLabel(clusterObj)
+	if err := informer.clients["cluster1"].Create(context.Background(), clusterObj); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	worker := &recordingWorker{delays: make(map[utils.QualifiedName]time.Duration)}
+	s := &KubeFedSyncController{
+		worker:              worker,
+		informer:            informer,
+		fedAccessor:         &fakeAccessor{fedResource: &fakeFederatedResource{fedObject: fedObject, targetObj: targetObj}},
+		hostClusterClient:   hostClient,
+		typeConfig:          &fedv1b1.FederatedTypeConfig{},
+		cacheSyncTimeout:    time.Second,
+		unreachableClusters: utils.NewSafeMap(),
+		limitedScope:        true,
+		ctx:                 context.Background(),
+		tracer:              noop.NewTracerProvider().Tracer(""),
+	}
+	fedName := utils.NewQualifiedName(fedObject)
+	targetKey := utils.NewQualifiedName(targetObj).String()
+	reconcile := func() {
+		t.Helper()
+		result, err := s.ReconcileOnce(context.Background(), fedObject)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if result.Status != utils.StatusAllOK {
+			t.Fatalf("Expected reconciliation to succeed, got %v", result.Status)
+		}
+	}
+
+	// Deletion of the federated resource marks its managed resources
+	// as pending deletion and removes its finalizer.
+	reconcile()
+	retainedObj, ok := informer.clients["cluster1"].objs[targetKey]
+	if !ok {
+		t.Fatalf("Expected the managed resource to be retained within the grace period")
+	}
+	until, pending := utils.DeletionPendingUntil(retainedObj)
+	if expected := deletionTimestamp.Add(time.Hour); !pending || !until.Equal(expected) {
+		t.Fatalf("Expected the managed resource to be pending deletion until %v, got %v", expected, retainedObj.GetAnnotations())
+	}
+	if _, ok := retainedObj.GetAnnotations()[utils.DeletionPendingOptionsAnnotation]; !ok {
+		t.Fatalf("Expected the delete options to be recorded on the managed resource, got %v", retainedObj.GetAnnotations())
+	}
+	if len(hostClient.objs[fedName.String()].GetFinalizers()) > 0 {
+		t.Fatalf("Expected the finalizer to be removed from the federated resource")
+	}
+	if delay := worker.delays[fedName]; delay <= 59*time.Minute || delay > time.Hour {
+		t.Fatalf("Expected %q to be enqueued for when the grace period elapses, got %v", fedName, delay)
+	}
+
+	// Once the federated resource is removed, the resource pending
+	// deletion is retained until the grace period elapses.
+	s.fedAccessor = &fakeAccessor{possibleOrphan: true}
+	delete(worker.delays, fedName)
+	reconcile()
+	retainedObj, ok = informer.clients["cluster1"].objs[targetKey]
+	if !ok || !utils.HasManagedLabel(retainedObj) {
+		t.Fatalf("Expected the resource pending deletion to be retained as managed")
+	}
+	if delay := worker.delays[fedName]; delay <= 59*time.Minute || delay > time.Hour {
+		t.Fatalf("Expected %q to be enqueued for when the grace period elapses, got %v", fedName, delay)
+	}
+
+	utils.SetDeletionPending(retainedObj, time.Now().Add(-time.Second), retainedObj.GetAnnotations()[utils.DeletionPendingOptionsAnnotation])
+	reconcile()
+	if _, ok := informer.clients["cluster1"].objs[targetKey]; ok {
+		t.Fatalf("Expected the managed resource to be deleted once the grace period elapsed")
+	}
+	// The resource is deleted with the delete options of the deleted
+	// federated resource, and only as observed.
+	deleteOptions := informer.clients["cluster1"].deleteOptions[targetKey]
+	if policy := deleteOptions.PropagationPolicy; policy == nil || *policy != metav1.DeletePropagationForeground {
+		t.Fatalf("Expected the managed resource to be deleted with the foreground propagation policy, got %v", policy)
+	}
+	if deleteOptions.Preconditions == nil || deleteOptions.Preconditions.ResourceVersion == nil {
+		t.Fatalf("Expected the managed resource to be deleted with a resource version precondition")
+	}
+}
+
+func TestReconcileOnceEnsuresDeletion(t *testing.T) {
+	testCases := map[string]struct {
+		orphanDependents bool
+	}{
+		"managed resources deleted": {},
+		"managed resources orphaned": {
+			orphanDependents: true,
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			deletionTimestamp := metav1.Now()
+			fedObject, targetObj := newFakeObjects("v1", "ConfigMap")
+			fedObject.SetFinalizers([]string{FinalizerSyncController})
+			fedObject.SetDeletionTimestamp(&deletionTimestamp)
+			if tc.orphanDependents {
+				utils.EnableOrphaning(fedObject)
+			}
+
+			hostClient := newHostClient(t, fedObject)
+			informer := newFakeInformer("cluster1", "cluster2")
+			key := utils.NewQualifiedName(targetObj).String()
+			for _, client := range informer.clients {
+				clusterObj := targetObj.DeepCopy()
+				utils.AddManagedLabel(clusterObj)
+				if err := client.Create(context.Background(), clusterObj); err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+			}
+			fedResource := &fakeFederatedResource{fedObject: fedObject, targetObj: targetObj, versionMap: map[string]string{"cluster1": "rv:1", "cluster2": "rv:1"}}
+			s := &KubeFedSyncController{
+				informer:            informer,
+				fedAccessor:         &fakeAccessor{fedResource: fedResource},
+				hostClusterClient:   hostClient,
+				typeConfig:          &fedv1b1.FederatedTypeConfig{},
+				cacheSyncTimeout:    time.Second,
+				unreachableClusters: utils.NewSafeMap(),
+				limitedScope:        true,
+				ctx:                 context.Background(),
+			}
+
+			// The finalizer is only removed once the managed
+			// resources are gone, which may take another
+			// reconciliation.
+			for i := 0; i < 3 && len(hostClient.objs[utils.NewQualifiedName(fedObject).String()].GetFinalizers()) > 0; i++ {
+				if _, err := s.ReconcileOnce(context.Background(), fedObject); err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+			}
+			if len(hostClient.objs[utils.NewQualifiedName(fedObject).String()].GetFinalizers()) > 0 {
+				t.Fatalf("Expected the finalizer to be removed from the deleted federated resource")
+			}
+			if fedResource.versionMap != nil {
+				t.Fatalf("Expected the recorded versions of the deleted federated resource to be deleted, got %v", fedResource.versionMap)
+			}
+			for clusterName, client := range informer.clients {
+				clusterObj, ok := client.objs[key]
+				if ok != tc.orphanDependents {
+					t.Fatalf("Expected the managed resource in %q to be retained: %v", clusterName, tc.orphanDependents)
+				}
+				if ok && utils.HasManagedLabel(clusterObj) {
+					t.Fatalf("Expected the managed label to be removed from the orphaned resource in %q", clusterName)
+				}
+			}
+		})
+	}
+}
+
+func TestReconcileOnceDefersDeletionInClusterInMaintenance(t *testing.T) {
+	deletionTimestamp := metav1.NewTime(time.Now().Truncate(time.Second))
+	fedObject, targetObj := newFakeObjects("v1", "ConfigMap")
+	fedObject.SetFinalizers([]string{FinalizerSyncController})
+	fedObject.SetDeletionTimestamp(&deletionTimestamp)
+
+	hostClient := newHostClient(t, fedObject)
+	cluster := &fedv1b1.KubeFedCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "cluster1",
+			Annotations: map[string]string{utils.MaintenanceAnnotation: utils.MaintenanceValue},
+		},
+		Status: fedv1b1.KubeFedClusterStatus{
+			Conditions: []fedv1b1.ClusterCondition{{Type: common.ClusterReady, Status: corev1.ConditionTrue}},
+		},
+	}
+	informer := &fakeInformer{clients: make(map[string]*memoryClient)}
+	informer.clusters = append(informer.clusters, cluster)
+	informer.clients["cluster1"] = newMemoryClient()
+	clusterObj := targetObj.DeepCopy()
+	utils.AddManagedLabel(clusterObj)
+	if err := informer.clients["cluster1"].Create(context.Background(), clusterObj); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	s := &KubeFedSyncController{
+		informer:            informer,
+		fedAccessor:         &fakeAccessor{fedResource: &fakeFederatedResource{fedObject: fedObject, targetObj: targetObj}},
+		hostClusterClient:   hostClient,
+		typeConfig:          &fedv1b1.FederatedTypeConfig{},
+		cacheSyncTimeout:    time.Second,
+		unreachableClusters: utils.NewSafeMap(),
+		limitedScope:        true,
+		ctx:                 context.Background(),
+		tracer:              noop.NewTracerProvider().Tracer(""),
+	}
+	fedKey := utils.NewQualifiedName(fedObject).String()
+	targetKey := utils.NewQualifiedName(targetObj).String()
+
+	result, err := s.ReconcileOnce(context.Background(), fedObject)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Status != utils.StatusError {
+		t.Fatalf("Expected deletion to be deferred, got %v", result.Status)
+	}
+	if _, ok := informer.clients["cluster1"].objs[targetKey]; !ok {
+		t.Fatalf("Expected the managed resource in the cluster in maintenance to be retained")
+	}
+	if len(hostClient.objs[fedKey].GetFinalizers()) == 0 {
+		t.Fatalf("Expected the finalizer to be retained while the cluster is in maintenance")
+	}
+
+	// Deletion proceeds once the maintenance annotation is removed.
+	cluster.SetAnnotations(nil)
+	result, err = s.ReconcileOnce(context.Background(), fedObject)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Status != utils.StatusNeedsRecheck {
+		t.Fatalf("Expected a recheck of the deletion, got %v", result.Status)
+	}
+	if _, ok := informer.clients["cluster1"].objs[targetKey]; ok {
+		t.Fatalf("Expected the managed resource to be deleted once maintenance ended")
+	}
+}
+
+func TestReconcileOnceEnforcesQuota(t *testing.T) {
+	newFederatedResource := func(namespace, name string, created time.Time) *fakeFederatedResource {
+		fedObject := &unstructured.Unstructured{}
+		fedObject.SetAPIVersion("types.kubefed.io/v1beta1")
+		fedObject.SetKind("FederatedConfigMap")
+		fedObject.SetNamespace(namespace)
+		fedObject.SetName(name)
+		fedObject.SetCreationTimestamp(metav1.NewTime(created))
+		targetObj := &unstructured.Unstructured{}
+		targetObj.SetAPIVersion("v1")
+		targetObj.SetKind("ConfigMap")
+		targetObj.SetNamespace(namespace)
+		targetObj.SetName(name)
+		return &fakeFederatedResource{fedObject: fedObject, targetObj: targetObj}
+	}
+
+	testCases := map[string]struct {
+		quota          fedv1b1.PlacementQuota
+		olderNamespace string
+		expectedStatus status.PropagationStatusMap
+	}{
+		"placement is blocked when the quota of a cluster is exhausted": {
+			quota:          fedv1b1.PlacementQuota{MaxPerCluster: ptr.To[int32](1)},
+			olderNamespace: "foo",
+			expectedStatus: status.PropagationStatusMap{
+				"cluster1": status.QuotaExceeded,
+				"cluster2": status.QuotaExceeded,
+			},
+		},
+		"placement is blocked when the quota of a namespace is exhausted": {
+			quota:          fedv1b1.PlacementQuota{MaxPerCluster: ptr.To[int32](2), MaxPerNamespace: ptr.To[int32](1)},
+			olderNamespace: "foo",
+			expectedStatus: status.PropagationStatusMap{
+				"cluster1": status.QuotaExceeded,
+				"cluster2": status.QuotaExceeded,
+			},
+		},
+		"placement is allowed within the quota of a namespace": {
+			quota:          fedv1b1.PlacementQuota{MaxPerNamespace: ptr.To[int32](1)},
+			olderNamespace: "baz",
+			expectedStatus: status.PropagationStatusMap{
+				"cluster1": status.ClusterPropagationOK,
+				"cluster2": status.ClusterPropagationOK,
+			},
+		},
+		"placement is allowed within the quota": {
+			quota:          fedv1b1.PlacementQuota{MaxPerCluster: ptr.To[int32](2), MaxPerNamespace: ptr.To[int32](2)},
+			olderNamespace: "foo",
+			expectedStatus: status.PropagationStatusMap{
+				"cluster1": status.ClusterPropagationOK,
+				"cluster2": status.ClusterPropagationOK,
+			},
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			now := time.Now()
+			// A resource created earlier that is already present in
+			// cluster1 and, having been created first, is admitted
+			// to cluster2 ahead of the reconciled resource.
+			olderResource := newFederatedResource(tc.olderNamespace, "older", now.Add(-time.Hour))
+			fedResource := newFederatedResource("foo", "bar", now)
+
+			hostClient := newMemoryClient()
+			if err := hostClient.Create(context.Background(), fedResource.fedObject); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			informer := newFakeInformer("cluster1", "cluster2")
+			if err := informer.clients["cluster1"].Create(context.Background(), olderResource.targetObj.DeepCopy()); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			typeConfig := &fedv1b1.FederatedTypeConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: "configmaps"},
+				Spec:       fedv1b1.FederatedTypeConfigSpec{Quota: &tc.quota},
+			}
+			s := &KubeFedSyncController{
+				informer: informer,
+				fedAccessor: &fakeAccessor{
+					objs:              []*unstructured.Unstructured{olderResource.fedObject, fedResource.fedObject},
+					fedResource:       fedResource,
+					otherFedResources: []FederatedResource{olderResource},
+				},
+				hostClusterClient:   hostClient,
+				typeConfig:          typeConfig,
+				cacheSyncTimeout:    time.Second,
+				unreachableClusters: utils.NewSafeMap(),
+				limitedScope:        true,
+				ctx:                 context.Background(),
+				tracer:              noop.NewTracerProvider().Tracer(""),
+			}
+			s.quota = quota.NewController(typeConfig.GetQuota(), s.quotaPlacements, func(utils.QualifiedName) {})
+
+			result, err := s.ReconcileOnce(context.Background(), fedResource.fedObject)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result.PropagationStatus == nil {
+				t.Fatalf("Expected propagation status to be collected")
+			}
+			if !reflect.DeepEqual(tc.expectedStatus, result.PropagationStatus.StatusMap) {
+				t.Fatalf("Expected status %v, got %v", tc.expectedStatus, result.PropagationStatus.StatusMap)
+			}
+			key := utils.NewQualifiedName(fedResource.targetObj).String()
+			for clusterName, propagationStatus := range tc.expectedStatus {
+				_, created := informer.clients[clusterName].objs[key]
+				if blocked := propagationStatus == status.QuotaExceeded; created == blocked {
+					t.Fatalf("Expected the ConfigMap to be created in %q: %v", clusterName, !blocked)
+				}
+			}
+		})
+	}
+}
+
+func TestReconcileOnceWaitsForCanary(t *testing.T) {
+	fedObject, targetObj := newFakeObjects("v1", "ConfigMap")
+	// The status of the resource reflects its current generation, so
+	// that updates of the status do not change its version.
+	targetObj.SetGeneration(1)
+
+	hostClient := newHostClient(t, fedObject)
+	informer := newFakeInformer("cluster1", "cluster2")
+	worker := &recordingWorker{delays: make(map[utils.QualifiedName]time.Duration)}
+	fedResource := &fakeFederatedResource{fedObject: fedObject, targetObj: targetObj, canaryClusterNames: []string{"cluster1"}}
+	s := &KubeFedSyncController{
+		worker:              worker,
+		informer:            informer,
+		fedAccessor:         &fakeAccessor{fedResource: fedResource},
+		hostClusterClient:   hostClient,
+		typeConfig:          &fedv1b1.FederatedTypeConfig{},
+		cacheSyncTimeout:    time.Second,
+		unreachableClusters: utils.NewSafeMap(),
+		limitedScope:        true,
+		ctx:                 context.Background(),
+		tracer:              noop.NewTracerProvider().Tracer(""),
+	}
+	key := utils.NewQualifiedName(targetObj).String()
+	setCanaryConditions := func(conditions ...interface{}) {
+		clusterObj := informer.clients["cluster1"].objs[key].DeepCopy()
+		clusterObj.Object["status"] = map[string]interface{}{
+			"observedGeneration": int64(1),
+			"conditions":         conditions,
+		}
+		if err := informer.clients["cluster1"].UpdateStatus(context.Background(), clusterObj); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	reconcile := func(expectedCanaryReason status.AggregateReason, expectedStatus status.PropagationStatusMap) {
+		t.Helper()
+		result, err := s.ReconcileOnce(context.Background(), fedObject)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if result.PropagationStatus == nil || result.PropagationStatus.Canary == nil {
+			t.Fatalf("Expected the state of the canary rollout to be collected")
+		}
+		if reason := result.PropagationStatus.Canary.Reason; reason != expectedCanaryReason {
+			t.Fatalf("Expected canary reason %q, got %q", expectedCanaryReason, reason)
+		}
+		if !reflect.DeepEqual(expectedStatus, result.PropagationStatus.StatusMap) {
+			t.Fatalf("Expected status %v, got %v", expectedStatus, result.PropagationStatus.StatusMap)
+		}
+		_, created := informer.clients["cluster2"].objs[key]
+		if waiting := expectedStatus["cluster2"] == status.WaitingForCanary; created == waiting {
+			t.Fatalf("Expected the ConfigMap to be created in %q: %v", "cluster2", !waiting)
+		}
+	}
+	waitingStatus := status.PropagationStatusMap{
+		"cluster1": status.ClusterPropagationOK,
+		"cluster2": status.WaitingForCanary,
+	}
+
+	// The resource is created in the canary cluster first.
+	reconcile(status.CanaryPending, waitingStatus)
+	if delay := worker.delays[utils.NewQualifiedName(fedObject)]; delay != canaryRecheckInterval {
+		t.Fatalf("Expected the federated resource to be reconciled again after %v, got %v", canaryRecheckInterval, delay)
+	}
+
+	setCanaryConditions(map[string]interface{}{"type": "Ready", "status": "False"})
+	reconcile(status.CanaryPending, waitingStatus)
+
+	setCanaryConditions(map[string]interface{}{"type": "Stalled", "status": "True", "reason": "InvalidSpec"})
+	reconcile(status.CanaryFailed, waitingStatus)
+
+	setCanaryConditions(map[string]interface{}{"type": "Ready", "status": "True"})
+	reconcile(status.AggregateSuccess, status.PropagationStatusMap{
+		"cluster1": status.ClusterPropagationOK,
+		"cluster2": status.ClusterPropagationOK,
+	})
+}
+
+func TestReconcileOnceSkipsUnavailableCanary(t *testing.T) {
+	fedObject, targetObj := newFakeObjects("v1", "ConfigMap")
+	hostClient := newHostClient(t, fedObject)
+	informer := newFakeInformer("cluster1", "cluster2", "cluster3")
+	informer.clusters[0].Annotations = map[string]string{utils.MaintenanceAnnotation: utils.MaintenanceValue}
+	fedResource := &fakeFederatedResource{fedObject: fedObject, targetObj: targetObj, canaryClusterNames: []string{"cluster1", "cluster2"}}
+	s := &KubeFedSyncController{
+		worker:              &recordingWorker{delays: make(map[utils.QualifiedName]time.Duration)},
+		informer:            informer,
+		fedAccessor:         &fakeAccessor{fedResource: fedResource},
+		hostClusterClient:   hostClient,
+		typeConfig:          &fedv1b1.FederatedTypeConfig{},
+		cacheSyncTimeout:    time.Second,
+		unreachableClusters: utils.NewSafeMap(),
+		limitedScope:        true,
+		ctx:                 context.Background(),
+		tracer:              noop.NewTracerProvider().Tracer(""),
+	}
+	key := utils.NewQualifiedName(targetObj).String()
+	reconcile := func(expectedCanary status.CanaryStatus, expectedStatus status.PropagationStatus) {
+		t.Helper()
+		result, err := s.ReconcileOnce(context.Background(), fedObject)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if result.PropagationStatus == nil || result.PropagationStatus.Canary == nil {
+			t.Fatalf("Expected the state of the canary rollout to be collected")
+		}
+		if canary := *result.PropagationStatus.Canary; canary != expectedCanary {
+			t.Fatalf("Expected canary state %#v, got %#v", expectedCanary, canary)
+		}
+		if propagationStatus := result.PropagationStatus.StatusMap["cluster3"]; propagationStatus != expectedStatus {
+			t.Fatalf("Expected status %q for %q, got %q", expectedStatus, "cluster3", propagationStatus)
+		}
+	}
+
+	// The rollout only waits for the canary cluster that is available.
+	reconcile(status.CanaryStatus{
+		Reason:  status.CanaryPending,
+		Message: `Waiting for propagation to canary cluster "cluster2"`,
+	}, status.WaitingForCanary)
+	if _, ok := informer.clients["cluster3"].objs[key]; ok {
+		t.Fatalf("Expected the ConfigMap not to be created in %q before the canary is healthy", "cluster3")
+	}
+
+	reconcile(status.CanaryStatus{}, status.ClusterPropagationOK)
+	if _, ok := informer.clients["cluster3"].objs[key]; !ok {
+		t.Fatalf("Expected the ConfigMap to be created in %q", "cluster3")
+	}
+}
+
+func newCustomResourceDefinition(name string, established bool) *unstructured.Unstructured {
+	crd := &unstructured.Unstructured{}
+	crd.SetAPIVersion("apiextensions.k8s.io/v1")
+	crd.SetKind("CustomResourceDefinition")
+	crd.SetName(name)
+	if established {
+		crd.Object["status"] = map[string]interface{}{
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Established", "status": "True"},
+			},
+		}
+	}
+	return crd
+}
+
+func TestReconcileOnceWaitsForCustomResourceDefinition(t *testing.T) {
+	fedObject, targetObj := newFakeObjects("example.com/v1", "Widget")
+	crdName := "widgets.example.com"
+
+	hostClient := newMemoryClient()
+	for _, obj := range []*unstructured.Unstructured{fedObject, newCustomResourceDefinition(crdName, true)} {
+		if err := hostClient.Create(context.Background(), obj); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	informer := newFakeInformer("cluster1", "cluster2", "cluster3")
+	// The definition is established in cluster1, created but not yet
+	// established in cluster2 and not yet created in cluster3.
+	if err := informer.clients["cluster1"].Create(context.Background(), newCustomResourceDefinition(crdName, true)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := informer.clients["cluster2"].Create(context.Background(), newCustomResourceDefinition(crdName, false)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	fedResource := &fakeFederatedResource{fedObject: fedObject, targetObj: targetObj}
+	s := &KubeFedSyncController{
+		informer:          informer,
+		fedAccessor:       &fakeAccessor{fedResource: fedResource},
+		hostClusterClient: hostClient,
+		typeConfig: &fedv1b1.FederatedTypeConfig{
+			Spec: fedv1b1.FederatedTypeConfigSpec{
+				TargetType: fedv1b1.APIResource{
+					Group:      "example.com",
+					Version:    "v1",
+					Kind:       "Widget",
+					PluralName: "widgets",
+					Scope:      apiextv1.NamespaceScoped,
+				},
+			},
+		},
+		cacheSyncTimeout:    time.Second,
+		unreachableClusters: utils.NewSafeMap(),
+		limitedScope:        true,
+		ctx:                 context.Background(),
+		tracer:              noop.NewTracerProvider().Tracer(""),
+	}
+
+	key := utils.NewQualifiedName(targetObj).String()
+	expectStatus := func(expectedStatus status.PropagationStatusMap) {
+		t.Helper()
+		result, err := s.ReconcileOnce(context.Background(), fedObject)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if result.PropagationStatus == nil {
+			t.Fatalf("Expected propagation status to be collected")
+		}
+		if !reflect.DeepEqual(expectedStatus, result.PropagationStatus.StatusMap) {
+			t.Fatalf("Expected status %v, got %v", expectedStatus, result.PropagationStatus.StatusMap)
+		}
+		for clusterName, clusterStatus := range expectedStatus {
+			_, created := informer.clients[clusterName].objs[key]
+			if created != (clusterStatus == status.ClusterPropagationOK) {
+				t.Fatalf("Expected the Widget to be created in %q only if its definition is established", clusterName)
+			}
+		}
+	}
+
+	expectStatus(status.PropagationStatusMap{
+		"cluster1": status.ClusterPropagationOK,
+		"cluster2": status.CustomResourceDefinitionNotEstablished,
+		"cluster3": status.CustomResourceDefinitionNotEstablished,
+	})
+
+	// The Widget is created once the definition is established.
+	for _, clusterName := range []string{"cluster2", "cluster3"} {
+		informer.clients[clusterName].store(newCustomResourceDefinition(crdName, true))
+	}
+	expectStatus(status.PropagationStatusMap{
+		"cluster1": status.ClusterPropagationOK,
+		"cluster2": status.ClusterPropagationOK,
+		"cluster3": status.ClusterPropagationOK,
+	})
+}
+
+func newNamespace(name string, labels map[string]string) *unstructured.Unstructured {
+	namespace := &unstructured.Unstructured{}
+	namespace.SetAPIVersion("v1")
+	namespace.SetKind("Namespace")
+	namespace.SetName(name)
+	namespace.SetLabels(labels)
+	return namespace
+}
+
+func TestReconcileOnceRequiresNamespaceOptIn(t *testing.T) {
+	fedObject, targetObj := newFakeObjects("v1", "ConfigMap")
+	optInLabel := "example.com/kubefed-managed"
+
+	hostClient := newHostClient(t, fedObject)
+	informer := newFakeInformer("cluster1", "cluster2", "cluster3", "cluster4", "cluster5")
+	// The namespace has opted in to management in cluster1, has the
+	// label with another value in cluster2, lacks it in cluster3 and
+	// does not exist in cluster4. Namespaces of cluster5 cannot be
+	// retrieved.
+	informer.clients["cluster5"].getErr = errors.NewServiceUnavailable("unavailable")
+	namespaceLabels := map[string]map[string]string{
+		"cluster1": {optInLabel: "true"},
+		"cluster2": {optInLabel: "false"},
+		"cluster3": nil,
+	}
+	for clusterName, labels := range namespaceLabels {
+		if err := informer.clients[clusterName].Create(context.Background(), newNamespace("foo", labels)); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	fedResource := &fakeFederatedResource{fedObject: fedObject, targetObj: targetObj}
+	s := &KubeFedSyncController{
+		informer:          informer,
+		fedAccessor:       &fakeAccessor{fedResource: fedResource},
+		hostClusterClient: hostClient,
+		typeConfig: &fedv1b1.FederatedTypeConfig{
+			Spec: fedv1b1.FederatedTypeConfigSpec{
+				TargetType: fedv1b1.APIResource{
+					Version:    "v1",
+					Kind:       "ConfigMap",
+					PluralName: "configmaps",
+					Scope:      apiextv1.NamespaceScoped,
+				},
+			},
+		},
+		cacheSyncTimeout:    time.Second,
+		unreachableClusters: utils.NewSafeMap(),
+		limitedScope:        true,
+		namespaceOptInLabel: optInLabel,
+		ctx:                 context.Background(),
+		tracer:              noop.NewTracerProvider().Tracer(""),
+	}
+
+	key := utils.NewQualifiedName(targetObj).String()
+	expectStatus := func(expectedStatus status.PropagationStatusMap) {
+		t.Helper()
+		result, err := s.ReconcileOnce(context.Background(), fedObject)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if result.PropagationStatus == nil {
+			t.Fatalf("Expected propagation status to be collected")
+		}
+		if !reflect.DeepEqual(expectedStatus, result.PropagationStatus.StatusMap) {
+			t.Fatalf("Expected status %v, got %v", expectedStatus, result.PropagationStatus.StatusMap)
+		}
+		for clusterName, clusterStatus := range expectedStatus {
+			_, created := informer.clients[clusterName].objs[key]
+			if created != (clusterStatus == status.ClusterPropagationOK) {
+				t.Fatalf("Expected the ConfigMap to be created in %q only if its namespace has opted in", clusterName)
+			}
+		}
+	}
+
+	expectStatus(status.PropagationStatusMap{
+		"cluster1": status.ClusterPropagationOK,
+		"cluster2": status.NamespaceNotOptedIn,
+		"cluster3": status.NamespaceNotOptedIn,
+		"cluster4": status.NamespaceNotOptedIn,
+		"cluster5": status.RetrievalFailed,
+	})
+
+	// The ConfigMap is created once the namespace opts in.
+	informer.clients["cluster5"].getErr = nil
+	for _, clusterName := range []string{"cluster2", "cluster3", "cluster4", "cluster5"} {
+		informer.clients[clusterName].store(newNamespace("foo", map[string]string{optInLabel: "true"}))
+	}
+	expectStatus(status.PropagationStatusMap{
+		"cluster1": status.ClusterPropagationOK,
+		"cluster2": status.ClusterPropagationOK,
+		"cluster3": status.ClusterPropagationOK,
+		"cluster4": status.ClusterPropagationOK,
+		"cluster5": status.ClusterPropagationOK,
+	})
+}
+
+func TestReconcileOnceCreatesInApplyOrder(t *testing.T) {
+	newResource := func(kind, name string, selectedClusterNames ...string) *fakeFederatedResource {
+		fedObject := &unstructured.Unstructured{}
+		fedObject.SetAPIVersion("types.kubefed.io/v1beta1")
+		fedObject.SetKind("Federated" + kind)
+		fedObject.SetNamespace("foo")
+		fedObject.SetName(name)
+		targetObj := &unstructured.Unstructured{}
+		targetObj.SetAPIVersion("v1")
+		targetObj.SetKind(kind)
+		targetObj.SetNamespace("foo")
+		targetObj.SetName(name)
+		return &fakeFederatedResource{fedObject: fedObject, targetObj: targetObj, selectedClusterNames: selectedClusterNames}
+	}
+	// The ServiceAccount is only placed to cluster1.
+	serviceAccount := newResource("ServiceAccount", "sa", "cluster1")
+	configMap := newResource("ConfigMap", "cm", "cluster1", "cluster2")
+
+	informer := newFakeInformer("cluster1", "cluster2")
+	gate := utils.NewApplyOrderGate(utils.ApplyOrder{"ServiceAccount", "ConfigMap"})
+	newController := func(fedResource *fakeFederatedResource, pluralName string) *KubeFedSyncController {
+		hostClient := newMemoryClient()
+		if err := hostClient.Create(context.Background(), fedResource.fedObject); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return &KubeFedSyncController{
+			informer: informer,
+			fedAccessor: &fakeAccessor{
+				fedResource: fedResource,
+				objs:        []*unstructured.Unstructured{fedResource.fedObject},
+			},
+			hostClusterClient: hostClient,
+			typeConfig: &fedv1b1.FederatedTypeConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: pluralName},
+				Spec: fedv1b1.FederatedTypeConfigSpec{
+					TargetType: fedv1b1.APIResource{
+						Version:    "v1",
+						Kind:       fedResource.TargetKind(),
+						PluralName: pluralName,
+						Scope:      apiextv1.NamespaceScoped,
+					},
+				},
+			},
+			applyOrderGate:      gate,
+			cacheSyncTimeout:    time.Second,
+			unreachableClusters: utils.NewSafeMap(),
+			limitedScope:        true,
+			ctx:                 context.Background(),
+			tracer:              noop.NewTracerProvider().Tracer(""),
+		}
+	}
+	serviceAccounts := newController(serviceAccount, "serviceaccounts")
+	configMaps := newController(configMap, "configmaps")
+	// Like Run, the controllers register the resources they have yet
+	// to create.
+	gate.Register("serviceaccounts", "ServiceAccount", serviceAccounts.pendingCreations)
+	gate.Register("configmaps", "ConfigMap", configMaps.pendingCreations)
+
+	expectStatus := func(s *KubeFedSyncController, fedResource *fakeFederatedResource, expectedStatus status.PropagationStatusMap) {
+		t.Helper()
+		result, err := s.ReconcileOnce(context.Background(), fedResource.fedObject)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if result.PropagationStatus == nil {
+			t.Fatalf("Expected propagation status to be collected")
+		}
+		if !reflect.DeepEqual(expectedStatus, result.PropagationStatus.StatusMap) {
+			t.Fatalf("Expected status %v, got %v", expectedStatus, result.PropagationStatus.StatusMap)
+		}
+		for clusterName, clusterStatus := range expectedStatus {
+			_, created := informer.clients[clusterName].objs[fedResource.TargetName().String()]
+			if created != (clusterStatus == status.ClusterPropagationOK) {
+				t.Fatalf("Expected the %s to be created in %q: %v", fedResource.TargetKind(), clusterName, !created)
+			}
+		}
+	}
+
+	// The ConfigMap is not created in cluster1 before the
+	// ServiceAccount, but is created in cluster2 where no
+	// ServiceAccount is placed.
+	expectStatus(configMaps, configMap, status.PropagationStatusMap{
+		"cluster1": status.WaitingForApplyOrder,
+		"cluster2": status.ClusterPropagationOK,
+	})
+	expectStatus(serviceAccounts, serviceAccount, status.PropagationStatusMap{
+		"cluster1": status.ClusterPropagationOK,
+	})
+	expectStatus(configMaps, configMap, status.PropagationStatusMap{
+		"cluster1": status.ClusterPropagationOK,
+		"cluster2": status.ClusterPropagationOK,
+	})
+}
+
+func newEndpointSlice(name, serviceName string, ready ...*bool) *unstructured.Unstructured {
+	endpointSlice := &unstructured.Unstructured{}
+	endpointSlice.SetAPIVersion("discovery.k8s.io/v1")
+	endpointSlice.SetKind("EndpointSlice")
+	endpointSlice.SetNamespace("foo")
+	endpointSlice.SetName(name)
+	endpointSlice.SetLabels(map[string]string{discoveryv1.LabelServiceName: serviceName})
+	var endpoints []interface{}
+	for _, endpointReady := range ready {
+		conditions := map[string]interface{}{}
+		if endpointReady != nil {
+			conditions["ready"] = *endpointReady
+		}
+		endpoints = append(endpoints, map[string]interface{}{
+			"addresses":  []interface{}{"10.0.0.1"},
+			"conditions": conditions,
+		})
+	}
+	endpointSlice.Object["addressType"] = "IPv4"
+	endpointSlice.Object["endpoints"] = endpoints
+	return endpointSlice
+}
+
+func TestReconcileOnceCollectsReadyEndpoints(t *testing.T) {
+	fedObject := &unstructured.Unstructured{}
+	fedObject.SetAPIVersion("types.kubefed.io/v1beta1")
+	fedObject.SetKind("FederatedService")
+	fedObject.SetNamespace("foo")
+	fedObject.SetName("bar")
+	targetObj := &unstructured.Unstructured{}
+	targetObj.SetAPIVersion("v1")
+	targetObj.SetKind(utils.ServiceKind)
+	targetObj.SetNamespace("foo")
+	targetObj.SetName("bar")
+
+	hostClient := newHostClient(t, fedObject)
+	informer := &fakeInformer{clients: make(map[string]*memoryClient)}
+	endpointSliceInformer := &fakeInformer{clients: make(map[string]*memoryClient)}
+	for _, clusterName := range []string{"cluster1", "cluster2", "cluster3"} {
+		informer.clusters = append(informer.clusters, &fedv1b1.KubeFedCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: clusterName},
+			Status: fedv1b1.KubeFedClusterStatus{
+				Conditions: []fedv1b1.ClusterCondition{{Type: common.ClusterReady, Status: corev1.ConditionTrue}},
+			},
+		})
+		informer.clients[clusterName] = newMemoryClient()
+	}
+	// The EndpointSlices of cluster3 are not synced. An endpoint
+	// whose readiness is unknown is ready, and the EndpointSlices of
+	// other services are ignored.
+	endpointSliceInformer.clients["cluster1"] = newMemoryClient()
+	endpointSliceInformer.clients["cluster1"].store(newEndpointSlice("bar-1", "bar", ptr.To(true), ptr.To(false)))
+	endpointSliceInformer.clients["cluster1"].store(newEndpointSlice("bar-2", "bar", ptr.To(true)))
+	endpointSliceInformer.clients["cluster1"].store(newEndpointSlice("baz-1", "baz", ptr.To(true)))
+	endpointSliceInformer.clients["cluster2"] = newMemoryClient()
+	endpointSliceInformer.clients["cluster2"].store(newEndpointSlice("bar-1", "bar", nil))
+
+	statusCollection := fedv1b1.StatusCollectionEnabled
+	fedResource := &fakeFederatedResource{fedObject: fedObject, targetObj: targetObj}
+	s := &KubeFedSyncController{
+		informer:              informer,
+		endpointSliceInformer: endpointSliceInformer,
+		fedAccessor:           &fakeAccessor{fedResource: fedResource},
+		hostClusterClient:     hostClient,
+		typeConfig: &fedv1b1.FederatedTypeConfig{
+			Spec: fedv1b1.FederatedTypeConfigSpec{
+				TargetType: fedv1b1.APIResource{
+					Version:    "v1",
+					Kind:       utils.ServiceKind,
+					PluralName: "services",
+					Scope:      apiextv1.NamespaceScoped,
+				},
+				StatusCollection: &statusCollection,
+			},
+		},
+		cacheSyncTimeout:            time.Second,
+		unreachableClusters:         utils.NewSafeMap(),
+		limitedScope:                true,
+		rawResourceStatusCollection: true,
+		ctx:                         context.Background(),
+		tracer:                      noop.NewTracerProvider().Tracer(""),
+	}
+
+	result, err := s.ReconcileOnce(context.Background(), fedObject)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Status != utils.StatusAllOK {
+		t.Fatalf("Expected reconciliation to succeed, got %v", result.Status)
+	}
+	fedStatus, err := status.DecodeGenericFederatedResource(hostClient.objs[utils.NewQualifiedName(fedObject).String()])
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if fedStatus.Status == nil {
+		t.Fatalf("Expected the status to be written")
+	}
+	readyEndpoints := map[string]*int64{}
+	for _, clusterStatus := range fedStatus.Status.Clusters {
+		readyEndpoints[clusterStatus.Name] = clusterStatus.ReadyEndpoints
+	}
+	expected := map[string]*int64{
+		"cluster1": ptr.To[int64](2),
+		"cluster2": ptr.To[int64](1),
+		"cluster3": nil,
+	}
+	if !reflect.DeepEqual(expected, readyEndpoints) {
+		t.Fatalf("Expected ready endpoints %v, got %v", expected, readyEndpoints)
+	}
+	if !reflect.DeepEqual(ptr.To[int64](3), fedStatus.Status.ReadyEndpoints) {
+		t.Fatalf("Expected 3 ready endpoints in total, got %v", fedStatus.Status.ReadyEndpoints)
+	}
+	if !slices.Contains(fedResource.eventReasons, "ReadyEndpointsNotCollected") {
+		t.Fatalf("Expected the cluster whose EndpointSlices are not synced to be reported, got events %v", fedResource.eventReasons)
+	}
+}
+
+func TestReconcileOnceAggregatesMetrics(t *testing.T) {
+	fedObject, targetObj := newFakeObjects("v1", "ConfigMap")
+	// The status of the resource reflects its current generation, so
+	// that updates of the status do not change its version.
+	targetObj.SetGeneration(1)
+
+	hostClient := newHostClient(t, fedObject)
+	informer := newFakeInformer("cluster1", "cluster2", "cluster3")
+	statusCollection := fedv1b1.StatusCollectionEnabled
+	s := &KubeFedSyncController{
+		informer:          informer,
+		fedAccessor:       &fakeAccessor{fedResource: &fakeFederatedResource{fedObject: fedObject, targetObj: targetObj}},
+		hostClusterClient: hostClient,
+		typeConfig: &fedv1b1.FederatedTypeConfig{
+			Spec: fedv1b1.FederatedTypeConfigSpec{
+				StatusCollection: &statusCollection,
+				StatusAggregations: []fedv1b1.StatusAggregation{
+					{Name: "totalReadyReplicas", Field: "readyReplicas", Function: fedv1b1.AggregationSum},
+					{Name: "minReadyReplicas", Field: "readyReplicas", Function: fedv1b1.AggregationMin},
+				},
+			},
+		},
+		cacheSyncTimeout:            time.Second,
+		unreachableClusters:         utils.NewSafeMap(),
+		limitedScope:                true,
+		rawResourceStatusCollection: true,
+		ctx:                         context.Background(),
+	}
+	reconcile := func(expectedMetrics map[string]int64, expectedUnreported []string) {
+		t.Helper()
+		if _, err := s.ReconcileOnce(context.Background(), fedObject); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		fedStatus, err := status.DecodeGenericFederatedResource(hostClient.objs[utils.NewQualifiedName(fedObject).String()])
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if fedStatus.Status == nil {
+			t.Fatalf("Expected the status to be written")
+		}
+		if !reflect.DeepEqual(expectedMetrics, fedStatus.Status.AggregatedMetrics) {
+			t.Fatalf("Expected aggregated metrics %v, got %v", expectedMetrics, fedStatus.Status.AggregatedMetrics)
+		}
+		if !reflect.DeepEqual(expectedUnreported, fedStatus.Status.UnreportedMetricsClusters) {
+			t.Fatalf("Expected unreported clusters %v, got %v", expectedUnreported, fedStatus.Status.UnreportedMetricsClusters)
+		}
+	}
+
+	// No cluster has reported the status of the newly created
+	// ConfigMaps.
+	reconcile(nil, []string{"cluster1", "cluster2", "cluster3"})
+
+	key := utils.NewQualifiedName(targetObj).String()
+	for clusterName, readyReplicas := range map[string]int64{"cluster1": 2, "cluster2": 3} {
+		clusterObj := informer.clients[clusterName].objs[key].DeepCopy()
+		clusterObj.Object["status"] = map[string]interface{}{"readyReplicas": readyReplicas}
+		if err := informer.clients[clusterName].UpdateStatus(context.Background(), clusterObj); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	// The cluster that has not reported a status is not silently
+	// left out of the aggregates.
+	reconcile(map[string]int64{"totalReadyReplicas": 5, "minReadyReplicas": 2}, []string{"cluster3"})
+}
+
+func TestEnqueueForEndpointSlice(t *testing.T) {
+	// The service in the member cluster is named from a template.
+	service := &unstructured.Unstructured{}
+	service.SetAPIVersion("v1")
+	service.SetKind(utils.ServiceKind)
+	service.SetNamespace("foo")
+	service.SetName("bar-templated")
+	service.SetAnnotations(map[string]string{utils.FederatedNameAnnotation: "bar"})
+
+	informer := &fakeInformer{
+		clusters: []*fedv1b1.KubeFedCluster{{ObjectMeta: metav1.ObjectMeta{Name: "cluster1"}}},
+		clients:  map[string]*memoryClient{"cluster1": newMemoryClient()},
+	}
+	informer.clients["cluster1"].store(service)
+	worker := &recordingWorker{delays: make(map[utils.QualifiedName]time.Duration)}
+	s := &KubeFedSyncController{
+		informer: informer,
+		worker:   worker,
+	}
+
+	s.enqueueForEndpointSlice(newEndpointSlice("bar-templated-1", "bar-templated"))
+	s.enqueueForEndpointSlice(newEndpointSlice("baz-1", "baz"))
+	unlabeled := newEndpointSlice("qux-1", "qux")
+	unlabeled.SetLabels(nil)
+	s.enqueueForEndpointSlice(unlabeled)
+
+	expected := map[utils.QualifiedName]time.Duration{
+		{Namespace: "foo", Name: "bar"}: 0,
+		{Namespace: "foo", Name: "baz"}: 0,
+	}
+	if !reflect.DeepEqual(expected, worker.delays) {
+		t.Fatalf("Expected %v to be enqueued, got %v", expected, worker.delays)
+	}
+}
+
+func TestDetectDrift(t *testing.T) {
+	fedObject, targetObj := newFakeObjects("v1", "ConfigMap")
+	fedObject.SetGeneration(1)
+	if err := unstructured.SetNestedField(targetObj.Object, "value", "data", "key"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	hostClient := newHostClient(t, fedObject)
+	informer := newFakeInformer("cluster1", "cluster2")
+	fedResource := &fakeFederatedResource{fedObject: fedObject, targetObj: targetObj}
+	s := &KubeFedSyncController{
+		informer:            informer,
+		fedAccessor:         &fakeAccessor{objs: []*unstructured.Unstructured{fedObject}, fedResource: fedResource, hostClient: hostClient},
+		hostClusterClient:   hostClient,
+		typeConfig:          &fedv1b1.FederatedTypeConfig{},
+		cacheSyncTimeout:    time.Second,
+		unreachableClusters: utils.NewSafeMap(),
+		limitedScope:        true,
+		ctx:                 context.Background(),
+		tracer:              noop.NewTracerProvider().Tracer(""),
+	}
+
+	driftConditions := func() map[string]*status.GenericCondition {
+		t.Helper()
+		stored := &unstructured.Unstructured{}
+		if err := hostClient.Get(context.Background(), stored, "foo", "bar"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		resource, err := status.DecodeGenericFederatedResource(stored)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		conditions := make(map[string]*status.GenericCondition)
+		if resource.Status == nil {
+			return conditions
+		}
+		for _, cluster := range resource.Status.Clusters {
+			for _, condition := range cluster.Conditions {
+				if condition.Type == status.DriftDetectedConditionType {
+					conditions[cluster.Name] = condition
+				}
+			}
+		}
+		return conditions
+	}
+
+	if _, err := s.ReconcileOnce(context.Background(), fedObject); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	s.detectDrift()
+	if conditions := driftConditions(); len(conditions) != 0 {
+		t.Fatalf("Expected no drift to be detected, got %v", conditions)
+	}
+
+	// Modify the resource in cluster1 out-of-band.
+	key := utils.NewQualifiedName(targetObj).String()
+	clusterObj := informer.clients["cluster1"].objs[key].DeepCopy()
+	if err := unstructured.SetNestedField(clusterObj.Object, "other", "data", "key"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	informer.clients["cluster1"].store(clusterObj)
+
+	s.detectDrift()
+	conditions := driftConditions()
+	condition, ok := conditions["cluster1"]
+	if !ok || condition.Status != corev1.ConditionTrue {
+		t.Fatalf("Expected drift to be detected in cluster1, got %v", conditions)
+	}
+	if !strings.Contains(condition.Message, "~/data/key") {
+		t.Fatalf("Expected the drifted path to be reported, got %q", condition.Message)
+	}
+	if _, ok := conditions["cluster2"]; ok {
+		t.Fatalf("Expected no drift to be detected in cluster2")
+	}
+
+	// Restore the desired content.
+	if err := unstructured.SetNestedField(clusterObj.Object, "value", "data", "key"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	informer.clients["cluster1"].store(clusterObj)
+
+	s.detectDrift()
+	if condition := driftConditions()["cluster1"]; condition == nil || condition.Status != corev1.ConditionFalse {
+		t.Fatalf("Expected the drift in cluster1 to be cleared, got %v", condition)
+	}
+}
+
+func TestSyncControllerStopsOnContextCancellation(t *testing.T) {
+	// Verified last so that connections to the test server are closed.
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	// The API server only serves discovery so that informers and the
+	// version manager keep retrying until they are stopped.
+	server := httptest.NewServer(discoveryOnlyHandler(map[string][]metav1.APIResource{
+		"core.kubefed.io/v1beta1": {
+			{Name: "kubefedclusters", Kind: "KubeFedCluster", Namespaced: true},
+		},
+		"core.kubefed.io/v1alpha1": {
+			{Name: "clusterpropagatedversions", Kind: "ClusterPropagatedVersion"},
+			{Name: "propagatedversions", Kind: "PropagatedVersion", Namespaced: true},
+		},
+		"types.kubefed.io/v1beta1": {
+			{Name: "federatedclusterroles", Kind: "FederatedClusterRole"},
+		},
+	}))
+	defer server.Close()
+
+	controllerConfig := &utils.ControllerConfig{
+		KubeFedNamespaces: utils.KubeFedNamespaces{
+			KubeFedNamespace: "kube-federation-system",
+			TargetNamespace:  metav1.NamespaceAll,
+		},
+		KubeConfig:                  &restclient.Config{Host: server.URL},
+		CacheSyncTimeout:            time.Second,
+		MaxConcurrentSyncReconciles: 1,
+	}
+	typeConfig := &fedv1b1.FederatedTypeConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "clusterroles.rbac.authorization.k8s.io"},
+		Spec: fedv1b1.FederatedTypeConfigSpec{
+			TargetType: fedv1b1.APIResource{
+				Group:      "rbac.authorization.k8s.io",
+				Version:    "v1",
+				Kind:       "ClusterRole",
+				PluralName: "clusterroles",
+				Scope:      apiextv1.ClusterScoped,
+			},
+			FederatedType: fedv1b1.APIResource{
+				Group:      "types.kubefed.io",
+				Version:    "v1beta1",
+				Kind:       "FederatedClusterRole",
+				PluralName: "federatedclusterroles",
+				Scope:      apiextv1.ClusterScoped,
+			},
+			Propagation: fedv1b1.PropagationEnabled,
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	// The stop channel is never closed, so only cancellation of the
+	// context can stop the controller.
+	stopChan := make(chan struct{})
+	if _, err := StartKubeFedSyncController(ctx, true, controllerConfig, stopChan, typeConfig, nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// Allow the controller to start retrying requests.
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+}
+
+// discoveryOnlyHandler serves discovery of the given resources keyed by
+// group version and responds to any other request as unavailable.
+func discoveryOnlyHandler(resources map[string][]metav1.APIResource) http.Handler {
+	groups := make(map[string]*metav1.APIGroup)
+	var groupNames []string
+	for groupVersion := range resources {
+		gv, _ := schema.ParseGroupVersion(groupVersion)
+		group, ok := groups[gv.Group]
+		if !ok {
+			group = &metav1.APIGroup{Name: gv.Group}
+			groups[gv.Group] = group
+			groupNames = append(groupNames, gv.Group)
+		}
+		version := metav1.GroupVersionForDiscovery{GroupVersion: groupVersion, Version: gv.Version}
+		group.Versions = append(group.Versions, version)
+		group.PreferredVersion = version
+	}
+	groupList := &metav1.APIGroupList{}
+	for _, name := range groupNames {
+		groupList.Groups = append(groupList.Groups, *groups[name])
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body interface{}
+		switch {
+		case r.URL.Path == "/api":
+			body = &metav1.APIVersions{}
+		case r.URL.Path == "/apis":
+			body = groupList
+		case strings.HasPrefix(r.URL.Path, "/apis/") && resources[strings.TrimPrefix(r.URL.Path, "/apis/")] != nil:
+			groupVersion := strings.TrimPrefix(r.URL.Path, "/apis/")
+			body = &metav1.APIResourceList{GroupVersion: groupVersion, APIResources: resources[groupVersion]}
+		default:
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(body)
+	})
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sync
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/kubefed/pkg/controller/sync/dispatch"
+	"sigs.k8s.io/kubefed/pkg/controller/utils"
+)
+
+// The deletion of the resources managed by a federated resource with
+// a deletion grace period proceeds as follows:
+//
+//  1. When the federated resource is deleted, its managed resources
+//     are annotated with the time at which the grace period elapses,
+//     and the finalizer is removed so that the federated resource is
+//     removed while its managed resources remain.
+//  2. Until that time, the managed resources are retained as possible
+//     orphans that are pending deletion.
+//  3. If the federated resource is recreated in the meantime, the
+//     annotation is removed when the resources are next updated and
+//     their deletion is cancelled.
+//  4. Otherwise the managed resources are deleted once the time has
+//     passed.
+
+// markDeletionPending annotates the resources managed by the given
+// deleted federated resource with the time at which its deletion grace
+// period elapses and with its delete options, and removes its
+// finalizer. The time is computed from the deletion timestamp so that
+// it is unchanged by retries.
+func (s *KubeFedSyncController) markDeletionPending(ctx context.Context, fedResource FederatedResource, gracePeriod time.Duration) utils.ReconciliationStatus {
+	key := fedResource.FederatedName().String()
+	kind := fedResource.FederatedKind()
+	until := fedResource.Object().GetDeletionTimestamp().Add(gracePeriod).UTC()
+
+	// The delete options are recorded as given so that the resources
+	// are deleted with them once the federated resource is gone.
+	if _, err := utils.GetDeleteOptions(fedResource.Object()); err != nil {
+		runtime.HandleError(errors.Wrapf(err, "failed to deserialize delete options of %s %q", kind, key))
+		return utils.StatusError
+	}
+	deleteOptions := fedResource.Object().GetAnnotations()[utils.DeleteOptionAnnotation]
+
+	clusters, err := s.informer.GetClusters()
+	if err != nil {
+		runtime.HandleError(errors.Wrap(err, "failed to get member clusters"))
+		return utils.StatusError
+	}
+	targetClusters, err := fedResource.ComputePlacement(clusters)
+	if err != nil {
+		runtime.HandleError(errors.Wrapf(err, "failed to compute placement for %s %q", kind, key))
+		return utils.StatusError
+	}
+
+	klog.V(2).Infof("Retaining resources managed by %s %q in member clusters until %v.", kind, key, until.Format(time.RFC3339))
+	ok, err := s.handleDeletionInClusters(ctx, fedResource.TargetGVK(), fedResource.TargetName(), targetClusters, func(dispatcher dispatch.UnmanagedDispatcher, clusterName string, clusterObj *unstructured.Unstructured) {
+		if clusterObj.GetDeletionTimestamp() != nil {
+			return
+		}
+		if fedResource.IsNamespaceInHostCluster(clusterObj) {
+			// A namespace in the host cluster is never deleted by
+			// the sync controller.
+			dispatcher.RemoveManagedLabel(clusterName, clusterObj)
+			return
+		}
+		recorded, pending := utils.DeletionPendingUntil(clusterObj)
+		if pending && recorded.Equal(until) && clusterObj.GetAnnotations()[utils.DeletionPendingOptionsAnnotation] == deleteOptions {
+			return
+		}
+		dispatcher.SetDeletionPending(clusterName, clusterObj, until, deleteOptions)
+	})
+	if err == nil && !ok {
+		err = errors.New("failed to mark managed resources as pending deletion in one or more clusters")
+	}
+	if err != nil {
+		fedResource.RecordError("DeletionPendingError", errors.Wrap(err, "Failed to mark managed resources as pending deletion"))
+		runtime.HandleError(errors.Wrapf(err, "failed to mark the resources managed by %s %q as pending deletion", kind, key))
+		return utils.StatusError
+	}
+
+	if err := s.removeFinalizer(fedResource); err != nil {
+		runtime.HandleError(errors.Wrapf(err, "failed to remove finalizer %q from %s %q", FinalizerSyncController, kind, key))
+		return utils.StatusError
+	}
+	fedResource.RecordEvent("DeletionPending", "Managed resources will be deleted from member clusters at %v unless the resource is recreated", until.Format(time.RFC3339))
+	s.worker.EnqueueWithDelay(fedResource.FederatedName(), time.Until(until))
+	return utils.StatusAllOK
+}
+
+// removeOrphanedResources removes the managed label from the resources
+// with the given name in member clusters for which no federated
+// resource exists. Resources pending deletion are instead deleted with
+// their recorded delete options once their deletion grace period has
+// elapsed, and the name is enqueued for when the next grace period
+// elapses.
+func (s *KubeFedSyncController) removeOrphanedResources(ctx context.Context, gvk schema.GroupVersionKind, qualifiedName utils.QualifiedName, clusters sets.Set[string]) error {
+	now := time.Now()
+	var nextDue time.Duration
+	ok, err := s.handleDeletionInClusters(ctx, gvk, qualifiedName, clusters, func(dispatcher dispatch.UnmanagedDispatcher, clusterName string, clusterObj *unstructured.Unstructured) {
+		if clusterObj.GetDeletionTimestamp() != nil {
+			return
+		}
+		until, pending := utils.DeletionPendingUntil(clusterObj)
+		if !pending || !utils.HasManagedLabel(clusterObj) {
+			dispatcher.RemoveManagedLabel(clusterName, clusterObj)
+			return
+		}
+		if remaining := until.Sub(now); remaining > 0 {
+			if nextDue == 0 || remaining < nextDue {
+				nextDue = remaining
+			}
+			return
+		}
+		opts, err := utils.DeletionPendingOptions(clusterObj)
+		if err != nil {
+			// The resource is retained rather than deleted with
+			// options other than those intended.
+			runtime.HandleError(errors.Wrapf(err, "failed to determine the delete options of %s %q in cluster %q", gvk.Kind, qualifiedName, clusterName))
+			return
+		}
+		// The resource is only deleted as observed, since a change
+		// may indicate that its federated resource was recreated.
+		resourceVersion := clusterObj.GetResourceVersion()
+		opts = append(opts, runtimeclient.Preconditions{ResourceVersion: &resourceVersion})
+		dispatcher.Delete(clusterName, opts...)
+	})
+	if nextDue > 0 {
+		s.worker.EnqueueWithDelay(qualifiedName, nextDue)
+	}
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.Errorf("failed to remove orphaned resources from one or more clusters.")
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sync
+
+import (
+	"reflect"
+
+	"github.com/pkg/errors"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"sigs.k8s.io/kubefed/pkg/controller/utils"
+)
+
+// ChangeType indicates how an element differs between two revisions
+// of a federated resource.
+type ChangeType = utils.ChangeType
+
+const (
+	ChangeAdded    = utils.ChangeAdded
+	ChangeRemoved  = utils.ChangeRemoved
+	ChangeModified = utils.ChangeModified
+)
+
+// OverrideChange describes a change to the override of a path. Old is
+// nil for an added override and New is nil for a removed override.
+type OverrideChange struct {
+	Path string
+	Type ChangeType
+	Old  *utils.ClusterOverride
+	New  *utils.ClusterOverride
+}
+
+// ClusterOverridesChange describes the changes to the overrides of a
+// cluster, ordered by path.
+type ClusterOverridesChange struct {
+	ClusterName string
+	Type        ChangeType
+	Changes     []OverrideChange
+}
+
+// TemplateChange describes a change to a field of the template
+// identified by a JSON pointer relative to the template. Lists are
+// compared as a whole. Old is nil for an added field and New is nil
+// for a removed field.
+type TemplateChange = utils.FieldChange
+
+// DiffOverrides returns the changes to the overrides of each cluster
+// between the given revisions of a federated resource, ordered by
+// cluster name. A nil revision is treated as having no overrides.
+func DiffOverrides(oldFedObject, newFedObject *unstructured.Unstructured) ([]ClusterOverridesChange, error) {
+	oldOverrides, err := utils.GetOverrides(oldFedObject)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error reading the overrides of the old revision")
+	}
+	newOverrides, err := utils.GetOverrides(newFedObject)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error reading the overrides of the new revision")
+	}
+
+	clusterNames := sets.New[string]()
+	for clusterName := range oldOverrides {
+		clusterNames.Insert(clusterName)
+	}
+	for clusterName := range newOverrides {
+		clusterNames.Insert(clusterName)
+	}
+
+	var clusterChanges []ClusterOverridesChange
+	for _, clusterName := range sets.List(clusterNames) {
+		oldClusterOverrides, oldExists := oldOverrides[clusterName]
+		newClusterOverrides, newExists := newOverrides[clusterName]
+		changes := diffClusterOverrides(oldClusterOverrides, newClusterOverrides)
+		if len(changes) == 0 {
+			continue
+		}
+		changeType := ChangeModified
+		switch {
+		case !oldExists || len(oldClusterOverrides) == 0:
+			changeType = ChangeAdded
+		case !newExists || len(newClusterOverrides) == 0:
+			changeType = ChangeRemoved
+		}
+		clusterChanges = append(clusterChanges, ClusterOverridesChange{
+			ClusterName: clusterName,
+			Type:        changeType,
+			Changes:     changes,
+		})
+	}
+	return clusterChanges, nil
+}
+
+func diffClusterOverrides(oldOverrides, newOverrides utils.ClusterOverrides) []OverrideChange {
+	oldByPath := make(map[string]utils.ClusterOverride, len(oldOverrides))
+	for _, override := range oldOverrides {
+		oldByPath[override.Path] = override
+	}
+	newByPath := make(map[string]utils.ClusterOverride, len(newOverrides))
+	for _, override := range newOverrides {
+		newByPath[override.Path] = override
+	}
+
+	paths := sets.New[string]()
+	for path := range oldByPath {
+		paths.Insert(path)
+	}
+	for path := range newByPath {
+		paths.Insert(path)
+	}
+
+	var changes []OverrideChange
+	for _, path := range sets.List(paths) {
+		oldOverride, oldExists := oldByPath[path]
+		newOverride, newExists := newByPath[path]
+		switch {
+		case !oldExists:
+			changes = append(changes, OverrideChange{Path: path, Type: ChangeAdded, New: &newOverride})
+		case !newExists:
+			changes = append(changes, OverrideChange{Path: path, Type: ChangeRemoved, Old: &oldOverride})
+		case !reflect.DeepEqual(oldOverride, newOverride):
+			changes = append(changes, OverrideChange{Path: path, Type: ChangeModified, Old: &oldOverride, New: &newOverride})
+		}
+	}
+	return changes
+}
+
+// DiffTemplate returns the changes to the fields of the template
+// between the given revisions of a federated resource, ordered by
+// path. A nil revision is treated as having an empty template.
+func DiffTemplate(oldFedObject, newFedObject *unstructured.Unstructured) ([]TemplateChange, error) {
+	oldTemplate, err := templateOf(oldFedObject)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error reading the template of the old revision")
+	}
+	newTemplate, err := templateOf(newFedObject)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error reading the template of the new revision")
+	}
+
+	// Avoid walking the templates if their hashes indicate that they
+	// are the same.
+	if oldFedObject != nil && newFedObject != nil {
+		oldHash, err := GetTemplateHash(oldFedObject.Object)
+		if err != nil {
+			return nil, err
+		}
+		newHash, err := GetTemplateHash(newFedObject.Object)
+		if err != nil {
+			return nil, err
+		}
+		if oldHash == newHash {
+			return nil, nil
+		}
+	}
+
+	return utils.DiffFields(oldTemplate, newTemplate), nil
+}
+
+func templateOf(fedObject *unstructured.Unstructured) (map[string]interface{}, error) {
+	if fedObject == nil {
+		return nil, nil
+	}
+	template, _, err := unstructured.NestedMap(fedObject.Object, utils.SpecField, utils.TemplateField)
+	return template, err
+}
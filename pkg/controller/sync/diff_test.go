@@ -0,0 +1,222 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sync
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"sigs.k8s.io/kubefed/pkg/controller/utils"
+)
+
+func newFedObjectWithOverrides(t *testing.T, overridesMap utils.OverridesMap) *unstructured.Unstructured {
+	fedObject := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	if err := utils.SetOverrides(fedObject, overridesMap); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return fedObject
+}
+
+func TestDiffOverrides(t *testing.T) {
+	replicas := func(value interface{}) utils.ClusterOverride {
+		return utils.ClusterOverride{Path: "/spec/replicas", Value: value}
+	}
+	image := utils.ClusterOverride{Path: "/spec/image", Value: "foo:v1"}
+	testCases := map[string]struct {
+		oldOverrides utils.OverridesMap
+		newOverrides utils.OverridesMap
+		expected     []ClusterOverridesChange
+	}{
+		"no changes": {
+			oldOverrides: utils.OverridesMap{"cluster1": {replicas(float64(1))}},
+			newOverrides: utils.OverridesMap{"cluster1": {replicas(float64(1))}},
+		},
+		"cluster added": {
+			oldOverrides: utils.OverridesMap{},
+			newOverrides: utils.OverridesMap{"cluster1": {replicas(float64(1))}},
+			expected: []ClusterOverridesChange{{
+				ClusterName: "cluster1",
+				Type:        ChangeAdded,
+				Changes: []OverrideChange{
+					{Path: "/spec/replicas", Type: ChangeAdded, New: &utils.ClusterOverride{Path: "/spec/replicas", Value: float64(1)}},
+				},
+			}},
+		},
+		"cluster removed": {
+			oldOverrides: utils.OverridesMap{"cluster1": {replicas(float64(1))}},
+			newOverrides: utils.OverridesMap{},
+			expected: []ClusterOverridesChange{{
+				ClusterName: "cluster1",
+				Type:        ChangeRemoved,
+				Changes: []OverrideChange{
+					{Path: "/spec/replicas", Type: ChangeRemoved, Old: &utils.ClusterOverride{Path: "/spec/replicas", Value: float64(1)}},
+				},
+			}},
+		},
+		"entries added, removed and modified": {
+			oldOverrides: utils.OverridesMap{
+				"cluster1": {replicas(float64(1))},
+				"cluster2": {replicas(float64(1)), image},
+			},
+			newOverrides: utils.OverridesMap{
+				"cluster1": {replicas(float64(1)), image},
+				"cluster2": {replicas(float64(2))},
+			},
+			expected: []ClusterOverridesChange{
+				{
+					ClusterName: "cluster1",
+					Type:        ChangeModified,
+					Changes: []OverrideChange{
+						{Path: "/spec/image", Type: ChangeAdded, New: &image},
+					},
+				},
+				{
+					ClusterName: "cluster2",
+					Type:        ChangeModified,
+					Changes: []OverrideChange{
+						{Path: "/spec/image", Type: ChangeRemoved, Old: &image},
+						{
+							Path: "/spec/replicas",
+							Type: ChangeModified,
+							Old:  &utils.ClusterOverride{Path: "/spec/replicas", Value: float64(1)},
+							New:  &utils.ClusterOverride{Path: "/spec/replicas", Value: float64(2)},
+						},
+					},
+				},
+			},
+		},
+		"op modified": {
+			oldOverrides: utils.OverridesMap{"cluster1": {{Op: "add", Path: "/spec/image", Value: "foo:v1"}}},
+			newOverrides: utils.OverridesMap{"cluster1": {{Op: "replace", Path: "/spec/image", Value: "foo:v1"}}},
+			expected: []ClusterOverridesChange{{
+				ClusterName: "cluster1",
+				Type:        ChangeModified,
+				Changes: []OverrideChange{{
+					Path: "/spec/image",
+					Type: ChangeModified,
+					Old:  &utils.ClusterOverride{Op: "add", Path: "/spec/image", Value: "foo:v1"},
+					New:  &utils.ClusterOverride{Op: "replace", Path: "/spec/image", Value: "foo:v1"},
+				}},
+			}},
+		},
+	}
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			oldFedObject := newFedObjectWithOverrides(t, testCase.oldOverrides)
+			newFedObject := newFedObjectWithOverrides(t, testCase.newOverrides)
+			changes, err := DiffOverrides(oldFedObject, newFedObject)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(testCase.expected, changes) {
+				t.Fatalf("Expected %#v, got %#v", testCase.expected, changes)
+			}
+		})
+	}
+}
+
+func TestDiffOverridesReturnsErrorForInvalidOverrides(t *testing.T) {
+	oldFedObject := newFedObjectWithOverrides(t, utils.OverridesMap{})
+	newFedObject := newFedObjectWithOverrides(t, utils.OverridesMap{
+		"cluster1": {{Path: "/metadata/name", Value: "foo"}},
+	})
+	if _, err := DiffOverrides(oldFedObject, newFedObject); err == nil {
+		t.Fatalf("Expected an error for an invalid override path")
+	}
+}
+
+func TestDiffTemplate(t *testing.T) {
+	newFedObject := func(template map[string]interface{}) *unstructured.Unstructured {
+		return &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"spec": map[string]interface{}{
+					"template": template,
+				},
+			},
+		}
+	}
+	testCases := map[string]struct {
+		oldTemplate map[string]interface{}
+		newTemplate map[string]interface{}
+		expected    []TemplateChange
+	}{
+		"no changes": {
+			oldTemplate: map[string]interface{}{"data": map[string]interface{}{"key": "value"}},
+			newTemplate: map[string]interface{}{"data": map[string]interface{}{"key": "value"}},
+		},
+		"fields added, removed and modified": {
+			oldTemplate: map[string]interface{}{
+				"data": map[string]interface{}{
+					"modified": "old",
+					"removed":  "value",
+				},
+			},
+			newTemplate: map[string]interface{}{
+				"data": map[string]interface{}{
+					"added":    "value",
+					"modified": "new",
+				},
+				"metadata": map[string]interface{}{
+					"labels": map[string]interface{}{"app": "foo"},
+				},
+			},
+			expected: []TemplateChange{
+				{Path: "/data/added", Type: ChangeAdded, New: "value"},
+				{Path: "/data/modified", Type: ChangeModified, Old: "old", New: "new"},
+				{Path: "/data/removed", Type: ChangeRemoved, Old: "value"},
+				{Path: "/metadata", Type: ChangeAdded, New: map[string]interface{}{
+					"labels": map[string]interface{}{"app": "foo"},
+				}},
+			},
+		},
+		"lists are compared as a whole": {
+			oldTemplate: map[string]interface{}{"spec": map[string]interface{}{"args": []interface{}{"a", "b"}}},
+			newTemplate: map[string]interface{}{"spec": map[string]interface{}{"args": []interface{}{"a", "c"}}},
+			expected: []TemplateChange{
+				{Path: "/spec/args", Type: ChangeModified, Old: []interface{}{"a", "b"}, New: []interface{}{"a", "c"}},
+			},
+		},
+		"keys are escaped": {
+			oldTemplate: map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"annotations": map[string]interface{}{"example.io/owner": "foo"},
+				},
+			},
+			newTemplate: map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"annotations": map[string]interface{}{"example.io/owner": "bar"},
+				},
+			},
+			expected: []TemplateChange{
+				{Path: "/metadata/annotations/example.io~1owner", Type: ChangeModified, Old: "foo", New: "bar"},
+			},
+		},
+	}
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			changes, err := DiffTemplate(newFedObject(testCase.oldTemplate), newFedObject(testCase.newTemplate))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(testCase.expected, changes) {
+				t.Fatalf("Expected %#v, got %#v", testCase.expected, changes)
+			}
+		})
+	}
+}
@@ -47,8 +47,8 @@ type checkUnmanagedDispatcherImpl struct {
 	targetName utils.QualifiedName
 }
 
-func NewCheckUnmanagedDispatcher(clientAccessor clientAccessorFunc, targetGVK schema.GroupVersionKind, targetName utils.QualifiedName) CheckUnmanagedDispatcher {
-	dispatcher := newOperationDispatcher(clientAccessor, nil)
+func NewCheckUnmanagedDispatcher(ctx context.Context, clientAccessor clientAccessorFunc, targetGVK schema.GroupVersionKind, targetName utils.QualifiedName) CheckUnmanagedDispatcher {
+	dispatcher := newOperationDispatcher(ctx, clientAccessor, nil)
 	return &checkUnmanagedDispatcherImpl{
 		dispatcher: dispatcher,
 		targetGVK:  targetGVK,
@@ -67,14 +67,14 @@ func (d *checkUnmanagedDispatcherImpl) CheckRemovedOrUnlabeled(clusterName strin
 	d.dispatcher.incrementOperationsInitiated()
 	const op = "check for deletion of resource or removal of managed label from"
 	const opContinuous = "Checking for deletion of resource or removal of managed label from"
-	go d.dispatcher.clusterOperation(clusterName, op, func(client generic.Client) utils.ReconciliationStatus {
+	go d.dispatcher.clusterOperation(clusterName, op, func(ctx context.Context, client generic.Client) utils.ReconciliationStatus {
 		targetName := d.targetNameForCluster(clusterName)
 
 		klog.V(2).Infof(eventTemplate, opContinuous, d.targetGVK.Kind, targetName, clusterName)
 
 		clusterObj := &unstructured.Unstructured{}
 		clusterObj.SetGroupVersionKind(d.targetGVK)
-		err := client.Get(context.Background(), clusterObj, targetName.Namespace, targetName.Name)
+		err := client.Get(ctx, clusterObj, targetName.Namespace, targetName.Name)
 		if apierrors.IsNotFound(err) {
 			return utils.StatusAllOK
 		}
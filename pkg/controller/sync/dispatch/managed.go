@@ -26,20 +26,34 @@ import (
 	"time"
 
 	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/trace"
 
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/klog/v2"
 	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 
+	fedv1b1 "sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
 	"sigs.k8s.io/kubefed/pkg/client/generic"
 	"sigs.k8s.io/kubefed/pkg/controller/sync/status"
 	"sigs.k8s.io/kubefed/pkg/controller/utils"
 	"sigs.k8s.io/kubefed/pkg/metrics"
 )
 
+// updateDiffLogLevel is the verbosity at which the fields changed by
+// an update of a resource in a member cluster are logged.
+const updateDiffLogLevel klog.Level = 5
+
+// serverMetadataFields are the metadata fields maintained by the API
+// server that are not compared when determining the changes of an update
+// or of drift.
+var serverMetadataFields = []string{"uid", "resourceVersion", "generation", "creationTimestamp", "managedFields", "selfLink"}
+
 // FederatedResourceForDispatch is the subset of the FederatedResource
 // interface required for dispatching operations to managed resources.
 type FederatedResourceForDispatch interface {
@@ -50,9 +64,15 @@ type FederatedResourceForDispatch interface {
 	VersionForCluster(clusterName string) (string, error)
 	ObjectForCluster(clusterName string) (*unstructured.Unstructured, error)
 	ApplyOverrides(obj *unstructured.Unstructured, clusterName string) error
+	Transform(ctx context.Context, obj *unstructured.Unstructured, clusterName string) (*unstructured.Unstructured, error)
+	AddManagedMetadata(obj *unstructured.Unstructured)
+	PlacementAnnotationOutdated(clusterObj *unstructured.Unstructured) bool
 	RecordError(errorCode string, err error)
 	RecordEvent(reason, messageFmt string, args ...interface{})
 	IsNamespaceInHostCluster(clusterObj runtimeclient.Object) bool
+	IncludedFields() []string
+	ServerManagedMetadata() fedv1b1.ServerManagedMetadata
+	SecretOverridePaths(clusterName string) []string
 }
 
 // ManagedDispatcher dispatches operations to member clusters for resources
@@ -62,6 +82,13 @@ type ManagedDispatcher interface {
 
 	Create(clusterName string)
 	Update(clusterName string, clusterObj *unstructured.Unstructured)
+	Drain(clusterName string, clusterObj *unstructured.Unstructured, patch []byte)
+	DeferUpdates()
+	MergeMetadata()
+	StampCreatedNamespaces(labels, annotations map[string]string)
+	Trace(ctx context.Context, tracer trace.Tracer)
+	OnAuthenticationFailure(handler func(clusterName string))
+	Observe(clusterName string)
 	VersionMap() map[string]string
 	CollectedStatus() (status.CollectedPropagationStatus, status.CollectedResourceStatus)
 
@@ -78,25 +105,46 @@ type managedDispatcherImpl struct {
 	versionMap            map[string]string
 	statusMap             status.PropagationStatusMap
 	resourceStatusMap     map[string]interface{}
+	applyResults          map[string]status.ClusterApplyResult
 	skipAdoptingResources bool
+	adoptionPolicy        *fedv1b1.ResourceAdoptionPolicy
+
+	// Whether updates that would modify resources in member clusters
+	// are deferred rather than performed.
+	deferUpdates bool
+
+	// Whether labels and annotations of resources in member clusters
+	// are merged with those declared rather than replaced by them.
+	mergeMetadata bool
+
+	// Labels and annotations added to namespaces created in member
+	// clusters.
+	createdNamespaceLabels      map[string]string
+	createdNamespaceAnnotations map[string]string
 
 	// Track when resource updates are performed to allow indicating
 	// when a change was last propagated to member clusters.
 	resourcesUpdated bool
 
 	rawResourceStatusCollection bool
+
+	// Invoked with the name of a cluster that rejected the
+	// credentials with which an operation was performed.
+	authenticationFailureHandler func(clusterName string)
 }
 
-func NewManagedDispatcher(clientAccessor clientAccessorFunc, fedResource FederatedResourceForDispatch, skipAdoptingResources, rawResourceStatusCollection bool) ManagedDispatcher {
+func NewManagedDispatcher(ctx context.Context, clientAccessor clientAccessorFunc, fedResource FederatedResourceForDispatch, skipAdoptingResources bool, adoptionPolicy *fedv1b1.ResourceAdoptionPolicy, rawResourceStatusCollection bool) ManagedDispatcher {
 	d := &managedDispatcherImpl{
 		fedResource:                 fedResource,
 		versionMap:                  make(map[string]string),
 		statusMap:                   make(status.PropagationStatusMap),
 		resourceStatusMap:           make(map[string]interface{}),
+		applyResults:                make(map[string]status.ClusterApplyResult),
 		skipAdoptingResources:       skipAdoptingResources,
+		adoptionPolicy:              adoptionPolicy,
 		rawResourceStatusCollection: rawResourceStatusCollection,
 	}
-	d.dispatcher = newOperationDispatcher(clientAccessor, d)
+	d.dispatcher = newOperationDispatcher(ctx, clientAccessor, d)
 	d.unmanagedDispatcher = newUnmanagedDispatcher(d.dispatcher, d, fedResource.TargetGVK(), fedResource.TargetName())
 	return d
 }
@@ -144,7 +192,7 @@ func (d *managedDispatcherImpl) Create(clusterName string) {
 	start := time.Now()
 	d.dispatcher.incrementOperationsInitiated()
 	const op = "create"
-	go d.dispatcher.clusterOperation(clusterName, op, func(client generic.Client) utils.ReconciliationStatus {
+	go d.dispatcher.clusterOperation(clusterName, op, func(ctx context.Context, client generic.Client) utils.ReconciliationStatus {
 		d.recordEvent(clusterName, op, "Creating")
 
 		obj, err := d.fedResource.ObjectForCluster(clusterName)
@@ -154,14 +202,46 @@ func (d *managedDispatcherImpl) Create(clusterName string) {
 
 		err = d.fedResource.ApplyOverrides(obj, clusterName)
 		if err != nil {
-			return d.recordOperationError(status.ApplyOverridesFailed, clusterName, op, err)
+			return d.recordOperationError(applyOverridesFailure(err), clusterName, op, err)
+		}
+
+		obj, err = d.fedResource.Transform(ctx, obj, clusterName)
+		if err != nil {
+			return d.recordOperationError(status.TransformationFailed, clusterName, op, err)
 		}
 
-		err = client.Create(context.Background(), obj)
+		err = d.checkTargetType(obj)
+		if err != nil {
+			return d.recordOperationError(status.TargetTypeMismatch, clusterName, op, err)
+		}
+
+		_, err = d.setOwnerReferences(ctx, client, obj, nil)
+		if err != nil {
+			return d.recordOperationError(status.OwnerReferencesFailed, clusterName, op, err)
+		}
+
+		// Metadata is merged before a created namespace is marked so
+		// that the mark is not declared and survives later updates.
+		if d.isMergingMetadata() {
+			err = utils.MergeClusterMetadata(obj, nil)
+			if err != nil {
+				return d.recordOperationError(status.FieldRetentionFailed, clusterName, op, err)
+			}
+		}
+
+		if d.fedResource.TargetKind() == utils.NamespaceKind {
+			// Only a namespace created rather than adopted may later
+			// be deleted once it no longer contains managed resources.
+			utils.MarkCreatedNamespace(obj)
+			d.stampCreatedNamespace(clusterName, obj)
+		}
+
+		err = client.Create(ctx, obj)
 		if err == nil {
 			version := utils.ObjectVersion(obj)
 			d.recordVersion(clusterName, version)
 			d.RecordStatus(clusterName, status.CreationTimedOut, obj.Object[utils.StatusField])
+			d.recordApplyResult(clusterName, status.ApplyCreated, nil)
 			metrics.DispatchOperationDurationFromStart("create", start)
 			return utils.StatusAllOK
 		}
@@ -170,12 +250,12 @@ func (d *managedDispatcherImpl) Create(clusterName string) {
 		// already exists indicates ServerTimeout instead of AlreadyExists.
 		alreadyExists := apierrors.IsAlreadyExists(err) || d.fedResource.TargetKind() == utils.NamespaceKind && apierrors.IsServerTimeout(err)
 		if !alreadyExists {
-			return d.recordOperationError(status.CreationFailed, clusterName, op, err)
+			return d.recordOperationError(applyFailure(status.CreationFailed, status.CreationForbidden, status.CreationRejected, err), clusterName, op, err)
 		}
 
 		// Attempt to update the existing resource to ensure that it
 		// is labeled as a managed resource.
-		err = client.Get(context.Background(), obj, obj.GetNamespace(), obj.GetName())
+		err = client.Get(ctx, obj, obj.GetNamespace(), obj.GetName())
 		if err != nil {
 			wrappedErr := errors.Wrapf(err, "failed to retrieve object potentially requiring adoption")
 			return d.recordOperationError(status.RetrievalFailed, clusterName, op, wrappedErr)
@@ -188,6 +268,13 @@ func (d *managedDispatcherImpl) Create(clusterName string) {
 			return utils.StatusAllOK
 		}
 
+		// A resource that is already managed is not being adopted.
+		if err := utils.CheckAdoption(d.adoptionPolicy, obj); err != nil && !utils.HasManagedLabel(obj) && !d.fedResource.IsNamespaceInHostCluster(obj) {
+			wrappedErr := errors.Wrap(err, "Resource pre-exist in cluster and may not be adopted")
+			_ = d.recordOperationError(status.AdoptionRefused, clusterName, op, wrappedErr)
+			return utils.StatusAllOK
+		}
+
 		d.recordError(clusterName, op, errors.Errorf("An update will be attempted instead of a creation due to an existing resource"))
 		d.Update(clusterName, obj)
 		metrics.DispatchOperationDurationFromStart("update", start)
@@ -200,7 +287,7 @@ func (d *managedDispatcherImpl) Update(clusterName string, clusterObj *unstructu
 
 	d.dispatcher.incrementOperationsInitiated()
 	const op = "update"
-	go d.dispatcher.clusterOperation(clusterName, op, func(client generic.Client) utils.ReconciliationStatus {
+	go d.dispatcher.clusterOperation(clusterName, op, func(ctx context.Context, client generic.Client) utils.ReconciliationStatus {
 		if utils.IsExplicitlyUnmanaged(clusterObj) {
 			err := errors.Errorf("Unable to manage the object which has label %s: %s", utils.ManagedByKubeFedLabelKey, utils.UnmanagedByKubeFedLabelValue)
 			return d.recordOperationError(status.ManagedLabelFalse, clusterName, op, err)
@@ -210,43 +297,281 @@ func (d *managedDispatcherImpl) Update(clusterName string, clusterObj *unstructu
 		if err != nil {
 			return d.recordOperationError(status.ComputeResourceFailed, clusterName, op, err)
 		}
+		declaredAnnotations := obj.GetAnnotations()
 
 		err = RetainClusterFields(d.fedResource.TargetKind(), obj, clusterObj, d.fedResource.Object())
 		if err != nil {
 			wrappedErr := errors.Wrapf(err, "failed to retain fields")
 			return d.recordOperationError(status.FieldRetentionFailed, clusterName, op, wrappedErr)
 		}
+		if d.isMergingMetadata() {
+			// Annotations of the cluster object are merged once those
+			// declared are known instead of being retained.
+			resetRetainedAnnotations(obj, declaredAnnotations, clusterObj)
+		}
 
 		err = d.fedResource.ApplyOverrides(obj, clusterName)
 		if err != nil {
-			return d.recordOperationError(status.ApplyOverridesFailed, clusterName, op, err)
+			return d.recordOperationError(applyOverridesFailure(err), clusterName, op, err)
 		}
 
+		obj, err = d.fedResource.Transform(ctx, obj, clusterName)
+		if err != nil {
+			return d.recordOperationError(status.TransformationFailed, clusterName, op, err)
+		}
+
+		err = d.checkTargetType(obj)
+		if err != nil {
+			return d.recordOperationError(status.TargetTypeMismatch, clusterName, op, err)
+		}
+
+		// Only modify the included fields of an existing resource if
+		// the remaining fields are managed by another controller.
+		if includedFields := d.fedResource.IncludedFields(); len(includedFields) > 0 {
+			obj, err = utils.ApplyIncludedFields(obj, clusterObj, includedFields)
+			if err != nil {
+				wrappedErr := errors.Wrapf(err, "failed to apply included fields")
+				return d.recordOperationError(status.FieldRetentionFailed, clusterName, op, wrappedErr)
+			}
+			d.fedResource.AddManagedMetadata(obj)
+		}
+
+		if d.isMergingMetadata() {
+			err = utils.MergeClusterMetadata(obj, clusterObj)
+			if err != nil {
+				wrappedErr := errors.Wrapf(err, "failed to merge metadata")
+				return d.recordOperationError(status.FieldRetentionFailed, clusterName, op, wrappedErr)
+			}
+		}
+
+		// Metadata populated in the cluster is not removed.
+		utils.RetainServerManagedMetadata(obj, clusterObj, d.fedResource.ServerManagedMetadata())
+
+		// Nor is the metadata stamped on a namespace that was created
+		// rather than adopted, which is not declared.
+		if d.fedResource.TargetKind() == utils.NamespaceKind && utils.IsCreatedNamespace(clusterObj) {
+			d.stampCreatedNamespace(clusterName, obj)
+		}
+
+		ownerReferencesChanged, err := d.setOwnerReferences(ctx, client, obj, clusterObj)
+		if err != nil {
+			return d.recordOperationError(status.OwnerReferencesFailed, clusterName, op, err)
+		}
+
+		// A resource that was being drained when its cluster was
+		// selected again is no longer being removed.
+		utils.RemoveDrainStarted(obj)
+		// Nor is a resource whose federated resource was recreated
+		// within its deletion grace period.
+		utils.RemoveDeletionPending(obj)
+
 		version, err := d.fedResource.VersionForCluster(clusterName)
 		if err != nil {
 			return d.recordOperationError(status.VersionRetrievalFailed, clusterName, op, err)
 		}
-		if !utils.ObjectNeedsUpdate(obj, clusterObj, version) {
+		// An outdated placement annotation or owner reference, an
+		// interrupted drain or a cancelled deletion requires an update
+		// even if the recorded version of the resource is current.
+		if !utils.ObjectNeedsUpdate(obj, clusterObj, version) && !d.fedResource.PlacementAnnotationOutdated(clusterObj) &&
+			!ownerReferencesChanged && !utils.IsDraining(clusterObj) && !utils.IsDeletionPending(clusterObj) {
 			// Resource is current
 			d.RecordStatus(clusterName, status.UpdateTimedOut, clusterObj.Object[utils.StatusField])
+			d.recordApplyResult(clusterName, status.ApplyUnchanged, nil)
+			return utils.StatusAllOK
+		}
+
+		if d.isDeferringUpdates() {
+			d.RecordStatus(clusterName, status.Deferred, clusterObj.Object[utils.StatusField])
 			return utils.StatusAllOK
 		}
 
 		// Only record an event if the resource is not current
 		d.recordEvent(clusterName, op, "Updating")
 
-		err = client.Update(context.Background(), obj)
+		// The changes are determined before the update since it
+		// replaces the contents of obj with the response. Fields set
+		// from Secrets are redacted.
+		var changedPaths string
+		if klog.V(updateDiffLogLevel).Enabled() {
+			changes := utils.RedactFieldChanges(UpdateChanges(clusterObj, obj), d.fedResource.SecretOverridePaths(clusterName))
+			changedPaths = utils.FormatChangedPaths(changes)
+		}
+
+		err = client.Update(ctx, obj)
 		if err != nil {
-			return d.recordOperationError(status.UpdateFailed, clusterName, op, err)
+			return d.recordOperationError(applyFailure(status.UpdateFailed, status.UpdateForbidden, status.UpdateRejected, err), clusterName, op, err)
 		}
+		klog.V(updateDiffLogLevel).Infof("Updated %s %q in cluster %q, changed: %s", d.fedResource.TargetKind(), d.fedResource.TargetName(), clusterName, changedPaths)
 		d.RecordStatus(clusterName, status.UpdateTimedOut, obj.Object[utils.StatusField])
 		d.setResourcesUpdated()
+		d.recordApplyResult(clusterName, status.ApplyUpdated, nil)
 		version = utils.ObjectVersion(obj)
 		d.recordVersion(clusterName, version)
 		return utils.StatusAllOK
 	})
 }
 
+// UpdateChanges returns the changes that updating the given cluster
+// object to the given desired object makes to it, ignoring status and
+// the metadata maintained by the API server.
+func UpdateChanges(clusterObj, desiredObj *unstructured.Unstructured) []utils.FieldChange {
+	return utils.DiffFields(comparableFields(clusterObj), comparableFields(desiredObj))
+}
+
+// DriftChanges returns the changes required for the given cluster
+// object to have the content of the given desired object, ignoring
+// status, the metadata maintained by the API server and fields that
+// the desired object does not set.
+func DriftChanges(clusterObj, desiredObj *unstructured.Unstructured) []utils.FieldChange {
+	return utils.DiffDesiredFields(comparableFields(desiredObj), comparableFields(clusterObj))
+}
+
+// comparableFields returns the fields of a copy of the given object
+// without status and the metadata maintained by the API server.
+func comparableFields(obj *unstructured.Unstructured) map[string]interface{} {
+	obj = obj.DeepCopy()
+	unstructured.RemoveNestedField(obj.Object, utils.StatusField)
+	for _, field := range serverMetadataFields {
+		unstructured.RemoveNestedField(obj.Object, "metadata", field)
+	}
+	return obj.Object
+}
+
+// DeferUpdates causes subsequent updates that would modify a resource
+// in a member cluster to be recorded as deferred instead of being
+// performed.
+func (d *managedDispatcherImpl) DeferUpdates() {
+	d.Lock()
+	defer d.Unlock()
+	d.deferUpdates = true
+}
+
+func (d *managedDispatcherImpl) isDeferringUpdates() bool {
+	d.RLock()
+	defer d.RUnlock()
+	return d.deferUpdates
+}
+
+// MergeMetadata causes subsequent creations and updates to preserve
+// labels and annotations added to resources in member clusters,
+// removing only those previously declared that are no longer
+// declared.
+func (d *managedDispatcherImpl) MergeMetadata() {
+	d.Lock()
+	defer d.Unlock()
+	d.mergeMetadata = true
+}
+
+func (d *managedDispatcherImpl) isMergingMetadata() bool {
+	d.RLock()
+	defer d.RUnlock()
+	return d.mergeMetadata
+}
+
+// StampCreatedNamespaces causes the given labels and annotations to be
+// added to namespaces that are subsequently created in member
+// clusters, and to be kept on those previously created when they are
+// updated.
+func (d *managedDispatcherImpl) StampCreatedNamespaces(labels, annotations map[string]string) {
+	d.Lock()
+	defer d.Unlock()
+	d.createdNamespaceLabels = labels
+	d.createdNamespaceAnnotations = annotations
+}
+
+// stampCreatedNamespace adds the labels and annotations configured for
+// created namespaces to the given namespace. Those declared by the
+// federated namespace take precedence, and a conflict is reported.
+func (d *managedDispatcherImpl) stampCreatedNamespace(clusterName string, obj *unstructured.Unstructured) {
+	d.RLock()
+	labels, annotations := d.createdNamespaceLabels, d.createdNamespaceAnnotations
+	d.RUnlock()
+	if conflicts := utils.StampCreatedNamespace(obj, labels, annotations); len(conflicts) > 0 {
+		d.fedResource.RecordError("CreatedNamespaceMetadataConflict", errors.Errorf(
+			"The declared values of %s of namespace %q in cluster %q take precedence over those configured for created namespaces",
+			strings.Join(conflicts, ", "), obj.GetName(), clusterName))
+	}
+}
+
+// Trace causes each subsequent operation in a member cluster to be
+// recorded as a span of the given tracer that is a child of the span
+// in ctx. It must be called before any operation is dispatched.
+func (d *managedDispatcherImpl) Trace(ctx context.Context, tracer trace.Tracer) {
+	d.dispatcher.trace(ctx, tracer)
+}
+
+// OnAuthenticationFailure causes the given handler to be invoked
+// with the name of each cluster that rejects the credentials with
+// which a subsequent operation is performed. It must be called before
+// any operation is dispatched.
+func (d *managedDispatcherImpl) OnAuthenticationFailure(handler func(clusterName string)) {
+	d.authenticationFailureHandler = handler
+}
+
+// resetRetainedAnnotations replaces the annotations retained from the
+// cluster object with the declared annotations. The paths of applied
+// overrides are still retained so that paths no longer overridden can
+// be reset.
+func resetRetainedAnnotations(obj *unstructured.Unstructured, declaredAnnotations map[string]string, clusterObj *unstructured.Unstructured) {
+	annotations := make(map[string]string, len(declaredAnnotations)+1)
+	for key, value := range declaredAnnotations {
+		annotations[key] = value
+	}
+	if value, ok := clusterObj.GetAnnotations()[utils.AppliedOverridePathsAnnotation]; ok {
+		annotations[utils.AppliedOverridePathsAnnotation] = value
+	}
+	obj.SetAnnotations(annotations)
+}
+
+// Observe records the status of the resource in the given cluster
+// without modifying it. The resource is retrieved from the cluster
+// rather than from the cache since an observed resource is not
+// required to have the managed label.
+func (d *managedDispatcherImpl) Observe(clusterName string) {
+	d.RecordStatus(clusterName, status.ObservationTimedOut, nil)
+
+	d.dispatcher.incrementOperationsInitiated()
+	const op = "observe"
+	go d.dispatcher.clusterOperation(clusterName, op, func(ctx context.Context, client generic.Client) utils.ReconciliationStatus {
+		targetName := d.unmanagedDispatcher.targetNameForCluster(clusterName)
+		clusterObj := &unstructured.Unstructured{}
+		clusterObj.SetGroupVersionKind(d.fedResource.TargetGVK())
+		err := client.Get(ctx, clusterObj, targetName.Namespace, targetName.Name)
+		if apierrors.IsNotFound(err) {
+			d.RecordStatus(clusterName, status.ResourceNotFound, nil)
+			return utils.StatusAllOK
+		}
+		if err != nil {
+			return d.recordOperationError(status.RetrievalFailed, clusterName, op, err)
+		}
+		d.RecordStatus(clusterName, status.ClusterPropagationOK, clusterObj.Object[utils.StatusField])
+		return utils.StatusAllOK
+	})
+}
+
+// Drain applies the given JSON merge patch to the resource in the
+// given cluster to drain it before its removal. The patch is expected
+// to record when draining started so that the resource can be deleted
+// once its drain duration has elapsed.
+func (d *managedDispatcherImpl) Drain(clusterName string, clusterObj *unstructured.Unstructured, patch []byte) {
+	d.RecordStatus(clusterName, status.Draining, clusterObj.Object[utils.StatusField])
+
+	d.dispatcher.incrementOperationsInitiated()
+	const op = "drain"
+	go d.dispatcher.clusterOperation(clusterName, op, func(ctx context.Context, client generic.Client) utils.ReconciliationStatus {
+		d.recordEvent(clusterName, op, "Draining")
+
+		// Avoid mutating the resource in the informer cache
+		obj := clusterObj.DeepCopy()
+		err := client.Patch(ctx, obj, runtimeclient.RawPatch(types.MergePatchType, patch))
+		if err != nil {
+			return d.recordOperationError(status.DrainFailed, clusterName, op, err)
+		}
+		d.RecordStatus(clusterName, status.Draining, obj.Object[utils.StatusField])
+		return utils.StatusAllOK
+	})
+}
+
 func (d *managedDispatcherImpl) Delete(clusterName string, opts ...runtimeclient.DeleteOption) {
 	d.RecordStatus(clusterName, status.DeletionTimedOut, nil)
 
@@ -259,6 +584,18 @@ func (d *managedDispatcherImpl) RemoveManagedLabel(clusterName string, clusterOb
 	d.unmanagedDispatcher.RemoveManagedLabel(clusterName, clusterObj)
 }
 
+func (d *managedDispatcherImpl) RemoveManagedMetadata(clusterName string, clusterObj *unstructured.Unstructured, removeFunc func(obj *unstructured.Unstructured)) {
+	d.RecordStatus(clusterName, status.LabelRemovalTimedOut, clusterObj.Object[utils.StatusField])
+
+	d.unmanagedDispatcher.RemoveManagedMetadata(clusterName, clusterObj, removeFunc)
+}
+
+func (d *managedDispatcherImpl) SetDeletionPending(clusterName string, clusterObj *unstructured.Unstructured, until time.Time, deleteOptions string) {
+	// Resources are only marked as pending deletion once their
+	// federated resource is deleted, when no status is recorded.
+	d.unmanagedDispatcher.SetDeletionPending(clusterName, clusterObj, until, deleteOptions)
+}
+
 func (d *managedDispatcherImpl) RecordClusterError(propStatus status.PropagationStatus, clusterName string, err error) {
 	d.fedResource.RecordError(string(propStatus), err)
 	d.RecordStatus(clusterName, propStatus, nil)
@@ -275,12 +612,113 @@ func (d *managedDispatcherImpl) RecordStatus(clusterName string, propStatus stat
 	}
 }
 
+// checkTargetType ensures that the object to be applied is of the
+// target type of the federated resource so that a misconfigured
+// FederatedTypeConfig or template cannot result in objects of the
+// wrong type being applied. The version is not compared since all
+// versions of a group and kind address the same resources.
+func (d *managedDispatcherImpl) checkTargetType(obj *unstructured.Unstructured) error {
+	expected := d.fedResource.TargetGVK().GroupKind()
+	actual := obj.GroupVersionKind().GroupKind()
+	if actual != expected {
+		return errors.Errorf("the object to apply is a %q but the target type of the FederatedTypeConfig is %q", actual, expected)
+	}
+	return nil
+}
+
+// setOwnerReferences sets the owner references recorded for the
+// federated resource on the given object, and returns whether they
+// differ from those of the given cluster object, if any. The uid of an
+// owner is taken from the reference of the cluster object to the same
+// owner, and the owner is otherwise retrieved from the cluster, so
+// that owners are only retrieved when a reference is added. When an
+// owner is recreated, the garbage collector of the cluster removes the
+// reference to the previous owner from the cluster object (or deletes
+// the object if it has no other owner), and the new owner is then
+// retrieved. An owner that does not (yet) exist in the cluster is an
+// error so that the operation is retried once the owner has been
+// propagated.
+func (d *managedDispatcherImpl) setOwnerReferences(ctx context.Context, client generic.Client, obj, clusterObj *unstructured.Unstructured) (bool, error) {
+	federatedOwnerReferences, err := utils.GetFederatedOwnerReferences(d.fedResource.Object())
+	if err != nil {
+		return false, err
+	}
+	if len(federatedOwnerReferences) == 0 {
+		return false, nil
+	}
+	var clusterOwnerReferences []metav1.OwnerReference
+	if clusterObj != nil {
+		clusterOwnerReferences = clusterObj.GetOwnerReferences()
+	}
+	var ownerReferences []metav1.OwnerReference
+	for _, federatedOwnerReference := range federatedOwnerReferences {
+		uid := federatedOwnerReference.ClusterUID(clusterOwnerReferences)
+		if len(uid) == 0 {
+			owner := &unstructured.Unstructured{}
+			owner.SetAPIVersion(federatedOwnerReference.APIVersion)
+			owner.SetKind(federatedOwnerReference.Kind)
+			err := client.Get(ctx, owner, obj.GetNamespace(), federatedOwnerReference.Name)
+			if err != nil {
+				return false, errors.Wrapf(err, "failed to retrieve owner %s %q", federatedOwnerReference.Kind, federatedOwnerReference.Name)
+			}
+			uid = owner.GetUID()
+		}
+		ownerReferences = append(ownerReferences, federatedOwnerReference.OwnerReference(uid))
+	}
+	obj.SetOwnerReferences(ownerReferences)
+	return !equality.Semantic.DeepEqual(ownerReferences, clusterOwnerReferences), nil
+}
+
+// applyOverridesFailure returns the status recorded for a cluster when
+// the overrides for the cluster could not be applied.
+func applyOverridesFailure(err error) status.PropagationStatus {
+	if utils.IsOverrideSourceNotFound(err) {
+		return status.OverrideSourceNotFound
+	}
+	return status.ApplyOverridesFailed
+}
+
+// applyFailure returns the status recorded for a cluster when creating
+// or updating the resource in the cluster failed, which is the given
+// rejected status if the cluster rejected the resource such that
+// retrying cannot succeed, or the given forbidden status if the
+// cluster forbade the request such that retrying can only succeed
+// once the cluster is reconfigured.
+func applyFailure(failed, forbidden, rejected status.PropagationStatus, err error) status.PropagationStatus {
+	switch {
+	case utils.IsPermanentApplyError(err):
+		return rejected
+	case utils.IsForbiddenApplyError(err):
+		return forbidden
+	}
+	return failed
+}
+
 func (d *managedDispatcherImpl) recordOperationError(propStatus status.PropagationStatus, clusterName, operation string, err error) utils.ReconciliationStatus {
 	d.recordError(clusterName, operation, err)
 	d.RecordStatus(clusterName, propStatus, nil)
+	if d.authenticationFailureHandler != nil && utils.IsAuthenticationError(err) {
+		d.authenticationFailureHandler(clusterName)
+	}
+	// Only creation and update apply the resource to a cluster.
+	if operation == "create" || operation == "update" {
+		d.recordApplyResult(clusterName, status.ApplyFailed, err)
+	}
 	return utils.StatusError
 }
 
+// recordApplyResult records the outcome of applying to the given
+// cluster.
+func (d *managedDispatcherImpl) recordApplyResult(clusterName string, result status.ApplyResult, err error) {
+	applyResult := status.ClusterApplyResult{Result: result}
+	if err != nil {
+		applyResult.Error = err.Error()
+	}
+	d.Lock()
+	defer d.Unlock()
+	d.applyResults[clusterName] = applyResult
+}
+
 func (d *managedDispatcherImpl) recordError(clusterName, operation string, err error) {
 	targetName := d.unmanagedDispatcher.targetNameForCluster(clusterName)
 	args := []interface{}{operation, d.fedResource.TargetKind(), targetName, clusterName}
@@ -335,11 +773,18 @@ func (d *managedDispatcherImpl) CollectedStatus() (status.CollectedPropagationSt
 	for key, value := range d.resourceStatusMap {
 		resourceStatusMap[key] = value
 	}
-	return status.CollectedPropagationStatus{
-			StatusMap:        statusMap,
-			ResourcesUpdated: d.resourcesUpdated,
-		}, status.CollectedResourceStatus{
-			StatusMap:        resourceStatusMap,
-			ResourcesUpdated: d.resourcesUpdated,
-		}
+	applyResults := make(map[string]status.ClusterApplyResult)
+	for key, value := range d.applyResults {
+		applyResults[key] = value
+	}
+	collectedStatus := status.CollectedPropagationStatus{
+		StatusMap:        statusMap,
+		ResourcesUpdated: d.resourcesUpdated,
+		ApplyResults:     applyResults,
+	}
+	collectedResourceStatus := status.CollectedResourceStatus{
+		StatusMap:        resourceStatusMap,
+		ResourcesUpdated: d.resourcesUpdated,
+	}
+	return collectedStatus, collectedResourceStatus
 }
@@ -0,0 +1,1163 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dispatch
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"io"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/pkg/errors"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	fedv1b1 "sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
+	"sigs.k8s.io/kubefed/pkg/client/generic"
+	"sigs.k8s.io/kubefed/pkg/controller/sync/status"
+	"sigs.k8s.io/kubefed/pkg/controller/utils"
+)
+
+type fakeFederatedResource struct {
+	targetGVK                   schema.GroupVersionKind
+	obj                         *unstructured.Unstructured
+	version                     string
+	placementAnnotationOutdated bool
+	includedFields              []string
+	secretOverridePaths         []string
+	errors                      []string
+}
+
+func (f *fakeFederatedResource) TargetName() utils.QualifiedName {
+	return utils.QualifiedName{Namespace: f.obj.GetNamespace(), Name: f.obj.GetName()}
+}
+func (f *fakeFederatedResource) TargetKind() string                       { return f.targetGVK.Kind }
+func (f *fakeFederatedResource) TargetGVK() schema.GroupVersionKind       { return f.targetGVK }
+func (f *fakeFederatedResource) Object() *unstructured.Unstructured       { return f.obj }
+func (f *fakeFederatedResource) VersionForCluster(string) (string, error) { return f.version, nil }
+func (f *fakeFederatedResource) ObjectForCluster(string) (*unstructured.Unstructured, error) {
+	return f.obj.DeepCopy(), nil
+}
+func (f *fakeFederatedResource) ApplyOverrides(*unstructured.Unstructured, string) error { return nil }
+func (f *fakeFederatedResource) Transform(_ context.Context, obj *unstructured.Unstructured, _ string) (*unstructured.Unstructured, error) {
+	return obj, nil
+}
+func (f *fakeFederatedResource) AddManagedMetadata(obj *unstructured.Unstructured) {
+	utils.AddManagedLabel(obj)
+}
+func (f *fakeFederatedResource) PlacementAnnotationOutdated(*unstructured.Unstructured) bool {
+	return f.placementAnnotationOutdated
+}
+func (f *fakeFederatedResource) RecordError(errorCode string, _ error) {
+	f.errors = append(f.errors, errorCode)
+}
+func (f *fakeFederatedResource) RecordEvent(string, string, ...interface{})         {}
+func (f *fakeFederatedResource) IsNamespaceInHostCluster(runtimeclient.Object) bool { return false }
+func (f *fakeFederatedResource) IncludedFields() []string                           { return f.includedFields }
+func (f *fakeFederatedResource) ServerManagedMetadata() fedv1b1.ServerManagedMetadata {
+	return fedv1b1.ServerManagedMetadata{}
+}
+func (f *fakeFederatedResource) SecretOverridePaths(string) []string {
+	return f.secretOverridePaths
+}
+
+// recordingClient counts the writes made through it and fails them
+// with err if it is set. Methods that are not overridden panic via the
+// nil embedded interface.
+type recordingClient struct {
+	generic.Client
+	writes int32
+	err    error
+}
+
+func (c *recordingClient) Create(context.Context, runtimeclient.Object) error {
+	atomic.AddInt32(&c.writes, 1)
+	return c.err
+}
+
+func (c *recordingClient) Update(context.Context, runtimeclient.Object) error {
+	atomic.AddInt32(&c.writes, 1)
+	return c.err
+}
+
+// existingResourceClient simulates a member cluster in which the
+// resource to be created already exists.
+type existingResourceClient struct {
+	recordingClient
+	existing *unstructured.Unstructured
+}
+
+func (c *existingResourceClient) Create(context.Context, runtimeclient.Object) error {
+	return apierrors.NewAlreadyExists(schema.GroupResource{Resource: "configmaps"}, c.existing.GetName())
+}
+
+func (c *existingResourceClient) Get(_ context.Context, obj runtimeclient.Object, _, _ string) error {
+	c.existing.DeepCopyInto(obj.(*unstructured.Unstructured))
+	return nil
+}
+
+// ownerClient simulates a member cluster containing the owners with
+// the given uids, keyed by kind and name, and records the created or
+// updated object and the number of owners retrieved.
+type ownerClient struct {
+	recordingClient
+	owners  map[string]types.UID
+	gets    int32
+	created *unstructured.Unstructured
+	updated *unstructured.Unstructured
+}
+
+func (c *ownerClient) Create(ctx context.Context, obj runtimeclient.Object) error {
+	c.created = obj.(*unstructured.Unstructured).DeepCopy()
+	return c.recordingClient.Create(ctx, obj)
+}
+
+func (c *ownerClient) Update(ctx context.Context, obj runtimeclient.Object) error {
+	c.updated = obj.(*unstructured.Unstructured).DeepCopy()
+	return c.recordingClient.Update(ctx, obj)
+}
+
+func (c *ownerClient) Get(_ context.Context, obj runtimeclient.Object, _, name string) error {
+	atomic.AddInt32(&c.gets, 1)
+	owner := obj.(*unstructured.Unstructured)
+	uid, ok := c.owners[owner.GetKind()+"/"+name]
+	if !ok {
+		return apierrors.NewNotFound(schema.GroupResource{Resource: owner.GetKind()}, name)
+	}
+	owner.SetName(name)
+	owner.SetUID(uid)
+	return nil
+}
+
+// observedClient simulates a member cluster containing the given
+// object, if any, and fails retrieval with err if it is set.
+type observedClient struct {
+	recordingClient
+	existing *unstructured.Unstructured
+	getErr   error
+}
+
+func (c *observedClient) Get(_ context.Context, obj runtimeclient.Object, _, name string) error {
+	if c.getErr != nil {
+		return c.getErr
+	}
+	if c.existing == nil {
+		return apierrors.NewNotFound(schema.GroupResource{Resource: "configmaps"}, name)
+	}
+	c.existing.DeepCopyInto(obj.(*unstructured.Unstructured))
+	return nil
+}
+
+// contextClient fails the operations made through it with the error
+// of their context.
+type contextClient struct {
+	recordingClient
+}
+
+func (c *contextClient) Create(ctx context.Context, obj runtimeclient.Object) error {
+	atomic.AddInt32(&c.writes, 1)
+	return ctx.Err()
+}
+
+func (c *contextClient) Get(ctx context.Context, _ runtimeclient.Object, _, _ string) error {
+	return ctx.Err()
+}
+
+func TestOperationsUseDispatcherContext(t *testing.T) {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("v1")
+	obj.SetKind("ConfigMap")
+	obj.SetNamespace("foo")
+	obj.SetName("bar")
+
+	testCases := map[string]struct {
+		dispatch       func(d ManagedDispatcher)
+		expectedStatus status.PropagationStatus
+	}{
+		"create": {
+			dispatch:       func(d ManagedDispatcher) { d.Create("cluster1") },
+			expectedStatus: status.CreationFailed,
+		},
+		"observe": {
+			dispatch:       func(d ManagedDispatcher) { d.Observe("cluster1") },
+			expectedStatus: status.RetrievalFailed,
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			fedResource := &fakeFederatedResource{
+				targetGVK: schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"},
+				obj:       obj,
+			}
+			clientAccessor := func(string) (generic.Client, error) {
+				return &contextClient{}, nil
+			}
+			// Operations of a cancelled reconciliation fail.
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			d := NewManagedDispatcher(ctx, clientAccessor, fedResource, false, nil, false)
+
+			tc.dispatch(d)
+			if _, err := d.Wait(); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			propStatus, _ := d.CollectedStatus()
+			if actual := propStatus.StatusMap["cluster1"]; actual != tc.expectedStatus {
+				t.Fatalf("Expected status %q, got %q", tc.expectedStatus, actual)
+			}
+		})
+	}
+}
+
+func TestTargetTypeMismatchPreventsApply(t *testing.T) {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("v1")
+	obj.SetKind("Secret")
+	obj.SetNamespace("foo")
+	obj.SetName("bar")
+
+	testCases := map[string]func(d ManagedDispatcher){
+		"create": func(d ManagedDispatcher) {
+			d.Create("cluster1")
+		},
+		"update": func(d ManagedDispatcher) {
+			d.Update("cluster1", obj.DeepCopy())
+		},
+	}
+	for testName, dispatch := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			fedResource := &fakeFederatedResource{
+				targetGVK: schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"},
+				obj:       obj,
+			}
+			client := &recordingClient{}
+			clientAccessor := func(string) (generic.Client, error) {
+				return client, nil
+			}
+			d := NewManagedDispatcher(context.Background(), clientAccessor, fedResource, false, nil, false)
+
+			dispatch(d)
+			ok, err := d.Wait()
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if ok {
+				t.Fatalf("Expected the operation to fail")
+			}
+			if writes := atomic.LoadInt32(&client.writes); writes != 0 {
+				t.Fatalf("Expected no writes to the member cluster, got %d", writes)
+			}
+			propStatus, _ := d.CollectedStatus()
+			if actual := propStatus.StatusMap["cluster1"]; actual != status.TargetTypeMismatch {
+				t.Fatalf("Expected status %q, got %q", status.TargetTypeMismatch, actual)
+			}
+		})
+	}
+}
+
+func TestDeferUpdates(t *testing.T) {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("v1")
+	obj.SetKind("ConfigMap")
+	obj.SetNamespace("foo")
+	obj.SetName("bar")
+
+	testCases := map[string]struct {
+		dispatch       func(d ManagedDispatcher)
+		expectedWrites int32
+		expectedStatus status.PropagationStatus
+	}{
+		"update is deferred": {
+			dispatch: func(d ManagedDispatcher) {
+				d.Update("cluster1", obj.DeepCopy())
+			},
+			expectedStatus: status.Deferred,
+		},
+		"create is not deferred": {
+			dispatch: func(d ManagedDispatcher) {
+				d.Create("cluster1")
+			},
+			expectedWrites: 1,
+			expectedStatus: status.ClusterPropagationOK,
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			fedResource := &fakeFederatedResource{
+				targetGVK: schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"},
+				obj:       obj,
+			}
+			client := &recordingClient{}
+			clientAccessor := func(string) (generic.Client, error) {
+				return client, nil
+			}
+			d := NewManagedDispatcher(context.Background(), clientAccessor, fedResource, false, nil, false)
+			d.DeferUpdates()
+
+			tc.dispatch(d)
+			if _, err := d.Wait(); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if writes := atomic.LoadInt32(&client.writes); writes != tc.expectedWrites {
+				t.Fatalf("Expected %d writes to the member cluster, got %d", tc.expectedWrites, writes)
+			}
+			propStatus, _ := d.CollectedStatus()
+			if actual := propStatus.StatusMap["cluster1"]; actual != tc.expectedStatus {
+				t.Fatalf("Expected status %q, got %q", tc.expectedStatus, actual)
+			}
+		})
+	}
+}
+
+func TestUpdateOfOutdatedPlacementAnnotation(t *testing.T) {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("v1")
+	obj.SetKind("ConfigMap")
+	obj.SetNamespace("foo")
+	obj.SetName("bar")
+	obj.SetResourceVersion("1")
+
+	testCases := map[string]struct {
+		placementAnnotationOutdated bool
+		expectedWrites              int32
+		expectedApplyResult         status.ApplyResult
+	}{
+		"current resource is not updated": {
+			expectedApplyResult: status.ApplyUnchanged,
+		},
+		"current resource with an outdated placement annotation is updated": {
+			placementAnnotationOutdated: true,
+			expectedWrites:              1,
+			expectedApplyResult:         status.ApplyUpdated,
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			fedResource := &fakeFederatedResource{
+				targetGVK:                   schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"},
+				obj:                         obj,
+				version:                     utils.ObjectVersion(obj),
+				placementAnnotationOutdated: tc.placementAnnotationOutdated,
+			}
+			client := &recordingClient{}
+			clientAccessor := func(string) (generic.Client, error) {
+				return client, nil
+			}
+			d := NewManagedDispatcher(context.Background(), clientAccessor, fedResource, false, nil, false)
+
+			d.Update("cluster1", obj.DeepCopy())
+			if _, err := d.Wait(); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if writes := atomic.LoadInt32(&client.writes); writes != tc.expectedWrites {
+				t.Fatalf("Expected %d writes to the member cluster, got %d", tc.expectedWrites, writes)
+			}
+			propStatus, _ := d.CollectedStatus()
+			if actual := propStatus.ApplyResults["cluster1"].Result; actual != tc.expectedApplyResult {
+				t.Fatalf("Expected apply result %q, got %q", tc.expectedApplyResult, actual)
+			}
+		})
+	}
+}
+
+func TestObserve(t *testing.T) {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("v1")
+	obj.SetKind("ConfigMap")
+	obj.SetNamespace("foo")
+	obj.SetName("bar")
+
+	clusterObj := obj.DeepCopy()
+	clusterObj.Object[utils.StatusField] = map[string]interface{}{"replicas": int64(1)}
+
+	testCases := map[string]struct {
+		existing               *unstructured.Unstructured
+		getErr                 error
+		expectedStatus         status.PropagationStatus
+		expectedResourceStatus interface{}
+	}{
+		"existing resource": {
+			existing:               clusterObj,
+			expectedStatus:         status.ClusterPropagationOK,
+			expectedResourceStatus: clusterObj.Object[utils.StatusField],
+		},
+		"missing resource": {
+			expectedStatus: status.ResourceNotFound,
+		},
+		"failed retrieval": {
+			getErr:         errors.New("get failed"),
+			expectedStatus: status.RetrievalFailed,
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			fedResource := &fakeFederatedResource{
+				targetGVK: schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"},
+				obj:       obj,
+			}
+			client := &observedClient{existing: tc.existing, getErr: tc.getErr}
+			clientAccessor := func(string) (generic.Client, error) {
+				return client, nil
+			}
+			d := NewManagedDispatcher(context.Background(), clientAccessor, fedResource, false, nil, true)
+
+			d.Observe("cluster1")
+			if _, err := d.Wait(); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if writes := atomic.LoadInt32(&client.writes); writes != 0 {
+				t.Fatalf("Expected no writes to the member cluster, got %d", writes)
+			}
+			propStatus, resourceStatus := d.CollectedStatus()
+			if actual := propStatus.StatusMap["cluster1"]; actual != tc.expectedStatus {
+				t.Fatalf("Expected status %q, got %q", tc.expectedStatus, actual)
+			}
+			if actual := resourceStatus.StatusMap["cluster1"]; !reflect.DeepEqual(tc.expectedResourceStatus, actual) {
+				t.Fatalf("Expected resource status %v, got %v", tc.expectedResourceStatus, actual)
+			}
+		})
+	}
+}
+
+func TestApplyResults(t *testing.T) {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("v1")
+	obj.SetKind("ConfigMap")
+	obj.SetNamespace("foo")
+	obj.SetName("bar")
+
+	clusterObj := obj.DeepCopy()
+	clusterObj.SetResourceVersion("1")
+
+	drainingClusterObj := clusterObj.DeepCopy()
+	drainingClusterObj.SetAnnotations(map[string]string{utils.DrainStartedAnnotation: "2024-05-01T12:00:00Z"})
+
+	testCases := map[string]struct {
+		dispatch       func(d ManagedDispatcher)
+		version        string
+		clientErr      error
+		expectedResult status.ApplyResult
+		expectedError  bool
+	}{
+		"create": {
+			dispatch: func(d ManagedDispatcher) {
+				d.Create("cluster1")
+			},
+			expectedResult: status.ApplyCreated,
+		},
+		"update": {
+			dispatch: func(d ManagedDispatcher) {
+				d.Update("cluster1", clusterObj.DeepCopy())
+			},
+			expectedResult: status.ApplyUpdated,
+		},
+		"update of current resource": {
+			dispatch: func(d ManagedDispatcher) {
+				d.Update("cluster1", clusterObj.DeepCopy())
+			},
+			version:        utils.ObjectVersion(clusterObj),
+			expectedResult: status.ApplyUnchanged,
+		},
+		"update of current resource being drained": {
+			dispatch: func(d ManagedDispatcher) {
+				d.Update("cluster1", drainingClusterObj.DeepCopy())
+			},
+			version:        utils.ObjectVersion(drainingClusterObj),
+			expectedResult: status.ApplyUpdated,
+		},
+		"failed update": {
+			dispatch: func(d ManagedDispatcher) {
+				d.Update("cluster1", clusterObj.DeepCopy())
+			},
+			clientErr:      errors.New("update failed"),
+			expectedResult: status.ApplyFailed,
+			expectedError:  true,
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			fedResource := &fakeFederatedResource{
+				targetGVK: schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"},
+				obj:       obj,
+				version:   tc.version,
+			}
+			client := &recordingClient{err: tc.clientErr}
+			clientAccessor := func(string) (generic.Client, error) {
+				return client, nil
+			}
+			d := NewManagedDispatcher(context.Background(), clientAccessor, fedResource, false, nil, false)
+
+			tc.dispatch(d)
+			if _, err := d.Wait(); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			propStatus, _ := d.CollectedStatus()
+			applyResult := propStatus.ApplyResults["cluster1"]
+			if applyResult.Result != tc.expectedResult {
+				t.Fatalf("Expected apply result %q, got %q", tc.expectedResult, applyResult.Result)
+			}
+			if tc.expectedError != (applyResult.Error != "") {
+				t.Fatalf("Expected error %v, got %q", tc.expectedError, applyResult.Error)
+			}
+		})
+	}
+}
+
+func TestAdoptionPolicy(t *testing.T) {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("v1")
+	obj.SetKind("ConfigMap")
+	obj.SetNamespace("foo")
+	obj.SetName("bar")
+
+	policy := &fedv1b1.ResourceAdoptionPolicy{RequiredAnnotation: "example.io/adoptable"}
+
+	testCases := map[string]struct {
+		annotations    map[string]string
+		labels         map[string]string
+		expectedWrites int32
+		expectedStatus status.PropagationStatus
+	}{
+		"adoption allowed": {
+			annotations:    map[string]string{"example.io/adoptable": "true"},
+			expectedWrites: 1,
+			expectedStatus: status.ClusterPropagationOK,
+		},
+		"adoption refused": {
+			expectedStatus: status.AdoptionRefused,
+		},
+		"managed resource is not subject to policy": {
+			labels:         map[string]string{utils.ManagedByKubeFedLabelKey: utils.ManagedByKubeFedLabelValue},
+			expectedWrites: 1,
+			expectedStatus: status.ClusterPropagationOK,
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			fedResource := &fakeFederatedResource{
+				targetGVK: schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"},
+				obj:       obj,
+			}
+			existing := obj.DeepCopy()
+			existing.SetResourceVersion("1")
+			existing.SetAnnotations(tc.annotations)
+			existing.SetLabels(tc.labels)
+			client := &existingResourceClient{existing: existing}
+			clientAccessor := func(string) (generic.Client, error) {
+				return client, nil
+			}
+			d := NewManagedDispatcher(context.Background(), clientAccessor, fedResource, false, policy, false)
+
+			d.Create("cluster1")
+			if _, err := d.Wait(); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if writes := atomic.LoadInt32(&client.writes); writes != tc.expectedWrites {
+				t.Fatalf("Expected %d writes to the member cluster, got %d", tc.expectedWrites, writes)
+			}
+			propStatus, _ := d.CollectedStatus()
+			if actual := propStatus.StatusMap["cluster1"]; actual != tc.expectedStatus {
+				t.Fatalf("Expected status %q, got %q", tc.expectedStatus, actual)
+			}
+		})
+	}
+}
+
+func TestOwnerReferences(t *testing.T) {
+	controller := true
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("autoscaling/v2")
+	obj.SetKind("HorizontalPodAutoscaler")
+	obj.SetNamespace("foo")
+	obj.SetName("bar")
+	err := utils.SetFederatedOwnerReferences(obj, []utils.FederatedOwnerReference{
+		{APIVersion: "apps/v1", Kind: "Deployment", Name: "bar", Controller: &controller},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	testCases := map[string]struct {
+		owners                  map[string]types.UID
+		expectedWrites          int32
+		expectedStatus          status.PropagationStatus
+		expectedOwnerReferences []metav1.OwnerReference
+	}{
+		"owner resolved in the cluster": {
+			owners:         map[string]types.UID{"Deployment/bar": "cluster1-uid"},
+			expectedWrites: 1,
+			expectedStatus: status.ClusterPropagationOK,
+			expectedOwnerReferences: []metav1.OwnerReference{
+				{APIVersion: "apps/v1", Kind: "Deployment", Name: "bar", UID: "cluster1-uid", Controller: &controller},
+			},
+		},
+		"owner missing from the cluster": {
+			expectedStatus: status.OwnerReferencesFailed,
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			fedResource := &fakeFederatedResource{
+				targetGVK: schema.GroupVersionKind{Group: "autoscaling", Version: "v2", Kind: "HorizontalPodAutoscaler"},
+				obj:       obj,
+			}
+			client := &ownerClient{owners: tc.owners}
+			clientAccessor := func(string) (generic.Client, error) {
+				return client, nil
+			}
+			d := NewManagedDispatcher(context.Background(), clientAccessor, fedResource, false, nil, false)
+
+			d.Create("cluster1")
+			if _, err := d.Wait(); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if writes := atomic.LoadInt32(&client.writes); writes != tc.expectedWrites {
+				t.Fatalf("Expected %d writes to the member cluster, got %d", tc.expectedWrites, writes)
+			}
+			propStatus, _ := d.CollectedStatus()
+			if actual := propStatus.StatusMap["cluster1"]; actual != tc.expectedStatus {
+				t.Fatalf("Expected status %q, got %q", tc.expectedStatus, actual)
+			}
+			if client.created == nil {
+				return
+			}
+			if actual := client.created.GetOwnerReferences(); !reflect.DeepEqual(tc.expectedOwnerReferences, actual) {
+				t.Fatalf("Expected owner references %v, got %v", tc.expectedOwnerReferences, actual)
+			}
+		})
+	}
+}
+
+func TestOwnerReferencesOfUpdatedResource(t *testing.T) {
+	controller := true
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("autoscaling/v2")
+	obj.SetKind("HorizontalPodAutoscaler")
+	obj.SetNamespace("foo")
+	obj.SetName("bar")
+	obj.SetResourceVersion("1")
+	err := utils.SetFederatedOwnerReferences(obj, []utils.FederatedOwnerReference{
+		{APIVersion: "apps/v1", Kind: "Deployment", Name: "bar", Controller: &controller},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	ownerReference := func(uid types.UID) metav1.OwnerReference {
+		return metav1.OwnerReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "bar", UID: uid, Controller: &controller}
+	}
+
+	testCases := map[string]struct {
+		clusterOwnerReferences  []metav1.OwnerReference
+		expectedGets            int32
+		expectedWrites          int32
+		expectedOwnerReferences []metav1.OwnerReference
+	}{
+		"owner already referenced is not retrieved": {
+			clusterOwnerReferences: []metav1.OwnerReference{ownerReference("cluster1-uid")},
+		},
+		// The garbage collector of the cluster removes the reference
+		// to an owner that was recreated.
+		"owner no longer referenced is retrieved and referenced": {
+			expectedGets:            1,
+			expectedWrites:          1,
+			expectedOwnerReferences: []metav1.OwnerReference{ownerReference("recreated-uid")},
+		},
+		"reference to an owner with changed properties is updated": {
+			clusterOwnerReferences: []metav1.OwnerReference{
+				{APIVersion: "apps/v1", Kind: "Deployment", Name: "bar", UID: "cluster1-uid"},
+			},
+			expectedWrites:          1,
+			expectedOwnerReferences: []metav1.OwnerReference{ownerReference("cluster1-uid")},
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			fedResource := &fakeFederatedResource{
+				targetGVK: schema.GroupVersionKind{Group: "autoscaling", Version: "v2", Kind: "HorizontalPodAutoscaler"},
+				obj:       obj,
+				version:   utils.ObjectVersion(obj),
+			}
+			client := &ownerClient{owners: map[string]types.UID{"Deployment/bar": "recreated-uid"}}
+			clientAccessor := func(string) (generic.Client, error) {
+				return client, nil
+			}
+			d := NewManagedDispatcher(context.Background(), clientAccessor, fedResource, false, nil, false)
+
+			clusterObj := obj.DeepCopy()
+			utils.AddManagedLabel(clusterObj)
+			clusterObj.SetOwnerReferences(tc.clusterOwnerReferences)
+			d.Update("cluster1", clusterObj)
+			if _, err := d.Wait(); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if gets := atomic.LoadInt32(&client.gets); gets != tc.expectedGets {
+				t.Fatalf("Expected %d owners to be retrieved, got %d", tc.expectedGets, gets)
+			}
+			if writes := atomic.LoadInt32(&client.writes); writes != tc.expectedWrites {
+				t.Fatalf("Expected %d writes to the member cluster, got %d", tc.expectedWrites, writes)
+			}
+			if client.updated == nil {
+				return
+			}
+			if actual := client.updated.GetOwnerReferences(); !reflect.DeepEqual(tc.expectedOwnerReferences, actual) {
+				t.Fatalf("Expected owner references %v, got %v", tc.expectedOwnerReferences, actual)
+			}
+		})
+	}
+}
+
+func TestCreatedNamespaceMarker(t *testing.T) {
+	testCases := map[string]struct {
+		kind           string
+		expectedMarked bool
+	}{
+		"created namespace is marked": {
+			kind:           utils.NamespaceKind,
+			expectedMarked: true,
+		},
+		"other created resource is not marked": {
+			kind: "ConfigMap",
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			obj := &unstructured.Unstructured{}
+			obj.SetAPIVersion("v1")
+			obj.SetKind(tc.kind)
+			obj.SetName("foo")
+			fedResource := &fakeFederatedResource{
+				targetGVK: schema.GroupVersionKind{Version: "v1", Kind: tc.kind},
+				obj:       obj,
+			}
+			client := &ownerClient{}
+			clientAccessor := func(string) (generic.Client, error) {
+				return client, nil
+			}
+			d := NewManagedDispatcher(context.Background(), clientAccessor, fedResource, false, nil, false)
+
+			d.Create("cluster1")
+			if _, err := d.Wait(); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if client.created == nil {
+				t.Fatalf("Expected the resource to be created")
+			}
+			if marked := utils.IsCreatedNamespace(client.created); marked != tc.expectedMarked {
+				t.Fatalf("Expected marked to be %v, got %v", tc.expectedMarked, marked)
+			}
+		})
+	}
+}
+
+func TestStampCreatedNamespaces(t *testing.T) {
+	// The declared label takes precedence over the stamped label with
+	// the same key.
+	stampLabels := map[string]string{"declared": "false", "stamped": "true"}
+	stampAnnotations := map[string]string{"stamped": "true"}
+
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("v1")
+	obj.SetKind(utils.NamespaceKind)
+	obj.SetName("foo")
+	obj.SetLabels(map[string]string{"declared": "true"})
+	fedResource := &fakeFederatedResource{
+		targetGVK: schema.GroupVersionKind{Version: "v1", Kind: utils.NamespaceKind},
+		obj:       obj,
+	}
+
+	t.Run("created namespace is stamped", func(t *testing.T) {
+		client := &ownerClient{}
+		clientAccessor := func(string) (generic.Client, error) {
+			return client, nil
+		}
+		d := NewManagedDispatcher(context.Background(), clientAccessor, fedResource, false, nil, false)
+		d.StampCreatedNamespaces(stampLabels, stampAnnotations)
+
+		d.Create("cluster1")
+		if _, err := d.Wait(); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if client.created == nil {
+			t.Fatalf("Expected the namespace to be created")
+		}
+		expectedLabels := map[string]string{"declared": "true", "stamped": "true"}
+		if labels := client.created.GetLabels(); !reflect.DeepEqual(labels, expectedLabels) {
+			t.Fatalf("Expected labels %v, got %v", expectedLabels, labels)
+		}
+		if value := client.created.GetAnnotations()["stamped"]; value != "true" {
+			t.Fatalf("Expected the stamped annotation, got annotations %v", client.created.GetAnnotations())
+		}
+		if !reflect.DeepEqual(fedResource.errors, []string{"CreatedNamespaceMetadataConflict"}) {
+			t.Fatalf("Expected the conflicting label to be reported, got %v", fedResource.errors)
+		}
+	})
+
+	testCases := map[string]struct {
+		created        bool
+		expectedLabels map[string]string
+	}{
+		"stamp is kept on update of created namespace": {
+			created:        true,
+			expectedLabels: map[string]string{"declared": "true", "stamped": "true"},
+		},
+		"adopted namespace is not stamped on update": {
+			expectedLabels: map[string]string{"declared": "true"},
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			clusterObj := obj.DeepCopy()
+			clusterObj.SetResourceVersion("1")
+			clusterObj.SetLabels(nil)
+			if tc.created {
+				utils.MarkCreatedNamespace(clusterObj)
+			}
+			client := &updatingClient{}
+			clientAccessor := func(string) (generic.Client, error) {
+				return client, nil
+			}
+			d := NewManagedDispatcher(context.Background(), clientAccessor, fedResource, false, nil, false)
+			d.StampCreatedNamespaces(stampLabels, stampAnnotations)
+
+			d.Update("cluster1", clusterObj)
+			if _, err := d.Wait(); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if client.updated == nil {
+				t.Fatalf("Expected the namespace to be updated")
+			}
+			if labels := client.updated.GetLabels(); !reflect.DeepEqual(labels, tc.expectedLabels) {
+				t.Fatalf("Expected labels %v, got %v", tc.expectedLabels, labels)
+			}
+			if _, ok := client.updated.GetAnnotations()["stamped"]; ok != tc.created {
+				t.Fatalf("Expected the stamped annotation to be present: %v, got annotations %v", tc.created, client.updated.GetAnnotations())
+			}
+		})
+	}
+}
+
+// updatingClient records the updated object.
+type updatingClient struct {
+	recordingClient
+	updated *unstructured.Unstructured
+}
+
+func (c *updatingClient) Update(ctx context.Context, obj runtimeclient.Object) error {
+	c.updated = obj.(*unstructured.Unstructured).DeepCopy()
+	return c.recordingClient.Update(ctx, obj)
+}
+
+func TestMergeMetadata(t *testing.T) {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("v1")
+	obj.SetKind("ConfigMap")
+	obj.SetNamespace("foo")
+	obj.SetName("bar")
+	obj.SetLabels(map[string]string{"declared": "true"})
+
+	clusterObj := obj.DeepCopy()
+	clusterObj.SetResourceVersion("1")
+	clusterObj.SetLabels(map[string]string{
+		"added":   "true",
+		"removed": "true",
+	})
+	clusterObj.SetAnnotations(map[string]string{
+		"added":                          "true",
+		utils.DeclaredMetadataAnnotation: `{"labels":["removed"]}`,
+	})
+
+	testCases := map[string]struct {
+		merge               bool
+		expectedLabels      map[string]string
+		expectedAnnotations map[string]string
+	}{
+		"cluster metadata is replaced by default": {
+			expectedLabels:      map[string]string{"declared": "true"},
+			expectedAnnotations: clusterObj.GetAnnotations(),
+		},
+		"cluster metadata that is not declared is preserved when merging": {
+			merge: true,
+			expectedLabels: map[string]string{
+				"added":    "true",
+				"declared": "true",
+			},
+			expectedAnnotations: map[string]string{
+				"added":                          "true",
+				utils.DeclaredMetadataAnnotation: `{"labels":["declared"]}`,
+			},
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			fedResource := &fakeFederatedResource{
+				targetGVK: schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"},
+				obj:       obj,
+			}
+			client := &updatingClient{}
+			clientAccessor := func(string) (generic.Client, error) {
+				return client, nil
+			}
+			d := NewManagedDispatcher(context.Background(), clientAccessor, fedResource, false, nil, false)
+			if tc.merge {
+				d.MergeMetadata()
+			}
+
+			d.Update("cluster1", clusterObj.DeepCopy())
+			if _, err := d.Wait(); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if client.updated == nil {
+				t.Fatalf("Expected the resource to be updated")
+			}
+			if labels := client.updated.GetLabels(); !reflect.DeepEqual(labels, tc.expectedLabels) {
+				t.Fatalf("Expected labels %v, got %v", tc.expectedLabels, labels)
+			}
+			if annotations := client.updated.GetAnnotations(); !reflect.DeepEqual(annotations, tc.expectedAnnotations) {
+				t.Fatalf("Expected annotations %v, got %v", tc.expectedAnnotations, annotations)
+			}
+		})
+	}
+}
+
+func TestUpdateOfCoOwnedResource(t *testing.T) {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("apps/v1")
+	obj.SetKind("Deployment")
+	obj.SetNamespace("foo")
+	obj.SetName("bar")
+	obj.Object["spec"] = map[string]interface{}{"replicas": int64(3)}
+
+	// Another controller set a field that is not in the template.
+	clusterObj := obj.DeepCopy()
+	clusterObj.SetResourceVersion("1")
+	clusterObj.Object["spec"] = map[string]interface{}{
+		"replicas":        int64(1),
+		"minReadySeconds": int64(5),
+	}
+
+	testCases := map[string]struct {
+		includedFields []string
+		expectedSpec   map[string]interface{}
+	}{
+		"fields of the cluster resource are replaced by default": {
+			expectedSpec: map[string]interface{}{"replicas": int64(3)},
+		},
+		"only the included fields of the cluster resource are updated": {
+			includedFields: []string{"spec.replicas"},
+			expectedSpec: map[string]interface{}{
+				"replicas":        int64(3),
+				"minReadySeconds": int64(5),
+			},
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			fedResource := &fakeFederatedResource{
+				targetGVK:      schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"},
+				obj:            obj,
+				includedFields: tc.includedFields,
+			}
+			client := &updatingClient{}
+			clientAccessor := func(string) (generic.Client, error) {
+				return client, nil
+			}
+			d := NewManagedDispatcher(context.Background(), clientAccessor, fedResource, false, nil, false)
+
+			d.Update("cluster1", clusterObj.DeepCopy())
+			if _, err := d.Wait(); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if client.updated == nil {
+				t.Fatalf("Expected the resource to be updated")
+			}
+			if spec := client.updated.Object["spec"]; !reflect.DeepEqual(spec, tc.expectedSpec) {
+				t.Fatalf("Expected spec %v, got %v", tc.expectedSpec, spec)
+			}
+		})
+	}
+}
+
+func TestUpdateDiffLog(t *testing.T) {
+	var logs bytes.Buffer
+	flags := flag.NewFlagSet("klog", flag.ContinueOnError)
+	klog.InitFlags(flags)
+	for name, value := range map[string]string{"v": "5", "logtostderr": "false", "alsologtostderr": "false"} {
+		previous := flags.Lookup(name).Value.String()
+		t.Cleanup(func() {
+			_ = flags.Set(name, previous)
+		})
+		if err := flags.Set(name, value); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	klog.SetOutput(&logs)
+	t.Cleanup(func() {
+		klog.SetOutput(io.Discard)
+	})
+
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("v1")
+	obj.SetKind("ConfigMap")
+	obj.SetNamespace("foo")
+	obj.SetName("bar")
+	obj.Object["data"] = map[string]interface{}{"key": "new", "added": "value"}
+	obj.Object["credentials"] = map[string]interface{}{"token": "new"}
+
+	clusterObj := obj.DeepCopy()
+	clusterObj.SetResourceVersion("1")
+	clusterObj.SetUID("uid")
+	clusterObj.Object["data"] = map[string]interface{}{"key": "old"}
+	clusterObj.Object["credentials"] = map[string]interface{}{"token": "old"}
+
+	testCases := map[string]struct {
+		version             string
+		secretOverridePaths []string
+		expectedLog         string
+	}{
+		"update of changed resource": {
+			expectedLog: `Updated ConfigMap "foo/bar" in cluster "cluster1", changed: ~/credentials/token, +/data/added, ~/data/key`,
+		},
+		"update of field set from a Secret": {
+			secretOverridePaths: []string{"/credentials"},
+			expectedLog:         `Updated ConfigMap "foo/bar" in cluster "cluster1", changed: ~/credentials, +/data/added, ~/data/key`,
+		},
+		"update of current resource": {
+			version: utils.ObjectVersion(clusterObj),
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			logs.Reset()
+			fedResource := &fakeFederatedResource{
+				targetGVK:           schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"},
+				obj:                 obj,
+				version:             tc.version,
+				secretOverridePaths: tc.secretOverridePaths,
+			}
+			client := &recordingClient{}
+			clientAccessor := func(string) (generic.Client, error) {
+				return client, nil
+			}
+			d := NewManagedDispatcher(context.Background(), clientAccessor, fedResource, false, nil, false)
+
+			d.Update("cluster1", clusterObj.DeepCopy())
+			if _, err := d.Wait(); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			klog.Flush()
+			logged := strings.Contains(logs.String(), "Updated ConfigMap")
+			if tc.expectedLog == "" {
+				if logged {
+					t.Fatalf("Expected no update to be logged, got %q", logs.String())
+				}
+				return
+			}
+			if !strings.Contains(logs.String(), tc.expectedLog) {
+				t.Fatalf("Expected log %q, got %q", tc.expectedLog, logs.String())
+			}
+		})
+	}
+}
+
+func TestAuthenticationFailureRefreshesClient(t *testing.T) {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("v1")
+	obj.SetKind("ConfigMap")
+	obj.SetNamespace("foo")
+	obj.SetName("bar")
+	fedResource := &fakeFederatedResource{
+		targetGVK: schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"},
+		obj:       obj,
+	}
+
+	// The client built before the token of the cluster was rotated is
+	// rejected by the cluster until it is rebuilt.
+	staleClient := &recordingClient{err: apierrors.NewUnauthorized("invalid token")}
+	currentClient := &recordingClient{}
+	cachedClient := staleClient
+	clientAccessor := func(string) (generic.Client, error) {
+		return cachedClient, nil
+	}
+	var refreshedClusters []string
+	refresh := func(clusterName string) {
+		refreshedClusters = append(refreshedClusters, clusterName)
+		cachedClient = currentClient
+	}
+
+	d := NewManagedDispatcher(context.Background(), clientAccessor, fedResource, false, nil, false)
+	d.OnAuthenticationFailure(refresh)
+	d.Create("cluster1")
+	if ok, err := d.Wait(); err != nil || ok {
+		t.Fatalf("Expected the create with the stale client to fail, got ok %v and error %v", ok, err)
+	}
+	if !reflect.DeepEqual(refreshedClusters, []string{"cluster1"}) {
+		t.Fatalf("Expected the client of cluster1 to be refreshed, got %v", refreshedClusters)
+	}
+
+	d = NewManagedDispatcher(context.Background(), clientAccessor, fedResource, false, nil, false)
+	d.OnAuthenticationFailure(refresh)
+	d.Create("cluster1")
+	if ok, err := d.Wait(); err != nil || !ok {
+		t.Fatalf("Expected the create with the refreshed client to succeed, got ok %v and error %v", ok, err)
+	}
+	if currentClient.writes != 1 {
+		t.Fatalf("Expected 1 write with the refreshed client, got %d", currentClient.writes)
+	}
+	if len(refreshedClusters) != 1 {
+		t.Fatalf("Expected no further refresh, got %v", refreshedClusters)
+	}
+}
+
+func TestUpdateChangesIgnoresServerManagedFields(t *testing.T) {
+	expectedObj := &unstructured.Unstructured{}
+	expectedObj.SetAPIVersion("v1")
+	expectedObj.SetKind("ConfigMap")
+	expectedObj.SetNamespace("foo")
+	expectedObj.SetName("bar")
+	if err := unstructured.SetNestedField(expectedObj.Object, "value", "data", "key"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	clusterObj := expectedObj.DeepCopy()
+	clusterObj.SetUID("uid")
+	clusterObj.SetResourceVersion("2")
+	clusterObj.SetGeneration(2)
+	if err := unstructured.SetNestedField(clusterObj.Object, "overridden", "data", "key"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := unstructured.SetNestedField(clusterObj.Object, true, "status", "ready"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	changes := UpdateChanges(clusterObj, expectedObj)
+	expected := []utils.FieldChange{
+		{Path: "/data/key", Type: utils.ChangeModified, Old: "overridden", New: "value"},
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Fatalf("Expected changes %v, got %v", expected, changes)
+	}
+}
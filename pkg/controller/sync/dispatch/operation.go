@@ -17,10 +17,14 @@ limitations under the License.
 package dispatch
 
 import (
+	"context"
 	"sync/atomic"
 	"time"
 
 	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 
 	"k8s.io/apimachinery/pkg/util/runtime"
 
@@ -31,6 +35,10 @@ import (
 
 type clientAccessorFunc func(clusterName string) (generic.Client, error)
 
+// operationFunc performs an operation in a member cluster with the
+// given context and client of the cluster.
+type operationFunc func(ctx context.Context, client generic.Client) utils.ReconciliationStatus
+
 type dispatchRecorder interface {
 	recordEvent(clusterName, operation, operationContinuous string)
 	recordOperationError(status status.PropagationStatus, clusterName, operation string, err error) utils.ReconciliationStatus
@@ -46,6 +54,10 @@ type OperationDispatcher interface {
 }
 
 type operationDispatcherImpl struct {
+	// The context of the reconciliation with which operations are
+	// performed.
+	ctx context.Context
+
 	clientAccessor clientAccessorFunc
 
 	resultChan          chan utils.ReconciliationStatus
@@ -54,10 +66,16 @@ type operationDispatcherImpl struct {
 	timeout time.Duration
 
 	recorder dispatchRecorder
+
+	// The tracer with which operations are recorded as children of
+	// the span in traceCtx. Operations are not traced if nil.
+	tracer   trace.Tracer
+	traceCtx context.Context
 }
 
-func newOperationDispatcher(clientAccessor clientAccessorFunc, recorder dispatchRecorder) *operationDispatcherImpl {
+func newOperationDispatcher(ctx context.Context, clientAccessor clientAccessorFunc, recorder dispatchRecorder) *operationDispatcherImpl {
 	return &operationDispatcherImpl{
+		ctx:            ctx,
 		clientAccessor: clientAccessor,
 		resultChan:     make(chan utils.ReconciliationStatus),
 		timeout:        30 * time.Second, // TODO(marun) Make this configurable
@@ -92,7 +110,27 @@ func (d *operationDispatcherImpl) Wait() (bool, error) {
 	return ok, nil
 }
 
-func (d *operationDispatcherImpl) clusterOperation(clusterName, op string, opFunc func(generic.Client) utils.ReconciliationStatus) {
+func (d *operationDispatcherImpl) clusterOperation(clusterName, op string, opFunc operationFunc) {
+	if d.tracer == nil {
+		d.resultChan <- d.performOperation(d.ctx, clusterName, op, opFunc)
+		return
+	}
+
+	ctx, span := d.tracer.Start(d.traceCtx, "per-cluster-apply", trace.WithAttributes(
+		attribute.String("kubefed.cluster", clusterName),
+		attribute.String("kubefed.operation", op),
+	))
+	result := d.performOperation(ctx, clusterName, op, opFunc)
+	if result == utils.StatusError {
+		span.SetStatus(codes.Error, "operation failed")
+	}
+	// The span is ended before the result is sent so that it is
+	// complete once Wait returns.
+	span.End()
+	d.resultChan <- result
+}
+
+func (d *operationDispatcherImpl) performOperation(ctx context.Context, clusterName, op string, opFunc operationFunc) utils.ReconciliationStatus {
 	// TODO(marun) Support cancellation of client calls on timeout.
 	client, err := d.clientAccessor(clusterName)
 	if err != nil {
@@ -102,13 +140,18 @@ func (d *operationDispatcherImpl) clusterOperation(clusterName, op string, opFun
 		} else {
 			d.recorder.recordOperationError(status.ClientRetrievalFailed, clusterName, op, wrappedErr)
 		}
-		d.resultChan <- utils.StatusError
-		return
+		return utils.StatusError
 	}
 
 	// TODO(marun) Retry on recoverable errors (e.g. IsConflict, AlreadyExists)
-	ok := opFunc(client)
-	d.resultChan <- ok
+	return opFunc(ctx, client)
+}
+
+// trace causes subsequently dispatched operations to be recorded as
+// spans of the given tracer that are children of the span in ctx.
+func (d *operationDispatcherImpl) trace(ctx context.Context, tracer trace.Tracer) {
+	d.traceCtx = ctx
+	d.tracer = tracer
 }
 
 func (d *operationDispatcherImpl) incrementOperationsInitiated() {
@@ -45,6 +45,8 @@ type UnmanagedDispatcher interface {
 
 	Delete(clusterName string, opts ...runtimeclient.DeleteOption)
 	RemoveManagedLabel(clusterName string, clusterObj *unstructured.Unstructured)
+	RemoveManagedMetadata(clusterName string, clusterObj *unstructured.Unstructured, removeFunc func(obj *unstructured.Unstructured))
+	SetDeletionPending(clusterName string, clusterObj *unstructured.Unstructured, until time.Time, deleteOptions string)
 }
 
 type unmanagedDispatcherImpl struct {
@@ -56,8 +58,8 @@ type unmanagedDispatcherImpl struct {
 	recorder dispatchRecorder
 }
 
-func NewUnmanagedDispatcher(clientAccessor clientAccessorFunc, targetGVK schema.GroupVersionKind, targetName utils.QualifiedName) UnmanagedDispatcher {
-	dispatcher := newOperationDispatcher(clientAccessor, nil)
+func NewUnmanagedDispatcher(ctx context.Context, clientAccessor clientAccessorFunc, targetGVK schema.GroupVersionKind, targetName utils.QualifiedName) UnmanagedDispatcher {
+	dispatcher := newOperationDispatcher(ctx, clientAccessor, nil)
 	return newUnmanagedDispatcher(dispatcher, nil, targetGVK, targetName)
 }
 
@@ -79,7 +81,7 @@ func (d *unmanagedDispatcherImpl) Delete(clusterName string, opts ...runtimeclie
 	d.dispatcher.incrementOperationsInitiated()
 	const op = "delete"
 	const opContinuous = "Deleting"
-	go d.dispatcher.clusterOperation(clusterName, op, func(client generic.Client) utils.ReconciliationStatus {
+	go d.dispatcher.clusterOperation(clusterName, op, func(ctx context.Context, client generic.Client) utils.ReconciliationStatus {
 		targetName := d.targetNameForCluster(clusterName)
 		if d.recorder == nil {
 			klog.V(2).Infof(eventTemplate, opContinuous, d.targetGVK.Kind, targetName, clusterName)
@@ -89,7 +91,7 @@ func (d *unmanagedDispatcherImpl) Delete(clusterName string, opts ...runtimeclie
 
 		obj := &unstructured.Unstructured{}
 		obj.SetGroupVersionKind(d.targetGVK)
-		err := client.Delete(context.Background(), obj, targetName.Namespace, targetName.Name, opts...)
+		err := client.Delete(ctx, obj, targetName.Namespace, targetName.Name, opts...)
 		if apierrors.IsNotFound(err) {
 			err = nil
 		}
@@ -108,10 +110,30 @@ func (d *unmanagedDispatcherImpl) Delete(clusterName string, opts ...runtimeclie
 }
 
 func (d *unmanagedDispatcherImpl) RemoveManagedLabel(clusterName string, clusterObj *unstructured.Unstructured) {
+	d.patchMetadata(clusterName, clusterObj, "remove managed label from", "Removing managed label from", utils.RemoveManagedLabel)
+}
+
+// RemoveManagedMetadata removes the markers of management by KubeFed
+// from the given cluster object with the given function, leaving the
+// object otherwise untouched.
+func (d *unmanagedDispatcherImpl) RemoveManagedMetadata(clusterName string, clusterObj *unstructured.Unstructured, removeFunc func(obj *unstructured.Unstructured)) {
+	d.patchMetadata(clusterName, clusterObj, "remove managed metadata from", "Removing managed metadata from", removeFunc)
+}
+
+// SetDeletionPending records on the given cluster object that it is to
+// be deleted after the given time with the given delete options,
+// leaving the object otherwise untouched.
+func (d *unmanagedDispatcherImpl) SetDeletionPending(clusterName string, clusterObj *unstructured.Unstructured, until time.Time, deleteOptions string) {
+	d.patchMetadata(clusterName, clusterObj, "mark pending deletion of", "Marking pending deletion of", func(obj *unstructured.Unstructured) {
+		utils.SetDeletionPending(obj, until, deleteOptions)
+	})
+}
+
+// patchMetadata patches the given cluster object with the metadata
+// changes made by the given function.
+func (d *unmanagedDispatcherImpl) patchMetadata(clusterName string, clusterObj *unstructured.Unstructured, op, opContinuous string, updateFunc func(obj *unstructured.Unstructured)) {
 	d.dispatcher.incrementOperationsInitiated()
-	const op = "remove managed label from"
-	const opContinuous = "Removing managed label from"
-	go d.dispatcher.clusterOperation(clusterName, op, func(client generic.Client) utils.ReconciliationStatus {
+	go d.dispatcher.clusterOperation(clusterName, op, func(ctx context.Context, client generic.Client) utils.ReconciliationStatus {
 		if d.recorder == nil {
 			klog.V(2).Infof(eventTemplate, opContinuous, d.targetGVK.Kind, d.targetNameForCluster(clusterName), clusterName)
 		} else {
@@ -122,9 +144,9 @@ func (d *unmanagedDispatcherImpl) RemoveManagedLabel(clusterName string, cluster
 		updateObj := clusterObj.DeepCopy()
 		patch := runtimeclient.MergeFrom(updateObj.DeepCopy())
 
-		utils.RemoveManagedLabel(updateObj)
+		updateFunc(updateObj)
 
-		err := client.Patch(context.Background(), updateObj, patch)
+		err := client.Patch(ctx, updateObj, patch)
 		if err != nil {
 			if d.recorder == nil {
 				wrappedErr := d.wrapOperationError(err, clusterName, op)
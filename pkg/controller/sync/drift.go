@@ -0,0 +1,245 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sync
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	apiv1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+
+	fedv1b1 "sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
+	"sigs.k8s.io/kubefed/pkg/controller/sync/dispatch"
+	"sigs.k8s.io/kubefed/pkg/controller/sync/status"
+	"sigs.k8s.io/kubefed/pkg/controller/utils"
+)
+
+// detectDrift compares the managed resources of every federated
+// resource of the type with their desired content, independently of
+// reconciliation, and records the outcome in the DriftDetected
+// condition of each checked cluster.
+func (s *KubeFedSyncController) detectDrift() {
+	if !s.fedAccessor.HasSynced() {
+		return
+	}
+	clusters, err := s.informer.GetClusters()
+	if err != nil {
+		runtime.HandleError(errors.Wrap(err, "Failed to retrieve list of clusters"))
+		return
+	}
+	readyClusters, err := s.informer.GetReadyClusters()
+	if err != nil {
+		runtime.HandleError(errors.Wrap(err, "Failed to retrieve list of ready clusters"))
+		return
+	}
+	if !s.informer.GetTargetStore().ClustersSynced(readyClusters) {
+		return
+	}
+	readyClusterNames := sets.New[string]()
+	for _, cluster := range readyClusters {
+		readyClusterNames.Insert(cluster.Name)
+	}
+
+	var names []utils.QualifiedName
+	s.fedAccessor.VisitFederatedResources(func(obj interface{}) {
+		if fedObject, ok := obj.(*unstructured.Unstructured); ok && fedObject.GetDeletionTimestamp() == nil {
+			names = append(names, utils.NewQualifiedName(fedObject))
+		}
+	})
+	for _, qualifiedName := range names {
+		if s.ctx.Err() != nil {
+			return
+		}
+		fedResource, _, err := s.fedAccessor.FederatedResource(qualifiedName)
+		if err != nil {
+			runtime.HandleError(errors.Wrapf(err, "Failed to retrieve federated resource %q", qualifiedName))
+			continue
+		}
+		if fedResource == nil || utils.IsReleaseRequested(fedResource.Object()) {
+			continue
+		}
+		clusterChanges, err := s.clusterDrift(s.ctx, fedResource, clusters, readyClusterNames)
+		if err != nil {
+			runtime.HandleError(errors.Wrapf(err, "Failed to detect drift of %s %q", fedResource.FederatedKind(), qualifiedName))
+			continue
+		}
+		s.setDriftStatus(fedResource, clusterChanges)
+	}
+}
+
+// clusterDrift returns the changes, keyed by cluster name, required
+// for the managed resources of the given federated resource in the
+// given clusters to have their desired content. Only ready clusters
+// that the resource has been propagated to with its current template
+// and overrides are checked, and a resource whose version is the one
+// recorded when it was propagated has not drifted. A cluster that
+// was checked and found without drift has no changes.
+func (s *KubeFedSyncController) clusterDrift(ctx context.Context, fedResource FederatedResource, clusters []*fedv1b1.KubeFedCluster, readyClusterNames sets.Set[string]) (map[string][]utils.FieldChange, error) {
+	selectedClusterNames, err := fedResource.ComputePlacement(clusters)
+	if err != nil {
+		return nil, err
+	}
+	placementOnlyClusterNames, err := fedResource.PlacementOnlyClusters()
+	if err != nil {
+		return nil, err
+	}
+	propagatedClusterNames := selectedClusterNames.Difference(placementOnlyClusterNames)
+	fedResource.SetPlacement(propagatedClusterNames)
+
+	key := fedResource.TargetName().String()
+	clusterChanges := make(map[string][]utils.FieldChange)
+	for clusterName := range propagatedClusterNames.Intersection(readyClusterNames) {
+		version, err := fedResource.VersionForCluster(clusterName)
+		if err != nil {
+			return nil, err
+		}
+		if len(version) == 0 {
+			// The resource has not been propagated to the cluster
+			// since its template or overrides last changed.
+			continue
+		}
+		rawClusterObj, _, err := s.informer.GetTargetStore().GetByKey(clusterName, key)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed to retrieve cached cluster object %q", key)
+		}
+		if rawClusterObj == nil {
+			// A missing resource is recreated by reconciliation and
+			// is reported by the consistency check.
+			continue
+		}
+		clusterObj := rawClusterObj.(*unstructured.Unstructured)
+		if utils.ObjectVersion(clusterObj) == version {
+			clusterChanges[clusterName] = nil
+			continue
+		}
+		changes, err := DriftForCluster(ctx, fedResource, clusterName, clusterObj, s.mergeMetadata)
+		if err != nil {
+			// A resource that cannot be rendered fails to propagate
+			// and is reported in the status by reconciliation.
+			klog.V(4).Infof("Unable to detect drift of %s %q in cluster %q: %v", fedResource.TargetKind(), key, clusterName, err)
+			continue
+		}
+		clusterChanges[clusterName] = changes
+	}
+	return clusterChanges, nil
+}
+
+// DriftForCluster returns the changes required for the given managed
+// resource of the named cluster to have the content that the given
+// federated resource would propagate to the cluster. Fields retained
+// from the cluster object and fields that are not managed by KubeFed
+// are not considered, nor is server-managed metadata or labels and
+// annotations added in the cluster if mergeMetadata is true.
+func DriftForCluster(ctx context.Context, fedResource dispatch.FederatedResourceForDispatch, clusterName string, clusterObj *unstructured.Unstructured, mergeMetadata bool) ([]utils.FieldChange, error) {
+	objects, err := RenderForClusters(ctx, fedResource, []string{clusterName})
+	if err != nil {
+		return nil, err
+	}
+	desiredObj := objects[clusterName]
+	declaredAnnotations := desiredObj.GetAnnotations()
+	err = dispatch.RetainClusterFields(fedResource.TargetKind(), desiredObj, clusterObj, fedResource.Object())
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to retain fields")
+	}
+	if mergeMetadata {
+		desiredObj.SetAnnotations(declaredAnnotations)
+	}
+	if includedFields := fedResource.IncludedFields(); len(includedFields) > 0 {
+		desiredObj, err = utils.ApplyIncludedFields(desiredObj, clusterObj, includedFields)
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to apply included fields")
+		}
+	}
+	if mergeMetadata {
+		err = utils.MergeClusterMetadata(desiredObj, clusterObj)
+		if err != nil {
+			return nil, err
+		}
+	}
+	utils.RetainServerManagedMetadata(desiredObj, clusterObj, fedResource.ServerManagedMetadata())
+	return dispatch.DriftChanges(clusterObj, desiredObj), nil
+}
+
+// setDriftStatus records the given changes in the DriftDetected
+// conditions of the clusters of the given federated resource, and
+// records an event for each cluster that newly drifted.
+func (s *KubeFedSyncController) setDriftStatus(fedResource FederatedResource, clusterChanges map[string][]utils.FieldChange) {
+	kind := fedResource.FederatedKind()
+	name := fedResource.FederatedName()
+	obj := fedResource.Object().DeepCopy()
+
+	previouslyDrifted := driftedClusters(obj)
+	err := wait.PollUntilContextTimeout(s.ctx, 1*time.Second, 5*time.Second, true, func(ctx context.Context) (bool, error) {
+		updateRequired, err := status.SetClusterDriftConditions(obj, clusterChanges)
+		if err != nil {
+			return false, errors.Wrap(err, "failed to set the drift conditions")
+		}
+		if !updateRequired {
+			return true, nil
+		}
+		err = s.hostClusterClient.UpdateStatus(ctx, obj)
+		if err == nil {
+			return true, nil
+		}
+		if apierrors.IsConflict(err) {
+			klog.V(2).Infof("Failed to set drift status for %s %q due to conflict (will retry): %v.", kind, name, err)
+			if err := s.hostClusterClient.Get(ctx, obj, obj.GetNamespace(), obj.GetName()); err != nil {
+				return false, errors.Wrapf(err, "failed to retrieve resource")
+			}
+			return false, nil
+		}
+		return false, errors.Wrapf(err, "failed to update resource")
+	})
+	if err != nil {
+		runtime.HandleError(errors.Wrapf(err, "failed to set drift status for %s %q", kind, name))
+		return
+	}
+
+	for clusterName, changes := range clusterChanges {
+		if len(changes) > 0 && !previouslyDrifted[clusterName] {
+			fedResource.RecordEvent(string(status.DriftDetectedConditionType), "%s %q in cluster %q was modified out-of-band: %s",
+				fedResource.TargetKind(), fedResource.TargetName(), clusterName, utils.FormatChangedPaths(changes))
+		}
+	}
+}
+
+// driftedClusters returns the names of the clusters whose
+// DriftDetected condition is True in the status of the given
+// federated resource.
+func driftedClusters(fedObject *unstructured.Unstructured) map[string]bool {
+	drifted := make(map[string]bool)
+	resource, err := status.DecodeGenericFederatedResource(fedObject)
+	if err != nil || resource.Status == nil {
+		return drifted
+	}
+	for _, cluster := range resource.Status.Clusters {
+		for _, condition := range cluster.Conditions {
+			if condition != nil && condition.Type == status.DriftDetectedConditionType && condition.Status == apiv1.ConditionTrue {
+				drifted[cluster.Name] = true
+			}
+		}
+	}
+	return drifted
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sync
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/kubefed/pkg/controller/sync/status"
+	"sigs.k8s.io/kubefed/pkg/controller/utils"
+)
+
+// collectReadyEndpoints returns the number of ready endpoints of the
+// service managed by the given federated resource in each of the
+// clusters it was successfully propagated to, keyed by cluster name.
+// The EndpointSlices of the service are read from the cache of those
+// in its namespace that are labeled with its name by the endpointslice
+// controller of the cluster. The sorted names of the clusters whose
+// EndpointSlices could not be read are also returned,
+// since they are omitted from the counts.
+func (s *KubeFedSyncController) collectReadyEndpoints(fedResource FederatedResource, statusMap status.PropagationStatusMap) (map[string]int64, []string) {
+	targetName := fedResource.TargetName()
+	readyEndpoints := make(map[string]int64)
+	var uncollected []string
+	for clusterName, propStatus := range statusMap {
+		if propStatus != status.ClusterPropagationOK {
+			continue
+		}
+		endpointSlices, err := s.cachedEndpointSlices(clusterName, targetName)
+		if err != nil {
+			klog.V(4).Infof("Unable to collect the ready endpoints of Service %q in cluster %q: %v", targetName, clusterName, err)
+			uncollected = append(uncollected, clusterName)
+			continue
+		}
+		readyEndpoints[clusterName] = status.ReadyEndpoints(endpointSlices)
+	}
+	sort.Strings(uncollected)
+	return readyEndpoints, uncollected
+}
+
+// cachedEndpointSlices returns the cached EndpointSlices of the named
+// service in the given cluster. An error is returned if the cache of
+// the cluster has not synced.
+func (s *KubeFedSyncController) cachedEndpointSlices(clusterName string, serviceName utils.QualifiedName) ([]discoveryv1.EndpointSlice, error) {
+	store := s.endpointSliceInformer.GetTargetStore()
+	if !store.ClusterSynced(clusterName) {
+		return nil, errors.Errorf("EndpointSlices of cluster %q are not synced", clusterName)
+	}
+	objs, err := store.ListFromCluster(clusterName)
+	if err != nil {
+		return nil, err
+	}
+	endpointSlices := []discoveryv1.EndpointSlice{}
+	for _, obj := range objs {
+		u, ok := obj.(*unstructured.Unstructured)
+		if !ok || u.GetNamespace() != serviceName.Namespace || u.GetLabels()[discoveryv1.LabelServiceName] != serviceName.Name {
+			continue
+		}
+		endpointSlice := discoveryv1.EndpointSlice{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, &endpointSlice); err != nil {
+			return nil, err
+		}
+		endpointSlices = append(endpointSlices, endpointSlice)
+	}
+	return endpointSlices, nil
+}
+
+// enqueueForEndpointSlice enqueues the federated service that the
+// given EndpointSlice belongs to. The federated name of a service
+// whose name is templated is only known from the annotation of the
+// service in a member cluster.
+func (s *KubeFedSyncController) enqueueForEndpointSlice(obj runtimeclient.Object) {
+	serviceName, ok := obj.GetLabels()[discoveryv1.LabelServiceName]
+	if !ok {
+		return
+	}
+	qualifiedName := utils.QualifiedName{Namespace: obj.GetNamespace(), Name: serviceName}
+	services, err := s.informer.GetTargetStore().GetFromAllClusters(qualifiedName.String())
+	if err == nil && len(services) > 0 {
+		if service, ok := services[0].Object.(runtimeclient.Object); ok {
+			qualifiedName = utils.FederatedNameForEvent(service)
+		}
+	}
+	s.worker.EnqueueForRetry(qualifiedName)
+}
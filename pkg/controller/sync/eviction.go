@@ -0,0 +1,165 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sync
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+
+	apiv1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+
+	fedv1b1 "sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
+	"sigs.k8s.io/kubefed/pkg/controller/sync/status"
+	"sigs.k8s.io/kubefed/pkg/controller/utils"
+)
+
+// recordApplyOutcomes records the outcomes of the applies of the
+// resource with the given key to member clusters with the failure
+// tracker and taints the clusters whose applies fail at a rate above
+// its threshold. Applies that a cluster rejected are not counted since
+// they indicate a problem with the resource rather than with the
+// cluster.
+func (s *KubeFedSyncController) recordApplyOutcomes(ctx context.Context, key string, collectedStatus status.CollectedPropagationStatus) {
+	if s.clusterFailureTracker == nil {
+		return
+	}
+	// Resources of different types may share a key.
+	key = fmt.Sprintf("%s/%s", s.typeConfig.GetFederatedType().Kind, key)
+	for clusterName, applyResult := range collectedStatus.ApplyResults {
+		var failed bool
+		switch applyResult.Result {
+		case status.ApplyCreated, status.ApplyUpdated:
+		case status.ApplyFailed:
+			propStatus := collectedStatus.StatusMap[clusterName]
+			if propStatus == status.CreationRejected || propStatus == status.UpdateRejected {
+				continue
+			}
+			failed = true
+		default:
+			continue
+		}
+		if rate, exceeded := s.clusterFailureTracker.Record(clusterName, key, failed); exceeded {
+			s.clusterFailureTracker.Evict(func() {
+				s.evictCluster(ctx, clusterName, rate)
+			})
+		}
+	}
+}
+
+// evictCluster taints the named cluster so that federated resources
+// are no longer placed in it, and records an event for the cluster.
+// Resources already propagated to the cluster are only removed from
+// it if the taint has the NoExecute effect. The cluster is not tainted
+// if the maximum number of clusters are already tainted or if no other
+// ready cluster would remain untainted, since applies failing in all
+// clusters are more likely to be caused by the resources than by the
+// clusters.
+func (s *KubeFedSyncController) evictCluster(ctx context.Context, clusterName string, rate utils.ClusterFailureRate) {
+	effect := s.clusterFailureTracker.TaintEffect()
+	cluster := &fedv1b1.KubeFedCluster{}
+	tainted := false
+	var skipReason string
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		clusterList := &fedv1b1.KubeFedClusterList{}
+		if err := s.hostClusterClient.List(ctx, clusterList, s.kubeFedNamespace); err != nil {
+			return err
+		}
+		evicted, healthy := 0, 0
+		found := false
+		for i := range clusterList.Items {
+			item := &clusterList.Items[i]
+			if item.Name == clusterName {
+				*cluster = *item
+				found = true
+			}
+			if hasEvictionTaint(item) {
+				evicted++
+			} else if item.Name != clusterName && utils.IsClusterReady(&item.Status) {
+				healthy++
+			}
+		}
+		if !found {
+			return apierrors.NewNotFound(fedv1b1.SchemeGroupVersion.WithResource("kubefedclusters").GroupResource(), clusterName)
+		}
+		if hasEvictionTaint(cluster) {
+			return nil
+		}
+		if max := s.clusterFailureTracker.MaxEvictedClusters(); evicted >= max {
+			skipReason = fmt.Sprintf("%d clusters are already tainted", evicted)
+			return nil
+		}
+		if healthy == 0 {
+			skipReason = "no other ready cluster would remain untainted"
+			return nil
+		}
+		cluster.Spec.Taints = append(cluster.Spec.Taints, apiv1.Taint{
+			Key:       utils.ClusterEvictionTaintKey,
+			Effect:    effect,
+			TimeAdded: &metav1.Time{Time: time.Now()},
+		})
+		if err := s.hostClusterClient.Update(ctx, cluster); err != nil {
+			return err
+		}
+		tainted = true
+		return nil
+	})
+	if err != nil {
+		runtime.HandleError(errors.Wrapf(err, "Failed to taint cluster %q whose applies are failing", clusterName))
+		return
+	}
+	// The cluster is referenced directly since the KubeFed types are
+	// not registered with the scheme of the event recorder.
+	ref := &apiv1.ObjectReference{
+		APIVersion: fedv1b1.SchemeGroupVersion.String(),
+		Kind:       "KubeFedCluster",
+		Namespace:  cluster.Namespace,
+		Name:       cluster.Name,
+		UID:        cluster.UID,
+	}
+	if skipReason != "" {
+		message := fmt.Sprintf("Not tainting the cluster although %d of %d applies within %v failed since %s",
+			rate.Failed, rate.Total, s.clusterFailureTracker.Window(), skipReason)
+		klog.Warningf("Cluster %q: %s", clusterName, message)
+		s.eventRecorder.Event(ref, apiv1.EventTypeWarning, "ClusterEvictionSkipped", message)
+		return
+	}
+	if !tainted {
+		return
+	}
+
+	message := fmt.Sprintf("Tainted the cluster with %s:%s since %d of %d applies within %v failed",
+		utils.ClusterEvictionTaintKey, effect, rate.Failed, rate.Total, s.clusterFailureTracker.Window())
+	klog.Warningf("Cluster %q: %s", clusterName, message)
+	s.eventRecorder.Event(ref, apiv1.EventTypeWarning, "ClusterEvicted", message)
+}
+
+func hasEvictionTaint(cluster *fedv1b1.KubeFedCluster) bool {
+	for _, taint := range cluster.Spec.Taints {
+		if taint.Key == utils.ClusterEvictionTaintKey {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notify
+
+import (
+	"context"
+)
+
+// DefaultQueueSize is the number of notifications that can wait to be
+// delivered before further notifications are dropped.
+const DefaultQueueSize = 100
+
+type queuedNotification struct {
+	notification *Notification
+	onError      func(error)
+}
+
+// Queue delivers notifications one at a time so that a slow or
+// unreachable webhook holds up a bounded number of notifications
+// rather than a goroutine for each of them.
+type Queue struct {
+	notifier Notifier
+	items    chan queuedNotification
+}
+
+// NewQueue returns a queue that delivers notifications with the given
+// notifier and holds at most the given number of notifications.
+func NewQueue(notifier Notifier, size int) *Queue {
+	return &Queue{
+		notifier: notifier,
+		items:    make(chan queuedNotification, size),
+	}
+}
+
+// Enqueue adds the notification to the queue, and returns false if it
+// was dropped because the queue is full. The given function is called
+// with the error if the notification cannot be delivered.
+func (q *Queue) Enqueue(notification *Notification, onError func(error)) bool {
+	select {
+	case q.items <- queuedNotification{notification: notification, onError: onError}:
+		return true
+	default:
+		return false
+	}
+}
+
+// Run delivers queued notifications until the context is done.
+func (q *Queue) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case item := <-q.items:
+			if err := q.notifier.Notify(ctx, item.notification); err != nil && item.onError != nil {
+				item.onError(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notify
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// blockingNotifier delivers notifications to a channel, failing those
+// named "fail".
+type blockingNotifier struct {
+	delivered chan string
+}
+
+func (n *blockingNotifier) Notify(ctx context.Context, notification *Notification) error {
+	n.delivered <- notification.Name
+	if notification.Name == "fail" {
+		return errors.New("delivery failed")
+	}
+	return nil
+}
+
+func TestQueueDropsNotificationsWhenFull(t *testing.T) {
+	notifier := &blockingNotifier{delivered: make(chan string)}
+	queue := NewQueue(notifier, 2)
+
+	for _, name := range []string{"a", "fail"} {
+		if !queue.Enqueue(&Notification{Name: name}, nil) {
+			t.Fatalf("Expected notification %q to be queued", name)
+		}
+	}
+	if queue.Enqueue(&Notification{Name: "c"}, nil) {
+		t.Fatalf("Expected a notification to be dropped when the queue is full")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	failed := make(chan error, 1)
+	go queue.Run(ctx)
+	if name := <-notifier.delivered; name != "a" {
+		t.Fatalf("Expected notification %q to be delivered first, got %q", "a", name)
+	}
+	// Delivering the first notification frees a place in the queue.
+	if !queue.Enqueue(&Notification{Name: "d"}, func(err error) { failed <- err }) {
+		t.Fatalf("Expected a notification to be queued once a queued notification is delivered")
+	}
+	if name := <-notifier.delivered; name != "fail" {
+		t.Fatalf("Expected notification %q to be delivered second, got %q", "fail", name)
+	}
+	if name := <-notifier.delivered; name != "d" {
+		t.Fatalf("Expected notification %q to be delivered last, got %q", "d", name)
+	}
+	select {
+	case err := <-failed:
+		t.Fatalf("Expected only the error of a failed notification to be reported, got %v", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+}
+
+func TestQueueReportsFailedNotifications(t *testing.T) {
+	notifier := &blockingNotifier{delivered: make(chan string, 1)}
+	queue := NewQueue(notifier, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go queue.Run(ctx)
+
+	failed := make(chan error, 1)
+	queue.Enqueue(&Notification{Name: "fail"}, func(err error) { failed <- err })
+	select {
+	case err := <-failed:
+		if err == nil {
+			t.Fatalf("Expected an error for a failed notification")
+		}
+	case <-time.After(wait.ForeverTestTimeout):
+		t.Fatalf("Expected the failure of the notification to be reported")
+	}
+}
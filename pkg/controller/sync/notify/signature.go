@@ -0,0 +1,87 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notify
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// SignatureHeader is the HTTP header that carries the signature of a
+// notification if the webhook configures a signing secret. Its value
+// has the form `t=<timestamp>,v1=<signature>`, where the timestamp is
+// the time of signing in seconds since the Unix epoch and the
+// signature is the hex encoded HMAC-SHA256, keyed with the shared
+// secret, of the timestamp, a period and the request body.
+const SignatureHeader = "X-Kubefed-Signature"
+
+// Sign returns the value of the signature header for the given body
+// signed with the given secret at the given time.
+func Sign(secret []byte, timestamp time.Time, body []byte) string {
+	t := strconv.FormatInt(timestamp.Unix(), 10)
+	return fmt.Sprintf("t=%s,v1=%s", t, computeSignature(secret, t, body))
+}
+
+// VerifySignature checks that the given value of the signature header
+// is a signature of the given body with the given secret. If tolerance
+// is positive, signatures made longer ago than the tolerance are
+// rejected to limit replay of intercepted notifications.
+func VerifySignature(secret []byte, header string, body []byte, tolerance time.Duration) error {
+	var t, signature string
+	for _, part := range strings.Split(header, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "t":
+			t = value
+		case "v1":
+			signature = value
+		}
+	}
+	if len(t) == 0 || len(signature) == 0 {
+		return errors.Errorf("%s must have the form t=<timestamp>,v1=<signature>", SignatureHeader)
+	}
+	seconds, err := strconv.ParseInt(t, 10, 64)
+	if err != nil {
+		return errors.Wrapf(err, "Invalid timestamp in %s", SignatureHeader)
+	}
+	if tolerance > 0 && time.Since(time.Unix(seconds, 0)) > tolerance {
+		return errors.Errorf("Signature is older than %v", tolerance)
+	}
+	expected := computeSignature(secret, t, body)
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return errors.New("Signature does not match the body")
+	}
+	return nil
+}
+
+func computeSignature(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,42 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notify
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Notification is sent by the sync controller to a propagation
+// webhook when propagation of a generation of a federated resource to
+// its placed clusters has completed.
+type Notification struct {
+	// Kind is the kind of the federated resource.
+	Kind string `json:"kind"`
+	// Namespace is the namespace of the federated resource, which is
+	// empty for a cluster-scoped resource.
+	Namespace string `json:"namespace,omitempty"`
+	// Name is the name of the federated resource.
+	Name string `json:"name"`
+	// Generation is the generation of the federated resource that
+	// was propagated.
+	Generation int64 `json:"generation"`
+	// Clusters are the names of the member clusters the resource was
+	// propagated to.
+	Clusters []string `json:"clusters"`
+	// CompletedAt is the time at which completion was observed.
+	CompletedAt metav1.Time `json:"completedAt"`
+}
@@ -0,0 +1,213 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+
+	fedv1b1 "sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
+	genericclient "sigs.k8s.io/kubefed/pkg/client/generic"
+)
+
+const (
+	// DefaultTimeout is the time to wait for a webhook to respond to
+	// an attempt if the webhook does not configure a timeout.
+	DefaultTimeout = 10 * time.Second
+
+	// DefaultRetries is the number of times a notification is retried
+	// if the webhook does not configure retries.
+	DefaultRetries = 3
+
+	// SigningSecretKey is the key of the shared secret in the data of
+	// the signing secret of a webhook.
+	SigningSecretKey = "secret"
+)
+
+// Notifier notifies of completed propagation of federated resources.
+type Notifier interface {
+	Notify(ctx context.Context, notification *Notification) error
+}
+
+// secretFunc returns the shared secret used to sign notifications.
+type secretFunc func(ctx context.Context) ([]byte, error)
+
+type webhookNotifier struct {
+	url           string
+	timeout       time.Duration
+	retries       int
+	backoff       wait.Backoff
+	signingSecret secretFunc
+	httpClient    *http.Client
+}
+
+// NewWebhookNotifier returns a Notifier that calls the given webhook,
+// or nil if no webhook is configured. The signing secret of the
+// webhook is retrieved from the given namespace with the given client
+// for each notification so that it can be rotated.
+func NewWebhookNotifier(webhook *fedv1b1.PropagationWebhook, client genericclient.Client, namespace string) (Notifier, error) {
+	if webhook == nil {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if len(webhook.CABundle) > 0 {
+		certPool := x509.NewCertPool()
+		if !certPool.AppendCertsFromPEM(webhook.CABundle) {
+			return nil, errors.New("Unable to load the CA bundle of the propagation webhook")
+		}
+		tlsConfig.RootCAs = certPool
+	}
+
+	timeout := DefaultTimeout
+	if webhook.TimeoutSeconds != nil {
+		timeout = time.Duration(*webhook.TimeoutSeconds) * time.Second
+	}
+	retries := DefaultRetries
+	if webhook.Retries != nil {
+		retries = int(*webhook.Retries)
+	}
+
+	n := &webhookNotifier{
+		url:     webhook.URL,
+		timeout: timeout,
+		retries: retries,
+		backoff: wait.Backoff{
+			Duration: time.Second,
+			Factor:   2,
+			Jitter:   0.1,
+			Steps:    math.MaxInt32,
+			Cap:      30 * time.Second,
+		},
+		httpClient: &http.Client{
+			Transport: &http.Transport{TLSClientConfig: tlsConfig},
+		},
+	}
+	if webhook.SigningSecretRef != nil {
+		secretName := webhook.SigningSecretRef.Name
+		n.signingSecret = func(ctx context.Context) ([]byte, error) {
+			secret := &corev1.Secret{}
+			if err := client.Get(ctx, secret, namespace, secretName); err != nil {
+				return nil, errors.Wrapf(err, "Error retrieving signing secret %q", secretName)
+			}
+			value, ok := secret.Data[SigningSecretKey]
+			if !ok || len(value) == 0 {
+				return nil, errors.Errorf("Signing secret %q has no %q key", secretName, SigningSecretKey)
+			}
+			return value, nil
+		}
+	}
+	return n, nil
+}
+
+// Notify sends the notification to the webhook, retrying with
+// exponential backoff while the webhook cannot be reached or responds
+// with a server error. A notification that cannot be delivered is
+// logged as a dead letter and an error is returned.
+func (n *webhookNotifier) Notify(ctx context.Context, notification *Notification) error {
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return errors.Wrap(err, "Error encoding propagation notification")
+	}
+
+	attempts, err := n.deliver(ctx, body)
+	if err != nil {
+		klog.Errorf("Dead letter: failed to deliver propagation notification for %s %q after %d attempt(s): %v; notification: %s",
+			notification.Kind, qualifiedName(notification), attempts, err, body)
+		return errors.Wrapf(err, "Failed to deliver propagation notification after %d attempt(s)", attempts)
+	}
+	return nil
+}
+
+// deliver sends the body until it is accepted or a permanent failure
+// occurs, and returns the number of attempts made.
+func (n *webhookNotifier) deliver(ctx context.Context, body []byte) (int, error) {
+	var secret []byte
+	if n.signingSecret != nil {
+		var err error
+		secret, err = n.signingSecret(ctx)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	backoff := n.backoff
+	attempts := 0
+	for {
+		attempts++
+		retriable, err := n.send(ctx, body, secret)
+		if err == nil || !retriable || attempts > n.retries {
+			return attempts, err
+		}
+		klog.V(4).Infof("Retrying propagation notification: %v", err)
+		select {
+		case <-ctx.Done():
+			return attempts, errors.Wrap(ctx.Err(), err.Error())
+		case <-time.After(backoff.Step()):
+		}
+	}
+}
+
+// send makes a single attempt to deliver the body, and returns whether
+// a failure may be resolved by retrying.
+func (n *webhookNotifier) send(ctx context.Context, body, secret []byte) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, n.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return false, errors.Wrap(err, "Error creating propagation notification request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if secret != nil {
+		req.Header.Set(SignatureHeader, Sign(secret, time.Now(), body))
+	}
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return true, errors.Wrap(err, "Error calling propagation webhook")
+	}
+	defer resp.Body.Close()
+	// Drain the body so that the connection can be reused.
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retriable := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+	return retriable, errors.Errorf("Propagation webhook returned %s", resp.Status)
+}
+
+func qualifiedName(notification *Notification) string {
+	if len(notification.Namespace) == 0 {
+		return notification.Name
+	}
+	return notification.Namespace + "/" + notification.Name
+}
@@ -0,0 +1,197 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	fedv1b1 "sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
+	genericclient "sigs.k8s.io/kubefed/pkg/client/generic"
+)
+
+const (
+	testNamespace  = "kube-federation-system"
+	testSecretName = "webhook-signing"
+)
+
+var testSecret = []byte("shared-secret")
+
+// secretClient returns the signing secret. Methods that are not
+// overridden panic via the nil embedded interface.
+type secretClient struct {
+	genericclient.Client
+}
+
+func (c *secretClient) Get(ctx context.Context, obj runtimeclient.Object, namespace, name string) error {
+	if namespace != testNamespace || name != testSecretName {
+		return apierrors.NewNotFound(schema.GroupResource{Resource: "secrets"}, name)
+	}
+	obj.(*corev1.Secret).Data = map[string][]byte{SigningSecretKey: testSecret}
+	return nil
+}
+
+// newFakeWebhook returns a TLS server that verifies the signature of
+// each notification and responds with the status codes in order,
+// repeating the last one, and the configuration to call it.
+func newFakeWebhook(t *testing.T, statusCodes []int, attempts *int32) (*httptest.Server, *fedv1b1.PropagationWebhook) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		attempt := int(atomic.AddInt32(attempts, 1))
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := VerifySignature(testSecret, req.Header.Get(SignatureHeader), body, time.Minute); err != nil {
+			t.Errorf("Expected the signature to verify against the configured secret: %v", err)
+		}
+		notification := &Notification{}
+		if err := json.Unmarshal(body, notification); err != nil {
+			t.Errorf("Error decoding notification: %v", err)
+		}
+		w.WriteHeader(statusCodes[min(attempt, len(statusCodes))-1])
+	}))
+	caBundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	return server, &fedv1b1.PropagationWebhook{
+		URL:              server.URL + "/notify",
+		CABundle:         caBundle,
+		SigningSecretRef: &fedv1b1.LocalSecretReference{Name: testSecretName},
+	}
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	testCases := map[string]struct {
+		statusCodes      []int
+		retries          int32
+		expectedAttempts int32
+		expectedError    string
+	}{
+		"notification is delivered": {
+			statusCodes:      []int{http.StatusOK},
+			retries:          3,
+			expectedAttempts: 1,
+		},
+		"server errors are retried": {
+			statusCodes:      []int{http.StatusServiceUnavailable, http.StatusInternalServerError, http.StatusNoContent},
+			retries:          3,
+			expectedAttempts: 3,
+		},
+		"notification is dead-lettered once retries are exhausted": {
+			statusCodes:      []int{http.StatusBadGateway},
+			retries:          2,
+			expectedAttempts: 3,
+			expectedError:    "after 3 attempt(s)",
+		},
+		"client errors are not retried": {
+			statusCodes:      []int{http.StatusBadRequest},
+			retries:          3,
+			expectedAttempts: 1,
+			expectedError:    "400 Bad Request",
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			var attempts int32
+			server, webhook := newFakeWebhook(t, tc.statusCodes, &attempts)
+			defer server.Close()
+			webhook.Retries = &tc.retries
+
+			notifier, err := NewWebhookNotifier(webhook, &secretClient{}, testNamespace)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			notifier.(*webhookNotifier).backoff = wait.Backoff{Duration: time.Millisecond, Factor: 2, Steps: 10}
+
+			err = notifier.Notify(context.Background(), &Notification{
+				Kind:       "FederatedConfigMap",
+				Namespace:  "ns",
+				Name:       "foo",
+				Generation: 2,
+				Clusters:   []string{"cluster1", "cluster2"},
+			})
+			if len(tc.expectedError) == 0 && err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(tc.expectedError) > 0 && (err == nil || !strings.Contains(err.Error(), tc.expectedError)) {
+				t.Fatalf("Expected error containing %q, got %v", tc.expectedError, err)
+			}
+			if attempts != tc.expectedAttempts {
+				t.Errorf("Expected %d attempts, got %d", tc.expectedAttempts, attempts)
+			}
+		})
+	}
+}
+
+func TestVerifySignature(t *testing.T) {
+	body := []byte(`{"name":"foo"}`)
+	now := time.Now()
+	testCases := map[string]struct {
+		header        string
+		body          []byte
+		expectedError string
+	}{
+		"valid signature": {
+			header: Sign(testSecret, now, body),
+			body:   body,
+		},
+		"modified body": {
+			header:        Sign(testSecret, now, body),
+			body:          []byte(`{"name":"bar"}`),
+			expectedError: "does not match",
+		},
+		"different secret": {
+			header:        Sign([]byte("other"), now, body),
+			body:          body,
+			expectedError: "does not match",
+		},
+		"expired signature": {
+			header:        Sign(testSecret, now.Add(-time.Hour), body),
+			body:          body,
+			expectedError: "older than",
+		},
+		"malformed header": {
+			header:        "sha256=abc",
+			body:          body,
+			expectedError: "must have the form",
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			err := VerifySignature(testSecret, tc.header, tc.body, 5*time.Minute)
+			if len(tc.expectedError) == 0 && err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(tc.expectedError) > 0 && (err == nil || !strings.Contains(err.Error(), tc.expectedError)) {
+				t.Fatalf("Expected error containing %q, got %v", tc.expectedError, err)
+			}
+		})
+	}
+}
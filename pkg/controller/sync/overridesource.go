@@ -0,0 +1,206 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sync
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	restclient "k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/kubefed/pkg/controller/utils"
+)
+
+// overrideSourceSyncTimeout is the time to wait for the cache of a
+// kind of override source to sync when it is first referenced.
+const overrideSourceSyncTimeout = 30 * time.Second
+
+// sourceInformerFunc returns an informer for the ConfigMaps or Secrets
+// of the host cluster that invokes the given function for each change.
+type sourceInformerFunc func(kind string, triggerFunc func(runtimeclient.Object)) (cache.Store, cache.Controller, error)
+
+type sourceInformer struct {
+	store      cache.Store
+	controller cache.Controller
+	// waited is closed once the initial wait for the cache to sync
+	// has ended, whether or not the cache synced.
+	waited chan struct{}
+}
+
+// overrideSourceWatcher resolves the ConfigMaps and Secrets referenced
+// by the overrides of federated resources and enqueues the federated
+// resources that reference a source when the source changes. The
+// informer for a kind of source is only started once the kind is
+// first referenced, and only caches the objects labeled as override
+// sources.
+type overrideSourceWatcher struct {
+	ctx         context.Context
+	newInformer sourceInformerFunc
+	enqueue     func(utils.QualifiedName)
+	syncTimeout time.Duration
+
+	informersLock sync.Mutex
+	informers     map[string]*sourceInformer
+
+	// Index from each referenced source to the names of the federated
+	// resources whose overrides reference it, and the reverse.
+	indexLock  sync.RWMutex
+	dependents map[utils.OverrideSource]sets.Set[utils.QualifiedName]
+	sources    map[utils.QualifiedName][]utils.OverrideSource
+}
+
+func newOverrideSourceWatcher(ctx context.Context, kubeConfig *restclient.Config, namespace string, enqueue func(utils.QualifiedName)) *overrideSourceWatcher {
+	newInformer := func(kind string, triggerFunc func(runtimeclient.Object)) (cache.Store, cache.Controller, error) {
+		apiResource := &metav1.APIResource{
+			Version:    "v1",
+			Kind:       kind,
+			Name:       strings.ToLower(kind) + "s",
+			Namespaced: true,
+		}
+		client, err := utils.NewResourceClient(kubeConfig, apiResource, utils.WithRequestMetrics())
+		if err != nil {
+			return nil, nil, err
+		}
+		store, controller := utils.NewOverrideSourceInformer(client, namespace, apiResource, triggerFunc)
+		return store, controller, nil
+	}
+	return newOverrideSourceWatcherWithInformers(ctx, newInformer, enqueue)
+}
+
+func newOverrideSourceWatcherWithInformers(ctx context.Context, newInformer sourceInformerFunc, enqueue func(utils.QualifiedName)) *overrideSourceWatcher {
+	return &overrideSourceWatcher{
+		ctx:         ctx,
+		newInformer: newInformer,
+		enqueue:     enqueue,
+		syncTimeout: overrideSourceSyncTimeout,
+		informers:   make(map[string]*sourceInformer),
+		dependents:  make(map[utils.OverrideSource]sets.Set[utils.QualifiedName]),
+		sources:     make(map[utils.QualifiedName][]utils.OverrideSource),
+	}
+}
+
+// SetDependent records the sources referenced by the overrides of the
+// named federated resource, replacing those previously recorded. A
+// resource that no longer exists references no sources.
+func (w *overrideSourceWatcher) SetDependent(fedName utils.QualifiedName, sources []utils.OverrideSource) {
+	w.indexLock.Lock()
+	defer w.indexLock.Unlock()
+	for _, source := range w.sources[fedName] {
+		w.dependents[source].Delete(fedName)
+		if w.dependents[source].Len() == 0 {
+			delete(w.dependents, source)
+		}
+	}
+	if len(sources) == 0 {
+		delete(w.sources, fedName)
+		return
+	}
+	w.sources[fedName] = sources
+	for _, source := range sources {
+		if _, ok := w.dependents[source]; !ok {
+			w.dependents[source] = sets.New[utils.QualifiedName]()
+		}
+		w.dependents[source].Insert(fedName)
+	}
+}
+
+// Dependents returns the names of the federated resources whose
+// overrides reference the given source.
+func (w *overrideSourceWatcher) Dependents(source utils.OverrideSource) []utils.QualifiedName {
+	w.indexLock.RLock()
+	defer w.indexLock.RUnlock()
+	return w.dependents[source].UnsortedList()
+}
+
+// Lookup returns the data of the given source, or nil if it does not
+// exist.
+func (w *overrideSourceWatcher) Lookup(source utils.OverrideSource) (map[string]string, error) {
+	store, err := w.storeFor(source.Kind)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := utils.ObjFromCache(store, source.Kind, source.Name.String())
+	if err != nil || obj == nil {
+		return nil, err
+	}
+	return utils.OverrideSourceData(obj)
+}
+
+// storeFor returns the synced store of the given kind of source,
+// starting its informer if the kind has not yet been referenced. Only
+// the first lookup of a kind waits for its cache to sync. If the cache
+// did not sync in time, later lookups fail immediately until the
+// informer catches up.
+func (w *overrideSourceWatcher) storeFor(kind string) (cache.Store, error) {
+	if kind != utils.ConfigMapKind && kind != utils.SecretKind {
+		return nil, errors.Errorf("%s is not a supported kind of override source", kind)
+	}
+
+	informer, err := w.informerFor(kind)
+	if err != nil {
+		return nil, err
+	}
+	if !informer.controller.HasSynced() {
+		<-informer.waited
+		if !informer.controller.HasSynced() {
+			return nil, errors.Errorf("The cache of %s override sources has not synced", kind)
+		}
+	}
+	return informer.store, nil
+}
+
+// informerFor returns the informer of the given kind of source,
+// starting it if needed. The lock is not held while waiting for the
+// cache to sync so that lookups of other kinds are not blocked.
+func (w *overrideSourceWatcher) informerFor(kind string) (*sourceInformer, error) {
+	w.informersLock.Lock()
+	defer w.informersLock.Unlock()
+	if informer, ok := w.informers[kind]; ok {
+		return informer, nil
+	}
+	store, controller, err := w.newInformer(kind, func(obj runtimeclient.Object) {
+		w.enqueueDependents(kind, obj)
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to watch %s override sources", kind)
+	}
+	informer := &sourceInformer{store: store, controller: controller, waited: make(chan struct{})}
+	w.informers[kind] = informer
+	go controller.Run(w.ctx.Done())
+	go func() {
+		defer close(informer.waited)
+		ctx, cancel := context.WithTimeout(w.ctx, w.syncTimeout)
+		defer cancel()
+		cache.WaitForCacheSync(ctx.Done(), controller.HasSynced)
+	}()
+	return informer, nil
+}
+
+func (w *overrideSourceWatcher) enqueueDependents(kind string, obj runtimeclient.Object) {
+	source := utils.OverrideSource{Kind: kind, Name: utils.NewQualifiedName(obj)}
+	for _, fedName := range w.Dependents(source) {
+		w.enqueue(fedName)
+	}
+}
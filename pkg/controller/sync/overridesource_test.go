@@ -0,0 +1,253 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sync
+
+import (
+	"context"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/cache"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	fedv1b1 "sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
+	"sigs.k8s.io/kubefed/pkg/controller/utils"
+)
+
+type fakeSourceController struct{}
+
+func (fakeSourceController) RunWithContext(ctx context.Context) {}
+func (fakeSourceController) Run(stopCh <-chan struct{})         {}
+func (fakeSourceController) HasSynced() bool                    { return true }
+func (fakeSourceController) LastSyncResourceVersion() string    { return "" }
+
+type unsyncedSourceController struct {
+	fakeSourceController
+	synced *atomic.Bool
+}
+
+func (c unsyncedSourceController) HasSynced() bool { return c.synced.Load() }
+
+func newTestConfigMap(build string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       utils.ConfigMapKind,
+			"metadata": map[string]interface{}{
+				"name":      "release",
+				"namespace": "bar",
+			},
+			"data": map[string]interface{}{
+				"build": build,
+			},
+		},
+	}
+}
+
+func TestOverrideSourceChangeRepropagates(t *testing.T) {
+	store := cache.NewStore(cache.MetaNamespaceKeyFunc)
+	var triggerFunc func(runtimeclient.Object)
+	newInformer := func(kind string, trigger func(runtimeclient.Object)) (cache.Store, cache.Controller, error) {
+		if kind != utils.ConfigMapKind {
+			t.Fatalf("Unexpected informer for %s", kind)
+		}
+		triggerFunc = trigger
+		return store, fakeSourceController{}, nil
+	}
+	var enqueued []utils.QualifiedName
+	watcher := newOverrideSourceWatcherWithInformers(context.Background(), newInformer, func(name utils.QualifiedName) {
+		enqueued = append(enqueued, name)
+	})
+
+	fedName := utils.QualifiedName{Namespace: "bar", Name: "foo"}
+	fedObject := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"name":      "foo",
+				"namespace": "bar",
+			},
+			"spec": map[string]interface{}{
+				"template": map[string]interface{}{
+					"data": map[string]interface{}{
+						"build": "0",
+					},
+				},
+				"overrides": []interface{}{
+					map[string]interface{}{
+						"clusterName": "cluster1",
+						"clusterOverrides": []interface{}{
+							map[string]interface{}{
+								"path": "/data/build",
+								"valueFrom": map[string]interface{}{
+									"configMapKeyRef": map[string]interface{}{
+										"name": "release",
+										"key":  "build",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	overridesMap, err := utils.GetOverrides(fedObject)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	watcher.SetDependent(fedName, overridesMap.ValueSources(fedName.Namespace))
+
+	fedResource := &federatedResource{
+		typeConfig: &fedv1b1.FederatedTypeConfig{
+			Spec: fedv1b1.FederatedTypeConfigSpec{
+				TargetType: fedv1b1.APIResource{
+					Version: "v1",
+					Kind:    utils.ConfigMapKind,
+				},
+			},
+		},
+		targetName:        fedName,
+		federatedName:     fedName,
+		federatedResource: fedObject,
+		overrideSources:   watcher,
+	}
+	appliedBuild := func() (string, error) {
+		obj, err := fedResource.ObjectForCluster("cluster1")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if err := fedResource.ApplyOverrides(obj, "cluster1"); err != nil {
+			return "", err
+		}
+		build, _, _ := unstructured.NestedString(obj.Object, "data", "build")
+		return build, nil
+	}
+
+	if err := store.Add(newTestConfigMap("1")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	build, err := appliedBuild()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if build != "1" {
+		t.Fatalf("Expected the value of the ConfigMap to be applied, got %q", build)
+	}
+	version, err := fedResource.ClusterOverrideVersion("cluster1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	updated := newTestConfigMap("2")
+	if err := store.Update(updated); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	triggerFunc(updated)
+	if expected := []utils.QualifiedName{fedName}; !reflect.DeepEqual(enqueued, expected) {
+		t.Fatalf("Expected %v to be enqueued, got %v", expected, enqueued)
+	}
+	updatedVersion, err := fedResource.ClusterOverrideVersion("cluster1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if updatedVersion == version {
+		t.Fatalf("Expected the override version to change with the value of the ConfigMap")
+	}
+	build, err = appliedBuild()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if build != "2" {
+		t.Fatalf("Expected the updated value of the ConfigMap to be applied, got %q", build)
+	}
+
+	if err := store.Delete(updated); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := appliedBuild(); !utils.IsOverrideSourceNotFound(err) {
+		t.Fatalf("Expected the ConfigMap not to be found, got %v", err)
+	}
+
+	watcher.SetDependent(fedName, nil)
+	enqueued = nil
+	triggerFunc(updated)
+	if len(enqueued) != 0 {
+		t.Fatalf("Expected nothing to be enqueued once the overrides no longer reference the ConfigMap, got %v", enqueued)
+	}
+}
+
+func TestOverrideSourceSyncFailureIsCached(t *testing.T) {
+	var secretsSynced atomic.Bool
+	newInformer := func(kind string, trigger func(runtimeclient.Object)) (cache.Store, cache.Controller, error) {
+		store := cache.NewStore(cache.MetaNamespaceKeyFunc)
+		if kind == utils.SecretKind {
+			return store, unsyncedSourceController{synced: &secretsSynced}, nil
+		}
+		return store, fakeSourceController{}, nil
+	}
+	watcher := newOverrideSourceWatcherWithInformers(context.Background(), newInformer, func(utils.QualifiedName) {})
+	watcher.syncTimeout = time.Second
+	secret := utils.OverrideSource{Kind: utils.SecretKind, Name: utils.QualifiedName{Namespace: "bar", Name: "credentials"}}
+	configMap := utils.OverrideSource{Kind: utils.ConfigMapKind, Name: utils.QualifiedName{Namespace: "bar", Name: "release"}}
+
+	secretErr := make(chan error)
+	go func() {
+		_, err := watcher.Lookup(secret)
+		secretErr <- err
+	}()
+	// Wait for the informer of Secrets to be started.
+	for {
+		watcher.informersLock.Lock()
+		_, started := watcher.informers[utils.SecretKind]
+		watcher.informersLock.Unlock()
+		if started {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if _, err := watcher.Lookup(configMap); err != nil {
+		t.Fatalf("Expected the lookup of a ConfigMap not to wait for the cache of Secrets, got %v", err)
+	}
+	select {
+	case <-secretErr:
+		t.Fatalf("Expected the lookup of a Secret to wait for its cache to sync")
+	default:
+	}
+	if err := <-secretErr; err == nil {
+		t.Fatalf("Expected the lookup of a Secret to fail when its cache does not sync")
+	}
+
+	start := time.Now()
+	if _, err := watcher.Lookup(secret); err == nil {
+		t.Fatalf("Expected the lookup of a Secret to fail while its cache has not synced")
+	}
+	if elapsed := time.Since(start); elapsed >= watcher.syncTimeout {
+		t.Fatalf("Expected the failure to sync to be cached, the lookup took %v", elapsed)
+	}
+
+	secretsSynced.Store(true)
+	data, err := watcher.Lookup(secret)
+	if err != nil {
+		t.Fatalf("Expected the lookup of a Secret to succeed once its cache has synced, got %v", err)
+	}
+	if data != nil {
+		t.Fatalf("Expected no data for a missing Secret, got %v", data)
+	}
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sync
+
+import (
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"sigs.k8s.io/kubefed/pkg/controller/sync/status"
+	"sigs.k8s.io/kubefed/pkg/metrics"
+)
+
+// federatedObjectPhase derives the phase of a federated object from
+// its propagation status.
+func federatedObjectPhase(obj *unstructured.Unstructured) string {
+	resource, err := status.DecodeGenericFederatedResource(obj)
+	if err != nil || resource.Status == nil {
+		return metrics.FederatedObjectPropagating
+	}
+	if resource.Status.ObservedGeneration != obj.GetGeneration() {
+		return metrics.FederatedObjectPropagating
+	}
+	for _, condition := range resource.Status.Conditions {
+		if condition.Type != status.PropagationConditionType {
+			continue
+		}
+		switch {
+		case condition.Status == apiv1.ConditionTrue:
+			return metrics.FederatedObjectHealthy
+		case condition.Reason == status.NamespaceNotFederated:
+			// Propagation will resume once the containing namespace
+			// is federated.
+			return metrics.FederatedObjectPaused
+		case condition.Reason == status.PropagationDeferred:
+			// Propagation will resume once the propagation window
+			// of the type opens.
+			return metrics.FederatedObjectPaused
+		case condition.Reason == status.PropagationPaused:
+			// Propagation will resume once the pause of the
+			// control plane is lifted.
+			return metrics.FederatedObjectPaused
+		default:
+			return metrics.FederatedObjectFailed
+		}
+	}
+	return metrics.FederatedObjectPropagating
+}
+
+// countFederatedObjectPhases counts the given federated objects by phase.
+func countFederatedObjectPhases(objs []*unstructured.Unstructured) map[string]int {
+	counts := make(map[string]int)
+	for _, obj := range objs {
+		counts[federatedObjectPhase(obj)]++
+	}
+	return counts
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sync
+
+import (
+	"reflect"
+	"testing"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"sigs.k8s.io/kubefed/pkg/controller/sync/status"
+	"sigs.k8s.io/kubefed/pkg/metrics"
+)
+
+func newPhaseTestObject(generation int64) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	obj.SetGeneration(generation)
+	return obj
+}
+
+func setPropagationCondition(obj *unstructured.Unstructured, observedGeneration int64, conditionStatus apiv1.ConditionStatus, reason status.AggregateReason) {
+	obj.Object["status"] = map[string]interface{}{
+		"observedGeneration": observedGeneration,
+		"conditions": []interface{}{
+			map[string]interface{}{
+				"type":   string(status.PropagationConditionType),
+				"status": string(conditionStatus),
+				"reason": string(reason),
+			},
+		},
+	}
+}
+
+func TestFederatedObjectPhase(t *testing.T) {
+	testCases := map[string]struct {
+		observedGeneration int64
+		conditionStatus    apiv1.ConditionStatus
+		reason             status.AggregateReason
+		noStatus           bool
+		expectedPhase      string
+	}{
+		"no status is propagating": {
+			noStatus:      true,
+			expectedPhase: metrics.FederatedObjectPropagating,
+		},
+		"stale generation is propagating": {
+			observedGeneration: 1,
+			conditionStatus:    apiv1.ConditionTrue,
+			expectedPhase:      metrics.FederatedObjectPropagating,
+		},
+		"propagation condition true is healthy": {
+			observedGeneration: 2,
+			conditionStatus:    apiv1.ConditionTrue,
+			expectedPhase:      metrics.FederatedObjectHealthy,
+		},
+		"propagation condition false is failed": {
+			observedGeneration: 2,
+			conditionStatus:    apiv1.ConditionFalse,
+			reason:             status.CheckClusters,
+			expectedPhase:      metrics.FederatedObjectFailed,
+		},
+		"namespace not federated is paused": {
+			observedGeneration: 2,
+			conditionStatus:    apiv1.ConditionFalse,
+			reason:             status.NamespaceNotFederated,
+			expectedPhase:      metrics.FederatedObjectPaused,
+		},
+		"deferred propagation is paused": {
+			observedGeneration: 2,
+			conditionStatus:    apiv1.ConditionFalse,
+			reason:             status.PropagationDeferred,
+			expectedPhase:      metrics.FederatedObjectPaused,
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			obj := newPhaseTestObject(2)
+			if !tc.noStatus {
+				setPropagationCondition(obj, tc.observedGeneration, tc.conditionStatus, tc.reason)
+			}
+			if phase := federatedObjectPhase(obj); phase != tc.expectedPhase {
+				t.Fatalf("Expected phase %q, got %q", tc.expectedPhase, phase)
+			}
+		})
+	}
+}
+
+func TestCountFederatedObjectPhases(t *testing.T) {
+	first := newPhaseTestObject(1)
+	second := newPhaseTestObject(1)
+	objs := []*unstructured.Unstructured{first, second}
+
+	expectCounts := func(expected map[string]int) {
+		t.Helper()
+		if counts := countFederatedObjectPhases(objs); !reflect.DeepEqual(counts, expected) {
+			t.Fatalf("Expected counts %v, got %v", expected, counts)
+		}
+	}
+
+	expectCounts(map[string]int{metrics.FederatedObjectPropagating: 2})
+
+	setPropagationCondition(first, 1, apiv1.ConditionTrue, status.AggregateSuccess)
+	expectCounts(map[string]int{metrics.FederatedObjectPropagating: 1, metrics.FederatedObjectHealthy: 1})
+
+	setPropagationCondition(second, 1, apiv1.ConditionFalse, status.CheckClusters)
+	expectCounts(map[string]int{metrics.FederatedObjectHealthy: 1, metrics.FederatedObjectFailed: 1})
+
+	// Removal of an object is reflected when counts are re-derived.
+	objs = objs[:1]
+	expectCounts(map[string]int{metrics.FederatedObjectHealthy: 1})
+}
@@ -0,0 +1,52 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sync
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	fedv1b1 "sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
+	"sigs.k8s.io/kubefed/pkg/controller/sync/dispatch"
+	"sigs.k8s.io/kubefed/pkg/controller/sync/status"
+	"sigs.k8s.io/kubefed/pkg/controller/utils"
+)
+
+// drainForRemoval drains the resource in the given cluster according
+// to the given removal strategy. The pre-delete patch is dispatched
+// if draining has not yet started, conditional on the resource
+// being unchanged since it was read. Returns whether the drain duration
+// has elapsed so that the resource may be deleted and, if not, how
+// long remains until it may be.
+func drainForRemoval(dispatcher dispatch.ManagedDispatcher, strategy *fedv1b1.RemovalStrategy, clusterName string,
+	clusterObj *unstructured.Unstructured, now time.Time) (bool, time.Duration, error) {
+	started, remaining := utils.DrainRemaining(strategy, clusterObj, now)
+	if !started {
+		patch, err := utils.DrainPatch(strategy, now, clusterObj.GetResourceVersion())
+		if err != nil {
+			return false, 0, err
+		}
+		dispatcher.Drain(clusterName, clusterObj, patch)
+		return false, strategy.DrainDuration.Duration, nil
+	}
+	if remaining > 0 {
+		dispatcher.RecordStatus(clusterName, status.Draining, clusterObj.Object[utils.StatusField])
+		return false, remaining, nil
+	}
+	return true, 0, nil
+}
@@ -0,0 +1,50 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sync
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"sigs.k8s.io/kubefed/pkg/controller/sync/dispatch"
+)
+
+// RenderForClusters returns the object that the given federated
+// resource would propagate to each of the named clusters, with the
+// template, any overrides for the cluster and any transformation
+// applied.
+func RenderForClusters(ctx context.Context, fedResource dispatch.FederatedResourceForDispatch, clusterNames []string) (map[string]*unstructured.Unstructured, error) {
+	objects := make(map[string]*unstructured.Unstructured, len(clusterNames))
+	for _, clusterName := range clusterNames {
+		obj, err := fedResource.ObjectForCluster(clusterName)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Error computing object for cluster %q", clusterName)
+		}
+		if err := fedResource.ApplyOverrides(obj, clusterName); err != nil {
+			return nil, errors.Wrapf(err, "Error applying overrides for cluster %q", clusterName)
+		}
+		obj, err = fedResource.Transform(ctx, obj, clusterName)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Error transforming object for cluster %q", clusterName)
+		}
+		objects[clusterName] = obj
+	}
+	return objects, nil
+}
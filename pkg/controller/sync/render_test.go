@@ -0,0 +1,99 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sync
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	fedv1b1 "sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
+	"sigs.k8s.io/kubefed/pkg/controller/utils"
+)
+
+func TestRenderForClusters(t *testing.T) {
+	fedObject := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"name":      "foo",
+				"namespace": "bar",
+			},
+			"spec": map[string]interface{}{
+				"template": map[string]interface{}{
+					"data": map[string]interface{}{
+						"key": "template",
+					},
+				},
+			},
+		},
+	}
+	fedResource := &federatedResource{
+		typeConfig: &fedv1b1.FederatedTypeConfig{
+			Spec: fedv1b1.FederatedTypeConfigSpec{
+				TargetType: fedv1b1.APIResource{
+					Version: "v1",
+					Kind:    "ConfigMap",
+				},
+			},
+		},
+		targetName:        utils.QualifiedName{Namespace: "bar", Name: "foo"},
+		federatedName:     utils.QualifiedName{Namespace: "bar", Name: "foo"},
+		federatedResource: fedObject,
+		overridesMap: utils.OverridesMap{
+			"cluster2": utils.ClusterOverrides{
+				{Path: "/data/key", Value: "override"},
+			},
+		},
+	}
+
+	objects, err := RenderForClusters(context.Background(), fedResource, []string{"cluster1", "cluster2"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expectedValues := map[string]string{
+		"cluster1": "template",
+		"cluster2": "override",
+	}
+	if len(objects) != len(expectedValues) {
+		t.Fatalf("Expected %d objects, got %d", len(expectedValues), len(objects))
+	}
+	for clusterName, expectedValue := range expectedValues {
+		obj, ok := objects[clusterName]
+		if !ok {
+			t.Fatalf("Expected an object for cluster %q", clusterName)
+		}
+		value, _, err := unstructured.NestedString(obj.Object, "data", "key")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if value != expectedValue {
+			t.Errorf("Expected value %q for cluster %q, got %q", expectedValue, clusterName, value)
+		}
+		if obj.GetName() != "foo" || obj.GetNamespace() != "bar" || obj.GetKind() != "ConfigMap" {
+			t.Errorf("Unexpected identity %s %s/%s for cluster %q", obj.GetKind(), obj.GetNamespace(), obj.GetName(), clusterName)
+		}
+		if obj.GetLabels()[utils.ManagedByKubeFedLabelKey] != utils.ManagedByKubeFedLabelValue {
+			t.Errorf("Expected the managed label to be set for cluster %q", clusterName)
+		}
+	}
+	// The template must not be mutated by rendering.
+	if value, _, _ := unstructured.NestedString(fedObject.Object, "spec", "template", "data", "key"); value != "template" {
+		t.Errorf("Expected the template to be unchanged, got %q", value)
+	}
+}
@@ -17,25 +17,31 @@ limitations under the License.
 package sync
 
 import (
+	"context"
 	"crypto/md5"
 	"encoding/hex"
 	"fmt"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 
 	corev1 "k8s.io/api/core/v1"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
 
 	"sigs.k8s.io/kubefed/pkg/apis/core/typeconfig"
 	fedv1b1 "sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
 	"sigs.k8s.io/kubefed/pkg/controller/sync/dispatch"
+	"sigs.k8s.io/kubefed/pkg/controller/sync/status"
+	"sigs.k8s.io/kubefed/pkg/controller/sync/transform"
 	"sigs.k8s.io/kubefed/pkg/controller/sync/version"
 	"sigs.k8s.io/kubefed/pkg/controller/utils"
 )
@@ -51,7 +57,18 @@ type FederatedResource interface {
 	UpdateVersions(selectedClusters []string, versionMap map[string]string) error
 	DeleteVersions()
 	ComputePlacement(clusters []*fedv1b1.KubeFedCluster) (selectedClusters sets.Set[string], err error)
+	SelectClusters(clusters []*fedv1b1.KubeFedCluster) (selectedClusters sets.Set[string], err error)
+	RetainedClusters() map[string]time.Time
+	SetPlacement(clusterNames sets.Set[string])
+	PlacementOnlyClusters() (sets.Set[string], error)
+	CanaryClusters() (sets.Set[string], error)
+	MinHealthyClusters() (*int32, error)
+	PropagationDeadline() (*time.Duration, error)
+	RemovalStrategy() (*fedv1b1.RemovalStrategy, error)
+	DeletionGracePeriod() (time.Duration, error)
+	OverrideClusterNames() (sets.Set[string], error)
 	NamespaceNotFederated() bool
+	RemoveManagedMetadata(obj *unstructured.Unstructured)
 }
 
 type federatedResource struct {
@@ -70,6 +87,35 @@ type federatedResource struct {
 	namespace         *unstructured.Unstructured
 	fedNamespace      *unstructured.Unstructured
 	eventRecorder     record.EventRecorder
+	transformer       transform.Transformer
+	pruneSchema       *apiextv1.JSONSchemaProps
+	targetSchema      *apiextv1.JSONSchemaProps
+
+	managedLabels      map[string]string
+	managedAnnotations map[string]string
+	selectorFields     []string
+
+	// The key of the annotation listing the clusters that the
+	// resource is propagated to, and those clusters as last recorded
+	// by SetPlacement.
+	placementAnnotation string
+	placement           sets.Set[string]
+
+	// The data that templated overrides are evaluated against for
+	// each cluster, as recorded by ComputePlacement.
+	templateData map[string]*utils.OverrideTemplateData
+
+	// Resolves the ConfigMaps and Secrets referenced by overrides.
+	// Nil for cluster-scoped federated types.
+	overrideSources *overrideSourceWatcher
+
+	// The times until which clusters that are no longer selected
+	// remain placed, as recorded by ComputePlacement.
+	retainedUntil map[string]time.Time
+	// The retention of clusters last determined for each federated
+	// resource of the type, keyed by federated name, so that a
+	// retention that could not be written to status is not restarted.
+	retainedClusters *utils.SafeMap
 }
 
 func (r *federatedResource) FederatedName() utils.QualifiedName {
@@ -93,6 +139,14 @@ func (r *federatedResource) TargetGVK() schema.GroupVersionKind {
 	return apiResourceToGVK(&apiResource)
 }
 
+func (r *federatedResource) IncludedFields() []string {
+	return r.typeConfig.GetIncludedFields()
+}
+
+func (r *federatedResource) ServerManagedMetadata() fedv1b1.ServerManagedMetadata {
+	return r.typeConfig.GetServerManagedMetadata()
+}
+
 func (r *federatedResource) Object() *unstructured.Unstructured {
 	return r.federatedResource
 }
@@ -103,22 +157,63 @@ func (r *federatedResource) TemplateVersion() (string, error) {
 }
 
 func (r *federatedResource) OverrideVersion() (string, error) {
-	// TODO(marun) Consider hashing overrides per cluster to minimize
-	// unnecessary updates.
 	return GetOverrideHash(r.federatedResource)
 }
 
+// ClusterOverrideVersion returns the hash of the overrides for the
+// named cluster. Templated overrides are hashed as evaluated for the
+// cluster, and overrides with value sources with their resolved
+// values, so that a change to the parameters of the cluster or to a
+// source invalidates the version propagated to it. If they cannot be
+// evaluated, resolved or validated, the unevaluated overrides are
+// hashed instead, which never matches a version recorded for
+// successfully propagated overrides.
+func (r *federatedResource) ClusterOverrideVersion(clusterName string) (string, error) {
+	overrides, err := r.overridesForCluster(clusterName)
+	if err != nil {
+		overridesMap, overridesErr := r.overrides()
+		if overridesErr != nil {
+			return "", err
+		}
+		overrides = overridesMap[clusterName]
+	}
+	return GetClusterOverrideHash(overrides)
+}
+
+// HasUnversionedOverrides indicates whether any override of the
+// resource is cluster-local, templated or sources its value, since the
+// override version does not reflect a change to the overrides for a
+// cluster that results from any of them.
+func (r *federatedResource) HasUnversionedOverrides() (bool, error) {
+	overridesMap, err := r.overrides()
+	if err != nil {
+		return false, err
+	}
+	for _, overrides := range overridesMap {
+		if len(overrides.WithoutClusterLocal()) != len(overrides) || overrides.Templated() || overrides.HasValueSources() {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 func (r *federatedResource) VersionForCluster(clusterName string) (string, error) {
-	r.Lock()
-	defer r.Unlock()
-	if r.versionMap == nil {
+	r.RLock()
+	versionMap := r.versionMap
+	r.RUnlock()
+	if versionMap == nil {
+		// The lock is not held while retrieving versions since
+		// determining their validity requires the overrides.
 		var err error
-		r.versionMap, err = r.versionManager.Get(r)
+		versionMap, err = r.versionManager.Get(r)
 		if err != nil {
 			return "", err
 		}
+		r.Lock()
+		r.versionMap = versionMap
+		r.Unlock()
 	}
-	return r.versionMap[clusterName], nil
+	return versionMap[clusterName], nil
 }
 
 func (r *federatedResource) UpdateVersions(selectedClusters []string, versionMap map[string]string) error {
@@ -127,15 +222,162 @@ func (r *federatedResource) UpdateVersions(selectedClusters []string, versionMap
 
 func (r *federatedResource) DeleteVersions() {
 	r.versionManager.Delete(r.federatedName)
+	// Nor is the retention of clusters of a deleted resource needed.
+	r.recordRetainedClusters(nil)
 }
 
+// ComputePlacement returns the names of the given clusters that the
+// resource is placed in, and records the data that templated overrides
+// are evaluated against for each of them.
 func (r *federatedResource) ComputePlacement(clusters []*fedv1b1.KubeFedCluster) (sets.Set[string], error) {
+	templateData := make(map[string]*utils.OverrideTemplateData, len(clusters))
+	for _, cluster := range clusters {
+		templateData[cluster.Name] = utils.NewOverrideTemplateData(cluster)
+	}
+	r.Lock()
+	r.templateData = templateData
+	r.Unlock()
+
+	selectedClusters, err := r.SelectClusters(clusters)
+	if err != nil {
+		return nil, err
+	}
+
+	// Clusters that are no longer selected remain placed for the
+	// stickiness of placement, if any, so that a transient change of
+	// their labels does not remove and recreate the resource.
+	retainedUntil, err := r.computeRetainedClusters(clusters, selectedClusters)
+	if err != nil {
+		return nil, err
+	}
+	r.Lock()
+	r.retainedUntil = retainedUntil
+	r.Unlock()
+	for clusterName := range retainedUntil {
+		selectedClusters.Insert(clusterName)
+	}
+	return selectedClusters, nil
+}
+
+// SelectClusters returns the names of the given clusters that are
+// selected by the placement of the resource, without the clusters
+// that remain placed for the stickiness of placement. Unlike
+// ComputePlacement, nothing is recorded.
+func (r *federatedResource) SelectClusters(clusters []*fedv1b1.KubeFedCluster) (sets.Set[string], error) {
 	if r.typeConfig.GetNamespaced() {
 		return utils.ComputeNamespacedPlacement(r.federatedResource, r.fedNamespace, clusters, r.limitedScope, false)
 	}
 	return utils.ComputePlacement(r.federatedResource, clusters, false)
 }
 
+// computeRetainedClusters returns the times until which the given
+// clusters that are not selected remain placed. A cluster with a taint
+// that would remove the resource from it is not retained.
+func (r *federatedResource) computeRetainedClusters(clusters []*fedv1b1.KubeFedCluster, selectedClusters sets.Set[string]) (map[string]time.Time, error) {
+	stickiness, err := utils.GetPlacementStickiness(r.federatedResource)
+	if err != nil || stickiness == nil {
+		r.recordRetainedClusters(nil)
+		return nil, err
+	}
+	untoleratedClusters, err := utils.UntoleratedClusterNames(r.federatedResource, clusters)
+	if err != nil {
+		return nil, err
+	}
+	unselectedClusters := sets.Set[string]{}
+	for _, cluster := range clusters {
+		if !selectedClusters.Has(cluster.Name) && !untoleratedClusters.Has(cluster.Name) {
+			unselectedClusters.Insert(cluster.Name)
+		}
+	}
+	var recorded map[string]time.Time
+	if r.retainedClusters != nil {
+		if value, ok := r.retainedClusters.Get(r.federatedName.String()); ok {
+			recorded = value.(map[string]time.Time)
+		}
+	}
+	retainedUntil, err := status.RetainedClusters(r.federatedResource, unselectedClusters, *stickiness, recorded, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	r.recordRetainedClusters(retainedUntil)
+	return retainedUntil, nil
+}
+
+// recordRetainedClusters records the given retention of clusters for
+// subsequent computations of placement.
+func (r *federatedResource) recordRetainedClusters(retainedUntil map[string]time.Time) {
+	if r.retainedClusters == nil {
+		return
+	}
+	if len(retainedUntil) == 0 {
+		r.retainedClusters.Delete(r.federatedName.String())
+		return
+	}
+	r.retainedClusters.Store(r.federatedName.String(), retainedUntil)
+}
+
+// RetainedClusters returns the times until which clusters that are no
+// longer selected remain placed, keyed by cluster name, as determined
+// by the most recent computation of placement.
+func (r *federatedResource) RetainedClusters() map[string]time.Time {
+	r.RLock()
+	defer r.RUnlock()
+	return r.retainedUntil
+}
+
+// MinHealthyClusters returns the number of placed clusters that must
+// be healthy for propagation to be considered successful, or nil if
+// all placed clusters must be healthy.
+func (r *federatedResource) MinHealthyClusters() (*int32, error) {
+	return utils.GetMinHealthyClusters(r.federatedResource)
+}
+
+// PropagationDeadline returns the duration within which propagation
+// must complete before the resource is reported as failed, or nil if
+// no deadline applies.
+func (r *federatedResource) PropagationDeadline() (*time.Duration, error) {
+	return utils.GetPropagationDeadline(r.federatedResource)
+}
+
+// RemovalStrategy returns how resources are drained before they are
+// deleted from clusters no longer selected by placement, or nil if
+// they are deleted immediately.
+func (r *federatedResource) RemovalStrategy() (*fedv1b1.RemovalStrategy, error) {
+	return utils.GetRemovalStrategy(r.federatedResource, r.typeConfig.GetRemovalStrategy())
+}
+
+// DeletionGracePeriod returns how long managed resources are kept in
+// member clusters after the resource is deleted, or zero if they are
+// deleted immediately.
+func (r *federatedResource) DeletionGracePeriod() (time.Duration, error) {
+	return utils.GetDeletionGracePeriod(r.federatedResource, r.typeConfig.GetDeletionGracePeriod())
+}
+
+// PlacementOnlyClusters returns the names of the clusters that are
+// considered placed but to which resources should not be propagated.
+// Clusters that are placement-only for the containing federated
+// namespace are also placement-only for the resources it contains.
+func (r *federatedResource) PlacementOnlyClusters() (sets.Set[string], error) {
+	clusterNames, err := utils.GetPlacementOnlyClusterNames(r.federatedResource)
+	if err != nil {
+		return nil, err
+	}
+	if r.typeConfig.GetNamespaced() && r.fedNamespace != nil {
+		namespaceClusterNames, err := utils.GetPlacementOnlyClusterNames(r.fedNamespace)
+		if err != nil {
+			return nil, err
+		}
+		clusterNames = clusterNames.Union(namespaceClusterNames)
+	}
+	return clusterNames, nil
+}
+
+// CanaryClusters returns the names of the clusters that resources are
+// propagated to before the other placed clusters.
+func (r *federatedResource) CanaryClusters() (sets.Set[string], error) {
+	return utils.GetCanaryClusterNames(r.federatedResource)
+}
+
 func (r *federatedResource) NamespaceNotFederated() bool {
 	return r.typeConfig.GetNamespaced() && r.fedNamespace == nil
 }
@@ -165,6 +407,10 @@ func (r *federatedResource) IsNamespaceInHostCluster(clusterObj runtimeclient.Ob
 
 // TODO(marun) Marshall the template once per reconcile, not per-cluster
 func (r *federatedResource) ObjectForCluster(clusterName string) (*unstructured.Unstructured, error) {
+	return r.objectForCluster(clusterName, true)
+}
+
+func (r *federatedResource) objectForCluster(clusterName string, recordErrors bool) (*unstructured.Unstructured, error) {
 	templateBody, ok, err := unstructured.NestedMap(r.federatedResource.Object, utils.SpecField, utils.TemplateField)
 	if err != nil {
 		return nil, errors.Wrap(err, "Error retrieving template body")
@@ -179,11 +425,15 @@ func (r *federatedResource) ObjectForCluster(clusterName string) (*unstructured.
 	notSupportedTemplate := "metadata.%s cannot be set via template to avoid conflicting with controllers " +
 		"in member clusters. Consider using an override to add or remove elements from this collection."
 	if len(obj.GetAnnotations()) > 0 {
-		r.RecordError("AnnotationsNotSupported", errors.Errorf(notSupportedTemplate, "annotations"))
+		if recordErrors {
+			r.RecordError("AnnotationsNotSupported", errors.Errorf(notSupportedTemplate, "annotations"))
+		}
 		obj.SetAnnotations(nil)
 	}
 	if len(obj.GetFinalizers()) > 0 {
-		r.RecordError("FinalizersNotSupported", errors.Errorf(notSupportedTemplate, "finalizers"))
+		if recordErrors {
+			r.RecordError("FinalizersNotSupported", errors.Errorf(notSupportedTemplate, "finalizers"))
+		}
 		obj.SetFinalizers(nil)
 	}
 
@@ -192,16 +442,26 @@ func (r *federatedResource) ObjectForCluster(clusterName string) (*unstructured.
 	// clusters.
 	//
 	// TODO(marun) this should be documented
-	obj.SetName(r.federatedResource.GetName())
+	obj.SetName(r.targetName.Name)
+	if r.targetName.Name != r.federatedName.Name {
+		// Allow events for the resource to be mapped back to the
+		// federated resource when the name was computed.
+		obj.SetAnnotations(map[string]string{
+			utils.FederatedNameAnnotation: r.federatedName.Name,
+		})
+	}
 	if !r.targetIsNamespace {
 		namespace := utils.NamespaceForCluster(clusterName, r.federatedResource.GetNamespace())
 		obj.SetNamespace(namespace)
 	}
+	// If the template does not specify a kind or an api version,
+	// default them to the ones configured for the target type in the
+	// FTC. A mismatch with the FTC is detected before the object is
+	// applied.
 	targetAPIResource := r.typeConfig.GetTargetType()
-	obj.SetKind(targetAPIResource.Kind)
-
-	// If the template does not specify an api version, default it to
-	// the one configured for the target type in the FTC.
+	if len(obj.GetKind()) == 0 {
+		obj.SetKind(targetAPIResource.Kind)
+	}
 	if len(obj.GetAPIVersion()) == 0 {
 		obj.SetAPIVersion(fmt.Sprintf("%s/%s", targetAPIResource.Group, targetAPIResource.Version))
 	}
@@ -210,27 +470,121 @@ func (r *federatedResource) ObjectForCluster(clusterName string) (*unstructured.
 }
 
 // ApplyOverrides applies overrides for the named cluster to the given
-// object. The managed label is added afterwards to ensure labeling even if an
-// override was attempted.
+// object. Paths set by the overrides applied when the object was last
+// propagated are first reset to their template value so that removing
+// an override does not leave a stale value in fields retained from the
+// cluster object. The managed label is added afterwards to ensure
+// labeling even if an override was attempted.
 func (r *federatedResource) ApplyOverrides(obj *unstructured.Unstructured, clusterName string) error {
 	overrides, err := r.overridesForCluster(clusterName)
 	if err != nil {
 		return err
 	}
+	if appliedPaths := utils.GetAppliedOverridePaths(obj); len(appliedPaths) > 0 {
+		templateObj, err := r.objectForCluster(clusterName, false)
+		if err != nil {
+			return err
+		}
+		if err := utils.ResetOverriddenPaths(obj, templateObj, appliedPaths); err != nil {
+			return err
+		}
+	}
 	if overrides != nil {
 		if err := utils.ApplyJSONPatch(obj, overrides); err != nil {
 			return err
 		}
 	}
+	if err := utils.SetAppliedOverridePaths(obj, overrides); err != nil {
+		return err
+	}
 
 	// Ensure that resources managed by KubeFed always have the
 	// managed label.  The label is intended to be targeted by all the
 	// KubeFed controllers.
-	utils.AddManagedLabel(obj)
+	r.AddManagedMetadata(obj)
 
 	return nil
 }
 
+// AddManagedMetadata ensures that the given object has the managed
+// label, any labels and annotations configured for managed resources
+// and, if configured, the placement annotation. The labels are only
+// added to the metadata of the object, and a warning is recorded if
+// the label selectors of the type contain one of them.
+func (r *federatedResource) AddManagedMetadata(obj *unstructured.Unstructured) {
+	utils.AddManagedMetadata(obj, r.managedLabels, r.managedAnnotations)
+	if collisions := utils.ManagedSelectorLabels(obj, r.selectorFields, r.managedLabels); len(collisions) > 0 {
+		r.eventRecorder.Eventf(r.Object(), corev1.EventTypeWarning, "ManagedLabelInSelector",
+			"Selectors %s contain labels that KubeFed adds to managed resources and may not match the intended resources",
+			strings.Join(collisions, ", "))
+	}
+	if placement, ok := r.annotatedPlacement(); ok {
+		utils.AddPlacementAnnotation(obj, r.placementAnnotation, placement)
+	}
+}
+
+// RemoveManagedMetadata removes the metadata added by
+// AddManagedMetadata from the given object.
+func (r *federatedResource) RemoveManagedMetadata(obj *unstructured.Unstructured) {
+	utils.RemoveManagedMetadata(obj, r.managedLabels, r.managedAnnotations, r.placementAnnotation)
+}
+
+// SetPlacement records the clusters that the resource is propagated
+// to for the placement annotation.
+func (r *federatedResource) SetPlacement(clusterNames sets.Set[string]) {
+	r.Lock()
+	defer r.Unlock()
+	r.placement = clusterNames
+}
+
+// PlacementAnnotationOutdated returns whether the placement annotation
+// of the given cluster object does not list the clusters that the
+// resource is propagated to. The annotation is not part of the
+// template, so a change of placement does not change the versions of
+// the resource and has to be detected separately.
+func (r *federatedResource) PlacementAnnotationOutdated(clusterObj *unstructured.Unstructured) bool {
+	placement, ok := r.annotatedPlacement()
+	if !ok {
+		return false
+	}
+	value, found := clusterObj.GetAnnotations()[r.placementAnnotation]
+	return !found || value != utils.PlacementAnnotationValue(placement)
+}
+
+// annotatedPlacement returns the clusters to list in the placement
+// annotation, and false if the annotation is not to be added.
+func (r *federatedResource) annotatedPlacement() (sets.Set[string], bool) {
+	if len(r.placementAnnotation) == 0 {
+		return nil, false
+	}
+	r.RLock()
+	defer r.RUnlock()
+	return r.placement, r.placement != nil
+}
+
+// Transform returns the given object as transformed for the named
+// cluster by the transformation webhook of the type, if any. Fields
+// unknown to the schema of the target type are first pruned if the
+// type enables pruning. The managed metadata is added afterwards in
+// case the webhook removed it.
+func (r *federatedResource) Transform(ctx context.Context, obj *unstructured.Unstructured, clusterName string) (*unstructured.Unstructured, error) {
+	if r.pruneSchema != nil {
+		if pruned := utils.PruneUnknownFields(obj.Object, r.pruneSchema); len(pruned) > 0 {
+			klog.V(2).Infof("Pruned fields unknown to the schema of %s from %q for cluster %q: %s",
+				r.TargetKind(), r.TargetName(), clusterName, strings.Join(pruned, ", "))
+		}
+	}
+	if r.transformer == nil {
+		return obj, nil
+	}
+	transformedObj, err := r.transformer.Transform(ctx, clusterName, obj)
+	if err != nil {
+		return nil, err
+	}
+	r.AddManagedMetadata(transformedObj)
+	return transformedObj, nil
+}
+
 // TODO(marun) Use an enumeration for errorCode.
 func (r *federatedResource) RecordError(errorCode string, err error) {
 	r.eventRecorder.Eventf(r.Object(), corev1.EventTypeWarning, errorCode, err.Error())
@@ -240,7 +594,69 @@ func (r *federatedResource) RecordEvent(reason, messageFmt string, args ...inter
 	r.eventRecorder.Eventf(r.Object(), corev1.EventTypeNormal, reason, messageFmt, args...)
 }
 
+// OverrideClusterNames returns the names of the clusters that
+// overrides are defined for.
+func (r *federatedResource) OverrideClusterNames() (sets.Set[string], error) {
+	overridesMap, err := r.overrides()
+	if err != nil {
+		return nil, err
+	}
+	clusterNames := sets.Set[string]{}
+	for clusterName := range overridesMap {
+		clusterNames.Insert(clusterName)
+	}
+	return clusterNames, nil
+}
+
+// SecretOverridePaths returns the paths of the overrides for the named
+// cluster whose values are sourced from Secrets.
+func (r *federatedResource) SecretOverridePaths(clusterName string) []string {
+	overridesMap, err := r.overrides()
+	if err != nil {
+		return nil
+	}
+	return overridesMap[clusterName].SecretValuePaths()
+}
+
+// overridesForCluster returns the overrides for the named cluster with
+// their values resolved, evaluated and validated against the schema of
+// the target type. Invalid overrides for a cluster only prevent
+// propagation to that cluster.
 func (r *federatedResource) overridesForCluster(clusterName string) (utils.ClusterOverrides, error) {
+	overridesMap, err := r.overrides()
+	if err != nil {
+		return nil, err
+	}
+	overrides := overridesMap[clusterName]
+	if overrides.HasValueSources() {
+		if r.overrideSources == nil {
+			return nil, errors.Errorf("Value sources of overrides are not supported for %s", r.federatedKind)
+		}
+		overrides, err = utils.ResolveOverrideValues(overrides, r.federatedName.Namespace, r.overrideSources.Lookup)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Error resolving the values of overrides for cluster %q", clusterName)
+		}
+	}
+	if overrides.Templated() {
+		r.RLock()
+		data, ok := r.templateData[clusterName]
+		r.RUnlock()
+		if !ok {
+			return nil, errors.Errorf("Templated overrides cannot be evaluated for unknown cluster %q", clusterName)
+		}
+		overrides, err = utils.ExpandOverrideTemplates(overrides, data)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Error evaluating templated overrides for cluster %q", clusterName)
+		}
+	}
+	overrides, err = utils.ValidateOverrides(overrides, r.targetSchema)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Invalid overrides for cluster %q", clusterName)
+	}
+	return overrides, nil
+}
+
+func (r *federatedResource) overrides() (utils.OverridesMap, error) {
 	r.Lock()
 	defer r.Unlock()
 	if r.overridesMap == nil {
@@ -248,9 +664,19 @@ func (r *federatedResource) overridesForCluster(clusterName string) (utils.Clust
 		if err != nil {
 			return nil, errors.Wrapf(err, "Error reading cluster overrides")
 		}
+		for clusterName, clusterOverrides := range overridesMap {
+			// An override of an immutable field is applied when the
+			// resource is created, but changing its value later fails
+			// every update of the resource.
+			if paths := utils.ImmutableOverridePaths(clusterOverrides, r.TargetGVK().GroupKind(), r.targetSchema); len(paths) > 0 {
+				r.eventRecorder.Eventf(r.Object(), corev1.EventTypeWarning, "ImmutableFieldOverride",
+					"Overrides for cluster %q target immutable fields %s whose values cannot be changed once the resource is created",
+					clusterName, strings.Join(paths, ", "))
+			}
+		}
 		r.overridesMap = overridesMap
 	}
-	return r.overridesMap[clusterName], nil
+	return r.overridesMap, nil
 }
 
 func GetTemplateHash(fieldMap map[string]interface{}) (string, error) {
@@ -267,6 +693,12 @@ func GetTemplateHash(fieldMap map[string]interface{}) (string, error) {
 	return hashUnstructured(obj, description)
 }
 
+// GetOverrideHash returns the hash of the overrides of the given
+// federated resource. Cluster-local overrides are excluded so that
+// adding, changing or removing one does not change the override
+// version of the resource. A cluster whose overrides are all
+// cluster-local is hashed as if it had no overrides, and an empty
+// list of overrides as if none were defined.
 func GetOverrideHash(rawObj *unstructured.Unstructured) (string, error) {
 	override := utils.GenericOverride{}
 	err := utils.UnstructuredToInterface(rawObj, &override)
@@ -276,16 +708,39 @@ func GetOverrideHash(rawObj *unstructured.Unstructured) (string, error) {
 	if override.Spec == nil {
 		return "", nil
 	}
+	var overrides []utils.GenericOverrideItem
+	for _, item := range override.Spec.Overrides {
+		clusterOverrides := utils.ClusterOverrides(item.ClusterOverrides).WithoutClusterLocal()
+		if len(clusterOverrides) == 0 && len(item.ClusterOverrides) != 0 {
+			continue
+		}
+		item.ClusterOverrides = clusterOverrides
+		overrides = append(overrides, item)
+	}
 	// Only hash the overrides
 	obj := &unstructured.Unstructured{
 		Object: map[string]interface{}{
-			"overrides": override.Spec.Overrides,
+			"overrides": overrides,
 		},
 	}
 
 	return hashUnstructured(obj, "overrides")
 }
 
+// GetClusterOverrideHash returns the hash of the given overrides of a
+// single cluster. Since an empty set of overrides also has a hash,
+// a cluster gaining its first override is detected as a change.
+// Unlike GetOverrideHash, cluster-local overrides are included so
+// that a change to them is still propagated to their cluster.
+func GetClusterOverrideHash(overrides utils.ClusterOverrides) (string, error) {
+	obj := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"overrides": overrides,
+		},
+	}
+	return hashUnstructured(obj, "overrides")
+}
+
 // TODO(marun) Investigate alternate ways of computing the hash of a field map.
 func hashUnstructured(obj *unstructured.Unstructured, description string) (string, error) {
 	jsonBytes, err := obj.MarshalJSON()
@@ -17,11 +17,22 @@ limitations under the License.
 package sync
 
 import (
+	"context"
+	"reflect"
 	"strings"
 	"testing"
 
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
 
+	fedv1b1 "sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
+	"sigs.k8s.io/kubefed/pkg/client/generic"
+	"sigs.k8s.io/kubefed/pkg/controller/sync/dispatch"
+	"sigs.k8s.io/kubefed/pkg/controller/utils"
 	kfenable "sigs.k8s.io/kubefed/pkg/kubefedctl/enable"
 )
 
@@ -47,3 +58,647 @@ spec:
 		t.Fatalf("Expected %s, got %s", expectedHash, hash)
 	}
 }
+
+func TestGetOverrideHashExcludesClusterLocalOverrides(t *testing.T) {
+	newFedObject := func(overridesMap utils.OverridesMap) *unstructured.Unstructured {
+		fedObject := &unstructured.Unstructured{Object: map[string]interface{}{}}
+		if err := utils.SetOverrides(fedObject, overridesMap); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return fedObject
+	}
+	replicas := utils.ClusterOverride{Path: "/spec/replicas", Value: 2}
+	debug := utils.ClusterOverride{
+		Op:           "add",
+		Path:         "/metadata/annotations",
+		Value:        map[string]interface{}{"debug": "true"},
+		ClusterLocal: true,
+	}
+	expectedHash, err := GetOverrideHash(newFedObject(utils.OverridesMap{
+		"cluster1": utils.ClusterOverrides{replicas},
+	}))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	testCases := map[string]struct {
+		overridesMap  utils.OverridesMap
+		expectChanged bool
+	}{
+		"cluster-local override added to a cluster with overrides": {
+			overridesMap: utils.OverridesMap{
+				"cluster1": utils.ClusterOverrides{replicas, debug},
+			},
+		},
+		"cluster-local override added to a cluster without overrides": {
+			overridesMap: utils.OverridesMap{
+				"cluster1": utils.ClusterOverrides{replicas},
+				"cluster2": utils.ClusterOverrides{debug},
+			},
+		},
+		"override added to a cluster without overrides": {
+			overridesMap: utils.OverridesMap{
+				"cluster1": utils.ClusterOverrides{replicas},
+				"cluster2": utils.ClusterOverrides{replicas},
+			},
+			expectChanged: true,
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			hash, err := GetOverrideHash(newFedObject(tc.overridesMap))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if changed := hash != expectedHash; changed != tc.expectChanged {
+				t.Fatalf("Expected the hash to have changed: %v, got %q for %q", tc.expectChanged, hash, expectedHash)
+			}
+		})
+	}
+
+	// The overrides of a cluster are still hashed in full so that a
+	// change to its cluster-local overrides is propagated.
+	hash, err := GetClusterOverrideHash(utils.ClusterOverrides{replicas})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	localHash, err := GetClusterOverrideHash(utils.ClusterOverrides{replicas, debug})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if hash == localHash {
+		t.Fatalf("Expected the cluster override hash to include cluster-local overrides")
+	}
+}
+
+func TestTransformPrunesUnknownFields(t *testing.T) {
+	schema := &apiextv1.JSONSchemaProps{
+		Type: "object",
+		Properties: map[string]apiextv1.JSONSchemaProps{
+			"spec": {
+				Type: "object",
+				Properties: map[string]apiextv1.JSONSchemaProps{
+					"size": {Type: "integer"},
+				},
+			},
+		},
+	}
+	testCases := map[string]struct {
+		pruneSchema      *apiextv1.JSONSchemaProps
+		expectedObsolete bool
+	}{
+		"obsolete field is retained when pruning is disabled": {
+			expectedObsolete: true,
+		},
+		"obsolete field is pruned when pruning is enabled": {
+			pruneSchema: schema,
+		},
+	}
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			obj := &unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"apiVersion": "example.io/v2",
+					"kind":       "Example",
+					"spec": map[string]interface{}{
+						"size":     int64(1),
+						"obsolete": "value",
+					},
+				},
+			}
+			fedResource := &federatedResource{
+				typeConfig: &fedv1b1.FederatedTypeConfig{
+					Spec: fedv1b1.FederatedTypeConfigSpec{
+						TargetType: fedv1b1.APIResource{Kind: "Example"},
+					},
+				},
+				pruneSchema: testCase.pruneSchema,
+			}
+
+			obj, err := fedResource.Transform(context.Background(), obj, "cluster1")
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			_, found, _ := unstructured.NestedString(obj.Object, "spec", "obsolete")
+			if found != testCase.expectedObsolete {
+				t.Fatalf("Expected the obsolete field to be present: %v, got %v", testCase.expectedObsolete, found)
+			}
+			if size, _, _ := unstructured.NestedInt64(obj.Object, "spec", "size"); size != 1 {
+				t.Fatalf("Expected the known field to be retained")
+			}
+		})
+	}
+}
+
+func TestApplyOverridesAddsManagedMetadata(t *testing.T) {
+	fedObject := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"name":      "foo",
+				"namespace": "bar",
+			},
+			"spec": map[string]interface{}{
+				"template": map[string]interface{}{
+					"metadata": map[string]interface{}{
+						"labels": map[string]interface{}{
+							"app":  "foo",
+							"team": "template",
+						},
+					},
+				},
+			},
+		},
+	}
+	fedResource := &federatedResource{
+		typeConfig: &fedv1b1.FederatedTypeConfig{
+			Spec: fedv1b1.FederatedTypeConfigSpec{
+				TargetType: fedv1b1.APIResource{
+					Version: "v1",
+					Kind:    "ConfigMap",
+				},
+			},
+		},
+		targetName:        utils.QualifiedName{Namespace: "bar", Name: "foo"},
+		federatedName:     utils.QualifiedName{Namespace: "bar", Name: "foo"},
+		federatedResource: fedObject,
+		overridesMap:      utils.OverridesMap{},
+		managedLabels: map[string]string{
+			"team":                         "platform",
+			utils.ManagedByKubeFedLabelKey: "false",
+		},
+		managedAnnotations: map[string]string{
+			"example.io/owner": "kubefed",
+		},
+	}
+
+	obj, err := fedResource.ObjectForCluster("cluster1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := fedResource.ApplyOverrides(obj, "cluster1"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expectedLabels := map[string]string{
+		"app":                          "foo",
+		"team":                         "platform",
+		utils.ManagedByKubeFedLabelKey: utils.ManagedByKubeFedLabelValue,
+	}
+	if labels := obj.GetLabels(); !reflect.DeepEqual(labels, expectedLabels) {
+		t.Fatalf("Expected labels %v, got %v", expectedLabels, labels)
+	}
+	if value := obj.GetAnnotations()["example.io/owner"]; value != "kubefed" {
+		t.Fatalf("Expected the managed annotation to be added, got %q", value)
+	}
+}
+
+func TestDispatchedResourcesHaveManagedMetadata(t *testing.T) {
+	fedObject := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"data": map[string]interface{}{"key": "value"},
+			},
+		},
+	}}
+	fedObject.SetNamespace("bar")
+	fedObject.SetName("foo")
+	fedResource := &federatedResource{
+		typeConfig: &fedv1b1.FederatedTypeConfig{
+			Spec: fedv1b1.FederatedTypeConfigSpec{
+				TargetType: fedv1b1.APIResource{
+					Version: "v1",
+					Kind:    "ConfigMap",
+				},
+			},
+		},
+		targetName:         utils.QualifiedName{Namespace: "bar", Name: "foo"},
+		federatedName:      utils.QualifiedName{Namespace: "bar", Name: "foo"},
+		federatedResource:  fedObject,
+		overridesMap:       utils.OverridesMap{},
+		versionMap:         map[string]string{},
+		eventRecorder:      record.NewFakeRecorder(10),
+		managedLabels:      map[string]string{"example.io/team": "platform"},
+		managedAnnotations: map[string]string{"example.io/owner": "kubefed"},
+	}
+	client := newMemoryClient()
+	clientAccessor := func(string) (generic.Client, error) {
+		return client, nil
+	}
+	expectManagedMetadata := func(operation string) {
+		t.Helper()
+		clusterObj, ok := client.objs["bar/foo"]
+		if !ok {
+			t.Fatalf("Expected the resource to be %s", operation)
+		}
+		if labels := clusterObj.GetLabels(); labels["example.io/team"] != "platform" || !utils.HasManagedLabel(clusterObj) {
+			t.Fatalf("Expected the %s resource to have the managed labels, got %v", operation, labels)
+		}
+		if value := clusterObj.GetAnnotations()["example.io/owner"]; value != "kubefed" {
+			t.Fatalf("Expected the %s resource to have the managed annotation, got %q", operation, value)
+		}
+	}
+
+	d := dispatch.NewManagedDispatcher(context.Background(), clientAccessor, fedResource, false, nil, false)
+	d.Create("cluster1")
+	if _, err := d.Wait(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expectManagedMetadata("created")
+
+	// The managed metadata removed from the resource in the member
+	// cluster is restored by the next update.
+	clusterObj := client.objs["bar/foo"].DeepCopy()
+	clusterObj.SetLabels(map[string]string{utils.ManagedByKubeFedLabelKey: utils.ManagedByKubeFedLabelValue})
+	clusterObj.SetAnnotations(nil)
+	client.objs["bar/foo"] = clusterObj.DeepCopy()
+	d = dispatch.NewManagedDispatcher(context.Background(), clientAccessor, fedResource, false, nil, false)
+	d.Update("cluster1", clusterObj)
+	if _, err := d.Wait(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expectManagedMetadata("updated")
+}
+
+func TestPlacementAnnotation(t *testing.T) {
+	const key = "example.io/placement"
+	testCases := map[string]struct {
+		placementAnnotation string
+		placement           sets.Set[string]
+		clusterAnnotations  map[string]string
+		expectedAnnotation  *string
+		expectedOutdated    bool
+	}{
+		"annotation is not added if not configured": {
+			placement: sets.New("cluster1"),
+		},
+		"annotation is not added without a recorded placement": {
+			placementAnnotation: key,
+		},
+		"annotation lists the sorted clusters of the placement": {
+			placementAnnotation: key,
+			placement:           sets.New("cluster2", "cluster1"),
+			clusterAnnotations:  map[string]string{key: "cluster1,cluster2"},
+			expectedAnnotation:  ptr.To("cluster1,cluster2"),
+		},
+		"annotation of an empty placement is empty": {
+			placementAnnotation: key,
+			placement:           sets.New[string](),
+			clusterAnnotations:  map[string]string{key: ""},
+			expectedAnnotation:  ptr.To(""),
+		},
+		"annotation listing other clusters is outdated": {
+			placementAnnotation: key,
+			placement:           sets.New("cluster1"),
+			clusterAnnotations:  map[string]string{key: "cluster1,cluster2"},
+			expectedAnnotation:  ptr.To("cluster1"),
+			expectedOutdated:    true,
+		},
+		"missing annotation is outdated": {
+			placementAnnotation: key,
+			placement:           sets.New("cluster1"),
+			expectedAnnotation:  ptr.To("cluster1"),
+			expectedOutdated:    true,
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			fedResource := &federatedResource{placementAnnotation: tc.placementAnnotation}
+			if tc.placement != nil {
+				fedResource.SetPlacement(tc.placement)
+			}
+
+			obj := &unstructured.Unstructured{Object: map[string]interface{}{}}
+			fedResource.AddManagedMetadata(obj)
+			value, ok := obj.GetAnnotations()[key]
+			switch {
+			case tc.expectedAnnotation == nil && ok:
+				t.Fatalf("Expected no placement annotation, got %q", value)
+			case tc.expectedAnnotation != nil && (!ok || value != *tc.expectedAnnotation):
+				t.Fatalf("Expected placement annotation %q, got %q", *tc.expectedAnnotation, value)
+			}
+
+			clusterObj := &unstructured.Unstructured{Object: map[string]interface{}{}}
+			clusterObj.SetAnnotations(tc.clusterAnnotations)
+			if outdated := fedResource.PlacementAnnotationOutdated(clusterObj); outdated != tc.expectedOutdated {
+				t.Fatalf("Expected outdated to be %v, got %v", tc.expectedOutdated, outdated)
+			}
+		})
+	}
+}
+
+func TestApplyOverridesCoercesValuesToTargetSchema(t *testing.T) {
+	schema := &apiextv1.JSONSchemaProps{
+		Type: "object",
+		Properties: map[string]apiextv1.JSONSchemaProps{
+			"spec": {
+				Type: "object",
+				Properties: map[string]apiextv1.JSONSchemaProps{
+					"replicas": {Type: "integer"},
+				},
+			},
+		},
+	}
+	testCases := map[string]struct {
+		targetSchema  *apiextv1.JSONSchemaProps
+		value         string
+		expectedValue interface{}
+		expectedErr   bool
+	}{
+		"string value is coerced to an integer with a schema": {
+			targetSchema:  schema,
+			value:         "3",
+			expectedValue: int64(3),
+		},
+		"string value is applied unchanged without a schema": {
+			value:         "3",
+			expectedValue: "3",
+		},
+		"incompatible value is rejected with a schema": {
+			targetSchema: schema,
+			value:        "three",
+			expectedErr:  true,
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			fedObject := &unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"metadata": map[string]interface{}{
+						"name":      "foo",
+						"namespace": "bar",
+					},
+					"spec": map[string]interface{}{
+						"template": map[string]interface{}{
+							"spec": map[string]interface{}{
+								"replicas": int64(1),
+							},
+						},
+					},
+				},
+			}
+			err := utils.SetOverrides(fedObject, utils.OverridesMap{
+				"cluster1": utils.ClusterOverrides{{Path: "/spec/replicas", Value: tc.value}},
+			})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			fedResource := &federatedResource{
+				typeConfig: &fedv1b1.FederatedTypeConfig{
+					Spec: fedv1b1.FederatedTypeConfigSpec{
+						TargetType: fedv1b1.APIResource{
+							Group:   "example.io",
+							Version: "v1",
+							Kind:    "Example",
+						},
+					},
+				},
+				targetName:        utils.QualifiedName{Namespace: "bar", Name: "foo"},
+				federatedName:     utils.QualifiedName{Namespace: "bar", Name: "foo"},
+				federatedResource: fedObject,
+				targetSchema:      tc.targetSchema,
+			}
+
+			obj, err := fedResource.ObjectForCluster("cluster1")
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			err = fedResource.ApplyOverrides(obj, "cluster1")
+			if tc.expectedErr {
+				if err == nil {
+					t.Fatalf("Expected an error for the override value %q", tc.value)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			value, _, _ := unstructured.NestedFieldNoCopy(obj.Object, "spec", "replicas")
+			if !reflect.DeepEqual(value, tc.expectedValue) {
+				t.Fatalf("Expected replicas to be %#v, got %#v", tc.expectedValue, value)
+			}
+		})
+	}
+}
+
+func TestInvalidOverridesOnlyFailTheirCluster(t *testing.T) {
+	fedObject := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"name":      "foo",
+				"namespace": "bar",
+			},
+			"spec": map[string]interface{}{
+				"template": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"replicas": int64(1),
+					},
+				},
+			},
+		},
+	}
+	err := utils.SetOverrides(fedObject, utils.OverridesMap{
+		"cluster1": utils.ClusterOverrides{{Path: "/spec/replicas", Value: "3"}},
+		"cluster2": utils.ClusterOverrides{{Path: "/spec/replicas", Value: "three"}},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	fedResource := &federatedResource{
+		typeConfig: &fedv1b1.FederatedTypeConfig{
+			Spec: fedv1b1.FederatedTypeConfigSpec{
+				TargetType: fedv1b1.APIResource{
+					Group:   "example.io",
+					Version: "v1",
+					Kind:    "Example",
+				},
+			},
+		},
+		targetName:        utils.QualifiedName{Namespace: "bar", Name: "foo"},
+		federatedName:     utils.QualifiedName{Namespace: "bar", Name: "foo"},
+		federatedResource: fedObject,
+		targetSchema: &apiextv1.JSONSchemaProps{
+			Type: "object",
+			Properties: map[string]apiextv1.JSONSchemaProps{
+				"spec": {
+					Type: "object",
+					Properties: map[string]apiextv1.JSONSchemaProps{
+						"replicas": {Type: "integer"},
+					},
+				},
+			},
+		},
+	}
+
+	clusterNames, err := fedResource.OverrideClusterNames()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := sets.New("cluster1", "cluster2"); !clusterNames.Equal(expected) {
+		t.Fatalf("Expected overrides for clusters %v, got %v", sets.List(expected), sets.List(clusterNames))
+	}
+
+	obj, err := fedResource.ObjectForCluster("cluster1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := fedResource.ApplyOverrides(obj, "cluster1"); err != nil {
+		t.Fatalf("Expected the overrides for %q to apply, got: %v", "cluster1", err)
+	}
+	if value, _, _ := unstructured.NestedFieldNoCopy(obj.Object, "spec", "replicas"); value != int64(3) {
+		t.Fatalf("Expected replicas to be %#v, got %#v", int64(3), value)
+	}
+	if _, err := fedResource.ClusterOverrideVersion("cluster1"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	obj, err = fedResource.ObjectForCluster("cluster2")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := fedResource.ApplyOverrides(obj, "cluster2"); err == nil {
+		t.Fatalf("Expected an error for the invalid override for %q", "cluster2")
+	}
+	// The unvalidated overrides are versioned instead, as are
+	// overrides that cannot be evaluated.
+	if _, err := fedResource.ClusterOverrideVersion("cluster2"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+func TestHasUnversionedOverrides(t *testing.T) {
+	replicas := utils.ClusterOverride{Path: "/spec/replicas", Value: 2}
+	testCases := map[string]struct {
+		override    utils.ClusterOverride
+		unversioned bool
+	}{
+		"override": {
+			override: replicas,
+		},
+		"cluster-local override": {
+			override:    utils.ClusterOverride{Path: "/metadata/annotations", Value: map[string]interface{}{"debug": "true"}, ClusterLocal: true},
+			unversioned: true,
+		},
+		"templated override": {
+			override:    utils.ClusterOverride{Path: "/spec/region", Value: "{{ .Labels.region }}", Template: true},
+			unversioned: true,
+		},
+		"override with a value source": {
+			override: utils.ClusterOverride{Path: "/data/key", ValueFrom: &utils.OverrideValueSource{
+				ConfigMapKeyRef: &utils.OverrideKeySelector{Name: "values", Key: "key"},
+			}},
+			unversioned: true,
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			fedObject := &unstructured.Unstructured{Object: map[string]interface{}{}}
+			overridesMap := utils.OverridesMap{
+				"cluster1": utils.ClusterOverrides{replicas},
+				"cluster2": utils.ClusterOverrides{tc.override},
+			}
+			if err := utils.SetOverrides(fedObject, overridesMap); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			fedResource := &federatedResource{
+				typeConfig:        &fedv1b1.FederatedTypeConfig{},
+				federatedResource: fedObject,
+				eventRecorder:     record.NewFakeRecorder(10),
+			}
+			unversioned, err := fedResource.HasUnversionedOverrides()
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if unversioned != tc.unversioned {
+				t.Fatalf("Expected unversioned overrides to be %v, got %v", tc.unversioned, unversioned)
+			}
+		})
+	}
+}
+
+func TestOverridesOfImmutableFieldsAreApplied(t *testing.T) {
+	fedObject := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	fedObject.SetNamespace("bar")
+	fedObject.SetName("foo")
+	overridesMap := utils.OverridesMap{
+		"cluster1": utils.ClusterOverrides{{Path: "/spec/storageClassName", Value: "fast"}},
+	}
+	if err := utils.SetOverrides(fedObject, overridesMap); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	recorder := record.NewFakeRecorder(10)
+	fedResource := &federatedResource{
+		typeConfig: &fedv1b1.FederatedTypeConfig{
+			Spec: fedv1b1.FederatedTypeConfigSpec{
+				TargetType: fedv1b1.APIResource{
+					Version: "v1",
+					Kind:    "PersistentVolumeClaim",
+				},
+			},
+		},
+		federatedResource: fedObject,
+		eventRecorder:     recorder,
+	}
+
+	overrides, err := fedResource.overridesForCluster("cluster1")
+	if err != nil {
+		t.Fatalf("Expected an override of an immutable field to be applied, got %v", err)
+	}
+	if !reflect.DeepEqual(overrides, overridesMap["cluster1"]) {
+		t.Fatalf("Expected overrides %v, got %v", overridesMap["cluster1"], overrides)
+	}
+	select {
+	case event := <-recorder.Events:
+		if !strings.Contains(event, "Warning ImmutableFieldOverride") || !strings.Contains(event, "/spec/storageClassName") {
+			t.Fatalf("Expected a warning naming the immutable field, got %q", event)
+		}
+	default:
+		t.Fatalf("Expected a warning for the override of an immutable field")
+	}
+}
+
+func TestAddManagedMetadataReportsManagedLabelsInSelectors(t *testing.T) {
+	fedObject := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	fedObject.SetNamespace("bar")
+	fedObject.SetName("foo")
+	recorder := record.NewFakeRecorder(10)
+	fedResource := &federatedResource{
+		federatedResource: fedObject,
+		eventRecorder:     recorder,
+		managedLabels:     map[string]string{"example.io/team": "platform"},
+		selectorFields:    []string{"spec.selector"},
+	}
+	selector := map[string]interface{}{
+		"app":             "web",
+		"example.io/team": "platform",
+	}
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"selector": runtime.DeepCopyJSONValue(selector),
+		},
+	}}
+
+	fedResource.AddManagedMetadata(obj)
+
+	if labels := obj.GetLabels(); labels["example.io/team"] != "platform" || !utils.HasManagedLabel(obj) {
+		t.Fatalf("Expected the managed labels to be added to the metadata, got %v", labels)
+	}
+	if actual, _, _ := unstructured.NestedMap(obj.Object, "spec", "selector"); !reflect.DeepEqual(actual, selector) {
+		t.Fatalf("Expected the selector to be left as %v, got %v", selector, actual)
+	}
+	select {
+	case event := <-recorder.Events:
+		if !strings.Contains(event, "Warning ManagedLabelInSelector") || !strings.Contains(event, "spec.selector[example.io/team]") {
+			t.Fatalf("Expected a warning naming the selector label, got %q", event)
+		}
+	default:
+		t.Fatalf("Expected a warning for the managed label in the selector")
+	}
+
+	unstructured.RemoveNestedField(obj.Object, "spec", "selector", "example.io/team")
+	fedResource.AddManagedMetadata(obj)
+	select {
+	case event := <-recorder.Events:
+		t.Fatalf("Expected no warning for a selector without managed labels, got %q", event)
+	default:
+	}
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"math"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
+)
+
+// AggregateMetrics computes the given aggregations from the raw
+// resource status collected from member clusters, keyed by cluster
+// name. Only the status of the given clusters is considered. A field
+// absent from the status reported by a cluster is considered to be
+// zero, since numeric status fields are commonly omitted when zero.
+// An aggregate is omitted if none of the clusters reported a status
+// or if a reported value is not an integer. The sorted names of the
+// given clusters that did not report a status, and whose status is
+// therefore not included in the aggregates, are also returned.
+func AggregateMetrics(aggregations []v1beta1.StatusAggregation, resourceStatusMap map[string]interface{}, clusterNames sets.Set[string]) (map[string]int64, []string) {
+	if len(aggregations) == 0 {
+		return nil, nil
+	}
+	var unreportedClusters []string
+	for _, clusterName := range sets.List(clusterNames) {
+		if _, ok := resourceStatusMap[clusterName].(map[string]interface{}); !ok {
+			unreportedClusters = append(unreportedClusters, clusterName)
+		}
+	}
+	var metrics map[string]int64
+	for _, aggregation := range aggregations {
+		value, ok := aggregateField(aggregation, resourceStatusMap, clusterNames)
+		if !ok {
+			continue
+		}
+		if metrics == nil {
+			metrics = make(map[string]int64)
+		}
+		metrics[aggregation.Name] = value
+	}
+	return metrics, unreportedClusters
+}
+
+func aggregateField(aggregation v1beta1.StatusAggregation, resourceStatusMap map[string]interface{}, clusterNames sets.Set[string]) (int64, bool) {
+	path := strings.Split(aggregation.Field, ".")
+	var result int64
+	reported := false
+	for clusterName, resourceStatus := range resourceStatusMap {
+		if !clusterNames.Has(clusterName) {
+			continue
+		}
+		statusObj, ok := resourceStatus.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		value, err := clusterFieldValue(statusObj, path)
+		if err != nil {
+			klog.Warningf("Unable to aggregate %q for cluster %q: %v", aggregation.Field, clusterName, err)
+			return 0, false
+		}
+		if !reported {
+			result = value
+			reported = true
+			continue
+		}
+		switch aggregation.Function {
+		case v1beta1.AggregationSum:
+			result += value
+		case v1beta1.AggregationMin:
+			if value < result {
+				result = value
+			}
+		case v1beta1.AggregationMax:
+			if value > result {
+				result = value
+			}
+		}
+	}
+	return result, reported
+}
+
+func clusterFieldValue(statusObj map[string]interface{}, path []string) (int64, error) {
+	value, found, err := unstructured.NestedFieldNoCopy(statusObj, path...)
+	if err != nil || !found {
+		return 0, err
+	}
+	switch typedValue := value.(type) {
+	case int64:
+		return typedValue, nil
+	case int32:
+		return int64(typedValue), nil
+	case int:
+		return int64(typedValue), nil
+	case float64:
+		// Numbers are decoded as float64 once the status has been
+		// normalized.
+		if typedValue == math.Trunc(typedValue) {
+			return int64(typedValue), nil
+		}
+	}
+	return 0, errors.Errorf("%v is not an integer", value)
+}
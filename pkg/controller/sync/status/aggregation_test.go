@@ -0,0 +1,87 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
+)
+
+func TestAggregateMetrics(t *testing.T) {
+	aggregations := []v1beta1.StatusAggregation{
+		{Name: "totalReadyReplicas", Field: "readyReplicas", Function: v1beta1.AggregationSum},
+		{Name: "minReadyReplicas", Field: "readyReplicas", Function: v1beta1.AggregationMin},
+		{Name: "maxReplicas", Field: "replicas", Function: v1beta1.AggregationMax},
+	}
+	clusterNames := sets.New("cluster1", "cluster2")
+
+	testCases := map[string]struct {
+		resourceStatusMap  map[string]interface{}
+		expected           map[string]int64
+		expectedUnreported []string
+	}{
+		"no status reported": {
+			resourceStatusMap:  map[string]interface{}{},
+			expected:           nil,
+			expectedUnreported: []string{"cluster1", "cluster2"},
+		},
+		"status reported by two clusters": {
+			resourceStatusMap: map[string]interface{}{
+				"cluster1": map[string]interface{}{"readyReplicas": int64(2), "replicas": int64(3)},
+				"cluster2": map[string]interface{}{"readyReplicas": float64(3), "replicas": float64(3)},
+			},
+			expected: map[string]int64{"totalReadyReplicas": 5, "minReadyReplicas": 2, "maxReplicas": 3},
+		},
+		"field omitted when zero": {
+			resourceStatusMap: map[string]interface{}{
+				"cluster1": map[string]interface{}{"readyReplicas": int64(2), "replicas": int64(2)},
+				"cluster2": map[string]interface{}{"replicas": int64(4)},
+			},
+			expected: map[string]int64{"totalReadyReplicas": 2, "minReadyReplicas": 0, "maxReplicas": 4},
+		},
+		"status of unselected cluster": {
+			resourceStatusMap: map[string]interface{}{
+				"cluster1": map[string]interface{}{"readyReplicas": int64(2), "replicas": int64(2)},
+				"cluster3": map[string]interface{}{"readyReplicas": int64(5), "replicas": int64(5)},
+			},
+			expected:           map[string]int64{"totalReadyReplicas": 2, "minReadyReplicas": 2, "maxReplicas": 2},
+			expectedUnreported: []string{"cluster2"},
+		},
+		"non-integer value": {
+			resourceStatusMap: map[string]interface{}{
+				"cluster1": map[string]interface{}{"readyReplicas": "2", "replicas": int64(2)},
+			},
+			expected:           map[string]int64{"maxReplicas": 2},
+			expectedUnreported: []string{"cluster2"},
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			metrics, unreported := AggregateMetrics(aggregations, tc.resourceStatusMap, clusterNames)
+			if !reflect.DeepEqual(tc.expected, metrics) {
+				t.Fatalf("Expected %v, got %v", tc.expected, metrics)
+			}
+			if !reflect.DeepEqual(tc.expectedUnreported, unreported) {
+				t.Fatalf("Expected unreported clusters %v, got %v", tc.expectedUnreported, unreported)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"sigs.k8s.io/kubefed/pkg/controller/utils"
+)
+
+// DecodeGenericFederatedResource converts a federated resource to the
+// generic resource struct, tolerating status written by a controller
+// of another version of KubeFed. Fields missing from the status, such
+// as those added since the status was written, are left at their
+// defaults. Conditions, clusters and other fields of the status that
+// cannot be decoded are ignored rather than failing the decoding of
+// the resource. The status of the returned resource is nil if the
+// federated resource has no status or its status is not an object.
+func DecodeGenericFederatedResource(fedObject *unstructured.Unstructured) (*GenericFederatedResource, error) {
+	content := make(map[string]interface{}, len(fedObject.Object))
+	for key, value := range fedObject.Object {
+		if key != utils.StatusField {
+			content[key] = value
+		}
+	}
+	resource := &GenericFederatedResource{}
+	if err := utils.UnstructuredToInterface(&unstructured.Unstructured{Object: content}, resource); err != nil {
+		return nil, errors.Wrapf(err, "Failed to unmarshall to generic resource")
+	}
+
+	statusObj, ok := fedObject.Object[utils.StatusField].(map[string]interface{})
+	if !ok {
+		return resource, nil
+	}
+	resource.Status = decodeGenericFederatedStatus(statusObj)
+	return resource, nil
+}
+
+func decodeGenericFederatedStatus(statusObj map[string]interface{}) *GenericFederatedStatus {
+	decodable := make(map[string]interface{}, len(statusObj))
+	for key, value := range statusObj {
+		decodable[key] = value
+	}
+	if conditions, ok := statusObj["conditions"].([]interface{}); ok {
+		decodable["conditions"] = decodableItems(conditions, func() interface{} { return &GenericCondition{} })
+	}
+	if clusters, ok := statusObj["clusters"].([]interface{}); ok {
+		decodable["clusters"] = decodableItems(clusters, func() interface{} { return &GenericClusterStatus{} })
+	}
+
+	status := &GenericFederatedStatus{}
+	if decode(decodable, status) {
+		return status
+	}
+
+	// Drop the fields that cannot be decoded one at a time, in a
+	// stable order, until the remainder can be.
+	keys := make([]string, 0, len(decodable))
+	for key := range decodable {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	remainder := make(map[string]interface{}, len(decodable))
+	for _, key := range keys {
+		remainder[key] = decodable[key]
+		if !decode(remainder, &GenericFederatedStatus{}) {
+			delete(remainder, key)
+		}
+	}
+	status = &GenericFederatedStatus{}
+	decode(remainder, status)
+	return status
+}
+
+// decodableItems returns the items of a list that are objects that can
+// be decoded into the value returned by newItem.
+func decodableItems(items []interface{}, newItem func() interface{}) []interface{} {
+	result := make([]interface{}, 0, len(items))
+	for _, item := range items {
+		if _, ok := item.(map[string]interface{}); ok && decode(item, newItem()) {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+func decode(value interface{}, target interface{}) bool {
+	content, err := json.Marshal(value)
+	if err != nil {
+		return false
+	}
+	return json.Unmarshal(content, target) == nil
+}
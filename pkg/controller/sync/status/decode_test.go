@@ -0,0 +1,141 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"reflect"
+	"testing"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestDecodeGenericFederatedResource(t *testing.T) {
+	propagationCondition := map[string]interface{}{
+		"type":               "Propagation",
+		"status":             "True",
+		"lastUpdateTime":     "2024-01-01T00:00:00Z",
+		"lastTransitionTime": "2024-01-01T00:00:00Z",
+	}
+	expectedCondition := &GenericCondition{
+		Type:               PropagationConditionType,
+		Status:             apiv1.ConditionTrue,
+		LastUpdateTime:     "2024-01-01T00:00:00Z",
+		LastTransitionTime: "2024-01-01T00:00:00Z",
+	}
+
+	testCases := map[string]struct {
+		status         interface{}
+		expectedStatus *GenericFederatedStatus
+	}{
+		"resource without status": {},
+		"status of the current layout": {
+			status: map[string]interface{}{
+				"observedGeneration": int64(2),
+				"conditions":         []interface{}{propagationCondition},
+				"clusters": []interface{}{
+					map[string]interface{}{
+						"name":        "cluster1",
+						"status":      "UpdateFailed",
+						"applyResult": "Failed",
+						"applyError":  "denied",
+					},
+				},
+				"targetName":        "foo-config",
+				"aggregatedMetrics": map[string]interface{}{"totalReplicas": int64(3)},
+			},
+			expectedStatus: &GenericFederatedStatus{
+				ObservedGeneration: 2,
+				Conditions:         []*GenericCondition{expectedCondition},
+				Clusters: []GenericClusterStatus{{
+					Name:        "cluster1",
+					Status:      UpdateFailed,
+					ApplyResult: ApplyFailed,
+					ApplyError:  "denied",
+				}},
+				TargetName:        "foo-config",
+				AggregatedMetrics: map[string]int64{"totalReplicas": 3},
+			},
+		},
+		"status of a previous layout has missing fields defaulted": {
+			status: map[string]interface{}{
+				"observedGeneration": int64(1),
+				"conditions":         []interface{}{propagationCondition},
+				"clusters": []interface{}{
+					map[string]interface{}{"name": "cluster1"},
+				},
+			},
+			expectedStatus: &GenericFederatedStatus{
+				ObservedGeneration: 1,
+				Conditions:         []*GenericCondition{expectedCondition},
+				Clusters:           []GenericClusterStatus{{Name: "cluster1"}},
+			},
+		},
+		"undecodable conditions and clusters are ignored": {
+			status: map[string]interface{}{
+				"conditions": []interface{}{nil, "Propagation", propagationCondition},
+				"clusters": []interface{}{
+					map[string]interface{}{"name": int64(1)},
+					map[string]interface{}{"name": "cluster2"},
+				},
+			},
+			expectedStatus: &GenericFederatedStatus{
+				Conditions: []*GenericCondition{expectedCondition},
+				Clusters:   []GenericClusterStatus{{Name: "cluster2"}},
+			},
+		},
+		"undecodable fields are ignored": {
+			status: map[string]interface{}{
+				"observedGeneration": "1",
+				"targetName":         "foo-config",
+				"aggregatedMetrics":  map[string]interface{}{"averageReplicas": 1.5},
+			},
+			expectedStatus: &GenericFederatedStatus{
+				TargetName: "foo-config",
+			},
+		},
+		"status that is not an object is ignored": {
+			status: "Propagated",
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			fedObject := &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "types.kubefed.io/v1beta1",
+				"kind":       "FederatedConfigMap",
+				"metadata": map[string]interface{}{
+					"name":      "foo",
+					"namespace": "ns",
+				},
+			}}
+			if tc.status != nil {
+				fedObject.Object["status"] = tc.status
+			}
+
+			resource, err := DecodeGenericFederatedResource(fedObject)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if resource.Name != "foo" || resource.Namespace != "ns" || resource.Kind != "FederatedConfigMap" {
+				t.Fatalf("Expected the metadata of the resource to be decoded, got %v", resource)
+			}
+			if !reflect.DeepEqual(tc.expectedStatus, resource.Status) {
+				t.Fatalf("Expected status %#v, got %#v", tc.expectedStatus, resource.Status)
+			}
+		})
+	}
+}
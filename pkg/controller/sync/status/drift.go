@@ -0,0 +1,112 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"sigs.k8s.io/kubefed/pkg/controller/utils"
+)
+
+// OutOfBandChange is the reason of a True DriftDetected condition.
+const OutOfBandChange AggregateReason = "OutOfBandChange"
+
+// SetClusterDriftConditions sets the DriftDetected condition of the
+// clusters in the status of the given federated resource from the
+// given changes, keyed by cluster name, that the managed resources in
+// the clusters require to have the desired content. The condition is
+// True with the changed paths as its message for a cluster with
+// changes, and a True condition is made False for a cluster without
+// changes. Clusters that are not in the given map or not in the status
+// are left unchanged. Returns a boolean indication of whether the
+// status should be written to the API.
+func SetClusterDriftConditions(fedObject *unstructured.Unstructured, clusterChanges map[string][]utils.FieldChange) (bool, error) {
+	resource, err := DecodeGenericFederatedResource(fedObject)
+	if err != nil {
+		return false, err
+	}
+	if resource.Status == nil {
+		return false, nil
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	updated := false
+	for i := range resource.Status.Clusters {
+		cluster := &resource.Status.Clusters[i]
+		changes, ok := clusterChanges[cluster.Name]
+		if !ok {
+			continue
+		}
+		if cluster.setDriftCondition(changes, now) {
+			updated = true
+		}
+	}
+	if !updated {
+		return false, nil
+	}
+	if err := setStatusField(fedObject, resource); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// setDriftCondition ensures that the DriftDetected condition of the
+// cluster reflects the given changes. Returns a boolean indication of
+// whether the condition was changed.
+func (s *GenericClusterStatus) setDriftCondition(changes []utils.FieldChange, now string) bool {
+	var driftCondition *GenericCondition
+	for _, condition := range s.Conditions {
+		if condition != nil && condition.Type == DriftDetectedConditionType {
+			driftCondition = condition
+			break
+		}
+	}
+	drifted := len(changes) > 0
+	if driftCondition == nil {
+		if !drifted {
+			// The condition is only added once drift is detected.
+			return false
+		}
+		driftCondition = &GenericCondition{Type: DriftDetectedConditionType}
+		s.Conditions = append(s.Conditions, driftCondition)
+	}
+
+	newStatus := apiv1.ConditionFalse
+	var reason AggregateReason
+	var message string
+	if drifted {
+		newStatus = apiv1.ConditionTrue
+		reason = OutOfBandChange
+		message = "Fields differ from the desired state: " + utils.FormatChangedPaths(changes)
+	}
+
+	transition := driftCondition.Status != newStatus
+	if transition {
+		driftCondition.LastTransitionTime = now
+		driftCondition.Status = newStatus
+	}
+	changed := transition || driftCondition.Reason != reason || driftCondition.Message != message
+	if changed {
+		driftCondition.Reason = reason
+		driftCondition.Message = message
+		driftCondition.LastUpdateTime = now
+	}
+	return changed
+}
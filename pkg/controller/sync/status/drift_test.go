@@ -0,0 +1,111 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"testing"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"sigs.k8s.io/kubefed/pkg/controller/utils"
+)
+
+func TestSetClusterDriftConditions(t *testing.T) {
+	fedObject := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "types.kubefed.io/v1beta1",
+			"kind":       "FederatedConfigMap",
+			"metadata": map[string]interface{}{
+				"name":       "foo",
+				"namespace":  "ns",
+				"generation": int64(1),
+			},
+		},
+	}
+	collectedStatus := CollectedPropagationStatus{
+		StatusMap: PropagationStatusMap{"cluster1": ClusterPropagationOK, "cluster2": ClusterPropagationOK},
+	}
+	if _, err := SetFederatedStatus(fedObject, AggregateSuccess, collectedStatus, CollectedResourceStatus{}, false); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	driftCondition := func(clusterName string) *GenericCondition {
+		resource, err := DecodeGenericFederatedResource(fedObject)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		for _, cluster := range resource.Status.Clusters {
+			if cluster.Name != clusterName {
+				continue
+			}
+			for _, condition := range cluster.Conditions {
+				if condition.Type == DriftDetectedConditionType {
+					return condition
+				}
+			}
+		}
+		return nil
+	}
+
+	changed, err := SetClusterDriftConditions(fedObject, map[string][]utils.FieldChange{"cluster1": nil, "cluster2": nil})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if changed || driftCondition("cluster1") != nil {
+		t.Fatalf("Expected no condition to be added for clusters without drift")
+	}
+
+	changes := []utils.FieldChange{{Path: "/data/key", Type: utils.ChangeModified, Old: "other", New: "value"}}
+	changed, err = SetClusterDriftConditions(fedObject, map[string][]utils.FieldChange{"cluster1": changes, "cluster2": nil})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !changed {
+		t.Fatalf("Expected the status to be changed")
+	}
+	condition := driftCondition("cluster1")
+	if condition == nil || condition.Status != apiv1.ConditionTrue || condition.Reason != OutOfBandChange {
+		t.Fatalf("Expected a True DriftDetected condition for cluster1, got %#v", condition)
+	}
+	if expected := "Fields differ from the desired state: ~/data/key"; condition.Message != expected {
+		t.Fatalf("Expected message %q, got %q", expected, condition.Message)
+	}
+	if driftCondition("cluster2") != nil {
+		t.Fatalf("Expected no condition for cluster2")
+	}
+
+	// Propagation status updates must retain the condition.
+	if _, err := SetFederatedStatus(fedObject, AggregateSuccess, collectedStatus, CollectedResourceStatus{}, false); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if condition := driftCondition("cluster1"); condition == nil || condition.Status != apiv1.ConditionTrue {
+		t.Fatalf("Expected the DriftDetected condition to be retained, got %#v", condition)
+	}
+
+	changed, err = SetClusterDriftConditions(fedObject, map[string][]utils.FieldChange{"cluster1": nil})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !changed {
+		t.Fatalf("Expected the status to be changed")
+	}
+	condition = driftCondition("cluster1")
+	if condition == nil || condition.Status != apiv1.ConditionFalse || condition.Reason != "" || condition.Message != "" {
+		t.Fatalf("Expected a False DriftDetected condition for cluster1, got %#v", condition)
+	}
+}
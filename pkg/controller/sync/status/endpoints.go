@@ -0,0 +1,49 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	discoveryv1 "k8s.io/api/discovery/v1"
+)
+
+// ReadyEndpoints returns the number of ready endpoints of the given
+// EndpointSlices of a service. An endpoint whose readiness is unknown
+// is considered to be ready, as recommended by the EndpointSlice API.
+func ReadyEndpoints(endpointSlices []discoveryv1.EndpointSlice) int64 {
+	var ready int64
+	for _, endpointSlice := range endpointSlices {
+		for _, endpoint := range endpointSlice.Endpoints {
+			if endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready {
+				ready++
+			}
+		}
+	}
+	return ready
+}
+
+// totalReadyEndpoints returns the sum of the given ready endpoints of
+// member clusters, or nil if none were collected.
+func totalReadyEndpoints(readyEndpoints map[string]int64) *int64 {
+	if len(readyEndpoints) == 0 {
+		return nil
+	}
+	var total int64
+	for _, ready := range readyEndpoints {
+		total += ready
+	}
+	return &total
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"testing"
+
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestReadyEndpoints(t *testing.T) {
+	endpointSlice := func(ready ...*bool) discoveryv1.EndpointSlice {
+		endpointSlice := discoveryv1.EndpointSlice{}
+		for _, r := range ready {
+			endpointSlice.Endpoints = append(endpointSlice.Endpoints, discoveryv1.Endpoint{
+				Addresses:  []string{"10.0.0.1"},
+				Conditions: discoveryv1.EndpointConditions{Ready: r},
+			})
+		}
+		return endpointSlice
+	}
+	testCases := map[string]struct {
+		endpointSlices []discoveryv1.EndpointSlice
+		expected       int64
+	}{
+		"no endpoint slices": {},
+		"ready endpoints are counted across slices": {
+			endpointSlices: []discoveryv1.EndpointSlice{
+				endpointSlice(ptr.To(true), ptr.To(true)),
+				endpointSlice(ptr.To(true)),
+			},
+			expected: 3,
+		},
+		"endpoints that are not ready are not counted": {
+			endpointSlices: []discoveryv1.EndpointSlice{
+				endpointSlice(ptr.To(true), ptr.To(false)),
+			},
+			expected: 1,
+		},
+		"endpoints of unknown readiness are counted": {
+			endpointSlices: []discoveryv1.EndpointSlice{
+				endpointSlice(nil),
+			},
+			expected: 1,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if actual := ReadyEndpoints(tc.endpointSlices); actual != tc.expected {
+				t.Fatalf("Expected %d ready endpoints, got %d", tc.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	fedv1b1 "sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
+	"sigs.k8s.io/kubefed/pkg/client/generic"
+)
+
+// queryPageSize bounds the number of federated resources retrieved by
+// a single request of a status query, so that the memory used by a
+// query does not grow with the number of federated resources beyond
+// that of the resources it returns.
+const queryPageSize = 500
+
+// ClusterStatusFilter returns whether the propagation status of a
+// federated resource in a cluster is selected by a status query.
+type ClusterStatusFilter func(clusterStatus *GenericClusterStatus) bool
+
+// Failing selects the clusters where propagation of a federated
+// resource failed, as opposed to having succeeded, being pending or
+// having been intentionally skipped.
+func Failing(clusterStatus *GenericClusterStatus) bool {
+	switch clusterStatus.Status {
+	case ClusterPropagationOK, WaitingForRemoval, PlacementOnly, Maintenance, Deferred, Paused, Draining, WaitingForCanary:
+		return false
+	}
+	return true
+}
+
+// WithStatus returns a filter selecting the clusters whose propagation
+// status is any of the given statuses.
+func WithStatus(statuses ...PropagationStatus) ClusterStatusFilter {
+	return func(clusterStatus *GenericClusterStatus) bool {
+		for _, status := range statuses {
+			if clusterStatus.Status == status {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// GetObjectsByClusterStatus returns the federated resources, of the
+// types configured by the FederatedTypeConfigs in the given kubefed
+// namespace, whose propagation status for the given cluster is
+// selected by the given filter. Resources without a status for the
+// cluster, e.g. because they are not placed in it, are not returned.
+// The federated resources of each type are listed in pages so that
+// only the resources that are selected are retained. Types whose
+// federated type is not served by the host cluster are skipped.
+func GetObjectsByClusterStatus(ctx context.Context, client generic.Client, kubefedNamespace, clusterName string, filter ClusterStatusFilter) ([]*unstructured.Unstructured, error) {
+	typeConfigList := &fedv1b1.FederatedTypeConfigList{}
+	if err := client.List(ctx, typeConfigList, kubefedNamespace); err != nil {
+		return nil, errors.Wrap(err, "Failed to list FederatedTypeConfigs")
+	}
+
+	var result []*unstructured.Unstructured
+	for i := range typeConfigList.Items {
+		fedAPIResource := typeConfigList.Items[i].GetFederatedType()
+		continueToken := ""
+		for {
+			fedList := &unstructured.UnstructuredList{}
+			fedList.SetAPIVersion(metav1.GroupVersion{Group: fedAPIResource.Group, Version: fedAPIResource.Version}.String())
+			fedList.SetKind(fedAPIResource.Kind + "List")
+			err := client.List(ctx, fedList, metav1.NamespaceAll, runtimeclient.Limit(queryPageSize), runtimeclient.Continue(continueToken))
+			if meta.IsNoMatchError(err) {
+				break
+			}
+			if err != nil {
+				return nil, errors.Wrapf(err, "Failed to list %s resources", fedAPIResource.Kind)
+			}
+			for j := range fedList.Items {
+				fedObject := &fedList.Items[j]
+				if clusterStatus := statusForCluster(fedObject, clusterName); clusterStatus != nil && filter(clusterStatus) {
+					result = append(result, fedObject)
+				}
+			}
+			continueToken = fedList.GetContinue()
+			if len(continueToken) == 0 {
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
+// statusForCluster returns the propagation status of the given
+// federated resource for the given cluster, or nil if the resource
+// has no status for the cluster that can be decoded. Only the status
+// of the cluster is decoded.
+func statusForCluster(fedObject *unstructured.Unstructured, clusterName string) *GenericClusterStatus {
+	clusters, _, _ := unstructured.NestedSlice(fedObject.Object, "status", "clusters")
+	for _, item := range clusters {
+		clusterObj, ok := item.(map[string]interface{})
+		if !ok || clusterObj["name"] != clusterName {
+			continue
+		}
+		clusterStatus := &GenericClusterStatus{}
+		if !decode(clusterObj, clusterStatus) {
+			return nil
+		}
+		return clusterStatus
+	}
+	return nil
+}
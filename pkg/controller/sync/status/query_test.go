@@ -0,0 +1,136 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status_test
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	fedv1b1 "sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
+	"sigs.k8s.io/kubefed/pkg/controller/sync/status"
+	"sigs.k8s.io/kubefed/test/common/fake"
+)
+
+func TestGetObjectsByClusterStatus(t *testing.T) {
+	ctx := context.Background()
+	c := fake.NewGenericClient(fake.NewStore())
+
+	typeConfig := &fedv1b1.FederatedTypeConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "kube-federation-system",
+			Name:      "configmaps",
+		},
+		Spec: fedv1b1.FederatedTypeConfigSpec{
+			TargetType: fedv1b1.APIResource{
+				Version:    "v1",
+				Kind:       "ConfigMap",
+				PluralName: "configmaps",
+				Scope:      "Namespaced",
+			},
+			FederatedType: fedv1b1.APIResource{
+				Group:      "types.kubefed.io",
+				Version:    "v1beta1",
+				Kind:       "FederatedConfigMap",
+				PluralName: "federatedconfigmaps",
+				Scope:      "Namespaced",
+			},
+			Propagation: fedv1b1.PropagationEnabled,
+		},
+	}
+	if err := c.Create(ctx, typeConfig); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// The status of each resource by cluster. A resource without an
+	// entry for cluster1 is not placed in it.
+	resources := map[string]map[string]status.PropagationStatus{
+		"ok":           {"cluster1": status.ClusterPropagationOK, "cluster2": status.UpdateFailed},
+		"failed":       {"cluster1": status.CreationFailed, "cluster2": status.ClusterPropagationOK},
+		"unreachable":  {"cluster1": status.ClusterNotReachable},
+		"deferred":     {"cluster1": status.Deferred},
+		"not-placed":   {"cluster2": status.CreationFailed},
+		"no-status":    nil,
+		"also-failing": {"cluster1": status.UpdateFailed},
+	}
+	for name, clusterStatuses := range resources {
+		fedObject := &unstructured.Unstructured{}
+		fedObject.SetAPIVersion("types.kubefed.io/v1beta1")
+		fedObject.SetKind("FederatedConfigMap")
+		fedObject.SetNamespace("ns")
+		fedObject.SetName(name)
+		if clusterStatuses != nil {
+			clusters := []interface{}{}
+			for clusterName, clusterStatus := range clusterStatuses {
+				clusters = append(clusters, map[string]interface{}{
+					"name":   clusterName,
+					"status": string(clusterStatus),
+				})
+			}
+			fedObject.Object["status"] = map[string]interface{}{"clusters": clusters}
+		}
+		if err := c.Create(ctx, fedObject); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	testCases := map[string]struct {
+		clusterName string
+		filter      status.ClusterStatusFilter
+		expected    []string
+	}{
+		"failing in cluster1": {
+			clusterName: "cluster1",
+			filter:      status.Failing,
+			expected:    []string{"also-failing", "failed", "unreachable"},
+		},
+		"failing in cluster2": {
+			clusterName: "cluster2",
+			filter:      status.Failing,
+			expected:    []string{"not-placed", "ok"},
+		},
+		"with status in cluster1": {
+			clusterName: "cluster1",
+			filter:      status.WithStatus(status.ClusterPropagationOK, status.Deferred),
+			expected:    []string{"deferred", "ok"},
+		},
+		"unknown cluster": {
+			clusterName: "cluster3",
+			filter:      status.Failing,
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			fedObjects, err := status.GetObjectsByClusterStatus(ctx, c, "kube-federation-system", tc.clusterName, tc.filter)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			var names []string
+			for _, fedObject := range fedObjects {
+				names = append(names, fedObject.GetName())
+			}
+			sort.Strings(names)
+			if !reflect.DeepEqual(names, tc.expected) {
+				t.Fatalf("Expected %v, got %v", tc.expected, names)
+			}
+		})
+	}
+}
@@ -18,7 +18,10 @@ package status
 
 import (
 	"encoding/json"
+	"fmt"
 	"reflect"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
@@ -37,45 +40,199 @@ type AggregateReason string
 
 type ConditionType string
 
+// ApplyResult is the outcome of applying a federated resource to a
+// member cluster during the most recent reconcile.
+type ApplyResult string
+
+const (
+	ApplyCreated   ApplyResult = "Created"
+	ApplyUpdated   ApplyResult = "Updated"
+	ApplyUnchanged ApplyResult = "Unchanged"
+	ApplyFailed    ApplyResult = "Failed"
+)
+
+// ClusterApplyResult is the outcome of applying to a member cluster
+// and, if applying failed, the error that was encountered.
+type ClusterApplyResult struct {
+	Result ApplyResult
+	Error  string
+}
+
 const (
 	ClusterPropagationOK PropagationStatus = ""
 	WaitingForRemoval    PropagationStatus = "WaitingForRemoval"
+	// PlacementOnly indicates that the cluster is placed but that
+	// the resource is not propagated to it.
+	PlacementOnly PropagationStatus = "PlacementOnly"
+	// Maintenance indicates that propagation to the cluster has been
+	// paused while the cluster is in maintenance.
+	Maintenance PropagationStatus = "Maintenance"
+	// Deferred indicates that an update of the resource in the
+	// cluster has been deferred until the propagation window of the
+	// type opens.
+	Deferred PropagationStatus = "Deferred"
+	// Paused indicates that neither applies nor deletions were
+	// performed in the cluster because propagation has been paused
+	// for the control plane.
+	Paused PropagationStatus = "Paused"
+	// Draining indicates that the resource in a cluster no longer
+	// selected by placement is being drained according to its
+	// removal strategy before it is deleted.
+	Draining PropagationStatus = "Draining"
+	// WaitingForCanary indicates that the resource was not created or
+	// updated in the cluster because it is not yet healthy in the
+	// canary clusters of its placement.
+	WaitingForCanary PropagationStatus = "WaitingForCanary"
 
 	// Cluster-specific errors
 	ClusterNotReady        PropagationStatus = "ClusterNotReady"
+	ClusterNotReachable    PropagationStatus = "ClusterNotReachable"
 	CachedRetrievalFailed  PropagationStatus = "CachedRetrievalFailed"
 	ComputeResourceFailed  PropagationStatus = "ComputeResourceFailed"
 	ApplyOverridesFailed   PropagationStatus = "ApplyOverridesFailed"
+	TransformationFailed   PropagationStatus = "TransformationFailed"
+	OwnerReferencesFailed  PropagationStatus = "OwnerReferencesFailed"
+	TargetTypeMismatch     PropagationStatus = "TargetTypeMismatch"
 	CreationFailed         PropagationStatus = "CreationFailed"
 	UpdateFailed           PropagationStatus = "UpdateFailed"
 	DeletionFailed         PropagationStatus = "DeletionFailed"
 	LabelRemovalFailed     PropagationStatus = "LabelRemovalFailed"
+	DrainFailed            PropagationStatus = "DrainFailed"
 	RetrievalFailed        PropagationStatus = "RetrievalFailed"
 	AlreadyExists          PropagationStatus = "AlreadyExists"
+	AdoptionRefused        PropagationStatus = "AdoptionRefused"
 	FieldRetentionFailed   PropagationStatus = "FieldRetentionFailed"
 	VersionRetrievalFailed PropagationStatus = "VersionRetrievalFailed"
 	ClientRetrievalFailed  PropagationStatus = "ClientRetrievalFailed"
 	ManagedLabelFalse      PropagationStatus = "ManagedLabelFalse"
+	// ResourceNotFound indicates that the resource was not found in a
+	// placed cluster when observing resources of an observe-only type.
+	ResourceNotFound PropagationStatus = "ResourceNotFound"
+	// CustomResourceDefinitionNotEstablished indicates that the
+	// resource, a custom resource, was not created in the cluster
+	// because its CustomResourceDefinition has not been established
+	// there.
+	CustomResourceDefinitionNotEstablished PropagationStatus = "CustomResourceDefinitionNotEstablished"
+	// WaitingForApplyOrder indicates that the resource was not created
+	// in the cluster because resources of kinds that precede its kind
+	// in the apply order have yet to be created there.
+	WaitingForApplyOrder PropagationStatus = "WaitingForApplyOrder"
+	// NamespaceNotOptedIn indicates that the resource was not created
+	// in or adopted from the cluster because its namespace there lacks
+	// the namespace opt-in label.
+	NamespaceNotOptedIn PropagationStatus = "NamespaceNotOptedIn"
+	// QuotaExceeded indicates that the resource was not created in the
+	// cluster because the quota of the type for the cluster or the
+	// namespace is exhausted.
+	QuotaExceeded PropagationStatus = "QuotaExceeded"
+	// OverrideSourceNotFound indicates that the resource was not
+	// propagated to the cluster because a ConfigMap or Secret that
+	// its overrides reference does not exist or is not labeled as an
+	// override source.
+	OverrideSourceNotFound PropagationStatus = "OverrideSourceNotFound"
+	// CreationRejected indicates that the cluster rejected the creation
+	// of the resource as invalid. Creation is not retried until the
+	// federated resource or its cluster changes.
+	CreationRejected PropagationStatus = "CreationRejected"
+	// UpdateRejected indicates that the cluster rejected the update of
+	// the resource as invalid. The update is not retried until the
+	// federated resource or its cluster changes.
+	UpdateRejected PropagationStatus = "UpdateRejected"
+	// CreationForbidden indicates that the cluster forbade the creation
+	// of the resource, e.g. for lack of authorization or quota.
+	// Creation is retried after a long delay.
+	CreationForbidden PropagationStatus = "CreationForbidden"
+	// UpdateForbidden indicates that the cluster forbade the update of
+	// the resource, e.g. for lack of authorization or quota. The update
+	// is retried after a long delay.
+	UpdateForbidden PropagationStatus = "UpdateForbidden"
 
 	// Operation timeout errors
 	CreationTimedOut     PropagationStatus = "CreationTimedOut"
 	UpdateTimedOut       PropagationStatus = "UpdateTimedOut"
 	DeletionTimedOut     PropagationStatus = "DeletionTimedOut"
 	LabelRemovalTimedOut PropagationStatus = "LabelRemovalTimedOut"
+	ObservationTimedOut  PropagationStatus = "ObservationTimedOut"
 
 	AggregateSuccess       AggregateReason = ""
 	ClusterRetrievalFailed AggregateReason = "ClusterRetrievalFailed"
 	ComputePlacementFailed AggregateReason = "ComputePlacementFailed"
 	CheckClusters          AggregateReason = "CheckClusters"
 	NamespaceNotFederated  AggregateReason = "NamespaceNotFederated"
+	DeletionBlocked        AggregateReason = "DeletionBlocked"
+
+	// UnplacedOverrideClusters indicates that overrides reference
+	// clusters that are not selected by placement.
+	UnplacedOverrideClusters AggregateReason = "UnplacedOverrideClusters"
+	// PropagationTimeout indicates that propagation did not complete
+	// within the propagation deadline of the federated resource.
+	PropagationTimeout AggregateReason = "Timeout"
+	// PropagationDeferred indicates that the only clusters that are
+	// not OK are those for which updates have been deferred until the
+	// propagation window of the type opens.
+	PropagationDeferred AggregateReason = "Deferred"
+	// PropagationPaused indicates that propagation has been paused
+	// for the control plane and that the placed clusters are
+	// otherwise OK.
+	PropagationPaused AggregateReason = "Paused"
+	// CanaryPending indicates that the resource is not yet healthy in
+	// the canary clusters of its placement.
+	CanaryPending AggregateReason = "CanaryPending"
+	// CanaryFailed indicates that propagation to a canary cluster
+	// failed or that the resource reports a failure there, which halts
+	// propagation to the remaining clusters.
+	CanaryFailed AggregateReason = "CanaryFailed"
+	// ApplyRejected indicates that a placed cluster rejected the
+	// resource as invalid.
+	ApplyRejected AggregateReason = "ApplyRejected"
 
 	PropagationConditionType ConditionType = "Propagation"
+	// OverridesPlacedConditionType is only added when overrides have
+	// referenced a cluster not selected by placement, and is a
+	// warning that does not affect propagation.
+	OverridesPlacedConditionType ConditionType = "OverridesPlaced"
+	// AllClustersPropagatedConditionType is only added when placement
+	// specifies minHealthyClusters, in which case the Propagation
+	// condition may be True before all placed clusters are OK.
+	AllClustersPropagatedConditionType ConditionType = "AllClustersPropagated"
+	// FailedConditionType is only added when propagation of a
+	// federated resource specifying propagationDeadlineSeconds did not
+	// complete within the deadline, in which case propagation continues
+	// to be retried while the condition is True, or when a placed
+	// cluster rejected the resource, in which case propagation is not
+	// retried until the federated resource changes.
+	FailedConditionType ConditionType = "Failed"
+	// CanaryHealthyConditionType is only added when placement
+	// references canary clusters, and is True once the resource is
+	// healthy in all of them.
+	CanaryHealthyConditionType ConditionType = "CanaryHealthy"
+
+	// DriftDetectedConditionType is a condition of a cluster that is
+	// only added by the drift detector, and is True while the managed
+	// resource in the cluster was last found to have been modified
+	// out-of-band.
+	DriftDetectedConditionType ConditionType = "DriftDetected"
 )
 
 type GenericClusterStatus struct {
 	Name         string            `json:"name"`
 	Status       PropagationStatus `json:"status,omitempty"`
 	RemoteStatus interface{}       `json:"remoteStatus,omitempty"`
+	// ApplyResult is the outcome of applying to the cluster during
+	// the most recent reconcile, if applying was attempted.
+	ApplyResult ApplyResult `json:"applyResult,omitempty"`
+	// ApplyError is the error encountered if applying failed.
+	ApplyError string `json:"applyError,omitempty"`
+	// ReadyEndpoints is the number of ready endpoints of the
+	// EndpointSlices of a service in the cluster, if collected.
+	ReadyEndpoints *int64 `json:"readyEndpoints,omitempty"`
+	// RetainedUntil is the time until which the cluster remains
+	// placed by the stickiness of placement although it is no longer
+	// selected, if it is retained.
+	RetainedUntil string `json:"retainedUntil,omitempty"`
+	// Conditions of the cluster that are maintained independently
+	// of reconciliation, e.g. by the drift detector.
+	Conditions []*GenericCondition `json:"conditions,omitempty"`
 }
 
 type GenericCondition struct {
@@ -93,12 +250,32 @@ type GenericCondition struct {
 	// (brief) reason for the condition's last transition.
 	// +optional
 	Reason AggregateReason `json:"reason,omitempty"`
+	// Human-readable details of the condition's last transition.
+	// +optional
+	Message string `json:"message,omitempty"`
 }
 
 type GenericFederatedStatus struct {
 	ObservedGeneration int64                  `json:"observedGeneration,omitempty"`
 	Conditions         []*GenericCondition    `json:"conditions,omitempty"`
 	Clusters           []GenericClusterStatus `json:"clusters,omitempty"`
+	// TargetName is the name of the resources managed in member
+	// clusters if it was computed from a name template.
+	TargetName string `json:"targetName,omitempty"`
+	// AggregatedStatus is written by the status controller when the
+	// StatusFeedback feature is enabled and is preserved here.
+	AggregatedStatus map[string]interface{} `json:"aggregatedStatus,omitempty"`
+	// AggregatedMetrics are the aggregates of numeric fields of the
+	// status of target resources across member clusters configured
+	// by the statusAggregations of the FederatedTypeConfig.
+	AggregatedMetrics map[string]int64 `json:"aggregatedMetrics,omitempty"`
+	// UnreportedMetricsClusters are the sorted names of placed
+	// clusters that did not report the status of their target
+	// resource and are therefore not included in AggregatedMetrics.
+	UnreportedMetricsClusters []string `json:"unreportedMetricsClusters,omitempty"`
+	// ReadyEndpoints is the total number of ready endpoints of a
+	// service across member clusters, if collected.
+	ReadyEndpoints *int64 `json:"readyEndpoints,omitempty"`
 }
 
 type GenericFederatedResource struct {
@@ -113,22 +290,63 @@ type PropagationStatusMap map[string]PropagationStatus
 type CollectedPropagationStatus struct {
 	StatusMap        PropagationStatusMap
 	ResourcesUpdated bool
+	TargetName       string
+	// UnplacedOverrideClusters are the sorted names of clusters that
+	// overrides are defined for but that are not selected by
+	// placement.
+	UnplacedOverrideClusters []string
+	// MinHealthyClusters is the number of placed clusters that must
+	// be OK for propagation to be considered successful. All placed
+	// clusters must be OK if it is not set.
+	MinHealthyClusters *int32
+	// PropagationDeadline is the duration within which propagation
+	// must complete before the resource is reported as failed. No
+	// failure is reported if it is not set.
+	PropagationDeadline *time.Duration
+	// DeferredUntil is the time at which the propagation window of the
+	// type next opens if updates were deferred.
+	DeferredUntil *time.Time
+	// ApplyResults are the outcomes of applying to member clusters
+	// during the reconcile, keyed by cluster name.
+	ApplyResults map[string]ClusterApplyResult
+	// RetainedUntil are the times until which clusters that are no
+	// longer selected remain placed, keyed by cluster name.
+	RetainedUntil map[string]time.Time
+	// Canary is the state of the rollout to the canary clusters of
+	// placement, or nil if placement references none.
+	Canary *CanaryStatus
+}
+
+// CanaryStatus is the state of the rollout of a federated resource to
+// the canary clusters of its placement.
+type CanaryStatus struct {
+	// Reason is AggregateSuccess once the resource is healthy in all
+	// canary clusters, and otherwise CanaryPending or CanaryFailed.
+	Reason  AggregateReason
+	Message string
 }
 
 type CollectedResourceStatus struct {
 	StatusMap        map[string]interface{}
 	ResourcesUpdated bool
+	// AggregatedMetrics are the aggregates computed from StatusMap.
+	AggregatedMetrics map[string]int64
+	// UnreportedMetricsClusters are the sorted names of clusters
+	// whose status is not included in AggregatedMetrics because
+	// none was collected.
+	UnreportedMetricsClusters []string
+	// ReadyEndpoints are the numbers of ready endpoints of a service
+	// collected from member clusters, keyed by cluster name.
+	ReadyEndpoints map[string]int64
 }
 
 // SetFederatedStatus sets the conditions and clusters fields of the
 // federated resource's object map. Returns a boolean indication of
 // whether status should be written to the API.
 func SetFederatedStatus(fedObject *unstructured.Unstructured, reason AggregateReason, collectedStatus CollectedPropagationStatus, collectedResourceStatus CollectedResourceStatus, resourceStatusCollection bool) (bool, error) {
-	resource := &GenericFederatedResource{}
-
-	err := utils.UnstructuredToInterface(fedObject, resource)
+	resource, err := DecodeGenericFederatedResource(fedObject)
 	if err != nil {
-		return false, errors.Wrapf(err, "Failed to unmarshall to generic resource")
+		return false, err
 	}
 
 	// we apply to collectedResourceStatus the same marshalling applied to GenericFederatedResource
@@ -148,20 +366,27 @@ func SetFederatedStatus(fedObject *unstructured.Unstructured, reason AggregateRe
 		return false, nil
 	}
 
+	klog.V(4).Infof("Setting the status of federated object '%v'", fedObject.GetName())
+	if err := setStatusField(fedObject, resource); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// setStatusField sets the status field of the given federated object
+// to the status of the given generic resource.
+func setStatusField(fedObject *unstructured.Unstructured, resource *GenericFederatedResource) error {
 	resourceJSON, err := json.Marshal(resource)
 	if err != nil {
-		return false, errors.Wrapf(err, "Failed to marshall generic status to json")
+		return errors.Wrapf(err, "Failed to marshall generic status to json")
 	}
 	resourceObj := &unstructured.Unstructured{}
 	err = resourceObj.UnmarshalJSON(resourceJSON)
 	if err != nil {
-		return false, errors.Wrapf(err, "Failed to marshall generic resource json to unstructured")
+		return errors.Wrapf(err, "Failed to marshall generic resource json to unstructured")
 	}
-
-	klog.V(4).Infof("Setting the status of federated object '%v' and resource object '%v'", fedObject.GetName(), resourceObj.GetName())
 	fedObject.Object[utils.StatusField] = resourceObj.Object[utils.StatusField]
-
-	return true, nil
+	return nil
 }
 
 // IsRecoverableError returns whether the given PropagationStatus is a possibly recoverable error.
@@ -172,17 +397,30 @@ func IsRecoverableError(status PropagationStatus) bool {
 		UpdateFailed,
 		DeletionFailed,
 		LabelRemovalFailed,
+		DrainFailed,
 		RetrievalFailed,
 		ClientRetrievalFailed,
+		TransformationFailed,
+		OwnerReferencesFailed,
+		CustomResourceDefinitionNotEstablished,
+		WaitingForApplyOrder,
+		NamespaceNotOptedIn,
 		CreationTimedOut,
 		UpdateTimedOut,
 		DeletionTimedOut,
-		LabelRemovalTimedOut:
+		LabelRemovalTimedOut,
+		ObservationTimedOut:
 		return true
 	}
 	return false
 }
 
+// propagationSkipped indicates whether the given status is recorded for
+// a cluster that propagation was intentionally not attempted for.
+func propagationSkipped(status PropagationStatus) bool {
+	return status == PlacementOnly || status == Maintenance || status == Paused
+}
+
 // update ensures that the status reflects the given generation, reason
 // and collected status. Returns a boolean indication of whether the
 // status has been changed.
@@ -193,20 +431,65 @@ func (s *GenericFederatedStatus) update(generation int64, reason AggregateReason
 		s.ObservedGeneration = generation
 	}
 
+	// The target name is only known to be accurate when propagation
+	// was attempted.
+	targetNameUpdated := reason == AggregateSuccess && s.TargetName != collectedStatus.TargetName
+	if targetNameUpdated {
+		s.TargetName = collectedStatus.TargetName
+	}
+
+	// Overrides can only be compared to placement when placement was
+	// computed.
+	overridesConditionUpdated := reason == AggregateSuccess && s.setOverridesPlacedCondition(collectedStatus.UnplacedOverrideClusters)
+
+	// Aggregates are only computed when propagation was attempted.
+	metricsUpdated := reason == AggregateSuccess && s.setAggregatedMetrics(collectedResourceStatus.AggregatedMetrics, collectedResourceStatus.UnreportedMetricsClusters)
+	readyEndpointsUpdated := reason == AggregateSuccess && s.setReadyEndpoints(totalReadyEndpoints(collectedResourceStatus.ReadyEndpoints))
+
 	// Identify whether one or more clusters could not be reconciled
 	// successfully.
+	allClustersOK := true
+	var message string
 	if reason == AggregateSuccess {
+		healthyClusters := 0
+		onlyDeferred := true
+		paused := false
 		for cluster, value := range collectedStatus.StatusMap {
+			paused = paused || value == Paused
+			if propagationSkipped(value) {
+				// Neither propagation nor remote status is expected
+				// for a cluster that propagation was skipped for.
+				continue
+			}
 			rawStatus := collectedResourceStatus.StatusMap[cluster]
 			if value != ClusterPropagationOK || (resourceStatusCollection && rawStatus == nil) {
 				klog.V(4).Infof("Check the cluster '%v' with resource status '%v' and propStatus '%v' whose resource status collection is: '%v'", cluster, rawStatus, value, resourceStatusCollection)
-				reason = CheckClusters
-				break
+				allClustersOK = false
+				onlyDeferred = onlyDeferred && value == Deferred
+				continue
+			}
+			healthyClusters++
+		}
+		// Propagation is successful despite lagging clusters as long
+		// as the minimum number of healthy clusters is met.
+		minHealthyClusters := collectedStatus.MinHealthyClusters
+		if !allClustersOK && (minHealthyClusters == nil || healthyClusters < int(*minHealthyClusters)) {
+			reason = CheckClusters
+			if onlyDeferred && collectedStatus.DeferredUntil != nil {
+				reason = PropagationDeferred
+				message = fmt.Sprintf("Updates are deferred until %s", collectedStatus.DeferredUntil.UTC().Format(time.RFC3339))
 			}
 		}
+		if reason == AggregateSuccess && paused {
+			// Propagation is not complete while paused, even if the
+			// resources in placed clusters happen to be current.
+			reason = PropagationPaused
+			message = "Propagation is paused for the control plane"
+		}
 	}
+	allPropagatedConditionUpdated := s.setAllClustersPropagatedCondition(reason, collectedStatus.MinHealthyClusters, allClustersOK)
 
-	clustersChanged := s.setClusters(collectedStatus.StatusMap, collectedResourceStatus.StatusMap, resourceStatusCollection)
+	clustersChanged := s.setClusters(collectedStatus.StatusMap, collectedResourceStatus, collectedStatus.ApplyResults, collectedStatus.RetainedUntil, resourceStatusCollection)
 
 	// Indicate that changes were propagated if either status.clusters
 	// was changed or if existing resources were updated (which could
@@ -214,9 +497,13 @@ func (s *GenericFederatedStatus) update(generation int64, reason AggregateReason
 	// TODO (hectorj2f): re-consider this new condition or add a new one for the resource status update or not.
 	changesPropagated := clustersChanged || len(collectedStatus.StatusMap) > 0 && len(collectedResourceStatus.StatusMap) > 0 && collectedStatus.ResourcesUpdated
 
-	propStatusUpdated := s.setPropagationCondition(reason, changesPropagated)
+	propStatusUpdated := s.setPropagationCondition(reason, message, changesPropagated)
+
+	failedConditionUpdated := s.setFailedCondition(reason, collectedStatus.PropagationDeadline, rejectedClusters(collectedStatus.StatusMap))
 
-	statusUpdated := generationUpdated || propStatusUpdated
+	canaryConditionUpdated := s.setCanaryHealthyCondition(reason, collectedStatus.Canary)
+
+	statusUpdated := generationUpdated || targetNameUpdated || propStatusUpdated || overridesConditionUpdated || allPropagatedConditionUpdated || failedConditionUpdated || canaryConditionUpdated || metricsUpdated || readyEndpointsUpdated
 
 	klog.V(4).Infof("Value of flags: propStatusUpdated: '%v'; statusUpdated '%v'; changesPropagated '%v'", propStatusUpdated, statusUpdated, changesPropagated)
 	return statusUpdated
@@ -225,17 +512,29 @@ func (s *GenericFederatedStatus) update(generation int64, reason AggregateReason
 // setClusters sets the status.clusters slice from propagation and resource status
 // maps. Returns a boolean indication of whether the status.clusters was
 // modified.
-func (s *GenericFederatedStatus) setClusters(statusMap PropagationStatusMap, resourceStatusMap map[string]interface{}, resourceStatusCollection bool) bool {
-	if !s.clustersDiffer(statusMap, resourceStatusMap, resourceStatusCollection) {
+func (s *GenericFederatedStatus) setClusters(statusMap PropagationStatusMap, collectedResourceStatus CollectedResourceStatus, applyResults map[string]ClusterApplyResult, retainedUntil map[string]time.Time, resourceStatusCollection bool) bool {
+	if !s.clustersDiffer(statusMap, collectedResourceStatus, applyResults, retainedUntil, resourceStatusCollection) {
 		return false
 	}
+	// The conditions of a cluster are not determined by
+	// reconciliation and are retained.
+	clusterConditions := make(map[string][]*GenericCondition, len(s.Clusters))
+	for _, cluster := range s.Clusters {
+		clusterConditions[cluster.Name] = cluster.Conditions
+	}
 	s.Clusters = []GenericClusterStatus{}
 	for clusterName, status := range statusMap {
-		rawResourceStatus := resourceStatusMap[clusterName]
+		rawResourceStatus := collectedResourceStatus.StatusMap[clusterName]
+		applyResult := applyResults[clusterName]
 		s.Clusters = append(s.Clusters, GenericClusterStatus{
-			Name:         clusterName,
-			Status:       status,
-			RemoteStatus: rawResourceStatus,
+			Name:           clusterName,
+			Status:         status,
+			RemoteStatus:   rawResourceStatus,
+			ApplyResult:    applyResult.Result,
+			ApplyError:     applyResult.Error,
+			ReadyEndpoints: readyEndpointsForCluster(collectedResourceStatus.ReadyEndpoints, clusterName),
+			RetainedUntil:  retainedUntilForCluster(retainedUntil, clusterName),
+			Conditions:     clusterConditions[clusterName],
 		})
 	}
 	return true
@@ -243,8 +542,15 @@ func (s *GenericFederatedStatus) setClusters(statusMap PropagationStatusMap, res
 
 // clustersDiffer checks whether `status.clusters` differs from the
 // given status map.
-func (s *GenericFederatedStatus) clustersDiffer(statusMap PropagationStatusMap, resourceStatusMap map[string]interface{}, resourceStatusCollection bool) bool {
-	if len(s.Clusters) != len(statusMap) || resourceStatusCollection && len(s.Clusters) != len(resourceStatusMap) {
+func (s *GenericFederatedStatus) clustersDiffer(statusMap PropagationStatusMap, collectedResourceStatus CollectedResourceStatus, applyResults map[string]ClusterApplyResult, retainedUntil map[string]time.Time, resourceStatusCollection bool) bool {
+	resourceStatusMap := collectedResourceStatus.StatusMap
+	skippedCount := 0
+	for _, status := range statusMap {
+		if propagationSkipped(status) {
+			skippedCount++
+		}
+	}
+	if len(s.Clusters) != len(statusMap) || resourceStatusCollection && len(s.Clusters) != len(resourceStatusMap)+skippedCount {
 		klog.V(4).Infof("Clusters differs from the size: clusters = %v, statusMap = %v, resourceStatusMap = %v", s.Clusters, statusMap, resourceStatusMap)
 		return true
 	}
@@ -252,18 +558,29 @@ func (s *GenericFederatedStatus) clustersDiffer(statusMap PropagationStatusMap,
 		if statusMap[status.Name] != status.Status {
 			return true
 		}
+		applyResult := applyResults[status.Name]
+		if applyResult.Result != status.ApplyResult || applyResult.Error != status.ApplyError {
+			return true
+		}
 		if !reflect.DeepEqual(resourceStatusMap[status.Name], status.RemoteStatus) {
 			klog.V(4).Infof("Clusters resource status differ: %v VS %v", resourceStatusMap[status.Name], status.RemoteStatus)
 			return true
 		}
+		if !reflect.DeepEqual(readyEndpointsForCluster(collectedResourceStatus.ReadyEndpoints, status.Name), status.ReadyEndpoints) {
+			return true
+		}
+		if retainedUntilForCluster(retainedUntil, status.Name) != status.RetainedUntil {
+			return true
+		}
 	}
 	return false
 }
 
 // setPropagationCondition ensures that the Propagation condition is
-// updated to reflect the given reason.  The type of the condition is
-// derived from the reason (empty -> True, not empty -> False).
-func (s *GenericFederatedStatus) setPropagationCondition(reason AggregateReason, changesPropagated bool) bool {
+// updated to reflect the given reason and message.  The type of the
+// condition is derived from the reason (empty -> True, not empty ->
+// False).
+func (s *GenericFederatedStatus) setPropagationCondition(reason AggregateReason, message string, changesPropagated bool) bool {
 	// Determine the appropriate status from the reason.
 	var newStatus apiv1.ConditionStatus
 	if reason == AggregateSuccess {
@@ -300,7 +617,10 @@ func (s *GenericFederatedStatus) setPropagationCondition(reason AggregateReason,
 		propCondition.Reason = reason
 	}
 
-	updateRequired := changesPropagated || transition
+	messageChanged := propCondition.Message != message
+	propCondition.Message = message
+
+	updateRequired := changesPropagated || transition || messageChanged
 	if updateRequired {
 		propCondition.LastUpdateTime = now
 	}
@@ -313,8 +633,11 @@ func normalizeStatus(collectedResourceStatus CollectedResourceStatus) (*Collecte
 		return &collectedResourceStatus, nil
 	}
 	cleanedStatus := CollectedResourceStatus{
-		StatusMap:        map[string]interface{}{},
-		ResourcesUpdated: collectedResourceStatus.ResourcesUpdated,
+		StatusMap:                 map[string]interface{}{},
+		ResourcesUpdated:          collectedResourceStatus.ResourcesUpdated,
+		AggregatedMetrics:         collectedResourceStatus.AggregatedMetrics,
+		UnreportedMetricsClusters: collectedResourceStatus.UnreportedMetricsClusters,
+		ReadyEndpoints:            collectedResourceStatus.ReadyEndpoints,
 	}
 
 	for key, value := range collectedResourceStatus.StatusMap {
@@ -332,3 +655,350 @@ func normalizeStatus(collectedResourceStatus CollectedResourceStatus) (*Collecte
 
 	return &cleanedStatus, nil
 }
+
+// setAggregatedMetrics ensures that status.aggregatedMetrics and
+// status.unreportedMetricsClusters reflect the given aggregates and
+// the clusters missing from them. Returns a boolean indication of
+// whether either was modified.
+func (s *GenericFederatedStatus) setAggregatedMetrics(metrics map[string]int64, unreportedClusters []string) bool {
+	updated := false
+	if (len(metrics) != 0 || len(s.AggregatedMetrics) != 0) && !reflect.DeepEqual(s.AggregatedMetrics, metrics) {
+		s.AggregatedMetrics = metrics
+		updated = true
+	}
+	if (len(unreportedClusters) != 0 || len(s.UnreportedMetricsClusters) != 0) && !reflect.DeepEqual(s.UnreportedMetricsClusters, unreportedClusters) {
+		s.UnreportedMetricsClusters = unreportedClusters
+		updated = true
+	}
+	return updated
+}
+
+// setReadyEndpoints ensures that status.readyEndpoints reflects the
+// given total. Returns a boolean indication of whether it was
+// modified.
+func (s *GenericFederatedStatus) setReadyEndpoints(total *int64) bool {
+	if reflect.DeepEqual(s.ReadyEndpoints, total) {
+		return false
+	}
+	s.ReadyEndpoints = total
+	return true
+}
+
+// readyEndpointsForCluster returns the ready endpoints collected for
+// the named cluster, or nil if none were collected.
+func readyEndpointsForCluster(readyEndpoints map[string]int64, clusterName string) *int64 {
+	ready, ok := readyEndpoints[clusterName]
+	if !ok {
+		return nil
+	}
+	return &ready
+}
+
+// setOverridesPlacedCondition ensures that the OverridesPlaced
+// condition reflects the given clusters that overrides reference but
+// placement does not select. The condition is only added once an
+// unplaced cluster has been referenced. Returns a boolean indication of
+// whether the condition was modified.
+func (s *GenericFederatedStatus) setOverridesPlacedCondition(unplacedClusters []string) bool {
+	var condition *GenericCondition
+	for _, c := range s.Conditions {
+		if c.Type == OverridesPlacedConditionType {
+			condition = c
+			break
+		}
+	}
+	if condition == nil {
+		if len(unplacedClusters) == 0 {
+			return false
+		}
+		condition = &GenericCondition{
+			Type: OverridesPlacedConditionType,
+		}
+		s.Conditions = append(s.Conditions, condition)
+	}
+
+	newStatus := apiv1.ConditionTrue
+	var newReason AggregateReason
+	var newMessage string
+	if len(unplacedClusters) > 0 {
+		newStatus = apiv1.ConditionFalse
+		newReason = UnplacedOverrideClusters
+		newMessage = fmt.Sprintf("Overrides reference clusters not selected by placement: %s", strings.Join(unplacedClusters, ", "))
+	}
+
+	if condition.Status == newStatus && condition.Reason == newReason && condition.Message == newMessage {
+		return false
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	if condition.Status != newStatus || condition.Reason != newReason {
+		condition.LastTransitionTime = now
+	}
+	condition.LastUpdateTime = now
+	condition.Status = newStatus
+	condition.Reason = newReason
+	condition.Message = newMessage
+	return true
+}
+
+// setAllClustersPropagatedCondition ensures that the
+// AllClustersPropagated condition reflects whether all placed clusters
+// are OK. The condition is only maintained while minHealthyClusters is
+// set, and is removed once placement no longer sets it. Returns a
+// boolean indication of whether the conditions were modified.
+func (s *GenericFederatedStatus) setAllClustersPropagatedCondition(reason AggregateReason, minHealthyClusters *int32, allClustersOK bool) bool {
+	index := -1
+	for i, c := range s.Conditions {
+		if c.Type == AllClustersPropagatedConditionType {
+			index = i
+			break
+		}
+	}
+	if minHealthyClusters == nil {
+		// Placement is only known not to set minHealthyClusters
+		// when propagation was attempted.
+		if index == -1 || (reason != AggregateSuccess && reason != CheckClusters && reason != PropagationDeferred) {
+			return false
+		}
+		s.Conditions = append(s.Conditions[:index], s.Conditions[index+1:]...)
+		return true
+	}
+
+	var condition *GenericCondition
+	if index == -1 {
+		condition = &GenericCondition{
+			Type: AllClustersPropagatedConditionType,
+		}
+		s.Conditions = append(s.Conditions, condition)
+	} else {
+		condition = s.Conditions[index]
+	}
+
+	newStatus := apiv1.ConditionTrue
+	newReason := reason
+	if newReason == AggregateSuccess && !allClustersOK {
+		newReason = CheckClusters
+	}
+	if newReason != AggregateSuccess {
+		newStatus = apiv1.ConditionFalse
+	}
+
+	if condition.Status == newStatus && condition.Reason == newReason {
+		return false
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	condition.LastTransitionTime = now
+	condition.LastUpdateTime = now
+	condition.Status = newStatus
+	condition.Reason = newReason
+	return true
+}
+
+// isRejected indicates whether the given status is recorded for a
+// cluster that rejected the resource, which is not retried.
+func isRejected(status PropagationStatus) bool {
+	return status == CreationRejected || status == UpdateRejected
+}
+
+// rejectedClusters returns the sorted names of the clusters in the
+// given status map that rejected the resource.
+func rejectedClusters(statusMap PropagationStatusMap) []string {
+	var clusterNames []string
+	for clusterName, status := range statusMap {
+		if isRejected(status) {
+			clusterNames = append(clusterNames, clusterName)
+		}
+	}
+	sort.Strings(clusterNames)
+	return clusterNames
+}
+
+// setFailedCondition ensures that the Failed condition reflects
+// whether a placed cluster rejected the resource or propagation has
+// been incomplete for longer than the given deadline, as measured from
+// the last transition of the Propagation condition. The condition is
+// only added once either has occurred. Returns a boolean indication of
+// whether the condition was modified.
+func (s *GenericFederatedStatus) setFailedCondition(reason AggregateReason, deadline *time.Duration, rejectedClusters []string) bool {
+	// The deadline is only known when propagation was attempted.
+	// Deferred updates do not count towards the deadline.
+	if reason != AggregateSuccess && reason != CheckClusters && reason != PropagationDeferred {
+		return false
+	}
+
+	var condition *GenericCondition
+	for _, c := range s.Conditions {
+		if c.Type == FailedConditionType {
+			condition = c
+			break
+		}
+	}
+
+	failed := false
+	var newReason AggregateReason
+	var newMessage string
+	switch {
+	case reason == CheckClusters && len(rejectedClusters) > 0:
+		failed = true
+		newReason = ApplyRejected
+		newMessage = fmt.Sprintf("The resource was rejected as invalid by clusters: %s", strings.Join(rejectedClusters, ", "))
+	case deadline != nil && reason == CheckClusters:
+		remaining, ok := s.timeUntilPropagationDeadline(*deadline)
+		if failed = ok && remaining <= 0; failed {
+			newReason = PropagationTimeout
+			newMessage = fmt.Sprintf("Propagation did not complete within %v", *deadline)
+		}
+	}
+
+	if condition == nil {
+		if !failed {
+			return false
+		}
+		condition = &GenericCondition{
+			Type: FailedConditionType,
+		}
+		s.Conditions = append(s.Conditions, condition)
+	}
+
+	newStatus := apiv1.ConditionFalse
+	if failed {
+		newStatus = apiv1.ConditionTrue
+	}
+
+	if condition.Status == newStatus && condition.Reason == newReason && condition.Message == newMessage {
+		return false
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	if condition.Status != newStatus || condition.Reason != newReason {
+		condition.LastTransitionTime = now
+	}
+	condition.LastUpdateTime = now
+	condition.Status = newStatus
+	condition.Reason = newReason
+	condition.Message = newMessage
+	return true
+}
+
+// setCanaryHealthyCondition ensures that the CanaryHealthy condition
+// reflects the given state of the rollout to canary clusters. The
+// condition is only maintained while placement references canary
+// clusters, and is removed once it no longer does. Returns a boolean
+// indication of whether the conditions were modified.
+func (s *GenericFederatedStatus) setCanaryHealthyCondition(reason AggregateReason, canary *CanaryStatus) bool {
+	index := -1
+	for i, c := range s.Conditions {
+		if c.Type == CanaryHealthyConditionType {
+			index = i
+			break
+		}
+	}
+	// The canary clusters are only known when propagation was
+	// attempted.
+	if reason != AggregateSuccess && reason != CheckClusters && reason != PropagationDeferred {
+		return false
+	}
+	if canary == nil {
+		if index == -1 {
+			return false
+		}
+		s.Conditions = append(s.Conditions[:index], s.Conditions[index+1:]...)
+		return true
+	}
+
+	var condition *GenericCondition
+	if index == -1 {
+		condition = &GenericCondition{
+			Type: CanaryHealthyConditionType,
+		}
+		s.Conditions = append(s.Conditions, condition)
+	} else {
+		condition = s.Conditions[index]
+	}
+
+	newStatus := apiv1.ConditionTrue
+	if canary.Reason != AggregateSuccess {
+		newStatus = apiv1.ConditionFalse
+	}
+
+	if condition.Status == newStatus && condition.Reason == canary.Reason && condition.Message == canary.Message {
+		return false
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	if condition.Status != newStatus || condition.Reason != canary.Reason {
+		condition.LastTransitionTime = now
+	}
+	condition.LastUpdateTime = now
+	condition.Status = newStatus
+	condition.Reason = canary.Reason
+	condition.Message = canary.Message
+	return true
+}
+
+// timeUntilPropagationDeadline returns the time remaining until
+// incomplete propagation exceeds the given deadline, which is
+// negative once the deadline has passed. False is returned if
+// propagation is not incomplete.
+func (s *GenericFederatedStatus) timeUntilPropagationDeadline(deadline time.Duration) (time.Duration, bool) {
+	for _, condition := range s.Conditions {
+		if condition.Type != PropagationConditionType {
+			continue
+		}
+		if condition.Reason != CheckClusters {
+			return 0, false
+		}
+		transitionTime, err := time.Parse(time.RFC3339, condition.LastTransitionTime)
+		if err != nil {
+			return 0, false
+		}
+		return time.Until(transitionTime.Add(deadline)), true
+	}
+	return 0, false
+}
+
+// PropagationCompleted returns the observed generation of the given
+// federated resource and whether its Propagation condition indicates
+// that propagation to its placed clusters has completed.
+func PropagationCompleted(fedObject *unstructured.Unstructured) (int64, bool) {
+	resource, err := DecodeGenericFederatedResource(fedObject)
+	if err != nil || resource.Status == nil {
+		return 0, false
+	}
+	for _, condition := range resource.Status.Conditions {
+		if condition.Type == PropagationConditionType {
+			return resource.Status.ObservedGeneration, condition.Status == apiv1.ConditionTrue
+		}
+	}
+	return resource.Status.ObservedGeneration, false
+}
+
+// PropagatedClusters returns the sorted names of the clusters that the
+// given status map records as successfully propagated to.
+func PropagatedClusters(statusMap PropagationStatusMap) []string {
+	clusterNames := []string{}
+	for clusterName, value := range statusMap {
+		if value == ClusterPropagationOK {
+			clusterNames = append(clusterNames, clusterName)
+		}
+	}
+	sort.Strings(clusterNames)
+	return clusterNames
+}
+
+// TimeUntilPropagationDeadline returns the time remaining until
+// incomplete propagation of the given federated resource exceeds the
+// given deadline. False is returned if propagation is not incomplete
+// or the deadline has already been exceeded.
+func TimeUntilPropagationDeadline(fedObject *unstructured.Unstructured, deadline time.Duration) (time.Duration, bool) {
+	resource, err := DecodeGenericFederatedResource(fedObject)
+	if err != nil || resource.Status == nil {
+		return 0, false
+	}
+	remaining, ok := resource.Status.timeUntilPropagationDeadline(deadline)
+	if !ok || remaining <= 0 {
+		return 0, false
+	}
+	return remaining, true
+}
@@ -18,9 +18,12 @@ package status
 
 import (
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
 func TestGenericPropagationStatusUpdateChanged(t *testing.T) {
@@ -31,6 +34,7 @@ func TestGenericPropagationStatusUpdateChanged(t *testing.T) {
 		resourceStatusMap        map[string]interface{}
 		remoteStatus             interface{}
 		resourcesUpdated         bool
+		targetName               string
 		expectedChanged          bool
 		resourceStatusCollection bool
 	}{
@@ -190,6 +194,15 @@ func TestGenericPropagationStatusUpdateChanged(t *testing.T) {
 			resourceStatusCollection: false,
 			expectedChanged:          true,
 		},
+		"Changed target name indicates changed": {
+			statusMap: PropagationStatusMap{
+				"cluster1": ClusterPropagationOK,
+			},
+			reason:                   AggregateSuccess,
+			targetName:               "tenant-a",
+			resourceStatusCollection: false,
+			expectedChanged:          true,
+		},
 	}
 	for testName, tc := range testCases {
 		t.Run(testName, func(t *testing.T) {
@@ -210,6 +223,7 @@ func TestGenericPropagationStatusUpdateChanged(t *testing.T) {
 			collectedStatus := CollectedPropagationStatus{
 				StatusMap:        tc.statusMap,
 				ResourcesUpdated: tc.resourcesUpdated,
+				TargetName:       tc.targetName,
 			}
 			collectedResourceStatus := CollectedResourceStatus{
 				StatusMap:        tc.resourceStatusMap,
@@ -223,6 +237,488 @@ func TestGenericPropagationStatusUpdateChanged(t *testing.T) {
 	}
 }
 
+func TestGenericPropagationStatusUpdateTargetName(t *testing.T) {
+	testCases := map[string]struct {
+		reason             AggregateReason
+		recordedName       string
+		targetName         string
+		expectedTargetName string
+	}{
+		"Target name is recorded on success": {
+			reason:             AggregateSuccess,
+			targetName:         "tenant-a",
+			expectedTargetName: "tenant-a",
+		},
+		"Target name is replaced on success": {
+			reason:             AggregateSuccess,
+			recordedName:       "tenant-a",
+			targetName:         "tenant-b",
+			expectedTargetName: "tenant-b",
+		},
+		"Target name is cleared on success": {
+			reason:       AggregateSuccess,
+			recordedName: "tenant-a",
+		},
+		"Target name is retained when propagation was not attempted": {
+			reason:             ClusterRetrievalFailed,
+			recordedName:       "tenant-a",
+			expectedTargetName: "tenant-a",
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			fedStatus := &GenericFederatedStatus{
+				TargetName: tc.recordedName,
+			}
+			collectedStatus := CollectedPropagationStatus{
+				TargetName: tc.targetName,
+			}
+			fedStatus.update(0, tc.reason, collectedStatus, CollectedResourceStatus{}, false)
+			if fedStatus.TargetName != tc.expectedTargetName {
+				t.Fatalf("Expected target name %q, got %q", tc.expectedTargetName, fedStatus.TargetName)
+			}
+		})
+	}
+}
+
+func TestGenericPropagationStatusUpdateSkippedClusters(t *testing.T) {
+	remoteStatus := map[string]interface{}{
+		"status": map[string]interface{}{
+			"status": "remoteStatus",
+		},
+	}
+	resourceStatusMap := map[string]interface{}{
+		"cluster1": remoteStatus,
+	}
+	collectedResourceStatus := CollectedResourceStatus{StatusMap: resourceStatusMap}
+
+	for _, skippedStatus := range []PropagationStatus{PlacementOnly, Maintenance} {
+		statusMap := PropagationStatusMap{
+			"cluster1": ClusterPropagationOK,
+			"cluster2": skippedStatus,
+		}
+		collectedStatus := CollectedPropagationStatus{StatusMap: statusMap}
+
+		for _, resourceStatusCollection := range []bool{true, false} {
+			fedStatus := &GenericFederatedStatus{}
+			if changed := fedStatus.update(0, AggregateSuccess, collectedStatus, collectedResourceStatus, resourceStatusCollection); !changed {
+				t.Fatalf("Expected the initial update to indicate changed")
+			}
+			condition := fedStatus.Conditions[0]
+			if condition.Status != apiv1.ConditionTrue || condition.Reason != AggregateSuccess {
+				t.Fatalf("Expected a %s cluster not to fail propagation, got status %q and reason %q", skippedStatus, condition.Status, condition.Reason)
+			}
+			if changed := fedStatus.update(0, AggregateSuccess, collectedStatus, collectedResourceStatus, resourceStatusCollection); changed {
+				t.Fatalf("Expected an unchanged %s cluster to indicate unchanged with status collection %v", skippedStatus, resourceStatusCollection)
+			}
+		}
+	}
+}
+
+func TestGenericPropagationStatusUpdateDeferred(t *testing.T) {
+	deferredUntil := time.Date(2024, time.March, 2, 2, 0, 0, 0, time.UTC)
+	testCases := map[string]struct {
+		statusMap       PropagationStatusMap
+		expectedReason  AggregateReason
+		expectedMessage string
+	}{
+		"only deferred clusters": {
+			statusMap: PropagationStatusMap{
+				"cluster1": ClusterPropagationOK,
+				"cluster2": Deferred,
+			},
+			expectedReason:  PropagationDeferred,
+			expectedMessage: "Updates are deferred until 2024-03-02T02:00:00Z",
+		},
+		"deferred and failed clusters": {
+			statusMap: PropagationStatusMap{
+				"cluster1": UpdateFailed,
+				"cluster2": Deferred,
+			},
+			expectedReason: CheckClusters,
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			collectedStatus := CollectedPropagationStatus{
+				StatusMap:     tc.statusMap,
+				DeferredUntil: &deferredUntil,
+			}
+			fedStatus := &GenericFederatedStatus{}
+			fedStatus.update(0, AggregateSuccess, collectedStatus, CollectedResourceStatus{}, false)
+			condition := fedStatus.Conditions[0]
+			if condition.Status != apiv1.ConditionFalse || condition.Reason != tc.expectedReason {
+				t.Fatalf("Expected status %q and reason %q, got %q and %q", apiv1.ConditionFalse, tc.expectedReason, condition.Status, condition.Reason)
+			}
+			if condition.Message != tc.expectedMessage {
+				t.Fatalf("Expected message %q, got %q", tc.expectedMessage, condition.Message)
+			}
+		})
+	}
+
+	// Once the deferred update has been applied the message is cleared.
+	fedStatus := &GenericFederatedStatus{}
+	fedStatus.update(0, AggregateSuccess, CollectedPropagationStatus{
+		StatusMap:     PropagationStatusMap{"cluster1": Deferred},
+		DeferredUntil: &deferredUntil,
+	}, CollectedResourceStatus{}, false)
+	if changed := fedStatus.update(0, AggregateSuccess, CollectedPropagationStatus{
+		StatusMap: PropagationStatusMap{"cluster1": ClusterPropagationOK},
+	}, CollectedResourceStatus{}, false); !changed {
+		t.Fatalf("Expected the application of deferred updates to indicate changed")
+	}
+	condition := fedStatus.Conditions[0]
+	if condition.Status != apiv1.ConditionTrue || len(condition.Message) > 0 {
+		t.Fatalf("Expected the condition to be true without a message, got status %q and message %q", condition.Status, condition.Message)
+	}
+}
+
+func TestGenericPropagationStatusUpdateApplyResults(t *testing.T) {
+	collectedStatus := CollectedPropagationStatus{
+		StatusMap: PropagationStatusMap{
+			"cluster1": ClusterPropagationOK,
+			"cluster2": UpdateFailed,
+		},
+		ApplyResults: map[string]ClusterApplyResult{
+			"cluster1": {Result: ApplyUpdated},
+			"cluster2": {Result: ApplyFailed, Error: "update failed"},
+		},
+	}
+	fedStatus := &GenericFederatedStatus{}
+	fedStatus.update(0, AggregateSuccess, collectedStatus, CollectedResourceStatus{}, false)
+	for _, cluster := range fedStatus.Clusters {
+		expected := collectedStatus.ApplyResults[cluster.Name]
+		if cluster.ApplyResult != expected.Result || cluster.ApplyError != expected.Error {
+			t.Fatalf("Expected apply result %q and error %q for cluster %q, got %q and %q", expected.Result, expected.Error, cluster.Name, cluster.ApplyResult, cluster.ApplyError)
+		}
+	}
+
+	// A change in apply result alone is reflected in status.
+	collectedStatus.ApplyResults = map[string]ClusterApplyResult{
+		"cluster1": {Result: ApplyUnchanged},
+		"cluster2": {Result: ApplyFailed, Error: "update failed"},
+	}
+	if changed := fedStatus.update(0, AggregateSuccess, collectedStatus, CollectedResourceStatus{}, false); !changed {
+		t.Fatalf("Expected a change in apply result to indicate changed")
+	}
+	if changed := fedStatus.update(0, AggregateSuccess, collectedStatus, CollectedResourceStatus{}, false); changed {
+		t.Fatalf("Expected unchanged apply results to indicate unchanged")
+	}
+}
+
+func TestGenericPropagationStatusUpdateOverridesPlaced(t *testing.T) {
+	overridesCondition := func(s *GenericFederatedStatus) *GenericCondition {
+		for _, condition := range s.Conditions {
+			if condition.Type == OverridesPlacedConditionType {
+				return condition
+			}
+		}
+		return nil
+	}
+	collectedStatus := func(unplacedClusters ...string) CollectedPropagationStatus {
+		return CollectedPropagationStatus{
+			StatusMap: PropagationStatusMap{
+				"cluster1": ClusterPropagationOK,
+			},
+			UnplacedOverrideClusters: unplacedClusters,
+		}
+	}
+
+	fedStatus := &GenericFederatedStatus{}
+	fedStatus.update(0, AggregateSuccess, collectedStatus(), CollectedResourceStatus{}, false)
+	if overridesCondition(fedStatus) != nil {
+		t.Fatalf("Expected no OverridesPlaced condition when all override clusters are placed")
+	}
+
+	if changed := fedStatus.update(0, AggregateSuccess, collectedStatus("cluster2"), CollectedResourceStatus{}, false); !changed {
+		t.Fatalf("Expected an unplaced override cluster to indicate changed")
+	}
+	condition := overridesCondition(fedStatus)
+	if condition == nil || condition.Status != apiv1.ConditionFalse || condition.Reason != UnplacedOverrideClusters {
+		t.Fatalf("Expected a False OverridesPlaced condition with reason %q, got %v", UnplacedOverrideClusters, condition)
+	}
+	if !strings.Contains(condition.Message, "cluster2") {
+		t.Fatalf("Expected the condition message to name the unplaced cluster, got %q", condition.Message)
+	}
+	for _, c := range fedStatus.Conditions {
+		if c.Type == PropagationConditionType && c.Status != apiv1.ConditionTrue {
+			t.Fatalf("Expected an unplaced override cluster not to affect propagation")
+		}
+	}
+
+	if changed := fedStatus.update(0, AggregateSuccess, collectedStatus("cluster2"), CollectedResourceStatus{}, false); changed {
+		t.Fatalf("Expected an unchanged unplaced override cluster to indicate unchanged")
+	}
+
+	if changed := fedStatus.update(0, ComputePlacementFailed, CollectedPropagationStatus{}, CollectedResourceStatus{}, false); !changed {
+		t.Fatalf("Expected a propagation failure to indicate changed")
+	}
+	if condition := overridesCondition(fedStatus); condition.Status != apiv1.ConditionFalse {
+		t.Fatalf("Expected the OverridesPlaced condition to be retained when placement was not computed")
+	}
+
+	fedStatus.update(0, AggregateSuccess, collectedStatus(), CollectedResourceStatus{}, false)
+	condition = overridesCondition(fedStatus)
+	if condition.Status != apiv1.ConditionTrue || condition.Reason != "" || condition.Message != "" {
+		t.Fatalf("Expected a True OverridesPlaced condition once all override clusters are placed, got %v", condition)
+	}
+}
+
+func TestGenericPropagationStatusUpdateMinHealthyClusters(t *testing.T) {
+	conditionOfType := func(s *GenericFederatedStatus, conditionType ConditionType) *GenericCondition {
+		for _, condition := range s.Conditions {
+			if condition.Type == conditionType {
+				return condition
+			}
+		}
+		return nil
+	}
+	collectedStatus := func(minHealthyClusters *int32, laggingStatus PropagationStatus) CollectedPropagationStatus {
+		return CollectedPropagationStatus{
+			StatusMap: PropagationStatusMap{
+				"cluster1": ClusterPropagationOK,
+				"cluster2": ClusterPropagationOK,
+				"cluster3": laggingStatus,
+			},
+			MinHealthyClusters: minHealthyClusters,
+		}
+	}
+	minHealthyClusters := func(count int32) *int32 {
+		return &count
+	}
+
+	testCases := map[string]struct {
+		minHealthyClusters           *int32
+		laggingStatus                PropagationStatus
+		expectedPropagation          apiv1.ConditionStatus
+		expectedAllPropagated        apiv1.ConditionStatus
+		expectAllPropagatedCondition bool
+	}{
+		"lagging cluster fails propagation without a minimum": {
+			laggingStatus:       CreationFailed,
+			expectedPropagation: apiv1.ConditionFalse,
+		},
+		"lagging cluster does not fail propagation when the minimum is met": {
+			minHealthyClusters:           minHealthyClusters(2),
+			laggingStatus:                CreationFailed,
+			expectedPropagation:          apiv1.ConditionTrue,
+			expectedAllPropagated:        apiv1.ConditionFalse,
+			expectAllPropagatedCondition: true,
+		},
+		"lagging cluster fails propagation when the minimum is not met": {
+			minHealthyClusters:           minHealthyClusters(3),
+			laggingStatus:                CreationFailed,
+			expectedPropagation:          apiv1.ConditionFalse,
+			expectedAllPropagated:        apiv1.ConditionFalse,
+			expectAllPropagatedCondition: true,
+		},
+		"all clusters propagated with a minimum": {
+			minHealthyClusters:           minHealthyClusters(2),
+			laggingStatus:                ClusterPropagationOK,
+			expectedPropagation:          apiv1.ConditionTrue,
+			expectedAllPropagated:        apiv1.ConditionTrue,
+			expectAllPropagatedCondition: true,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			fedStatus := &GenericFederatedStatus{}
+			collected := collectedStatus(tc.minHealthyClusters, tc.laggingStatus)
+			if changed := fedStatus.update(0, AggregateSuccess, collected, CollectedResourceStatus{}, false); !changed {
+				t.Fatalf("Expected the initial update to indicate changed")
+			}
+			if condition := conditionOfType(fedStatus, PropagationConditionType); condition.Status != tc.expectedPropagation {
+				t.Fatalf("Expected Propagation condition status %q, got %q", tc.expectedPropagation, condition.Status)
+			}
+			condition := conditionOfType(fedStatus, AllClustersPropagatedConditionType)
+			if !tc.expectAllPropagatedCondition {
+				if condition != nil {
+					t.Fatalf("Expected no AllClustersPropagated condition without a minimum")
+				}
+			} else if condition == nil || condition.Status != tc.expectedAllPropagated {
+				t.Fatalf("Expected AllClustersPropagated condition status %q, got %v", tc.expectedAllPropagated, condition)
+			}
+
+			// A cluster that is perpetually lagging must not result in
+			// further updates.
+			if changed := fedStatus.update(0, AggregateSuccess, collected, CollectedResourceStatus{}, false); changed {
+				t.Fatalf("Expected an unchanged status to indicate unchanged")
+			}
+		})
+	}
+
+	fedStatus := &GenericFederatedStatus{}
+	fedStatus.update(0, AggregateSuccess, collectedStatus(minHealthyClusters(2), CreationFailed), CollectedResourceStatus{}, false)
+	if changed := fedStatus.update(0, AggregateSuccess, collectedStatus(nil, CreationFailed), CollectedResourceStatus{}, false); !changed {
+		t.Fatalf("Expected removal of the minimum to indicate changed")
+	}
+	if conditionOfType(fedStatus, AllClustersPropagatedConditionType) != nil {
+		t.Fatalf("Expected the AllClustersPropagated condition to be removed with the minimum")
+	}
+}
+
+func TestGenericPropagationStatusUpdatePropagationDeadline(t *testing.T) {
+	conditionOfType := func(s *GenericFederatedStatus, conditionType ConditionType) *GenericCondition {
+		for _, condition := range s.Conditions {
+			if condition.Type == conditionType {
+				return condition
+			}
+		}
+		return nil
+	}
+	deadline := 10 * time.Minute
+	collectedStatus := func(laggingStatus PropagationStatus) CollectedPropagationStatus {
+		return CollectedPropagationStatus{
+			StatusMap: PropagationStatusMap{
+				"cluster1": ClusterPropagationOK,
+				"cluster2": laggingStatus,
+			},
+			PropagationDeadline: &deadline,
+		}
+	}
+
+	fedStatus := &GenericFederatedStatus{}
+	fedStatus.update(0, AggregateSuccess, collectedStatus(CreationFailed), CollectedResourceStatus{}, false)
+	if conditionOfType(fedStatus, FailedConditionType) != nil {
+		t.Fatalf("Expected no Failed condition before the deadline")
+	}
+	if _, ok := fedStatus.timeUntilPropagationDeadline(deadline); !ok {
+		t.Fatalf("Expected a deadline to apply to incomplete propagation")
+	}
+
+	// Simulate a cluster that has not converged within the deadline.
+	propCondition := conditionOfType(fedStatus, PropagationConditionType)
+	propCondition.LastTransitionTime = time.Now().Add(-deadline).UTC().Format(time.RFC3339)
+	if changed := fedStatus.update(0, AggregateSuccess, collectedStatus(CreationFailed), CollectedResourceStatus{}, false); !changed {
+		t.Fatalf("Expected exceeding the deadline to indicate changed")
+	}
+	condition := conditionOfType(fedStatus, FailedConditionType)
+	if condition == nil || condition.Status != apiv1.ConditionTrue || condition.Reason != PropagationTimeout {
+		t.Fatalf("Expected a True Failed condition with reason %q, got %v", PropagationTimeout, condition)
+	}
+	if propCondition.Reason != CheckClusters {
+		t.Fatalf("Expected the Propagation condition to retain reason %q, got %q", CheckClusters, propCondition.Reason)
+	}
+	if changed := fedStatus.update(0, AggregateSuccess, collectedStatus(CreationFailed), CollectedResourceStatus{}, false); changed {
+		t.Fatalf("Expected a perpetually lagging cluster to indicate unchanged")
+	}
+
+	// Propagation that eventually completes clears the failure.
+	if changed := fedStatus.update(0, AggregateSuccess, collectedStatus(ClusterPropagationOK), CollectedResourceStatus{}, false); !changed {
+		t.Fatalf("Expected completion of propagation to indicate changed")
+	}
+	if condition := conditionOfType(fedStatus, FailedConditionType); condition.Status != apiv1.ConditionFalse {
+		t.Fatalf("Expected the Failed condition to be False after propagation completed, got %q", condition.Status)
+	}
+}
+
+func TestGenericPropagationStatusUpdateApplyRejected(t *testing.T) {
+	collectedStatus := func(clusterStatus PropagationStatus) CollectedPropagationStatus {
+		return CollectedPropagationStatus{
+			StatusMap: PropagationStatusMap{
+				"cluster1": clusterStatus,
+				"cluster2": UpdateRejected,
+				"cluster3": ClusterPropagationOK,
+			},
+		}
+	}
+
+	fedStatus := &GenericFederatedStatus{}
+	if changed := fedStatus.update(0, AggregateSuccess, collectedStatus(CreationRejected), CollectedResourceStatus{}, false); !changed {
+		t.Fatalf("Expected a rejection to indicate changed")
+	}
+	var condition *GenericCondition
+	for _, c := range fedStatus.Conditions {
+		if c.Type == FailedConditionType {
+			condition = c
+		}
+	}
+	if condition == nil || condition.Status != apiv1.ConditionTrue || condition.Reason != ApplyRejected {
+		t.Fatalf("Expected a True Failed condition with reason %q, got %v", ApplyRejected, condition)
+	}
+	if expected := "The resource was rejected as invalid by clusters: cluster1, cluster2"; condition.Message != expected {
+		t.Fatalf("Expected message %q, got %q", expected, condition.Message)
+	}
+
+	// A cluster that accepts the resource is no longer reported.
+	if changed := fedStatus.update(0, AggregateSuccess, collectedStatus(ClusterPropagationOK), CollectedResourceStatus{}, false); !changed {
+		t.Fatalf("Expected a change of the rejecting clusters to indicate changed")
+	}
+	if expected := "The resource was rejected as invalid by clusters: cluster2"; condition.Message != expected {
+		t.Fatalf("Expected message %q, got %q", expected, condition.Message)
+	}
+}
+
+func TestGenericPropagationStatusUpdateCanary(t *testing.T) {
+	conditionOfType := func(s *GenericFederatedStatus, conditionType ConditionType) *GenericCondition {
+		for _, condition := range s.Conditions {
+			if condition.Type == conditionType {
+				return condition
+			}
+		}
+		return nil
+	}
+	collectedStatus := func(remainingStatus PropagationStatus, canary *CanaryStatus) CollectedPropagationStatus {
+		return CollectedPropagationStatus{
+			StatusMap: PropagationStatusMap{
+				"cluster1": ClusterPropagationOK,
+				"cluster2": remainingStatus,
+			},
+			Canary: canary,
+		}
+	}
+
+	fedStatus := &GenericFederatedStatus{}
+	fedStatus.update(0, AggregateSuccess, collectedStatus(ClusterPropagationOK, nil), CollectedResourceStatus{}, false)
+	if conditionOfType(fedStatus, CanaryHealthyConditionType) != nil {
+		t.Fatalf("Expected no CanaryHealthy condition without canary clusters")
+	}
+
+	pending := &CanaryStatus{Reason: CanaryPending, Message: "Waiting for cluster1"}
+	if changed := fedStatus.update(0, AggregateSuccess, collectedStatus(WaitingForCanary, pending), CollectedResourceStatus{}, false); !changed {
+		t.Fatalf("Expected a pending canary to indicate changed")
+	}
+	condition := conditionOfType(fedStatus, CanaryHealthyConditionType)
+	if condition == nil || condition.Status != apiv1.ConditionFalse || condition.Reason != CanaryPending {
+		t.Fatalf("Expected a False CanaryHealthy condition with reason %q, got %v", CanaryPending, condition)
+	}
+	if propCondition := conditionOfType(fedStatus, PropagationConditionType); propCondition.Status != apiv1.ConditionFalse || propCondition.Reason != CheckClusters {
+		t.Fatalf("Expected a cluster waiting for the canary to fail propagation with reason %q, got %v", CheckClusters, propCondition)
+	}
+	if changed := fedStatus.update(0, AggregateSuccess, collectedStatus(WaitingForCanary, pending), CollectedResourceStatus{}, false); changed {
+		t.Fatalf("Expected an unchanged pending canary to indicate unchanged")
+	}
+
+	failed := &CanaryStatus{Reason: CanaryFailed, Message: "Canary cluster1 failed"}
+	if changed := fedStatus.update(0, AggregateSuccess, collectedStatus(WaitingForCanary, failed), CollectedResourceStatus{}, false); !changed {
+		t.Fatalf("Expected a failed canary to indicate changed")
+	}
+	condition = conditionOfType(fedStatus, CanaryHealthyConditionType)
+	if condition.Status != apiv1.ConditionFalse || condition.Reason != CanaryFailed || condition.Message != failed.Message {
+		t.Fatalf("Expected a False CanaryHealthy condition with reason %q, got %v", CanaryFailed, condition)
+	}
+
+	if changed := fedStatus.update(0, ComputePlacementFailed, CollectedPropagationStatus{}, CollectedResourceStatus{}, false); !changed {
+		t.Fatalf("Expected a propagation failure to indicate changed")
+	}
+	if conditionOfType(fedStatus, CanaryHealthyConditionType) == nil {
+		t.Fatalf("Expected the CanaryHealthy condition to be retained when placement was not computed")
+	}
+
+	fedStatus.update(0, AggregateSuccess, collectedStatus(ClusterPropagationOK, &CanaryStatus{}), CollectedResourceStatus{}, false)
+	condition = conditionOfType(fedStatus, CanaryHealthyConditionType)
+	if condition.Status != apiv1.ConditionTrue || condition.Reason != "" || condition.Message != "" {
+		t.Fatalf("Expected a True CanaryHealthy condition once the canary is healthy, got %v", condition)
+	}
+
+	if changed := fedStatus.update(0, AggregateSuccess, collectedStatus(ClusterPropagationOK, nil), CollectedResourceStatus{}, false); !changed {
+		t.Fatalf("Expected removal of the canary clusters to indicate changed")
+	}
+	if conditionOfType(fedStatus, CanaryHealthyConditionType) != nil {
+		t.Fatalf("Expected the CanaryHealthy condition to be removed with the canary clusters")
+	}
+}
+
 func TestNormalizeStatus(t *testing.T) {
 	testCases := []struct {
 		name           string
@@ -280,3 +776,196 @@ func TestNormalizeStatus(t *testing.T) {
 		})
 	}
 }
+
+func TestSetFederatedStatusPreservesAggregatedStatus(t *testing.T) {
+	aggregatedStatus := map[string]interface{}{
+		"loadBalancer": map[string]interface{}{
+			"ingress": []interface{}{
+				map[string]interface{}{"ip": "10.0.0.1"},
+			},
+		},
+	}
+	fedObject := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "types.kubefed.io/v1beta1",
+			"kind":       "FederatedService",
+			"metadata": map[string]interface{}{
+				"name":       "foo",
+				"namespace":  "ns",
+				"generation": int64(2),
+			},
+			"status": map[string]interface{}{
+				"aggregatedStatus": aggregatedStatus,
+			},
+		},
+	}
+
+	collectedStatus := CollectedPropagationStatus{
+		StatusMap: PropagationStatusMap{"cluster1": ClusterPropagationOK},
+	}
+	changed, err := SetFederatedStatus(fedObject, AggregateSuccess, collectedStatus, CollectedResourceStatus{}, false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !changed {
+		t.Fatalf("Expected the status to be changed")
+	}
+
+	actual, _, err := unstructured.NestedMap(fedObject.Object, "status", "aggregatedStatus")
+	if err != nil {
+		t.Fatalf("Unexpected error reading aggregated status: %v", err)
+	}
+	if !reflect.DeepEqual(aggregatedStatus, actual) {
+		t.Fatalf("Expected aggregated status %v to be preserved, got %v", aggregatedStatus, actual)
+	}
+}
+
+func TestSetFederatedStatusAggregatedMetrics(t *testing.T) {
+	fedObject := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "types.kubefed.io/v1beta1",
+			"kind":       "FederatedDeployment",
+			"metadata": map[string]interface{}{
+				"name":       "foo",
+				"namespace":  "ns",
+				"generation": int64(1),
+			},
+		},
+	}
+	collectedStatus := CollectedPropagationStatus{
+		StatusMap: PropagationStatusMap{"cluster1": ClusterPropagationOK, "cluster2": ClusterPropagationOK},
+	}
+	collectedResourceStatus := CollectedResourceStatus{
+		StatusMap: map[string]interface{}{
+			"cluster1": map[string]interface{}{"readyReplicas": int64(2)},
+			"cluster2": map[string]interface{}{"readyReplicas": int64(3)},
+		},
+		AggregatedMetrics: map[string]int64{"totalReadyReplicas": 5},
+	}
+
+	changed, err := SetFederatedStatus(fedObject, AggregateSuccess, collectedStatus, collectedResourceStatus, true)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !changed {
+		t.Fatalf("Expected the status to be changed")
+	}
+	actual, _, err := unstructured.NestedFieldNoCopy(fedObject.Object, "status", "aggregatedMetrics", "totalReadyReplicas")
+	if err != nil {
+		t.Fatalf("Unexpected error reading aggregated metrics: %v", err)
+	}
+	if actual != int64(5) {
+		t.Fatalf("Expected the aggregated metric to be 5, got %v", actual)
+	}
+
+	changed, err = SetFederatedStatus(fedObject, AggregateSuccess, collectedStatus, collectedResourceStatus, true)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if changed {
+		t.Fatalf("Expected the status not to be changed when the aggregated metrics are unchanged")
+	}
+}
+
+func TestSetFederatedStatusReadyEndpoints(t *testing.T) {
+	fedObject := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "types.kubefed.io/v1beta1",
+			"kind":       "FederatedService",
+			"metadata": map[string]interface{}{
+				"name":       "foo",
+				"namespace":  "ns",
+				"generation": int64(1),
+			},
+		},
+	}
+	collectedStatus := CollectedPropagationStatus{
+		StatusMap: PropagationStatusMap{"cluster1": ClusterPropagationOK, "cluster2": ClusterPropagationOK},
+	}
+	collectedResourceStatus := CollectedResourceStatus{
+		StatusMap: map[string]interface{}{
+			"cluster1": map[string]interface{}{},
+			"cluster2": map[string]interface{}{},
+		},
+		ReadyEndpoints: map[string]int64{"cluster1": 2, "cluster2": 0},
+	}
+
+	changed, err := SetFederatedStatus(fedObject, AggregateSuccess, collectedStatus, collectedResourceStatus, true)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !changed {
+		t.Fatalf("Expected the status to be changed")
+	}
+	resource, err := DecodeGenericFederatedResource(fedObject)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if total := resource.Status.ReadyEndpoints; total == nil || *total != 2 {
+		t.Fatalf("Expected 2 ready endpoints in total, got %v", total)
+	}
+	for _, cluster := range resource.Status.Clusters {
+		expected := collectedResourceStatus.ReadyEndpoints[cluster.Name]
+		if cluster.ReadyEndpoints == nil || *cluster.ReadyEndpoints != expected {
+			t.Fatalf("Expected %d ready endpoints for cluster %q, got %v", expected, cluster.Name, cluster.ReadyEndpoints)
+		}
+	}
+
+	changed, err = SetFederatedStatus(fedObject, AggregateSuccess, collectedStatus, collectedResourceStatus, true)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if changed {
+		t.Fatalf("Expected the status not to be changed when the ready endpoints are unchanged")
+	}
+
+	collectedResourceStatus.ReadyEndpoints = map[string]int64{"cluster1": 2, "cluster2": 1}
+	changed, err = SetFederatedStatus(fedObject, AggregateSuccess, collectedStatus, collectedResourceStatus, true)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !changed {
+		t.Fatalf("Expected the status to be changed when the ready endpoints of a cluster change")
+	}
+}
+
+func TestPropagationCompleted(t *testing.T) {
+	fedObject := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "types.kubefed.io/v1beta1",
+			"kind":       "FederatedConfigMap",
+			"metadata": map[string]interface{}{
+				"name":       "foo",
+				"namespace":  "ns",
+				"generation": int64(2),
+			},
+		},
+	}
+	if _, completed := PropagationCompleted(fedObject); completed {
+		t.Fatalf("Expected propagation not to be completed without status")
+	}
+
+	collectedStatus := CollectedPropagationStatus{
+		StatusMap: PropagationStatusMap{"cluster2": ClusterPropagationOK, "cluster1": ClusterPropagationOK, "cluster3": CreationFailed},
+	}
+	if _, err := SetFederatedStatus(fedObject, AggregateSuccess, collectedStatus, CollectedResourceStatus{}, false); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, completed := PropagationCompleted(fedObject); completed {
+		t.Fatalf("Expected propagation not to be completed while a cluster failed")
+	}
+
+	collectedStatus.StatusMap["cluster3"] = PlacementOnly
+	if _, err := SetFederatedStatus(fedObject, AggregateSuccess, collectedStatus, CollectedResourceStatus{}, false); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	generation, completed := PropagationCompleted(fedObject)
+	if !completed || generation != 2 {
+		t.Fatalf("Expected propagation of generation 2 to be completed, got generation %d completed %v", generation, completed)
+	}
+
+	expectedClusters := []string{"cluster1", "cluster2"}
+	if clusters := PropagatedClusters(collectedStatus.StatusMap); !reflect.DeepEqual(clusters, expectedClusters) {
+		t.Fatalf("Expected propagated clusters %v, got %v", expectedClusters, clusters)
+	}
+}
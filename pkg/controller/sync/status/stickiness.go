@@ -0,0 +1,94 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// RetainedClusters returns the times until which the given clusters,
+// which are no longer selected by the placement of the given
+// federated resource, remain placed due to the given stickiness.
+//
+// A cluster is retained if the status of the resource reports it as
+// placed rather than as having its resource removed. A cluster that
+// the status does not report as retained has only just stopped being
+// selected, unless its retention was previously determined but not yet
+// written to status, in which case the given recorded time applies.
+// It is otherwise retained for the stickiness from now. A cluster
+// whose retention has elapsed is no longer retained, and once a
+// cluster is selected again its retention no longer applies.
+func RetainedClusters(fedObject *unstructured.Unstructured, unselectedClusterNames sets.Set[string], stickiness time.Duration, recorded map[string]time.Time, now time.Time) (map[string]time.Time, error) {
+	resource, err := DecodeGenericFederatedResource(fedObject)
+	if err != nil {
+		return nil, err
+	}
+	retained := make(map[string]time.Time)
+	if resource.Status == nil {
+		return retained, nil
+	}
+	for _, cluster := range resource.Status.Clusters {
+		if !unselectedClusterNames.Has(cluster.Name) || removingResource(cluster.Status) {
+			continue
+		}
+		// A retention that cannot be parsed is restarted rather than
+		// ending placement prematurely.
+		until, err := time.Parse(time.RFC3339, cluster.RetainedUntil)
+		if err != nil {
+			var ok bool
+			until, ok = recorded[cluster.Name]
+			if !ok {
+				until = now.Add(stickiness)
+			}
+		}
+		if now.Before(until) {
+			retained[cluster.Name] = until
+		}
+	}
+	return retained, nil
+}
+
+// removingResource indicates whether the given status is recorded for
+// a cluster from which the resource is being removed.
+func removingResource(status PropagationStatus) bool {
+	switch status {
+	case
+		WaitingForRemoval,
+		Draining,
+		DrainFailed,
+		DeletionFailed,
+		DeletionTimedOut,
+		LabelRemovalFailed,
+		LabelRemovalTimedOut:
+		return true
+	}
+	return false
+}
+
+// retainedUntilForCluster returns the time until which the named
+// cluster is retained in the form recorded in status, or an empty
+// string if it is not retained.
+func retainedUntilForCluster(retainedUntil map[string]time.Time, clusterName string) string {
+	until, ok := retainedUntil[clusterName]
+	if !ok {
+		return ""
+	}
+	return until.UTC().Format(time.RFC3339)
+}
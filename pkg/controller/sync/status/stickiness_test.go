@@ -0,0 +1,107 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+func TestRetainedClusters(t *testing.T) {
+	now := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+	stickiness := 5 * time.Minute
+	fedObject := func(clusters ...interface{}) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{}}
+		if len(clusters) > 0 {
+			obj.Object["status"] = map[string]interface{}{"clusters": clusters}
+		}
+		return obj
+	}
+	cluster := func(name string, status PropagationStatus, retainedUntil string) interface{} {
+		cluster := map[string]interface{}{"name": name}
+		if status != "" {
+			cluster["status"] = string(status)
+		}
+		if retainedUntil != "" {
+			cluster["retainedUntil"] = retainedUntil
+		}
+		return cluster
+	}
+	testCases := map[string]struct {
+		fedObject  *unstructured.Unstructured
+		unselected sets.Set[string]
+		recorded   map[string]time.Time
+		expected   map[string]time.Time
+	}{
+		"no status": {
+			fedObject:  fedObject(),
+			unselected: sets.New("cluster1"),
+			expected:   map[string]time.Time{},
+		},
+		"newly unselected cluster is retained for the stickiness": {
+			fedObject:  fedObject(cluster("cluster1", ClusterPropagationOK, "")),
+			unselected: sets.New("cluster1"),
+			expected:   map[string]time.Time{"cluster1": now.Add(stickiness)},
+		},
+		"retention not yet written to status is preserved": {
+			fedObject:  fedObject(cluster("cluster1", ClusterPropagationOK, "")),
+			unselected: sets.New("cluster1"),
+			recorded:   map[string]time.Time{"cluster1": now.Add(time.Minute)},
+			expected:   map[string]time.Time{"cluster1": now.Add(time.Minute)},
+		},
+		"recorded retention is preserved until it elapses": {
+			fedObject:  fedObject(cluster("cluster1", ClusterPropagationOK, "2024-03-01T12:02:00Z")),
+			unselected: sets.New("cluster1"),
+			expected:   map[string]time.Time{"cluster1": now.Add(2 * time.Minute)},
+		},
+		"elapsed retention is not retained": {
+			fedObject:  fedObject(cluster("cluster1", ClusterPropagationOK, "2024-03-01T12:00:00Z")),
+			unselected: sets.New("cluster1"),
+			expected:   map[string]time.Time{},
+		},
+		"invalid retention is restarted": {
+			fedObject:  fedObject(cluster("cluster1", ClusterPropagationOK, "never")),
+			unselected: sets.New("cluster1"),
+			expected:   map[string]time.Time{"cluster1": now.Add(stickiness)},
+		},
+		"cluster whose resource is being removed is not retained": {
+			fedObject:  fedObject(cluster("cluster1", WaitingForRemoval, ""), cluster("cluster2", Draining, "")),
+			unselected: sets.New("cluster1", "cluster2"),
+			expected:   map[string]time.Time{},
+		},
+		"selected cluster is not retained": {
+			fedObject:  fedObject(cluster("cluster1", ClusterPropagationOK, "2024-03-01T12:02:00Z")),
+			unselected: sets.New[string](),
+			expected:   map[string]time.Time{},
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			actual, err := RetainedClusters(tc.fedObject, tc.unselected, stickiness, tc.recorded, now)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(actual, tc.expected) {
+				t.Fatalf("Expected retained clusters %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+}
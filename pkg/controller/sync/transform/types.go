@@ -0,0 +1,42 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package transform
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Request is sent by the sync controller to a transformation webhook
+// for each object computed for a member cluster.
+type Request struct {
+	// ClusterName is the name of the member cluster the object will
+	// be applied to.
+	ClusterName string `json:"clusterName"`
+	// Object is the object computed for the cluster from the template
+	// and overrides of a federated resource.
+	Object *unstructured.Unstructured `json:"object"`
+}
+
+// Response is returned by a transformation webhook. The returned
+// object is applied to the member cluster in place of the requested
+// object, and must have the same apiVersion, kind, namespace and name.
+// A non-empty error rejects the object and fails propagation of the
+// object to the cluster.
+type Response struct {
+	Object *unstructured.Unstructured `json:"object,omitempty"`
+	Error  string                     `json:"error,omitempty"`
+}
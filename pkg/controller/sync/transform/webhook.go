@@ -0,0 +1,133 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package transform
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	fedv1b1 "sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
+)
+
+// DefaultTimeout is the time to wait for a webhook to respond if the
+// webhook does not configure a timeout.
+const DefaultTimeout = 10 * time.Second
+
+// Transformer transforms the object computed for a member cluster
+// before it is applied to the cluster.
+type Transformer interface {
+	Transform(ctx context.Context, clusterName string, obj *unstructured.Unstructured) (*unstructured.Unstructured, error)
+}
+
+type webhookTransformer struct {
+	url        string
+	timeout    time.Duration
+	httpClient *http.Client
+}
+
+// NewWebhookTransformer returns a Transformer that calls the given
+// webhook, or nil if no webhook is configured.
+func NewWebhookTransformer(webhook *fedv1b1.TransformationWebhook) (Transformer, error) {
+	if webhook == nil {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if len(webhook.CABundle) > 0 {
+		certPool := x509.NewCertPool()
+		if !certPool.AppendCertsFromPEM(webhook.CABundle) {
+			return nil, errors.New("Unable to load the CA bundle of the transformation webhook")
+		}
+		tlsConfig.RootCAs = certPool
+	}
+
+	timeout := DefaultTimeout
+	if webhook.TimeoutSeconds != nil {
+		timeout = time.Duration(*webhook.TimeoutSeconds) * time.Second
+	}
+
+	return &webhookTransformer{
+		url:     webhook.URL,
+		timeout: timeout,
+		httpClient: &http.Client{
+			Transport: &http.Transport{TLSClientConfig: tlsConfig},
+		},
+	}, nil
+}
+
+// Transform sends the object to the webhook and returns the object the
+// webhook responded with. An error is returned if the webhook cannot
+// be called, rejects the object or responds with an object that does
+// not identify the same resource.
+func (t *webhookTransformer) Transform(ctx context.Context, clusterName string, obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	ctx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+
+	var body bytes.Buffer
+	if err := json.NewEncoder(&body).Encode(&Request{ClusterName: clusterName, Object: obj}); err != nil {
+		return nil, errors.Wrap(err, "Error encoding transformation request")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, &body)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error creating transformation request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error calling transformation webhook")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("Transformation webhook returned %s", resp.Status)
+	}
+	response := &Response{}
+	if err := json.NewDecoder(resp.Body).Decode(response); err != nil {
+		return nil, errors.Wrap(err, "Error decoding transformation response")
+	}
+	if len(response.Error) > 0 {
+		return nil, errors.Errorf("Transformation webhook rejected the object: %s", response.Error)
+	}
+	if response.Object == nil {
+		return nil, errors.New("Transformation webhook did not return an object")
+	}
+	if err := checkSameResource(obj, response.Object); err != nil {
+		return nil, err
+	}
+	return response.Object, nil
+}
+
+func checkSameResource(requested, transformed *unstructured.Unstructured) error {
+	if requested.GetAPIVersion() != transformed.GetAPIVersion() || requested.GetKind() != transformed.GetKind() {
+		return errors.Errorf("Transformation webhook changed the type of the object from %s %s to %s %s",
+			requested.GetAPIVersion(), requested.GetKind(), transformed.GetAPIVersion(), transformed.GetKind())
+	}
+	if requested.GetNamespace() != transformed.GetNamespace() || requested.GetName() != transformed.GetName() {
+		return errors.Errorf("Transformation webhook changed the namespace or name of the object")
+	}
+	return nil
+}
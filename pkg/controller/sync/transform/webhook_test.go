@@ -0,0 +1,161 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package transform
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	fedv1b1 "sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
+)
+
+// newFakeWebhook returns a TLS server that transforms requested
+// objects with the given function, and the configuration to call it.
+func newFakeWebhook(t *testing.T, transformFunc func(*Request) *Response) (*httptest.Server, *fedv1b1.TransformationWebhook) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		request := &Request{}
+		if err := json.NewDecoder(req.Body).Decode(request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		response := transformFunc(request)
+		if response == nil {
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			t.Errorf("Error encoding response: %v", err)
+		}
+	}))
+	caBundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	return server, &fedv1b1.TransformationWebhook{
+		URL:      server.URL + "/transform",
+		CABundle: caBundle,
+	}
+}
+
+func newConfigMap() *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("v1")
+	obj.SetKind("ConfigMap")
+	obj.SetNamespace("ns")
+	obj.SetName("foo")
+	_ = unstructured.SetNestedField(obj.Object, "value", "data", "key")
+	_ = unstructured.SetNestedField(obj.Object, "host-only", "data", "hostOnly")
+	return obj
+}
+
+func TestWebhookTransformer(t *testing.T) {
+	testCases := map[string]struct {
+		transformFunc func(*Request) *Response
+		expectedError string
+	}{
+		"object is transformed": {
+			transformFunc: func(request *Request) *Response {
+				obj := request.Object
+				unstructured.RemoveNestedField(obj.Object, "data", "hostOnly")
+				_ = unstructured.SetNestedField(obj.Object, request.ClusterName, "data", "cluster")
+				return &Response{Object: obj}
+			},
+		},
+		"object is rejected": {
+			transformFunc: func(request *Request) *Response {
+				return &Response{Error: "not allowed"}
+			},
+			expectedError: "rejected the object: not allowed",
+		},
+		"no object is returned": {
+			transformFunc: func(request *Request) *Response {
+				return &Response{}
+			},
+			expectedError: "did not return an object",
+		},
+		"name is changed": {
+			transformFunc: func(request *Request) *Response {
+				request.Object.SetName("bar")
+				return &Response{Object: request.Object}
+			},
+			expectedError: "changed the namespace or name",
+		},
+		"webhook fails": {
+			transformFunc: func(request *Request) *Response {
+				return nil
+			},
+			expectedError: "500",
+		},
+	}
+
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			server, webhook := newFakeWebhook(t, tc.transformFunc)
+			defer server.Close()
+
+			transformer, err := NewWebhookTransformer(webhook)
+			if err != nil {
+				t.Fatalf("Unexpected error creating transformer: %v", err)
+			}
+			obj, err := transformer.Transform(context.Background(), "cluster1", newConfigMap())
+			if len(tc.expectedError) > 0 {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedError) {
+					t.Fatalf("Expected an error containing %q, got %v", tc.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			expectedData := map[string]interface{}{
+				"key":     "value",
+				"cluster": "cluster1",
+			}
+			data, _, _ := unstructured.NestedMap(obj.Object, "data")
+			if len(data) != len(expectedData) || data["key"] != expectedData["key"] || data["cluster"] != expectedData["cluster"] {
+				t.Fatalf("Expected data %v, got %v", expectedData, data)
+			}
+		})
+	}
+}
+
+func TestWebhookTransformerUntrustedCertificate(t *testing.T) {
+	server, webhook := newFakeWebhook(t, func(request *Request) *Response {
+		return &Response{Object: request.Object}
+	})
+	defer server.Close()
+	webhook.CABundle = nil
+
+	transformer, err := NewWebhookTransformer(webhook)
+	if err != nil {
+		t.Fatalf("Unexpected error creating transformer: %v", err)
+	}
+	if _, err := transformer.Transform(context.Background(), "cluster1", newConfigMap()); err == nil {
+		t.Fatalf("Expected an error calling a webhook with an untrusted certificate")
+	}
+}
+
+func TestNewWebhookTransformerWithoutWebhook(t *testing.T) {
+	transformer, err := NewWebhookTransformer(nil)
+	if err != nil || transformer != nil {
+		t.Fatalf("Expected no transformer and no error, got %v and %v", transformer, err)
+	}
+}
@@ -0,0 +1,191 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package version
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/klog/v2"
+
+	fedv1a1 "sigs.k8s.io/kubefed/pkg/apis/core/v1alpha1"
+	"sigs.k8s.io/kubefed/pkg/client/generic"
+	"sigs.k8s.io/kubefed/pkg/controller/utils"
+)
+
+// VersionExport is a serializable snapshot of propagated versions.
+// Versions can be rebuilt by the sync controllers, but only by
+// updating every resource in member clusters, so restoring them
+// avoids the mass re-propagation that would otherwise follow the
+// recovery of a control plane.
+type VersionExport struct {
+	PropagatedVersions        []fedv1a1.PropagatedVersion        `json:"propagatedVersions,omitempty"`
+	ClusterPropagatedVersions []fedv1a1.ClusterPropagatedVersion `json:"clusterPropagatedVersions,omitempty"`
+}
+
+// ExportVersions serializes the propagated versions in the given
+// namespace, or in all namespaces if the namespace is empty. The
+// versions of federated namespaces are stored in the namespace they
+// are recorded for and are exported with the other versions of that
+// namespace. Cluster propagated versions are only exported for all
+// namespaces.
+func ExportVersions(ctx context.Context, c generic.Client, namespace string) ([]byte, error) {
+	export := &VersionExport{}
+
+	versionList := &fedv1a1.PropagatedVersionList{}
+	if err := c.List(ctx, versionList, namespace); err != nil {
+		return nil, errors.Wrap(err, "Failed to list PropagatedVersion resources")
+	}
+	for _, version := range versionList.Items {
+		export.PropagatedVersions = append(export.PropagatedVersions, fedv1a1.PropagatedVersion{
+			ObjectMeta: exportedObjectMeta(version.ObjectMeta),
+			Status:     version.Status,
+		})
+	}
+
+	if namespace == metav1.NamespaceAll {
+		clusterVersionList := &fedv1a1.ClusterPropagatedVersionList{}
+		if err := c.List(ctx, clusterVersionList, metav1.NamespaceAll); err != nil {
+			return nil, errors.Wrap(err, "Failed to list ClusterPropagatedVersion resources")
+		}
+		for _, version := range clusterVersionList.Items {
+			export.ClusterPropagatedVersions = append(export.ClusterPropagatedVersions, fedv1a1.ClusterPropagatedVersion{
+				ObjectMeta: exportedObjectMeta(version.ObjectMeta),
+				Status:     version.Status,
+			})
+		}
+	}
+
+	data, err := json.Marshal(export)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to serialize propagated versions")
+	}
+	return data, nil
+}
+
+// exportedObjectMeta retains the metadata of a version that is not
+// maintained by the API.
+func exportedObjectMeta(objectMeta metav1.ObjectMeta) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Namespace:       objectMeta.Namespace,
+		Name:            objectMeta.Name,
+		Labels:          objectMeta.Labels,
+		Annotations:     objectMeta.Annotations,
+		OwnerReferences: objectMeta.OwnerReferences,
+	}
+}
+
+// ImportVersions recreates the propagated versions serialized by
+// ExportVersions and returns the number of versions created. Since
+// federated resources restored from a backup are likely to have been
+// assigned a new uid, the owner references of each version are
+// updated with the uid of its current owner. A version whose owner no
+// longer exists is skipped since it would be garbage collected, and a
+// version that already exists is left unchanged since it was recorded
+// by a sync controller after the export.
+func ImportVersions(ctx context.Context, c generic.Client, data []byte) (int, error) {
+	export := &VersionExport{}
+	if err := json.Unmarshal(data, export); err != nil {
+		return 0, errors.Wrap(err, "Failed to deserialize propagated versions")
+	}
+
+	created := 0
+	importFunc := func(adapter Adapter, obj runtimeclient.Object) error {
+		ok, err := importVersion(ctx, c, adapter, obj)
+		if err != nil {
+			return err
+		}
+		if ok {
+			created++
+		}
+		return nil
+	}
+	namespacedAdapter := NewVersionAdapter(true)
+	for i := range export.PropagatedVersions {
+		if err := importFunc(namespacedAdapter, &export.PropagatedVersions[i]); err != nil {
+			return created, err
+		}
+	}
+	clusterAdapter := NewVersionAdapter(false)
+	for i := range export.ClusterPropagatedVersions {
+		if err := importFunc(clusterAdapter, &export.ClusterPropagatedVersions[i]); err != nil {
+			return created, err
+		}
+	}
+	return created, nil
+}
+
+// importVersion creates the given version and returns whether it was
+// created.
+func importVersion(ctx context.Context, c generic.Client, adapter Adapter, obj runtimeclient.Object) (bool, error) {
+	adapterType := adapter.TypeName()
+	qualifiedName := utils.NewQualifiedName(obj)
+
+	ownerReferences, ok, err := currentOwnerReferences(ctx, c, obj.GetNamespace(), obj.GetOwnerReferences())
+	if err != nil {
+		return false, errors.Wrapf(err, "Failed to resolve the owners of %s %q", adapterType, qualifiedName)
+	}
+	if !ok {
+		klog.V(2).Infof("Skipping import of %s %q since its owner no longer exists", adapterType, qualifiedName)
+		return false, nil
+	}
+	obj.SetOwnerReferences(ownerReferences)
+
+	// Status is a subresource and is not persisted on creation.
+	status := adapter.GetStatus(obj)
+	err = c.Create(ctx, obj)
+	if apierrors.IsAlreadyExists(err) {
+		klog.V(2).Infof("Skipping import of %s %q since it already exists", adapterType, qualifiedName)
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrapf(err, "Failed to create %s %q", adapterType, qualifiedName)
+	}
+	adapter.SetStatus(obj, status)
+	if err := c.UpdateStatus(ctx, obj); err != nil {
+		return false, errors.Wrapf(err, "Failed to update the status of %s %q", adapterType, qualifiedName)
+	}
+	return true, nil
+}
+
+// currentOwnerReferences returns the given owner references with the
+// uid of the owners that currently exist in the given namespace, and
+// whether all of the owners exist.
+func currentOwnerReferences(ctx context.Context, c generic.Client, namespace string, ownerReferences []metav1.OwnerReference) ([]metav1.OwnerReference, bool, error) {
+	var result []metav1.OwnerReference
+	for _, ownerReference := range ownerReferences {
+		owner := &unstructured.Unstructured{}
+		owner.SetAPIVersion(ownerReference.APIVersion)
+		owner.SetKind(ownerReference.Kind)
+		err := c.Get(ctx, owner, namespace, ownerReference.Name)
+		if apierrors.IsNotFound(err) {
+			return nil, false, nil
+		}
+		if err != nil {
+			return nil, false, errors.Wrapf(err, "Failed to retrieve %s %q", ownerReference.Kind, ownerReference.Name)
+		}
+		ownerReference.UID = owner.GetUID()
+		result = append(result, ownerReference)
+	}
+	return result, true, nil
+}
@@ -0,0 +1,183 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package version_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	fedv1a1 "sigs.k8s.io/kubefed/pkg/apis/core/v1alpha1"
+	"sigs.k8s.io/kubefed/pkg/client/generic"
+	"sigs.k8s.io/kubefed/pkg/controller/sync/version"
+	"sigs.k8s.io/kubefed/pkg/controller/utils"
+	"sigs.k8s.io/kubefed/test/common/fake"
+)
+
+type versionedResource struct {
+	qualifiedName utils.QualifiedName
+	// clusterOverrideVersions are the override versions of clusters,
+	// which are empty unless specified.
+	clusterOverrideVersions map[string]string
+	// failingCluster is the name of a cluster whose override version
+	// cannot be determined.
+	failingCluster string
+}
+
+func (r *versionedResource) FederatedName() utils.QualifiedName { return r.qualifiedName }
+func (r *versionedResource) Object() *unstructured.Unstructured { return nil }
+func (r *versionedResource) TemplateVersion() (string, error)   { return "t1", nil }
+func (r *versionedResource) OverrideVersion() (string, error)   { return "o1", nil }
+func (r *versionedResource) ClusterOverrideVersion(clusterName string) (string, error) {
+	if clusterName == r.failingCluster {
+		return "", errors.New("failed to evaluate overrides")
+	}
+	return r.clusterOverrideVersions[clusterName], nil
+}
+func (r *versionedResource) HasUnversionedOverrides() (bool, error) { return false, nil }
+
+func createOwner(t *testing.T, c generic.Client, kind, namespace, name string) metav1.OwnerReference {
+	owner := &unstructured.Unstructured{}
+	owner.SetAPIVersion("types.kubefed.io/v1beta1")
+	owner.SetKind(kind)
+	owner.SetNamespace(namespace)
+	owner.SetName(name)
+	require.NoError(t, c.Create(context.Background(), owner))
+	return metav1.OwnerReference{
+		APIVersion: owner.GetAPIVersion(),
+		Kind:       kind,
+		Name:       name,
+		UID:        owner.GetUID(),
+	}
+}
+
+func deleteOwner(t *testing.T, c generic.Client, kind, namespace, name string) {
+	owner := &unstructured.Unstructured{}
+	owner.SetAPIVersion("types.kubefed.io/v1beta1")
+	owner.SetKind(kind)
+	require.NoError(t, c.Delete(context.Background(), owner, namespace, name))
+}
+
+func createVersion(t *testing.T, c generic.Client, namespace, name string, owner metav1.OwnerReference, clusterVersions map[string]string) {
+	propagatedVersion := &fedv1a1.PropagatedVersion{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       namespace,
+			Name:            name,
+			OwnerReferences: []metav1.OwnerReference{owner},
+		},
+	}
+	require.NoError(t, c.Create(context.Background(), propagatedVersion))
+	propagatedVersion.Status = fedv1a1.PropagatedVersionStatus{
+		TemplateVersion: "t1",
+		OverrideVersion: "o1",
+		ClusterVersions: version.MapToClusterVersions(clusterVersions),
+	}
+	require.NoError(t, c.UpdateStatus(context.Background(), propagatedVersion))
+}
+
+func TestExportImportVersions(t *testing.T) {
+	ctx := context.Background()
+	c := fake.NewGenericClient(fake.NewStore())
+
+	configMapVersions := map[string]string{"cluster1": "1", "cluster2": "2"}
+	namespaceVersions := map[string]string{"cluster1": "3"}
+	owner := createOwner(t, c, "FederatedConfigMap", "ns1", "cm1")
+	createVersion(t, c, "ns1", "configmap-cm1", owner, configMapVersions)
+	owner = createOwner(t, c, "FederatedNamespace", "ns1", "ns1")
+	createVersion(t, c, "ns1", "namespace-ns1", owner, namespaceVersions)
+	owner = createOwner(t, c, "FederatedConfigMap", "ns1", "cm2")
+	createVersion(t, c, "ns1", "configmap-cm2", owner, configMapVersions)
+	owner = createOwner(t, c, "FederatedConfigMap", "ns2", "cm3")
+	createVersion(t, c, "ns2", "configmap-cm3", owner, configMapVersions)
+
+	data, err := version.ExportVersions(ctx, c, "ns1")
+	require.NoError(t, err)
+
+	// Simulate the loss of the control plane by deleting the versions
+	// and recreating their owners with a new uid, except for an owner
+	// that is not restored.
+	versionList := &fedv1a1.PropagatedVersionList{}
+	require.NoError(t, c.List(ctx, versionList, "ns1"))
+	require.Len(t, versionList.Items, 3)
+	for _, propagatedVersion := range versionList.Items {
+		require.NoError(t, c.Delete(ctx, &propagatedVersion, propagatedVersion.Namespace, propagatedVersion.Name))
+	}
+	deleteOwner(t, c, "FederatedConfigMap", "ns1", "cm1")
+	deleteOwner(t, c, "FederatedNamespace", "ns1", "ns1")
+	deleteOwner(t, c, "FederatedConfigMap", "ns1", "cm2")
+	configMapOwner := createOwner(t, c, "FederatedConfigMap", "ns1", "cm1")
+	namespaceOwner := createOwner(t, c, "FederatedNamespace", "ns1", "ns1")
+
+	created, err := version.ImportVersions(ctx, c, data)
+	require.NoError(t, err)
+	assert.Equal(t, 2, created, "Only the versions whose owner exists should be created")
+
+	importedVersion := &fedv1a1.PropagatedVersion{}
+	require.NoError(t, c.Get(ctx, importedVersion, "ns1", "configmap-cm1"))
+	assert.Equal(t, []metav1.OwnerReference{configMapOwner}, importedVersion.OwnerReferences,
+		"The owner reference should refer to the current owner")
+
+	created, err = version.ImportVersions(ctx, c, data)
+	require.NoError(t, err)
+	assert.Equal(t, 0, created, "Existing versions should not be recreated")
+
+	stopChan := make(chan struct{})
+	defer close(stopChan)
+	testCases := map[string]struct {
+		federatedKind string
+		targetKind    string
+		qualifiedName utils.QualifiedName
+		expected      map[string]string
+	}{
+		"namespaced resource": {
+			federatedKind: "FederatedConfigMap",
+			targetKind:    "ConfigMap",
+			qualifiedName: utils.QualifiedName{Namespace: "ns1", Name: configMapOwner.Name},
+			expected:      configMapVersions,
+		},
+		"namespace": {
+			federatedKind: "FederatedNamespace",
+			targetKind:    "Namespace",
+			qualifiedName: utils.QualifiedName{Namespace: "ns1", Name: namespaceOwner.Name},
+			expected:      namespaceVersions,
+		},
+		"resource whose owner was not restored": {
+			federatedKind: "FederatedConfigMap",
+			targetKind:    "ConfigMap",
+			qualifiedName: utils.QualifiedName{Namespace: "ns1", Name: "cm2"},
+			expected:      map[string]string{},
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			manager := version.NewVersionManager(ctx, false, c, true, tc.federatedKind, tc.targetKind, metav1.NamespaceAll)
+			manager.Sync(stopChan)
+			require.True(t, manager.HasSynced())
+
+			versionMap, err := manager.Get(&versionedResource{qualifiedName: tc.qualifiedName})
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, versionMap)
+		})
+	}
+}
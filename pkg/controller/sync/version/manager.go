@@ -47,6 +47,11 @@ type VersionedResource interface {
 	Object() *unstructured.Unstructured
 	TemplateVersion() (string, error)
 	OverrideVersion() (string, error)
+	ClusterOverrideVersion(clusterName string) (string, error)
+	// HasUnversionedOverrides indicates whether the overrides of the
+	// resource for a cluster can change without a change to its
+	// override version.
+	HasUnversionedOverrides() (bool, error)
 }
 
 // Manager is a structure that manages the synchronization and propagation of versions for different resources in a federated environment.
@@ -90,7 +95,7 @@ func NewVersionManager(ctx context.Context, immediate bool, c generic.Client, na
 // Sync retrieves propagated versions from the api and loads it into
 // memory.
 func (m *Manager) Sync(stopChan <-chan struct{}) {
-	versionList, ok := m.list(context.TODO())
+	versionList, ok := m.list(wait.ContextForChannel(stopChan))
 	if !ok {
 		return
 	}
@@ -118,28 +123,25 @@ func (m *Manager) Get(resource VersionedResource) (map[string]string, error) {
 	if !ok {
 		return versionMap, nil
 	}
-	status := m.adapter.GetStatus(obj)
-
-	templateVersion, err := resource.TemplateVersion()
-	if err != nil {
-		return nil, errors.Wrap(err, "Failed to determine template version")
-	}
-	overrideVersion, err := resource.OverrideVersion()
+	clusterVersions, err := validClusterVersions(resource, m.adapter.GetStatus(obj))
 	if err != nil {
-		return nil, errors.Wrap(err, "Failed to determine override version")
+		return nil, err
 	}
-	if templateVersion == status.TemplateVersion &&
-		overrideVersion == status.OverrideVersion {
-		for _, versions := range status.ClusterVersions {
-			versionMap[versions.ClusterName] = versions.Version
-		}
+	for _, clusterVersion := range clusterVersions {
+		versionMap[clusterVersion.ClusterName] = clusterVersion.Version
 	}
 
 	return versionMap, nil
 }
 
 // Update ensures that the propagated version for the given versioned
-// resource is recorded.
+// resource is recorded. Versions previously recorded for clusters that
+// are not selected are pruned to keep the propagated version compact.
+// Pruning is safe since placement is computed for all member clusters
+// regardless of their readiness: a cluster that is only transiently
+// unavailable, in maintenance or retained by the stickiness of
+// placement remains selected, and a resource is never updated based on
+// the version of a cluster it is being removed from.
 func (m *Manager) Update(resource VersionedResource,
 	selectedClusters []string, versionMap map[string]string) error {
 	templateVersion, err := resource.TemplateVersion()
@@ -150,6 +152,12 @@ func (m *Manager) Update(resource VersionedResource,
 	if err != nil {
 		return errors.Wrap(err, "Failed to determine override version")
 	}
+	unversionedOverrides, err := resource.HasUnversionedOverrides()
+	if err != nil {
+		// Assuming unversioned overrides only disables the check of
+		// the override version of the resource.
+		unversionedOverrides = true
+	}
 	qualifiedName := m.versionQualifiedName(resource.FederatedName())
 	key := qualifiedName.String()
 
@@ -161,19 +169,37 @@ func (m *Manager) Update(resource VersionedResource,
 	var clusterVersions []fedv1a1.ClusterObjectVersion
 	if ok {
 		oldStatus = m.adapter.GetStatus(obj)
-		// The existing versions are still valid if the template and override versions match.
-		if oldStatus.TemplateVersion == templateVersion && oldStatus.OverrideVersion == overrideVersion {
-			clusterVersions = oldStatus.ClusterVersions
+		clusterVersions, err = validClusterVersions(resource, oldStatus)
+		if err != nil {
+			m.Unlock()
+			return err
 		}
 		clusterVersions = updateClusterVersions(clusterVersions, versionMap, selectedClusters)
 	} else {
 		clusterVersions = MapToClusterVersions(versionMap)
 	}
+	// Record the overrides each version was produced with so that a
+	// change to the overrides of one cluster (e.g. for a cluster
+	// newly added to placement) does not invalidate the versions of
+	// the other clusters. The version of a cluster whose overrides
+	// cannot be hashed is not recorded, which only causes the
+	// resource in that cluster to be updated again.
+	recordedVersions := clusterVersions[:0]
+	for _, clusterVersion := range clusterVersions {
+		clusterVersion.OverrideVersion, err = resource.ClusterOverrideVersion(clusterVersion.ClusterName)
+		if err != nil {
+			runtime.HandleError(errors.Wrapf(err, "Failed to determine override version of %s %q for cluster %q", m.adapter.TypeName(), qualifiedName, clusterVersion.ClusterName))
+			continue
+		}
+		recordedVersions = append(recordedVersions, clusterVersion)
+	}
+	clusterVersions = recordedVersions
 
 	status := &fedv1a1.PropagatedVersionStatus{
-		TemplateVersion: templateVersion,
-		OverrideVersion: overrideVersion,
-		ClusterVersions: clusterVersions,
+		TemplateVersion:      templateVersion,
+		OverrideVersion:      overrideVersion,
+		ClusterVersions:      clusterVersions,
+		UnversionedOverrides: unversionedOverrides,
 	}
 
 	if oldStatus != nil && utils.PropagatedVersionStatusEquivalent(oldStatus, status) {
@@ -291,7 +317,7 @@ func (m *Manager) writeVersion(obj runtimeclient.Object, qualifiedName utils.Qua
 	refreshVersion := false
 	// TODO(marun) Centralize polling interval and duration
 	waitDuration := 30 * time.Second
-	err = wait.PollUntilContextTimeout(context.Background(), 100*time.Millisecond, waitDuration, true, func(ctx context.Context) (done bool, err error) {
+	err = wait.PollUntilContextTimeout(m.ctx, 100*time.Millisecond, waitDuration, true, func(ctx context.Context) (done bool, err error) {
 		if refreshVersion {
 			// Version was written to the API by another process after the last manager write.
 			resourceVersion, err = m.getResourceVersionFromAPI(qualifiedName)
@@ -313,7 +339,7 @@ func (m *Manager) writeVersion(obj runtimeclient.Object, qualifiedName utils.Qua
 			}
 
 			klog.V(4).Infof("Creating %s %q", adapterType, qualifiedName)
-			err = m.client.Create(context.TODO(), createdObj)
+			err = m.client.Create(ctx, createdObj)
 			if apierrors.IsAlreadyExists(err) {
 				klog.V(4).Infof("%s %q was created by another process. Will refresh the resourceVersion and attempt to update.", adapterType, qualifiedName)
 				refreshVersion = true
@@ -348,7 +374,7 @@ func (m *Manager) writeVersion(obj runtimeclient.Object, qualifiedName utils.Qua
 		}
 
 		klog.V(4).Infof("Updating the status of %s %q", adapterType, qualifiedName)
-		err = m.client.UpdateStatus(context.TODO(), updatedObj)
+		err = m.client.UpdateStatus(ctx, updatedObj)
 		if apierrors.IsConflict(err) {
 			klog.V(4).Infof("%s %q was updated by another process. Will refresh the resourceVersion and retry the update.", adapterType, qualifiedName)
 			refreshVersion = true
@@ -396,7 +422,7 @@ func (m *Manager) writeVersion(obj runtimeclient.Object, qualifiedName utils.Qua
 func (m *Manager) getResourceVersionFromAPI(qualifiedName utils.QualifiedName) (string, error) {
 	klog.V(4).Infof("Retrieving resourceVersion for %s %q from the API", m.federatedKind, qualifiedName)
 	obj := m.adapter.NewObject()
-	err := m.client.Get(context.TODO(), obj, qualifiedName.Namespace, qualifiedName.Name)
+	err := m.client.Get(m.ctx, obj, qualifiedName.Namespace, qualifiedName.Name)
 	if err != nil {
 		return "", err
 	}
@@ -430,9 +456,61 @@ func ownerReferenceForUnstructured(obj *unstructured.Unstructured) metav1.OwnerR
 	}
 }
 
+// validClusterVersions returns the cluster versions of the given
+// status that are still valid for the resource. Versions are
+// invalidated by a change to the template. A change to the overrides
+// only invalidates the versions of the clusters whose overrides
+// changed, or all versions if they were recorded without the version
+// of the overrides for their cluster. All versions remain valid if
+// the override version is unchanged, unless the overrides for a
+// cluster can change without a change to the override version (e.g.
+// because they are cluster-local) either now or when the versions
+// were recorded. In that case the overrides of each cluster are
+// compared. The version of a cluster whose overrides cannot be hashed
+// is considered invalid without affecting the versions of other
+// clusters.
+func validClusterVersions(resource VersionedResource, status *fedv1a1.PropagatedVersionStatus) ([]fedv1a1.ClusterObjectVersion, error) {
+	templateVersion, err := resource.TemplateVersion()
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to determine template version")
+	}
+	if templateVersion != status.TemplateVersion {
+		return nil, nil
+	}
+	overrideVersion, err := resource.OverrideVersion()
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to determine override version")
+	}
+	overridesUnchanged := overrideVersion == status.OverrideVersion
+	if overridesUnchanged && !status.UnversionedOverrides {
+		if unversionedOverrides, err := resource.HasUnversionedOverrides(); err == nil && !unversionedOverrides {
+			return status.ClusterVersions, nil
+		}
+	}
+	var clusterVersions []fedv1a1.ClusterObjectVersion
+	for _, clusterVersion := range status.ClusterVersions {
+		if len(clusterVersion.OverrideVersion) == 0 {
+			if overridesUnchanged {
+				clusterVersions = append(clusterVersions, clusterVersion)
+			}
+			continue
+		}
+		clusterOverrideVersion, err := resource.ClusterOverrideVersion(clusterVersion.ClusterName)
+		if err != nil {
+			runtime.HandleError(errors.Wrapf(err, "Failed to determine override version of %q for cluster %q", resource.FederatedName(), clusterVersion.ClusterName))
+			continue
+		}
+		if clusterOverrideVersion == clusterVersion.OverrideVersion {
+			clusterVersions = append(clusterVersions, clusterVersion)
+		}
+	}
+	return clusterVersions, nil
+}
+
 func updateClusterVersions(oldVersions []fedv1a1.ClusterObjectVersion,
 	newVersions map[string]string, selectedClusters []string) []fedv1a1.ClusterObjectVersion {
-	// Retain versions for selected clusters that were not changed
+	// Retain versions for selected clusters that were not changed,
+	// pruning those of clusters that are no longer selected.
 	selectedClusterSet := sets.NewString(selectedClusters...)
 	for _, oldVersion := range oldVersions {
 		if !selectedClusterSet.Has(oldVersion.ClusterName) {
@@ -0,0 +1,166 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package version
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	fedv1a1 "sigs.k8s.io/kubefed/pkg/apis/core/v1alpha1"
+	"sigs.k8s.io/kubefed/pkg/controller/utils"
+)
+
+type fakeVersionedResource struct {
+	templateVersion         string
+	overrideVersion         string
+	clusterOverrideVersions map[string]string
+	failingCluster          string
+	unversionedOverrides    bool
+}
+
+func (r *fakeVersionedResource) FederatedName() utils.QualifiedName { return utils.QualifiedName{} }
+func (r *fakeVersionedResource) Object() *unstructured.Unstructured { return nil }
+func (r *fakeVersionedResource) TemplateVersion() (string, error)   { return r.templateVersion, nil }
+func (r *fakeVersionedResource) OverrideVersion() (string, error)   { return r.overrideVersion, nil }
+func (r *fakeVersionedResource) ClusterOverrideVersion(clusterName string) (string, error) {
+	if clusterName == r.failingCluster {
+		return "", errors.New("failed to evaluate overrides")
+	}
+	return r.clusterOverrideVersions[clusterName], nil
+}
+func (r *fakeVersionedResource) HasUnversionedOverrides() (bool, error) {
+	return r.unversionedOverrides, nil
+}
+
+func TestValidClusterVersions(t *testing.T) {
+	status := &fedv1a1.PropagatedVersionStatus{
+		TemplateVersion: "t1",
+		OverrideVersion: "o1",
+		ClusterVersions: []fedv1a1.ClusterObjectVersion{
+			{ClusterName: "cluster1", Version: "1", OverrideVersion: "c1"},
+			{ClusterName: "cluster2", Version: "2", OverrideVersion: "c2"},
+			{ClusterName: "cluster3", Version: "3"},
+		},
+	}
+	testCases := map[string]struct {
+		resource *fakeVersionedResource
+		// recordedUnversionedOverrides indicates whether the versions
+		// were recorded for a resource with unversioned overrides.
+		recordedUnversionedOverrides bool
+		expected                     []fedv1a1.ClusterObjectVersion
+	}{
+		"unchanged": {
+			resource: &fakeVersionedResource{
+				templateVersion: "t1",
+				overrideVersion: "o1",
+				clusterOverrideVersions: map[string]string{
+					"cluster1": "c1",
+					"cluster2": "c2",
+				},
+			},
+			expected: status.ClusterVersions,
+		},
+		"overrides of clusters are not hashed if the override version is unchanged": {
+			resource: &fakeVersionedResource{
+				templateVersion: "t1",
+				overrideVersion: "o1",
+				failingCluster:  "cluster1",
+			},
+			expected: status.ClusterVersions,
+		},
+		"template changed": {
+			resource: &fakeVersionedResource{templateVersion: "t2", overrideVersion: "o1"},
+		},
+		"overrides of another cluster changed": {
+			resource: &fakeVersionedResource{
+				templateVersion: "t1",
+				overrideVersion: "o2",
+				clusterOverrideVersions: map[string]string{
+					"cluster1": "c1",
+					"cluster2": "c2",
+					"cluster4": "c4",
+				},
+			},
+			expected: status.ClusterVersions[:2],
+		},
+		"overrides of a cluster changed": {
+			resource: &fakeVersionedResource{
+				templateVersion: "t1",
+				overrideVersion: "o2",
+				clusterOverrideVersions: map[string]string{
+					"cluster1": "c1",
+					"cluster2": "c2-changed",
+				},
+			},
+			expected: status.ClusterVersions[:1],
+		},
+		"cluster-local overrides of a cluster changed": {
+			resource: &fakeVersionedResource{
+				templateVersion: "t1",
+				overrideVersion: "o1",
+				clusterOverrideVersions: map[string]string{
+					"cluster1": "c1-changed",
+					"cluster2": "c2",
+				},
+				unversionedOverrides: true,
+			},
+			recordedUnversionedOverrides: true,
+			expected:                     status.ClusterVersions[1:],
+		},
+		"cluster-local overrides of a cluster removed": {
+			resource: &fakeVersionedResource{
+				templateVersion: "t1",
+				overrideVersion: "o1",
+				clusterOverrideVersions: map[string]string{
+					"cluster1": "c1-changed",
+					"cluster2": "c2",
+				},
+			},
+			recordedUnversionedOverrides: true,
+			expected:                     status.ClusterVersions[1:],
+		},
+		"overrides of a cluster cannot be hashed": {
+			resource: &fakeVersionedResource{
+				templateVersion: "t1",
+				overrideVersion: "o1",
+				clusterOverrideVersions: map[string]string{
+					"cluster2": "c2",
+				},
+				failingCluster:       "cluster1",
+				unversionedOverrides: true,
+			},
+			expected: status.ClusterVersions[1:],
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			status := status.DeepCopy()
+			status.UnversionedOverrides = tc.recordedUnversionedOverrides
+			clusterVersions, err := validClusterVersions(tc.resource, status)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(tc.expected, clusterVersions) {
+				t.Fatalf("Expected %v, got %v", tc.expected, clusterVersions)
+			}
+		})
+	}
+}
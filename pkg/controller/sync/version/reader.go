@@ -0,0 +1,90 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package version
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+
+	"sigs.k8s.io/kubefed/pkg/apis/core/common"
+	fedv1a1 "sigs.k8s.io/kubefed/pkg/apis/core/v1alpha1"
+	"sigs.k8s.io/kubefed/pkg/client/generic"
+	"sigs.k8s.io/kubefed/pkg/controller/utils"
+)
+
+// Reader provides lookup of the propagated versions of many
+// federated resources of a type from a single list request, avoiding
+// a request per resource when validating resources in bulk.
+type Reader struct {
+	// versions is keyed by the qualified name of the federated
+	// resource a version was recorded for.
+	versions map[string]*fedv1a1.PropagatedVersionStatus
+}
+
+// NewReader lists the propagated versions for the given target kind
+// in the given namespace (all namespaces if empty) and indexes them by
+// the name of the federated resource they were recorded for. List
+// options like a label selector may be used to further limit the
+// versions that are retrieved.
+func NewReader(ctx context.Context, c generic.Client, adapter Adapter, targetKind, namespace string, opts ...runtimeclient.ListOption) (*Reader, error) {
+	versionList := adapter.NewListObject()
+	if err := c.List(ctx, versionList, namespace, opts...); err != nil {
+		return nil, errors.Wrapf(err, "Failed to list %s resources", adapter.TypeName())
+	}
+	items, err := meta.ExtractList(versionList)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to understand list result for %q", adapter.TypeName())
+	}
+
+	typePrefix := common.PropagatedVersionPrefix(targetKind)
+	r := &Reader{
+		versions: make(map[string]*fedv1a1.PropagatedVersionStatus, len(items)),
+	}
+	for _, item := range items {
+		obj := item.(runtimeclient.Object)
+		// Ignore propagated versions for other types
+		if !strings.HasPrefix(obj.GetName(), typePrefix) {
+			continue
+		}
+		// Versions are stored in the namespace of the federated
+		// resource with a name derived from the name of the
+		// federated resource.
+		qualifiedName := utils.QualifiedName{
+			Namespace: obj.GetNamespace(),
+			Name:      strings.TrimPrefix(obj.GetName(), typePrefix),
+		}
+		r.versions[qualifiedName.String()] = adapter.GetStatus(obj)
+	}
+	return r, nil
+}
+
+// Get returns the propagated version status recorded for the named
+// federated resource.
+func (r *Reader) Get(qualifiedName utils.QualifiedName) (*fedv1a1.PropagatedVersionStatus, bool) {
+	status, ok := r.versions[qualifiedName.String()]
+	return status, ok
+}
+
+// Len returns the number of indexed propagated versions.
+func (r *Reader) Len() int {
+	return len(r.versions)
+}
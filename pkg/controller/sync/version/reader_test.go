@@ -0,0 +1,125 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package version
+
+import (
+	"context"
+	"testing"
+
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/kubefed/pkg/apis/core/common"
+	fedv1a1 "sigs.k8s.io/kubefed/pkg/apis/core/v1alpha1"
+	"sigs.k8s.io/kubefed/pkg/client/generic"
+	"sigs.k8s.io/kubefed/pkg/controller/utils"
+)
+
+// listClient is a generic client that only supports listing
+// propagated versions.
+type listClient struct {
+	generic.Client
+
+	versions []fedv1a1.PropagatedVersion
+}
+
+func (c *listClient) List(ctx context.Context, obj runtimeclient.ObjectList, namespace string, opts ...runtimeclient.ListOption) error {
+	list := obj.(*fedv1a1.PropagatedVersionList)
+	for _, version := range c.versions {
+		if namespace == "" || version.Namespace == namespace {
+			list.Items = append(list.Items, version)
+		}
+	}
+	return nil
+}
+
+func newPropagatedVersion(namespace, name, templateVersion string) fedv1a1.PropagatedVersion {
+	return fedv1a1.PropagatedVersion{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+		},
+		Status: fedv1a1.PropagatedVersionStatus{
+			TemplateVersion: templateVersion,
+		},
+	}
+}
+
+func TestReader(t *testing.T) {
+	client := &listClient{
+		versions: []fedv1a1.PropagatedVersion{
+			newPropagatedVersion("ns1", common.PropagatedVersionName("ConfigMap", "foo"), "ns1-foo"),
+			newPropagatedVersion("ns2", common.PropagatedVersionName("ConfigMap", "foo"), "ns2-foo"),
+			newPropagatedVersion("ns1", common.PropagatedVersionName("ConfigMap", "bar"), "ns1-bar"),
+			newPropagatedVersion("ns1", common.PropagatedVersionName("Secret", "foo"), "secret"),
+			// The version of a federated namespace is stored in the
+			// namespace itself.
+			newPropagatedVersion("ns1", common.PropagatedVersionName("Namespace", "ns1"), "namespace-ns1"),
+		},
+	}
+	adapter := NewVersionAdapter(true)
+
+	testCases := map[string]struct {
+		targetKind       string
+		namespace        string
+		expectedVersions map[utils.QualifiedName]string
+	}{
+		"versions in all namespaces are indexed by federated name": {
+			targetKind: "ConfigMap",
+			expectedVersions: map[utils.QualifiedName]string{
+				{Namespace: "ns1", Name: "foo"}: "ns1-foo",
+				{Namespace: "ns2", Name: "foo"}: "ns2-foo",
+				{Namespace: "ns1", Name: "bar"}: "ns1-bar",
+			},
+		},
+		"versions are limited to the given namespace": {
+			targetKind: "ConfigMap",
+			namespace:  "ns2",
+			expectedVersions: map[utils.QualifiedName]string{
+				{Namespace: "ns2", Name: "foo"}: "ns2-foo",
+			},
+		},
+		"namespace versions are indexed by the namespace name": {
+			targetKind: "Namespace",
+			expectedVersions: map[utils.QualifiedName]string{
+				{Namespace: "ns1", Name: "ns1"}: "namespace-ns1",
+			},
+		},
+	}
+
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			reader, err := NewReader(context.Background(), client, adapter, tc.targetKind, tc.namespace)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if reader.Len() != len(tc.expectedVersions) {
+				t.Fatalf("Expected %d versions, got %d", len(tc.expectedVersions), reader.Len())
+			}
+			for qualifiedName, expectedVersion := range tc.expectedVersions {
+				status, ok := reader.Get(qualifiedName)
+				if !ok {
+					t.Fatalf("Expected a version for %q", qualifiedName)
+				}
+				if status.TemplateVersion != expectedVersion {
+					t.Errorf("Expected template version %q for %q, got %q", expectedVersion, qualifiedName, status.TemplateVersion)
+				}
+			}
+		})
+	}
+}
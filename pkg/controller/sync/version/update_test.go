@@ -0,0 +1,116 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package version_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fedv1a1 "sigs.k8s.io/kubefed/pkg/apis/core/v1alpha1"
+	"sigs.k8s.io/kubefed/pkg/controller/sync/version"
+	"sigs.k8s.io/kubefed/pkg/controller/utils"
+	"sigs.k8s.io/kubefed/test/common/fake"
+)
+
+func TestUpdatePrunesVersionsOfDeplacedClusters(t *testing.T) {
+	ctx := context.Background()
+	c := fake.NewGenericClient(fake.NewStore())
+
+	// The version carries an entry for cluster3, which has since been
+	// removed from placement.
+	owner := createOwner(t, c, "FederatedConfigMap", "ns1", "cm1")
+	createVersion(t, c, "ns1", "configmap-cm1", owner, map[string]string{"cluster1": "1", "cluster2": "2", "cluster3": "3"})
+
+	stopChan := make(chan struct{})
+	defer close(stopChan)
+	manager := version.NewVersionManager(ctx, false, c, true, "FederatedConfigMap", "ConfigMap", metav1.NamespaceAll)
+	manager.Sync(stopChan)
+	require.True(t, manager.HasSynced())
+
+	resource := &versionedResource{qualifiedName: utils.QualifiedName{Namespace: "ns1", Name: "cm1"}}
+	require.NoError(t, manager.Update(resource, []string{"cluster1", "cluster2"}, map[string]string{"cluster1": "4"}))
+
+	expected := map[string]string{"cluster1": "4", "cluster2": "2"}
+	versionMap, err := manager.Get(resource)
+	require.NoError(t, err)
+	assert.Equal(t, expected, versionMap)
+
+	propagatedVersion := &fedv1a1.PropagatedVersion{}
+	require.NoError(t, c.Get(ctx, propagatedVersion, "ns1", "configmap-cm1"))
+	assert.Equal(t, version.MapToClusterVersions(expected), propagatedVersion.Status.ClusterVersions,
+		"The version of the deplaced cluster should be pruned from the API")
+}
+
+func TestUpdateRetainsVersionsOfOtherClustersOnPlacementExpansion(t *testing.T) {
+	ctx := context.Background()
+	c := fake.NewGenericClient(fake.NewStore())
+	owner := createOwner(t, c, "FederatedConfigMap", "ns1", "cm1")
+	createVersion(t, c, "ns1", "configmap-cm1", owner, nil)
+
+	stopChan := make(chan struct{})
+	defer close(stopChan)
+	manager := version.NewVersionManager(ctx, false, c, true, "FederatedConfigMap", "ConfigMap", metav1.NamespaceAll)
+	manager.Sync(stopChan)
+	require.True(t, manager.HasSynced())
+
+	resource := &versionedResource{
+		qualifiedName:           utils.QualifiedName{Namespace: "ns1", Name: "cm1"},
+		clusterOverrideVersions: map[string]string{"cluster1": "c1"},
+	}
+	require.NoError(t, manager.Update(resource, []string{"cluster1"}, map[string]string{"cluster1": "1"}))
+
+	// Adding cluster2 to placement together with an override for it
+	// leaves the version of cluster1 valid.
+	resource.clusterOverrideVersions = map[string]string{"cluster1": "c1", "cluster2": "c2"}
+	versionMap, err := manager.Get(resource)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"cluster1": "1"}, versionMap)
+}
+
+func TestUpdateSkipsClusterWhoseOverrideVersionFails(t *testing.T) {
+	ctx := context.Background()
+	c := fake.NewGenericClient(fake.NewStore())
+	owner := createOwner(t, c, "FederatedConfigMap", "ns1", "cm1")
+	createVersion(t, c, "ns1", "configmap-cm1", owner, nil)
+
+	stopChan := make(chan struct{})
+	defer close(stopChan)
+	manager := version.NewVersionManager(ctx, false, c, true, "FederatedConfigMap", "ConfigMap", metav1.NamespaceAll)
+	manager.Sync(stopChan)
+	require.True(t, manager.HasSynced())
+
+	resource := &versionedResource{
+		qualifiedName:  utils.QualifiedName{Namespace: "ns1", Name: "cm1"},
+		failingCluster: "cluster2",
+	}
+	require.NoError(t, manager.Update(resource, []string{"cluster1", "cluster2"}, map[string]string{"cluster1": "1", "cluster2": "2"}))
+
+	expected := map[string]string{"cluster1": "1"}
+	versionMap, err := manager.Get(resource)
+	require.NoError(t, err)
+	assert.Equal(t, expected, versionMap)
+
+	propagatedVersion := &fedv1a1.PropagatedVersion{}
+	require.NoError(t, c.Get(ctx, propagatedVersion, "ns1", "configmap-cm1"))
+	assert.Equal(t, version.MapToClusterVersions(expected), propagatedVersion.Status.ClusterVersions,
+		"Only the version of the failing cluster should be omitted")
+}
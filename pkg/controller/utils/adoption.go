@@ -0,0 +1,50 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fedv1b1 "sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
+)
+
+// CheckAdoption returns an error describing why the given
+// pre-existing resource in a member cluster may not be adopted
+// according to the given policy, or nil if it may be adopted. All
+// resources may be adopted if the policy is nil.
+func CheckAdoption(policy *fedv1b1.ResourceAdoptionPolicy, obj metav1.Object) error {
+	if policy == nil {
+		return nil
+	}
+	if len(policy.NamePrefix) > 0 && !strings.HasPrefix(obj.GetName(), policy.NamePrefix) {
+		return errors.Errorf("the name of the resource does not have the prefix %q required for adoption", policy.NamePrefix)
+	}
+	if len(policy.RequiredAnnotation) > 0 {
+		value, ok := obj.GetAnnotations()[policy.RequiredAnnotation]
+		if !ok {
+			return errors.Errorf("the resource does not have the annotation %q required for adoption", policy.RequiredAnnotation)
+		}
+		if len(policy.RequiredAnnotationValue) > 0 && value != policy.RequiredAnnotationValue {
+			return errors.Errorf("the annotation %q of the resource does not have the value %q required for adoption", policy.RequiredAnnotation, policy.RequiredAnnotationValue)
+		}
+	}
+	return nil
+}
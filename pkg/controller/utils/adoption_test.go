@@ -0,0 +1,86 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fedv1b1 "sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
+)
+
+func TestCheckAdoption(t *testing.T) {
+	testCases := map[string]struct {
+		policy        *fedv1b1.ResourceAdoptionPolicy
+		name          string
+		annotations   map[string]string
+		expectAllowed bool
+	}{
+		"allowed without policy": {
+			name:          "foo",
+			expectAllowed: true,
+		},
+		"allowed with matching name prefix": {
+			policy:        &fedv1b1.ResourceAdoptionPolicy{NamePrefix: "team-a-"},
+			name:          "team-a-foo",
+			expectAllowed: true,
+		},
+		"refused without matching name prefix": {
+			policy: &fedv1b1.ResourceAdoptionPolicy{NamePrefix: "team-a-"},
+			name:   "team-b-foo",
+		},
+		"allowed with required annotation of any value": {
+			policy:        &fedv1b1.ResourceAdoptionPolicy{RequiredAnnotation: "example.io/adoptable"},
+			name:          "foo",
+			annotations:   map[string]string{"example.io/adoptable": ""},
+			expectAllowed: true,
+		},
+		"refused without required annotation": {
+			policy: &fedv1b1.ResourceAdoptionPolicy{RequiredAnnotation: "example.io/adoptable"},
+			name:   "foo",
+		},
+		"allowed with required annotation value": {
+			policy:        &fedv1b1.ResourceAdoptionPolicy{RequiredAnnotation: "example.io/owner", RequiredAnnotationValue: "kubefed"},
+			name:          "foo",
+			annotations:   map[string]string{"example.io/owner": "kubefed"},
+			expectAllowed: true,
+		},
+		"refused with other annotation value": {
+			policy:      &fedv1b1.ResourceAdoptionPolicy{RequiredAnnotation: "example.io/owner", RequiredAnnotationValue: "kubefed"},
+			name:        "foo",
+			annotations: map[string]string{"example.io/owner": "argocd"},
+		},
+		"refused unless all criteria are satisfied": {
+			policy:      &fedv1b1.ResourceAdoptionPolicy{RequiredAnnotation: "example.io/adoptable", NamePrefix: "team-a-"},
+			name:        "team-b-foo",
+			annotations: map[string]string{"example.io/adoptable": "true"},
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			obj := &metav1.ObjectMeta{Name: tc.name, Annotations: tc.annotations}
+			err := CheckAdoption(tc.policy, obj)
+			if tc.expectAllowed && err != nil {
+				t.Fatalf("Expected adoption to be allowed, got: %v", err)
+			}
+			if !tc.expectAllowed && err == nil {
+				t.Fatalf("Expected adoption to be refused")
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"github.com/pkg/errors"
+
+	apiv1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// IsPermanentApplyError checks whether the given error, returned by a
+// member cluster for a create or update, indicates that the cluster
+// rejected the content of the resource such that retrying the same
+// request cannot succeed. Forbidden errors are only permanent if their
+// causes name fields of the resource.
+func IsPermanentApplyError(err error) bool {
+	switch {
+	case apierrors.IsForbidden(err):
+		return hasFieldCause(err)
+	case apierrors.IsInvalid(err),
+		apierrors.IsBadRequest(err),
+		apierrors.IsMethodNotSupported(err),
+		apierrors.IsNotAcceptable(err),
+		apierrors.IsUnsupportedMediaType(err),
+		apierrors.IsRequestEntityTooLargeError(err):
+		return true
+	}
+	return false
+}
+
+// IsForbiddenApplyError checks whether the given error, returned by a
+// member cluster for a create or update, indicates that the request was
+// forbidden for a reason other than the content of the resource, e.g.
+// because the identity of the cluster lacks authorization or because
+// the quota of the namespace is exhausted. Such requests may succeed
+// once the cluster is reconfigured, but not before. Forbidden errors
+// returned for namespaces being terminated are transient.
+func IsForbiddenApplyError(err error) bool {
+	return apierrors.IsForbidden(err) &&
+		!hasFieldCause(err) &&
+		!apierrors.HasStatusCause(err, apiv1.NamespaceTerminatingCause)
+}
+
+// hasFieldCause checks whether the status of the given error has a
+// cause that names a field of the resource.
+func hasFieldCause(err error) bool {
+	var status apierrors.APIStatus
+	if !errors.As(err, &status) || status.Status().Details == nil {
+		return false
+	}
+	for _, cause := range status.Status().Details.Causes {
+		if len(cause.Field) > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+
+	apiv1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func TestClassifyApplyError(t *testing.T) {
+	resource := schema.GroupResource{Resource: "configmaps"}
+	terminating := apierrors.NewForbidden(resource, "name", errors.New("namespace ns is being terminated"))
+	terminating.ErrStatus.Details.Causes = []metav1.StatusCause{{Type: apiv1.NamespaceTerminatingCause}}
+
+	forbiddenField := apierrors.NewForbidden(resource, "name", errors.New("privileged containers are not allowed"))
+	forbiddenField.ErrStatus.Details.Causes = []metav1.StatusCause{{Type: metav1.CauseType(field.ErrorTypeForbidden), Field: "spec.containers[0].securityContext.privileged"}}
+
+	testCases := map[string]struct {
+		err       error
+		permanent bool
+		forbidden bool
+	}{
+		"forbidden": {
+			err:       apierrors.NewForbidden(resource, "name", errors.New("cannot create resource")),
+			forbidden: true,
+		},
+		"forbidden value of a field": {
+			err:       forbiddenField,
+			permanent: true,
+		},
+		"invalid": {
+			err:       apierrors.NewInvalid(schema.GroupKind{Kind: "ConfigMap"}, "name", field.ErrorList{field.Required(field.NewPath("data"), "")}),
+			permanent: true,
+		},
+		"bad request": {
+			err:       apierrors.NewBadRequest("malformed"),
+			permanent: true,
+		},
+		"wrapped forbidden value of a field": {
+			err:       errors.Wrap(forbiddenField, "failed to create"),
+			permanent: true,
+		},
+		"wrapped forbidden": {
+			err:       errors.Wrap(apierrors.NewForbidden(resource, "name", errors.New("cannot create resource")), "failed to create"),
+			forbidden: true,
+		},
+		"forbidden by exceeded quota": {
+			err:       apierrors.NewForbidden(resource, "name", errors.New("exceeded quota: compute, requested: pods=1")),
+			forbidden: true,
+		},
+		"forbidden in terminating namespace": {
+			err: terminating,
+		},
+		"conflict": {
+			err: apierrors.NewConflict(resource, "name", errors.New("modified")),
+		},
+		"timeout": {
+			err: apierrors.NewServerTimeout(resource, "create", 1),
+		},
+		"unauthorized": {
+			err: apierrors.NewUnauthorized("expired"),
+		},
+		"other error": {
+			err: errors.New("connection refused"),
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			if permanent := IsPermanentApplyError(tc.err); permanent != tc.permanent {
+				t.Fatalf("Expected permanent to be %v, got %v", tc.permanent, permanent)
+			}
+			if forbidden := IsForbiddenApplyError(tc.err); forbidden != tc.forbidden {
+				t.Fatalf("Expected forbidden to be %v, got %v", tc.forbidden, forbidden)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// ApplyOrder is the order in which resources are created in a member
+// cluster, by kind.
+type ApplyOrder []string
+
+// Rank returns the position of the given kind in the order. Kinds
+// that are not in the order are ranked after all kinds that are.
+func (o ApplyOrder) Rank(kind string) int {
+	for i, orderedKind := range o {
+		if orderedKind == kind {
+			return i
+		}
+	}
+	return len(o)
+}
+
+// PendingCreationsFunc returns the names of the resources in the given
+// namespace that are placed to the named cluster but have yet to be
+// created there.
+type PendingCreationsFunc func(clusterName, namespace string) ([]string, error)
+
+type applyOrderRegistration struct {
+	kind             string
+	pendingCreations PendingCreationsFunc
+}
+
+// ApplyOrderGate defers the creation of a resource in a member cluster
+// until the resources of the kinds that precede its kind in the apply
+// order have been created there. Resources are ordered among those in
+// the same namespace, which includes the namespace itself, and
+// cluster-scoped resources among themselves. A nil gate defers no
+// creation.
+type ApplyOrderGate struct {
+	order ApplyOrder
+
+	lock          sync.RWMutex
+	registrations map[string]applyOrderRegistration
+}
+
+// NewApplyOrderGate returns a gate for the given apply order.
+func NewApplyOrderGate(order ApplyOrder) *ApplyOrderGate {
+	return &ApplyOrderGate{
+		order:         order,
+		registrations: make(map[string]applyOrderRegistration),
+	}
+}
+
+// Register registers the given function for the resources of the
+// given kind under the given unique name, and returns a function that
+// removes the registration.
+func (g *ApplyOrderGate) Register(name, kind string, pendingCreations PendingCreationsFunc) func() {
+	if g == nil {
+		return func() {}
+	}
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	g.registrations[name] = applyOrderRegistration{kind: kind, pendingCreations: pendingCreations}
+	return func() {
+		g.lock.Lock()
+		defer g.lock.Unlock()
+		delete(g.registrations, name)
+	}
+}
+
+// CheckCreation returns an error if a resource of the given kind in
+// the given namespace cannot yet be created in the named cluster
+// because resources of kinds that precede it in the apply order have
+// yet to be created there.
+func (g *ApplyOrderGate) CheckCreation(kind, clusterName, namespace string) error {
+	if g == nil {
+		return nil
+	}
+	rank := g.order.Rank(kind)
+	var preceding []applyOrderRegistration
+	g.lock.RLock()
+	for _, registration := range g.registrations {
+		if g.order.Rank(registration.kind) < rank {
+			preceding = append(preceding, registration)
+		}
+	}
+	g.lock.RUnlock()
+	sort.Slice(preceding, func(i, j int) bool {
+		return g.order.Rank(preceding[i].kind) < g.order.Rank(preceding[j].kind)
+	})
+
+	for _, registration := range preceding {
+		names, err := registration.pendingCreations(clusterName, namespace)
+		if err != nil {
+			return errors.Wrapf(err, "Failed to determine the %s resources yet to be created in cluster %q", registration.kind, clusterName)
+		}
+		if len(names) > 0 {
+			names = append([]string{}, names...)
+			sort.Strings(names)
+			return errors.Errorf("Waiting for the creation of %s %s in cluster %q, which precede %s in the apply order", registration.kind, strings.Join(names, ", "), clusterName, kind)
+		}
+	}
+	return nil
+}
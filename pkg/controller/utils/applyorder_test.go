@@ -0,0 +1,124 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestApplyOrderGate(t *testing.T) {
+	gate := NewApplyOrderGate(ApplyOrder{"Namespace", "ServiceAccount", "ConfigMap", "Deployment"})
+
+	// Resources yet to be created, by kind and then cluster and
+	// namespace.
+	pending := map[string]map[string][]string{}
+	register := func(kind string) func() {
+		return gate.Register(strings.ToLower(kind)+"s", kind, func(clusterName, namespace string) ([]string, error) {
+			if kind == "Namespace" && clusterName == "broken" {
+				return nil, errors.New("not synced")
+			}
+			return pending[kind][clusterName+"/"+namespace], nil
+		})
+	}
+	for _, kind := range []string{"Namespace", "ServiceAccount", "Deployment", "Widget"} {
+		register(kind)
+	}
+	unregisterConfigMaps := register("ConfigMap")
+	pending["Namespace"] = map[string][]string{"cluster1/foo": {"foo"}}
+	pending["ServiceAccount"] = map[string][]string{"cluster2/foo": {"foo/a"}, "cluster3/foo": {"foo/b", "foo/a"}}
+	pending["ConfigMap"] = map[string][]string{"cluster2/foo": {"foo/c"}}
+
+	testCases := map[string]struct {
+		kind          string
+		clusterName   string
+		namespace     string
+		expectedError string
+	}{
+		"first kind is never deferred": {
+			kind:        "Namespace",
+			clusterName: "cluster1",
+			namespace:   "foo",
+		},
+		"creation waits for the first preceding kind": {
+			kind:          "Deployment",
+			clusterName:   "cluster1",
+			namespace:     "foo",
+			expectedError: `Waiting for the creation of Namespace foo in cluster "cluster1"`,
+		},
+		"pending resources are listed in order": {
+			kind:          "ConfigMap",
+			clusterName:   "cluster3",
+			namespace:     "foo",
+			expectedError: `Waiting for the creation of ServiceAccount foo/a, foo/b in cluster "cluster3"`,
+		},
+		"resources of the same kind are not ordered": {
+			kind:        "ServiceAccount",
+			clusterName: "cluster2",
+			namespace:   "foo",
+		},
+		"resources in other namespaces do not defer creation": {
+			kind:        "Deployment",
+			clusterName: "cluster1",
+			namespace:   "bar",
+		},
+		"unordered kinds wait for all ordered kinds": {
+			kind:          "Widget",
+			clusterName:   "cluster2",
+			namespace:     "foo",
+			expectedError: `Waiting for the creation of ServiceAccount foo/a in cluster "cluster2"`,
+		},
+		"creation waits if pending resources cannot be determined": {
+			kind:          "ConfigMap",
+			clusterName:   "broken",
+			namespace:     "foo",
+			expectedError: "Failed to determine the Namespace resources yet to be created",
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			err := gate.CheckCreation(tc.kind, tc.clusterName, tc.namespace)
+			if len(tc.expectedError) == 0 {
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.expectedError) {
+				t.Fatalf("Expected error containing %q, got %v", tc.expectedError, err)
+			}
+		})
+	}
+
+	pending["ServiceAccount"] = nil
+	if err := gate.CheckCreation("Deployment", "cluster2", "foo"); err == nil {
+		t.Fatalf("Expected creation to wait for ConfigMap foo/c")
+	}
+	// Kinds whose controller has stopped no longer defer creation.
+	unregisterConfigMaps()
+	if err := gate.CheckCreation("Deployment", "cluster2", "foo"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var nilGate *ApplyOrderGate
+	nilGate.Register("widgets", "Widget", nil)()
+	if err := nilGate.CheckCreation("Deployment", "cluster1", "foo"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
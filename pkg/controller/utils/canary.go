@@ -0,0 +1,68 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// CanaryHealth determines from its status whether a resource
+// propagated to a canary cluster is healthy, which allows propagation
+// to the remaining placed clusters, or has failed, which halts it. A
+// resource whose status does not reflect its current generation is
+// neither. A resource that reports neither a Ready nor an Available
+// condition is healthy, since most resources without a controller in
+// the cluster have no status.
+func CanaryHealth(obj *unstructured.Unstructured) (healthy bool, failure string) {
+	observedGeneration, found, _ := unstructured.NestedInt64(obj.Object, StatusField, "observedGeneration")
+	if found && observedGeneration < obj.GetGeneration() {
+		return false, ""
+	}
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, StatusField, "conditions")
+	healthy = true
+	for _, rawCondition := range conditions {
+		condition, ok := rawCondition.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		conditionType, _ := condition["type"].(string)
+		conditionStatus, _ := condition["status"].(string)
+		reason, _ := condition["reason"].(string)
+		switch {
+		case (conditionType == "Failed" || conditionType == "Stalled") && conditionStatus == "True":
+			return false, conditionFailure(conditionType, conditionStatus, reason, condition)
+		case conditionType == "Progressing" && reason == "ProgressDeadlineExceeded":
+			return false, conditionFailure(conditionType, conditionStatus, reason, condition)
+		case conditionType == "Ready" || conditionType == "Available":
+			healthy = healthy && conditionStatus == "True"
+		}
+	}
+	return healthy, ""
+}
+
+func conditionFailure(conditionType, conditionStatus, reason string, condition map[string]interface{}) string {
+	failure := fmt.Sprintf("%s condition is %s", conditionType, conditionStatus)
+	if len(reason) > 0 {
+		failure = fmt.Sprintf("%s (%s)", failure, reason)
+	}
+	if message, _ := condition["message"].(string); len(message) > 0 {
+		failure = fmt.Sprintf("%s: %s", failure, message)
+	}
+	return failure
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestCanaryHealth(t *testing.T) {
+	testCases := map[string]struct {
+		generation      int64
+		status          map[string]interface{}
+		expectedHealthy bool
+		expectedFailure string
+	}{
+		"healthy without status": {
+			expectedHealthy: true,
+		},
+		"healthy when ready": {
+			generation: 2,
+			status: map[string]interface{}{
+				"observedGeneration": int64(2),
+				"conditions": []interface{}{
+					map[string]interface{}{"type": "Ready", "status": "True"},
+				},
+			},
+			expectedHealthy: true,
+		},
+		"not healthy while the generation is not observed": {
+			generation: 2,
+			status: map[string]interface{}{
+				"observedGeneration": int64(1),
+				"conditions": []interface{}{
+					map[string]interface{}{"type": "Ready", "status": "True"},
+				},
+			},
+		},
+		"not healthy while not available": {
+			status: map[string]interface{}{
+				"conditions": []interface{}{
+					map[string]interface{}{"type": "Ready", "status": "True"},
+					map[string]interface{}{"type": "Available", "status": "False"},
+				},
+			},
+		},
+		"failed when stalled": {
+			status: map[string]interface{}{
+				"conditions": []interface{}{
+					map[string]interface{}{"type": "Stalled", "status": "True", "reason": "InvalidSpec", "message": "replicas must be positive"},
+				},
+			},
+			expectedFailure: "Stalled condition is True (InvalidSpec): replicas must be positive",
+		},
+		"failed when the progress deadline is exceeded": {
+			status: map[string]interface{}{
+				"conditions": []interface{}{
+					map[string]interface{}{"type": "Available", "status": "True"},
+					map[string]interface{}{"type": "Progressing", "status": "False", "reason": "ProgressDeadlineExceeded"},
+				},
+			},
+			expectedFailure: "Progressing condition is False (ProgressDeadlineExceeded)",
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			obj := &unstructured.Unstructured{Object: map[string]interface{}{}}
+			obj.SetGeneration(tc.generation)
+			if tc.status != nil {
+				obj.Object[StatusField] = tc.status
+			}
+
+			healthy, failure := CanaryHealth(obj)
+			if healthy != tc.expectedHealthy {
+				t.Fatalf("Expected healthy to be %v, got %v", tc.expectedHealthy, healthy)
+			}
+			if failure != tc.expectedFailure {
+				t.Fatalf("Expected failure %q, got %q", tc.expectedFailure, failure)
+			}
+		})
+	}
+}
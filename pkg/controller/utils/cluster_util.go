@@ -46,8 +46,32 @@ const (
 	TokenKey          = "token"
 	CaCrtKey          = "ca.crt"
 	KubeFedConfigName = "kubefed"
+
+	// DefaultClusterClientQPS and DefaultClusterClientBurst are the
+	// rate limits of the clients of member clusters used by the
+	// controller manager unless configured otherwise.
+	DefaultClusterClientQPS   = 50.0
+	DefaultClusterClientBurst = 100
 )
 
+// ClientRateLimits limits the rate of the requests of a client to an
+// API server.
+type ClientRateLimits struct {
+	QPS   float32
+	Burst int
+}
+
+// Apply sets the configured rate limits on the given config. A limit
+// that is not set leaves the limit of the config unchanged.
+func (l ClientRateLimits) Apply(config *restclient.Config) {
+	if l.QPS > 0 {
+		config.QPS = l.QPS
+	}
+	if l.Burst > 0 {
+		config.Burst = l.Burst
+	}
+}
+
 // BuildClusterConfig returns a restclient.Config that can be used to configure
 // a client for the given KubeFedCluster or an error. The client is used to
 // access kubernetes secrets in the kubefed namespace.
@@ -102,6 +126,19 @@ func BuildClusterConfig(fedCluster *fedv1b1.KubeFedCluster, client generic.Clien
 	return clusterConfig, nil
 }
 
+// BuildRateLimitedClusterConfig returns the configuration built by
+// BuildClusterConfig for the given KubeFedCluster with the given rate
+// limits applied. Controllers use it for all their clients of member
+// clusters so that the configured limits apply to each of them.
+func BuildRateLimitedClusterConfig(fedCluster *fedv1b1.KubeFedCluster, client generic.Client, fedNamespace string, rateLimits ClientRateLimits) (*restclient.Config, error) {
+	clusterConfig, err := BuildClusterConfig(fedCluster, client, fedNamespace)
+	if err != nil {
+		return nil, err
+	}
+	rateLimits.Apply(clusterConfig)
+	return clusterConfig, nil
+}
+
 // IsPrimaryCluster checks if the caller is working with objects for the
 // primary cluster by checking if the UIDs match for both ObjectMetas passed
 // in.
@@ -0,0 +1,83 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubeclientset "k8s.io/client-go/kubernetes"
+
+	fedv1b1 "sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
+	"sigs.k8s.io/kubefed/pkg/controller/utils"
+	"sigs.k8s.io/kubefed/test/common/fake"
+)
+
+func TestClusterClientRateLimits(t *testing.T) {
+	client := fake.NewGenericClient(fake.NewStore())
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "kube-federation-system", Name: "cluster1-secret"},
+		Data:       map[string][]byte{utils.TokenKey: []byte("token")},
+	}
+	require.NoError(t, client.Create(context.Background(), secret))
+	cluster := &fedv1b1.KubeFedCluster{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "kube-federation-system", Name: "cluster1"},
+		Spec: fedv1b1.KubeFedClusterSpec{
+			APIEndpoint: "https://cluster1.example.com",
+			SecretRef:   fedv1b1.LocalSecretReference{Name: "cluster1-secret"},
+		},
+	}
+
+	testCases := map[string]struct {
+		rateLimits    utils.ClientRateLimits
+		expectedQPS   float32
+		expectedBurst int
+	}{
+		"limits of the cluster config apply if not set": {
+			expectedQPS:   utils.KubeAPIQPS,
+			expectedBurst: utils.KubeAPIBurst,
+		},
+		"configured limits apply": {
+			rateLimits:    utils.ClientRateLimits{QPS: utils.DefaultClusterClientQPS, Burst: utils.DefaultClusterClientBurst},
+			expectedQPS:   utils.DefaultClusterClientQPS,
+			expectedBurst: utils.DefaultClusterClientBurst,
+		},
+		"only the configured limit applies": {
+			rateLimits:    utils.ClientRateLimits{Burst: 500},
+			expectedQPS:   utils.KubeAPIQPS,
+			expectedBurst: 500,
+		},
+	}
+
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			clusterConfig, err := utils.BuildRateLimitedClusterConfig(cluster, client, "kube-federation-system", tc.rateLimits)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedQPS, clusterConfig.QPS)
+			assert.Equal(t, tc.expectedBurst, clusterConfig.Burst)
+
+			kubeClient, err := kubeclientset.NewForConfig(clusterConfig)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedQPS, kubeClient.CoreV1().RESTClient().GetRateLimiter().QPS())
+		})
+	}
+}
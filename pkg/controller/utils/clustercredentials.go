@@ -0,0 +1,57 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	fedv1b1 "sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
+)
+
+const (
+	// ClusterCredentialsVersionAnnotation records on a KubeFedCluster
+	// the resource version of its secret. It is updated by the cluster
+	// controller whenever the secret changes so that the clients of
+	// the cluster are rebuilt with the current credentials.
+	ClusterCredentialsVersionAnnotation = "kubefed.io/credentials-version"
+
+	// minClientRefreshInterval bounds the rate at which the client of
+	// a cluster is rebuilt in response to authentication failures.
+	minClientRefreshInterval = 10 * time.Second
+)
+
+// IsClusterSecret checks whether the given secret holds the
+// credentials of the given cluster.
+func IsClusterSecret(cluster *fedv1b1.KubeFedCluster, secret *apiv1.Secret) bool {
+	return cluster.Namespace == secret.Namespace && cluster.Spec.SecretRef.Name == secret.Name
+}
+
+// ClusterCredentialsOutdated checks whether the credentials of the
+// given cluster were last recorded for a version of its secret other
+// than the given one.
+func ClusterCredentialsOutdated(cluster *fedv1b1.KubeFedCluster, secret *apiv1.Secret) bool {
+	return cluster.GetAnnotations()[ClusterCredentialsVersionAnnotation] != secret.ResourceVersion
+}
+
+// IsAuthenticationError checks whether the given error indicates that
+// a cluster rejected the credentials with which it was accessed.
+func IsAuthenticationError(err error) bool {
+	return apierrors.IsUnauthorized(err)
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"testing"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	restclient "k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+
+	"sigs.k8s.io/kubefed/pkg/apis/core/common"
+	fedv1b1 "sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
+	"sigs.k8s.io/kubefed/pkg/client/generic"
+)
+
+func TestRefreshClientForCluster(t *testing.T) {
+	cluster := &fedv1b1.KubeFedCluster{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "kube-federation-system", Name: "cluster1"},
+		Status: fedv1b1.KubeFedClusterStatus{
+			Conditions: []fedv1b1.ClusterCondition{{Type: common.ClusterReady, Status: apiv1.ConditionTrue}},
+		},
+	}
+	store := cache.NewStore(cache.MetaNamespaceKeyFunc)
+	if err := store.Add(cluster); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// The token of the cluster secret, which is read whenever a
+	// client is built.
+	token := "token1"
+	var builtTokens []string
+	fedInformer := &federatedInformerImpl{
+		clusterInformer: informer{store: store},
+		configFactory: func(*fedv1b1.KubeFedCluster) (*restclient.Config, error) {
+			builtTokens = append(builtTokens, token)
+			return &restclient.Config{Host: "https://cluster1.example.com", BearerToken: token}, nil
+		},
+		targetInformers: make(map[string]informer),
+		fedNamespace:    cluster.Namespace,
+		clusterClients:  make(map[string]generic.Client),
+		clientRefreshes: make(map[string]time.Time),
+	}
+
+	staleClient, err := fedInformer.GetClientForCluster(cluster.Name)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	token = "token2"
+	cachedClient, err := fedInformer.GetClientForCluster(cluster.Name)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cachedClient != staleClient {
+		t.Fatalf("Expected the client to be cached until refreshed")
+	}
+
+	fedInformer.RefreshClientForCluster(cluster.Name)
+	refreshedClient, err := fedInformer.GetClientForCluster(cluster.Name)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if refreshedClient == staleClient {
+		t.Fatalf("Expected the client to be rebuilt after a refresh")
+	}
+	if len(builtTokens) != 2 || builtTokens[1] != "token2" {
+		t.Fatalf("Expected the client to be rebuilt with the current token, got tokens %v", builtTokens)
+	}
+
+	// A refresh soon after the last one is ignored
+	fedInformer.RefreshClientForCluster(cluster.Name)
+	if client, _ := fedInformer.GetClientForCluster(cluster.Name); client != refreshedClient {
+		t.Fatalf("Expected a refresh within %v of the last one to be ignored", minClientRefreshInterval)
+	}
+}
+
+func TestClusterCredentialsOutdated(t *testing.T) {
+	cluster := &fedv1b1.KubeFedCluster{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "kube-federation-system", Name: "cluster1"},
+		Spec:       fedv1b1.KubeFedClusterSpec{SecretRef: fedv1b1.LocalSecretReference{Name: "cluster1-secret"}},
+	}
+	secret := &apiv1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "kube-federation-system", Name: "cluster1-secret", ResourceVersion: "2"},
+	}
+	if !IsClusterSecret(cluster, secret) {
+		t.Fatalf("Expected the secret to hold the credentials of the cluster")
+	}
+	if !ClusterCredentialsOutdated(cluster, secret) {
+		t.Fatalf("Expected the credentials of a cluster without a recorded version to be outdated")
+	}
+	cluster.SetAnnotations(map[string]string{ClusterCredentialsVersionAnnotation: "2"})
+	if ClusterCredentialsOutdated(cluster, secret) {
+		t.Fatalf("Expected the credentials recorded for the current version not to be outdated")
+	}
+	otherSecret := secret.DeepCopy()
+	otherSecret.Name = "cluster2-secret"
+	if IsClusterSecret(cluster, otherSecret) {
+		t.Fatalf("Expected the secret of another cluster not to hold the credentials of the cluster")
+	}
+}
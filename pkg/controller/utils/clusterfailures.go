@@ -0,0 +1,150 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ClusterEvictionTaintKey is the key of the taint added to a member
+// cluster whose applies fail at a rate above the configured threshold.
+const ClusterEvictionTaintKey = "kubefed.io/apply-failures"
+
+// ClusterFailureRate is the number of resources applied to a member
+// cluster within the window of a ClusterFailureTracker and how many of
+// them failed to be applied.
+type ClusterFailureRate struct {
+	Failed int
+	Total  int
+}
+
+// ClusterFailureTracker tracks the outcomes of the applies to member
+// clusters across the sync controllers of all types to determine the
+// clusters whose applies fail at a rate above a threshold.
+type ClusterFailureTracker struct {
+	sync.Mutex
+
+	thresholdPercent   int
+	minimumApplies     int
+	window             time.Duration
+	maxEvictedClusters int
+	taintEffect        corev1.TaintEffect
+
+	// The latest outcome of the apply of each resource to each
+	// cluster within the window, keyed by cluster and resource, so
+	// that the retries of a failing resource are only counted once.
+	outcomes map[string]map[string]applyOutcome
+
+	// Serializes the eviction of clusters so that the limit on the
+	// number of evicted clusters holds across the sync controllers.
+	evictionLock sync.Mutex
+
+	now func() time.Time
+}
+
+type applyOutcome struct {
+	time   time.Time
+	failed bool
+}
+
+// NewClusterFailureTracker returns a tracker that reports a cluster
+// once the applies of at least thresholdPercent of at least
+// minimumApplies resources to it within the window failed. A reported
+// cluster is tainted with the given effect, provided that no more than
+// maxEvictedClusters clusters are tainted.
+func NewClusterFailureTracker(thresholdPercent, minimumApplies int, window time.Duration, maxEvictedClusters int, taintEffect corev1.TaintEffect) *ClusterFailureTracker {
+	if minimumApplies < 1 {
+		minimumApplies = 1
+	}
+	if maxEvictedClusters < 1 {
+		maxEvictedClusters = 1
+	}
+	return &ClusterFailureTracker{
+		thresholdPercent:   thresholdPercent,
+		minimumApplies:     minimumApplies,
+		window:             window,
+		maxEvictedClusters: maxEvictedClusters,
+		taintEffect:        taintEffect,
+		outcomes:           make(map[string]map[string]applyOutcome),
+		now:                time.Now,
+	}
+}
+
+// Record records the outcome of an apply of the resource with the
+// given key to the named cluster, replacing an outcome previously
+// recorded for the resource. It returns the failure rate of the
+// cluster and true if the rate exceeds the threshold, in which case
+// the outcomes recorded for the cluster are discarded so that the
+// cluster is only reported again for subsequent failures.
+func (t *ClusterFailureTracker) Record(clusterName, key string, failed bool) (ClusterFailureRate, bool) {
+	t.Lock()
+	defer t.Unlock()
+
+	now := t.now()
+	outcomes, ok := t.outcomes[clusterName]
+	if !ok {
+		outcomes = make(map[string]applyOutcome)
+		t.outcomes[clusterName] = outcomes
+	}
+	outcomes[key] = applyOutcome{time: now, failed: failed}
+
+	rate := ClusterFailureRate{}
+	for outcomeKey, outcome := range outcomes {
+		if now.Sub(outcome.time) > t.window {
+			delete(outcomes, outcomeKey)
+			continue
+		}
+		rate.Total++
+		if outcome.failed {
+			rate.Failed++
+		}
+	}
+	if rate.Total >= t.minimumApplies && rate.Failed*100 >= t.thresholdPercent*rate.Total {
+		delete(t.outcomes, clusterName)
+		return rate, true
+	}
+	return rate, false
+}
+
+// Window returns the period over which the failure rate of a cluster
+// is evaluated.
+func (t *ClusterFailureTracker) Window() time.Duration {
+	return t.window
+}
+
+// MaxEvictedClusters returns the maximum number of clusters that may
+// be tainted at the same time.
+func (t *ClusterFailureTracker) MaxEvictedClusters() int {
+	return t.maxEvictedClusters
+}
+
+// TaintEffect returns the effect of the taint added to a cluster whose
+// failure rate exceeds the threshold.
+func (t *ClusterFailureTracker) TaintEffect() corev1.TaintEffect {
+	return t.taintEffect
+}
+
+// Evict calls the given function with the eviction of clusters by
+// other callers excluded.
+func (t *ClusterFailureTracker) Evict(evict func()) {
+	t.evictionLock.Lock()
+	defer t.evictionLock.Unlock()
+	evict()
+}
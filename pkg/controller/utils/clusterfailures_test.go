@@ -0,0 +1,78 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestClusterFailureTracker(t *testing.T) {
+	now := time.Now()
+	tracker := NewClusterFailureTracker(50, 4, time.Minute, 1, corev1.TaintEffectNoSchedule)
+	tracker.now = func() time.Time { return now }
+
+	record := func(clusterName, key string, failed, expectExceeded bool) ClusterFailureRate {
+		t.Helper()
+		rate, exceeded := tracker.Record(clusterName, key, failed)
+		if exceeded != expectExceeded {
+			t.Fatalf("Expected the threshold to be exceeded for %q to be %v, got %v with %+v", clusterName, expectExceeded, exceeded, rate)
+		}
+		return rate
+	}
+
+	// The rate is not evaluated before the minimum number of applies.
+	record("cluster1", "a", true, false)
+	record("cluster1", "b", true, false)
+	record("cluster1", "c", false, false)
+	// Retries of a resource only count once.
+	for i := 0; i < 3; i++ {
+		record("cluster1", "a", true, false)
+	}
+	// Successes of another cluster do not affect the rate.
+	record("cluster2", "a", false, false)
+	rate := record("cluster1", "d", true, true)
+	if expected := (ClusterFailureRate{Failed: 3, Total: 4}); rate != expected {
+		t.Fatalf("Expected rate %+v, got %+v", expected, rate)
+	}
+
+	// The outcomes of a reported cluster are discarded.
+	record("cluster1", "a", true, false)
+
+	// Outcomes outside the window are not counted.
+	now = now.Add(2 * time.Minute)
+	record("cluster1", "b", true, false)
+	record("cluster1", "c", true, false)
+	rate = record("cluster1", "d", false, false)
+	if expected := (ClusterFailureRate{Failed: 2, Total: 3}); rate != expected {
+		t.Fatalf("Expected rate %+v, got %+v", expected, rate)
+	}
+
+	// The latest outcome of a resource replaces an earlier one.
+	rate = record("cluster1", "b", false, false)
+	if expected := (ClusterFailureRate{Failed: 1, Total: 3}); rate != expected {
+		t.Fatalf("Expected rate %+v, got %+v", expected, rate)
+	}
+
+	// A rate below the threshold is not reported.
+	for _, key := range []string{"b", "c", "d", "e"} {
+		record("cluster2", key, false, false)
+	}
+	record("cluster2", "f", true, false)
+}
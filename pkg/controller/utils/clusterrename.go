@@ -0,0 +1,179 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/klog/v2"
+
+	fedv1b1 "sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
+	"sigs.k8s.io/kubefed/pkg/client/generic"
+)
+
+// RenameClusterReferences updates the references to the cluster
+// oldName in `spec.placement.clusters` and `spec.overrides` of the
+// given federated object to refer to the cluster newName instead, e.g.
+// after the cluster has been joined again under a new name. References
+// to other clusters are left untouched. A placement reference to
+// oldName is dropped if newName is already placed, but an error is
+// returned if overrides are defined for both names since they cannot
+// be merged safely. Returns whether the object was changed.
+func RenameClusterReferences(fedObject *unstructured.Unstructured, oldName, newName string) (bool, error) {
+	if oldName == newName {
+		return false, nil
+	}
+
+	overrides, found, err := unstructured.NestedSlice(fedObject.Object, SpecField, OverridesField)
+	if err != nil {
+		return false, errors.Wrap(err, "Failed to retrieve overrides")
+	}
+	overridesChanged := false
+	if found {
+		if hasReference(overrides, ClusterNameField, oldName) && hasReference(overrides, ClusterNameField, newName) {
+			return false, errors.Errorf("Overrides are defined for both cluster %q and cluster %q", oldName, newName)
+		}
+		overridesChanged, err = renameReferences(overrides, ClusterNameField, oldName, newName)
+		if err != nil {
+			return false, errors.Wrap(err, "Failed to rename overrides")
+		}
+	}
+
+	clusters, found, err := unstructured.NestedSlice(fedObject.Object, SpecField, PlacementField, ClustersField)
+	if err != nil {
+		return false, errors.Wrap(err, "Failed to retrieve placement clusters")
+	}
+	clustersChanged := false
+	if found {
+		if hasReference(clusters, NameField, newName) {
+			// The new name is already placed, so the reference to the
+			// old name is redundant.
+			clusters, clustersChanged = removeReferences(clusters, NameField, oldName)
+		} else {
+			clustersChanged, err = renameReferences(clusters, NameField, oldName, newName)
+			if err != nil {
+				return false, errors.Wrap(err, "Failed to rename placement clusters")
+			}
+		}
+	}
+
+	if overridesChanged {
+		if err := unstructured.SetNestedSlice(fedObject.Object, overrides, SpecField, OverridesField); err != nil {
+			return false, errors.Wrap(err, "Failed to set overrides")
+		}
+	}
+	if clustersChanged {
+		if err := unstructured.SetNestedSlice(fedObject.Object, clusters, SpecField, PlacementField, ClustersField); err != nil {
+			return false, errors.Wrap(err, "Failed to set placement clusters")
+		}
+	}
+	return overridesChanged || clustersChanged, nil
+}
+
+// RenameClusterReferencesInFederatedObjects renames the references to
+// the cluster oldName to the cluster newName in the federated objects
+// of every type configured by a FederatedTypeConfig in the given
+// KubeFed namespace, limited to the given namespace unless it is
+// empty. Objects that fail to be renamed or updated do not prevent
+// the renaming of other objects, and their errors are aggregated.
+// Returns the number of updated objects.
+func RenameClusterReferencesInFederatedObjects(ctx context.Context, client generic.Client, kubefedNamespace, namespace, oldName, newName string) (int, error) {
+	typeConfigList := &fedv1b1.FederatedTypeConfigList{}
+	if err := client.List(ctx, typeConfigList, kubefedNamespace); err != nil {
+		return 0, errors.Wrap(err, "Failed to list FederatedTypeConfigs")
+	}
+
+	updated := 0
+	var errs []error
+	for i := range typeConfigList.Items {
+		fedAPIResource := typeConfigList.Items[i].GetFederatedType()
+		fedObjectList := &unstructured.UnstructuredList{}
+		fedObjectList.SetGroupVersionKind(schema.GroupVersionKind{
+			Group:   fedAPIResource.Group,
+			Version: fedAPIResource.Version,
+			Kind:    fedAPIResource.Kind + "List",
+		})
+		if err := client.List(ctx, fedObjectList, namespace); err != nil {
+			errs = append(errs, errors.Wrapf(err, "Failed to list %s", fedAPIResource.Kind))
+			continue
+		}
+		for j := range fedObjectList.Items {
+			fedObject := &fedObjectList.Items[j]
+			qualifiedName := NewQualifiedName(fedObject)
+			changed, err := RenameClusterReferences(fedObject, oldName, newName)
+			if err != nil {
+				errs = append(errs, errors.Wrapf(err, "Failed to rename cluster references of %s %q", fedAPIResource.Kind, qualifiedName))
+				continue
+			}
+			if !changed {
+				continue
+			}
+			if err := client.Update(ctx, fedObject); err != nil {
+				errs = append(errs, errors.Wrapf(err, "Failed to update %s %q", fedAPIResource.Kind, qualifiedName))
+				continue
+			}
+			klog.V(2).Infof("Renamed references to cluster %q to %q in %s %q", oldName, newName, fedAPIResource.Kind, qualifiedName)
+			updated++
+		}
+	}
+	return updated, utilerrors.NewAggregate(errs)
+}
+
+// renameReferences sets the given name field of the items of the given
+// slice that refer to oldName to newName, and returns whether any item
+// was changed.
+func renameReferences(items []interface{}, nameField, oldName, newName string) (bool, error) {
+	changed := false
+	for i, rawItem := range items {
+		item, ok := rawItem.(map[string]interface{})
+		if !ok {
+			return false, errors.Errorf("item %d is not an object: %T", i, rawItem)
+		}
+		if item[nameField] == oldName {
+			item[nameField] = newName
+			changed = true
+		}
+	}
+	return changed, nil
+}
+
+// removeReferences returns the items of the given slice that do not
+// refer to the given name, and whether any item was removed.
+func removeReferences(items []interface{}, nameField, name string) ([]interface{}, bool) {
+	retained := make([]interface{}, 0, len(items))
+	for _, rawItem := range items {
+		if item, ok := rawItem.(map[string]interface{}); ok && item[nameField] == name {
+			continue
+		}
+		retained = append(retained, rawItem)
+	}
+	return retained, len(retained) != len(items)
+}
+
+func hasReference(items []interface{}, nameField, name string) bool {
+	for _, rawItem := range items {
+		if item, ok := rawItem.(map[string]interface{}); ok && item[nameField] == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,170 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"sigs.k8s.io/kubefed/pkg/controller/utils"
+	"sigs.k8s.io/kubefed/test/common/fake"
+)
+
+func newFederatedConfigMap(name string, spec map[string]interface{}) *unstructured.Unstructured {
+	fedObject := newObject("types.kubefed.io/v1beta1", "FederatedConfigMap", "ns1", name)
+	fedObject.Object[utils.SpecField] = spec
+	return fedObject
+}
+
+func placementClusters(names ...string) map[string]interface{} {
+	clusters := []interface{}{}
+	for _, name := range names {
+		clusters = append(clusters, map[string]interface{}{utils.NameField: name})
+	}
+	return map[string]interface{}{
+		utils.ClustersField: clusters,
+		"mode":              "Exclusive",
+	}
+}
+
+func clusterOverrides(names ...string) []interface{} {
+	overrides := []interface{}{}
+	for _, name := range names {
+		overrides = append(overrides, map[string]interface{}{
+			utils.ClusterNameField: name,
+			utils.ClusterOverridesField: []interface{}{
+				map[string]interface{}{"path": "/data/cluster", "value": name},
+			},
+		})
+	}
+	return overrides
+}
+
+func TestRenameClusterReferences(t *testing.T) {
+	testCases := map[string]struct {
+		spec            map[string]interface{}
+		expectedSpec    map[string]interface{}
+		expectedChanged bool
+		expectedErr     bool
+	}{
+		"placement is renamed": {
+			spec: map[string]interface{}{
+				utils.PlacementField: placementClusters("old", "other"),
+			},
+			expectedSpec: map[string]interface{}{
+				utils.PlacementField: placementClusters("new", "other"),
+			},
+			expectedChanged: true,
+		},
+		"placement already including the new name drops the old name": {
+			spec: map[string]interface{}{
+				utils.PlacementField: placementClusters("old", "new"),
+			},
+			expectedSpec: map[string]interface{}{
+				utils.PlacementField: placementClusters("new"),
+			},
+			expectedChanged: true,
+		},
+		"overrides are renamed": {
+			spec: map[string]interface{}{
+				utils.OverridesField: clusterOverrides("other", "old"),
+			},
+			expectedSpec: map[string]interface{}{
+				utils.OverridesField: []interface{}{
+					clusterOverrides("other")[0],
+					map[string]interface{}{
+						utils.ClusterNameField: "new",
+						utils.ClusterOverridesField: []interface{}{
+							map[string]interface{}{"path": "/data/cluster", "value": "old"},
+						},
+					},
+				},
+			},
+			expectedChanged: true,
+		},
+		"overrides for both names are rejected": {
+			spec: map[string]interface{}{
+				utils.OverridesField: clusterOverrides("old", "new"),
+			},
+			expectedErr: true,
+		},
+		"unreferenced cluster leaves the object unchanged": {
+			spec: map[string]interface{}{
+				utils.PlacementField: placementClusters("other"),
+				utils.OverridesField: clusterOverrides("other"),
+			},
+			expectedSpec: map[string]interface{}{
+				utils.PlacementField: placementClusters("other"),
+				utils.OverridesField: clusterOverrides("other"),
+			},
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			fedObject := newFederatedConfigMap("cm1", tc.spec)
+			changed, err := utils.RenameClusterReferences(fedObject, "old", "new")
+			if tc.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedChanged, changed)
+			assert.Equal(t, tc.expectedSpec, fedObject.Object[utils.SpecField])
+		})
+	}
+}
+
+func TestRenameClusterReferencesInFederatedObjects(t *testing.T) {
+	ctx := context.Background()
+	client := fake.NewGenericClient(fake.NewStore())
+	require.NoError(t, client.Create(ctx, newTypeConfig("configmaps", "ConfigMap", apiextv1.NamespaceScoped)))
+	for _, fedObject := range []*unstructured.Unstructured{
+		newFederatedConfigMap("placed", map[string]interface{}{
+			utils.PlacementField: placementClusters("old"),
+		}),
+		newFederatedConfigMap("unrelated", map[string]interface{}{
+			utils.PlacementField: placementClusters("other"),
+		}),
+		newFederatedConfigMap("conflicting", map[string]interface{}{
+			utils.OverridesField: clusterOverrides("old", "new"),
+		}),
+	} {
+		require.NoError(t, client.Create(ctx, fedObject))
+	}
+
+	updated, err := utils.RenameClusterReferencesInFederatedObjects(ctx, client, "kube-federation-system", "", "old", "new")
+	assert.Error(t, err)
+	assert.Equal(t, 1, updated)
+
+	expectedClusters := map[string][]interface{}{
+		"placed":    placementClusters("new")[utils.ClustersField].([]interface{}),
+		"unrelated": placementClusters("other")[utils.ClustersField].([]interface{}),
+	}
+	for name, expected := range expectedClusters {
+		fedObject := newObject("types.kubefed.io/v1beta1", "FederatedConfigMap", "", "")
+		require.NoError(t, client.Get(ctx, fedObject, "ns1", name))
+		clusters, _, err := unstructured.NestedSlice(fedObject.Object, utils.SpecField, utils.PlacementField, utils.ClustersField)
+		require.NoError(t, err)
+		assert.Equal(t, expected, clusters, name)
+	}
+}
@@ -0,0 +1,308 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"sigs.k8s.io/kubefed/pkg/apis/core/typeconfig"
+	fedv1a1 "sigs.k8s.io/kubefed/pkg/apis/core/v1alpha1"
+)
+
+// DivergenceType identifies how the sources cross-checked by
+// CheckConsistency disagree for a cluster.
+type DivergenceType string
+
+const (
+	// StatusOKResourceMissing indicates that the federated status
+	// reports propagation to the cluster as successful but the
+	// managed resource does not exist in the cluster.
+	StatusOKResourceMissing DivergenceType = "StatusOKResourceMissing"
+
+	// VersionRecordedResourceMissing indicates that the
+	// PropagatedVersion records a version for the cluster but the
+	// managed resource does not exist in the cluster.
+	VersionRecordedResourceMissing DivergenceType = "VersionRecordedResourceMissing"
+
+	// ResourceNotPlaced indicates that a managed resource exists in a
+	// cluster that neither the federated status nor the
+	// PropagatedVersion reports as placed.
+	ResourceNotPlaced DivergenceType = "ResourceNotPlaced"
+
+	// VersionMismatch indicates that the version recorded by the
+	// PropagatedVersion for the cluster differs from the version of
+	// the managed resource in the cluster.
+	VersionMismatch DivergenceType = "VersionMismatch"
+
+	// ContentMismatch indicates that the version recorded by the
+	// PropagatedVersion for the cluster matches the managed resource
+	// in the cluster, but the resource does not have the content
+	// rendered from the template and overrides.
+	ContentMismatch DivergenceType = "ContentMismatch"
+)
+
+// clusterStatusOK is the status reported in the federated status of
+// a cluster to which propagation was successful.
+const clusterStatusOK = ""
+
+// Divergence describes a disagreement between the sources
+// cross-checked by CheckConsistency for a cluster.
+type Divergence struct {
+	ClusterName string
+	Type        DivergenceType
+	Message     string
+}
+
+// ConsistencyReport is the result of cross-checking the sources
+// describing the propagation of a federated resource.
+type ConsistencyReport struct {
+	// Divergences are sorted by cluster name and type.
+	Divergences []Divergence
+}
+
+// Consistent returns whether no divergence was found.
+func (r *ConsistencyReport) Consistent() bool {
+	return len(r.Divergences) == 0
+}
+
+// CheckConsistency cross-checks the PropagatedVersion, the federated
+// status and the managed resources in member clusters of the given
+// federated resource, and reports any divergence between them. It is
+// the programmatic form of the propagation checks of the crudtester,
+// usable as an ongoing health probe.
+//
+// The given cluster objects are the managed resources retrieved from
+// member clusters, keyed by cluster name, with a nil value for a
+// cluster without the resource. Only the clusters in the map are
+// checked, so clusters that could not be reached may be omitted. A
+// nil PropagatedVersion status indicates that no versions have been
+// recorded.
+func CheckConsistency(typeConfig typeconfig.Interface, fedObject *unstructured.Unstructured, propagatedVersion *fedv1a1.PropagatedVersionStatus, clusterObjects map[string]*unstructured.Unstructured) (*ConsistencyReport, error) {
+	targetKind := typeConfig.GetTargetType().Kind
+
+	clusterStatuses, err := federatedClusterStatuses(fedObject)
+	if err != nil {
+		return nil, err
+	}
+	recordedVersions := map[string]string{}
+	if propagatedVersion != nil {
+		for _, clusterVersion := range propagatedVersion.ClusterVersions {
+			recordedVersions[clusterVersion.ClusterName] = clusterVersion.Version
+		}
+	}
+	overridesMap, err := GetOverrides(fedObject)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ConsistencyReport{}
+	addDivergence := func(clusterName string, divergenceType DivergenceType, messageFmt string, args ...interface{}) {
+		report.Divergences = append(report.Divergences, Divergence{
+			ClusterName: clusterName,
+			Type:        divergenceType,
+			Message:     fmt.Sprintf(messageFmt, args...),
+		})
+	}
+
+	for clusterName, clusterObj := range clusterObjects {
+		clusterStatus, statusReported := clusterStatuses[clusterName]
+		recordedVersion, versionRecorded := recordedVersions[clusterName]
+
+		if clusterObj == nil {
+			if statusReported && clusterStatus == clusterStatusOK {
+				addDivergence(clusterName, StatusOKResourceMissing, "Status reports propagation as successful but %s is missing", targetKind)
+			}
+			if versionRecorded {
+				addDivergence(clusterName, VersionRecordedResourceMissing, "Version %q is recorded but %s is missing", recordedVersion, targetKind)
+			}
+			continue
+		}
+
+		if !statusReported && !versionRecorded {
+			if HasManagedLabel(clusterObj) {
+				addDivergence(clusterName, ResourceNotPlaced, "Managed %s exists but is not reported as placed", targetKind)
+			}
+			continue
+		}
+		if !versionRecorded {
+			continue
+		}
+
+		if version := ObjectVersion(clusterObj); version != recordedVersion {
+			addDivergence(clusterName, VersionMismatch, "Version %q is recorded but %s has version %q", recordedVersion, targetKind, version)
+			continue
+		}
+		desiredObj, err := desiredObjectForCluster(typeConfig, fedObject, overridesMap[clusterName])
+		if err != nil {
+			// Overrides that cannot be applied prevent propagation
+			// and are reported in the status by the sync controller.
+			// The content of resources with templated overrides or
+			// overrides with value sources is not checked.
+			continue
+		}
+		if path, ok := contentMatches(desiredObj, clusterObj); !ok {
+			addDivergence(clusterName, ContentMismatch, "Version %q is current but %s differs from the desired content at %s", recordedVersion, targetKind, path)
+		}
+	}
+
+	sort.Slice(report.Divergences, func(i, j int) bool {
+		if report.Divergences[i].ClusterName != report.Divergences[j].ClusterName {
+			return report.Divergences[i].ClusterName < report.Divergences[j].ClusterName
+		}
+		return report.Divergences[i].Type < report.Divergences[j].Type
+	})
+	return report, nil
+}
+
+// federatedClusterStatuses returns the propagation status of each
+// cluster reported in the status of the given federated resource.
+func federatedClusterStatuses(fedObject *unstructured.Unstructured) (map[string]string, error) {
+	clusters, _, err := unstructured.NestedSlice(fedObject.Object, StatusField, ClustersField)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to retrieve the cluster status")
+	}
+	statuses := map[string]string{}
+	for _, rawCluster := range clusters {
+		cluster, ok := rawCluster.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, ok := cluster[NameField].(string)
+		if !ok {
+			continue
+		}
+		status, _ := cluster[StatusField].(string)
+		statuses[name] = status
+	}
+	return statuses, nil
+}
+
+// desiredObjectForCluster renders the content of the template of the
+// given federated resource with the given overrides applied.
+func desiredObjectForCluster(typeConfig typeconfig.Interface, fedObject *unstructured.Unstructured, overrides ClusterOverrides) (*unstructured.Unstructured, error) {
+	templateBody, _, err := unstructured.NestedMap(fedObject.Object, SpecField, TemplateField)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error retrieving template body")
+	}
+	if templateBody == nil {
+		templateBody = map[string]interface{}{}
+	}
+	obj := &unstructured.Unstructured{Object: templateBody}
+	// Annotations and finalizers are not propagated from the template.
+	obj.SetAnnotations(nil)
+	obj.SetFinalizers(nil)
+	targetAPIResource := typeConfig.GetTargetType()
+	if len(obj.GetKind()) == 0 {
+		obj.SetKind(targetAPIResource.Kind)
+	}
+	if len(obj.GetAPIVersion()) == 0 {
+		obj.SetAPIVersion(schema.GroupVersion{Group: targetAPIResource.Group, Version: targetAPIResource.Version}.String())
+	}
+	if overrides.Templated() {
+		return nil, errors.New("Templated overrides require the parameters of the cluster")
+	}
+	if overrides.HasValueSources() {
+		return nil, errors.New("Overrides with value sources require the ConfigMaps and Secrets they reference")
+	}
+	if len(overrides) > 0 {
+		// ApplyJSONPatch defaults the operation of the overrides in
+		// place.
+		overridesCopy := append(ClusterOverrides{}, overrides...)
+		if err := ApplyJSONPatch(obj, overridesCopy); err != nil {
+			return nil, err
+		}
+	}
+	return obj, nil
+}
+
+// contentMatches returns whether the given cluster object has the
+// content of the given desired object, and the path of the first
+// field that differs otherwise. Only the labels and annotations of
+// the metadata are compared, and fields absent from the desired
+// object are ignored since they may be defaulted or retained in the
+// cluster.
+func contentMatches(desiredObj, clusterObj *unstructured.Unstructured) (string, bool) {
+	for _, field := range []string{"labels", "annotations"} {
+		desired, _, _ := unstructured.NestedFieldNoCopy(desiredObj.Object, "metadata", field)
+		actual, _, _ := unstructured.NestedFieldNoCopy(clusterObj.Object, "metadata", field)
+		if path, ok := subsetMatches(desired, actual, "metadata."+field); !ok {
+			return path, false
+		}
+	}
+	keys := make([]string, 0, len(desiredObj.Object))
+	for key := range desiredObj.Object {
+		switch key {
+		case "apiVersion", "kind", "metadata", StatusField:
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if path, ok := subsetMatches(desiredObj.Object[key], clusterObj.Object[key], key); !ok {
+			return path, false
+		}
+	}
+	return "", true
+}
+
+// subsetMatches returns whether the given actual value contains the
+// given desired value, and the path of the first field that differs
+// otherwise. Maps match if every desired key matches, and lists match
+// if they have the same length and each desired item matches.
+func subsetMatches(desired, actual interface{}, path string) (string, bool) {
+	switch desiredValue := desired.(type) {
+	case map[string]interface{}:
+		actualValue, ok := actual.(map[string]interface{})
+		if !ok {
+			return path, len(desiredValue) == 0 && actual == nil
+		}
+		keys := make([]string, 0, len(desiredValue))
+		for key := range desiredValue {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if fieldPath, ok := subsetMatches(desiredValue[key], actualValue[key], path+"."+key); !ok {
+				return fieldPath, false
+			}
+		}
+		return "", true
+	case []interface{}:
+		actualValue, ok := actual.([]interface{})
+		if !ok || len(actualValue) != len(desiredValue) {
+			return path, len(desiredValue) == 0 && actual == nil
+		}
+		for i := range desiredValue {
+			if itemPath, ok := subsetMatches(desiredValue[i], actualValue[i], fmt.Sprintf("%s[%d]", path, i)); !ok {
+				return itemPath, false
+			}
+		}
+		return "", true
+	case nil:
+		return "", true
+	default:
+		return path, reflect.DeepEqual(desired, actual)
+	}
+}
@@ -0,0 +1,203 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"reflect"
+	"testing"
+
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	fedv1a1 "sigs.k8s.io/kubefed/pkg/apis/core/v1alpha1"
+	fedv1b1 "sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
+)
+
+func newConsistentFederatedConfigMap() *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "types.kubefed.io/v1beta1",
+		"kind":       "FederatedConfigMap",
+		"metadata": map[string]interface{}{
+			"name":      "foo",
+			"namespace": "ns",
+		},
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"labels": map[string]interface{}{"app": "foo"},
+				},
+				"data": map[string]interface{}{"key": "value"},
+			},
+			"overrides": []interface{}{
+				map[string]interface{}{
+					"clusterName": "cluster2",
+					"clusterOverrides": []interface{}{
+						map[string]interface{}{"path": "/data/key", "value": "override"},
+					},
+				},
+			},
+		},
+		"status": map[string]interface{}{
+			"clusters": []interface{}{
+				map[string]interface{}{"name": "cluster1"},
+				map[string]interface{}{"name": "cluster2"},
+			},
+		},
+	}}
+}
+
+func newConsistentClusterConfigMap(resourceVersion, value string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]interface{}{
+			"name":            "foo",
+			"namespace":       "ns",
+			"resourceVersion": resourceVersion,
+			"labels": map[string]interface{}{
+				"app":                    "foo",
+				ManagedByKubeFedLabelKey: ManagedByKubeFedLabelValue,
+			},
+		},
+		"data": map[string]interface{}{"key": value},
+	}}
+}
+
+func TestCheckConsistency(t *testing.T) {
+	typeConfig := &fedv1b1.FederatedTypeConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "configmaps"},
+		Spec: fedv1b1.FederatedTypeConfigSpec{
+			TargetType: fedv1b1.APIResource{Version: "v1", Kind: "ConfigMap", Scope: apiextv1.NamespaceScoped},
+		},
+	}
+
+	testCases := map[string]struct {
+		plantDivergence func(fedObject *unstructured.Unstructured, propagatedVersion *fedv1a1.PropagatedVersionStatus, clusterObjects map[string]*unstructured.Unstructured)
+		expected        []Divergence
+	}{
+		"consistent propagation": {},
+		"status reports success but the resource is missing": {
+			plantDivergence: func(fedObject *unstructured.Unstructured, propagatedVersion *fedv1a1.PropagatedVersionStatus, clusterObjects map[string]*unstructured.Unstructured) {
+				propagatedVersion.ClusterVersions = propagatedVersion.ClusterVersions[1:]
+				clusterObjects["cluster1"] = nil
+			},
+			expected: []Divergence{{
+				ClusterName: "cluster1",
+				Type:        StatusOKResourceMissing,
+				Message:     "Status reports propagation as successful but ConfigMap is missing",
+			}},
+		},
+		"version is recorded but the resource is missing": {
+			plantDivergence: func(fedObject *unstructured.Unstructured, propagatedVersion *fedv1a1.PropagatedVersionStatus, clusterObjects map[string]*unstructured.Unstructured) {
+				err := unstructured.SetNestedSlice(fedObject.Object, []interface{}{
+					map[string]interface{}{"name": "cluster1", "status": "ClusterNotReady"},
+					map[string]interface{}{"name": "cluster2"},
+				}, "status", "clusters")
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				clusterObjects["cluster1"] = nil
+			},
+			expected: []Divergence{{
+				ClusterName: "cluster1",
+				Type:        VersionRecordedResourceMissing,
+				Message:     `Version "rv:1" is recorded but ConfigMap is missing`,
+			}},
+		},
+		"managed resource exists in a cluster that is not placed": {
+			plantDivergence: func(fedObject *unstructured.Unstructured, propagatedVersion *fedv1a1.PropagatedVersionStatus, clusterObjects map[string]*unstructured.Unstructured) {
+				clusterObjects["cluster3"] = newConsistentClusterConfigMap("3", "value")
+				// An unmanaged resource is not expected to be removed.
+				unmanagedObj := newConsistentClusterConfigMap("4", "value")
+				RemoveManagedLabel(unmanagedObj)
+				clusterObjects["cluster4"] = unmanagedObj
+			},
+			expected: []Divergence{{
+				ClusterName: "cluster3",
+				Type:        ResourceNotPlaced,
+				Message:     "Managed ConfigMap exists but is not reported as placed",
+			}},
+		},
+		"recorded version differs from the resource": {
+			plantDivergence: func(fedObject *unstructured.Unstructured, propagatedVersion *fedv1a1.PropagatedVersionStatus, clusterObjects map[string]*unstructured.Unstructured) {
+				clusterObjects["cluster2"].SetResourceVersion("5")
+			},
+			expected: []Divergence{{
+				ClusterName: "cluster2",
+				Type:        VersionMismatch,
+				Message:     `Version "rv:2" is recorded but ConfigMap has version "rv:5"`,
+			}},
+		},
+		"version matches but the content differs": {
+			plantDivergence: func(fedObject *unstructured.Unstructured, propagatedVersion *fedv1a1.PropagatedVersionStatus, clusterObjects map[string]*unstructured.Unstructured) {
+				// Cluster2 has the template value rather than the
+				// overridden one.
+				clusterObjects["cluster2"] = newConsistentClusterConfigMap("2", "value")
+				// Cluster1 lacks a label of the template.
+				clusterObjects["cluster1"].SetLabels(map[string]string{ManagedByKubeFedLabelKey: ManagedByKubeFedLabelValue})
+			},
+			expected: []Divergence{
+				{
+					ClusterName: "cluster1",
+					Type:        ContentMismatch,
+					Message:     `Version "rv:1" is current but ConfigMap differs from the desired content at metadata.labels.app`,
+				},
+				{
+					ClusterName: "cluster2",
+					Type:        ContentMismatch,
+					Message:     `Version "rv:2" is current but ConfigMap differs from the desired content at data.key`,
+				},
+			},
+		},
+		"fields absent from the desired content are ignored": {
+			plantDivergence: func(fedObject *unstructured.Unstructured, propagatedVersion *fedv1a1.PropagatedVersionStatus, clusterObjects map[string]*unstructured.Unstructured) {
+				clusterObjects["cluster1"].Object["data"].(map[string]interface{})["extra"] = "value"
+				clusterObjects["cluster1"].SetAnnotations(map[string]string{"extra": "value"})
+			},
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			fedObject := newConsistentFederatedConfigMap()
+			propagatedVersion := &fedv1a1.PropagatedVersionStatus{
+				ClusterVersions: []fedv1a1.ClusterObjectVersion{
+					{ClusterName: "cluster1", Version: "rv:1"},
+					{ClusterName: "cluster2", Version: "rv:2"},
+				},
+			}
+			clusterObjects := map[string]*unstructured.Unstructured{
+				"cluster1": newConsistentClusterConfigMap("1", "value"),
+				"cluster2": newConsistentClusterConfigMap("2", "override"),
+			}
+			if tc.plantDivergence != nil {
+				tc.plantDivergence(fedObject, propagatedVersion, clusterObjects)
+			}
+
+			report, err := CheckConsistency(typeConfig, fedObject, propagatedVersion, clusterObjects)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if report.Consistent() != (len(tc.expected) == 0) {
+				t.Fatalf("Expected consistency to be %v, got divergences %v", len(tc.expected) == 0, report.Divergences)
+			}
+			if len(tc.expected) > 0 && !reflect.DeepEqual(tc.expected, report.Divergences) {
+				t.Fatalf("Expected divergences %#v, got %#v", tc.expected, report.Divergences)
+			}
+		})
+	}
+}
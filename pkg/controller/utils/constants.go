@@ -33,6 +33,10 @@ const (
 
 	ServiceAccountKind = "ServiceAccount"
 
+	ConfigMapKind = "ConfigMap"
+
+	SecretKind = "Secret"
+
 	// The following fields are used to interact with unstructured
 	// resources.
 
@@ -58,9 +62,22 @@ const (
 	TemplateField = "template"
 
 	// Placement fields
-	PlacementField       = "placement"
-	ClusterSelectorField = "clusterSelector"
-	MatchLabelsField     = "matchLabels"
+	PlacementField          = "placement"
+	ClusterSelectorField    = "clusterSelector"
+	ClusterSelectorsField   = "clusterSelectors"
+	MatchLabelsField        = "matchLabels"
+	MinHealthyClustersField = "minHealthyClusters"
+	TolerationsField        = "tolerations"
+	SampleField             = "sample"
+	CountField              = "count"
+	StickinessSecondsField  = "stickinessSeconds"
+
+	// FederatedNamespace fields
+	PlacementInheritanceField = "placementInheritance"
+
+	// Propagation fields
+	PropagationDeadlineSecondsField = "propagationDeadlineSeconds"
+	RemovalStrategyField            = "removalStrategy"
 
 	// Override fields
 	OverridesField        = "overrides"
@@ -72,6 +89,7 @@ const (
 	// Cluster reference
 	ClustersField = "clusters"
 	NameField     = "name"
+	ModeField     = "mode"
 )
 
 type ReconciliationStatus int
@@ -19,6 +19,8 @@ package utils
 import (
 	"time"
 
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	restclient "k8s.io/client-go/rest"
 
@@ -76,9 +78,67 @@ type ControllerConfig struct {
 	MaxConcurrentSyncReconciles   int64
 	MaxConcurrentStatusReconciles int64
 	SkipAdoptingResources         bool
+	AdoptionPolicy                *fedv1b1.ResourceAdoptionPolicy
 	RawResourceStatusCollection   bool
+	StatusFeedback                bool
+	MetadataMerge                 bool
+	ManagedLabels                 map[string]string
+	ManagedAnnotations            map[string]string
+	CreatedNamespaceLabels        map[string]string
+	CreatedNamespaceAnnotations   map[string]string
+	NamespaceOptInLabel           string
+	PlacementAnnotation           string
+	DeleteEmptyNamespaces         bool
+	PruneRemovedClusters          bool
+	// ApplyOrderGate orders the creation of resources in member
+	// clusters by kind. Creation is not ordered if not set.
+	ApplyOrderGate *ApplyOrderGate
+	// PropagationPause records whether propagation is paused for the
+	// control plane. Propagation is never paused if not set.
+	PropagationPause *PropagationPause
+	// StatusCollectionInterval is the interval at which the status of
+	// resources in member clusters is collected. Status is collected
+	// whenever the resources change if not set.
+	StatusCollectionInterval time.Duration
+	// DriftDetectionInterval is the interval at which managed
+	// resources are checked for out-of-band modifications. Drift is
+	// not detected if not set.
+	DriftDetectionInterval time.Duration
+	// SyncDebounceWindow is the time for which the sync controllers
+	// coalesce successive changes to a federated resource into a
+	// single reconcile. Every change is reconciled without delay if
+	// not set.
+	SyncDebounceWindow time.Duration
+	// SyncReconcileLimiter bounds the number of reconciles in flight
+	// across the sync controllers of all types. The reconciles of
+	// each type are only bounded by MaxConcurrentSyncReconciles if not
+	// set.
+	SyncReconcileLimiter *ReconcileLimiter
+	// ClusterFailureTracker tracks the outcomes of applies across the
+	// sync controllers of all types so that a member cluster whose
+	// applies fail at a rate above a threshold is tainted. Clusters
+	// are not tainted if not set.
+	ClusterFailureTracker *ClusterFailureTracker
+	// ClusterClientRateLimits limits the rate of the requests of the
+	// clients of member clusters. The limits of BuildClusterConfig
+	// apply if not set.
+	ClusterClientRateLimits ClientRateLimits
+	// TracerProvider provides the tracers with which controllers
+	// record the spans of their reconciliations. Spans are not
+	// recorded if not set.
+	TracerProvider trace.TracerProvider
 }
 
 func (c *ControllerConfig) LimitedScope() bool {
 	return c.KubeFedNamespaces.TargetNamespace != metav1.NamespaceAll
 }
+
+// Tracer returns the tracer with the given instrumentation name from
+// the configured tracer provider, or a no-op tracer if tracing is not
+// configured.
+func (c *ControllerConfig) Tracer(name string) trace.Tracer {
+	if c.TracerProvider == nil {
+		return noop.NewTracerProvider().Tracer(name)
+	}
+	return c.TracerProvider.Tracer(name)
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"sigs.k8s.io/kubefed/pkg/client/generic"
+)
+
+// CustomResourceDefinitionName returns the name of the
+// CustomResourceDefinition that would define the given API resource,
+// or an empty string for a resource of the core group, which cannot
+// be defined by a CustomResourceDefinition.
+func CustomResourceDefinitionName(apiResource metav1.APIResource) string {
+	if len(apiResource.Group) == 0 {
+		return ""
+	}
+	return apiResource.Name + "." + apiResource.Group
+}
+
+// GetCustomResourceDefinitionEstablished retrieves the named
+// CustomResourceDefinition and returns whether it exists and whether
+// it has been established. Custom resources can only be created once
+// their definition has been established.
+func GetCustomResourceDefinitionEstablished(ctx context.Context, client generic.Client, name string) (found, established bool, err error) {
+	crd := &unstructured.Unstructured{}
+	crd.SetGroupVersionKind(apiextv1.SchemeGroupVersion.WithKind("CustomResourceDefinition"))
+	err = client.Get(ctx, crd, "", name)
+	if apierrors.IsNotFound(err) {
+		return false, false, nil
+	}
+	if err != nil {
+		return false, false, errors.Wrapf(err, "Failed to retrieve CustomResourceDefinition %q", name)
+	}
+	conditions, _, err := unstructured.NestedSlice(crd.Object, StatusField, "conditions")
+	if err != nil {
+		return true, false, errors.Wrapf(err, "Failed to retrieve the conditions of CustomResourceDefinition %q", name)
+	}
+	for _, rawCondition := range conditions {
+		condition, ok := rawCondition.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if condition["type"] == string(apiextv1.Established) && condition["status"] == string(apiextv1.ConditionTrue) {
+			return true, true, nil
+		}
+	}
+	return true, false, nil
+}
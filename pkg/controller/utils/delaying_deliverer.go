@@ -103,7 +103,11 @@ func (d *DelayingDeliverer) deliver(timestamp time.Time) {
 			return
 		}
 		item := heap.Pop(d.heap).(*DelayingDelivererItem)
-		d.targetChannel <- item
+		select {
+		case d.targetChannel <- item:
+		case <-d.stopChannel:
+			return
+		}
 	}
 }
 
@@ -147,13 +151,18 @@ func (d *DelayingDeliverer) Stop() {
 	close(d.stopChannel)
 }
 
-// Delivers value at the given time.
+// Delivers value at the given time. The value is discarded if the
+// DelayingDeliverer has been stopped.
 func (d *DelayingDeliverer) DeliverAt(key string, value interface{}, deliveryTime time.Time) {
-	d.updateChannel <- &DelayingDelivererItem{
+	item := &DelayingDelivererItem{
 		Key:          key,
 		Value:        value,
 		DeliveryTime: deliveryTime,
 	}
+	select {
+	case d.updateChannel <- item:
+	case <-d.stopChannel:
+	}
 }
 
 // Delivers value after the given delay.
@@ -40,19 +40,27 @@ func GetDeleteOptions(obj *unstructured.Unstructured) ([]client.DeleteOption, er
 	}
 
 	if optStr, ok := annotations[DeleteOptionAnnotation]; ok {
-		opt := &metav1.DeleteOptions{}
-		if err := json.Unmarshal([]byte(optStr), opt); err != nil {
-			return nil, errors.Wrapf(err, "could not deserialize delete options from annotation value '%s'", optStr)
+		clientOpt, err := parseDeleteOptions(optStr)
+		if err != nil {
+			return nil, err
 		}
-		clientOpt := &client.DeleteOptions{}
-		clientOpt.GracePeriodSeconds = opt.GracePeriodSeconds
-		clientOpt.PropagationPolicy = opt.PropagationPolicy
-		clientOpt.Preconditions = opt.Preconditions
 		options = append(options, clientOpt)
 	}
 	return options, nil
 }
 
+func parseDeleteOptions(optStr string) (*client.DeleteOptions, error) {
+	opt := &metav1.DeleteOptions{}
+	if err := json.Unmarshal([]byte(optStr), opt); err != nil {
+		return nil, errors.Wrapf(err, "could not deserialize delete options from annotation value '%s'", optStr)
+	}
+	clientOpt := &client.DeleteOptions{}
+	clientOpt.GracePeriodSeconds = opt.GracePeriodSeconds
+	clientOpt.PropagationPolicy = opt.PropagationPolicy
+	clientOpt.Preconditions = opt.Preconditions
+	return clientOpt, nil
+}
+
 // ApplyDeleteOptions set the DeleteOptions on the annotation
 func ApplyDeleteOptions(obj *unstructured.Unstructured, opts ...client.DeleteOption) error {
 	opt := client.DeleteOptions{}
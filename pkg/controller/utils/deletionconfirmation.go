@@ -0,0 +1,66 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// DeletionConfirmationRequiredAnnotation If this annotation is present on a federated resource,
+	// resources in member clusters will not be deleted along with the
+	// federated resource unless the deletion has been confirmed.
+	DeletionConfirmationRequiredAnnotation = "kubefed.io/deletion-confirmation-required"
+	DeletionConfirmationRequiredValue      = "true"
+
+	// ConfirmDeletionAnnotation confirms the deletion of a federated
+	// resource that requires confirmation. The value must match the
+	// name of the federated resource.
+	ConfirmDeletionAnnotation = "kubefed.io/confirm-deletion"
+)
+
+// IsDeletionBlocked checks whether deletion of resources managed by
+// the given federated resource requires a confirmation that has not
+// been provided.
+func IsDeletionBlocked(obj *unstructured.Unstructured) bool {
+	annotations := obj.GetAnnotations()
+	if annotations[DeletionConfirmationRequiredAnnotation] != DeletionConfirmationRequiredValue {
+		return false
+	}
+	return annotations[ConfirmDeletionAnnotation] != obj.GetName()
+}
+
+// RequireDeletionConfirmation requires confirmation before resources
+// managed by the given federated resource are deleted.
+func RequireDeletionConfirmation(obj *unstructured.Unstructured) {
+	setAnnotation(obj, DeletionConfirmationRequiredAnnotation, DeletionConfirmationRequiredValue)
+}
+
+// ConfirmDeletion confirms the deletion of resources managed by the
+// given federated resource.
+func ConfirmDeletion(obj *unstructured.Unstructured) {
+	setAnnotation(obj, ConfirmDeletionAnnotation, obj.GetName())
+}
+
+func setAnnotation(obj *unstructured.Unstructured, key, value string) {
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[key] = value
+	obj.SetAnnotations(annotations)
+}
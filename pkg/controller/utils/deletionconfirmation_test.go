@@ -0,0 +1,84 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestIsDeletionBlocked(t *testing.T) {
+	testCases := map[string]struct {
+		annotations map[string]string
+		expected    bool
+	}{
+		"not blocked without annotations": {
+			expected: false,
+		},
+		"not blocked when confirmation is not required": {
+			annotations: map[string]string{
+				ConfirmDeletionAnnotation: "wrong",
+			},
+			expected: false,
+		},
+		"blocked when confirmation is required and absent": {
+			annotations: map[string]string{
+				DeletionConfirmationRequiredAnnotation: DeletionConfirmationRequiredValue,
+			},
+			expected: true,
+		},
+		"blocked when the confirmation does not match the name": {
+			annotations: map[string]string{
+				DeletionConfirmationRequiredAnnotation: DeletionConfirmationRequiredValue,
+				ConfirmDeletionAnnotation:              "bar",
+			},
+			expected: true,
+		},
+		"not blocked when the confirmation matches the name": {
+			annotations: map[string]string{
+				DeletionConfirmationRequiredAnnotation: DeletionConfirmationRequiredValue,
+				ConfirmDeletionAnnotation:              "foo",
+			},
+			expected: false,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			obj := &unstructured.Unstructured{}
+			obj.SetName("foo")
+			obj.SetAnnotations(testCase.annotations)
+			if blocked := IsDeletionBlocked(obj); blocked != testCase.expected {
+				t.Fatalf("Expected blocked to be %v, got %v", testCase.expected, blocked)
+			}
+		})
+	}
+}
+
+func TestConfirmDeletion(t *testing.T) {
+	obj := &unstructured.Unstructured{}
+	obj.SetName("foo")
+	RequireDeletionConfirmation(obj)
+	if !IsDeletionBlocked(obj) {
+		t.Fatalf("Expected deletion to be blocked before confirmation")
+	}
+	ConfirmDeletion(obj)
+	if IsDeletionBlocked(obj) {
+		t.Fatalf("Expected deletion not to be blocked after confirmation")
+	}
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// DeletionGracePeriodAnnotation on a federated resource gives the
+	// duration (e.g. "10m") for which its managed resources are kept
+	// in member clusters after the federated resource is deleted.
+	// Recreating the federated resource within that period cancels
+	// their deletion. A value of "0s" opts out of the grace period of
+	// the type.
+	DeletionGracePeriodAnnotation = "kubefed.io/deletion-grace-period"
+
+	// DeletionPendingAnnotation on a resource in a member cluster
+	// gives the RFC 3339 time after which the resource is deleted
+	// since the federated resource that managed it was deleted.
+	DeletionPendingAnnotation = "kubefed.io/deletion-pending-until"
+
+	// DeletionPendingOptionsAnnotation on a resource pending deletion
+	// in a member cluster records the delete options of the deleted
+	// federated resource, in the form of its kubefed.io/deleteoption
+	// annotation, with which the resource is deleted.
+	DeletionPendingOptionsAnnotation = "kubefed.io/deletion-pending-options"
+)
+
+// GetDeletionGracePeriod returns the deletion grace period of the
+// given federated resource, or the given grace period of its type if
+// the resource does not specify one. Returns zero if managed resources
+// should be deleted as soon as the federated resource is deleted.
+func GetDeletionGracePeriod(obj metav1.Object, typeGracePeriod *metav1.Duration) (time.Duration, error) {
+	value, ok := obj.GetAnnotations()[DeletionGracePeriodAnnotation]
+	if !ok {
+		if typeGracePeriod == nil {
+			return 0, nil
+		}
+		return typeGracePeriod.Duration, nil
+	}
+	gracePeriod, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, errors.Wrapf(err, "Failed to parse the %q annotation", DeletionGracePeriodAnnotation)
+	}
+	if gracePeriod < 0 {
+		return 0, errors.Errorf("The %q annotation must not be negative, got %q", DeletionGracePeriodAnnotation, value)
+	}
+	return gracePeriod, nil
+}
+
+// DeletionPendingUntil returns the time after which the given resource
+// in a member cluster is deleted, and whether its deletion is pending.
+// Deletion is considered not to be pending if the recorded time cannot
+// be parsed, so that the resource is never deleted without a valid
+// deadline.
+func DeletionPendingUntil(clusterObj *unstructured.Unstructured) (time.Time, bool) {
+	value, ok := clusterObj.GetAnnotations()[DeletionPendingAnnotation]
+	if !ok {
+		return time.Time{}, false
+	}
+	until, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return until, true
+}
+
+// SetDeletionPending records on the given object that it is to be
+// deleted after the given time with the given delete options, in the
+// form of the kubefed.io/deleteoption annotation. No options are
+// recorded if the given options are empty.
+func SetDeletionPending(obj *unstructured.Unstructured, until time.Time, deleteOptions string) {
+	setAnnotation(obj, DeletionPendingAnnotation, until.UTC().Format(time.RFC3339))
+	if len(deleteOptions) > 0 {
+		setAnnotation(obj, DeletionPendingOptionsAnnotation, deleteOptions)
+		return
+	}
+	annotations := obj.GetAnnotations()
+	if _, ok := annotations[DeletionPendingOptionsAnnotation]; ok {
+		delete(annotations, DeletionPendingOptionsAnnotation)
+		obj.SetAnnotations(annotations)
+	}
+}
+
+// DeletionPendingOptions returns the delete options recorded on the
+// given resource pending deletion.
+func DeletionPendingOptions(clusterObj *unstructured.Unstructured) ([]client.DeleteOption, error) {
+	value, ok := clusterObj.GetAnnotations()[DeletionPendingOptionsAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	opt, err := parseDeleteOptions(value)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to parse the %q annotation", DeletionPendingOptionsAnnotation)
+	}
+	return []client.DeleteOption{opt}, nil
+}
+
+// IsDeletionPending checks whether the given resource in a member
+// cluster is pending deletion.
+func IsDeletionPending(clusterObj *unstructured.Unstructured) bool {
+	_, ok := clusterObj.GetAnnotations()[DeletionPendingAnnotation]
+	return ok
+}
+
+// RemoveDeletionPending removes the record of pending deletion from
+// the given object so that a resource whose federated resource was
+// recreated within the grace period is retained.
+func RemoveDeletionPending(obj *unstructured.Unstructured) {
+	annotations := obj.GetAnnotations()
+	_, pending := annotations[DeletionPendingAnnotation]
+	_, hasOptions := annotations[DeletionPendingOptionsAnnotation]
+	if !pending && !hasOptions {
+		return
+	}
+	delete(annotations, DeletionPendingAnnotation)
+	delete(annotations, DeletionPendingOptionsAnnotation)
+	obj.SetAnnotations(annotations)
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestGetDeletionGracePeriod(t *testing.T) {
+	testCases := map[string]struct {
+		annotations     map[string]string
+		typeGracePeriod *metav1.Duration
+		expected        time.Duration
+		expectedErr     bool
+	}{
+		"no grace period by default": {},
+		"grace period of the type": {
+			typeGracePeriod: &metav1.Duration{Duration: time.Hour},
+			expected:        time.Hour,
+		},
+		"grace period of the resource replaces that of the type": {
+			annotations:     map[string]string{DeletionGracePeriodAnnotation: "10m"},
+			typeGracePeriod: &metav1.Duration{Duration: time.Hour},
+			expected:        10 * time.Minute,
+		},
+		"resource opts out of the grace period of the type": {
+			annotations:     map[string]string{DeletionGracePeriodAnnotation: "0s"},
+			typeGracePeriod: &metav1.Duration{Duration: time.Hour},
+		},
+		"invalid grace period": {
+			annotations: map[string]string{DeletionGracePeriodAnnotation: "a while"},
+			expectedErr: true,
+		},
+		"negative grace period": {
+			annotations: map[string]string{DeletionGracePeriodAnnotation: "-1m"},
+			expectedErr: true,
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			obj := &unstructured.Unstructured{}
+			obj.SetAnnotations(tc.annotations)
+
+			gracePeriod, err := GetDeletionGracePeriod(obj, tc.typeGracePeriod)
+			if tc.expectedErr {
+				if err == nil {
+					t.Fatalf("Expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if gracePeriod != tc.expected {
+				t.Fatalf("Expected grace period %v, got %v", tc.expected, gracePeriod)
+			}
+		})
+	}
+}
+
+func TestDeletionPending(t *testing.T) {
+	obj := &unstructured.Unstructured{}
+	if _, pending := DeletionPendingUntil(obj); pending || IsDeletionPending(obj) {
+		t.Fatalf("Expected deletion not to be pending")
+	}
+
+	until := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	SetDeletionPending(obj, until, `{"propagationPolicy":"Orphan"}`)
+	recorded, pending := DeletionPendingUntil(obj)
+	if !pending || !recorded.Equal(until) || !IsDeletionPending(obj) {
+		t.Fatalf("Expected deletion to be pending until %v, got %v", until, recorded)
+	}
+	opts, err := DeletionPendingOptions(obj)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	deleteOptions := &client.DeleteOptions{}
+	deleteOptions.ApplyOptions(opts)
+	if policy := deleteOptions.PropagationPolicy; policy == nil || *policy != metav1.DeletePropagationOrphan {
+		t.Fatalf("Expected the orphan propagation policy to be recorded, got %v", policy)
+	}
+
+	// Marking the resource again without options removes the
+	// recorded options.
+	SetDeletionPending(obj, until, "")
+	if opts, err := DeletionPendingOptions(obj); err != nil || len(opts) > 0 {
+		t.Fatalf("Expected no delete options to be recorded, got %v, %v", opts, err)
+	}
+
+	SetDeletionPending(obj, until, `{"propagationPolicy":"Orphan"}`)
+	RemoveDeletionPending(obj)
+	if IsDeletionPending(obj) || len(obj.GetAnnotations()) > 0 {
+		t.Fatalf("Expected the record of pending deletion to be removed, got %v", obj.GetAnnotations())
+	}
+
+	// Resources with delete options that cannot be parsed are not
+	// deleted with other options.
+	obj.SetAnnotations(map[string]string{DeletionPendingOptionsAnnotation: "orphan"})
+	if _, err := DeletionPendingOptions(obj); err == nil {
+		t.Fatalf("Expected an error for invalid delete options")
+	}
+
+	// A resource is never deleted without a valid deadline.
+	obj.SetAnnotations(map[string]string{DeletionPendingAnnotation: "soon"})
+	if _, pending := DeletionPendingUntil(obj); pending {
+		t.Fatalf("Expected deletion with an invalid deadline not to be pending")
+	}
+}
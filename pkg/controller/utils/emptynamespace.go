@@ -0,0 +1,215 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/pkg/errors"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	restclient "k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/kubefed/pkg/client/generic"
+)
+
+const (
+	// CreatedNamespaceAnnotation marks a namespace that was created
+	// in a member cluster by KubeFed rather than adopted.
+	CreatedNamespaceAnnotation      = "kubefed.io/created-namespace"
+	CreatedNamespaceAnnotationValue = "true"
+)
+
+// IsCreatedNamespace indicates whether the given namespace was created
+// by KubeFed.
+func IsCreatedNamespace(obj *unstructured.Unstructured) bool {
+	return obj.GetAnnotations()[CreatedNamespaceAnnotation] == CreatedNamespaceAnnotationValue
+}
+
+// MarkCreatedNamespace ensures that the given namespace is marked as
+// created by KubeFed.
+func MarkCreatedNamespace(obj *unstructured.Unstructured) {
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[CreatedNamespaceAnnotation] = CreatedNamespaceAnnotationValue
+	obj.SetAnnotations(annotations)
+}
+
+// StampCreatedNamespace adds the given labels and annotations to the
+// given namespace created by KubeFed. Labels and annotations that the
+// namespace already declares are left as they are, and their keys are
+// returned so that the conflict can be reported.
+func StampCreatedNamespace(obj *unstructured.Unstructured, labels, annotations map[string]string) []string {
+	var conflicts []string
+	stamp := func(stamped, declared map[string]string, kind string) map[string]string {
+		if declared == nil {
+			declared = make(map[string]string)
+		}
+		for key, value := range stamped {
+			if declaredValue, ok := declared[key]; ok {
+				if declaredValue != value {
+					conflicts = append(conflicts, fmt.Sprintf("%s %q", kind, key))
+				}
+				continue
+			}
+			declared[key] = value
+		}
+		return declared
+	}
+	if len(labels) > 0 {
+		obj.SetLabels(stamp(labels, obj.GetLabels(), "label"))
+	}
+	if len(annotations) > 0 {
+		obj.SetAnnotations(stamp(annotations, obj.GetAnnotations(), "annotation"))
+	}
+	slices.Sort(conflicts)
+	return conflicts
+}
+
+// NamespacedResourceTypes returns the listable namespaced resource
+// types served by the member cluster of the given config at their
+// preferred versions. Discovery is bounded by the given timeout, and
+// a group whose discovery fails fails the whole call so that no
+// resource goes unnoticed.
+func NamespacedResourceTypes(config *restclient.Config, timeout time.Duration) ([]metav1.APIResource, error) {
+	discoveryConfig := restclient.CopyConfig(config)
+	discoveryConfig.Timeout = timeout
+	client, err := discovery.NewDiscoveryClientForConfig(discoveryConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to create discovery client")
+	}
+	resourceLists, err := client.ServerPreferredNamespacedResources()
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to discover the namespaced resource types")
+	}
+	var resourceTypes []metav1.APIResource
+	for _, resourceList := range resourceLists {
+		groupVersion, err := schema.ParseGroupVersion(resourceList.GroupVersion)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed to parse group version %q", resourceList.GroupVersion)
+		}
+		for _, resource := range resourceList.APIResources {
+			if !slices.Contains(resource.Verbs, "list") {
+				continue
+			}
+			resource.Group = groupVersion.Group
+			resource.Version = groupVersion.Version
+			resourceTypes = append(resourceTypes, resource)
+		}
+	}
+	return resourceTypes, nil
+}
+
+// DeleteNamespaceIfEmpty deletes the named namespace with the given
+// client if it was created by KubeFed, is no longer managed and no
+// longer contains a resource of the given types, whether managed or
+// not. Resources that are being deleted are not considered, nor are
+// those that Kubernetes creates in every namespace. The namespace is
+// only deleted if it is unchanged since it was checked, so that a
+// namespace that is managed again in the meantime is retained.
+// Returns whether the namespace was deleted.
+func DeleteNamespaceIfEmpty(ctx context.Context, client generic.Client, name string, resourceTypes []metav1.APIResource) (bool, error) {
+	namespace := &unstructured.Unstructured{}
+	namespace.SetAPIVersion("v1")
+	namespace.SetKind(NamespaceKind)
+	err := client.Get(ctx, namespace, "", name)
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrapf(err, "Failed to retrieve namespace %q", name)
+	}
+	if namespace.GetDeletionTimestamp() != nil || !IsCreatedNamespace(namespace) || HasManagedLabel(namespace) {
+		return false, nil
+	}
+
+	for _, resourceType := range resourceTypes {
+		contained, err := containsResource(ctx, client, name, resourceType)
+		if err != nil {
+			return false, err
+		}
+		if contained {
+			return false, nil
+		}
+	}
+
+	err = client.Delete(ctx, namespace, "", name, runtimeclient.Preconditions{
+		UID:             ptr.To(namespace.GetUID()),
+		ResourceVersion: ptr.To(namespace.GetResourceVersion()),
+	})
+	if apierrors.IsNotFound(err) || apierrors.IsConflict(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrapf(err, "Failed to delete namespace %q", name)
+	}
+	klog.V(2).Infof("Deleted namespace %q since it no longer contains resources", name)
+	return true, nil
+}
+
+// defaultNamespaceResources are the names of the resources that
+// Kubernetes creates in every namespace keyed by their group kind.
+var defaultNamespaceResources = map[schema.GroupKind]string{
+	{Kind: "ConfigMap"}:      "kube-root-ca.crt",
+	{Kind: "ServiceAccount"}: "default",
+}
+
+// containsResource indicates whether the named namespace contains a
+// resource of the given type that is not being deleted. Events, which
+// are removed along with their namespace, and the resources that
+// Kubernetes creates in every namespace are not considered. A type
+// that is not served by the cluster has no resources.
+func containsResource(ctx context.Context, client generic.Client, namespace string, resourceType metav1.APIResource) (bool, error) {
+	if resourceType.Kind == "Event" {
+		return false, nil
+	}
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   resourceType.Group,
+		Version: resourceType.Version,
+		Kind:    resourceType.Kind + "List",
+	})
+	err := client.List(ctx, list, namespace)
+	if meta.IsNoMatchError(err) || apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrapf(err, "Failed to list %s in namespace %q", resourceType.Kind, namespace)
+	}
+	defaultName, hasDefault := defaultNamespaceResources[schema.GroupKind{Group: resourceType.Group, Kind: resourceType.Kind}]
+	for i := range list.Items {
+		item := &list.Items[i]
+		if item.GetDeletionTimestamp() != nil || (hasDefault && item.GetName() == defaultName) {
+			continue
+		}
+		return true, nil
+	}
+	return false, nil
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/kubefed/pkg/client/generic"
+	"sigs.k8s.io/kubefed/pkg/controller/utils"
+	"sigs.k8s.io/kubefed/test/common/fake"
+)
+
+// namespaceChangingClient changes the namespace it was created for
+// when the resources of the namespace are listed, like a concurrent
+// writer would between the check and the deletion of the namespace.
+type namespaceChangingClient struct {
+	generic.Client
+}
+
+func (c *namespaceChangingClient) List(ctx context.Context, obj runtimeclient.ObjectList, namespace string, opts ...runtimeclient.ListOption) error {
+	stored := newObject("v1", utils.NamespaceKind, "", "")
+	if err := c.Client.Get(ctx, stored, "", namespace); err != nil {
+		return err
+	}
+	utils.AddManagedLabel(stored)
+	if err := c.Client.Update(ctx, stored); err != nil {
+		return err
+	}
+	return c.Client.List(ctx, obj, namespace, opts...)
+}
+
+func TestDeleteNamespaceIfEmpty(t *testing.T) {
+	testCases := map[string]struct {
+		created       bool
+		managed       bool
+		object        *unstructured.Unstructured
+		objectManaged bool
+		terminating   bool
+		changed       bool
+		expected      bool
+	}{
+		"created namespace without resources is deleted": {
+			created:  true,
+			expected: true,
+		},
+		"adopted namespace is retained": {},
+		"namespace still managed is retained": {
+			created: true,
+			managed: true,
+		},
+		"namespace with a managed resource is retained": {
+			created:       true,
+			object:        newObject("v1", "ConfigMap", "ns1", "managed"),
+			objectManaged: true,
+		},
+		"namespace with an unmanaged resource is retained": {
+			created: true,
+			object:  newObject("v1", "ConfigMap", "ns1", "unmanaged"),
+		},
+		"resource being deleted is not considered": {
+			created:     true,
+			object:      newObject("v1", "ConfigMap", "ns1", "terminating"),
+			terminating: true,
+			expected:    true,
+		},
+		"resource in another namespace is not considered": {
+			created:  true,
+			object:   newObject("v1", "ConfigMap", "ns2", "other"),
+			expected: true,
+		},
+		"namespace changed since it was checked is retained": {
+			created: true,
+			changed: true,
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			ctx := context.Background()
+			var client generic.Client = fake.NewGenericClient(fake.NewStore())
+			namespace := newObject("v1", utils.NamespaceKind, "", "ns1")
+			if tc.created {
+				utils.MarkCreatedNamespace(namespace)
+			}
+			if tc.managed {
+				utils.AddManagedLabel(namespace)
+			}
+			require.NoError(t, client.Create(ctx, namespace))
+			// Resources that Kubernetes creates in every namespace,
+			// and events, do not keep it from being deleted.
+			require.NoError(t, client.Create(ctx, newObject("v1", "ConfigMap", "ns1", "kube-root-ca.crt")))
+			require.NoError(t, client.Create(ctx, newObject("v1", "ServiceAccount", "ns1", "default")))
+			require.NoError(t, client.Create(ctx, newObject("v1", "Event", "ns1", "event")))
+			if tc.object != nil {
+				if tc.objectManaged {
+					utils.AddManagedLabel(tc.object)
+				}
+				if tc.terminating {
+					now := metav1.Now()
+					tc.object.SetDeletionTimestamp(&now)
+				}
+				require.NoError(t, client.Create(ctx, tc.object))
+			}
+			if tc.changed {
+				client = &namespaceChangingClient{Client: client}
+			}
+
+			resourceTypes := []metav1.APIResource{
+				{Version: "v1", Kind: "ConfigMap", Namespaced: true},
+				{Version: "v1", Kind: "ServiceAccount", Namespaced: true},
+				{Version: "v1", Kind: "Event", Namespaced: true},
+			}
+			deleted, err := utils.DeleteNamespaceIfEmpty(ctx, client, "ns1", resourceTypes)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, deleted)
+
+			err = client.Get(ctx, newObject("v1", utils.NamespaceKind, "", ""), "", "ns1")
+			if tc.expected {
+				assert.True(t, apierrors.IsNotFound(err), "expected namespace to be deleted, got %v", err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestStampCreatedNamespace(t *testing.T) {
+	namespace := &unstructured.Unstructured{}
+	namespace.SetLabels(map[string]string{"declared": "true", "same": "true"})
+
+	conflicts := utils.StampCreatedNamespace(namespace,
+		map[string]string{"declared": "false", "same": "true", "stamped": "true"},
+		map[string]string{"stamped": "true"})
+
+	assert.Equal(t, map[string]string{"declared": "true", "same": "true", "stamped": "true"}, namespace.GetLabels())
+	assert.Equal(t, map[string]string{"stamped": "true"}, namespace.GetAnnotations())
+	assert.Equal(t, []string{`label "declared"`}, conflicts)
+}
@@ -72,6 +72,10 @@ type FederatedReadOnlyStore interface {
 	// that there may be significant delays in content updates of all kinds and write their
 	// code that it doesn't break if something is slightly out-of-sync.
 	ClustersSynced(clusters []*fedv1b1.KubeFedCluster) bool
+
+	// ClusterSynced Checks whether the store for the given cluster is
+	// there and is synced, regardless of the stores of other clusters.
+	ClusterSynced(clusterName string) bool
 }
 
 // RegisteredClustersView An interface to retrieve both KubeFedCluster resources and clients
@@ -80,6 +84,12 @@ type RegisteredClustersView interface {
 	// GetClientForCluster returns a client for the cluster, if present.
 	GetClientForCluster(clusterName string) (generic.Client, error)
 
+	// RefreshClientForCluster discards the cached client of the
+	// cluster and restarts its informer so that both are rebuilt from
+	// the current credentials of the cluster. Refreshes of a cluster
+	// more frequent than minClientRefreshInterval are ignored.
+	RefreshClientForCluster(clusterName string)
+
 	// GetUnreadyClusters returns a list of all clusters that are not ready yet.
 	GetUnreadyClusters() ([]*fedv1b1.KubeFedCluster, error)
 
@@ -132,6 +142,8 @@ type ClusterLifecycleHandlerFuncs struct {
 	// Fired when the cluster becomes unavailable. The second arg contains data that was present
 	// in the cluster before deletion.
 	ClusterUnavailable func(*fedv1b1.KubeFedCluster, []interface{})
+	// Fired when the cluster is deleted, before ClusterUnavailable.
+	ClusterRemoved func(*fedv1b1.KubeFedCluster)
 }
 
 // NewFederatedInformer Builds a FederatedInformer for the given configuration.
@@ -150,23 +162,49 @@ func NewFederatedInformer(
 		store, controller := NewManagedResourceInformer(resourceClient, targetNamespace, apiResource, triggerFunc)
 		return store, controller, nil
 	}
+	return newFederatedInformer(config, client, targetInformerFactory, clusterLifecycle)
+}
 
+// NewEndpointSliceFederatedInformer Builds a FederatedInformer for the
+// EndpointSlices of services in registered clusters. EndpointSlices
+// are not managed by KubeFed, so all those labeled with the name of a
+// service are watched.
+func NewEndpointSliceFederatedInformer(
+	config *ControllerConfig,
+	client generic.Client,
+	triggerFunc func(runtimeclient.Object),
+	clusterLifecycle *ClusterLifecycleHandlerFuncs) (FederatedInformer, error) {
+	targetInformerFactory := func(cluster *fedv1b1.KubeFedCluster, clusterConfig *restclient.Config) (cache.Store, cache.Controller, error) {
+		resourceClient, err := NewResourceClient(clusterConfig, &EndpointSliceAPIResource)
+		if err != nil {
+			return nil, nil, err
+		}
+		targetNamespace := NamespaceForCluster(cluster.Name, config.TargetNamespace)
+		store, controller := NewEndpointSliceInformer(resourceClient, targetNamespace, triggerFunc)
+		return store, controller, nil
+	}
+	return newFederatedInformer(config, client, targetInformerFactory, clusterLifecycle)
+}
+
+func newFederatedInformer(
+	config *ControllerConfig,
+	client generic.Client,
+	targetInformerFactory TargetInformerFactory,
+	clusterLifecycle *ClusterLifecycleHandlerFuncs) (FederatedInformer, error) {
 	federatedInformer := &federatedInformerImpl{
 		targetInformerFactory: targetInformerFactory,
 		configFactory: func(cluster *fedv1b1.KubeFedCluster) (*restclient.Config, error) {
-			clusterConfig, err := BuildClusterConfig(cluster, client, config.KubeFedNamespace)
+			clusterConfig, err := BuildRateLimitedClusterConfig(cluster, client, config.KubeFedNamespace, config.ClusterClientRateLimits)
 			if err != nil {
 				return nil, err
 			}
-			if clusterConfig == nil {
-				return nil, errors.Errorf("Unable to load configuration for cluster %q", cluster.Name)
-			}
 			restclient.AddUserAgent(clusterConfig, userAgentName)
 			return clusterConfig, nil
 		},
 		targetInformers: make(map[string]informer),
 		fedNamespace:    config.KubeFedNamespace,
 		clusterClients:  make(map[string]generic.Client),
+		clientRefreshes: make(map[string]time.Time),
 	}
 
 	getClusterData := func(name string) []interface{} {
@@ -193,6 +231,9 @@ func NewFederatedInformer(
 						data = getClusterData(oldCluster.Name)
 					}
 					federatedInformer.deleteCluster(oldCluster)
+					if clusterLifecycle.ClusterRemoved != nil {
+						clusterLifecycle.ClusterRemoved(oldCluster)
+					}
 					if clusterLifecycle.ClusterUnavailable != nil {
 						clusterLifecycle.ClusterUnavailable(oldCluster, data)
 					}
@@ -284,6 +325,9 @@ type federatedInformerImpl struct {
 	// Caches cluster clients (reduces client discovery and secret retrieval)
 	clusterClients map[string]generic.Client
 
+	// The times at which the clients of clusters were last refreshed
+	clientRefreshes map[string]time.Time
+
 	// Namespace from which to source KubeFedCluster resources
 	fedNamespace string
 }
@@ -343,6 +387,34 @@ func (f *federatedInformerImpl) GetClientForCluster(clusterName string) (generic
 	return client, nil
 }
 
+// RefreshClientForCluster discards the cached client of the cluster
+// and restarts its informer so that both are rebuilt from the current
+// credentials of the cluster.
+func (f *federatedInformerImpl) RefreshClientForCluster(clusterName string) {
+	f.Lock()
+	now := time.Now()
+	if lastRefresh, ok := f.clientRefreshes[clusterName]; ok && now.Sub(lastRefresh) < minClientRefreshInterval {
+		f.Unlock()
+		return
+	}
+	f.clientRefreshes[clusterName] = now
+	delete(f.clusterClients, clusterName)
+	_, restartInformer := f.targetInformers[clusterName]
+	cluster, found, err := f.getReadyClusterUnlocked(clusterName)
+	f.Unlock()
+
+	klog.Infof("Refreshing the client of cluster %q", clusterName)
+	if !restartInformer {
+		return
+	}
+	if err != nil || !found {
+		klog.Errorf("Failed to restart the informer of cluster %q: cluster not found: %v", clusterName, err)
+		return
+	}
+	f.deleteCluster(cluster)
+	f.addCluster(cluster)
+}
+
 func (f *federatedInformerImpl) getConfigForClusterUnlocked(clusterName string) (*restclient.Config, error) {
 	// No locking needed. Will happen in f.GetCluster.
 	klog.V(4).Infof("Getting config for cluster %q", clusterName)
@@ -534,6 +606,15 @@ func (fs *federatedStoreImpl) GetKeyFor(item interface{}) string {
 	return key
 }
 
+// ClusterSynced checks whether the store for the given cluster is there and is synced.
+func (fs *federatedStoreImpl) ClusterSynced(clusterName string) bool {
+	fs.federatedInformer.Lock()
+	defer fs.federatedInformer.Unlock()
+
+	targetInformer, found := fs.federatedInformer.targetInformers[clusterName]
+	return found && targetInformer.controller.HasSynced()
+}
+
 // ClustersSynced checks whether stores for all clusters form the lists (and only these) are there and
 // are synced.
 func (fs *federatedStoreImpl) ClustersSynced(clusters []*fedv1b1.KubeFedCluster) bool {
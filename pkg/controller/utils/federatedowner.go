@@ -0,0 +1,86 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	fedv1b1 "sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
+	"sigs.k8s.io/kubefed/pkg/client/generic"
+)
+
+// FindOwningFederatedObject returns the federated resource in the
+// host cluster that manages the given resource of a member cluster.
+// The federated type is determined from the FederatedTypeConfigs in
+// the given kubefed namespace, and the name of the federated
+// resource from the naming conventions of the sync controller,
+// including a target name computed from a name template. A NotFound
+// error is returned if the resource is not managed by KubeFed or if
+// the federated resource no longer exists.
+func FindOwningFederatedObject(ctx context.Context, clusterObj *unstructured.Unstructured, client generic.Client, kubefedNamespace string) (*unstructured.Unstructured, error) {
+	gvk := clusterObj.GroupVersionKind()
+	qualifiedName := NewQualifiedName(clusterObj)
+	if !HasManagedLabel(clusterObj) {
+		return nil, errors.Wrapf(notFound(gvk, qualifiedName), "%s %q is not managed by KubeFed", gvk.Kind, qualifiedName)
+	}
+
+	typeConfigList := &fedv1b1.FederatedTypeConfigList{}
+	if err := client.List(ctx, typeConfigList, kubefedNamespace); err != nil {
+		return nil, errors.Wrap(err, "Failed to list FederatedTypeConfigs")
+	}
+	var typeConfig *fedv1b1.FederatedTypeConfig
+	for i := range typeConfigList.Items {
+		targetType := typeConfigList.Items[i].GetTargetType()
+		if targetType.Group == gvk.Group && targetType.Kind == gvk.Kind {
+			typeConfig = &typeConfigList.Items[i]
+			break
+		}
+	}
+	if typeConfig == nil {
+		return nil, errors.Wrapf(notFound(gvk, qualifiedName), "Federation of %s is not enabled", gvk.Kind)
+	}
+
+	fedName := FederatedNameForEvent(clusterObj)
+	if gvk.Kind == NamespaceKind {
+		// A federated namespace is contained by its target namespace.
+		fedName.Namespace = fedName.Name
+	}
+	fedAPIResource := typeConfig.GetFederatedType()
+	fedObject := &unstructured.Unstructured{}
+	fedObject.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   fedAPIResource.Group,
+		Version: fedAPIResource.Version,
+		Kind:    fedAPIResource.Kind,
+	})
+	if err := client.Get(ctx, fedObject, fedName.Namespace, fedName.Name); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, err
+		}
+		return nil, errors.Wrapf(err, "Failed to retrieve %s %q", fedAPIResource.Kind, fedName)
+	}
+	return fedObject, nil
+}
+
+func notFound(gvk schema.GroupVersionKind, qualifiedName QualifiedName) error {
+	return apierrors.NewNotFound(schema.GroupResource{Group: gvk.Group, Resource: gvk.Kind}, qualifiedName.String())
+}
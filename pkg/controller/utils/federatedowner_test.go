@@ -0,0 +1,142 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	fedv1b1 "sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
+	"sigs.k8s.io/kubefed/pkg/controller/utils"
+	"sigs.k8s.io/kubefed/test/common/fake"
+)
+
+func newTypeConfig(name, kind string, scope apiextv1.ResourceScope) *fedv1b1.FederatedTypeConfig {
+	return &fedv1b1.FederatedTypeConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "kube-federation-system",
+			Name:      name,
+		},
+		Spec: fedv1b1.FederatedTypeConfigSpec{
+			TargetType: fedv1b1.APIResource{
+				Version:    "v1",
+				Kind:       kind,
+				PluralName: name,
+				Scope:      scope,
+			},
+			FederatedType: fedv1b1.APIResource{
+				Group:      "types.kubefed.io",
+				Version:    "v1beta1",
+				Kind:       "Federated" + kind,
+				PluralName: "federated" + name,
+				Scope:      apiextv1.NamespaceScoped,
+			},
+			Propagation: fedv1b1.PropagationEnabled,
+		},
+	}
+}
+
+func newObject(apiVersion, kind, namespace, name string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(apiVersion)
+	obj.SetKind(kind)
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	return obj
+}
+
+func TestFindOwningFederatedObject(t *testing.T) {
+	ctx := context.Background()
+	client := fake.NewGenericClient(fake.NewStore())
+	require.NoError(t, client.Create(ctx, newTypeConfig("configmaps", "ConfigMap", apiextv1.NamespaceScoped)))
+	require.NoError(t, client.Create(ctx, newTypeConfig("namespaces", "Namespace", apiextv1.ClusterScoped)))
+	for _, fedObject := range []*unstructured.Unstructured{
+		newObject("types.kubefed.io/v1beta1", "FederatedConfigMap", "ns1", "cm1"),
+		newObject("types.kubefed.io/v1beta1", "FederatedConfigMap", "ns1", "templated"),
+		newObject("types.kubefed.io/v1beta1", "FederatedNamespace", "ns1", "ns1"),
+	} {
+		require.NoError(t, client.Create(ctx, fedObject))
+	}
+
+	testCases := map[string]struct {
+		clusterObj    *unstructured.Unstructured
+		managed       bool
+		annotations   map[string]string
+		expectedKind  string
+		expectedName  utils.QualifiedName
+		expectedFound bool
+	}{
+		"managed resource": {
+			clusterObj:    newObject("v1", "ConfigMap", "ns1", "cm1"),
+			managed:       true,
+			expectedKind:  "FederatedConfigMap",
+			expectedName:  utils.QualifiedName{Namespace: "ns1", Name: "cm1"},
+			expectedFound: true,
+		},
+		"managed resource named from a template": {
+			clusterObj:    newObject("v1", "ConfigMap", "ns1", "cm1-cluster1"),
+			managed:       true,
+			annotations:   map[string]string{utils.FederatedNameAnnotation: "templated"},
+			expectedKind:  "FederatedConfigMap",
+			expectedName:  utils.QualifiedName{Namespace: "ns1", Name: "templated"},
+			expectedFound: true,
+		},
+		"managed namespace": {
+			clusterObj:    newObject("v1", "Namespace", "", "ns1"),
+			managed:       true,
+			expectedKind:  "FederatedNamespace",
+			expectedName:  utils.QualifiedName{Namespace: "ns1", Name: "ns1"},
+			expectedFound: true,
+		},
+		"unmanaged resource": {
+			clusterObj: newObject("v1", "ConfigMap", "ns1", "cm1"),
+		},
+		"managed resource whose federated resource was removed": {
+			clusterObj: newObject("v1", "ConfigMap", "ns1", "cm2"),
+			managed:    true,
+		},
+		"managed resource of a type that is not enabled": {
+			clusterObj: newObject("v1", "Secret", "ns1", "cm1"),
+			managed:    true,
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			if tc.managed {
+				utils.AddManagedLabel(tc.clusterObj)
+			}
+			tc.clusterObj.SetAnnotations(tc.annotations)
+
+			fedObject, err := utils.FindOwningFederatedObject(ctx, tc.clusterObj, client, "kube-federation-system")
+			if !tc.expectedFound {
+				assert.True(t, apierrors.IsNotFound(err), "Expected a NotFound error, got %v", err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedKind, fedObject.GetKind())
+			assert.Equal(t, tc.expectedName, utils.NewQualifiedName(fedObject))
+		})
+	}
+}
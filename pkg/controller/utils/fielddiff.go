@@ -0,0 +1,219 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ChangeType indicates how an element differs between two revisions
+// of a resource.
+type ChangeType string
+
+const (
+	ChangeAdded    ChangeType = "Added"
+	ChangeRemoved  ChangeType = "Removed"
+	ChangeModified ChangeType = "Modified"
+)
+
+// FieldChange describes a change to a field identified by a JSON
+// pointer. Lists are compared as a whole. Old is nil for an added
+// field and New is nil for a removed field.
+type FieldChange struct {
+	Path string
+	Type ChangeType
+	Old  interface{}
+	New  interface{}
+}
+
+// DiffFields returns the changes between the given fields, ordered by
+// path.
+func DiffFields(oldFields, newFields map[string]interface{}) []FieldChange {
+	var changes []FieldChange
+	diffFields("", oldFields, newFields, &changes)
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+	return changes
+}
+
+// FormatChangedPaths returns a concise description of the given
+// changes that includes their paths but not their values, which may
+// be sensitive.
+func FormatChangedPaths(changes []FieldChange) string {
+	descriptions := make([]string, 0, len(changes))
+	for _, change := range changes {
+		var prefix string
+		switch change.Type {
+		case ChangeAdded:
+			prefix = "+"
+		case ChangeRemoved:
+			prefix = "-"
+		default:
+			prefix = "~"
+		}
+		descriptions = append(descriptions, prefix+change.Path)
+	}
+	return strings.Join(descriptions, ", ")
+}
+
+// RedactFieldChanges returns the given changes without the values of
+// the fields at the given paths, e.g. those set from a Secret. Changes
+// below one of the paths, whose paths could reveal the keys of a
+// value, are reported as a single modification of the path, and the
+// values of changes to fields containing one of the paths are removed.
+func RedactFieldChanges(changes []FieldChange, redactedPaths []string) []FieldChange {
+	if len(redactedPaths) == 0 {
+		return changes
+	}
+	redacted := make([]FieldChange, 0, len(changes))
+	collapsedPaths := make(map[string]bool)
+	for _, change := range changes {
+		for _, path := range redactedPaths {
+			switch {
+			case change.Path == path:
+				change = FieldChange{Path: path, Type: change.Type}
+			case strings.HasPrefix(change.Path, path+"/"):
+				change = FieldChange{Path: path, Type: ChangeModified}
+			case strings.HasPrefix(path, change.Path+"/"):
+				change.Old, change.New = nil, nil
+			}
+		}
+		if collapsedPaths[change.Path] {
+			continue
+		}
+		collapsedPaths[change.Path] = true
+		redacted = append(redacted, change)
+	}
+	return redacted
+}
+
+// FormatFieldChanges returns a description of the given changes that
+// includes their values, one change per line. It is intended for
+// debugging since the values may be sensitive.
+func FormatFieldChanges(changes []FieldChange) string {
+	descriptions := make([]string, 0, len(changes))
+	for _, change := range changes {
+		switch change.Type {
+		case ChangeAdded:
+			descriptions = append(descriptions, fmt.Sprintf("+%s: %s", change.Path, formatFieldValue(change.New)))
+		case ChangeRemoved:
+			descriptions = append(descriptions, fmt.Sprintf("-%s: %s", change.Path, formatFieldValue(change.Old)))
+		default:
+			descriptions = append(descriptions, fmt.Sprintf("~%s: %s -> %s", change.Path, formatFieldValue(change.Old), formatFieldValue(change.New)))
+		}
+	}
+	return strings.Join(descriptions, "\n")
+}
+
+func formatFieldValue(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
+
+func diffFields(path string, oldFields, newFields map[string]interface{}, changes *[]FieldChange) {
+	for key, oldValue := range oldFields {
+		fieldPath := path + "/" + escapeJSONPointerToken(key)
+		newValue, ok := newFields[key]
+		if !ok {
+			*changes = append(*changes, FieldChange{Path: fieldPath, Type: ChangeRemoved, Old: oldValue})
+			continue
+		}
+		oldMap, oldIsMap := oldValue.(map[string]interface{})
+		newMap, newIsMap := newValue.(map[string]interface{})
+		if oldIsMap && newIsMap {
+			diffFields(fieldPath, oldMap, newMap, changes)
+			continue
+		}
+		if !reflect.DeepEqual(oldValue, newValue) {
+			*changes = append(*changes, FieldChange{Path: fieldPath, Type: ChangeModified, Old: oldValue, New: newValue})
+		}
+	}
+	for key, newValue := range newFields {
+		if _, ok := oldFields[key]; !ok {
+			fieldPath := path + "/" + escapeJSONPointerToken(key)
+			*changes = append(*changes, FieldChange{Path: fieldPath, Type: ChangeAdded, New: newValue})
+		}
+	}
+}
+
+// DiffDesiredFields returns the changes required for the given actual
+// fields to contain the given desired fields, ordered by path. Unlike
+// DiffFields, fields that are absent from the desired fields are
+// ignored since they may be defaulted or retained, and lists of the
+// same length are compared item by item so that defaulted fields of
+// their items are also ignored.
+func DiffDesiredFields(desiredFields, actualFields map[string]interface{}) []FieldChange {
+	var changes []FieldChange
+	diffDesiredValue("", desiredFields, actualFields, &changes)
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+	return changes
+}
+
+func diffDesiredValue(path string, desired, actual interface{}, changes *[]FieldChange) {
+	switch desiredValue := desired.(type) {
+	case map[string]interface{}:
+		actualValue, ok := actual.(map[string]interface{})
+		if !ok {
+			if len(desiredValue) > 0 || actual != nil {
+				*changes = append(*changes, FieldChange{Path: path, Type: ChangeModified, Old: actual, New: desired})
+			}
+			return
+		}
+		for key, desiredItem := range desiredValue {
+			fieldPath := path + "/" + escapeJSONPointerToken(key)
+			actualItem, ok := actualValue[key]
+			if !ok {
+				if desiredItem != nil {
+					*changes = append(*changes, FieldChange{Path: fieldPath, Type: ChangeAdded, New: desiredItem})
+				}
+				continue
+			}
+			diffDesiredValue(fieldPath, desiredItem, actualItem, changes)
+		}
+	case []interface{}:
+		actualValue, ok := actual.([]interface{})
+		if !ok || len(actualValue) != len(desiredValue) {
+			if len(desiredValue) > 0 || actual != nil {
+				*changes = append(*changes, FieldChange{Path: path, Type: ChangeModified, Old: actual, New: desired})
+			}
+			return
+		}
+		for i := range desiredValue {
+			diffDesiredValue(fmt.Sprintf("%s/%d", path, i), desiredValue[i], actualValue[i], changes)
+		}
+	case nil:
+	default:
+		if !reflect.DeepEqual(desired, actual) {
+			*changes = append(*changes, FieldChange{Path: path, Type: ChangeModified, Old: actual, New: desired})
+		}
+	}
+}
+
+func escapeJSONPointerToken(token string) string {
+	token = strings.ReplaceAll(token, "~", "~0")
+	return strings.ReplaceAll(token, "/", "~1")
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffDesiredFields(t *testing.T) {
+	testCases := map[string]struct {
+		desired  map[string]interface{}
+		actual   map[string]interface{}
+		expected []FieldChange
+	}{
+		"fields absent from the desired fields are ignored": {
+			desired: map[string]interface{}{
+				"data": map[string]interface{}{"key": "value"},
+			},
+			actual: map[string]interface{}{
+				"data":   map[string]interface{}{"key": "value", "defaulted": "value"},
+				"status": map[string]interface{}{"ready": true},
+			},
+		},
+		"fields added and modified": {
+			desired: map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"labels": map[string]interface{}{"example.io/app": "foo", "tier": "web"},
+				},
+			},
+			actual: map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"labels": map[string]interface{}{"example.io/app": "bar"},
+				},
+			},
+			expected: []FieldChange{
+				{Path: "/metadata/labels/example.io~1app", Type: ChangeModified, Old: "bar", New: "foo"},
+				{Path: "/metadata/labels/tier", Type: ChangeAdded, New: "web"},
+			},
+		},
+		"list items of the same length are compared by index": {
+			desired: map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{"name": "app", "image": "app:v2"},
+					},
+				},
+			},
+			actual: map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{"name": "app", "image": "app:v1", "imagePullPolicy": "Always"},
+					},
+				},
+			},
+			expected: []FieldChange{
+				{Path: "/spec/containers/0/image", Type: ChangeModified, Old: "app:v1", New: "app:v2"},
+			},
+		},
+		"lists of different lengths are compared as a whole": {
+			desired: map[string]interface{}{"args": []interface{}{"a"}},
+			actual:  map[string]interface{}{"args": []interface{}{"a", "b"}},
+			expected: []FieldChange{
+				{Path: "/args", Type: ChangeModified, Old: []interface{}{"a", "b"}, New: []interface{}{"a"}},
+			},
+		},
+		"null desired fields are ignored": {
+			desired: map[string]interface{}{"data": nil},
+			actual:  map[string]interface{}{},
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			changes := DiffDesiredFields(tc.desired, tc.actual)
+			if !reflect.DeepEqual(tc.expected, changes) {
+				t.Fatalf("Expected %#v, got %#v", tc.expected, changes)
+			}
+		})
+	}
+}
+
+func TestFormatFieldChanges(t *testing.T) {
+	expected := map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "foo"},
+		"data":     map[string]interface{}{"key": "value", "other": "value"},
+	}
+	actual := map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "foo"},
+		"data":     map[string]interface{}{"key": "overridden", "other": "value"},
+	}
+	changes := DiffFields(actual, expected)
+	if len(changes) != 1 {
+		t.Fatalf("Expected a single changed field, got %v", changes)
+	}
+	if formatted, expected := FormatFieldChanges(changes), `~/data/key: "overridden" -> "value"`; formatted != expected {
+		t.Fatalf("Expected %q, got %q", expected, formatted)
+	}
+
+	changes = []FieldChange{
+		{Path: "/spec/replicas", Type: ChangeAdded, New: int64(3)},
+		{Path: "/spec/paused", Type: ChangeRemoved, Old: true},
+	}
+	if formatted, expected := FormatFieldChanges(changes), "+/spec/replicas: 3\n-/spec/paused: true"; formatted != expected {
+		t.Fatalf("Expected %q, got %q", expected, formatted)
+	}
+}
+
+func TestRedactFieldChanges(t *testing.T) {
+	changes := []FieldChange{
+		{Path: "/data", Type: ChangeAdded, New: map[string]interface{}{"password": "s3cr3t"}},
+		{Path: "/spec/credentials/token", Type: ChangeModified, Old: "old", New: "new"},
+		{Path: "/spec/credentials/user", Type: ChangeAdded, New: "admin"},
+		{Path: "/spec/replicas", Type: ChangeModified, Old: int64(1), New: int64(3)},
+	}
+	expected := []FieldChange{
+		{Path: "/data", Type: ChangeAdded},
+		{Path: "/spec/credentials", Type: ChangeModified},
+		{Path: "/spec/replicas", Type: ChangeModified, Old: int64(1), New: int64(3)},
+	}
+	redacted := RedactFieldChanges(changes, []string{"/data/password", "/spec/credentials"})
+	if !reflect.DeepEqual(redacted, expected) {
+		t.Fatalf("Expected %#v, got %#v", expected, redacted)
+	}
+}
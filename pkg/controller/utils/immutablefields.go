@@ -0,0 +1,128 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"strings"
+
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// knownImmutableFields are the JSON pointer paths of the fields of
+// built-in types that cannot be changed once a resource is created.
+// Types defined by a CRD instead declare immutable fields in their
+// schema.
+var knownImmutableFields = map[schema.GroupKind][]string{
+	{Kind: "PersistentVolumeClaim"}: {
+		"/spec/accessModes",
+		"/spec/dataSource",
+		"/spec/dataSourceRef",
+		"/spec/selector",
+		"/spec/storageClassName",
+		"/spec/volumeMode",
+		"/spec/volumeName",
+	},
+	{Kind: "Secret"}: {
+		"/type",
+	},
+	{Kind: "Service"}: {
+		"/spec/clusterIP",
+		"/spec/clusterIPs",
+	},
+	{Group: "apps", Kind: "DaemonSet"}: {
+		"/spec/selector",
+	},
+	{Group: "apps", Kind: "Deployment"}: {
+		"/spec/selector",
+	},
+	{Group: "apps", Kind: "ReplicaSet"}: {
+		"/spec/selector",
+	},
+	{Group: "apps", Kind: "StatefulSet"}: {
+		"/spec/podManagementPolicy",
+		"/spec/selector",
+		"/spec/serviceName",
+		"/spec/volumeClaimTemplates",
+	},
+	{Group: "batch", Kind: "Job"}: {
+		"/spec/completionMode",
+		"/spec/selector",
+		"/spec/template",
+	},
+}
+
+// ImmutableOverridePaths returns the paths of the given overrides that
+// target an immutable field of the target type. Overrides that only
+// test a value are ignored.
+func ImmutableOverridePaths(overrides ClusterOverrides, targetKind schema.GroupKind, targetSchema *apiextv1.JSONSchemaProps) []string {
+	var paths []string
+	for _, override := range overrides {
+		if override.Op != "test" && IsImmutableField(targetKind, targetSchema, override.Path) {
+			paths = append(paths, override.Path)
+		}
+	}
+	return paths
+}
+
+// IsImmutableField indicates whether the field at the given JSON
+// pointer path, or one of the fields containing it, cannot be changed
+// once a resource of the given kind is created. A field is immutable if
+// it is known to be immutable for a built-in kind, or if the given
+// schema declares a `self == oldSelf` validation rule for it.
+func IsImmutableField(targetKind schema.GroupKind, targetSchema *apiextv1.JSONSchemaProps, path string) bool {
+	for _, immutablePath := range knownImmutableFields[targetKind] {
+		if path == immutablePath || strings.HasPrefix(path, immutablePath+"/") {
+			return true
+		}
+	}
+	if targetSchema == nil || !strings.HasPrefix(path, "/") {
+		return false
+	}
+	current := targetSchema
+	for _, token := range strings.Split(path[1:], "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		switch current.Type {
+		case "array":
+			if current.Items == nil || current.Items.Schema == nil {
+				return false
+			}
+			current = current.Items.Schema
+		default:
+			propSchema, known := fieldSchema(current, token)
+			if !known || propSchema == nil {
+				return false
+			}
+			current = propSchema
+		}
+		if hasImmutabilityRule(current) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasImmutabilityRule indicates whether the given schema declares a
+// transition rule that prevents its value from being changed.
+func hasImmutabilityRule(fieldSchema *apiextv1.JSONSchemaProps) bool {
+	for _, validation := range fieldSchema.XValidations {
+		if strings.Join(strings.Fields(validation.Rule), "") == "self==oldSelf" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestIsImmutableField(t *testing.T) {
+	immutable := apiextv1.ValidationRules{{Rule: "self == oldSelf", Message: "Value is immutable"}}
+	targetSchema := &apiextv1.JSONSchemaProps{
+		Type: "object",
+		Properties: map[string]apiextv1.JSONSchemaProps{
+			"spec": {
+				Type: "object",
+				Properties: map[string]apiextv1.JSONSchemaProps{
+					"region": {Type: "string", XValidations: immutable},
+					"size":   {Type: "integer"},
+					"network": {
+						Type:         "object",
+						XValidations: immutable,
+						Properties: map[string]apiextv1.JSONSchemaProps{
+							"cidr": {Type: "string"},
+						},
+					},
+					"volumes": {
+						Type: "array",
+						Items: &apiextv1.JSONSchemaPropsOrArray{
+							Schema: &apiextv1.JSONSchemaProps{
+								Type: "object",
+								Properties: map[string]apiextv1.JSONSchemaProps{
+									"name": {Type: "string", XValidations: apiextv1.ValidationRules{{Rule: "self==oldSelf"}}},
+									"size": {Type: "integer", XValidations: apiextv1.ValidationRules{{Rule: "self >= oldSelf"}}},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	deployment := schema.GroupKind{Group: "apps", Kind: "Deployment"}
+	widget := schema.GroupKind{Group: "example.io", Kind: "Widget"}
+	testCases := map[string]struct {
+		targetKind schema.GroupKind
+		schema     *apiextv1.JSONSchemaProps
+		path       string
+		expected   bool
+	}{
+		"known immutable field": {
+			targetKind: deployment,
+			path:       "/spec/selector",
+			expected:   true,
+		},
+		"field of a known immutable field": {
+			targetKind: deployment,
+			path:       "/spec/selector/matchLabels/app",
+			expected:   true,
+		},
+		"field sharing a prefix with a known immutable field": {
+			targetKind: deployment,
+			path:       "/spec/selectorPolicy",
+		},
+		"known immutable field of another kind": {
+			targetKind: schema.GroupKind{Group: "apps", Kind: "StatefulSet"},
+			path:       "/spec/replicas",
+		},
+		"field with an immutability rule": {
+			targetKind: widget,
+			schema:     targetSchema,
+			path:       "/spec/region",
+			expected:   true,
+		},
+		"field of a field with an immutability rule": {
+			targetKind: widget,
+			schema:     targetSchema,
+			path:       "/spec/network/cidr",
+			expected:   true,
+		},
+		"field of an array item with an immutability rule": {
+			targetKind: widget,
+			schema:     targetSchema,
+			path:       "/spec/volumes/0/name",
+			expected:   true,
+		},
+		"field with another transition rule": {
+			targetKind: widget,
+			schema:     targetSchema,
+			path:       "/spec/volumes/0/size",
+		},
+		"field without a rule": {
+			targetKind: widget,
+			schema:     targetSchema,
+			path:       "/spec/size",
+		},
+		"field unknown to the schema": {
+			targetKind: widget,
+			schema:     targetSchema,
+			path:       "/spec/unknown",
+		},
+		"field without a schema": {
+			targetKind: widget,
+			path:       "/spec/region",
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			assert.Equal(t, tc.expected, IsImmutableField(tc.targetKind, tc.schema, tc.path))
+		})
+	}
+}
+
+func TestImmutableOverridePaths(t *testing.T) {
+	pvc := schema.GroupKind{Kind: "PersistentVolumeClaim"}
+	testCases := map[string]struct {
+		override      ClusterOverride
+		expectedPaths []string
+	}{
+		"override of an immutable field is reported": {
+			override:      ClusterOverride{Path: "/spec/storageClassName", Value: "fast"},
+			expectedPaths: []string{"/spec/storageClassName"},
+		},
+		"removal of an immutable field is reported": {
+			override:      ClusterOverride{Op: "remove", Path: "/spec/storageClassName"},
+			expectedPaths: []string{"/spec/storageClassName"},
+		},
+		"test of an immutable field is not reported": {
+			override: ClusterOverride{Op: "test", Path: "/spec/storageClassName", Value: "fast"},
+		},
+		"override of a mutable field is not reported": {
+			override: ClusterOverride{Path: "/spec/resources/requests/storage", Value: "10Gi"},
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			assert.Equal(t, tc.expectedPaths, ImmutableOverridePaths(ClusterOverrides{tc.override}, pvc, nil))
+		})
+	}
+}
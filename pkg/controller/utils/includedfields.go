@@ -0,0 +1,52 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// ApplyIncludedFields returns a copy of the cluster object with the
+// given dot-separated field paths set to their values in the desired
+// object. Fields absent from the desired object are removed. All
+// other fields of the cluster object are left untouched so that they
+// can be managed by other controllers. The managed label is ensured
+// on the returned object.
+func ApplyIncludedFields(desiredObj, clusterObj *unstructured.Unstructured, includedFields []string) (*unstructured.Unstructured, error) {
+	obj := clusterObj.DeepCopy()
+	for _, path := range includedFields {
+		fields := strings.Split(path, ".")
+		value, found, err := unstructured.NestedFieldNoCopy(desiredObj.Object, fields...)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to retrieve included field %q", path)
+		}
+		if !found {
+			unstructured.RemoveNestedField(obj.Object, fields...)
+			continue
+		}
+		if err := unstructured.SetNestedField(obj.Object, runtime.DeepCopyJSONValue(value), fields...); err != nil {
+			return nil, errors.Wrapf(err, "failed to set included field %q", path)
+		}
+	}
+	AddManagedLabel(obj)
+	return obj, nil
+}
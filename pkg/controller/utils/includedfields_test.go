@@ -0,0 +1,70 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestApplyIncludedFields(t *testing.T) {
+	clusterObj := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"name": "foo",
+			},
+			"spec": map[string]interface{}{
+				"replicas": int64(1),
+				"paused":   true,
+				"strategy": "Recreate",
+			},
+		},
+	}
+	desiredObj := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"name": "foo",
+			},
+			"spec": map[string]interface{}{
+				"replicas": int64(3),
+				"strategy": "RollingUpdate",
+			},
+		},
+	}
+
+	obj, err := ApplyIncludedFields(desiredObj, clusterObj, []string{"spec.replicas", "spec.paused"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expectedSpec := map[string]interface{}{
+		"replicas": int64(3),
+		"strategy": "Recreate",
+	}
+	spec, _, _ := unstructured.NestedMap(obj.Object, "spec")
+	if !reflect.DeepEqual(spec, expectedSpec) {
+		t.Fatalf("Expected spec %v, got %v", expectedSpec, spec)
+	}
+	if !HasManagedLabel(obj) {
+		t.Fatalf("Expected the managed label to be set")
+	}
+	if paused, _, _ := unstructured.NestedBool(clusterObj.Object, "spec", "paused"); !paused {
+		t.Fatalf("Expected the cluster object to be unmodified")
+	}
+}
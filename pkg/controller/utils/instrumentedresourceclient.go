@@ -0,0 +1,153 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+
+	"sigs.k8s.io/kubefed/pkg/metrics"
+)
+
+// instrumentedResourceClient decorates a ResourceClient to record the
+// number, result and latency of the requests made through it.
+type instrumentedResourceClient struct {
+	client   ResourceClient
+	resource string
+}
+
+// NewInstrumentedResourceClient returns a ResourceClient that records
+// metrics for requests made with the given client, labeled with the
+// given resource name.
+func NewInstrumentedResourceClient(client ResourceClient, resource string) ResourceClient {
+	return &instrumentedResourceClient{
+		client:   client,
+		resource: resource,
+	}
+}
+
+func (c *instrumentedResourceClient) Resources(namespace string) dynamic.ResourceInterface {
+	return &instrumentedResourceInterface{
+		client:   c.client.Resources(namespace),
+		resource: c.resource,
+	}
+}
+
+func (c *instrumentedResourceClient) Kind() string {
+	return c.client.Kind()
+}
+
+type instrumentedResourceInterface struct {
+	client   dynamic.ResourceInterface
+	resource string
+}
+
+func (c *instrumentedResourceInterface) record(verb string, start time.Time, err error) {
+	result := labelSuccess
+	if err != nil {
+		result = labelError
+		if reason := apierrors.ReasonForError(err); reason != metav1.StatusReasonUnknown {
+			result = string(reason)
+		}
+	}
+	metrics.ResourceClientRequestsTotal.WithLabelValues(verb, c.resource, result).Inc()
+	metrics.ResourceClientRequestDuration.WithLabelValues(verb, c.resource).Observe(time.Since(start).Seconds())
+}
+
+func (c *instrumentedResourceInterface) Create(ctx context.Context, obj *unstructured.Unstructured, options metav1.CreateOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	start := time.Now()
+	result, err := c.client.Create(ctx, obj, options, subresources...)
+	c.record("create", start, err)
+	return result, err
+}
+
+func (c *instrumentedResourceInterface) Update(ctx context.Context, obj *unstructured.Unstructured, options metav1.UpdateOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	start := time.Now()
+	result, err := c.client.Update(ctx, obj, options, subresources...)
+	c.record("update", start, err)
+	return result, err
+}
+
+func (c *instrumentedResourceInterface) UpdateStatus(ctx context.Context, obj *unstructured.Unstructured, options metav1.UpdateOptions) (*unstructured.Unstructured, error) {
+	start := time.Now()
+	result, err := c.client.UpdateStatus(ctx, obj, options)
+	c.record("update_status", start, err)
+	return result, err
+}
+
+func (c *instrumentedResourceInterface) Delete(ctx context.Context, name string, options metav1.DeleteOptions, subresources ...string) error {
+	start := time.Now()
+	err := c.client.Delete(ctx, name, options, subresources...)
+	c.record("delete", start, err)
+	return err
+}
+
+func (c *instrumentedResourceInterface) DeleteCollection(ctx context.Context, options metav1.DeleteOptions, listOptions metav1.ListOptions) error {
+	start := time.Now()
+	err := c.client.DeleteCollection(ctx, options, listOptions)
+	c.record("delete_collection", start, err)
+	return err
+}
+
+func (c *instrumentedResourceInterface) Get(ctx context.Context, name string, options metav1.GetOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	start := time.Now()
+	result, err := c.client.Get(ctx, name, options, subresources...)
+	c.record("get", start, err)
+	return result, err
+}
+
+func (c *instrumentedResourceInterface) List(ctx context.Context, opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+	start := time.Now()
+	result, err := c.client.List(ctx, opts)
+	c.record("list", start, err)
+	return result, err
+}
+
+func (c *instrumentedResourceInterface) Watch(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error) {
+	start := time.Now()
+	result, err := c.client.Watch(ctx, opts)
+	c.record("watch", start, err)
+	return result, err
+}
+
+func (c *instrumentedResourceInterface) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, options metav1.PatchOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	start := time.Now()
+	result, err := c.client.Patch(ctx, name, pt, data, options, subresources...)
+	c.record("patch", start, err)
+	return result, err
+}
+
+func (c *instrumentedResourceInterface) Apply(ctx context.Context, name string, obj *unstructured.Unstructured, options metav1.ApplyOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	start := time.Now()
+	result, err := c.client.Apply(ctx, name, obj, options, subresources...)
+	c.record("apply", start, err)
+	return result, err
+}
+
+func (c *instrumentedResourceInterface) ApplyStatus(ctx context.Context, name string, obj *unstructured.Unstructured, options metav1.ApplyOptions) (*unstructured.Unstructured, error) {
+	start := time.Now()
+	result, err := c.client.ApplyStatus(ctx, name, obj, options)
+	c.record("apply_status", start, err)
+	return result, err
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+
+	"sigs.k8s.io/kubefed/pkg/metrics"
+)
+
+func TestInstrumentedResourceClient(t *testing.T) {
+	gvr := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("v1")
+	obj.SetKind("ConfigMap")
+	obj.SetNamespace("ns")
+	obj.SetName("foo")
+
+	dynamicClient := fake.NewSimpleDynamicClient(runtime.NewScheme(), obj)
+	client := NewInstrumentedResourceClient(&resourceClient{
+		client:      dynamicClient,
+		apiResource: gvr,
+		namespaced:  true,
+		kind:        "ConfigMap",
+	}, gvr.GroupResource().String())
+	resource := gvr.GroupResource().String()
+
+	count := func(verb, result string) float64 {
+		return testutil.ToFloat64(metrics.ResourceClientRequestsTotal.WithLabelValues(verb, resource, result))
+	}
+	before := map[string]float64{
+		"get":      count("get", labelSuccess),
+		"update":   count("update", labelSuccess),
+		"delete":   count("delete", labelSuccess),
+		"notFound": count("get", string(metav1.StatusReasonNotFound)),
+	}
+
+	ctx := context.Background()
+	resources := client.Resources("ns")
+	current, err := resources.Get(ctx, "foo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error getting object: %v", err)
+	}
+	if _, err := resources.Update(ctx, current, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("Unexpected error updating object: %v", err)
+	}
+	if err := resources.Delete(ctx, "foo", metav1.DeleteOptions{}); err != nil {
+		t.Fatalf("Unexpected error deleting object: %v", err)
+	}
+	if _, err := resources.Get(ctx, "foo", metav1.GetOptions{}); err == nil {
+		t.Fatalf("Expected an error getting a deleted object")
+	}
+
+	testCases := map[string]struct {
+		verb   string
+		result string
+		key    string
+	}{
+		"successful get":    {verb: "get", result: labelSuccess, key: "get"},
+		"successful update": {verb: "update", result: labelSuccess, key: "update"},
+		"successful delete": {verb: "delete", result: labelSuccess, key: "delete"},
+		"not found get":     {verb: "get", result: string(metav1.StatusReasonNotFound), key: "notFound"},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			if delta := count(tc.verb, tc.result) - before[tc.key]; delta != 1 {
+				t.Errorf("Expected counter to increment by 1, got %v", delta)
+			}
+		})
+	}
+
+	if samples := testutil.CollectAndCount(metrics.ResourceClientRequestDuration); samples == 0 {
+		t.Errorf("Expected request latency to be observed")
+	}
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"sync"
+)
+
+// KeyedMutex is a mutual exclusion lock per key. The lock of a key is
+// only retained while it is held or waited for. The zero value is an
+// unlocked mutex for every key.
+type KeyedMutex struct {
+	lock  sync.Mutex
+	locks map[string]*keyedMutexEntry
+}
+
+type keyedMutexEntry struct {
+	sync.Mutex
+	// The number of callers holding or waiting for the lock.
+	refs int
+}
+
+// Lock locks the given key, blocking until it is available.
+func (m *KeyedMutex) Lock(key string) {
+	m.lock.Lock()
+	if m.locks == nil {
+		m.locks = make(map[string]*keyedMutexEntry)
+	}
+	entry, ok := m.locks[key]
+	if !ok {
+		entry = &keyedMutexEntry{}
+		m.locks[key] = entry
+	}
+	entry.refs++
+	m.lock.Unlock()
+
+	entry.Lock()
+}
+
+// Unlock unlocks the given key, which must be locked.
+func (m *KeyedMutex) Unlock(key string) {
+	m.lock.Lock()
+	entry := m.locks[key]
+	entry.refs--
+	if entry.refs == 0 {
+		delete(m.locks, key)
+	}
+	m.lock.Unlock()
+
+	entry.Unlock()
+}
@@ -0,0 +1,55 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+func TestKeyedMutex(t *testing.T) {
+	var m KeyedMutex
+
+	m.Lock("foo")
+	// Other keys are not blocked.
+	m.Lock("bar")
+	m.Unlock("bar")
+
+	locked := make(chan struct{})
+	go func() {
+		m.Lock("foo")
+		close(locked)
+	}()
+	select {
+	case <-locked:
+		t.Fatalf("Expected the key to remain locked")
+	case <-time.After(50 * time.Millisecond):
+	}
+	m.Unlock("foo")
+	select {
+	case <-locked:
+	case <-time.After(wait.ForeverTestTimeout):
+		t.Fatalf("Expected the key to be locked once unlocked")
+	}
+	m.Unlock("foo")
+
+	if len(m.locks) != 0 {
+		t.Fatalf("Expected no locks to be retained, got %d", len(m.locks))
+	}
+}
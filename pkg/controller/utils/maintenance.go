@@ -0,0 +1,35 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	fedv1b1 "sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
+)
+
+const (
+	// MaintenanceAnnotation If this annotation is present on a
+	// KubeFedCluster, the sync controller will neither create, update
+	// nor delete resources in the cluster until it is removed.
+	MaintenanceAnnotation = "kubefed.io/maintenance"
+	MaintenanceValue      = "true"
+)
+
+// IsClusterInMaintenance checks whether propagation to the given
+// cluster has been paused for maintenance.
+func IsClusterInMaintenance(cluster *fedv1b1.KubeFedCluster) bool {
+	return cluster.GetAnnotations()[MaintenanceAnnotation] == MaintenanceValue
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fedv1b1 "sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
+)
+
+func TestIsClusterInMaintenance(t *testing.T) {
+	testCases := map[string]struct {
+		annotations map[string]string
+		expected    bool
+	}{
+		"not in maintenance without annotations": {
+			expected: false,
+		},
+		"not in maintenance with an unexpected value": {
+			annotations: map[string]string{
+				MaintenanceAnnotation: "false",
+			},
+			expected: false,
+		},
+		"in maintenance": {
+			annotations: map[string]string{
+				MaintenanceAnnotation: MaintenanceValue,
+			},
+			expected: true,
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			cluster := &fedv1b1.KubeFedCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "cluster1",
+					Annotations: tc.annotations,
+				},
+			}
+			if actual := IsClusterInMaintenance(cluster); actual != tc.expected {
+				t.Fatalf("Expected %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+}
@@ -17,7 +17,10 @@ limitations under the License.
 package utils
 
 import (
+	"strings"
+
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/sets"
 )
 
 const (
@@ -57,6 +60,50 @@ func AddManagedLabel(obj *unstructured.Unstructured) {
 	obj.SetLabels(labels)
 }
 
+// AddManagedMetadata ensures that the given object has the managed
+// label and the given labels and annotations. The managed label takes
+// precedence over a label with the same key.
+func AddManagedMetadata(obj *unstructured.Unstructured, managedLabels, managedAnnotations map[string]string) {
+	if len(managedLabels) > 0 {
+		labels := obj.GetLabels()
+		if labels == nil {
+			labels = make(map[string]string)
+		}
+		for key, value := range managedLabels {
+			labels[key] = value
+		}
+		obj.SetLabels(labels)
+	}
+	if len(managedAnnotations) > 0 {
+		annotations := obj.GetAnnotations()
+		if annotations == nil {
+			annotations = make(map[string]string)
+		}
+		for key, value := range managedAnnotations {
+			annotations[key] = value
+		}
+		obj.SetAnnotations(annotations)
+	}
+	AddManagedLabel(obj)
+}
+
+// PlacementAnnotationValue returns the value of the placement
+// annotation of a resource propagated to the given clusters.
+func PlacementAnnotationValue(clusterNames sets.Set[string]) string {
+	return strings.Join(sets.List(clusterNames), ",")
+}
+
+// AddPlacementAnnotation ensures that the given object has the
+// annotation with the given key listing the given clusters.
+func AddPlacementAnnotation(obj *unstructured.Unstructured, key string, clusterNames sets.Set[string]) {
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[key] = PlacementAnnotationValue(clusterNames)
+	obj.SetAnnotations(annotations)
+}
+
 // RemoveManagedLabel ensures that the given object does not have the
 // managed label.
 func RemoveManagedLabel(obj *unstructured.Unstructured) {
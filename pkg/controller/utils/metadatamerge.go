@@ -0,0 +1,126 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// DeclaredMetadataAnnotation records on a resource in a member
+	// cluster the keys of the labels and annotations that KubeFed set
+	// when the resource was last propagated with merging of metadata,
+	// so that they can be removed once they are no longer declared
+	// while those added in the member cluster are preserved.
+	DeclaredMetadataAnnotation = "kubefed.io/declared-metadata"
+)
+
+// declaredMetadata is the content of the declared metadata
+// annotation.
+type declaredMetadata struct {
+	Labels      []string `json:"labels,omitempty"`
+	Annotations []string `json:"annotations,omitempty"`
+}
+
+// getDeclaredMetadata returns the keys recorded on the given object.
+// An annotation that cannot be decoded is ignored.
+func getDeclaredMetadata(obj metav1.Object) declaredMetadata {
+	var declared declaredMetadata
+	value, ok := obj.GetAnnotations()[DeclaredMetadataAnnotation]
+	if !ok {
+		return declared
+	}
+	if err := json.Unmarshal([]byte(value), &declared); err != nil {
+		return declaredMetadata{}
+	}
+	return declared
+}
+
+// MergeClusterMetadata merges the labels and annotations of the given
+// desired object, which are those declared by KubeFed, with those of
+// the given cluster object, which may be nil if the resource does not
+// yet exist. Labels and annotations of the cluster object that are
+// not declared are preserved unless they were declared when the
+// resource was last propagated, and the declared keys are recorded
+// on the desired object for the next propagation.
+func MergeClusterMetadata(desiredObj, clusterObj *unstructured.Unstructured) error {
+	declaredAnnotations := desiredObj.GetAnnotations()
+	delete(declaredAnnotations, DeclaredMetadataAnnotation)
+	declared := declaredMetadata{
+		Labels:      sortedKeys(desiredObj.GetLabels()),
+		Annotations: sortedKeys(declaredAnnotations),
+	}
+
+	var liveLabels, liveAnnotations map[string]string
+	var previous declaredMetadata
+	if clusterObj != nil {
+		liveLabels = clusterObj.GetLabels()
+		liveAnnotations = clusterObj.GetAnnotations()
+		previous = getDeclaredMetadata(clusterObj)
+	}
+	labels := mergeDeclaredMap(liveLabels, desiredObj.GetLabels(), previous.Labels)
+	annotations := mergeDeclaredMap(liveAnnotations, declaredAnnotations, previous.Annotations)
+
+	value, err := json.Marshal(declared)
+	if err != nil {
+		return errors.Wrap(err, "Error encoding declared metadata")
+	}
+	annotations[DeclaredMetadataAnnotation] = string(value)
+
+	if len(labels) == 0 {
+		labels = nil
+	}
+	desiredObj.SetLabels(labels)
+	desiredObj.SetAnnotations(annotations)
+	return nil
+}
+
+// mergeDeclaredMap returns the entries of the live map that were not
+// previously declared, overlaid with the declared entries.
+func mergeDeclaredMap(live, declared map[string]string, previouslyDeclared []string) map[string]string {
+	merged := make(map[string]string, len(live)+len(declared))
+	for key, value := range live {
+		merged[key] = value
+	}
+	// The record of the previous propagation is replaced.
+	delete(merged, DeclaredMetadataAnnotation)
+	for _, key := range previouslyDeclared {
+		delete(merged, key)
+	}
+	for key, value := range declared {
+		merged[key] = value
+	}
+	return merged
+}
+
+func sortedKeys(m map[string]string) []string {
+	if len(m) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
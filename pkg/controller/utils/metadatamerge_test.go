@@ -0,0 +1,97 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestMergeClusterMetadata(t *testing.T) {
+	newObj := func(labels, annotations map[string]string) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{}}
+		obj.SetLabels(labels)
+		obj.SetAnnotations(annotations)
+		return obj
+	}
+	testCases := map[string]struct {
+		desired             *unstructured.Unstructured
+		cluster             *unstructured.Unstructured
+		expectedLabels      map[string]string
+		expectedAnnotations map[string]string
+	}{
+		"declared metadata is recorded for a new resource": {
+			desired:        newObj(map[string]string{"app": "foo"}, map[string]string{"a": "1"}),
+			expectedLabels: map[string]string{"app": "foo"},
+			expectedAnnotations: map[string]string{
+				"a":                        "1",
+				DeclaredMetadataAnnotation: `{"labels":["app"],"annotations":["a"]}`,
+			},
+		},
+		"metadata added in the cluster is preserved": {
+			desired: newObj(map[string]string{"app": "foo"}, map[string]string{"a": "2"}),
+			cluster: newObj(map[string]string{"app": "bar", "added": "true"}, map[string]string{
+				"a":                        "1",
+				"added":                    "true",
+				DeclaredMetadataAnnotation: `{"labels":["app"],"annotations":["a"]}`,
+			}),
+			expectedLabels: map[string]string{"app": "foo", "added": "true"},
+			expectedAnnotations: map[string]string{
+				"a":                        "2",
+				"added":                    "true",
+				DeclaredMetadataAnnotation: `{"labels":["app"],"annotations":["a"]}`,
+			},
+		},
+		"metadata no longer declared is removed": {
+			desired: newObj(nil, nil),
+			cluster: newObj(map[string]string{"app": "foo", "added": "true"}, map[string]string{
+				"a":                        "1",
+				"added":                    "true",
+				DeclaredMetadataAnnotation: `{"labels":["app"],"annotations":["a"]}`,
+			}),
+			expectedLabels: map[string]string{"added": "true"},
+			expectedAnnotations: map[string]string{
+				"added":                    "true",
+				DeclaredMetadataAnnotation: `{}`,
+			},
+		},
+		"metadata of a resource not previously merged is preserved": {
+			desired:        newObj(map[string]string{"app": "foo"}, nil),
+			cluster:        newObj(map[string]string{"added": "true"}, map[string]string{"added": "true"}),
+			expectedLabels: map[string]string{"app": "foo", "added": "true"},
+			expectedAnnotations: map[string]string{
+				"added":                    "true",
+				DeclaredMetadataAnnotation: `{"labels":["app"]}`,
+			},
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if err := MergeClusterMetadata(tc.desired, tc.cluster); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if labels := tc.desired.GetLabels(); !reflect.DeepEqual(labels, tc.expectedLabels) {
+				t.Errorf("Expected labels %v, got %v", tc.expectedLabels, labels)
+			}
+			if annotations := tc.desired.GetAnnotations(); !reflect.DeepEqual(annotations, tc.expectedAnnotations) {
+				t.Errorf("Expected annotations %v, got %v", tc.expectedAnnotations, annotations)
+			}
+		})
+	}
+}
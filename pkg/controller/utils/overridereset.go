@@ -0,0 +1,187 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const (
+	// AppliedOverridePathsAnnotation records on a resource in a member
+	// cluster the paths set by the overrides applied when the resource
+	// was last propagated, so that the paths can be reset to their
+	// template value once the overrides are removed.
+	AppliedOverridePathsAnnotation = "kubefed.io/applied-override-paths"
+)
+
+// GetAppliedOverridePaths returns the override paths recorded on the
+// given object. An annotation that cannot be decoded is ignored.
+func GetAppliedOverridePaths(obj metav1.Object) []string {
+	value, ok := obj.GetAnnotations()[AppliedOverridePathsAnnotation]
+	if !ok {
+		return nil
+	}
+	var paths []string
+	if err := json.Unmarshal([]byte(value), &paths); err != nil {
+		return nil
+	}
+	return paths
+}
+
+// SetAppliedOverridePaths records the paths of the given overrides on
+// the given object, or removes the record if there are no overrides.
+// An override that sets the labels or annotations map is recorded as
+// the paths of the keys it sets, so that only those keys are reset.
+func SetAppliedOverridePaths(obj *unstructured.Unstructured, overrides ClusterOverrides) error {
+	annotations := obj.GetAnnotations()
+	paths := sets.New[string]()
+	for _, override := range overrides {
+		value, isMap := override.Value.(map[string]interface{})
+		if !isMetadataMapPath(parseJSONPointer(override.Path)) || !isMap {
+			paths.Insert(override.Path)
+			continue
+		}
+		for key := range value {
+			paths.Insert(override.Path + "/" + escapeJSONPointerToken(key))
+		}
+	}
+	if paths.Len() == 0 {
+		if _, ok := annotations[AppliedOverridePathsAnnotation]; ok {
+			delete(annotations, AppliedOverridePathsAnnotation)
+			obj.SetAnnotations(annotations)
+		}
+		return nil
+	}
+	value, err := json.Marshal(sets.List(paths))
+	if err != nil {
+		return errors.Wrap(err, "Error encoding applied override paths")
+	}
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[AppliedOverridePathsAnnotation] = string(value)
+	obj.SetAnnotations(annotations)
+	return nil
+}
+
+// ResetOverriddenPaths sets each of the given override paths of the
+// desired object to its value in the template object, or removes the
+// path if the template does not set it. A path that traverses a list
+// resets the whole list, since list indices of the template and the
+// desired object may not correspond. A path of the labels or
+// annotations map itself only resets the keys set by the template, so
+// that labels and annotations retained from the cluster object are
+// kept.
+func ResetOverriddenPaths(desiredObj, templateObj *unstructured.Unstructured, paths []string) error {
+	for _, path := range paths {
+		fields := resettableFields(desiredObj.Object, templateObj.Object, parseJSONPointer(path))
+		if len(fields) == 0 {
+			continue
+		}
+		if isMetadataMapPath(fields) {
+			if err := resetMetadataMap(desiredObj, templateObj, fields); err != nil {
+				return errors.Wrapf(err, "Error resetting overridden path %q", path)
+			}
+			continue
+		}
+		value, found, err := unstructured.NestedFieldNoCopy(templateObj.Object, fields...)
+		if err != nil || !found {
+			unstructured.RemoveNestedField(desiredObj.Object, fields...)
+			continue
+		}
+		if err := unstructured.SetNestedField(desiredObj.Object, runtime.DeepCopyJSONValue(value), fields...); err != nil {
+			return errors.Wrapf(err, "Error resetting overridden path %q", path)
+		}
+	}
+	return nil
+}
+
+// isMetadataMapPath returns whether the given fields are those of the
+// labels or annotations of an object.
+func isMetadataMapPath(fields []string) bool {
+	return len(fields) == 2 && fields[0] == MetadataField && (fields[1] == "labels" || fields[1] == "annotations")
+}
+
+// resetMetadataMap sets the keys of the labels or annotations map at
+// the given fields of the template object to their template value in
+// the desired object, leaving the other keys of the desired object.
+func resetMetadataMap(desiredObj, templateObj *unstructured.Unstructured, fields []string) error {
+	templateMap, _, err := unstructured.NestedStringMap(templateObj.Object, fields...)
+	if err != nil || len(templateMap) == 0 {
+		return err
+	}
+	desiredMap, _, err := unstructured.NestedStringMap(desiredObj.Object, fields...)
+	if err != nil {
+		desiredMap = nil
+	}
+	if desiredMap == nil {
+		desiredMap = map[string]string{}
+	}
+	for key, value := range templateMap {
+		desiredMap[key] = value
+	}
+	return unstructured.SetNestedStringMap(desiredObj.Object, desiredMap, fields...)
+}
+
+// resettableFields returns the prefix of the given fields up to and
+// including the first field holding a list or a scalar in either of
+// the given objects.
+func resettableFields(desired, template map[string]interface{}, fields []string) []string {
+	for i := range fields {
+		if !isMapField(desired, fields[:i+1]) || !isMapField(template, fields[:i+1]) {
+			return fields[:i+1]
+		}
+	}
+	return fields
+}
+
+// isMapField returns whether the value at the given fields of the
+// given object is absent or a map.
+func isMapField(obj map[string]interface{}, fields []string) bool {
+	value, found, err := unstructured.NestedFieldNoCopy(obj, fields...)
+	if err != nil {
+		return false
+	}
+	if !found {
+		return true
+	}
+	_, ok := value.(map[string]interface{})
+	return ok
+}
+
+// parseJSONPointer returns the unescaped tokens of the given JSON
+// pointer.
+func parseJSONPointer(path string) []string {
+	path = strings.TrimPrefix(path, "/")
+	if len(path) == 0 {
+		return nil
+	}
+	tokens := strings.Split(path, "/")
+	for i, token := range tokens {
+		token = strings.ReplaceAll(token, "~1", "/")
+		tokens[i] = strings.ReplaceAll(token, "~0", "~")
+	}
+	return tokens
+}
@@ -0,0 +1,164 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestResetOverriddenPaths(t *testing.T) {
+	templateObj := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"labels": map[string]interface{}{"team": "platform"},
+			},
+			"spec": map[string]interface{}{
+				"replicas": int64(1),
+				"containers": []interface{}{
+					map[string]interface{}{"image": "foo:1"},
+				},
+			},
+		},
+	}
+	testCases := map[string]struct {
+		desired  map[string]interface{}
+		paths    []string
+		expected map[string]interface{}
+	}{
+		"path set by the template is reset to the template value": {
+			desired: map[string]interface{}{
+				"spec": map[string]interface{}{"replicas": int64(5)},
+			},
+			paths: []string{"/spec/replicas"},
+			expected: map[string]interface{}{
+				"spec": map[string]interface{}{"replicas": int64(1)},
+			},
+		},
+		"path not set by the template is removed": {
+			desired: map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"annotations": map[string]interface{}{
+						"retained": "true",
+						"a/b":      "true",
+					},
+				},
+			},
+			paths: []string{"/metadata/annotations/a~1b"},
+			expected: map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"annotations": map[string]interface{}{
+						"retained": "true",
+					},
+				},
+			},
+		},
+		"path of the labels map only resets the labels of the template": {
+			desired: map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"labels": map[string]interface{}{
+						"team":     "override",
+						"retained": "true",
+					},
+				},
+			},
+			paths: []string{"/metadata/labels"},
+			expected: map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"labels": map[string]interface{}{
+						"team":     "platform",
+						"retained": "true",
+					},
+				},
+			},
+		},
+		"path of the annotations map leaves annotations the template does not set": {
+			desired: map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"annotations": map[string]interface{}{
+						"retained": "true",
+					},
+				},
+			},
+			paths: []string{"/metadata/annotations"},
+			expected: map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"annotations": map[string]interface{}{
+						"retained": "true",
+					},
+				},
+			},
+		},
+		"path within a list resets the whole list": {
+			desired: map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{"image": "foo:2"},
+						map[string]interface{}{"image": "bar:1"},
+					},
+				},
+			},
+			paths: []string{"/spec/containers/0/image"},
+			expected: map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{"image": "foo:1"},
+					},
+				},
+			},
+		},
+	}
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			desiredObj := &unstructured.Unstructured{Object: testCase.desired}
+			if err := ResetOverriddenPaths(desiredObj, templateObj, testCase.paths); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(desiredObj.Object, testCase.expected) {
+				t.Fatalf("Expected %v, got %v", testCase.expected, desiredObj.Object)
+			}
+		})
+	}
+}
+
+func TestAppliedOverridePathsRoundTrip(t *testing.T) {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	overrides := ClusterOverrides{
+		{Path: "/spec/replicas"},
+		{Path: "/metadata/annotations/foo"},
+		{Path: "/spec/replicas"},
+		// An override of the labels map is recorded as the labels
+		// it sets.
+		{Path: "/metadata/labels", Value: map[string]interface{}{"example.io/team": "platform", "tier": "web"}},
+	}
+	if err := SetAppliedOverridePaths(obj, overrides); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expectedPaths := []string{"/metadata/annotations/foo", "/metadata/labels/example.io~1team", "/metadata/labels/tier", "/spec/replicas"}
+	if paths := GetAppliedOverridePaths(obj); !reflect.DeepEqual(paths, expectedPaths) {
+		t.Fatalf("Expected paths %v, got %v", expectedPaths, paths)
+	}
+
+	if err := SetAppliedOverridePaths(obj, nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, ok := obj.GetAnnotations()[AppliedOverridePathsAnnotation]; ok {
+		t.Fatalf("Expected the %q annotation to be removed", AppliedOverridePathsAnnotation)
+	}
+}
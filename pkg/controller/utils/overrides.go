@@ -31,6 +31,20 @@ type ClusterOverride struct {
 	Op    string      `json:"op,omitempty"`
 	Path  string      `json:"path"`
 	Value interface{} `json:"value,omitempty"`
+	// ClusterLocal indicates that the override is only of concern to
+	// its cluster (e.g. a temporary debug annotation). It is applied
+	// like any other override but excluded from the override version
+	// of the federated resource.
+	ClusterLocal bool `json:"clusterLocal,omitempty"`
+	// Template indicates that the string values of the override are
+	// templates evaluated against the name, labels and parameters of
+	// its cluster before the override is applied.
+	Template bool `json:"template,omitempty"`
+	// ValueFrom sources the value of the override from a key of a
+	// ConfigMap or Secret in the namespace of the federated resource.
+	// The value is resolved each time the override is applied, and
+	// the federated resource is reconciled when the source changes.
+	ValueFrom *OverrideValueSource `json:"valueFrom,omitempty"`
 }
 
 type GenericOverrideItem struct {
@@ -70,6 +84,17 @@ var invalidPaths = sets.NewString(
 // ClusterOverrides Slice of ClusterOverride
 type ClusterOverrides []ClusterOverride
 
+// WithoutClusterLocal returns the overrides that are not cluster-local.
+func (o ClusterOverrides) WithoutClusterLocal() ClusterOverrides {
+	var overrides ClusterOverrides
+	for _, override := range o {
+		if !override.ClusterLocal {
+			overrides = append(overrides, override)
+		}
+	}
+	return overrides
+}
+
 // OverridesMap Mapping of clusterName to overrides for the cluster
 type OverridesMap map[string]ClusterOverrides
 
@@ -124,6 +149,11 @@ func GetOverrides(rawObj *unstructured.Unstructured) (OverridesMap, error) {
 			if paths.Has(path) {
 				return nil, errors.Errorf("path %q appears more than once for cluster %q", path, clusterName)
 			}
+			if clusterOverride.ValueFrom != nil {
+				if err := validateValueFrom(clusterOverride); err != nil {
+					return nil, errors.Wrapf(err, "override[%d] for cluster %q has an invalid value source", i, clusterName)
+				}
+			}
 			paths.Insert(path)
 		}
 		overridesMap[clusterName] = clusterOverrides
@@ -0,0 +1,103 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestOverridesRoundTrip(t *testing.T) {
+	testCases := map[string]OverridesMap{
+		"no overrides": {},
+		"cluster without overrides": {
+			"cluster1": ClusterOverrides{},
+		},
+		"scalar values": {
+			"cluster1": ClusterOverrides{
+				{Path: "/spec/replicas", Value: 3},
+				{Path: "/spec/paused", Value: true},
+				{Path: "/spec/ratio", Value: 0.5},
+			},
+		},
+		"nested values and ops": {
+			"cluster1": ClusterOverrides{
+				{
+					Op:   "add",
+					Path: "/metadata/annotations",
+					Value: map[string]interface{}{
+						"foo": "bar",
+						"nested": map[string]interface{}{
+							"list": []interface{}{"a", int64(1), map[string]interface{}{"b": nil}},
+						},
+					},
+				},
+				{Op: "remove", Path: "/metadata/labels/foo"},
+			},
+			"cluster2": ClusterOverrides{
+				{Op: "replace", Path: "/spec/template/spec/containers/0/args", Value: []interface{}{"--foo", "--bar=baz"}},
+			},
+		},
+	}
+
+	for testName, overrides := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			obj := &unstructured.Unstructured{}
+			obj.SetAPIVersion("types.kubefed.io/v1beta1")
+			obj.SetKind("FederatedConfigMap")
+			if err := SetOverrides(obj, overrides); err != nil {
+				t.Fatalf("Unexpected error setting overrides: %v", err)
+			}
+
+			// Serialize the object as the API would.
+			content, err := obj.MarshalJSON()
+			if err != nil {
+				t.Fatalf("Unexpected error marshaling object: %v", err)
+			}
+			retrievedObj := &unstructured.Unstructured{}
+			if err := retrievedObj.UnmarshalJSON(content); err != nil {
+				t.Fatalf("Unexpected error unmarshaling object: %v", err)
+			}
+
+			actual, err := GetOverrides(retrievedObj)
+			if err != nil {
+				t.Fatalf("Unexpected error getting overrides: %v", err)
+			}
+			expected := normalizeOverrides(t, overrides)
+			if !reflect.DeepEqual(expected, actual) {
+				t.Fatalf("Expected overrides %#v, got %#v", expected, actual)
+			}
+		})
+	}
+}
+
+// normalizeOverrides returns the given overrides with values in the
+// form produced by json decoding.
+func normalizeOverrides(t *testing.T, overrides OverridesMap) OverridesMap {
+	content, err := json.Marshal(overrides)
+	if err != nil {
+		t.Fatalf("Unexpected error marshaling overrides: %v", err)
+	}
+	normalized := OverridesMap{}
+	if err := json.Unmarshal(content, &normalized); err != nil {
+		t.Fatalf("Unexpected error unmarshaling overrides: %v", err)
+	}
+	return normalized
+}
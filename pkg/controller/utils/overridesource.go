@@ -0,0 +1,217 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// OverrideSourceLabelKey opts a ConfigMap or Secret in to being
+	// referenced by overrides. The values of objects without the label
+	// are never read, so that a user able to create a federated
+	// resource cannot copy arbitrary Secrets of its namespace to member
+	// clusters.
+	OverrideSourceLabelKey   = "kubefed.io/override-source"
+	OverrideSourceLabelValue = "true"
+)
+
+// OverrideValueSource identifies the key of a ConfigMap or Secret that
+// the value of an override is sourced from. Exactly one of the
+// references must be set.
+type OverrideValueSource struct {
+	ConfigMapKeyRef *OverrideKeySelector `json:"configMapKeyRef,omitempty"`
+	SecretKeyRef    *OverrideKeySelector `json:"secretKeyRef,omitempty"`
+}
+
+// OverrideKeySelector selects a key of a ConfigMap or Secret.
+type OverrideKeySelector struct {
+	Name string `json:"name"`
+	Key  string `json:"key"`
+}
+
+// OverrideSource identifies a ConfigMap or Secret referenced by the
+// overrides of a federated resource.
+type OverrideSource struct {
+	Kind string
+	Name QualifiedName
+}
+
+func (s OverrideSource) String() string {
+	return fmt.Sprintf("%s %q", s.Kind, s.Name)
+}
+
+// OverrideSourceNotFoundError indicates that a ConfigMap or Secret
+// referenced by an override does not exist.
+type OverrideSourceNotFoundError struct {
+	Source OverrideSource
+}
+
+func (e *OverrideSourceNotFoundError) Error() string {
+	return fmt.Sprintf("%s referenced by overrides was not found or is not labeled %s=%s", e.Source, OverrideSourceLabelKey, OverrideSourceLabelValue)
+}
+
+// IsOverrideSourceNotFound returns whether the given error, or the
+// error it wraps, indicates that an override source does not exist.
+func IsOverrideSourceNotFound(err error) bool {
+	var notFoundErr *OverrideSourceNotFoundError
+	return errors.As(err, &notFoundErr)
+}
+
+// OverrideSourceLookupFunc returns the data of the given source, with
+// the values of a Secret decoded, or nil if the source does not exist.
+type OverrideSourceLookupFunc func(source OverrideSource) (map[string]string, error)
+
+// source returns the source referenced in the given namespace and the
+// selected key.
+func (v *OverrideValueSource) source(namespace string) (OverrideSource, string) {
+	if v.ConfigMapKeyRef != nil {
+		return OverrideSource{Kind: ConfigMapKind, Name: QualifiedName{Namespace: namespace, Name: v.ConfigMapKeyRef.Name}}, v.ConfigMapKeyRef.Key
+	}
+	return OverrideSource{Kind: SecretKind, Name: QualifiedName{Namespace: namespace, Name: v.SecretKeyRef.Name}}, v.SecretKeyRef.Key
+}
+
+func validateValueFrom(override ClusterOverride) error {
+	valueFrom := override.ValueFrom
+	switch {
+	case (valueFrom.ConfigMapKeyRef == nil) == (valueFrom.SecretKeyRef == nil):
+		return errors.New("exactly one of configMapKeyRef and secretKeyRef must be set")
+	case override.Value != nil:
+		return errors.New("value must not be set")
+	case override.Template:
+		return errors.New("a templated override cannot have a value source")
+	}
+	switch override.Op {
+	case "", "add", "replace":
+	default:
+		return errors.Errorf("op %q does not take a value", override.Op)
+	}
+	selector := valueFrom.ConfigMapKeyRef
+	if selector == nil {
+		selector = valueFrom.SecretKeyRef
+	}
+	if len(selector.Name) == 0 || len(selector.Key) == 0 {
+		return errors.New("name and key must be set")
+	}
+	return nil
+}
+
+// HasValueSources returns whether any of the overrides sources its
+// value from a ConfigMap or Secret.
+func (o ClusterOverrides) HasValueSources() bool {
+	for _, override := range o {
+		if override.ValueFrom != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// SecretValuePaths returns the paths of the overrides that source
+// their value from a Secret.
+func (o ClusterOverrides) SecretValuePaths() []string {
+	var paths []string
+	for _, override := range o {
+		if override.ValueFrom != nil && override.ValueFrom.SecretKeyRef != nil {
+			paths = append(paths, override.Path)
+		}
+	}
+	return paths
+}
+
+// ValueSources returns the sources referenced by the overrides of all
+// clusters, which are in the given namespace of the federated
+// resource.
+func (m OverridesMap) ValueSources(namespace string) []OverrideSource {
+	var sources []OverrideSource
+	seen := make(map[OverrideSource]bool)
+	for _, overrides := range m {
+		for _, override := range overrides {
+			if override.ValueFrom == nil {
+				continue
+			}
+			source, _ := override.ValueFrom.source(namespace)
+			if !seen[source] {
+				seen[source] = true
+				sources = append(sources, source)
+			}
+		}
+	}
+	return sources
+}
+
+// ResolveOverrideValues returns a copy of the given overrides in which
+// the value of each override with a value source has been set to the
+// value of the selected key of its source in the given namespace. An
+// OverrideSourceNotFoundError is returned if a source does not exist.
+func ResolveOverrideValues(overrides ClusterOverrides, namespace string, lookup OverrideSourceLookupFunc) (ClusterOverrides, error) {
+	if !overrides.HasValueSources() {
+		return overrides, nil
+	}
+	if len(namespace) == 0 {
+		return nil, errors.New("Value sources of overrides are only supported for namespaced federated resources")
+	}
+	resolved := make(ClusterOverrides, 0, len(overrides))
+	for i, override := range overrides {
+		if override.ValueFrom != nil {
+			source, key := override.ValueFrom.source(namespace)
+			data, err := lookup(source)
+			if err != nil {
+				return nil, errors.Wrapf(err, "Error retrieving %s for override[%d]", source, i)
+			}
+			if data == nil {
+				return nil, &OverrideSourceNotFoundError{Source: source}
+			}
+			value, ok := data[key]
+			if !ok {
+				return nil, errors.Errorf("%s referenced by override[%d] has no key %q", source, i, key)
+			}
+			override.Value = value
+			override.ValueFrom = nil
+		}
+		resolved = append(resolved, override)
+	}
+	return resolved, nil
+}
+
+// OverrideSourceData returns the data of the given ConfigMap or
+// Secret, with the values of a Secret decoded.
+func OverrideSourceData(obj *unstructured.Unstructured) (map[string]string, error) {
+	data, _, err := unstructured.NestedStringMap(obj.Object, "data")
+	if err != nil {
+		return nil, errors.Wrapf(err, "Error reading the data of %s %q", obj.GetKind(), NewQualifiedName(obj))
+	}
+	if data == nil {
+		data = map[string]string{}
+	}
+	if obj.GetKind() != SecretKind {
+		return data, nil
+	}
+	for key, encoded := range data {
+		decoded, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Error decoding key %q of Secret %q", key, NewQualifiedName(obj))
+		}
+		data[key] = string(decoded)
+	}
+	return data, nil
+}
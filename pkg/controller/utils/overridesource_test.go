@@ -0,0 +1,238 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestResolveOverrideValues(t *testing.T) {
+	sources := map[OverrideSource]map[string]string{
+		{Kind: ConfigMapKind, Name: QualifiedName{Namespace: "ns", Name: "release"}}: {"build": "1234"},
+		{Kind: SecretKind, Name: QualifiedName{Namespace: "ns", Name: "creds"}}:      {"password": "s3cr3t"},
+	}
+	lookup := func(source OverrideSource) (map[string]string, error) {
+		return sources[source], nil
+	}
+
+	testCases := map[string]struct {
+		overrides         ClusterOverrides
+		namespace         string
+		expectedOverrides ClusterOverrides
+		expectedNotFound  bool
+		expectedErr       bool
+	}{
+		"overrides without value sources are unchanged": {
+			overrides:         ClusterOverrides{{Path: "/spec/replicas", Value: int64(2)}},
+			namespace:         "ns",
+			expectedOverrides: ClusterOverrides{{Path: "/spec/replicas", Value: int64(2)}},
+		},
+		"ConfigMap and Secret values are resolved": {
+			overrides: ClusterOverrides{
+				{Path: "/data/build", ValueFrom: &OverrideValueSource{ConfigMapKeyRef: &OverrideKeySelector{Name: "release", Key: "build"}}},
+				{Path: "/spec/replicas", Value: int64(2)},
+				{Path: "/data/password", Op: "replace", ValueFrom: &OverrideValueSource{SecretKeyRef: &OverrideKeySelector{Name: "creds", Key: "password"}}},
+			},
+			namespace: "ns",
+			expectedOverrides: ClusterOverrides{
+				{Path: "/data/build", Value: "1234"},
+				{Path: "/spec/replicas", Value: int64(2)},
+				{Path: "/data/password", Op: "replace", Value: "s3cr3t"},
+			},
+		},
+		"missing source": {
+			overrides: ClusterOverrides{
+				{Path: "/data/build", ValueFrom: &OverrideValueSource{ConfigMapKeyRef: &OverrideKeySelector{Name: "missing", Key: "build"}}},
+			},
+			namespace:        "ns",
+			expectedNotFound: true,
+			expectedErr:      true,
+		},
+		"source in another namespace is not visible": {
+			overrides: ClusterOverrides{
+				{Path: "/data/build", ValueFrom: &OverrideValueSource{ConfigMapKeyRef: &OverrideKeySelector{Name: "release", Key: "build"}}},
+			},
+			namespace:        "other",
+			expectedNotFound: true,
+			expectedErr:      true,
+		},
+		"missing key": {
+			overrides: ClusterOverrides{
+				{Path: "/data/build", ValueFrom: &OverrideValueSource{ConfigMapKeyRef: &OverrideKeySelector{Name: "release", Key: "missing"}}},
+			},
+			namespace:   "ns",
+			expectedErr: true,
+		},
+		"cluster-scoped resource": {
+			overrides: ClusterOverrides{
+				{Path: "/data/build", ValueFrom: &OverrideValueSource{ConfigMapKeyRef: &OverrideKeySelector{Name: "release", Key: "build"}}},
+			},
+			expectedErr: true,
+		},
+	}
+
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			overrides, err := ResolveOverrideValues(tc.overrides, tc.namespace, lookup)
+			if (err != nil) != tc.expectedErr {
+				t.Fatalf("Expected error %v, got %v", tc.expectedErr, err)
+			}
+			if IsOverrideSourceNotFound(err) != tc.expectedNotFound {
+				t.Fatalf("Expected a missing source %v, got %v", tc.expectedNotFound, err)
+			}
+			if !reflect.DeepEqual(overrides, tc.expectedOverrides) {
+				t.Fatalf("Expected overrides %v, got %v", tc.expectedOverrides, overrides)
+			}
+		})
+	}
+}
+
+func TestGetOverridesValidatesValueSources(t *testing.T) {
+	configMapRef := map[string]interface{}{"name": "release", "key": "build"}
+	testCases := map[string]struct {
+		override    map[string]interface{}
+		expectedErr bool
+	}{
+		"ConfigMap reference": {
+			override: map[string]interface{}{
+				"path":      "/data/build",
+				"valueFrom": map[string]interface{}{"configMapKeyRef": configMapRef},
+			},
+		},
+		"both references": {
+			override: map[string]interface{}{
+				"path": "/data/build",
+				"valueFrom": map[string]interface{}{
+					"configMapKeyRef": configMapRef,
+					"secretKeyRef":    configMapRef,
+				},
+			},
+			expectedErr: true,
+		},
+		"no reference": {
+			override: map[string]interface{}{
+				"path":      "/data/build",
+				"valueFrom": map[string]interface{}{},
+			},
+			expectedErr: true,
+		},
+		"inline value": {
+			override: map[string]interface{}{
+				"path":      "/data/build",
+				"value":     "1234",
+				"valueFrom": map[string]interface{}{"configMapKeyRef": configMapRef},
+			},
+			expectedErr: true,
+		},
+		"templated": {
+			override: map[string]interface{}{
+				"path":      "/data/build",
+				"template":  true,
+				"valueFrom": map[string]interface{}{"configMapKeyRef": configMapRef},
+			},
+			expectedErr: true,
+		},
+		"remove op": {
+			override: map[string]interface{}{
+				"path":      "/data/build",
+				"op":        "remove",
+				"valueFrom": map[string]interface{}{"configMapKeyRef": configMapRef},
+			},
+			expectedErr: true,
+		},
+		"missing key": {
+			override: map[string]interface{}{
+				"path":      "/data/build",
+				"valueFrom": map[string]interface{}{"secretKeyRef": map[string]interface{}{"name": "creds"}},
+			},
+			expectedErr: true,
+		},
+	}
+
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			fedObject := &unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"spec": map[string]interface{}{
+						"overrides": []interface{}{
+							map[string]interface{}{
+								"clusterName":      "cluster1",
+								"clusterOverrides": []interface{}{tc.override},
+							},
+						},
+					},
+				},
+			}
+			overridesMap, err := GetOverrides(fedObject)
+			if (err != nil) != tc.expectedErr {
+				t.Fatalf("Expected error %v, got %v", tc.expectedErr, err)
+			}
+			if err == nil && !overridesMap["cluster1"].HasValueSources() {
+				t.Fatalf("Expected the overrides to have value sources")
+			}
+		})
+	}
+}
+
+func TestOverrideSourceData(t *testing.T) {
+	testCases := map[string]struct {
+		obj          map[string]interface{}
+		expectedData map[string]string
+		expectedErr  bool
+	}{
+		"ConfigMap": {
+			obj: map[string]interface{}{
+				"kind": ConfigMapKind,
+				"data": map[string]interface{}{"build": "1234"},
+			},
+			expectedData: map[string]string{"build": "1234"},
+		},
+		"ConfigMap without data": {
+			obj:          map[string]interface{}{"kind": ConfigMapKind},
+			expectedData: map[string]string{},
+		},
+		"Secret values are decoded": {
+			obj: map[string]interface{}{
+				"kind": SecretKind,
+				"data": map[string]interface{}{"password": "czNjcjN0"},
+			},
+			expectedData: map[string]string{"password": "s3cr3t"},
+		},
+		"Secret value that is not base64": {
+			obj: map[string]interface{}{
+				"kind": SecretKind,
+				"data": map[string]interface{}{"password": "s3cr3t!"},
+			},
+			expectedErr: true,
+		},
+	}
+
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			data, err := OverrideSourceData(&unstructured.Unstructured{Object: tc.obj})
+			if (err != nil) != tc.expectedErr {
+				t.Fatalf("Expected error %v, got %v", tc.expectedErr, err)
+			}
+			if !tc.expectedErr && !reflect.DeepEqual(data, tc.expectedData) {
+				t.Fatalf("Expected data %v, got %v", tc.expectedData, data)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"bytes"
+	"text/template"
+
+	"github.com/pkg/errors"
+
+	fedv1b1 "sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
+)
+
+// OverrideTemplateData is the data that the values of templated
+// overrides are evaluated against for a member cluster.
+type OverrideTemplateData struct {
+	// ClusterName is the name of the KubeFedCluster.
+	ClusterName string
+	// Labels are the labels of the KubeFedCluster.
+	Labels map[string]string
+	// Params are the parameters of the KubeFedCluster.
+	Params map[string]string
+}
+
+// NewOverrideTemplateData returns the data that templated overrides
+// are evaluated against for the given cluster.
+func NewOverrideTemplateData(cluster *fedv1b1.KubeFedCluster) *OverrideTemplateData {
+	data := &OverrideTemplateData{
+		ClusterName: cluster.Name,
+		Labels:      cluster.Labels,
+		Params:      cluster.Spec.Parameters,
+	}
+	// Referencing a missing label or parameter is an error rather
+	// than yielding an empty value.
+	if data.Labels == nil {
+		data.Labels = map[string]string{}
+	}
+	if data.Params == nil {
+		data.Params = map[string]string{}
+	}
+	return data
+}
+
+// Templated returns whether any of the overrides is templated.
+func (o ClusterOverrides) Templated() bool {
+	for _, override := range o {
+		if override.Template {
+			return true
+		}
+	}
+	return false
+}
+
+// ExpandOverrideTemplates returns a copy of the given overrides in
+// which the string values of templated overrides, including those
+// nested in objects and lists, have been evaluated as templates
+// against the given data. The returned overrides are no longer
+// templated, so that their hash changes with the data of the cluster.
+func ExpandOverrideTemplates(overrides ClusterOverrides, data *OverrideTemplateData) (ClusterOverrides, error) {
+	if !overrides.Templated() {
+		return overrides, nil
+	}
+	expanded := make(ClusterOverrides, 0, len(overrides))
+	for i, override := range overrides {
+		if override.Template {
+			value, err := expandTemplateValue(override.Value, data)
+			if err != nil {
+				return nil, errors.Wrapf(err, "override[%d] has an invalid template for path %s", i, override.Path)
+			}
+			override.Value = value
+			override.Template = false
+		}
+		expanded = append(expanded, override)
+	}
+	return expanded, nil
+}
+
+func expandTemplateValue(value interface{}, data *OverrideTemplateData) (interface{}, error) {
+	switch typedValue := value.(type) {
+	case string:
+		tmpl, err := template.New("override").Option("missingkey=error").Parse(typedValue)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse override template")
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return nil, errors.Wrap(err, "failed to evaluate override template")
+		}
+		return buf.String(), nil
+	case map[string]interface{}:
+		expanded := make(map[string]interface{}, len(typedValue))
+		for key, item := range typedValue {
+			expandedItem, err := expandTemplateValue(item, data)
+			if err != nil {
+				return nil, err
+			}
+			expanded[key] = expandedItem
+		}
+		return expanded, nil
+	case []interface{}:
+		expanded := make([]interface{}, 0, len(typedValue))
+		for _, item := range typedValue {
+			expandedItem, err := expandTemplateValue(item, data)
+			if err != nil {
+				return nil, err
+			}
+			expanded = append(expanded, expandedItem)
+		}
+		return expanded, nil
+	default:
+		return value, nil
+	}
+}
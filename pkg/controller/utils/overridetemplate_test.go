@@ -0,0 +1,116 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fedv1b1 "sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
+)
+
+func TestExpandOverrideTemplates(t *testing.T) {
+	cluster := &fedv1b1.KubeFedCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "cluster1",
+			Labels: map[string]string{"region": "eu"},
+		},
+		Spec: fedv1b1.KubeFedClusterSpec{
+			Parameters: map[string]string{"dbHost": "db.eu.example.com"},
+		},
+	}
+	data := NewOverrideTemplateData(cluster)
+
+	testCases := map[string]struct {
+		overrides         ClusterOverrides
+		expectedOverrides ClusterOverrides
+		expectedErr       bool
+	}{
+		"overrides that are not templated are unchanged": {
+			overrides: ClusterOverrides{
+				{Path: "/data/endpoint", Value: "{{ .Params.dbHost }}"},
+			},
+			expectedOverrides: ClusterOverrides{
+				{Path: "/data/endpoint", Value: "{{ .Params.dbHost }}"},
+			},
+		},
+		"parameter is resolved": {
+			overrides: ClusterOverrides{
+				{Path: "/data/endpoint", Value: "{{ .Params.dbHost }}:5432", Template: true},
+				{Path: "/spec/replicas", Value: int64(2)},
+			},
+			expectedOverrides: ClusterOverrides{
+				{Path: "/data/endpoint", Value: "db.eu.example.com:5432"},
+				{Path: "/spec/replicas", Value: int64(2)},
+			},
+		},
+		"nested values are resolved": {
+			overrides: ClusterOverrides{
+				{
+					Path: "/data",
+					Value: map[string]interface{}{
+						"cluster": "{{ .ClusterName }}",
+						"hosts":   []interface{}{"{{ .Params.dbHost }}", int64(1)},
+						"region":  "{{ .Labels.region }}",
+					},
+					Template: true,
+				},
+			},
+			expectedOverrides: ClusterOverrides{
+				{
+					Path: "/data",
+					Value: map[string]interface{}{
+						"cluster": "cluster1",
+						"hosts":   []interface{}{"db.eu.example.com", int64(1)},
+						"region":  "eu",
+					},
+				},
+			},
+		},
+		"missing parameter": {
+			overrides: ClusterOverrides{
+				{Path: "/data/endpoint", Value: "{{ .Params.missing }}", Template: true},
+			},
+			expectedErr: true,
+		},
+		"invalid template": {
+			overrides: ClusterOverrides{
+				{Path: "/data/endpoint", Value: "{{ .Params.dbHost", Template: true},
+			},
+			expectedErr: true,
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			overrides, err := ExpandOverrideTemplates(tc.overrides, data)
+			if tc.expectedErr {
+				if err == nil {
+					t.Fatalf("Expected an error, got overrides %v", overrides)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(tc.expectedOverrides, overrides) {
+				t.Fatalf("Expected overrides %v, got %v", tc.expectedOverrides, overrides)
+			}
+		})
+	}
+}
@@ -0,0 +1,191 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+// ValidateOverrides checks the values of the given overrides against
+// the types of the fields of the given schema of the target type, and
+// returns a copy of the overrides in which a value of a compatible
+// type is replaced with the value of the expected type (e.g. the
+// string "3" with the integer 3 for an integer field). An error is
+// returned for a value that cannot be converted. The given overrides
+// are not modified.
+//
+// Validation is best-effort: values are left unchanged if the schema
+// is nil (e.g. for a type that is not defined by a CRD) or does not
+// describe the overridden field. Templated overrides and overrides with
+// a value source are not validated until their values have been
+// expanded or resolved for a cluster.
+func ValidateOverrides(overrides ClusterOverrides, schema *apiextv1.JSONSchemaProps) (ClusterOverrides, error) {
+	if schema == nil {
+		return overrides, nil
+	}
+	validated := make(ClusterOverrides, len(overrides))
+	copy(validated, overrides)
+	for i, override := range overrides {
+		if override.Template || override.ValueFrom != nil {
+			continue
+		}
+		switch override.Op {
+		case "", "add", "replace":
+		default:
+			continue
+		}
+		fieldSchema := schemaForPath(schema, override.Path)
+		if fieldSchema == nil {
+			continue
+		}
+		value, err := coerceValue(override.Value, fieldSchema)
+		if err != nil {
+			return nil, errors.Wrapf(err, "override[%d] has an invalid value for path %s", i, override.Path)
+		}
+		validated[i].Value = value
+	}
+	return validated, nil
+}
+
+// schemaForPath returns the schema of the field at the given JSON
+// pointer path, or nil if the schema does not describe the field.
+func schemaForPath(schema *apiextv1.JSONSchemaProps, path string) *apiextv1.JSONSchemaProps {
+	if !strings.HasPrefix(path, "/") {
+		return nil
+	}
+	for _, token := range strings.Split(path[1:], "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		switch schema.Type {
+		case "array":
+			if schema.Items == nil || schema.Items.Schema == nil {
+				return nil
+			}
+			schema = schema.Items.Schema
+		default:
+			propSchema, known := fieldSchema(schema, token)
+			if !known || propSchema == nil {
+				return nil
+			}
+			schema = propSchema
+		}
+	}
+	return schema
+}
+
+// coerceValue returns the given value as the type described by the
+// given schema, converting the values of nested objects and arrays
+// into copies of them.
+func coerceValue(value interface{}, schema *apiextv1.JSONSchemaProps) (interface{}, error) {
+	if value == nil || schema == nil || schema.XIntOrString {
+		return value, nil
+	}
+	switch schema.Type {
+	case "integer":
+		switch typedValue := value.(type) {
+		case int64:
+			return typedValue, nil
+		case float64:
+			if typedValue == math.Trunc(typedValue) {
+				return int64(typedValue), nil
+			}
+		case string:
+			if intValue, err := strconv.ParseInt(typedValue, 10, 64); err == nil {
+				return intValue, nil
+			}
+		}
+	case "number":
+		switch typedValue := value.(type) {
+		case int64, float64:
+			return typedValue, nil
+		case string:
+			if floatValue, err := strconv.ParseFloat(typedValue, 64); err == nil {
+				return floatValue, nil
+			}
+		}
+	case "boolean":
+		switch typedValue := value.(type) {
+		case bool:
+			return typedValue, nil
+		case string:
+			if typedValue == "true" || typedValue == "false" {
+				return typedValue == "true", nil
+			}
+		}
+	case "string":
+		switch typedValue := value.(type) {
+		case string:
+			return typedValue, nil
+		case int64:
+			return strconv.FormatInt(typedValue, 10), nil
+		case float64:
+			return strconv.FormatFloat(typedValue, 'f', -1, 64), nil
+		case bool:
+			return strconv.FormatBool(typedValue), nil
+		}
+	case "object":
+		if typedValue, ok := value.(map[string]interface{}); ok {
+			coercedValue := make(map[string]interface{}, len(typedValue))
+			for key, fieldValue := range typedValue {
+				propSchema, _ := fieldSchema(schema, key)
+				coerced, err := coerceValue(fieldValue, propSchema)
+				if err != nil {
+					return nil, errors.Wrapf(err, "field %q", key)
+				}
+				coercedValue[key] = coerced
+			}
+			return coercedValue, nil
+		}
+	case "array":
+		if typedValue, ok := value.([]interface{}); ok {
+			if schema.Items == nil || schema.Items.Schema == nil {
+				return typedValue, nil
+			}
+			coercedValue := make([]interface{}, len(typedValue))
+			for i, item := range typedValue {
+				coerced, err := coerceValue(item, schema.Items.Schema)
+				if err != nil {
+					return nil, errors.Wrapf(err, "item %d", i)
+				}
+				coercedValue[i] = coerced
+			}
+			return coercedValue, nil
+		}
+	default:
+		return value, nil
+	}
+	return nil, errors.Errorf("expected a value of type %s, got %s", schema.Type, describeValue(value))
+}
+
+func describeValue(value interface{}) string {
+	switch value.(type) {
+	case map[string]interface{}:
+		return "an object"
+	case []interface{}:
+		return "an array"
+	case string:
+		return fmt.Sprintf("the string %q", value)
+	default:
+		return fmt.Sprintf("%v", value)
+	}
+}
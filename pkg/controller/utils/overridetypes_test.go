@@ -0,0 +1,157 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+func TestValidateOverrides(t *testing.T) {
+	schema := &apiextv1.JSONSchemaProps{
+		Type: "object",
+		Properties: map[string]apiextv1.JSONSchemaProps{
+			"spec": {
+				Type: "object",
+				Properties: map[string]apiextv1.JSONSchemaProps{
+					"replicas": {Type: "integer"},
+					"ratio":    {Type: "number"},
+					"paused":   {Type: "boolean"},
+					"image":    {Type: "string"},
+					"port":     {XIntOrString: true},
+					"selector": {
+						Type: "object",
+						AdditionalProperties: &apiextv1.JSONSchemaPropsOrBool{
+							Schema: &apiextv1.JSONSchemaProps{Type: "string"},
+						},
+					},
+					"containers": {
+						Type: "array",
+						Items: &apiextv1.JSONSchemaPropsOrArray{
+							Schema: &apiextv1.JSONSchemaProps{
+								Type: "object",
+								Properties: map[string]apiextv1.JSONSchemaProps{
+									"name":     {Type: "string"},
+									"replicas": {Type: "integer"},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	testCases := map[string]struct {
+		schema        *apiextv1.JSONSchemaProps
+		override      ClusterOverride
+		expectedValue interface{}
+		expectedErr   bool
+	}{
+		"string where an integer is expected is coerced": {
+			schema:        schema,
+			override:      ClusterOverride{Path: "/spec/replicas", Value: "3"},
+			expectedValue: int64(3),
+		},
+		"string where an integer is expected is unchanged without a schema": {
+			override:      ClusterOverride{Path: "/spec/replicas", Value: "3"},
+			expectedValue: "3",
+		},
+		"integral number is an integer": {
+			schema:        schema,
+			override:      ClusterOverride{Path: "/spec/replicas", Value: float64(3)},
+			expectedValue: int64(3),
+		},
+		"fractional number where an integer is expected is rejected": {
+			schema:      schema,
+			override:    ClusterOverride{Path: "/spec/replicas", Value: 3.5},
+			expectedErr: true,
+		},
+		"non-numeric string where an integer is expected is rejected": {
+			schema:      schema,
+			override:    ClusterOverride{Op: "add", Path: "/spec/replicas", Value: "three"},
+			expectedErr: true,
+		},
+		"string where a number is expected is coerced": {
+			schema:        schema,
+			override:      ClusterOverride{Path: "/spec/ratio", Value: "0.5"},
+			expectedValue: 0.5,
+		},
+		"string where a boolean is expected is coerced": {
+			schema:        schema,
+			override:      ClusterOverride{Path: "/spec/paused", Value: "true"},
+			expectedValue: true,
+		},
+		"number where a string is expected is coerced": {
+			schema:        schema,
+			override:      ClusterOverride{Path: "/spec/image", Value: float64(1)},
+			expectedValue: "1",
+		},
+		"object where a string is expected is rejected": {
+			schema:      schema,
+			override:    ClusterOverride{Path: "/spec/image", Value: map[string]interface{}{}},
+			expectedErr: true,
+		},
+		"int-or-string is unchanged": {
+			schema:        schema,
+			override:      ClusterOverride{Path: "/spec/port", Value: "http"},
+			expectedValue: "http",
+		},
+		"values of an object are coerced": {
+			schema:        schema,
+			override:      ClusterOverride{Path: "/spec/selector", Value: map[string]interface{}{"tier": float64(1)}},
+			expectedValue: map[string]interface{}{"tier": "1"},
+		},
+		"field of an array item is coerced": {
+			schema:        schema,
+			override:      ClusterOverride{Path: "/spec/containers/0/replicas", Value: "2"},
+			expectedValue: int64(2),
+		},
+		"items of an array are coerced": {
+			schema:   schema,
+			override: ClusterOverride{Op: "add", Path: "/spec/containers/-", Value: map[string]interface{}{"name": "app", "replicas": "2"}},
+			expectedValue: map[string]interface{}{
+				"name":     "app",
+				"replicas": int64(2),
+			},
+		},
+		"field unknown to the schema is unchanged": {
+			schema:        schema,
+			override:      ClusterOverride{Path: "/spec/unknown", Value: "3"},
+			expectedValue: "3",
+		},
+		"value of a removal is ignored": {
+			schema:   schema,
+			override: ClusterOverride{Op: "remove", Path: "/spec/replicas"},
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			overrides := ClusterOverrides{tc.override}
+			validated, err := ValidateOverrides(overrides, tc.schema)
+			if tc.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedValue, validated[0].Value)
+			assert.Equal(t, tc.override, overrides[0], "the given overrides should not be modified")
+		})
+	}
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// OwnerReferencesAnnotation records on a federated resource the
+	// owners of the resources managed for it in member clusters. The
+	// uid of an owner differs between clusters, so owners are
+	// identified by name and are resolved in each member cluster
+	// when the managed resource is created or updated.
+	OwnerReferencesAnnotation = "kubefed.io/owner-references"
+)
+
+// FederatedOwnerReference identifies an owner of a managed resource
+// by its kind and name in the namespace of the managed resource.
+type FederatedOwnerReference struct {
+	APIVersion         string `json:"apiVersion"`
+	Kind               string `json:"kind"`
+	Name               string `json:"name"`
+	Controller         *bool  `json:"controller,omitempty"`
+	BlockOwnerDeletion *bool  `json:"blockOwnerDeletion,omitempty"`
+}
+
+// NewFederatedOwnerReference returns the federated form of the given
+// owner reference.
+func NewFederatedOwnerReference(ownerReference metav1.OwnerReference) FederatedOwnerReference {
+	return FederatedOwnerReference{
+		APIVersion:         ownerReference.APIVersion,
+		Kind:               ownerReference.Kind,
+		Name:               ownerReference.Name,
+		Controller:         ownerReference.Controller,
+		BlockOwnerDeletion: ownerReference.BlockOwnerDeletion,
+	}
+}
+
+// OwnerReference returns the owner reference to the given uid of the
+// owner in a member cluster.
+func (r FederatedOwnerReference) OwnerReference(uid types.UID) metav1.OwnerReference {
+	return metav1.OwnerReference{
+		APIVersion:         r.APIVersion,
+		Kind:               r.Kind,
+		Name:               r.Name,
+		UID:                uid,
+		Controller:         r.Controller,
+		BlockOwnerDeletion: r.BlockOwnerDeletion,
+	}
+}
+
+// ClusterUID returns the uid of the owner in the given owner
+// references of a resource in a member cluster, or an empty uid if
+// they do not reference the owner.
+func (r FederatedOwnerReference) ClusterUID(ownerReferences []metav1.OwnerReference) types.UID {
+	for _, ownerReference := range ownerReferences {
+		if ownerReference.APIVersion == r.APIVersion && ownerReference.Kind == r.Kind && ownerReference.Name == r.Name {
+			return ownerReference.UID
+		}
+	}
+	return ""
+}
+
+// GetFederatedOwnerReferences returns the owner references recorded
+// on the given federated resource.
+func GetFederatedOwnerReferences(obj metav1.Object) ([]FederatedOwnerReference, error) {
+	value, ok := obj.GetAnnotations()[OwnerReferencesAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	var ownerReferences []FederatedOwnerReference
+	if err := json.Unmarshal([]byte(value), &ownerReferences); err != nil {
+		return nil, errors.Wrapf(err, "Error decoding annotation %q", OwnerReferencesAnnotation)
+	}
+	return ownerReferences, nil
+}
+
+// SetFederatedOwnerReferences records the given owner references on
+// the given federated resource, or removes the record if there are no
+// owner references.
+func SetFederatedOwnerReferences(obj metav1.Object, ownerReferences []FederatedOwnerReference) error {
+	annotations := obj.GetAnnotations()
+	if len(ownerReferences) == 0 {
+		if _, ok := annotations[OwnerReferencesAnnotation]; ok {
+			delete(annotations, OwnerReferencesAnnotation)
+			obj.SetAnnotations(annotations)
+		}
+		return nil
+	}
+	value, err := json.Marshal(ownerReferences)
+	if err != nil {
+		return errors.Wrap(err, "Error encoding owner references")
+	}
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[OwnerReferencesAnnotation] = string(value)
+	obj.SetAnnotations(annotations)
+	return nil
+}
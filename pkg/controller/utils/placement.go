@@ -17,25 +17,78 @@ limitations under the License.
 package utils
 
 import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
 
 	fedv1b1 "sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
 )
 
+const (
+	// IntersectionPlacementInheritance indicates that the placement of
+	// a federated namespace constrains the placement of the resources
+	// it contains, which are placed to the intersection of their
+	// placement and that of the namespace. This is the default.
+	IntersectionPlacementInheritance = "Intersection"
+
+	// DefaultPlacementInheritance indicates that the placement of a
+	// federated namespace is only the default placement of the
+	// resources it contains. Resources that specify placement are
+	// placed verbatim, even to clusters the namespace is not placed to.
+	DefaultPlacementInheritance = "Default"
+)
+
+const (
+	// PropagatePlacementMode indicates that resources are propagated
+	// to a placed cluster. This is the default.
+	PropagatePlacementMode = "Propagate"
+
+	// PlacementOnlyMode indicates that a cluster is considered placed
+	// for status purposes but that resources are not propagated to it.
+	PlacementOnlyMode = "PlacementOnly"
+
+	// CanaryPlacementMode indicates that resources are propagated to
+	// a placed cluster before any other placed cluster, and that they
+	// are only propagated to the other clusters once they are healthy
+	// in all canary clusters.
+	CanaryPlacementMode = "Canary"
+)
+
 type GenericClusterReference struct {
 	Name string `json:"name"`
+	Mode string `json:"mode,omitempty"`
 }
 
 type GenericPlacementFields struct {
 	Clusters        []GenericClusterReference `json:"clusters,omitempty"`
 	ClusterSelector *metav1.LabelSelector     `json:"clusterSelector,omitempty"`
+	// ClusterSelectors select the clusters matching any of the
+	// selectors in addition to the clusters otherwise selected.
+	ClusterSelectors []metav1.LabelSelector `json:"clusterSelectors,omitempty"`
+	// MinHealthyClusters is the number of placed clusters that must
+	// be healthy for propagation to be considered successful.
+	MinHealthyClusters *int32 `json:"minHealthyClusters,omitempty"`
+	// Tolerations allow placement in clusters with matching taints.
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+	// Sample limits placement to a number of the selected clusters.
+	Sample *PlacementSample `json:"sample,omitempty"`
+	// StickinessSeconds is the number of seconds for which a cluster
+	// that is no longer selected remains placed.
+	StickinessSeconds *int64 `json:"stickinessSeconds,omitempty"`
 }
 
 type GenericPlacementSpec struct {
 	Placement GenericPlacementFields `json:"placement,omitempty"`
+	// PlacementInheritance is only set for federated namespaces and
+	// determines how their placement applies to the resources they
+	// contain.
+	PlacementInheritance string `json:"placementInheritance,omitempty"`
 }
 
 type GenericPlacement struct {
@@ -58,13 +111,37 @@ func (p *GenericPlacement) ClusterNames() []string {
 	if p.Spec.Placement.Clusters == nil {
 		return nil
 	}
-	var clusterNames []string
+	clusterNames := []string{}
 	for _, cluster := range p.Spec.Placement.Clusters {
 		clusterNames = append(clusterNames, cluster.Name)
 	}
 	return clusterNames
 }
 
+// PlacementOnlyClusterNames returns the names of the clusters
+// referenced with the PlacementOnly mode.
+func (p *GenericPlacement) PlacementOnlyClusterNames() sets.Set[string] {
+	clusterNames := sets.Set[string]{}
+	for _, cluster := range p.Spec.Placement.Clusters {
+		if cluster.Mode == PlacementOnlyMode {
+			clusterNames.Insert(cluster.Name)
+		}
+	}
+	return clusterNames
+}
+
+// CanaryClusterNames returns the names of the clusters referenced
+// with the Canary mode.
+func (p *GenericPlacement) CanaryClusterNames() sets.Set[string] {
+	clusterNames := sets.Set[string]{}
+	for _, cluster := range p.Spec.Placement.Clusters {
+		if cluster.Mode == CanaryPlacementMode {
+			clusterNames.Insert(cluster.Name)
+		}
+	}
+	return clusterNames
+}
+
 func (p *GenericPlacement) ClusterSelector() (labels.Selector, error) {
 	return metav1.LabelSelectorAsSelector(p.Spec.Placement.ClusterSelector)
 }
@@ -77,6 +154,35 @@ func GetClusterNames(obj *unstructured.Unstructured) ([]string, error) {
 	return placement.ClusterNames(), nil
 }
 
+// GetMinHealthyClusters returns the minimum number of healthy clusters
+// specified by the placement of the given object, or nil if all placed
+// clusters are required to be healthy.
+func GetMinHealthyClusters(obj *unstructured.Unstructured) (*int32, error) {
+	placement, err := UnmarshalGenericPlacement(obj)
+	if err != nil {
+		return nil, err
+	}
+	return placement.Spec.Placement.MinHealthyClusters, nil
+}
+
+func GetPlacementOnlyClusterNames(obj *unstructured.Unstructured) (sets.Set[string], error) {
+	placement, err := UnmarshalGenericPlacement(obj)
+	if err != nil {
+		return nil, err
+	}
+	return placement.PlacementOnlyClusterNames(), nil
+}
+
+// GetCanaryClusterNames returns the names of the clusters that the
+// placement of the given object references with the Canary mode.
+func GetCanaryClusterNames(obj *unstructured.Unstructured) (sets.Set[string], error) {
+	placement, err := UnmarshalGenericPlacement(obj)
+	if err != nil {
+		return nil, err
+	}
+	return placement.CanaryClusterNames(), nil
+}
+
 func SetClusterNames(obj *unstructured.Unstructured, clusterNames []string) error {
 	var clusters []interface{}
 	if clusterNames != nil {
@@ -90,16 +196,190 @@ func SetClusterNames(obj *unstructured.Unstructured, clusterNames []string) erro
 	return unstructured.SetNestedSlice(obj.Object, clusters, SpecField, PlacementField, ClustersField)
 }
 
+// RemoveClusterNames removes the named clusters from the clusters
+// listed in the placement of the given object and returns the names
+// that were removed. The mode of the remaining clusters is retained,
+// and a placement left without clusters selects no cluster.
+func RemoveClusterNames(obj *unstructured.Unstructured, clusterNames sets.Set[string]) (sets.Set[string], error) {
+	clusters, found, err := unstructured.NestedSlice(obj.Object, SpecField, PlacementField, ClustersField)
+	if err != nil || !found {
+		return nil, err
+	}
+	removed := sets.Set[string]{}
+	retained := []interface{}{}
+	for _, rawCluster := range clusters {
+		cluster, ok := rawCluster.(map[string]interface{})
+		if ok {
+			if name, _ := cluster[NameField].(string); clusterNames.Has(name) {
+				removed.Insert(name)
+				continue
+			}
+		}
+		retained = append(retained, rawCluster)
+	}
+	if removed.Len() == 0 {
+		return removed, nil
+	}
+	return removed, unstructured.SetNestedSlice(obj.Object, retained, SpecField, PlacementField, ClustersField)
+}
+
+// SetCanaryClusterNames sets the mode of the clusters listed in the
+// placement of the given object to Canary for the named clusters and
+// to the default for the others.
+func SetCanaryClusterNames(obj *unstructured.Unstructured, clusterNames sets.Set[string]) error {
+	clusters, _, err := unstructured.NestedSlice(obj.Object, SpecField, PlacementField, ClustersField)
+	if err != nil {
+		return err
+	}
+	for _, rawCluster := range clusters {
+		cluster, ok := rawCluster.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _ := cluster[NameField].(string)
+		if clusterNames.Has(name) {
+			cluster[ModeField] = CanaryPlacementMode
+		} else if cluster[ModeField] == CanaryPlacementMode {
+			delete(cluster, ModeField)
+		}
+	}
+	return unstructured.SetNestedSlice(obj.Object, clusters, SpecField, PlacementField, ClustersField)
+}
+
 func SetClusterSelector(obj *unstructured.Unstructured, clusterSelector map[string]string) error {
 	return unstructured.SetNestedStringMap(obj.Object, clusterSelector, SpecField, PlacementField, ClusterSelectorField, MatchLabelsField)
 }
 
+// SetClusterSelectors sets the selectors of the placement of the given
+// federated resource that select clusters matching any of the given
+// sets of labels, removing them if none are given.
+func SetClusterSelectors(obj *unstructured.Unstructured, clusterSelectors []map[string]string) error {
+	if len(clusterSelectors) == 0 {
+		unstructured.RemoveNestedField(obj.Object, SpecField, PlacementField, ClusterSelectorsField)
+		return nil
+	}
+	values := make([]interface{}, 0, len(clusterSelectors))
+	for _, matchLabels := range clusterSelectors {
+		selector := map[string]interface{}{}
+		for key, value := range matchLabels {
+			selector[key] = value
+		}
+		values = append(values, map[string]interface{}{MatchLabelsField: selector})
+	}
+	return unstructured.SetNestedSlice(obj.Object, values, SpecField, PlacementField, ClusterSelectorsField)
+}
+
+// SetTolerations sets the tolerations of the placement of the given
+// federated resource, removing them if none are given.
+func SetTolerations(obj *unstructured.Unstructured, tolerations []corev1.Toleration) error {
+	if len(tolerations) == 0 {
+		unstructured.RemoveNestedField(obj.Object, SpecField, PlacementField, TolerationsField)
+		return nil
+	}
+	values := make([]interface{}, 0, len(tolerations))
+	for i := range tolerations {
+		value, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&tolerations[i])
+		if err != nil {
+			return err
+		}
+		values = append(values, value)
+	}
+	return unstructured.SetNestedSlice(obj.Object, values, SpecField, PlacementField, TolerationsField)
+}
+
+// SetPlacementSample sets the number of clusters sampled by the
+// placement of the given federated resource, removing the sample if
+// the count is nil.
+func SetPlacementSample(obj *unstructured.Unstructured, count *int32) error {
+	if count == nil {
+		unstructured.RemoveNestedField(obj.Object, SpecField, PlacementField, SampleField)
+		return nil
+	}
+	return unstructured.SetNestedField(obj.Object, int64(*count), SpecField, PlacementField, SampleField, CountField)
+}
+
+// ValidateSelectorMatchesAny returns whether the given selector
+// matches the labels of at least one of the given clusters. A selector
+// that matches no cluster often indicates a typo in a label key or
+// value, but the result is advisory since matching clusters may be
+// added later.
+func ValidateSelectorMatchesAny(selector *metav1.LabelSelector, clusters []*fedv1b1.KubeFedCluster) (bool, error) {
+	labelSelector, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return false, err
+	}
+	for _, cluster := range clusters {
+		if labelSelector.Matches(labels.Set(cluster.Labels)) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// PlacementWarnings returns advisory warnings for the placement of the
+// given federated resource, suitable for surfacing as admission
+// warnings. A warning is returned if the placement is determined by a
+// cluster selector that currently matches none of the given clusters.
+func PlacementWarnings(obj *unstructured.Unstructured, clusters []*fedv1b1.KubeFedCluster) ([]string, error) {
+	placement, err := UnmarshalGenericPlacement(obj)
+	if err != nil {
+		return nil, err
+	}
+	clusterSelector := placement.Spec.Placement.ClusterSelector
+	// The selector is ignored if cluster names are provided, and the
+	// absence of both is an explicit request for no placement.
+	if placement.ClusterNames() != nil || clusterSelector == nil {
+		return nil, nil
+	}
+	matchesAny, err := ValidateSelectorMatchesAny(clusterSelector, clusters)
+	if err != nil || matchesAny {
+		return nil, err
+	}
+	return []string{
+		fmt.Sprintf("spec.placement.clusterSelector %q does not match any KubeFedCluster", metav1.FormatLabelSelector(clusterSelector)),
+	}, nil
+}
+
+// GetPlacementInheritance returns how the placement of the given
+// federated namespace applies to the resources it contains.
+func GetPlacementInheritance(namespace *unstructured.Unstructured) (string, error) {
+	placement, err := UnmarshalGenericPlacement(namespace)
+	if err != nil {
+		return "", err
+	}
+	if placement.Spec.PlacementInheritance == DefaultPlacementInheritance {
+		return DefaultPlacementInheritance, nil
+	}
+	return IntersectionPlacementInheritance, nil
+}
+
+// SetPlacementInheritance sets how the placement of the given
+// federated namespace applies to the resources it contains.
+func SetPlacementInheritance(namespace *unstructured.Unstructured, inheritance string) error {
+	return unstructured.SetNestedField(namespace.Object, inheritance, SpecField, PlacementInheritanceField)
+}
+
+// specifiesPlacement returns whether the given federated resource
+// specifies placement, as opposed to having no placement fields.
+func specifiesPlacement(resource *unstructured.Unstructured) (bool, error) {
+	placement, err := UnmarshalGenericPlacement(resource)
+	if err != nil {
+		return false, err
+	}
+	fields := placement.Spec.Placement
+	return fields.Clusters != nil || fields.ClusterSelector != nil || len(fields.ClusterSelectors) > 0, nil
+}
+
 // ComputeNamespacedPlacement determines placement for namespaced
 // federated resources (e.g. FederatedConfigMap).
 //
 // If KubeFed is deployed cluster-wide, placement is the intersection
 // of the placement for the federated resource and the placement of
-// the federated namespace containing the resource.
+// the federated namespace containing the resource. If the placement
+// inheritance of the federated namespace is Default, the namespace
+// placement is instead only used for a resource that does not specify
+// placement, and the placement of a resource that does is used
+// verbatim.
 //
 // If KubeFed is limited to a single namespace, placement is
 // determined as the intersection of resource and namespace placement
@@ -109,7 +389,10 @@ func SetClusterSelector(obj *unstructured.Unstructured, clusterSelector map[stri
 // clusters, so namespace placement becomes a mechanism for limiting
 // rather than allowing propagation.
 func ComputeNamespacedPlacement(resource, namespace *unstructured.Unstructured, clusters []*fedv1b1.KubeFedCluster, limitedScope bool, selectorOnly bool) (selectedClusters sets.Set[string], err error) {
-	resourceClusters, err := ComputePlacement(resource, clusters, selectorOnly)
+	// A sample of the resource placement is taken from the clusters
+	// it may be placed to, so that it is not reduced by the namespace
+	// placement.
+	resourceClusters, err := eligibleClusterNames(resource, clusters, selectorOnly)
 	if err != nil {
 		return nil, err
 	}
@@ -119,7 +402,7 @@ func ComputeNamespacedPlacement(resource, namespace *unstructured.Unstructured,
 			// Use the resource placement verbatim if no federated
 			// namespace is present and KubeFed is targeting a
 			// single namespace.
-			return resourceClusters, nil
+			return samplePlacement(resource, resourceClusters, clusters)
 		}
 		// Resource should not exist in any member clusters.
 		return sets.Set[string]{}, nil
@@ -130,20 +413,207 @@ func ComputeNamespacedPlacement(resource, namespace *unstructured.Unstructured,
 		return nil, err
 	}
 
+	inheritance, err := GetPlacementInheritance(namespace)
+	if err != nil {
+		return nil, err
+	}
+	if inheritance == DefaultPlacementInheritance {
+		resourcePlaced, err := specifiesPlacement(resource)
+		if err != nil {
+			return nil, err
+		}
+		if resourcePlaced {
+			return samplePlacement(resource, resourceClusters, clusters)
+		}
+		// The namespace placement is inherited, subject to the
+		// tolerations of the resource.
+		taintedNames, err := UntoleratedClusterNames(resource, clusters)
+		if err != nil {
+			return nil, err
+		}
+		return namespaceClusters.Difference(taintedNames), nil
+	}
+
 	// If both namespace and resource placement exist, the desired
 	// list of clusters is their intersection.
-	return resourceClusters.Intersection(namespaceClusters), nil
+	return samplePlacement(resource, resourceClusters.Intersection(namespaceClusters), clusters)
 }
 
 // ComputePlacement determines the selected clusters for a federated
-// resource.
+// resource. Clusters with taints that the placement of the resource
+// does not tolerate are excluded, except that a NoSchedule taint does
+// not exclude a cluster to which the resource is already placed. If
+// the placement specifies a sample, only the sampled clusters are
+// selected.
 func ComputePlacement(resource *unstructured.Unstructured, clusters []*fedv1b1.KubeFedCluster, selectorOnly bool) (selectedClusters sets.Set[string], err error) {
+	eligibleNames, err := eligibleClusterNames(resource, clusters, selectorOnly)
+	if err != nil {
+		return nil, err
+	}
+	return samplePlacement(resource, eligibleNames, clusters)
+}
+
+// PlacementExclusionReason is the reason that a cluster is not in the
+// placement of a federated resource.
+type PlacementExclusionReason string
+
+const (
+	// ClusterNotSelected indicates that the placement of the resource
+	// neither names the cluster nor selects it by its labels.
+	ClusterNotSelected PlacementExclusionReason = "NotSelected"
+	// ClusterTaintNotTolerated indicates that the cluster has a taint
+	// that the placement of the resource does not tolerate.
+	ClusterTaintNotTolerated PlacementExclusionReason = "TaintNotTolerated"
+	// ClusterNotPlacedForNamespace indicates that the federated
+	// namespace containing the resource is not placed in the cluster.
+	ClusterNotPlacedForNamespace PlacementExclusionReason = "NamespaceNotPlaced"
+	// ClusterNotSampled indicates that the cluster is eligible but was
+	// not among the clusters sampled by the placement of the resource.
+	ClusterNotSampled PlacementExclusionReason = "NotSampled"
+)
+
+// PlacementExclusions returns the reason that each of the given
+// clusters that is not among the given selected clusters, as computed
+// by ComputePlacement or ComputeNamespacedPlacement, is excluded from
+// the placement of the given federated resource, keyed by cluster
+// name. The federated namespace is nil unless namespace placement
+// applies to the resource.
+func PlacementExclusions(resource, namespace *unstructured.Unstructured, clusters []*fedv1b1.KubeFedCluster, selectedClusters sets.Set[string]) (map[string]PlacementExclusionReason, error) {
+	resourceNames, err := selectedClusterNames(resource, clusters, false)
+	if err != nil {
+		return nil, err
+	}
+	taintedNames, err := UntoleratedClusterNames(resource, clusters)
+	if err != nil {
+		return nil, err
+	}
+
+	// The namespace placement either constrains the placement of the
+	// resource, replaces it when inherited, or does not apply.
+	var namespaceNames sets.Set[string]
+	intersected, inherited := false, false
+	if namespace != nil {
+		namespaceNames, err = ComputePlacement(namespace, clusters, false)
+		if err != nil {
+			return nil, err
+		}
+		inheritance, err := GetPlacementInheritance(namespace)
+		if err != nil {
+			return nil, err
+		}
+		intersected = true
+		if inheritance == DefaultPlacementInheritance {
+			resourcePlaced, err := specifiesPlacement(resource)
+			if err != nil {
+				return nil, err
+			}
+			intersected, inherited = false, !resourcePlaced
+		}
+	}
+
+	reasons := make(map[string]PlacementExclusionReason)
+	for _, cluster := range clusters {
+		name := cluster.Name
+		switch {
+		case selectedClusters.Has(name):
+			continue
+		case inherited && !namespaceNames.Has(name):
+			reasons[name] = ClusterNotPlacedForNamespace
+		case !inherited && !resourceNames.Has(name):
+			reasons[name] = ClusterNotSelected
+		case taintedNames.Has(name):
+			reasons[name] = ClusterTaintNotTolerated
+		case intersected && !namespaceNames.Has(name):
+			reasons[name] = ClusterNotPlacedForNamespace
+		default:
+			reasons[name] = ClusterNotSampled
+		}
+	}
+	return reasons, nil
+}
+
+// eligibleClusterNames returns the names of the clusters selected by
+// the placement of a federated resource that it may be placed to.
+func eligibleClusterNames(resource *unstructured.Unstructured, clusters []*fedv1b1.KubeFedCluster, selectorOnly bool) (sets.Set[string], error) {
 	selectedNames, err := selectedClusterNames(resource, clusters, selectorOnly)
 	if err != nil {
 		return nil, err
 	}
+	taintedNames, err := UntoleratedClusterNames(resource, clusters)
+	if err != nil {
+		return nil, err
+	}
 	clusterNames := getClusterNames(clusters)
-	return clusterNames.Intersection(selectedNames), nil
+	return clusterNames.Intersection(selectedNames).Difference(taintedNames), nil
+}
+
+// UntoleratedClusterNames returns the names of the clusters that a
+// federated resource may not be placed in due to taints that its
+// placement does not tolerate. The clusters reported in the status of
+// the resource are those it is already placed to, which a NoSchedule
+// taint does not exclude.
+func UntoleratedClusterNames(resource *unstructured.Unstructured, clusters []*fedv1b1.KubeFedCluster) (sets.Set[string], error) {
+	names := sets.Set[string]{}
+	var tolerations []corev1.Toleration
+	var placedNames sets.Set[string]
+	for _, cluster := range clusters {
+		if len(cluster.Spec.Taints) == 0 {
+			continue
+		}
+		if placedNames == nil {
+			placement, err := UnmarshalGenericPlacement(resource)
+			if err != nil {
+				return nil, err
+			}
+			tolerations = placement.Spec.Placement.Tolerations
+			placedNames, err = statusClusterNames(resource)
+			if err != nil {
+				return nil, err
+			}
+		}
+		for i := range cluster.Spec.Taints {
+			taint := &cluster.Spec.Taints[i]
+			if taint.Effect == corev1.TaintEffectNoSchedule && placedNames.Has(cluster.Name) {
+				continue
+			}
+			if !tolerationsTolerateTaint(tolerations, taint) {
+				names.Insert(cluster.Name)
+				break
+			}
+		}
+	}
+	return names, nil
+}
+
+func tolerationsTolerateTaint(tolerations []corev1.Toleration, taint *corev1.Taint) bool {
+	for i := range tolerations {
+		// Comparison operators are not supported for taints of
+		// clusters.
+		if tolerations[i].ToleratesTaint(klog.Background(), taint, false) {
+			return true
+		}
+	}
+	return false
+}
+
+// statusClusterNames returns the names of the clusters reported in
+// the status of the given federated resource.
+func statusClusterNames(resource *unstructured.Unstructured) (sets.Set[string], error) {
+	names := sets.Set[string]{}
+	clusters, _, err := unstructured.NestedSlice(resource.Object, StatusField, ClustersField)
+	if err != nil {
+		return nil, err
+	}
+	for _, rawCluster := range clusters {
+		cluster, ok := rawCluster.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if name, ok := cluster[NameField].(string); ok {
+			names.Insert(name)
+		}
+	}
+	return names, nil
 }
 
 func selectedClusterNames(resource *unstructured.Unstructured, clusters []*fedv1b1.KubeFedCluster, selectorOnly bool) (sets.Set[string], error) {
@@ -172,6 +642,20 @@ func selectedClusterNames(resource *unstructured.Unstructured, clusters []*fedv1
 		}
 	}
 
+	// Clusters matching any of the additional selectors are selected
+	// regardless of whether cluster names are provided.
+	for i := range placement.Spec.Placement.ClusterSelectors {
+		selector, err := metav1.LabelSelectorAsSelector(&placement.Spec.Placement.ClusterSelectors[i])
+		if err != nil {
+			return nil, err
+		}
+		for _, cluster := range clusters {
+			if selector.Matches(labels.Set(cluster.Labels)) {
+				selectedNames.Insert(cluster.Name)
+			}
+		}
+	}
+
 	return selectedNames, nil
 }
 
@@ -20,6 +20,7 @@ import (
 	"reflect"
 	"testing"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -102,3 +103,530 @@ func TestSelectedClusterNames(t *testing.T) {
 		})
 	}
 }
+
+func TestSelectedClusterNamesWithClusterSelectors(t *testing.T) {
+	clusters := []*fedv1b1.KubeFedCluster{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   "cluster1",
+				Labels: map[string]string{"region": "us"},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   "cluster2",
+				Labels: map[string]string{"tier": "edge"},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   "cluster3",
+				Labels: map[string]string{"region": "eu"},
+			},
+		},
+	}
+
+	testCases := map[string]struct {
+		clusterNames     []string
+		clusterSelector  map[string]string
+		clusterSelectors []map[string]string
+		expectedNames    sets.Set[string]
+	}{
+		"clusters matching any selector": {
+			clusterSelectors: []map[string]string{{"region": "us"}, {"tier": "edge"}},
+			expectedNames:    sets.New("cluster1", "cluster2"),
+		},
+		"clusters matching no selector": {
+			clusterSelectors: []map[string]string{{"region": "ap"}},
+			expectedNames:    sets.New[string](),
+		},
+		"union with cluster names": {
+			clusterNames:     []string{"cluster3"},
+			clusterSelectors: []map[string]string{{"tier": "edge"}},
+			expectedNames:    sets.New("cluster2", "cluster3"),
+		},
+		"union with cluster names when empty": {
+			clusterNames:     []string{},
+			clusterSelectors: []map[string]string{{"tier": "edge"}},
+			expectedNames:    sets.New("cluster2"),
+		},
+		"union with cluster selector": {
+			clusterSelector:  map[string]string{"region": "eu"},
+			clusterSelectors: []map[string]string{{"tier": "edge"}},
+			expectedNames:    sets.New("cluster2", "cluster3"),
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			obj := &unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"spec": make(map[string]interface{}),
+				},
+			}
+			if err := SetClusterNames(obj, testCase.clusterNames); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if testCase.clusterSelector != nil {
+				if err := SetClusterSelector(obj, testCase.clusterSelector); err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+			}
+			if err := SetClusterSelectors(obj, testCase.clusterSelectors); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			selectedNames, err := selectedClusterNames(obj, clusters, false)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !selectedNames.Equal(testCase.expectedNames) {
+				t.Fatalf("Expected names %v, got %v", sets.List(testCase.expectedNames), sets.List(selectedNames))
+			}
+		})
+	}
+}
+
+func TestComputePlacementWithTaints(t *testing.T) {
+	newCluster := func(name string, taints ...corev1.Taint) *fedv1b1.KubeFedCluster {
+		return &fedv1b1.KubeFedCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       fedv1b1.KubeFedClusterSpec{Taints: taints},
+		}
+	}
+	noSchedule := corev1.Taint{Key: "draining", Value: "true", Effect: corev1.TaintEffectNoSchedule}
+	noExecute := corev1.Taint{Key: "draining", Value: "true", Effect: corev1.TaintEffectNoExecute}
+	clusters := []*fedv1b1.KubeFedCluster{
+		newCluster("cluster1"),
+		newCluster("cluster2", noSchedule),
+		newCluster("cluster3", noExecute),
+	}
+
+	testCases := map[string]struct {
+		tolerations   []corev1.Toleration
+		placedNames   []string
+		expectedNames sets.Set[string]
+	}{
+		"tainted clusters are excluded without tolerations": {
+			expectedNames: sets.New("cluster1"),
+		},
+		"existing placement is retained for NoSchedule taints": {
+			placedNames:   []string{"cluster1", "cluster2", "cluster3"},
+			expectedNames: sets.New("cluster1", "cluster2"),
+		},
+		"tolerated taints do not exclude clusters": {
+			tolerations: []corev1.Toleration{
+				{Key: "draining", Operator: corev1.TolerationOpEqual, Value: "true"},
+			},
+			expectedNames: sets.New("cluster1", "cluster2", "cluster3"),
+		},
+		"tolerations only tolerate taints with their effect": {
+			tolerations: []corev1.Toleration{
+				{Key: "draining", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+			},
+			expectedNames: sets.New("cluster1", "cluster2"),
+		},
+		"tolerations only tolerate taints with their value": {
+			tolerations: []corev1.Toleration{
+				{Key: "draining", Operator: corev1.TolerationOpEqual, Value: "false"},
+			},
+			expectedNames: sets.New("cluster1"),
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			obj := &unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"spec": make(map[string]interface{}),
+				},
+			}
+			if err := SetClusterSelector(obj, map[string]string{}); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if err := SetTolerations(obj, testCase.tolerations); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			var placedClusters []interface{}
+			for _, clusterName := range testCase.placedNames {
+				placedClusters = append(placedClusters, map[string]interface{}{NameField: clusterName})
+			}
+			if err := unstructured.SetNestedSlice(obj.Object, placedClusters, StatusField, ClustersField); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			selectedNames, err := ComputePlacement(obj, clusters, false)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !selectedNames.Equal(testCase.expectedNames) {
+				t.Fatalf("Expected names %v, got %v", sets.List(testCase.expectedNames), sets.List(selectedNames))
+			}
+		})
+	}
+}
+
+func TestComputeNamespacedPlacementInheritance(t *testing.T) {
+	clusters := []*fedv1b1.KubeFedCluster{
+		{ObjectMeta: metav1.ObjectMeta{Name: "cluster1"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "cluster2"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "cluster3"}},
+	}
+
+	testCases := map[string]struct {
+		inheritance   string
+		resourceNames []string
+		expectedNames sets.Set[string]
+	}{
+		"placement is intersected by default": {
+			resourceNames: []string{"cluster2", "cluster3"},
+			expectedNames: sets.New("cluster2"),
+		},
+		"placement is intersected in intersection mode": {
+			inheritance:   IntersectionPlacementInheritance,
+			resourceNames: []string{"cluster2", "cluster3"},
+			expectedNames: sets.New("cluster2"),
+		},
+		"resource without placement is not placed in intersection mode": {
+			inheritance:   IntersectionPlacementInheritance,
+			expectedNames: sets.New[string](),
+		},
+		"resource placement may exceed the namespace in default mode": {
+			inheritance:   DefaultPlacementInheritance,
+			resourceNames: []string{"cluster2", "cluster3"},
+			expectedNames: sets.New("cluster2", "cluster3"),
+		},
+		"empty resource placement is used verbatim in default mode": {
+			inheritance:   DefaultPlacementInheritance,
+			resourceNames: []string{},
+			expectedNames: sets.New[string](),
+		},
+		"resource without placement inherits namespace placement in default mode": {
+			inheritance:   DefaultPlacementInheritance,
+			expectedNames: sets.New("cluster1", "cluster2"),
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			namespace := &unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"spec": make(map[string]interface{}),
+				},
+			}
+			if err := SetClusterNames(namespace, []string{"cluster1", "cluster2"}); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(testCase.inheritance) > 0 {
+				if err := SetPlacementInheritance(namespace, testCase.inheritance); err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+			}
+			resource := &unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"spec": make(map[string]interface{}),
+				},
+			}
+			if testCase.resourceNames != nil {
+				if err := SetClusterNames(resource, testCase.resourceNames); err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+			}
+
+			selectedNames, err := ComputeNamespacedPlacement(resource, namespace, clusters, false, false)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !selectedNames.Equal(testCase.expectedNames) {
+				t.Fatalf("Expected names %v, got %v", sets.List(testCase.expectedNames), sets.List(selectedNames))
+			}
+		})
+	}
+}
+
+func TestPlacementExclusions(t *testing.T) {
+	clusters := []*fedv1b1.KubeFedCluster{
+		{ObjectMeta: metav1.ObjectMeta{Name: "cluster1"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "cluster2"}},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster3"},
+			Spec: fedv1b1.KubeFedClusterSpec{
+				Taints: []corev1.Taint{{Key: "draining", Value: "true", Effect: corev1.TaintEffectNoExecute}},
+			},
+		},
+		{ObjectMeta: metav1.ObjectMeta{Name: "cluster4"}},
+	}
+	newObject := func(clusterNames []string) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{
+			"spec": make(map[string]interface{}),
+		}}
+		if clusterNames != nil {
+			if err := SetClusterNames(obj, clusterNames); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		}
+		return obj
+	}
+
+	testCases := map[string]struct {
+		resourceNames  []string
+		namespaceNames []string
+		inheritance    string
+		expected       map[string]PlacementExclusionReason
+	}{
+		"resource placement": {
+			resourceNames: []string{"cluster1", "cluster3"},
+			expected: map[string]PlacementExclusionReason{
+				"cluster2": ClusterNotSelected,
+				"cluster3": ClusterTaintNotTolerated,
+				"cluster4": ClusterNotSelected,
+			},
+		},
+		"intersection with namespace placement": {
+			resourceNames:  []string{"cluster1", "cluster2", "cluster3"},
+			namespaceNames: []string{"cluster1", "cluster4"},
+			expected: map[string]PlacementExclusionReason{
+				"cluster2": ClusterNotPlacedForNamespace,
+				"cluster3": ClusterTaintNotTolerated,
+				"cluster4": ClusterNotSelected,
+			},
+		},
+		"inherited namespace placement": {
+			namespaceNames: []string{"cluster1", "cluster3"},
+			inheritance:    DefaultPlacementInheritance,
+			// The taint also excludes cluster3 from the placement of
+			// the namespace.
+			expected: map[string]PlacementExclusionReason{
+				"cluster2": ClusterNotPlacedForNamespace,
+				"cluster3": ClusterNotPlacedForNamespace,
+				"cluster4": ClusterNotPlacedForNamespace,
+			},
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			resource := newObject(tc.resourceNames)
+			var namespace *unstructured.Unstructured
+			var selectedNames sets.Set[string]
+			var err error
+			if tc.namespaceNames != nil {
+				namespace = newObject(tc.namespaceNames)
+				if len(tc.inheritance) > 0 {
+					if err := SetPlacementInheritance(namespace, tc.inheritance); err != nil {
+						t.Fatalf("Unexpected error: %v", err)
+					}
+				}
+				selectedNames, err = ComputeNamespacedPlacement(resource, namespace, clusters, false, false)
+			} else {
+				selectedNames, err = ComputePlacement(resource, clusters, false)
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			reasons, err := PlacementExclusions(resource, namespace, clusters, selectedNames)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(reasons, tc.expected) {
+				t.Fatalf("Expected exclusions %v, got %v", tc.expected, reasons)
+			}
+		})
+	}
+}
+
+func TestGetPlacementOnlyClusterNames(t *testing.T) {
+	obj := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"placement": map[string]interface{}{
+					"clusters": []interface{}{
+						map[string]interface{}{
+							"name": "cluster1",
+						},
+						map[string]interface{}{
+							"name": "cluster2",
+							"mode": PropagatePlacementMode,
+						},
+						map[string]interface{}{
+							"name": "cluster3",
+							"mode": PlacementOnlyMode,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	clusterNames, err := GetPlacementOnlyClusterNames(obj)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expectedNames := sets.New[string]("cluster3")
+	if !clusterNames.Equal(expectedNames) {
+		t.Fatalf("Expected names %v, got %v", sets.List(expectedNames), sets.List(clusterNames))
+	}
+}
+
+func TestSetCanaryClusterNames(t *testing.T) {
+	obj := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"placement": map[string]interface{}{
+					"clusters": []interface{}{
+						map[string]interface{}{
+							"name": "cluster1",
+							"mode": CanaryPlacementMode,
+						},
+						map[string]interface{}{
+							"name": "cluster2",
+						},
+						map[string]interface{}{
+							"name": "cluster3",
+							"mode": PlacementOnlyMode,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	if err := SetCanaryClusterNames(obj, sets.New[string]("cluster2")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	clusterNames, err := GetCanaryClusterNames(obj)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expectedNames := sets.New[string]("cluster2")
+	if !clusterNames.Equal(expectedNames) {
+		t.Fatalf("Expected canary names %v, got %v", sets.List(expectedNames), sets.List(clusterNames))
+	}
+	placementOnlyNames, err := GetPlacementOnlyClusterNames(obj)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !placementOnlyNames.Equal(sets.New[string]("cluster3")) {
+		t.Fatalf("Expected the mode of other clusters to be retained, got placement-only names %v", sets.List(placementOnlyNames))
+	}
+}
+
+func TestRemoveClusterNames(t *testing.T) {
+	obj := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"placement": map[string]interface{}{
+					"clusters": []interface{}{
+						map[string]interface{}{
+							"name": "cluster1",
+						},
+						map[string]interface{}{
+							"name": "cluster2",
+							"mode": PlacementOnlyMode,
+						},
+						map[string]interface{}{
+							"name": "cluster3",
+							"mode": PlacementOnlyMode,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	removed, err := RemoveClusterNames(obj, sets.New[string]("cluster2", "cluster4"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !removed.Equal(sets.New[string]("cluster2")) {
+		t.Fatalf("Expected cluster2 to be removed, got %v", sets.List(removed))
+	}
+	clusterNames, err := GetClusterNames(obj)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := []string{"cluster1", "cluster3"}; !reflect.DeepEqual(clusterNames, expected) {
+		t.Fatalf("Expected cluster names %v, got %v", expected, clusterNames)
+	}
+	placementOnlyNames, err := GetPlacementOnlyClusterNames(obj)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !placementOnlyNames.Equal(sets.New[string]("cluster3")) {
+		t.Fatalf("Expected the mode of other clusters to be retained, got placement-only names %v", sets.List(placementOnlyNames))
+	}
+
+	// A placement left without clusters selects no cluster rather
+	// than falling back to the cluster selector.
+	if _, err := RemoveClusterNames(obj, sets.New[string]("cluster1", "cluster3")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	clusterNames, err = GetClusterNames(obj)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if clusterNames == nil || len(clusterNames) > 0 {
+		t.Fatalf("Expected an empty list of cluster names, got %#v", clusterNames)
+	}
+}
+
+func TestPlacementWarnings(t *testing.T) {
+	clusters := []*fedv1b1.KubeFedCluster{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "cluster1",
+				Labels: map[string]string{
+					"region": "us-east",
+				},
+			},
+		},
+	}
+
+	testCases := map[string]struct {
+		clusterNames    []string
+		clusterSelector map[string]string
+		expectWarning   bool
+	}{
+		"no warning when selector matches a cluster": {
+			clusterSelector: map[string]string{"region": "us-east"},
+		},
+		"no warning when selector is empty": {
+			clusterSelector: map[string]string{},
+		},
+		"warning when selector matches no cluster": {
+			clusterSelector: map[string]string{"regoin": "us-east"},
+			expectWarning:   true,
+		},
+		"no warning when cluster names override selector": {
+			clusterNames:    []string{"cluster1"},
+			clusterSelector: map[string]string{"regoin": "us-east"},
+		},
+		"no warning when placement is absent": {},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			obj := &unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"spec": make(map[string]interface{}),
+				},
+			}
+			if err := SetClusterNames(obj, testCase.clusterNames); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if testCase.clusterSelector != nil {
+				if err := SetClusterSelector(obj, testCase.clusterSelector); err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+			}
+
+			warnings, err := PlacementWarnings(obj, clusters)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if testCase.expectWarning != (len(warnings) > 0) {
+				t.Fatalf("Expected warning %v, got %v", testCase.expectWarning, warnings)
+			}
+		})
+	}
+}
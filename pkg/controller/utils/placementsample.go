@@ -0,0 +1,126 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"math"
+	"sort"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	fedv1b1 "sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
+)
+
+const (
+	// PlacementCapacityAnnotation on a KubeFedCluster gives the
+	// capacity of the cluster relative to other clusters, weighting
+	// its selection by sampled placement. The value must be a
+	// positive integer, and clusters without a valid capacity have a
+	// capacity of 1.
+	PlacementCapacityAnnotation = "kubefed.io/placement-capacity"
+
+	defaultPlacementCapacity = 1
+)
+
+// PlacementSample limits placement to a number of the clusters
+// otherwise selected, sampled with a probability proportional to their
+// capacity.
+type PlacementSample struct {
+	// Count is the number of clusters to place to. All selected
+	// clusters are placed to if fewer are selected.
+	Count int32 `json:"count"`
+}
+
+// ClusterPlacementCapacity returns the capacity of the given cluster
+// for sampled placement.
+func ClusterPlacementCapacity(cluster *fedv1b1.KubeFedCluster) int64 {
+	capacity, err := strconv.ParseInt(cluster.GetAnnotations()[PlacementCapacityAnnotation], 10, 64)
+	if err != nil || capacity <= 0 {
+		return defaultPlacementCapacity
+	}
+	return capacity
+}
+
+// samplePlacement returns the clusters sampled from the given selected
+// clusters if the placement of the given federated resource specifies
+// a sample, and the selected clusters otherwise.
+//
+// Sampling uses weighted rendezvous hashing: each selected cluster is
+// scored by hashing it together with the UID of the resource, scaled
+// by the capacity of the cluster, and the clusters with the highest
+// scores are placed to. The placement of a resource is therefore
+// stable for a given set of clusters and capacities, and changes to
+// the clusters disrupt as few placements as possible. Adding a cluster
+// only moves the resources for which the new cluster scores among the
+// highest, and removing a cluster only moves the resources placed to
+// it, each to the cluster with the next highest score.
+func samplePlacement(resource *unstructured.Unstructured, selectedNames sets.Set[string], clusters []*fedv1b1.KubeFedCluster) (sets.Set[string], error) {
+	placement, err := UnmarshalGenericPlacement(resource)
+	if err != nil {
+		return nil, err
+	}
+	sample := placement.Spec.Placement.Sample
+	if sample == nil || int(sample.Count) >= selectedNames.Len() {
+		return selectedNames, nil
+	}
+
+	// Resources are seeded by their name until they are assigned a
+	// UID, which happens on creation.
+	seed := string(resource.GetUID())
+	if len(seed) == 0 {
+		seed = NewQualifiedName(resource).String()
+	}
+	type scoredCluster struct {
+		name  string
+		score float64
+	}
+	var scored []scoredCluster
+	for _, cluster := range clusters {
+		if !selectedNames.Has(cluster.Name) {
+			continue
+		}
+		scored = append(scored, scoredCluster{
+			name:  cluster.Name,
+			score: rendezvousScore(seed, cluster.Name, ClusterPlacementCapacity(cluster)),
+		})
+	}
+	sort.Slice(scored, func(i, j int) bool {
+		if scored[i].score != scored[j].score {
+			return scored[i].score > scored[j].score
+		}
+		return scored[i].name < scored[j].name
+	})
+
+	sampledNames := sets.Set[string]{}
+	for i := 0; i < int(sample.Count) && i < len(scored); i++ {
+		sampledNames.Insert(scored[i].name)
+	}
+	return sampledNames, nil
+}
+
+// rendezvousScore returns the weighted rendezvous hashing score of a
+// cluster for the given seed. The hash is mapped to the open interval
+// (0, 1) so that the score is finite and positive.
+func rendezvousScore(seed, clusterName string, capacity int64) float64 {
+	sum := sha256.Sum256([]byte(seed + "/" + clusterName))
+	hash := (float64(binary.BigEndian.Uint64(sum[:8])>>11) + 0.5) / (1 << 53)
+	return -float64(capacity) / math.Log(hash)
+}
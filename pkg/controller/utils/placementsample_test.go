@@ -0,0 +1,233 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	fedv1b1 "sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
+)
+
+func newCapacityCluster(name string, capacity string) *fedv1b1.KubeFedCluster {
+	cluster := &fedv1b1.KubeFedCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{"tier": "tenant"},
+		},
+	}
+	if len(capacity) > 0 {
+		cluster.Annotations = map[string]string{PlacementCapacityAnnotation: capacity}
+	}
+	return cluster
+}
+
+func newSampledResource(t *testing.T, uid string, count int32) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"name":      "tenant-" + uid,
+				"namespace": "ns",
+				"uid":       uid,
+			},
+			"spec": make(map[string]interface{}),
+		},
+	}
+	if err := SetClusterSelector(obj, map[string]string{"tier": "tenant"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := SetPlacementSample(obj, &count); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return obj
+}
+
+func computeSampledPlacement(t *testing.T, resource *unstructured.Unstructured, clusters []*fedv1b1.KubeFedCluster) sets.Set[string] {
+	selectedNames, err := ComputePlacement(resource, clusters, false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return selectedNames
+}
+
+func TestClusterPlacementCapacity(t *testing.T) {
+	testCases := map[string]struct {
+		capacity string
+		expected int64
+	}{
+		"capacity defaults to 1":       {expected: 1},
+		"valid capacity":               {capacity: "8", expected: 8},
+		"invalid capacity is ignored":  {capacity: "large", expected: 1},
+		"zero capacity is ignored":     {capacity: "0", expected: 1},
+		"negative capacity is ignored": {capacity: "-2", expected: 1},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			capacity := ClusterPlacementCapacity(newCapacityCluster("cluster1", tc.capacity))
+			if capacity != tc.expected {
+				t.Fatalf("Expected a capacity of %d, got %d", tc.expected, capacity)
+			}
+		})
+	}
+}
+
+func TestSamplePlacement(t *testing.T) {
+	clusters := []*fedv1b1.KubeFedCluster{
+		newCapacityCluster("cluster1", ""),
+		newCapacityCluster("cluster2", ""),
+		newCapacityCluster("cluster3", ""),
+		newCapacityCluster("cluster4", ""),
+	}
+
+	testCases := map[string]struct {
+		count         int32
+		expectedCount int
+	}{
+		"the sampled number of clusters is selected": {
+			count:         2,
+			expectedCount: 2,
+		},
+		"all clusters are selected if fewer are eligible": {
+			count:         5,
+			expectedCount: 4,
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			resource := newSampledResource(t, "8a3b1c52-0f5e-4b0e-a3d4-0e6c7f4c1d2a", tc.count)
+			selectedNames := computeSampledPlacement(t, resource, clusters)
+			if selectedNames.Len() != tc.expectedCount {
+				t.Fatalf("Expected %d clusters to be selected, got %v", tc.expectedCount, sets.List(selectedNames))
+			}
+
+			// Placement is stable regardless of the order of clusters.
+			reversed := make([]*fedv1b1.KubeFedCluster, 0, len(clusters))
+			for i := len(clusters) - 1; i >= 0; i-- {
+				reversed = append(reversed, clusters[i])
+			}
+			if reselected := computeSampledPlacement(t, resource, reversed); !reselected.Equal(selectedNames) {
+				t.Fatalf("Expected placement %v to be stable, got %v", sets.List(selectedNames), sets.List(reselected))
+			}
+		})
+	}
+}
+
+func TestSamplePlacementIsWeightedByCapacity(t *testing.T) {
+	clusters := []*fedv1b1.KubeFedCluster{
+		newCapacityCluster("small", "1"),
+		newCapacityCluster("medium", "3"),
+		newCapacityCluster("large", "6"),
+	}
+
+	const resources = 3000
+	placements := map[string]int{}
+	for i := 0; i < resources; i++ {
+		resource := newSampledResource(t, fmt.Sprintf("uid-%d", i), 1)
+		for name := range computeSampledPlacement(t, resource, clusters) {
+			placements[name]++
+		}
+	}
+
+	// Each cluster is selected in proportion to its share of the total
+	// capacity of 10, within a tolerance.
+	for _, cluster := range clusters {
+		expected := float64(resources) * float64(ClusterPlacementCapacity(cluster)) / 10
+		if actual := float64(placements[cluster.Name]); actual < expected*0.85 || actual > expected*1.15 {
+			t.Errorf("Expected cluster %q to be selected about %.0f times, got %.0f", cluster.Name, expected, actual)
+		}
+	}
+}
+
+func TestSamplePlacementMinimizesDisruption(t *testing.T) {
+	clusters := []*fedv1b1.KubeFedCluster{
+		newCapacityCluster("cluster1", "2"),
+		newCapacityCluster("cluster2", ""),
+		newCapacityCluster("cluster3", "3"),
+		newCapacityCluster("cluster4", ""),
+	}
+	addedCluster := newCapacityCluster("cluster5", "2")
+
+	for i := 0; i < 500; i++ {
+		resource := newSampledResource(t, fmt.Sprintf("uid-%d", i), 2)
+		placement := computeSampledPlacement(t, resource, clusters)
+
+		// Adding a cluster moves at most one placement, to the new
+		// cluster.
+		withAdded := computeSampledPlacement(t, resource, append(clusters, addedCluster))
+		if removed := placement.Difference(withAdded); removed.Len() > 1 {
+			t.Fatalf("Expected adding a cluster to move at most one placement of %q, got %v -> %v", resource.GetName(), sets.List(placement), sets.List(withAdded))
+		}
+		if added := withAdded.Difference(placement); added.Len() > 0 && !added.Equal(sets.New(addedCluster.Name)) {
+			t.Fatalf("Expected placement of %q to only move to the added cluster, got %v -> %v", resource.GetName(), sets.List(placement), sets.List(withAdded))
+		}
+
+		// Removing a cluster only moves the placement to it.
+		withoutFirst := computeSampledPlacement(t, resource, clusters[1:])
+		if placement.Has(clusters[0].Name) {
+			if retained := placement.Intersection(withoutFirst); retained.Len() != 1 {
+				t.Fatalf("Expected the other placement of %q to be retained, got %v -> %v", resource.GetName(), sets.List(placement), sets.List(withoutFirst))
+			}
+		} else if !withoutFirst.Equal(placement) {
+			t.Fatalf("Expected placement of %q to be unchanged, got %v -> %v", resource.GetName(), sets.List(placement), sets.List(withoutFirst))
+		}
+	}
+}
+
+func TestSamplePlacementOfNamespacedResource(t *testing.T) {
+	clusters := []*fedv1b1.KubeFedCluster{
+		newCapacityCluster("cluster1", ""),
+		newCapacityCluster("cluster2", ""),
+		newCapacityCluster("cluster3", ""),
+	}
+	namespace := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": make(map[string]interface{}),
+		},
+	}
+	if err := SetClusterNames(namespace, []string{"cluster1", "cluster2"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for i := 0; i < 50; i++ {
+		resource := newSampledResource(t, fmt.Sprintf("uid-%d", i), 1)
+		selectedNames, err := ComputeNamespacedPlacement(resource, namespace, clusters, false, false)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		// The sample is taken from the clusters of the namespace.
+		if selectedNames.Len() != 1 || !sets.New("cluster1", "cluster2").IsSuperset(selectedNames) {
+			t.Fatalf("Expected one of the clusters of the namespace to be selected for %q, got %v", resource.GetName(), sets.List(selectedNames))
+		}
+	}
+
+	// Without a sample all clusters of the namespace are selected.
+	resource := newSampledResource(t, "uid", 1)
+	if err := SetPlacementSample(resource, nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	selectedNames, err := ComputeNamespacedPlacement(resource, namespace, clusters, false, false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !selectedNames.Equal(sets.New("cluster1", "cluster2")) {
+		t.Fatalf("Expected all clusters of the namespace to be selected, got %v", sets.List(selectedNames))
+	}
+}
@@ -0,0 +1,55 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// GetPlacementStickiness returns the duration for which a cluster
+// that is no longer selected by the placement of the given federated
+// resource remains placed, or nil if it is removed from placement
+// immediately.
+func GetPlacementStickiness(obj *unstructured.Unstructured) (*time.Duration, error) {
+	seconds, found, err := unstructured.NestedInt64(obj.Object, SpecField, PlacementField, StickinessSecondsField)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to retrieve %s.%s.%s", SpecField, PlacementField, StickinessSecondsField)
+	}
+	if !found {
+		return nil, nil
+	}
+	if seconds < 1 {
+		return nil, errors.Errorf("%s.%s.%s must be at least 1, got %d", SpecField, PlacementField, StickinessSecondsField, seconds)
+	}
+	stickiness := time.Duration(seconds) * time.Second
+	return &stickiness, nil
+}
+
+// SetPlacementStickiness sets the number of seconds for which the
+// placement of the given federated resource retains a cluster that is
+// no longer selected, removing the stickiness if seconds is nil.
+func SetPlacementStickiness(obj *unstructured.Unstructured, seconds *int64) error {
+	if seconds == nil {
+		unstructured.RemoveNestedField(obj.Object, SpecField, PlacementField, StickinessSecondsField)
+		return nil
+	}
+	return unstructured.SetNestedField(obj.Object, *seconds, SpecField, PlacementField, StickinessSecondsField)
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	restclient "k8s.io/client-go/rest"
+)
+
+// DefaultPreflightTimeout bounds the connectivity preflight of a
+// member cluster.
+const DefaultPreflightTimeout = 5 * time.Second
+
+// PreflightCluster verifies that a member cluster can be reached
+// and that the credentials of the given config are accepted by
+// performing a lightweight discovery call. The call is bounded by
+// the given timeout so that an unreachable cluster is reported
+// promptly.
+func PreflightCluster(config *restclient.Config, timeout time.Duration) error {
+	preflightConfig := restclient.CopyConfig(config)
+	preflightConfig.Timeout = timeout
+	client, err := discovery.NewDiscoveryClientForConfig(preflightConfig)
+	if err != nil {
+		return errors.Wrap(err, "Failed to create discovery client")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	// Unlike the version and health endpoints, the core group
+	// discovery endpoint is not served to anonymous users by default
+	// and therefore also validates the credentials.
+	err = client.RESTClient().Get().AbsPath("/api").Do(ctx).Error()
+	if err != nil {
+		return errors.Wrapf(err, "Cluster %q is not reachable", config.Host)
+	}
+	return nil
+}
+
+// TargetTypeServed determines whether a member cluster serves the
+// given API resource at its version by discovering the resources of
+// its group version. A group version that is not served by the cluster
+// is reported as not serving the resource rather than as an error. The
+// discovery is bounded by the given timeout.
+func TargetTypeServed(config *restclient.Config, apiResource metav1.APIResource, timeout time.Duration) (bool, error) {
+	preflightConfig := restclient.CopyConfig(config)
+	preflightConfig.Timeout = timeout
+	client, err := discovery.NewDiscoveryClientForConfig(preflightConfig)
+	if err != nil {
+		return false, errors.Wrap(err, "Failed to create discovery client")
+	}
+
+	groupVersion := schema.GroupVersion{Group: apiResource.Group, Version: apiResource.Version}.String()
+	resourceList, err := client.ServerResourcesForGroupVersion(groupVersion)
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrapf(err, "Failed to discover the resources of %q", groupVersion)
+	}
+	for _, resource := range resourceList.APIResources {
+		if resource.Name == apiResource.Name && resource.Kind == apiResource.Kind {
+			return true, nil
+		}
+	}
+	return false, nil
+}
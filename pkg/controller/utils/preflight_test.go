@@ -0,0 +1,126 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	restclient "k8s.io/client-go/rest"
+)
+
+func TestPreflightCluster(t *testing.T) {
+	testCases := map[string]struct {
+		handler     http.HandlerFunc
+		expectError bool
+	}{
+		"reachable cluster": {
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"kind":"APIVersions","versions":["v1"]}`))
+			},
+		},
+		"rejected credentials": {
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusUnauthorized)
+			},
+			expectError: true,
+		},
+		"unresponsive cluster": {
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				time.Sleep(time.Second)
+			},
+			expectError: true,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(tc.handler)
+			defer server.Close()
+
+			err := PreflightCluster(&restclient.Config{Host: server.URL}, 100*time.Millisecond)
+			if tc.expectError && err == nil {
+				t.Fatalf("Expected an error but got none")
+			}
+			if !tc.expectError && err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestTargetTypeServed(t *testing.T) {
+	deployments := metav1.APIResource{Group: "apps", Version: "v1", Kind: "Deployment", Name: "deployments"}
+	appsV1 := func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/apis/apps/v1" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"kind":"APIResourceList","groupVersion":"apps/v1","resources":[{"name":"deployments","kind":"Deployment","namespaced":true,"verbs":["get"]}]}`))
+	}
+	testCases := map[string]struct {
+		handler     http.HandlerFunc
+		apiResource metav1.APIResource
+		expected    bool
+		expectError bool
+	}{
+		"served type": {
+			handler:     appsV1,
+			apiResource: deployments,
+			expected:    true,
+		},
+		"type missing from a served group version": {
+			handler:     appsV1,
+			apiResource: metav1.APIResource{Group: "apps", Version: "v1", Kind: "ReplicaSet", Name: "replicasets"},
+		},
+		"group version not served": {
+			handler:     appsV1,
+			apiResource: metav1.APIResource{Group: "apps", Version: "v1beta2", Kind: "Deployment", Name: "deployments"},
+		},
+		"rejected credentials": {
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusUnauthorized)
+			},
+			apiResource: deployments,
+			expectError: true,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(tc.handler)
+			defer server.Close()
+
+			served, err := TargetTypeServed(&restclient.Config{Host: server.URL}, tc.apiResource, time.Second)
+			if tc.expectError {
+				if err == nil {
+					t.Fatalf("Expected an error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if served != tc.expected {
+				t.Fatalf("Expected served to be %v, got %v", tc.expected, served)
+			}
+		})
+	}
+}
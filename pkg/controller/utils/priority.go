@@ -0,0 +1,53 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"strconv"
+
+	"github.com/pkg/errors"
+
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ReconcilePriorityAnnotation sets the priority with which the
+	// sync controller reconciles a federated resource. Resources with
+	// a higher priority are reconciled before queued resources with a
+	// lower priority. The value must be an integer.
+	ReconcilePriorityAnnotation = "kubefed.io/reconcile-priority"
+
+	// DefaultReconcilePriority is the priority of resources without
+	// the ReconcilePriorityAnnotation.
+	DefaultReconcilePriority = 0
+)
+
+// GetReconcilePriority returns the reconcile priority of the given
+// object, or the default priority if the object does not have the
+// ReconcilePriorityAnnotation. An error is returned along with the
+// default priority if the annotation is not an integer.
+func GetReconcilePriority(obj runtimeclient.Object) (int, error) {
+	value, ok := obj.GetAnnotations()[ReconcilePriorityAnnotation]
+	if !ok {
+		return DefaultReconcilePriority, nil
+	}
+	priority, err := strconv.ParseInt(value, 10, 32)
+	if err != nil {
+		return DefaultReconcilePriority, errors.Errorf("%s must be an integer, got %q", ReconcilePriorityAnnotation, value)
+	}
+	return int(priority), nil
+}
@@ -0,0 +1,301 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"container/heap"
+	"container/list"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
+)
+
+// unfinishedWorkUpdatePeriod is the interval at which the metrics of
+// the items being processed are updated, as for a workqueue.
+const unfinishedWorkUpdatePeriod = 500 * time.Millisecond
+
+type priorityQueueItem struct {
+	name     QualifiedName
+	priority int
+	// When the item was added to the queue.
+	added time.Time
+	// Order in which the item was added to the queue.
+	seq uint64
+	// Position of the item in the heap.
+	index int
+	// Position of the item in the list of items ordered by age.
+	element *list.Element
+}
+
+type priorityHeap []*priorityQueueItem
+
+// Functions required by container.Heap.
+
+func (h priorityHeap) Len() int { return len(h) }
+func (h priorityHeap) Less(i, j int) bool {
+	if h[i].priority != h[j].priority {
+		return h[i].priority > h[j].priority
+	}
+	return h[i].seq < h[j].seq
+}
+func (h priorityHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *priorityHeap) Push(x interface{}) {
+	item := x.(*priorityQueueItem)
+	item.index = len(*h)
+	*h = append(*h, item)
+}
+
+func (h *priorityHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return item
+}
+
+// PriorityQueue is a work queue of qualified names that hands out the
+// queued item of highest priority first, and items of the same
+// priority in the order they were added. To prevent starvation of
+// items with a low priority, an item that has been queued for longer
+// than the maximum wait is handed out before any other item that has
+// been queued for less time, regardless of priority.
+//
+// As with a workqueue, an item is only queued once, and an item added
+// while it is being processed is requeued once processing is done.
+type PriorityQueue struct {
+	cond *sync.Cond
+
+	maxWait time.Duration
+	now     func() time.Time
+
+	// Items waiting to be processed by priority.
+	heap priorityHeap
+	// Items waiting to be processed by age.
+	age *list.List
+	// Queued items by name.
+	queued map[QualifiedName]*priorityQueueItem
+	// Items being processed.
+	processing map[QualifiedName]bool
+	// Items added while being processed, with their priority.
+	dirty map[QualifiedName]int
+
+	seq          uint64
+	shuttingDown bool
+
+	// Metrics of the queue, or nil if they are not recorded.
+	metrics *priorityQueueMetrics
+	// Closed on shut down to stop updating the metrics.
+	stopCh chan struct{}
+}
+
+// priorityQueueMetrics are the workqueue metrics of a priority queue.
+// They are only accessed with the lock of the queue held.
+type priorityQueueMetrics struct {
+	depth                   workqueue.GaugeMetric
+	adds                    workqueue.CounterMetric
+	latency                 workqueue.HistogramMetric
+	workDuration            workqueue.HistogramMetric
+	unfinishedWorkSeconds   workqueue.SettableGaugeMetric
+	longestRunningProcessor workqueue.SettableGaugeMetric
+
+	// When the items being processed were handed out.
+	processingStartTimes map[QualifiedName]time.Time
+}
+
+// NewPriorityQueue returns a queue that hands out an item queued for
+// longer than maxWait ahead of items of higher priority.
+func NewPriorityQueue(maxWait time.Duration) *PriorityQueue {
+	return &PriorityQueue{
+		cond:       sync.NewCond(&sync.Mutex{}),
+		maxWait:    maxWait,
+		now:        time.Now,
+		age:        list.New(),
+		queued:     make(map[QualifiedName]*priorityQueueItem),
+		processing: make(map[QualifiedName]bool),
+		dirty:      make(map[QualifiedName]int),
+	}
+}
+
+// NewPriorityQueueWithMetrics returns a priority queue whose workqueue
+// metrics are recorded under the given name by the given provider.
+// The metrics of items being processed are updated periodically until
+// the queue is shut down.
+func NewPriorityQueueWithMetrics(name string, maxWait time.Duration, metricsProvider workqueue.MetricsProvider) *PriorityQueue {
+	q := NewPriorityQueue(maxWait)
+	q.metrics = &priorityQueueMetrics{
+		depth:                   metricsProvider.NewDepthMetric(name),
+		adds:                    metricsProvider.NewAddsMetric(name),
+		latency:                 metricsProvider.NewLatencyMetric(name),
+		workDuration:            metricsProvider.NewWorkDurationMetric(name),
+		unfinishedWorkSeconds:   metricsProvider.NewUnfinishedWorkSecondsMetric(name),
+		longestRunningProcessor: metricsProvider.NewLongestRunningProcessorSecondsMetric(name),
+		processingStartTimes:    make(map[QualifiedName]time.Time),
+	}
+	q.stopCh = make(chan struct{})
+	go q.updateUnfinishedWorkLoop()
+	return q
+}
+
+// Add queues the named item with the given priority. If the item is
+// already queued, its priority is raised to the given priority if
+// that is higher.
+func (q *PriorityQueue) Add(name QualifiedName, priority int) {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	if q.shuttingDown {
+		return
+	}
+	if q.processing[name] {
+		if existing, ok := q.dirty[name]; !ok || priority > existing {
+			q.dirty[name] = priority
+		}
+		return
+	}
+	q.add(name, priority)
+}
+
+func (q *PriorityQueue) add(name QualifiedName, priority int) {
+	if item, ok := q.queued[name]; ok {
+		if priority > item.priority {
+			item.priority = priority
+			heap.Fix(&q.heap, item.index)
+		}
+		return
+	}
+	q.seq++
+	item := &priorityQueueItem{
+		name:     name,
+		priority: priority,
+		added:    q.now(),
+		seq:      q.seq,
+	}
+	heap.Push(&q.heap, item)
+	item.element = q.age.PushBack(item)
+	q.queued[name] = item
+	if q.metrics != nil {
+		q.metrics.depth.Inc()
+		q.metrics.adds.Inc()
+	}
+	q.cond.Signal()
+}
+
+// Get blocks until an item can be processed and returns it. The
+// caller must call Done with the item once processing is complete.
+// The second return value is true once the queue has been shut down.
+func (q *PriorityQueue) Get() (QualifiedName, bool) {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	for len(q.heap) == 0 && !q.shuttingDown {
+		q.cond.Wait()
+	}
+	if len(q.heap) == 0 {
+		return QualifiedName{}, true
+	}
+
+	// The oldest item takes precedence over priority once it has
+	// waited for longer than the maximum wait.
+	item := q.age.Front().Value.(*priorityQueueItem)
+	if q.now().Sub(item.added) <= q.maxWait {
+		item = q.heap[0]
+	}
+	heap.Remove(&q.heap, item.index)
+	q.age.Remove(item.element)
+	delete(q.queued, item.name)
+	q.processing[item.name] = true
+	if q.metrics != nil {
+		now := q.now()
+		q.metrics.depth.Dec()
+		q.metrics.latency.Observe(now.Sub(item.added).Seconds())
+		q.metrics.processingStartTimes[item.name] = now
+	}
+	return item.name, false
+}
+
+// Done marks the processing of the named item as complete, requeuing
+// it if it was added while being processed.
+func (q *PriorityQueue) Done(name QualifiedName) {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	delete(q.processing, name)
+	if q.metrics != nil {
+		if start, ok := q.metrics.processingStartTimes[name]; ok {
+			q.metrics.workDuration.Observe(q.now().Sub(start).Seconds())
+			delete(q.metrics.processingStartTimes, name)
+		}
+	}
+	if priority, ok := q.dirty[name]; ok {
+		delete(q.dirty, name)
+		q.add(name, priority)
+	}
+}
+
+// Len returns the number of queued items.
+func (q *PriorityQueue) Len() int {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	return len(q.heap)
+}
+
+// ShutDown causes the queue to ignore added items and Get to return
+// once the queued items have been handed out.
+func (q *PriorityQueue) ShutDown() {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	if q.stopCh != nil && !q.shuttingDown {
+		close(q.stopCh)
+	}
+	q.shuttingDown = true
+	q.cond.Broadcast()
+}
+
+func (q *PriorityQueue) updateUnfinishedWorkLoop() {
+	ticker := time.NewTicker(unfinishedWorkUpdatePeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-q.stopCh:
+			return
+		case <-ticker.C:
+			q.updateUnfinishedWork()
+		}
+	}
+}
+
+// updateUnfinishedWork records the total time for which the items
+// being processed have been processed, and the longest of these times.
+func (q *PriorityQueue) updateUnfinishedWork() {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	now := q.now()
+	var total, longest float64
+	for _, start := range q.metrics.processingStartTimes {
+		processing := now.Sub(start).Seconds()
+		total += processing
+		if processing > longest {
+			longest = processing
+		}
+	}
+	q.metrics.unfinishedWorkSeconds.Set(total)
+	q.metrics.longestRunningProcessor.Set(longest)
+}
@@ -0,0 +1,273 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/util/workqueue"
+)
+
+type queuedItem struct {
+	name     string
+	priority int
+	// How long before the items are retrieved the item is added.
+	age time.Duration
+}
+
+func TestPriorityQueueOrder(t *testing.T) {
+	maxWait := time.Minute
+	testCases := map[string]struct {
+		items         []queuedItem
+		expectedOrder []string
+	}{
+		"items of the same priority in the order they were added": {
+			items: []queuedItem{
+				{name: "a"},
+				{name: "b"},
+				{name: "c"},
+			},
+			expectedOrder: []string{"a", "b", "c"},
+		},
+		"items of higher priority first": {
+			items: []queuedItem{
+				{name: "bulk-1"},
+				{name: "critical", priority: 10},
+				{name: "bulk-2"},
+				{name: "important", priority: 5},
+				{name: "deferred", priority: -1},
+			},
+			expectedOrder: []string{"critical", "important", "bulk-1", "bulk-2", "deferred"},
+		},
+		"items waiting longer than the maximum wait before items of higher priority": {
+			items: []queuedItem{
+				{name: "starved-1", age: 2 * maxWait},
+				{name: "starved-2", age: maxWait + time.Second},
+				{name: "critical", priority: 10},
+				{name: "recent"},
+			},
+			expectedOrder: []string{"starved-1", "starved-2", "critical", "recent"},
+		},
+		"items waiting less than the maximum wait by priority": {
+			items: []queuedItem{
+				{name: "waiting", age: maxWait},
+				{name: "critical", priority: 10},
+			},
+			expectedOrder: []string{"critical", "waiting"},
+		},
+		"duplicate items once with the highest priority": {
+			items: []queuedItem{
+				{name: "a"},
+				{name: "b"},
+				{name: "b", priority: 5},
+				{name: "a", priority: -5},
+			},
+			expectedOrder: []string{"b", "a"},
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			now := time.Now()
+			queue := NewPriorityQueue(maxWait)
+			for _, item := range tc.items {
+				addedAt := now.Add(-item.age)
+				queue.now = func() time.Time { return addedAt }
+				queue.Add(QualifiedName{Namespace: "ns", Name: item.name}, item.priority)
+			}
+			queue.now = func() time.Time { return now }
+
+			var order []string
+			for queue.Len() > 0 {
+				name, quit := queue.Get()
+				require.False(t, quit)
+				order = append(order, name.Name)
+				queue.Done(name)
+			}
+			assert.Equal(t, tc.expectedOrder, order)
+		})
+	}
+}
+
+func TestPriorityQueueDoesNotStarveLowPriorityItems(t *testing.T) {
+	maxWait := time.Minute
+	start := time.Now()
+	now := start
+	queue := NewPriorityQueue(maxWait)
+	queue.now = func() time.Time { return now }
+
+	low := QualifiedName{Namespace: "ns", Name: "low"}
+	high := QualifiedName{Namespace: "ns", Name: "high"}
+	queue.Add(low, DefaultReconcilePriority)
+
+	// A steady stream of high priority items is processed ahead of
+	// the low priority item until it has waited for the maximum wait.
+	for i := 0; ; i++ {
+		require.Less(t, i, 100, "Low priority item was starved")
+		queue.Add(high, 10)
+		name, quit := queue.Get()
+		require.False(t, quit)
+		queue.Done(name)
+		if name == low {
+			assert.Greater(t, now.Sub(start), maxWait)
+			break
+		}
+		assert.Equal(t, high, name)
+		now = now.Add(10 * time.Second)
+	}
+	assert.Equal(t, 1, queue.Len())
+}
+
+func TestPriorityQueueRequeuesItemsAddedWhileProcessing(t *testing.T) {
+	queue := NewPriorityQueue(time.Minute)
+	a := QualifiedName{Namespace: "ns", Name: "a"}
+	b := QualifiedName{Namespace: "ns", Name: "b"}
+
+	queue.Add(a, DefaultReconcilePriority)
+	name, quit := queue.Get()
+	require.False(t, quit)
+	require.Equal(t, a, name)
+
+	// An item being processed is not handed out again until it is done.
+	queue.Add(a, 10)
+	queue.Add(b, DefaultReconcilePriority)
+	assert.Equal(t, 1, queue.Len())
+	name, _ = queue.Get()
+	assert.Equal(t, b, name)
+	queue.Done(b)
+
+	queue.Done(a)
+	assert.Equal(t, 1, queue.Len())
+	name, _ = queue.Get()
+	assert.Equal(t, a, name)
+	queue.Done(a)
+
+	queue.ShutDown()
+	_, quit = queue.Get()
+	assert.True(t, quit)
+}
+
+// recordingMetric records the values of a metric.
+type recordingMetric struct {
+	value        float64
+	observations []float64
+}
+
+func (m *recordingMetric) Inc()                { m.value++ }
+func (m *recordingMetric) Dec()                { m.value-- }
+func (m *recordingMetric) Set(value float64)   { m.value = value }
+func (m *recordingMetric) Observe(obs float64) { m.observations = append(m.observations, obs) }
+
+// recordingMetricsProvider provides recording metrics by metric.
+type recordingMetricsProvider struct {
+	depth, adds, latency, workDuration, unfinished, longestRunning, retries recordingMetric
+}
+
+func (p *recordingMetricsProvider) NewDepthMetric(string) workqueue.GaugeMetric {
+	return &p.depth
+}
+func (p *recordingMetricsProvider) NewAddsMetric(string) workqueue.CounterMetric {
+	return &p.adds
+}
+func (p *recordingMetricsProvider) NewLatencyMetric(string) workqueue.HistogramMetric {
+	return &p.latency
+}
+func (p *recordingMetricsProvider) NewWorkDurationMetric(string) workqueue.HistogramMetric {
+	return &p.workDuration
+}
+func (p *recordingMetricsProvider) NewUnfinishedWorkSecondsMetric(string) workqueue.SettableGaugeMetric {
+	return &p.unfinished
+}
+func (p *recordingMetricsProvider) NewLongestRunningProcessorSecondsMetric(string) workqueue.SettableGaugeMetric {
+	return &p.longestRunning
+}
+func (p *recordingMetricsProvider) NewRetriesMetric(string) workqueue.CounterMetric {
+	return &p.retries
+}
+
+func TestPriorityQueueRecordsMetrics(t *testing.T) {
+	provider := &recordingMetricsProvider{}
+	queue := NewPriorityQueueWithMetrics("test", time.Minute, provider)
+	// The metrics of items being processed are updated explicitly.
+	close(queue.stopCh)
+	now := time.Now()
+	queue.now = func() time.Time { return now }
+
+	a := QualifiedName{Namespace: "ns", Name: "a"}
+	b := QualifiedName{Namespace: "ns", Name: "b"}
+	queue.Add(a, DefaultReconcilePriority)
+	queue.Add(b, 10)
+	// An item that is already queued is not added again.
+	queue.Add(a, 20)
+	assert.Equal(t, 2.0, provider.depth.value)
+	assert.Equal(t, 2.0, provider.adds.value)
+
+	now = now.Add(3 * time.Second)
+	name, _ := queue.Get()
+	assert.Equal(t, a, name)
+	assert.Equal(t, 1.0, provider.depth.value)
+	assert.Equal(t, []float64{3}, provider.latency.observations)
+
+	now = now.Add(2 * time.Second)
+	queue.updateUnfinishedWork()
+	assert.Equal(t, 2.0, provider.unfinished.value)
+	assert.Equal(t, 2.0, provider.longestRunning.value)
+
+	queue.Done(a)
+	assert.Equal(t, []float64{2}, provider.workDuration.observations)
+	queue.updateUnfinishedWork()
+	assert.Equal(t, 0.0, provider.unfinished.value)
+	assert.Equal(t, 0.0, provider.longestRunning.value)
+}
+
+func TestGetReconcilePriority(t *testing.T) {
+	testCases := map[string]struct {
+		annotations      map[string]string
+		expectedPriority int
+		expectedErr      bool
+	}{
+		"default priority without the annotation": {
+			expectedPriority: DefaultReconcilePriority,
+		},
+		"priority from the annotation": {
+			annotations:      map[string]string{ReconcilePriorityAnnotation: "100"},
+			expectedPriority: 100,
+		},
+		"negative priority from the annotation": {
+			annotations:      map[string]string{ReconcilePriorityAnnotation: "-1"},
+			expectedPriority: -1,
+		},
+		"default priority for an invalid annotation": {
+			annotations:      map[string]string{ReconcilePriorityAnnotation: "high"},
+			expectedPriority: DefaultReconcilePriority,
+			expectedErr:      true,
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			obj := &unstructured.Unstructured{}
+			obj.SetAnnotations(tc.annotations)
+			priority, err := GetReconcilePriority(obj)
+			assert.Equal(t, tc.expectedPriority, priority)
+			assert.Equal(t, tc.expectedErr, err != nil, "Unexpected error: %v", err)
+		})
+	}
+}
@@ -67,10 +67,11 @@ func SortClusterVersions(versions []fedv1a1.ClusterObjectVersion) {
 }
 
 // PropagatedVersionStatusEquivalent returns true if both statuses are equal by
-// comparing Template and Override version, and their ClusterVersion slices;
-// false otherwise.
+// comparing Template and Override version, whether they have unversioned
+// overrides, and their ClusterVersion slices; false otherwise.
 func PropagatedVersionStatusEquivalent(pvs1, pvs2 *fedv1a1.PropagatedVersionStatus) bool {
 	return pvs1.TemplateVersion == pvs2.TemplateVersion &&
 		pvs1.OverrideVersion == pvs2.OverrideVersion &&
+		pvs1.UnversionedOverrides == pvs2.UnversionedOverrides &&
 		reflect.DeepEqual(pvs1.ClusterVersions, pvs2.ClusterVersions)
 }
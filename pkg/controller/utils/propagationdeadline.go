@@ -0,0 +1,43 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// GetPropagationDeadline returns the duration within which
+// propagation of the given federated resource must complete, or nil
+// if no deadline is specified.
+func GetPropagationDeadline(obj *unstructured.Unstructured) (*time.Duration, error) {
+	seconds, found, err := unstructured.NestedInt64(obj.Object, SpecField, PropagationDeadlineSecondsField)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to retrieve %s.%s", SpecField, PropagationDeadlineSecondsField)
+	}
+	if !found {
+		return nil, nil
+	}
+	if seconds < 1 {
+		return nil, errors.Errorf("%s.%s must be at least 1, got %d", SpecField, PropagationDeadlineSecondsField, seconds)
+	}
+	deadline := time.Duration(seconds) * time.Second
+	return &deadline, nil
+}
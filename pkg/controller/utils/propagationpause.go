@@ -0,0 +1,58 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"sync/atomic"
+
+	fedv1b1 "sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
+)
+
+const (
+	// PropagationPausedAnnotation If this annotation is present on
+	// the KubeFedConfig of the control plane, the sync controllers of
+	// all federated types will neither create, update nor delete
+	// resources in member clusters until it is removed. The status of
+	// federated resources continues to be collected.
+	PropagationPausedAnnotation = "kubefed.io/propagation-paused"
+	PropagationPausedValue      = "true"
+)
+
+// IsPropagationPaused checks whether propagation has been paused for
+// the control plane configured by the given KubeFedConfig.
+func IsPropagationPaused(config *fedv1b1.KubeFedConfig) bool {
+	return config.GetAnnotations()[PropagationPausedAnnotation] == PropagationPausedValue
+}
+
+// PropagationPause records whether propagation is paused for the
+// control plane. It is shared by the FederatedTypeConfig controller
+// that observes the pause and the sync controllers that honor it.
+type PropagationPause struct {
+	paused atomic.Bool
+}
+
+// Paused returns whether propagation is paused. A nil pause is never
+// paused.
+func (p *PropagationPause) Paused() bool {
+	return p != nil && p.paused.Load()
+}
+
+// Set records whether propagation is paused and returns whether that
+// changed.
+func (p *PropagationPause) Set(paused bool) bool {
+	return p.paused.Swap(paused) != paused
+}
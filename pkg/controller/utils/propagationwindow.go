@@ -0,0 +1,128 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fedv1b1 "sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
+)
+
+const (
+	// PropagateImmediatelyAnnotation marks the changes to a federated
+	// resource as urgent so that they are propagated immediately even
+	// when the propagation window of its type is closed.
+	PropagateImmediatelyAnnotation = "kubefed.io/propagate-immediately"
+
+	maxPropagationWindowDuration = 7 * 24 * time.Hour
+)
+
+var weekdays = map[string]time.Weekday{
+	"Sun": time.Sunday,
+	"Mon": time.Monday,
+	"Tue": time.Tuesday,
+	"Wed": time.Wednesday,
+	"Thu": time.Thursday,
+	"Fri": time.Friday,
+	"Sat": time.Saturday,
+}
+
+// PropagationWindow is the parsed form of a propagation window.
+//
+// Propagation of changes to a type configuring a window is classified
+// by urgency. Creation of resources in member clusters, removal of
+// resources from clusters no longer selected by placement and the
+// handling of deleted federated resources are urgent and always
+// propagated immediately. Updates of existing resources in member
+// clusters (i.e. changes to the template or overrides) are not urgent
+// and are deferred while the window is closed unless the federated
+// resource has the PropagateImmediatelyAnnotation.
+type PropagationWindow struct {
+	days     map[time.Weekday]bool
+	start    time.Duration
+	duration time.Duration
+}
+
+// ParsePropagationWindow returns the parsed form of the given window.
+func ParsePropagationWindow(window *fedv1b1.PropagationWindow) (*PropagationWindow, error) {
+	start, err := time.Parse("15:04", window.Start)
+	if err != nil {
+		return nil, errors.Errorf("start must be a time of day in the format HH:MM, got %q", window.Start)
+	}
+	duration := window.Duration.Duration
+	if duration <= 0 || duration > maxPropagationWindowDuration {
+		return nil, errors.Errorf("duration must be greater than 0 and at most %v, got %v", maxPropagationWindowDuration, duration)
+	}
+	var days map[time.Weekday]bool
+	if len(window.Days) > 0 {
+		days = make(map[time.Weekday]bool, len(window.Days))
+		for _, day := range window.Days {
+			weekday, ok := weekdays[day]
+			if !ok {
+				return nil, errors.Errorf("days must be three-letter abbreviations of days of the week (e.g. Sat), got %q", day)
+			}
+			days[weekday] = true
+		}
+	}
+	return &PropagationWindow{
+		days:     days,
+		start:    time.Duration(start.Hour())*time.Hour + time.Duration(start.Minute())*time.Minute,
+		duration: duration,
+	}, nil
+}
+
+// Next returns whether the window is open at the given time and, if
+// it is not, the time at which it next opens.
+func (w *PropagationWindow) Next(now time.Time) (bool, time.Time) {
+	now = now.UTC()
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	// A window may have opened up to the maximum duration ago, and
+	// the next window opens within a week.
+	for offset := -7; offset <= 7; offset++ {
+		day := midnight.AddDate(0, 0, offset)
+		if w.days != nil && !w.days[day.Weekday()] {
+			continue
+		}
+		opensAt := day.Add(w.start)
+		if !now.Before(opensAt) && now.Before(opensAt.Add(w.duration)) {
+			return true, time.Time{}
+		}
+		if opensAt.After(now) {
+			return false, opensAt
+		}
+	}
+	// Unreachable since at least one day of each week is allowed.
+	return true, time.Time{}
+}
+
+// PropagationDeferredUntil returns the time until which updates of the
+// given federated resource are deferred by the given window, or nil
+// if updates should be propagated immediately.
+func PropagationDeferredUntil(window *PropagationWindow, fedObject metav1.Object, now time.Time) *time.Time {
+	if window == nil || fedObject.GetAnnotations()[PropagateImmediatelyAnnotation] == "true" {
+		return nil
+	}
+	open, opensAt := window.Next(now)
+	if open {
+		return nil
+	}
+	return &opensAt
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fedv1b1 "sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
+)
+
+func TestPropagationWindowNext(t *testing.T) {
+	// 2024-03-01 is a Friday.
+	friday := func(hour, minute int) time.Time {
+		return time.Date(2024, time.March, 1, hour, minute, 0, 0, time.UTC)
+	}
+	testCases := map[string]struct {
+		window          fedv1b1.PropagationWindow
+		now             time.Time
+		expectedOpen    bool
+		expectedOpensAt time.Time
+	}{
+		"open during daily window": {
+			window:       fedv1b1.PropagationWindow{Start: "02:00", Duration: metav1.Duration{Duration: 2 * time.Hour}},
+			now:          friday(3, 0),
+			expectedOpen: true,
+		},
+		"open when window opens": {
+			window:       fedv1b1.PropagationWindow{Start: "02:00", Duration: metav1.Duration{Duration: 2 * time.Hour}},
+			now:          friday(2, 0),
+			expectedOpen: true,
+		},
+		"closed before daily window": {
+			window:          fedv1b1.PropagationWindow{Start: "02:00", Duration: metav1.Duration{Duration: 2 * time.Hour}},
+			now:             friday(1, 30),
+			expectedOpensAt: friday(2, 0),
+		},
+		"closed after daily window": {
+			window:          fedv1b1.PropagationWindow{Start: "02:00", Duration: metav1.Duration{Duration: 2 * time.Hour}},
+			now:             friday(4, 0),
+			expectedOpensAt: friday(2, 0).AddDate(0, 0, 1),
+		},
+		"open during window spanning midnight": {
+			window:       fedv1b1.PropagationWindow{Start: "22:00", Duration: metav1.Duration{Duration: 4 * time.Hour}},
+			now:          friday(1, 0),
+			expectedOpen: true,
+		},
+		"closed until next allowed day": {
+			window:          fedv1b1.PropagationWindow{Days: []string{"Sat", "Sun"}, Start: "02:00", Duration: metav1.Duration{Duration: time.Hour}},
+			now:             friday(2, 30),
+			expectedOpensAt: friday(2, 0).AddDate(0, 0, 1),
+		},
+		"open during multi-day window": {
+			window:       fedv1b1.PropagationWindow{Days: []string{"Wed"}, Start: "00:00", Duration: metav1.Duration{Duration: 3 * 24 * time.Hour}},
+			now:          friday(12, 0),
+			expectedOpen: true,
+		},
+		"time in another zone is converted to UTC": {
+			window:          fedv1b1.PropagationWindow{Start: "02:00", Duration: metav1.Duration{Duration: time.Hour}},
+			now:             friday(1, 0).In(time.FixedZone("UTC+5", 5*60*60)),
+			expectedOpensAt: friday(2, 0),
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			window, err := ParsePropagationWindow(&tc.window)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			open, opensAt := window.Next(tc.now)
+			if open != tc.expectedOpen {
+				t.Fatalf("Expected open to be %v, got %v", tc.expectedOpen, open)
+			}
+			if !opensAt.Equal(tc.expectedOpensAt) {
+				t.Fatalf("Expected the window to next open at %v, got %v", tc.expectedOpensAt, opensAt)
+			}
+		})
+	}
+}
+
+func TestParsePropagationWindowErrors(t *testing.T) {
+	testCases := map[string]fedv1b1.PropagationWindow{
+		"invalid start":      {Start: "2am", Duration: metav1.Duration{Duration: time.Hour}},
+		"invalid day":        {Days: []string{"Friday"}, Start: "02:00", Duration: metav1.Duration{Duration: time.Hour}},
+		"missing duration":   {Start: "02:00"},
+		"excessive duration": {Start: "02:00", Duration: metav1.Duration{Duration: 8 * 24 * time.Hour}},
+	}
+	for testName, window := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			if _, err := ParsePropagationWindow(&window); err == nil {
+				t.Fatalf("Expected an error")
+			}
+		})
+	}
+}
+
+func TestPropagationDeferredUntil(t *testing.T) {
+	window, err := ParsePropagationWindow(&fedv1b1.PropagationWindow{Start: "02:00", Duration: metav1.Duration{Duration: time.Hour}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	fedObject := &metav1.ObjectMeta{}
+	outsideWindow := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	if deferredUntil := PropagationDeferredUntil(nil, fedObject, outsideWindow); deferredUntil != nil {
+		t.Fatalf("Expected no deferral without a window, got %v", *deferredUntil)
+	}
+
+	// A change made outside the window is deferred until the window
+	// opens and is then propagated.
+	deferredUntil := PropagationDeferredUntil(window, fedObject, outsideWindow)
+	expected := time.Date(2024, time.March, 2, 2, 0, 0, 0, time.UTC)
+	if deferredUntil == nil || !deferredUntil.Equal(expected) {
+		t.Fatalf("Expected deferral until %v, got %v", expected, deferredUntil)
+	}
+	if deferredUntil := PropagationDeferredUntil(window, fedObject, *deferredUntil); deferredUntil != nil {
+		t.Fatalf("Expected no deferral once the window opens, got %v", *deferredUntil)
+	}
+
+	fedObject.SetAnnotations(map[string]string{PropagateImmediatelyAnnotation: "true"})
+	if deferredUntil := PropagationDeferredUntil(window, fedObject, outsideWindow); deferredUntil != nil {
+		t.Fatalf("Expected no deferral of urgent changes, got %v", *deferredUntil)
+	}
+}
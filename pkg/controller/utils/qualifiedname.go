@@ -21,6 +21,8 @@ import (
 
 	"k8s.io/apimachinery/pkg/api/meta"
 	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/kubefed/pkg/apis/core/typeconfig"
 )
 
 // QualifiedName comprises a resource name with an optional namespace.
@@ -55,3 +57,15 @@ func (n QualifiedName) String() string {
 	}
 	return fmt.Sprintf("%s/%s", n.Namespace, n.Name)
 }
+
+// QualifiedNameForTarget returns the qualified name of the target
+// resource managed by the given federated resource. A federated
+// namespace is namespaced (e.g. "foo/foo") while its target namespace
+// is not (e.g. "foo").
+func QualifiedNameForTarget(typeConfig typeconfig.Interface, fedObject runtimeclient.Object) QualifiedName {
+	qualifiedName := NewQualifiedName(fedObject)
+	if typeConfig.GetTargetType().Kind == NamespaceKind {
+		qualifiedName.Namespace = ""
+	}
+	return qualifiedName
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"testing"
+
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	fedv1b1 "sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
+)
+
+func TestQualifiedNameForTarget(t *testing.T) {
+	testCases := map[string]struct {
+		typeConfig   *fedv1b1.FederatedTypeConfig
+		namespace    string
+		name         string
+		expectedName QualifiedName
+	}{
+		"namespaced target": {
+			typeConfig: &fedv1b1.FederatedTypeConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: "configmaps"},
+				Spec: fedv1b1.FederatedTypeConfigSpec{
+					TargetType: fedv1b1.APIResource{Kind: "ConfigMap", Scope: apiextv1.NamespaceScoped},
+				},
+			},
+			namespace:    "foo",
+			name:         "bar",
+			expectedName: QualifiedName{Namespace: "foo", Name: "bar"},
+		},
+		"cluster-scoped target": {
+			typeConfig: &fedv1b1.FederatedTypeConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: "clusterroles.rbac.authorization.k8s.io"},
+				Spec: fedv1b1.FederatedTypeConfigSpec{
+					TargetType: fedv1b1.APIResource{Kind: "ClusterRole", Scope: apiextv1.ClusterScoped},
+				},
+			},
+			name:         "bar",
+			expectedName: QualifiedName{Name: "bar"},
+		},
+		"namespace target": {
+			typeConfig: &fedv1b1.FederatedTypeConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: "namespaces"},
+				Spec: fedv1b1.FederatedTypeConfigSpec{
+					TargetType: fedv1b1.APIResource{Kind: NamespaceKind, Scope: apiextv1.ClusterScoped},
+				},
+			},
+			namespace:    "foo",
+			name:         "foo",
+			expectedName: QualifiedName{Name: "foo"},
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			fedObject := &unstructured.Unstructured{}
+			fedObject.SetNamespace(tc.namespace)
+			fedObject.SetName(tc.name)
+			if name := QualifiedNameForTarget(tc.typeConfig, fedObject); name != tc.expectedName {
+				t.Fatalf("Expected %q, got %q", tc.expectedName, name)
+			}
+		})
+	}
+}
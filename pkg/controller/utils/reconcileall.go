@@ -0,0 +1,36 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ReconcileAllAnnotation If the value of this annotation on a
+	// FederatedTypeConfig changes while its sync controller is
+	// running, all federated resources of the type are reconciled.
+	// Any value that differs from the previous one (e.g. a
+	// timestamp) triggers a new round of reconciliation.
+	ReconcileAllAnnotation = "kubefed.io/reconcile-all"
+)
+
+// GetReconcileAllRequest returns the value of the annotation
+// requesting reconciliation of all federated resources of a type.
+func GetReconcileAllRequest(obj metav1.Object) string {
+	return obj.GetAnnotations()[ReconcileAllAnnotation]
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"sync"
+)
+
+// ReconcileLimiter bounds the number of reconciles in flight across
+// all the workers that share it. When the limit is reached, a
+// reconcile waits for a slot to be released. A released slot is
+// granted to the waiting worker with the fewest reconciles in flight,
+// and among those to the one that has waited longest, so that a worker
+// with many resources to reconcile cannot starve the other workers.
+type ReconcileLimiter struct {
+	sync.Mutex
+
+	limit int
+
+	// The number of reconciles in flight, in total and by worker.
+	inFlight       int
+	inFlightByName map[string]int
+
+	// The reconciles waiting for a slot, in order of arrival.
+	waiters []*limiterWaiter
+}
+
+type limiterWaiter struct {
+	name    string
+	granted chan struct{}
+}
+
+// NewReconcileLimiter returns a limiter allowing the given number of
+// reconciles to be in flight at once.
+func NewReconcileLimiter(limit int) *ReconcileLimiter {
+	if limit < 1 {
+		limit = 1
+	}
+	return &ReconcileLimiter{
+		limit:          limit,
+		inFlightByName: make(map[string]int),
+	}
+}
+
+// Acquire waits for a slot for a reconcile of the named worker. It
+// returns false without a slot if the stop channel is closed first.
+// A slot that was acquired must be released with Release.
+func (l *ReconcileLimiter) Acquire(name string, stopChan <-chan struct{}) bool {
+	l.Lock()
+	if l.inFlight < l.limit && len(l.waiters) == 0 {
+		l.grant(name)
+		l.Unlock()
+		return true
+	}
+	waiter := &limiterWaiter{name: name, granted: make(chan struct{})}
+	l.waiters = append(l.waiters, waiter)
+	l.Unlock()
+
+	select {
+	case <-waiter.granted:
+		return true
+	case <-stopChan:
+	}
+
+	l.Lock()
+	defer l.Unlock()
+	for i, w := range l.waiters {
+		if w == waiter {
+			l.waiters = append(l.waiters[:i], l.waiters[i+1:]...)
+			return false
+		}
+	}
+	// The slot was granted concurrently with the stop and is passed
+	// on to another waiter.
+	l.release(name)
+	return false
+}
+
+// Release releases a slot acquired for a reconcile of the named
+// worker.
+func (l *ReconcileLimiter) Release(name string) {
+	l.Lock()
+	defer l.Unlock()
+	l.release(name)
+}
+
+// InFlight returns the number of reconciles in flight.
+func (l *ReconcileLimiter) InFlight() int {
+	l.Lock()
+	defer l.Unlock()
+	return l.inFlight
+}
+
+func (l *ReconcileLimiter) grant(name string) {
+	l.inFlight++
+	l.inFlightByName[name]++
+}
+
+func (l *ReconcileLimiter) release(name string) {
+	l.inFlight--
+	if l.inFlightByName[name]--; l.inFlightByName[name] <= 0 {
+		delete(l.inFlightByName, name)
+	}
+	for l.inFlight < l.limit && len(l.waiters) > 0 {
+		next := 0
+		for i, waiter := range l.waiters {
+			if l.inFlightByName[waiter.name] < l.inFlightByName[l.waiters[next].name] {
+				next = i
+			}
+		}
+		waiter := l.waiters[next]
+		l.waiters = append(l.waiters[:next], l.waiters[next+1:]...)
+		l.grant(waiter.name)
+		close(waiter.granted)
+	}
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+func TestReconcileLimiterAcrossWorkers(t *testing.T) {
+	const limit = 3
+	limiter := NewReconcileLimiter(limit)
+
+	var inFlight, maxInFlight int32
+	var reconciled sync.WaitGroup
+	reconcile := func(qualifiedName QualifiedName) ReconciliationStatus {
+		current := atomic.AddInt32(&inFlight, 1)
+		for {
+			observed := atomic.LoadInt32(&maxInFlight)
+			if current <= observed || atomic.CompareAndSwapInt32(&maxInFlight, observed, current) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+		reconciled.Done()
+		return StatusAllOK
+	}
+
+	// Like the sync controllers of two types, each worker would run
+	// more reconciles at once than the shared limit allows.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var workers []ReconcileWorker
+	for _, name := range []string{"configmap", "secret"} {
+		worker := NewReconcileWorker(name, reconcile, WorkerOptions{
+			MaxConcurrentReconciles: 5,
+			Limiter:                 limiter,
+		})
+		worker.Run(ctx.Done())
+		workers = append(workers, worker)
+	}
+
+	for i := 0; i < 20; i++ {
+		for _, worker := range workers {
+			reconciled.Add(1)
+			worker.Enqueue(QualifiedName{Namespace: "ns", Name: fmt.Sprintf("name-%d", i)})
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		reconciled.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(wait.ForeverTestTimeout):
+		t.Fatal("timed out waiting for the resources to be reconciled")
+	}
+
+	if observed := atomic.LoadInt32(&maxInFlight); observed > limit {
+		t.Fatalf("Expected at most %d reconciles in flight, observed %d", limit, observed)
+	}
+	if observed := limiter.InFlight(); observed != 0 {
+		t.Fatalf("Expected all slots to be released, %d are held", observed)
+	}
+}
+
+func TestReconcileLimiterFairness(t *testing.T) {
+	limiter := NewReconcileLimiter(2)
+	stopChan := make(chan struct{})
+	defer close(stopChan)
+
+	for i := 0; i < 2; i++ {
+		if !limiter.Acquire("busy", stopChan) {
+			t.Fatalf("Expected a slot to be acquired")
+		}
+	}
+
+	// The busy worker queues up several reconciles before another
+	// worker asks for a slot.
+	granted := make(chan string, 10)
+	acquire := func(name string) {
+		if limiter.Acquire(name, stopChan) {
+			granted <- name
+		}
+	}
+	for i := 0; i < 3; i++ {
+		go acquire("busy")
+	}
+	waitForWaiters(t, limiter, 3)
+	go acquire("quiet")
+	waitForWaiters(t, limiter, 4)
+
+	limiter.Release("busy")
+	if name := <-granted; name != "quiet" {
+		t.Fatalf("Expected the released slot to be granted to the worker with fewer reconciles in flight, got %q", name)
+	}
+}
+
+func TestReconcileLimiterStop(t *testing.T) {
+	limiter := NewReconcileLimiter(1)
+	if !limiter.Acquire("name", nil) {
+		t.Fatalf("Expected a slot to be acquired")
+	}
+
+	stopChan := make(chan struct{})
+	result := make(chan bool)
+	go func() {
+		result <- limiter.Acquire("name", stopChan)
+	}()
+	waitForWaiters(t, limiter, 1)
+	close(stopChan)
+	if <-result {
+		t.Fatalf("Expected no slot to be acquired once stopped")
+	}
+
+	limiter.Release("name")
+	if observed := limiter.InFlight(); observed != 0 {
+		t.Fatalf("Expected all slots to be released, %d are held", observed)
+	}
+}
+
+func waitForWaiters(t *testing.T, limiter *ReconcileLimiter, count int) {
+	t.Helper()
+	deadline := time.Now().Add(wait.ForeverTestTimeout)
+	for time.Now().Before(deadline) {
+		limiter.Lock()
+		waiting := len(limiter.waiters)
+		limiter.Unlock()
+		if waiting == count {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("Timed out waiting for %d reconciles to wait for a slot", count)
+}
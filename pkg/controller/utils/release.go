@@ -0,0 +1,85 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+const (
+	// ReleaseAnnotation on a federated resource requests that the
+	// sync controller hand the resources it manages in member clusters
+	// back to the controllers of those clusters. The markers of
+	// management by KubeFed are removed from the resources, which are
+	// otherwise left untouched, and the federated resource is no
+	// longer propagated. Unlike orphaning, release does not require
+	// the federated resource to be deleted.
+	ReleaseAnnotation = "kubefed.io/release"
+	ReleasedValue     = "true"
+)
+
+// IsReleaseRequested returns whether the given federated resource
+// requests the release of its managed resources.
+func IsReleaseRequested(obj *unstructured.Unstructured) bool {
+	return obj.GetAnnotations()[ReleaseAnnotation] == ReleasedValue
+}
+
+// bookkeepingAnnotations are the annotations that the sync controller
+// records on managed resources to track their propagation. They are
+// meaningless once a resource is no longer managed, and would be acted
+// upon if the resource became managed again.
+var bookkeepingAnnotations = []string{
+	FederatedNameAnnotation,
+	AppliedOverridePathsAnnotation,
+	DeclaredMetadataAnnotation,
+	DrainStartedAnnotation,
+	DeletionPendingAnnotation,
+	DeletionPendingOptionsAnnotation,
+}
+
+// RemoveManagedMetadata ensures that the given object does not have
+// the markers of management by KubeFed: the managed label, the
+// annotations recorded by the sync controller to track propagation,
+// the placement annotation with the given key, and the given labels
+// and annotations configured for managed resources. Configured labels
+// and annotations are only removed if they have the configured value,
+// so that values set by other controllers are retained.
+func RemoveManagedMetadata(obj *unstructured.Unstructured, managedLabels, managedAnnotations map[string]string, placementAnnotation string) {
+	RemoveManagedLabel(obj)
+
+	if labels := obj.GetLabels(); len(labels) > 0 {
+		for key, value := range managedLabels {
+			if key != ManagedByKubeFedLabelKey && labels[key] == value {
+				delete(labels, key)
+			}
+		}
+		obj.SetLabels(labels)
+	}
+
+	if annotations := obj.GetAnnotations(); len(annotations) > 0 {
+		for key, value := range managedAnnotations {
+			if annotations[key] == value {
+				delete(annotations, key)
+			}
+		}
+		for _, key := range bookkeepingAnnotations {
+			delete(annotations, key)
+		}
+		if len(placementAnnotation) > 0 {
+			delete(annotations, placementAnnotation)
+		}
+		obj.SetAnnotations(annotations)
+	}
+}
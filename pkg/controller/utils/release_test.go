@@ -0,0 +1,93 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+func TestRemoveManagedMetadata(t *testing.T) {
+	managedLabels := map[string]string{"team": "platform"}
+	managedAnnotations := map[string]string{"owner": "kubefed"}
+	const placementAnnotation = "kubefed.io/placement"
+
+	testCases := map[string]struct {
+		labels              map[string]string
+		annotations         map[string]string
+		expectedLabels      map[string]string
+		expectedAnnotations map[string]string
+	}{
+		"markers are removed": {
+			labels: map[string]string{
+				ManagedByKubeFedLabelKey: ManagedByKubeFedLabelValue,
+				"team":                   "platform",
+				"app":                    "foo",
+			},
+			annotations: map[string]string{
+				"owner":                          "kubefed",
+				FederatedNameAnnotation:          "foo",
+				AppliedOverridePathsAnnotation:   "/data/key",
+				DeclaredMetadataAnnotation:       `{"labels":["app"]}`,
+				DrainStartedAnnotation:           "2024-01-01T00:00:00Z",
+				DeletionPendingAnnotation:        "2024-01-01T00:00:00Z",
+				DeletionPendingOptionsAnnotation: `{"propagationPolicy":"Orphan"}`,
+				placementAnnotation:              "cluster1,cluster2",
+				"note":                           "kept",
+			},
+			expectedLabels:      map[string]string{"app": "foo"},
+			expectedAnnotations: map[string]string{"note": "kept"},
+		},
+		"configured metadata with other values is retained": {
+			labels: map[string]string{
+				ManagedByKubeFedLabelKey: ManagedByKubeFedLabelValue,
+				"team":                   "storage",
+			},
+			annotations:         map[string]string{"owner": "storage"},
+			expectedLabels:      map[string]string{"team": "storage"},
+			expectedAnnotations: map[string]string{"owner": "storage"},
+		},
+		"explicitly unmanaged label is retained": {
+			labels:         map[string]string{ManagedByKubeFedLabelKey: UnmanagedByKubeFedLabelValue},
+			expectedLabels: map[string]string{ManagedByKubeFedLabelKey: UnmanagedByKubeFedLabelValue},
+		},
+		"object without metadata": {},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			obj := &unstructured.Unstructured{Object: map[string]interface{}{}}
+			obj.SetLabels(tc.labels)
+			obj.SetAnnotations(tc.annotations)
+
+			RemoveManagedMetadata(obj, managedLabels, managedAnnotations, placementAnnotation)
+
+			if labels := obj.GetLabels(); len(labels) > 0 || len(tc.expectedLabels) > 0 {
+				if !reflect.DeepEqual(tc.expectedLabels, labels) {
+					t.Fatalf("Expected labels %v, got %v", tc.expectedLabels, labels)
+				}
+			}
+			if annotations := obj.GetAnnotations(); len(annotations) > 0 || len(tc.expectedAnnotations) > 0 {
+				if !reflect.DeepEqual(tc.expectedAnnotations, annotations) {
+					t.Fatalf("Expected annotations %v, got %v", sets.List(sets.KeySet(tc.expectedAnnotations)), sets.List(sets.KeySet(annotations)))
+				}
+			}
+		})
+	}
+}
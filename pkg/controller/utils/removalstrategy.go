@@ -0,0 +1,150 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	fedv1b1 "sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
+)
+
+// DrainStartedAnnotation on a resource in a member cluster gives the
+// RFC 3339 time at which the pre-delete patch of its removal strategy
+// was applied, from which its drain duration elapses.
+const DrainStartedAnnotation = "kubefed.io/drain-started"
+
+// GetRemovalStrategy returns the removal strategy of the given
+// federated resource, or the given strategy of its type if the
+// resource does not specify one. Returns nil if resources should be
+// deleted as soon as their cluster is no longer selected.
+func GetRemovalStrategy(fedObject *unstructured.Unstructured, typeStrategy *fedv1b1.RemovalStrategy) (*fedv1b1.RemovalStrategy, error) {
+	strategy := typeStrategy
+	value, found, err := unstructured.NestedMap(fedObject.Object, SpecField, RemovalStrategyField)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to retrieve %s.%s", SpecField, RemovalStrategyField)
+	}
+	if found {
+		strategy = &fedv1b1.RemovalStrategy{}
+		err = runtime.DefaultUnstructuredConverter.FromUnstructured(value, strategy)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed to parse %s.%s", SpecField, RemovalStrategyField)
+		}
+		if err := ValidateRemovalStrategy(strategy); err != nil {
+			return nil, errors.Wrapf(err, "Invalid %s.%s", SpecField, RemovalStrategyField)
+		}
+	}
+	// A strategy that neither patches nor waits is equivalent to
+	// immediate deletion, which allows a federated resource to opt
+	// out of the strategy of its type.
+	if strategy == nil || (strategy.PreDeletePatch == nil && strategy.DrainDuration.Duration == 0) {
+		return nil, nil
+	}
+	return strategy, nil
+}
+
+// ValidateRemovalStrategy checks that the pre-delete patch of the
+// given strategy is a JSON object that can record the start of
+// draining and that its drain duration is not negative.
+func ValidateRemovalStrategy(strategy *fedv1b1.RemovalStrategy) error {
+	if strategy.DrainDuration.Duration < 0 {
+		return errors.Errorf("drainDuration must not be negative, got %v", strategy.DrainDuration.Duration)
+	}
+	_, err := DrainPatch(strategy, time.Time{}, "")
+	return err
+}
+
+// DrainPatch returns the JSON merge patch that applies the pre-delete
+// patch of the given strategy and records that draining started at
+// the given time. A non-empty resource version is included as a
+// precondition so that a patch computed from a stale copy of the
+// resource, which does not show that draining already started, fails
+// with a conflict rather than restarting the drain duration.
+func DrainPatch(strategy *fedv1b1.RemovalStrategy, startedAt time.Time, resourceVersion string) ([]byte, error) {
+	patch := make(map[string]interface{})
+	if strategy.PreDeletePatch != nil {
+		var err error
+		patch, err = preDeletePatch(strategy)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err := unstructured.SetNestedField(patch, startedAt.UTC().Format(time.RFC3339Nano), "metadata", "annotations", DrainStartedAnnotation)
+	if err != nil {
+		return nil, errors.Wrap(err, "preDeletePatch must not replace the annotations of the resource")
+	}
+	if len(resourceVersion) > 0 {
+		err = unstructured.SetNestedField(patch, resourceVersion, "metadata", "resourceVersion")
+		if err != nil {
+			return nil, errors.Wrap(err, "preDeletePatch must not replace the metadata of the resource")
+		}
+	}
+	return json.Marshal(patch)
+}
+
+func preDeletePatch(strategy *fedv1b1.RemovalStrategy) (map[string]interface{}, error) {
+	patch := make(map[string]interface{})
+	if err := json.Unmarshal(strategy.PreDeletePatch.Raw, &patch); err != nil || patch == nil {
+		return nil, errors.New("preDeletePatch must be a JSON object")
+	}
+	return patch, nil
+}
+
+// DrainRemaining returns whether draining of the given resource in a
+// member cluster has started and, if so, how long remains until the
+// drain duration of the given strategy elapses. Draining is
+// considered not to have started if the recorded start time cannot be
+// parsed, so that the pre-delete patch is applied again.
+func DrainRemaining(strategy *fedv1b1.RemovalStrategy, clusterObj *unstructured.Unstructured, now time.Time) (bool, time.Duration) {
+	value, ok := clusterObj.GetAnnotations()[DrainStartedAnnotation]
+	if !ok {
+		return false, 0
+	}
+	startedAt, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return false, 0
+	}
+	remaining := startedAt.Add(strategy.DrainDuration.Duration).Sub(now)
+	if remaining < 0 {
+		remaining = 0
+	}
+	return true, remaining
+}
+
+// IsDraining checks whether the given resource in a member cluster is
+// being drained before its removal.
+func IsDraining(clusterObj *unstructured.Unstructured) bool {
+	_, ok := clusterObj.GetAnnotations()[DrainStartedAnnotation]
+	return ok
+}
+
+// RemoveDrainStarted removes the record of draining from the given
+// object so that a resource whose cluster is selected again is
+// drained anew should it later be removed.
+func RemoveDrainStarted(obj *unstructured.Unstructured) {
+	annotations := obj.GetAnnotations()
+	if _, ok := annotations[DrainStartedAnnotation]; !ok {
+		return
+	}
+	delete(annotations, DrainStartedAnnotation)
+	obj.SetAnnotations(annotations)
+}
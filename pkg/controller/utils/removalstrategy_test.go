@@ -0,0 +1,203 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"testing"
+	"time"
+
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	fedv1b1 "sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
+)
+
+func TestGetRemovalStrategy(t *testing.T) {
+	typeStrategy := &fedv1b1.RemovalStrategy{DrainDuration: metav1.Duration{Duration: time.Minute}}
+
+	testCases := map[string]struct {
+		typeStrategy    *fedv1b1.RemovalStrategy
+		removalStrategy map[string]interface{}
+		expectedPatch   string
+		expectedDrain   time.Duration
+		expectedNil     bool
+		expectedErr     bool
+	}{
+		"no strategy": {
+			expectedNil: true,
+		},
+		"strategy of the type": {
+			typeStrategy:  typeStrategy,
+			expectedDrain: time.Minute,
+		},
+		"strategy of the resource replaces that of the type": {
+			typeStrategy: typeStrategy,
+			removalStrategy: map[string]interface{}{
+				"preDeletePatch": map[string]interface{}{
+					"spec": map[string]interface{}{"replicas": int64(0)},
+				},
+				"drainDuration": "30s",
+			},
+			expectedPatch: `{"spec":{"replicas":0}}`,
+			expectedDrain: 30 * time.Second,
+		},
+		"empty strategy of the resource opts out of that of the type": {
+			typeStrategy:    typeStrategy,
+			removalStrategy: map[string]interface{}{},
+			expectedNil:     true,
+		},
+		"invalid drain duration": {
+			removalStrategy: map[string]interface{}{"drainDuration": "a while"},
+			expectedErr:     true,
+		},
+		"negative drain duration": {
+			removalStrategy: map[string]interface{}{"drainDuration": "-1m"},
+			expectedErr:     true,
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			fedObject := &unstructured.Unstructured{Object: map[string]interface{}{}}
+			if tc.removalStrategy != nil {
+				if err := unstructured.SetNestedMap(fedObject.Object, tc.removalStrategy, SpecField, RemovalStrategyField); err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+			}
+
+			strategy, err := GetRemovalStrategy(fedObject, tc.typeStrategy)
+			if tc.expectedErr {
+				if err == nil {
+					t.Fatalf("Expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if tc.expectedNil {
+				if strategy != nil {
+					t.Fatalf("Expected no strategy, got %v", strategy)
+				}
+				return
+			}
+			if strategy == nil {
+				t.Fatalf("Expected a strategy")
+			}
+			var patch string
+			if strategy.PreDeletePatch != nil {
+				patch = string(strategy.PreDeletePatch.Raw)
+			}
+			if patch != tc.expectedPatch {
+				t.Fatalf("Expected patch %q, got %q", tc.expectedPatch, patch)
+			}
+			if strategy.DrainDuration.Duration != tc.expectedDrain {
+				t.Fatalf("Expected drain duration %v, got %v", tc.expectedDrain, strategy.DrainDuration.Duration)
+			}
+		})
+	}
+}
+
+func TestDrainPatch(t *testing.T) {
+	startedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := map[string]struct {
+		preDeletePatch  string
+		resourceVersion string
+		expected        string
+		expectedErr     bool
+	}{
+		"start of draining is recorded without a patch": {
+			expected: `{"metadata":{"annotations":{"kubefed.io/drain-started":"2024-05-01T12:00:00Z"}}}`,
+		},
+		"start of draining is recorded with the patch": {
+			preDeletePatch: `{"metadata":{"labels":{"serving":"false"}},"spec":{"replicas":0}}`,
+			expected:       `{"metadata":{"annotations":{"kubefed.io/drain-started":"2024-05-01T12:00:00Z"},"labels":{"serving":"false"}},"spec":{"replicas":0}}`,
+		},
+		"resource version is a precondition of the patch": {
+			preDeletePatch:  `{"spec":{"replicas":0}}`,
+			resourceVersion: "42",
+			expected:        `{"metadata":{"annotations":{"kubefed.io/drain-started":"2024-05-01T12:00:00Z"},"resourceVersion":"42"},"spec":{"replicas":0}}`,
+		},
+		"patch removing all annotations": {
+			preDeletePatch: `{"metadata":{"annotations":null}}`,
+			expectedErr:    true,
+		},
+		"patch that is not an object": {
+			preDeletePatch: `[]`,
+			expectedErr:    true,
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			strategy := &fedv1b1.RemovalStrategy{}
+			if len(tc.preDeletePatch) > 0 {
+				strategy.PreDeletePatch = &apiextv1.JSON{Raw: []byte(tc.preDeletePatch)}
+			}
+
+			patch, err := DrainPatch(strategy, startedAt, tc.resourceVersion)
+			if tc.expectedErr {
+				if err == nil {
+					t.Fatalf("Expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if string(patch) != tc.expected {
+				t.Fatalf("Expected patch %s, got %s", tc.expected, patch)
+			}
+		})
+	}
+}
+
+func TestDrainRemaining(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	strategy := &fedv1b1.RemovalStrategy{DrainDuration: metav1.Duration{Duration: time.Minute}}
+
+	testCases := map[string]struct {
+		annotations       map[string]string
+		expectedStarted   bool
+		expectedRemaining time.Duration
+	}{
+		"draining not started": {},
+		"draining in progress": {
+			annotations:       map[string]string{DrainStartedAnnotation: "2024-05-01T11:59:30Z"},
+			expectedStarted:   true,
+			expectedRemaining: 30 * time.Second,
+		},
+		"drain duration elapsed": {
+			annotations:     map[string]string{DrainStartedAnnotation: "2024-05-01T11:00:00Z"},
+			expectedStarted: true,
+		},
+		"invalid start restarts draining": {
+			annotations: map[string]string{DrainStartedAnnotation: "yesterday"},
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			clusterObj := &unstructured.Unstructured{}
+			clusterObj.SetAnnotations(tc.annotations)
+
+			started, remaining := DrainRemaining(strategy, clusterObj, now)
+			if started != tc.expectedStarted || remaining != tc.expectedRemaining {
+				t.Fatalf("Expected started %v with %v remaining, got started %v with %v remaining", tc.expectedStarted, tc.expectedRemaining, started, remaining)
+			}
+		})
+	}
+}
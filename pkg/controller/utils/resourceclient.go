@@ -37,8 +37,28 @@ type resourceClient struct {
 	kind        string
 }
 
+// ResourceClientOption configures a client created by NewResourceClient.
+type ResourceClientOption func(*resourceClientOptions)
+
+type resourceClientOptions struct {
+	requestMetrics bool
+}
+
+// WithRequestMetrics opts a client into recording metrics for the
+// requests made with it.
+func WithRequestMetrics() ResourceClientOption {
+	return func(o *resourceClientOptions) {
+		o.requestMetrics = true
+	}
+}
+
 // NewResourceClient The dynamic client.
-func NewResourceClient(config *rest.Config, apiResource *metav1.APIResource) (ResourceClient, error) {
+func NewResourceClient(config *rest.Config, apiResource *metav1.APIResource, opts ...ResourceClientOption) (ResourceClient, error) {
+	options := &resourceClientOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
 	resource := schema.GroupVersionResource{
 		Group:    apiResource.Group,
 		Version:  apiResource.Version,
@@ -49,12 +69,16 @@ func NewResourceClient(config *rest.Config, apiResource *metav1.APIResource) (Re
 		return nil, err
 	}
 
-	return &resourceClient{
+	var rc ResourceClient = &resourceClient{
 		client:      client,
 		apiResource: resource,
 		namespaced:  apiResource.Namespaced,
 		kind:        apiResource.Kind,
-	}, nil
+	}
+	if options.requestMetrics {
+		rc = NewInstrumentedResourceClient(rc, resource.GroupResource().String())
+	}
+	return rc, nil
 }
 
 func (c *resourceClient) Resources(namespace string) dynamic.ResourceInterface {
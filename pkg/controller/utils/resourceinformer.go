@@ -22,8 +22,11 @@ import (
 	"github.com/pkg/errors"
 	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 
+	discoveryv1 "k8s.io/api/discovery/v1"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
 	pkgruntime "k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -34,17 +37,57 @@ import (
 
 // NewResourceInformer returns an unfiltered informer.
 func NewResourceInformer(client ResourceClient, namespace string, apiResource *metav1.APIResource, triggerFunc func(runtimeclient.Object)) (cache.Store, cache.Controller) {
-	return newResourceInformer(client, namespace, apiResource, triggerFunc, "")
+	return newResourceInformer(client, namespace, apiResource, triggerFunc, "", "")
 }
 
 // NewManagedResourceInformer returns an informer limited to resources
 // managed by KubeFed as indicated by labeling.
 func NewManagedResourceInformer(client ResourceClient, namespace string, apiResource *metav1.APIResource, triggerFunc func(runtimeclient.Object)) (cache.Store, cache.Controller) {
 	labelSelector := labels.Set(map[string]string{ManagedByKubeFedLabelKey: ManagedByKubeFedLabelValue}).AsSelector().String()
-	return newResourceInformer(client, namespace, apiResource, triggerFunc, labelSelector)
+	return newResourceInformer(client, namespace, apiResource, triggerFunc, labelSelector, "")
 }
 
-func newResourceInformer(client ResourceClient, namespace string, apiResource *metav1.APIResource, triggerFunc func(runtimeclient.Object), labelSelector string) (cache.Store, cache.Controller) {
+// NewOverrideSourceInformer returns an informer limited to ConfigMaps
+// or Secrets that may be referenced by overrides as indicated by
+// labeling.
+func NewOverrideSourceInformer(client ResourceClient, namespace string, apiResource *metav1.APIResource, triggerFunc func(runtimeclient.Object)) (cache.Store, cache.Controller) {
+	labelSelector := labels.Set(map[string]string{OverrideSourceLabelKey: OverrideSourceLabelValue}).AsSelector().String()
+	return newResourceInformer(client, namespace, apiResource, triggerFunc, labelSelector, "")
+}
+
+// EndpointSliceAPIResource is the API resource of EndpointSlices.
+var EndpointSliceAPIResource = metav1.APIResource{
+	Name:       "endpointslices",
+	Group:      discoveryv1.GroupName,
+	Version:    "v1",
+	Kind:       "EndpointSlice",
+	Namespaced: true,
+}
+
+// NewEndpointSliceInformer returns an informer limited to the
+// EndpointSlices that are labeled with the name of the service they
+// belong to.
+func NewEndpointSliceInformer(client ResourceClient, namespace string, triggerFunc func(runtimeclient.Object)) (cache.Store, cache.Controller) {
+	return newResourceInformer(client, namespace, &EndpointSliceAPIResource, triggerFunc, discoveryv1.LabelServiceName, "")
+}
+
+// CustomResourceDefinitionAPIResource is the API resource of
+// CustomResourceDefinitions.
+var CustomResourceDefinitionAPIResource = metav1.APIResource{
+	Name:    "customresourcedefinitions",
+	Group:   apiextv1.GroupName,
+	Version: "v1",
+	Kind:    "CustomResourceDefinition",
+}
+
+// NewNamedResourceInformer returns an informer limited to the
+// cluster-scoped resource with the given name.
+func NewNamedResourceInformer(client ResourceClient, apiResource *metav1.APIResource, name string, triggerFunc func(runtimeclient.Object)) (cache.Store, cache.Controller) {
+	fieldSelector := fields.OneTermEqualSelector("metadata.name", name).String()
+	return newResourceInformer(client, "", apiResource, triggerFunc, "", fieldSelector)
+}
+
+func newResourceInformer(client ResourceClient, namespace string, apiResource *metav1.APIResource, triggerFunc func(runtimeclient.Object), labelSelector, fieldSelector string) (cache.Store, cache.Controller) {
 	obj := &unstructured.Unstructured{}
 
 	if apiResource != nil {
@@ -55,10 +98,12 @@ func newResourceInformer(client ResourceClient, namespace string, apiResource *m
 		&cache.ListWatch{
 			ListFunc: func(options metav1.ListOptions) (pkgruntime.Object, error) {
 				options.LabelSelector = labelSelector
+				options.FieldSelector = fieldSelector
 				return client.Resources(namespace).List(context.Background(), options)
 			},
 			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
 				options.LabelSelector = labelSelector
+				options.FieldSelector = fieldSelector
 				return client.Resources(namespace).Watch(context.Background(), options)
 			},
 		},
@@ -0,0 +1,97 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"sigs.k8s.io/kubefed/pkg/apis/core/typeconfig"
+)
+
+const (
+	// SelectorFieldsAnnotation on a FederatedTypeConfig lists, as
+	// comma-separated dot-separated paths, the label selector fields
+	// of its target type. The sync controller reports selectors that
+	// contain a label it adds to the metadata of managed resources,
+	// since such a selector likely matches fewer resources in member
+	// clusters than intended. The selector fields of well-known
+	// target types are used if the annotation is not set, and no
+	// field is protected if it is set to an empty value.
+	SelectorFieldsAnnotation = "kubefed.io/selector-fields"
+)
+
+// defaultSelectorFields are the label selector fields of well-known
+// target kinds, whose labels determine the pods that are selected.
+var defaultSelectorFields = map[string][]string{
+	ServiceKind:             {"spec.selector"},
+	"ReplicationController": {"spec.selector"},
+	"Deployment":            {"spec.selector.matchLabels"},
+	"ReplicaSet":            {"spec.selector.matchLabels"},
+	"StatefulSet":           {"spec.selector.matchLabels"},
+	"DaemonSet":             {"spec.selector.matchLabels"},
+	"Job":                   {"spec.selector.matchLabels"},
+}
+
+// SelectorFields returns the label selector fields of the target type
+// of the given type config.
+func SelectorFields(typeConfig typeconfig.Interface) []string {
+	objectMeta := typeConfig.GetObjectMeta()
+	value, ok := objectMeta.GetAnnotations()[SelectorFieldsAnnotation]
+	if !ok {
+		return defaultSelectorFields[typeConfig.GetTargetType().Kind]
+	}
+	var fields []string
+	for _, field := range strings.Split(value, ",") {
+		if field = strings.TrimSpace(field); len(field) > 0 {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// ManagedSelectorLabels returns the labels of the given label selector
+// fields of the given object that equal the managed label or one of
+// the given managed labels, as the path of the field followed by the
+// key of the label. The selectors are left as they are, since a user
+// may intend to select by a label that KubeFed also sets.
+func ManagedSelectorLabels(obj *unstructured.Unstructured, selectorFields []string, managedLabels map[string]string) []string {
+	var collisions []string
+	for _, field := range selectorFields {
+		selector, found, err := unstructured.NestedStringMap(obj.Object, strings.Split(field, ".")...)
+		if err != nil || !found {
+			continue
+		}
+		var keys []string
+		if selector[ManagedByKubeFedLabelKey] == ManagedByKubeFedLabelValue {
+			keys = append(keys, ManagedByKubeFedLabelKey)
+		}
+		for key, value := range managedLabels {
+			if selectorValue, ok := selector[key]; ok && selectorValue == value {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			collisions = append(collisions, fmt.Sprintf("%s[%s]", field, key))
+		}
+	}
+	return collisions
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	fedv1b1 "sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
+)
+
+func TestSelectorFields(t *testing.T) {
+	testCases := map[string]struct {
+		kind        string
+		annotations map[string]string
+		expected    []string
+	}{
+		"well-known type defaults to its selector": {
+			kind:     "Deployment",
+			expected: []string{"spec.selector.matchLabels"},
+		},
+		"other type has no selector by default": {
+			kind: ConfigMapKind,
+		},
+		"annotation lists the selectors": {
+			kind:        "Widget",
+			annotations: map[string]string{SelectorFieldsAnnotation: "spec.selector, spec.podSelector.matchLabels"},
+			expected:    []string{"spec.selector", "spec.podSelector.matchLabels"},
+		},
+		"empty annotation disables the default": {
+			kind:        ServiceKind,
+			annotations: map[string]string{SelectorFieldsAnnotation: ""},
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			typeConfig := &fedv1b1.FederatedTypeConfig{
+				ObjectMeta: metav1.ObjectMeta{Annotations: tc.annotations},
+				Spec: fedv1b1.FederatedTypeConfigSpec{
+					TargetType: fedv1b1.APIResource{Kind: tc.kind},
+				},
+			}
+			if fields := SelectorFields(typeConfig); !reflect.DeepEqual(fields, tc.expected) {
+				t.Fatalf("Expected %v, got %v", tc.expected, fields)
+			}
+		})
+	}
+}
+
+func TestManagedSelectorLabels(t *testing.T) {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"selector": map[string]interface{}{
+				"app":                    "web",
+				ManagedByKubeFedLabelKey: ManagedByKubeFedLabelValue,
+				"example.io/team":        "platform",
+				"example.io/tier":        "frontend",
+			},
+		},
+	}}
+	managedLabels := map[string]string{
+		"example.io/team": "platform",
+		// A selector label whose value differs from that of the
+		// managed label does not collide with it.
+		"example.io/tier": "backend",
+	}
+	expectedSelector, _, _ := unstructured.NestedStringMap(obj.Object, "spec", "selector")
+
+	collisions := ManagedSelectorLabels(obj, []string{"spec.selector", "spec.missing"}, managedLabels)
+	expected := []string{"spec.selector[example.io/team]", "spec.selector[kubefed.io/managed]"}
+	if !reflect.DeepEqual(collisions, expected) {
+		t.Fatalf("Expected collisions %v, got %v", expected, collisions)
+	}
+	selector, _, _ := unstructured.NestedStringMap(obj.Object, "spec", "selector")
+	if !reflect.DeepEqual(selector, expectedSelector) {
+		t.Fatalf("Expected the selector to be left as %v, got %v", expectedSelector, selector)
+	}
+
+	if collisions := ManagedSelectorLabels(obj, []string{"spec.selector"}, nil); !reflect.DeepEqual(collisions, []string{"spec.selector[kubefed.io/managed]"}) {
+		t.Fatalf("Expected only the managed label to collide, got %v", collisions)
+	}
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	fedv1b1 "sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
+)
+
+// RetainServerManagedMetadata copies to the desired object the
+// labels, annotations and finalizers identified by the given metadata
+// that are set on the cluster object, which may be nil if the
+// resource does not yet exist. Metadata populated in a member cluster
+// is thereby neither removed by propagation nor reported as drift.
+func RetainServerManagedMetadata(desiredObj, clusterObj *unstructured.Unstructured, metadata fedv1b1.ServerManagedMetadata) {
+	if clusterObj == nil {
+		return
+	}
+
+	if labels := retainKeys(desiredObj.GetLabels(), clusterObj.GetLabels(), metadata.Labels); labels != nil {
+		desiredObj.SetLabels(labels)
+	}
+	if annotations := retainKeys(desiredObj.GetAnnotations(), clusterObj.GetAnnotations(), metadata.Annotations); annotations != nil {
+		desiredObj.SetAnnotations(annotations)
+	}
+
+	serverManaged := sets.New(metadata.Finalizers...)
+	finalizers := desiredObj.GetFinalizers()
+	desired := sets.New(finalizers...)
+	for _, finalizer := range clusterObj.GetFinalizers() {
+		if serverManaged.Has(finalizer) && !desired.Has(finalizer) {
+			finalizers = append(finalizers, finalizer)
+			desired.Insert(finalizer)
+		}
+	}
+	if len(finalizers) > len(desiredObj.GetFinalizers()) {
+		desiredObj.SetFinalizers(finalizers)
+	}
+}
+
+// retainKeys returns the desired map with the given keys of the live
+// map added, or nil if none of the keys are set in the live map.
+func retainKeys(desired, live map[string]string, keys []string) map[string]string {
+	var retained map[string]string
+	for _, key := range keys {
+		value, ok := live[key]
+		if !ok {
+			continue
+		}
+		if retained == nil {
+			retained = make(map[string]string, len(desired)+len(keys))
+			for k, v := range desired {
+				retained[k] = v
+			}
+		}
+		retained[key] = value
+	}
+	return retained
+}
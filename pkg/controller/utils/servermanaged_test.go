@@ -0,0 +1,108 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"reflect"
+	"testing"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"sigs.k8s.io/kubefed/pkg/apis/core/common"
+	fedv1b1 "sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
+)
+
+func TestRetainServerManagedMetadata(t *testing.T) {
+	newObj := func(labels, annotations map[string]string, finalizers ...string) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{}}
+		obj.SetLabels(labels)
+		obj.SetAnnotations(annotations)
+		obj.SetFinalizers(finalizers)
+		return obj
+	}
+	namespaceTypeConfig := &fedv1b1.FederatedTypeConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: common.NamespaceName,
+		},
+		Spec: fedv1b1.FederatedTypeConfigSpec{
+			TargetType: fedv1b1.APIResource{
+				Version: "v1",
+				Kind:    "Namespace",
+				Scope:   "Cluster",
+			},
+		},
+	}
+	customMetadata := fedv1b1.ServerManagedMetadata{
+		Annotations: []string{"example.com/defaulted"},
+		Finalizers:  []string{"example.com/protection"},
+	}
+	testCases := map[string]struct {
+		desired             *unstructured.Unstructured
+		cluster             *unstructured.Unstructured
+		metadata            fedv1b1.ServerManagedMetadata
+		expectedLabels      map[string]string
+		expectedAnnotations map[string]string
+		expectedFinalizers  []string
+	}{
+		"namespace name label is retained for namespaces": {
+			desired:        newObj(map[string]string{"app": "foo"}, nil),
+			cluster:        newObj(map[string]string{"app": "bar", apiv1.LabelMetadataName: "foo"}, nil),
+			metadata:       namespaceTypeConfig.GetServerManagedMetadata(),
+			expectedLabels: map[string]string{"app": "foo", apiv1.LabelMetadataName: "foo"},
+		},
+		"namespace name label is not retained for other types": {
+			desired:        newObj(map[string]string{"app": "foo"}, nil),
+			cluster:        newObj(map[string]string{apiv1.LabelMetadataName: "foo"}, nil),
+			metadata:       (&fedv1b1.FederatedTypeConfig{}).GetServerManagedMetadata(),
+			expectedLabels: map[string]string{"app": "foo"},
+		},
+		"custom annotation and finalizer are retained": {
+			desired:             newObj(nil, map[string]string{"a": "1"}, "kubefed.io/example"),
+			cluster:             newObj(nil, map[string]string{"example.com/defaulted": "true", "b": "2"}, "example.com/protection", "example.com/other"),
+			metadata:            customMetadata,
+			expectedAnnotations: map[string]string{"a": "1", "example.com/defaulted": "true"},
+			expectedFinalizers:  []string{"kubefed.io/example", "example.com/protection"},
+		},
+		"finalizer already desired is not duplicated": {
+			desired:            newObj(nil, nil, "example.com/protection"),
+			cluster:            newObj(nil, nil, "example.com/protection"),
+			metadata:           customMetadata,
+			expectedFinalizers: []string{"example.com/protection"},
+		},
+		"nothing is retained for a new resource": {
+			desired:        newObj(map[string]string{"app": "foo"}, nil),
+			metadata:       customMetadata,
+			expectedLabels: map[string]string{"app": "foo"},
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			RetainServerManagedMetadata(tc.desired, tc.cluster, tc.metadata)
+			if labels := tc.desired.GetLabels(); !reflect.DeepEqual(labels, tc.expectedLabels) {
+				t.Errorf("Expected labels %v, got %v", tc.expectedLabels, labels)
+			}
+			if annotations := tc.desired.GetAnnotations(); !reflect.DeepEqual(annotations, tc.expectedAnnotations) {
+				t.Errorf("Expected annotations %v, got %v", tc.expectedAnnotations, annotations)
+			}
+			if finalizers := tc.desired.GetFinalizers(); !reflect.DeepEqual(finalizers, tc.expectedFinalizers) {
+				t.Errorf("Expected finalizers %v, got %v", tc.expectedFinalizers, finalizers)
+			}
+		})
+	}
+}
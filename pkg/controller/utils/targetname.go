@@ -0,0 +1,79 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"bytes"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// FederatedNameAnnotation is added to resources in member clusters
+	// whose name was computed from a name template so that events for
+	// those resources can be mapped back to their federated resource.
+	FederatedNameAnnotation = "kubefed.io/federated-name"
+
+	TargetNameField = "targetName"
+)
+
+// ComputeTargetName evaluates the given name template against the
+// provided labels and returns the resulting name. Referencing a
+// label that is not present is an error.
+func ComputeTargetName(nameTemplate string, labels map[string]string) (string, error) {
+	tmpl, err := template.New("targetName").Option("missingkey=error").Parse(nameTemplate)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to parse target name template")
+	}
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, labels); err != nil {
+		return "", errors.Wrap(err, "failed to evaluate target name template")
+	}
+	name := buf.String()
+	if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+		return "", errors.Errorf("computed target name %q is invalid: %s", name, strings.Join(errs, ", "))
+	}
+	return name, nil
+}
+
+// GetRecordedTargetName returns the target name recorded in the
+// status of a federated resource, or an empty string if no name has
+// been recorded.
+func GetRecordedTargetName(fedObject *unstructured.Unstructured) string {
+	name, _, _ := unstructured.NestedString(fedObject.Object, StatusField, TargetNameField)
+	return name
+}
+
+// FederatedNameForEvent returns the qualified name of the federated
+// resource responsible for the given object, taking into account a
+// target name computed from a name template.
+func FederatedNameForEvent(obj runtimeclient.Object) QualifiedName {
+	qualifiedName := NewQualifiedName(obj)
+	if fedName, ok := obj.GetAnnotations()[FederatedNameAnnotation]; ok && len(fedName) > 0 {
+		qualifiedName.Name = fedName
+	}
+	return qualifiedName
+}
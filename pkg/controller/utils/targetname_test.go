@@ -0,0 +1,87 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestComputeTargetName(t *testing.T) {
+	testCases := map[string]struct {
+		nameTemplate string
+		labels       map[string]string
+		expectedName string
+		expectedErr  bool
+	}{
+		"name computed from label": {
+			nameTemplate: "{{ .tenant }}-config",
+			labels:       map[string]string{"tenant": "foo"},
+			expectedName: "foo-config",
+		},
+		"name computed from qualified label": {
+			nameTemplate: `{{ index . "example.com/tenant" }}`,
+			labels:       map[string]string{"example.com/tenant": "bar"},
+			expectedName: "bar",
+		},
+		"missing label is an error": {
+			nameTemplate: "{{ .tenant }}-config",
+			expectedErr:  true,
+		},
+		"invalid computed name is an error": {
+			nameTemplate: "{{ .tenant }}",
+			labels:       map[string]string{"tenant": "Foo_Bar"},
+			expectedErr:  true,
+		},
+		"invalid template is an error": {
+			nameTemplate: "{{ .tenant",
+			expectedErr:  true,
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			name, err := ComputeTargetName(tc.nameTemplate, tc.labels)
+			if tc.expectedErr {
+				if err == nil {
+					t.Fatalf("Expected an error, got name %q", name)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if name != tc.expectedName {
+				t.Fatalf("Expected name %q, got %q", tc.expectedName, name)
+			}
+		})
+	}
+}
+
+func TestFederatedNameForEvent(t *testing.T) {
+	obj := &unstructured.Unstructured{}
+	obj.SetNamespace("ns")
+	obj.SetName("foo-config")
+	if name := FederatedNameForEvent(obj); name.String() != "ns/foo-config" {
+		t.Fatalf("Expected name %q, got %q", "ns/foo-config", name)
+	}
+
+	obj.SetAnnotations(map[string]string{FederatedNameAnnotation: "foo"})
+	if name := FederatedNameForEvent(obj); name.String() != "ns/foo" {
+		t.Fatalf("Expected name %q, got %q", "ns/foo", name)
+	}
+}
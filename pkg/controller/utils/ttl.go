@@ -0,0 +1,64 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// TTLAnnotation on a federated resource gives the duration (e.g.
+	// "72h") after which the sync controller deletes the resource,
+	// removing it from member clusters.
+	TTLAnnotation = "kubefed.io/ttl"
+
+	// TTLStartAnnotation on a federated resource gives the RFC 3339
+	// time from which its TTL elapses. The TTL elapses from the
+	// creation of the resource if it is not present.
+	TTLStartAnnotation = "kubefed.io/ttl-start"
+)
+
+// GetExpirationTime returns the time at which the TTL of the given
+// federated resource elapses, or nil if it does not have a TTL.
+func GetExpirationTime(obj metav1.Object) (*time.Time, error) {
+	annotations := obj.GetAnnotations()
+	value, ok := annotations[TTLAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	ttl, err := time.ParseDuration(value)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to parse the %q annotation", TTLAnnotation)
+	}
+	if ttl <= 0 {
+		return nil, errors.Errorf("The %q annotation must be a positive duration, got %q", TTLAnnotation, value)
+	}
+
+	start := obj.GetCreationTimestamp().Time
+	if value, ok := annotations[TTLStartAnnotation]; ok {
+		start, err = time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed to parse the %q annotation", TTLStartAnnotation)
+		}
+	}
+	expiration := start.Add(ttl)
+	return &expiration, nil
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/utils/ptr"
+)
+
+func TestGetExpirationTime(t *testing.T) {
+	created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := map[string]struct {
+		annotations map[string]string
+		expected    *time.Time
+		expectedErr bool
+	}{
+		"no expiration without a TTL": {},
+		"TTL elapses from creation": {
+			annotations: map[string]string{TTLAnnotation: "72h"},
+			expected:    ptr.To(created.Add(72 * time.Hour)),
+		},
+		"TTL elapses from the specified start": {
+			annotations: map[string]string{
+				TTLAnnotation:      "30m",
+				TTLStartAnnotation: "2024-06-01T08:00:00Z",
+			},
+			expected: ptr.To(time.Date(2024, 6, 1, 8, 30, 0, 0, time.UTC)),
+		},
+		"invalid TTL": {
+			annotations: map[string]string{TTLAnnotation: "3 days"},
+			expectedErr: true,
+		},
+		"non-positive TTL": {
+			annotations: map[string]string{TTLAnnotation: "0s"},
+			expectedErr: true,
+		},
+		"invalid start": {
+			annotations: map[string]string{
+				TTLAnnotation:      "1h",
+				TTLStartAnnotation: "tomorrow",
+			},
+			expectedErr: true,
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			obj := &unstructured.Unstructured{}
+			obj.SetCreationTimestamp(metav1.NewTime(created))
+			obj.SetAnnotations(tc.annotations)
+
+			expiration, err := GetExpirationTime(obj)
+			if tc.expectedErr {
+				if err == nil {
+					t.Fatalf("Expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if (expiration == nil) != (tc.expected == nil) || (expiration != nil && !expiration.Equal(*tc.expected)) {
+				t.Fatalf("Expected expiration %v, got %v", tc.expected, expiration)
+			}
+		})
+	}
+}
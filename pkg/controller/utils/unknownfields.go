@@ -0,0 +1,143 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextv1client "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/typed/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+)
+
+// GetTargetSchema returns the OpenAPI schema of the given version of
+// the CRD defining the target type. A nil schema is returned if the
+// target type is not defined by a CRD.
+func GetTargetSchema(config *rest.Config, apiResource metav1.APIResource) (*apiextv1.JSONSchemaProps, error) {
+	// CRDs must have a group
+	if len(apiResource.Group) == 0 {
+		return nil, nil
+	}
+	crdClient, err := apiextv1client.NewForConfig(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to create crd clientset")
+	}
+	crdName := TargetCRDName(apiResource)
+	crd, err := crdClient.CustomResourceDefinitions().Get(context.Background(), crdName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "Error attempting retrieval of crd %q", crdName)
+	}
+	return CRDVersionSchema(crd, apiResource.Version)
+}
+
+// TargetCRDName returns the name of the CRD that would define the given
+// target type.
+func TargetCRDName(apiResource metav1.APIResource) string {
+	return fmt.Sprintf("%s.%s", apiResource.Name, apiResource.Group)
+}
+
+// CRDVersionSchema returns the OpenAPI schema of the given version of
+// the given CRD.
+func CRDVersionSchema(crd *apiextv1.CustomResourceDefinition, versionName string) (*apiextv1.JSONSchemaProps, error) {
+	for _, version := range crd.Spec.Versions {
+		if version.Name != versionName {
+			continue
+		}
+		if version.Schema == nil || version.Schema.OpenAPIV3Schema == nil {
+			return nil, errors.Errorf("Version %q of crd %q does not define a schema", version.Name, crd.Name)
+		}
+		return version.Schema.OpenAPIV3Schema, nil
+	}
+	return nil, errors.Errorf("Version %q of crd %q not found", versionName, crd.Name)
+}
+
+// PruneUnknownFields removes the fields of the given object that are
+// not described by the given schema and returns the dot-separated
+// paths of the removed fields in sorted order. The apiVersion, kind
+// and metadata fields of the object are never removed.
+func PruneUnknownFields(obj map[string]interface{}, schema *apiextv1.JSONSchemaProps) []string {
+	var pruned []string
+	pruneObject(obj, schema, "", true, &pruned)
+	sort.Strings(pruned)
+	return pruned
+}
+
+func pruneObject(obj map[string]interface{}, schema *apiextv1.JSONSchemaProps, path string, isResource bool, pruned *[]string) {
+	for key, value := range obj {
+		if isResource && (key == "apiVersion" || key == "kind" || key == MetadataField) {
+			continue
+		}
+		fieldPath := key
+		if len(path) > 0 {
+			fieldPath = fmt.Sprintf("%s.%s", path, key)
+		}
+		fieldSchema, known := fieldSchema(schema, key)
+		if !known {
+			delete(obj, key)
+			*pruned = append(*pruned, fieldPath)
+			continue
+		}
+		pruneValue(value, fieldSchema, fieldPath, pruned)
+	}
+}
+
+func pruneValue(value interface{}, schema *apiextv1.JSONSchemaProps, path string, pruned *[]string) {
+	if schema == nil {
+		return
+	}
+	switch typedValue := value.(type) {
+	case map[string]interface{}:
+		pruneObject(typedValue, schema, path, schema.XEmbeddedResource, pruned)
+	case []interface{}:
+		if schema.Items == nil || schema.Items.Schema == nil {
+			return
+		}
+		for i, item := range typedValue {
+			pruneValue(item, schema.Items.Schema, fmt.Sprintf("%s[%d]", path, i), pruned)
+		}
+	}
+}
+
+// fieldSchema returns the schema of the named field of an object
+// described by the given schema and whether the field is known. A
+// known field without a schema may hold any value.
+func fieldSchema(schema *apiextv1.JSONSchemaProps, key string) (*apiextv1.JSONSchemaProps, bool) {
+	if propSchema, ok := schema.Properties[key]; ok {
+		return &propSchema, true
+	}
+	if additional := schema.AdditionalProperties; additional != nil {
+		if additional.Schema != nil {
+			return additional.Schema, true
+		}
+		if additional.Allows {
+			return nil, true
+		}
+	}
+	if schema.XPreserveUnknownFields != nil && *schema.XPreserveUnknownFields {
+		return nil, true
+	}
+	return nil, false
+}
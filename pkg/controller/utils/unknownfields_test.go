@@ -0,0 +1,115 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"reflect"
+	"testing"
+
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestPruneUnknownFields(t *testing.T) {
+	schema := &apiextv1.JSONSchemaProps{
+		Type: "object",
+		Properties: map[string]apiextv1.JSONSchemaProps{
+			"spec": {
+				Type: "object",
+				Properties: map[string]apiextv1.JSONSchemaProps{
+					"size": {Type: "integer"},
+					"ports": {
+						Type: "array",
+						Items: &apiextv1.JSONSchemaPropsOrArray{
+							Schema: &apiextv1.JSONSchemaProps{
+								Type: "object",
+								Properties: map[string]apiextv1.JSONSchemaProps{
+									"port": {Type: "integer"},
+								},
+							},
+						},
+					},
+					"labels": {
+						Type: "object",
+						AdditionalProperties: &apiextv1.JSONSchemaPropsOrBool{
+							Schema: &apiextv1.JSONSchemaProps{Type: "string"},
+						},
+					},
+					"config": {
+						Type:                   "object",
+						XPreserveUnknownFields: ptr.To(true),
+					},
+				},
+			},
+		},
+	}
+	obj := map[string]interface{}{
+		"apiVersion": "example.io/v2",
+		"kind":       "Example",
+		"metadata": map[string]interface{}{
+			"name": "foo",
+		},
+		"spec": map[string]interface{}{
+			"size":     int64(1),
+			"obsolete": "value",
+			"ports": []interface{}{
+				map[string]interface{}{
+					"port":     int64(80),
+					"protocol": "TCP",
+				},
+			},
+			"labels": map[string]interface{}{
+				"foo": "bar",
+			},
+			"config": map[string]interface{}{
+				"anything": "goes",
+			},
+		},
+		"legacy": true,
+	}
+
+	pruned := PruneUnknownFields(obj, schema)
+
+	expectedPruned := []string{"legacy", "spec.obsolete", "spec.ports[0].protocol"}
+	if !reflect.DeepEqual(pruned, expectedPruned) {
+		t.Fatalf("Expected pruned fields %v, got %v", expectedPruned, pruned)
+	}
+	expectedObj := map[string]interface{}{
+		"apiVersion": "example.io/v2",
+		"kind":       "Example",
+		"metadata": map[string]interface{}{
+			"name": "foo",
+		},
+		"spec": map[string]interface{}{
+			"size": int64(1),
+			"ports": []interface{}{
+				map[string]interface{}{
+					"port": int64(80),
+				},
+			},
+			"labels": map[string]interface{}{
+				"foo": "bar",
+			},
+			"config": map[string]interface{}{
+				"anything": "goes",
+			},
+		},
+	}
+	if !reflect.DeepEqual(obj, expectedObj) {
+		t.Fatalf("Expected object %v, got %v", expectedObj, obj)
+	}
+}
@@ -45,6 +45,22 @@ type WorkerOptions struct {
 
 	// MaxConcurrentReconciles is the maximum number of concurrent Reconciles which can be run. Defaults to 1.
 	MaxConcurrentReconciles int
+
+	// Priority returns the priority with which the named resource is
+	// reconciled. Defaults to DefaultReconcilePriority for all
+	// resources.
+	Priority func(qualifiedName QualifiedName) int
+
+	// MetricsProvider provides the workqueue metrics of the queue of
+	// the worker. Defaults to the provider of the metrics exposed by
+	// the controller manager.
+	MetricsProvider workqueue.MetricsProvider
+
+	// Limiter bounds the number of reconciles in flight across the
+	// workers sharing it, in addition to MaxConcurrentReconciles.
+	// Reconciles are only bounded by MaxConcurrentReconciles if not
+	// set.
+	Limiter *ReconcileLimiter
 }
 
 type WorkerTiming struct {
@@ -53,6 +69,16 @@ type WorkerTiming struct {
 	ClusterSyncDelay time.Duration
 	InitialBackoff   time.Duration
 	MaxBackoff       time.Duration
+	// MaxPriorityWait bounds the time a resource waits to be
+	// reconciled ahead of resources of higher priority.
+	MaxPriorityWait time.Duration
+	// DebounceWindow is the time for which a resource enqueued by
+	// Enqueue waits before it is reconciled. Resources enqueued again
+	// within the window are reconciled once, with the state observed
+	// at the end of the window, and enqueuing a resource after the
+	// window has elapsed always triggers another reconcile. Resources
+	// are reconciled without delay if not set.
+	DebounceWindow time.Duration
 }
 
 type asyncWorker struct {
@@ -64,17 +90,28 @@ type asyncWorker struct {
 
 	maxConcurrentReconciles int
 
+	priority func(qualifiedName QualifiedName) int
+
+	limiter *ReconcileLimiter
+
+	// Closed when the worker is stopped.
+	stopChan <-chan struct{}
+
 	// For triggering reconciliation of a single resource. This is
 	// used when there is an add/update/delete operation on a resource
 	// in either the API of the cluster hosting KubeFed or in the API
 	// of a member cluster.
 	deliverer *DelayingDeliverer
 
-	// Work queue allowing parallel processing of resources
-	queue workqueue.Interface
+	// Work queue allowing parallel processing of resources in order
+	// of priority
+	queue *PriorityQueue
 
 	// Backoff manager
 	backoff *flowcontrol.Backoff
+
+	// Counts the deliveries of resources whose reconciliation failed.
+	retries workqueue.CounterMetric
 }
 
 func NewReconcileWorker(name string, reconcile ReconcileFunc, options WorkerOptions) ReconcileWorker {
@@ -90,22 +127,36 @@ func NewReconcileWorker(name string, reconcile ReconcileFunc, options WorkerOpti
 	if options.MaxBackoff == 0 {
 		options.MaxBackoff = time.Minute
 	}
+	if options.MaxPriorityWait == 0 {
+		options.MaxPriorityWait = time.Second * 30
+	}
 	if options.MaxConcurrentReconciles == 0 {
 		options.MaxConcurrentReconciles = 1
 	}
+	if options.MetricsProvider == nil {
+		options.MetricsProvider = metrics.NewWorkqueueMetricsProvider()
+	}
+	if options.Priority == nil {
+		options.Priority = func(QualifiedName) int {
+			return DefaultReconcilePriority
+		}
+	}
 	return &asyncWorker{
 		name:                    name,
 		reconcile:               reconcile,
 		timing:                  options.WorkerTiming,
 		maxConcurrentReconciles: options.MaxConcurrentReconciles,
+		priority:                options.Priority,
+		limiter:                 options.Limiter,
 		deliverer:               NewDelayingDeliverer(),
-		queue:                   workqueue.NewNamed(name),
+		queue:                   NewPriorityQueueWithMetrics(name, options.MaxPriorityWait, options.MetricsProvider),
+		retries:                 options.MetricsProvider.NewRetriesMetric(name),
 		backoff:                 flowcontrol.NewBackOff(options.InitialBackoff, options.MaxBackoff),
 	}
 }
 
 func (w *asyncWorker) Enqueue(qualifiedName QualifiedName) {
-	w.deliver(qualifiedName, 0, false)
+	w.deliver(qualifiedName, w.timing.DebounceWindow, false)
 }
 
 func (w *asyncWorker) EnqueueForError(qualifiedName QualifiedName) {
@@ -131,12 +182,13 @@ func (w *asyncWorker) EnqueueWithDelay(qualifiedName QualifiedName, delay time.D
 
 func (w *asyncWorker) Run(stopChan <-chan struct{}) {
 	w.initMetrics()
+	w.stopChan = stopChan
 
 	StartBackoffGC(w.backoff, stopChan)
 	w.deliverer.StartWithHandler(func(item *DelayingDelivererItem) {
 		qualifiedName, ok := item.Value.(*QualifiedName)
 		if ok {
-			w.queue.Add(*qualifiedName)
+			w.queue.Add(*qualifiedName, w.priority(*qualifiedName))
 		}
 	})
 
@@ -162,6 +214,7 @@ func (w *asyncWorker) SetDelay(retryDelay, clusterSyncDelay time.Duration) {
 func (w *asyncWorker) deliver(qualifiedName QualifiedName, delay time.Duration, failed bool) {
 	key := qualifiedName.String()
 	if failed {
+		w.retries.Inc()
 		w.backoff.Next(key, time.Now())
 		delay += w.backoff.Get(key)
 	} else {
@@ -176,15 +229,19 @@ func (w *asyncWorker) worker() {
 }
 
 func (w *asyncWorker) reconcileOnce() bool {
-	obj, quit := w.queue.Get()
+	qualifiedName, quit := w.queue.Get()
 	if quit {
 		return false
 	}
-	defer w.queue.Done(obj)
+	defer w.queue.Done(qualifiedName)
 
-	qualifiedName, ok := obj.(QualifiedName)
-	if !ok {
-		return true
+	// The slot is only acquired once there is a resource to
+	// reconcile so that idle workers do not hold slots.
+	if w.limiter != nil {
+		if !w.limiter.Acquire(w.name, w.stopChan) {
+			return false
+		}
+		defer w.limiter.Release(w.name)
 	}
 
 	metrics.ControllerRuntimeActiveWorkers.WithLabelValues(w.name).Add(1)
@@ -86,3 +86,65 @@ func TestDeduplicate(t *testing.T) {
 
 	t.Logf("the enqueued (before or during reconciliation) 15 same events have been squashed to 2")
 }
+
+func TestDebounce(t *testing.T) {
+	qualifiedName := QualifiedName{
+		Namespace: "ns",
+		Name:      "name",
+	}
+
+	// The state of the resource, which is updated before each enqueue
+	var state, reconcileCount int32
+	reconciledStates := make(chan int32, 10)
+
+	worker := NewReconcileWorker("test debounce",
+		func(qualifiedName QualifiedName) ReconciliationStatus {
+			atomic.AddInt32(&reconcileCount, 1)
+			reconciledStates <- atomic.LoadInt32(&state)
+			return StatusAllOK
+		},
+		WorkerOptions{
+			WorkerTiming: WorkerTiming{
+				DebounceWindow: 500 * time.Millisecond,
+			},
+		},
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	worker.Run(ctx.Done())
+
+	// Update the resource rapidly within the debounce window
+	for i := 0; i < 10; i++ {
+		atomic.AddInt32(&state, 1)
+		worker.Enqueue(qualifiedName)
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	select {
+	case reconciled := <-reconciledStates:
+		if reconciled != 10 {
+			t.Errorf("expected the latest state 10 to be reconciled but got %d", reconciled)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the coalesced reconcile")
+	}
+
+	// An update after the window has elapsed must not be dropped
+	atomic.AddInt32(&state, 1)
+	worker.Enqueue(qualifiedName)
+	select {
+	case reconciled := <-reconciledStates:
+		if reconciled != 11 {
+			t.Errorf("expected the latest state 11 to be reconciled but got %d", reconciled)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the reconcile of the final update")
+	}
+
+	// Allow for any stray reconcile to be observed
+	time.Sleep(time.Second)
+	if count := atomic.LoadInt32(&reconcileCount); count != 2 {
+		t.Errorf("expected reconcile count 2 but got %d", count)
+	}
+}
@@ -0,0 +1,228 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package federatedresource
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/klog/v2"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
+	"sigs.k8s.io/kubefed/pkg/controller/utils"
+	"sigs.k8s.io/kubefed/pkg/controller/webhook"
+)
+
+const (
+	ResourceName = "FederatedResource"
+)
+
+// AdmissionHook validates the overrides of federated resources against
+// the target type of their FederatedTypeConfig. The reader is expected
+// to be backed by an informer cache of the FederatedTypeConfigs in the
+// KubeFed namespace and of CRDs so that admission does not depend on
+// requests to the API server.
+type AdmissionHook struct {
+	reader           runtimeclient.Reader
+	kubeFedNamespace string
+}
+
+var _ admission.Handler = &AdmissionHook{}
+
+func NewAdmissionHook(reader runtimeclient.Reader, kubeFedNamespace string) *AdmissionHook {
+	return &AdmissionHook{
+		reader:           reader,
+		kubeFedNamespace: kubeFedNamespace,
+	}
+}
+
+func (a *AdmissionHook) Handle(ctx context.Context, admissionSpec admission.Request) admission.Response {
+	klog.V(4).Infof("Validating %q AdmissionRequest = %s", ResourceName, webhook.AdmissionRequestDebugString(admissionSpec))
+
+	// We want to let through:
+	// - Requests that are not for create, update
+	// - Requests for subresources (e.g. status)
+	// - Requests for things that are not federated types
+	createOrUpdate := admissionSpec.Operation == admissionv1.Create || admissionSpec.Operation == admissionv1.Update
+	if !createOrUpdate || len(admissionSpec.SubResource) != 0 {
+		return allowed()
+	}
+
+	typeConfig, err := a.typeConfigFor(ctx, admissionSpec.Resource)
+	if err != nil {
+		return errored(http.StatusInternalServerError, metav1.StatusReasonInternalError, err)
+	}
+	if typeConfig == nil {
+		return allowed()
+	}
+
+	admittingObject := &unstructured.Unstructured{}
+	if err := webhook.Unmarshal(&admissionSpec.Object, admittingObject); err != nil {
+		return errored(http.StatusBadRequest, metav1.StatusReasonBadRequest, err)
+	}
+	var oldObject *unstructured.Unstructured
+	if admissionSpec.Operation == admissionv1.Update {
+		oldObject = &unstructured.Unstructured{}
+		if err := webhook.Unmarshal(&admissionSpec.OldObject, oldObject); err != nil {
+			return errored(http.StatusBadRequest, metav1.StatusReasonBadRequest, err)
+		}
+	}
+
+	targetType := typeConfig.GetTargetType()
+	targetKind := schema.GroupKind{Group: targetType.Group, Kind: targetType.Kind}
+	targetSchema, err := a.targetSchema(ctx, targetType)
+	if err != nil {
+		// Immutable fields of built-in types are still known without
+		// the schema.
+		klog.Warningf("Overrides of %q will not be validated against its schema: %v", targetType.Kind, err)
+		targetSchema = nil
+	}
+
+	klog.V(4).Infof("Validating %q %s = %+v", ResourceName, admittingObject.GetKind(), admittingObject.Object)
+
+	return webhook.Validate(func() field.ErrorList {
+		return validateOverrides(admittingObject, oldObject, targetKind, targetSchema)
+	})
+}
+
+// typeConfigFor returns the FederatedTypeConfig whose federated type is
+// the given resource, or nil if there is none.
+func (a *AdmissionHook) typeConfigFor(ctx context.Context, resource metav1.GroupVersionResource) (*v1beta1.FederatedTypeConfig, error) {
+	typeConfigs := &v1beta1.FederatedTypeConfigList{}
+	if err := a.reader.List(ctx, typeConfigs, runtimeclient.InNamespace(a.kubeFedNamespace)); err != nil {
+		return nil, err
+	}
+	for i := range typeConfigs.Items {
+		federatedType := typeConfigs.Items[i].GetFederatedType()
+		if federatedType.Group == resource.Group && federatedType.Name == resource.Resource {
+			return &typeConfigs.Items[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// targetSchema returns the schema of the given target type, or nil if
+// the target type is not defined by a CRD.
+func (a *AdmissionHook) targetSchema(ctx context.Context, targetType metav1.APIResource) (*apiextv1.JSONSchemaProps, error) {
+	// CRDs must have a group
+	if len(targetType.Group) == 0 {
+		return nil, nil
+	}
+	crd := &apiextv1.CustomResourceDefinition{}
+	err := a.reader.Get(ctx, runtimeclient.ObjectKey{Name: utils.TargetCRDName(targetType)}, crd)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return utils.CRDVersionSchema(crd, targetType.Version)
+}
+
+// validateOverrides validates the overrides of the given federated
+// resource against the schema of the target type. When the resource is
+// updated, overrides of immutable fields of the target type must be
+// unchanged from the given old resource since a changed value would
+// fail every update of the resources in member clusters. Overrides of
+// immutable fields are allowed when the resource is created since they
+// are applied when the resources are created in member clusters.
+func validateOverrides(fedObject, oldObject *unstructured.Unstructured, targetKind schema.GroupKind, targetSchema *apiextv1.JSONSchemaProps) field.ErrorList {
+	overridesPath := field.NewPath(utils.SpecField, utils.OverridesField)
+	overridesMap, err := utils.GetOverrides(fedObject)
+	if err != nil {
+		return field.ErrorList{field.Invalid(overridesPath, nil, err.Error())}
+	}
+	oldOverridesMap := utils.OverridesMap{}
+	if oldObject != nil {
+		oldOverridesMap, err = utils.GetOverrides(oldObject)
+		if err != nil {
+			// The overrides of the old resource were not valid, so
+			// there is nothing to compare with.
+			oldObject = nil
+		}
+	}
+	clusterNames := sets.KeySet(overridesMap).Union(sets.KeySet(oldOverridesMap))
+	allErrs := field.ErrorList{}
+	for _, clusterName := range sets.List(clusterNames) {
+		clusterPath := overridesPath.Key(clusterName)
+		overrides, oldOverrides := overridesMap[clusterName], oldOverridesMap[clusterName]
+		if oldObject != nil {
+			allErrs = append(allErrs, validateImmutableOverrides(clusterPath, overrides, oldOverrides, targetKind, targetSchema)...)
+		}
+		if _, err := utils.ValidateOverrides(overrides, targetSchema); err != nil {
+			allErrs = append(allErrs, field.Invalid(clusterPath, nil, err.Error()))
+		}
+	}
+	return allErrs
+}
+
+// validateImmutableOverrides returns an error for each path of an
+// immutable field whose override differs between the given overrides
+// and the given old overrides.
+func validateImmutableOverrides(path *field.Path, overrides, oldOverrides utils.ClusterOverrides, targetKind schema.GroupKind, targetSchema *apiextv1.JSONSchemaProps) field.ErrorList {
+	immutablePaths := sets.New(utils.ImmutableOverridePaths(overrides, targetKind, targetSchema)...)
+	immutablePaths.Insert(utils.ImmutableOverridePaths(oldOverrides, targetKind, targetSchema)...)
+	allErrs := field.ErrorList{}
+	for _, immutablePath := range sets.List(immutablePaths) {
+		if !reflect.DeepEqual(overridesForPath(overrides, immutablePath), overridesForPath(oldOverrides, immutablePath)) {
+			allErrs = append(allErrs, field.Forbidden(path, fmt.Sprintf("override of immutable field %s of %s cannot be changed once the resource is created", immutablePath, targetKind.Kind)))
+		}
+	}
+	return allErrs
+}
+
+func overridesForPath(overrides utils.ClusterOverrides, path string) utils.ClusterOverrides {
+	var matching utils.ClusterOverrides
+	for _, override := range overrides {
+		if override.Path == path {
+			matching = append(matching, override)
+		}
+	}
+	return matching
+}
+
+func allowed() admission.Response {
+	return admission.Response{
+		AdmissionResponse: admissionv1.AdmissionResponse{
+			Allowed: true,
+		},
+	}
+}
+
+func errored(code int32, reason metav1.StatusReason, err error) admission.Response {
+	return admission.Response{
+		AdmissionResponse: admissionv1.AdmissionResponse{
+			Allowed: false,
+			Result: &metav1.Status{
+				Status: metav1.StatusFailure, Code: code, Reason: reason,
+				Message: err.Error(),
+			},
+		},
+	}
+}
@@ -0,0 +1,313 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package federatedresource
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/ptr"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
+	genericscheme "sigs.k8s.io/kubefed/pkg/client/generic/scheme"
+	"sigs.k8s.io/kubefed/pkg/controller/utils"
+)
+
+const kubeFedNamespace = "kube-federation-system"
+
+var federatedWidgets = metav1.GroupVersionResource{Group: "types.kubefed.io", Version: "v1beta1", Resource: "federatedwidgets"}
+
+func newTypeConfig(name, namespace string) *v1beta1.FederatedTypeConfig {
+	return &v1beta1.FederatedTypeConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec: v1beta1.FederatedTypeConfigSpec{
+			TargetType: v1beta1.APIResource{
+				Group:      "example.io",
+				Version:    "v1",
+				Kind:       "Widget",
+				PluralName: "widgets",
+				Scope:      apiextv1.NamespaceScoped,
+			},
+			Propagation: v1beta1.PropagationEnabled,
+			FederatedType: v1beta1.APIResource{
+				Group:      federatedWidgets.Group,
+				Version:    federatedWidgets.Version,
+				Kind:       "FederatedWidget",
+				PluralName: federatedWidgets.Resource,
+				Scope:      apiextv1.NamespaceScoped,
+			},
+		},
+	}
+}
+
+func newWidgetCRD() *apiextv1.CustomResourceDefinition {
+	return &apiextv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "widgets.example.io"},
+		Spec: apiextv1.CustomResourceDefinitionSpec{
+			Group: "example.io",
+			Versions: []apiextv1.CustomResourceDefinitionVersion{{
+				Name: "v1",
+				Schema: &apiextv1.CustomResourceValidation{
+					OpenAPIV3Schema: &apiextv1.JSONSchemaProps{
+						Type: "object",
+						Properties: map[string]apiextv1.JSONSchemaProps{
+							"spec": {
+								Type: "object",
+								Properties: map[string]apiextv1.JSONSchemaProps{
+									"region": {
+										Type:         "string",
+										XValidations: apiextv1.ValidationRules{{Rule: "self == oldSelf"}},
+									},
+									"size": {Type: "integer"},
+								},
+							},
+						},
+					},
+				},
+			}},
+		},
+	}
+}
+
+func newFakeReader(t *testing.T, objs ...runtimeclient.Object) runtimeclient.Reader {
+	scheme := runtime.NewScheme()
+	require.NoError(t, genericscheme.AddToScheme(scheme))
+	require.NoError(t, apiextv1.AddToScheme(scheme))
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+}
+
+func TestTypeConfigFor(t *testing.T) {
+	testCases := map[string]struct {
+		typeConfigs  []runtimeclient.Object
+		resource     metav1.GroupVersionResource
+		expectedName string
+	}{
+		"type config of the federated type is returned": {
+			typeConfigs:  []runtimeclient.Object{newTypeConfig("widgets.example.io", kubeFedNamespace)},
+			resource:     federatedWidgets,
+			expectedName: "widgets.example.io",
+		},
+		"type config in another namespace is ignored": {
+			typeConfigs: []runtimeclient.Object{newTypeConfig("widgets.example.io", "other")},
+			resource:    federatedWidgets,
+		},
+		"resource that is not a federated type has no type config": {
+			typeConfigs: []runtimeclient.Object{newTypeConfig("widgets.example.io", kubeFedNamespace)},
+			resource:    metav1.GroupVersionResource{Group: "example.io", Version: "v1", Resource: "widgets"},
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			hook := NewAdmissionHook(newFakeReader(t, tc.typeConfigs...), kubeFedNamespace)
+			typeConfig, err := hook.typeConfigFor(context.Background(), tc.resource)
+			require.NoError(t, err)
+			if tc.expectedName == "" {
+				assert.Nil(t, typeConfig)
+				return
+			}
+			require.NotNil(t, typeConfig)
+			assert.Equal(t, tc.expectedName, typeConfig.Name)
+		})
+	}
+}
+
+func TestHandle(t *testing.T) {
+	fedWidget := func(overrides ...utils.ClusterOverride) runtime.RawExtension {
+		fedObject := &unstructured.Unstructured{Object: map[string]interface{}{}}
+		fedObject.SetAPIVersion("types.kubefed.io/v1beta1")
+		fedObject.SetKind("FederatedWidget")
+		fedObject.SetName("widget")
+		fedObject.SetNamespace("default")
+		overridesMap := utils.OverridesMap{}
+		if len(overrides) > 0 {
+			overridesMap["cluster1"] = overrides
+		}
+		require.NoError(t, utils.SetOverrides(fedObject, overridesMap))
+		raw, err := json.Marshal(fedObject.Object)
+		require.NoError(t, err)
+		return runtime.RawExtension{Raw: raw}
+	}
+	region := func(value string) utils.ClusterOverride {
+		return utils.ClusterOverride{Path: "/spec/region", Value: value}
+	}
+	size := func(value interface{}) utils.ClusterOverride {
+		return utils.ClusterOverride{Path: "/spec/size", Value: value}
+	}
+	testCases := map[string]struct {
+		operation   admissionv1.Operation
+		resource    metav1.GroupVersionResource
+		subResource string
+		object      runtime.RawExtension
+		oldObject   runtime.RawExtension
+		withoutCRD  bool
+		allowed     bool
+	}{
+		"deletion is allowed": {
+			operation: admissionv1.Delete,
+			resource:  federatedWidgets,
+			allowed:   true,
+		},
+		"status update is allowed": {
+			operation:   admissionv1.Update,
+			resource:    federatedWidgets,
+			subResource: "status",
+			allowed:     true,
+		},
+		"resource that is not a federated type is allowed": {
+			operation: admissionv1.Create,
+			resource:  metav1.GroupVersionResource{Group: "example.io", Version: "v1", Resource: "widgets"},
+			object:    runtime.RawExtension{Raw: []byte("not json")},
+			allowed:   true,
+		},
+		"override of an immutable field is allowed on creation": {
+			operation: admissionv1.Create,
+			resource:  federatedWidgets,
+			object:    fedWidget(region("eu")),
+			allowed:   true,
+		},
+		"override of the wrong type is rejected": {
+			operation: admissionv1.Create,
+			resource:  federatedWidgets,
+			object:    fedWidget(size("large")),
+		},
+		"changed override of an immutable field is rejected on update": {
+			operation: admissionv1.Update,
+			resource:  federatedWidgets,
+			object:    fedWidget(region("us")),
+			oldObject: fedWidget(region("eu")),
+		},
+		"changed override of a mutable field is allowed on update": {
+			operation: admissionv1.Update,
+			resource:  federatedWidgets,
+			object:    fedWidget(region("eu"), size(2)),
+			oldObject: fedWidget(region("eu"), size(1)),
+			allowed:   true,
+		},
+		"changed override is allowed on update when the target CRD is missing": {
+			operation:  admissionv1.Update,
+			resource:   federatedWidgets,
+			object:     fedWidget(region("us")),
+			oldObject:  fedWidget(region("eu")),
+			withoutCRD: true,
+			allowed:    true,
+		},
+		"malformed object is rejected": {
+			operation: admissionv1.Create,
+			resource:  federatedWidgets,
+			object:    runtime.RawExtension{Raw: []byte("not json")},
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			objs := []runtimeclient.Object{newTypeConfig("widgets.example.io", kubeFedNamespace)}
+			if !tc.withoutCRD {
+				objs = append(objs, newWidgetCRD())
+			}
+			hook := NewAdmissionHook(newFakeReader(t, objs...), kubeFedNamespace)
+			response := hook.Handle(context.Background(), admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					Operation:   tc.operation,
+					Resource:    tc.resource,
+					SubResource: tc.subResource,
+					Object:      tc.object,
+					OldObject:   tc.oldObject,
+					DryRun:      ptr.To(false),
+				},
+			})
+			assert.Equal(t, tc.allowed, response.Allowed, "%+v", response.Result)
+		})
+	}
+}
+
+func TestValidateOverrides(t *testing.T) {
+	pvc := schema.GroupKind{Kind: "PersistentVolumeClaim"}
+	storageClass := func(value string) utils.ClusterOverride {
+		return utils.ClusterOverride{Path: "/spec/storageClassName", Value: value}
+	}
+	storage := func(value string) utils.ClusterOverride {
+		return utils.ClusterOverride{Path: "/spec/resources/requests/storage", Value: value}
+	}
+	testCases := map[string]struct {
+		overrides     utils.ClusterOverrides
+		oldOverrides  *utils.ClusterOverrides
+		expectedError string
+	}{
+		"override of an immutable field is accepted on creation": {
+			overrides: utils.ClusterOverrides{storageClass("fast")},
+		},
+		"unchanged override of an immutable field is accepted on update": {
+			overrides:    utils.ClusterOverrides{storageClass("fast"), storage("20Gi")},
+			oldOverrides: &utils.ClusterOverrides{storageClass("fast"), storage("10Gi")},
+		},
+		"changed override of an immutable field is rejected on update": {
+			overrides:     utils.ClusterOverrides{storageClass("slow")},
+			oldOverrides:  &utils.ClusterOverrides{storageClass("fast")},
+			expectedError: "spec.overrides[cluster1]: Forbidden: override of immutable field /spec/storageClassName of PersistentVolumeClaim cannot be changed once the resource is created",
+		},
+		"added override of an immutable field is rejected on update": {
+			overrides:     utils.ClusterOverrides{storageClass("fast")},
+			oldOverrides:  &utils.ClusterOverrides{},
+			expectedError: "spec.overrides[cluster1]: Forbidden: override of immutable field /spec/storageClassName of PersistentVolumeClaim cannot be changed once the resource is created",
+		},
+		"removed override of an immutable field is rejected on update": {
+			overrides:     utils.ClusterOverrides{},
+			oldOverrides:  &utils.ClusterOverrides{storageClass("fast")},
+			expectedError: "spec.overrides[cluster1]: Forbidden: override of immutable field /spec/storageClassName of PersistentVolumeClaim cannot be changed once the resource is created",
+		},
+		"changed override of a mutable field is accepted on update": {
+			overrides:    utils.ClusterOverrides{storage("20Gi")},
+			oldOverrides: &utils.ClusterOverrides{storage("10Gi")},
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			newFedObject := func(overrides utils.ClusterOverrides) *unstructured.Unstructured {
+				fedObject := &unstructured.Unstructured{Object: map[string]interface{}{}}
+				overridesMap := utils.OverridesMap{}
+				if len(overrides) > 0 {
+					overridesMap["cluster1"] = overrides
+				}
+				assert.NoError(t, utils.SetOverrides(fedObject, overridesMap))
+				return fedObject
+			}
+			var oldObject *unstructured.Unstructured
+			if tc.oldOverrides != nil {
+				oldObject = newFedObject(*tc.oldOverrides)
+			}
+
+			errs := validateOverrides(newFedObject(tc.overrides), oldObject, pvc, nil)
+			if tc.expectedError == "" {
+				assert.Empty(t, errs)
+				return
+			}
+			assert.EqualError(t, errs.ToAggregate(), tc.expectedError)
+		})
+	}
+}
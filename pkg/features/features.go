@@ -31,6 +31,16 @@ const (
 
 	// RawResourceStatusCollection enables the collection of the status of target types when enabled
 	RawResourceStatusCollection featuregate.Feature = "RawResourceStatusCollection"
+
+	// StatusFeedback enables the status controller to write a value
+	// aggregated from the status of target resources in member
+	// clusters back to the status of the federated resource.
+	StatusFeedback featuregate.Feature = "StatusFeedback"
+
+	// MetadataMerge preserves labels and annotations added to managed
+	// resources in member clusters, removing only those that KubeFed
+	// previously set and no longer declares.
+	MetadataMerge featuregate.Feature = "MetadataMerge"
 )
 
 func init() {
@@ -46,4 +56,15 @@ var DefaultKubeFedFeatureGates = map[featuregate.Feature]featuregate.FeatureSpec
 	SchedulerPreferences:        {Default: true, PreRelease: featuregate.Alpha},
 	PushReconciler:              {Default: true, PreRelease: featuregate.Beta},
 	RawResourceStatusCollection: {Default: false, PreRelease: featuregate.Beta},
+	StatusFeedback:              {Default: false, PreRelease: featuregate.Alpha},
+	MetadataMerge:               {Default: false, PreRelease: featuregate.Alpha},
+}
+
+// TypeOverridableFeatureGates consists of the feature keys that a
+// FederatedTypeConfig may enable or disable for the controllers of
+// its type only.
+var TypeOverridableFeatureGates = []featuregate.Feature{
+	RawResourceStatusCollection,
+	StatusFeedback,
+	MetadataMerge,
 }
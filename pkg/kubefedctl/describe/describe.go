@@ -0,0 +1,282 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package describe
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+
+	genericclient "sigs.k8s.io/kubefed/pkg/client/generic"
+	"sigs.k8s.io/kubefed/pkg/controller/sync/status"
+	"sigs.k8s.io/kubefed/pkg/controller/utils"
+	"sigs.k8s.io/kubefed/pkg/kubefedctl/enable"
+	"sigs.k8s.io/kubefed/pkg/kubefedctl/options"
+	"sigs.k8s.io/kubefed/pkg/kubefedctl/util"
+)
+
+var (
+	describeNamespaceLong = `
+		Describes the propagation of a namespace: its FederatedNamespace
+		and the federated resources it contains, together with their
+		propagation status in each member cluster.
+
+		Current context is assumed to be a Kubernetes cluster hosting
+		the kubefed control plane. Please use the
+		--host-cluster-context flag otherwise.`
+
+	describeNamespaceExample = `
+		# Describe the propagation of namespace foo
+		kubefedctl describe namespace foo --host-cluster-context=cluster1`
+
+	describeObjectLong = `
+		Describes the propagation of a single federated resource as a
+		machine-readable report in JSON or YAML: the clusters selected
+		and excluded by its placement, its conditions and, for each
+		member cluster, its propagation status, whether the recorded
+		version matches the managed resource, and any drift or other
+		divergence. Nothing is modified.
+
+		TYPE is the name of the federated type or of its target type.
+
+		Current context is assumed to be a Kubernetes cluster hosting
+		the kubefed control plane. Please use the
+		--host-cluster-context flag otherwise.`
+
+	describeObjectExample = `
+		# Describe the propagation of FederatedConfigMap foo in namespace bar as JSON
+		kubefedctl describe object federatedconfigmaps foo -n bar -o json --host-cluster-context=cluster1
+
+		# Describe the propagation of the federated namespace bar
+		kubefedctl describe object namespaces bar --host-cluster-context=cluster1`
+)
+
+type describeNamespace struct {
+	options.GlobalSubcommandOptions
+	namespace string
+}
+
+type describeObject struct {
+	options.GlobalSubcommandOptions
+	typeName          string
+	resourceName      string
+	resourceNamespace string
+	output            string
+}
+
+// NewCmdDescribe is the head of the describe sub commands.
+func NewCmdDescribe(cmdOut io.Writer, config util.FedConfig) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "describe",
+		Short: "Describe the propagation of federated resources",
+		Long:  "Describe the propagation of federated resources",
+		Run: func(cmd *cobra.Command, args []string) {
+			err := cmd.Help()
+			if err != nil {
+				klog.Fatalf("Error: %v", err)
+			}
+		},
+	}
+	cmd.AddCommand(newCmdDescribeNamespace(cmdOut, config))
+	cmd.AddCommand(newCmdDescribeObject(cmdOut, config))
+
+	return cmd
+}
+
+func newCmdDescribeNamespace(cmdOut io.Writer, config util.FedConfig) *cobra.Command {
+	opts := &describeNamespace{}
+	cmd := &cobra.Command{
+		Use:     "namespace NAME",
+		Short:   "Describe the propagation of a namespace and its contents",
+		Long:    describeNamespaceLong,
+		Example: describeNamespaceExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			err := opts.Complete(args)
+			if err != nil {
+				klog.Fatalf("Error: %v", err)
+			}
+
+			err = opts.Run(cmdOut, config)
+			if err != nil {
+				klog.Fatalf("Error: %v", err)
+			}
+		},
+	}
+
+	flags := cmd.Flags()
+	opts.GlobalSubcommandBind(flags)
+	err := flags.MarkHidden("dry-run")
+	if err != nil {
+		klog.Fatalf("Error: %v", err)
+	}
+
+	return cmd
+}
+
+// Complete ensures that options are valid.
+func (o *describeNamespace) Complete(args []string) error {
+	if len(args) == 0 {
+		return errors.New("NAME is required")
+	}
+	o.namespace = args[0]
+	return nil
+}
+
+// Run is the implementation of the `describe namespace` command.
+func (o *describeNamespace) Run(cmdOut io.Writer, config util.FedConfig) error {
+	hostConfig, err := config.HostConfig(o.HostClusterContext, o.Kubeconfig)
+	if err != nil {
+		return errors.Wrap(err, "Failed to get host cluster config")
+	}
+	client, err := genericclient.New(hostConfig)
+	if err != nil {
+		return errors.Wrap(err, "Failed to get kubefed clientset")
+	}
+	root, err := BuildNamespacePropagationGraph(client, o.KubeFedNamespace, o.namespace)
+	if err != nil {
+		return err
+	}
+	return WritePropagationGraph(cmdOut, root)
+}
+
+func newCmdDescribeObject(cmdOut io.Writer, config util.FedConfig) *cobra.Command {
+	opts := &describeObject{}
+	cmd := &cobra.Command{
+		Use:     "object TYPE NAME",
+		Short:   "Describe the propagation of a federated resource as JSON or YAML",
+		Long:    describeObjectLong,
+		Example: describeObjectExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			err := opts.Complete(args, config)
+			if err != nil {
+				klog.Fatalf("Error: %v", err)
+			}
+
+			err = opts.Run(cmdOut, config)
+			if err != nil {
+				klog.Fatalf("Error: %v", err)
+			}
+		},
+	}
+
+	flags := cmd.Flags()
+	opts.GlobalSubcommandBind(flags)
+	flags.StringVarP(&opts.resourceNamespace, "namespace", "n", "", "If present, the namespace scope for this CLI request")
+	flags.StringVarP(&opts.output, "output", "o", OutputFormatYAML, "The format of the report. Valid values are ['json', 'yaml'].")
+	err := flags.MarkHidden("dry-run")
+	if err != nil {
+		klog.Fatalf("Error: %v", err)
+	}
+
+	return cmd
+}
+
+// Complete ensures that options are valid.
+func (o *describeObject) Complete(args []string, config util.FedConfig) error {
+	if len(args) == 0 {
+		return errors.New("TYPE is required")
+	}
+	o.typeName = args[0]
+	if len(args) == 1 {
+		return errors.New("NAME is required")
+	}
+	o.resourceName = args[1]
+	if o.output != OutputFormatJSON && o.output != OutputFormatYAML {
+		return errors.Errorf("Invalid output format %q, valid formats are %q and %q", o.output, OutputFormatJSON, OutputFormatYAML)
+	}
+
+	if len(o.resourceNamespace) == 0 {
+		var err error
+		o.resourceNamespace, err = util.GetNamespace(o.HostClusterContext, o.Kubeconfig, config)
+		return err
+	}
+	return nil
+}
+
+// Run is the implementation of the `describe object` command.
+func (o *describeObject) Run(cmdOut io.Writer, config util.FedConfig) error {
+	hostConfig, err := config.HostConfig(o.HostClusterContext, o.Kubeconfig)
+	if err != nil {
+		return errors.Wrap(err, "Failed to get host cluster config")
+	}
+	apiResource, err := enable.LookupAPIResource(hostConfig, o.typeName, "")
+	if err != nil {
+		return errors.Wrapf(err, "Failed to find type %s", o.typeName)
+	}
+	gvk := schema.GroupVersionKind{Group: apiResource.Group, Version: apiResource.Version, Kind: apiResource.Kind}
+	qualifiedName := utils.QualifiedName{Namespace: o.resourceNamespace, Name: o.resourceName}
+	report, err := DescribeObject(hostConfig, o.KubeFedNamespace, gvk, qualifiedName)
+	if err != nil {
+		return err
+	}
+	return WriteObjectReport(cmdOut, report, o.output)
+}
+
+// WritePropagationGraph writes a human-readable form of the given
+// propagation graph.
+func WritePropagationGraph(w io.Writer, root *PropagationNode) error {
+	return writePropagationNode(w, root, 0)
+}
+
+func writePropagationNode(w io.Writer, node *PropagationNode, depth int) error {
+	indent := strings.Repeat("  ", depth)
+	resource := node.Resource
+	name := utils.QualifiedName{Namespace: resource.Namespace, Name: resource.Name}
+	if resource.Status == nil {
+		if _, err := fmt.Fprintf(w, "%s%s %q: no status\n", indent, resource.Kind, name); err != nil {
+			return err
+		}
+	} else {
+		if _, err := fmt.Fprintf(w, "%s%s %q: %s\n", indent, resource.Kind, name, propagationSummary(resource.Status)); err != nil {
+			return err
+		}
+		for _, cluster := range resource.Status.Clusters {
+			clusterStatus := string(cluster.Status)
+			if cluster.Status == status.ClusterPropagationOK {
+				clusterStatus = "OK"
+			}
+			if _, err := fmt.Fprintf(w, "%s  - %s: %s\n", indent, cluster.Name, clusterStatus); err != nil {
+				return err
+			}
+		}
+	}
+	for _, child := range node.Children {
+		if err := writePropagationNode(w, child, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func propagationSummary(fedStatus *status.GenericFederatedStatus) string {
+	for _, condition := range fedStatus.Conditions {
+		if condition.Type != status.PropagationConditionType {
+			continue
+		}
+		if len(condition.Reason) == 0 {
+			return fmt.Sprintf("Propagation=%s", condition.Status)
+		}
+		return fmt.Sprintf("Propagation=%s (%s)", condition.Status, condition.Reason)
+	}
+	return "Propagation=Unknown"
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package describe
+
+import (
+	"context"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	fedv1b1 "sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
+	genericclient "sigs.k8s.io/kubefed/pkg/client/generic"
+	"sigs.k8s.io/kubefed/pkg/controller/sync/status"
+	"sigs.k8s.io/kubefed/pkg/controller/utils"
+)
+
+// PropagationNode summarizes a federated resource and its
+// propagation to member clusters.
+type PropagationNode struct {
+	// Resource is the metadata and status of the federated resource.
+	// For a namespace that is not federated, only the kind and name
+	// are set and the status is nil.
+	Resource status.GenericFederatedResource
+	// TargetName is the name of the resource managed in member
+	// clusters. The target of a federated namespace is not namespaced.
+	TargetName utils.QualifiedName
+	// Children are the federated resources contained in the
+	// namespace, ordered by kind and name.
+	Children []*PropagationNode
+}
+
+// BuildNamespacePropagationGraph returns the propagation graph of the
+// given namespace, rooted at the namespace's FederatedNamespace and
+// containing the federated resources of every enabled namespaced type
+// in the namespace. The federated types are determined from the
+// FederatedTypeConfigs in kubefedNamespace. Resources are only read.
+func BuildNamespacePropagationGraph(client genericclient.Client, kubefedNamespace, namespace string) (*PropagationNode, error) {
+	typeConfigs := &fedv1b1.FederatedTypeConfigList{}
+	if err := client.List(context.TODO(), typeConfigs, kubefedNamespace); err != nil {
+		return nil, errors.Wrapf(err, "Failed to list FederatedTypeConfigs in namespace %q", kubefedNamespace)
+	}
+
+	root := &PropagationNode{
+		TargetName: utils.QualifiedName{Name: namespace},
+	}
+	root.Resource.Kind = utils.NamespaceKind
+	root.Resource.Name = namespace
+
+	for i := range typeConfigs.Items {
+		typeConfig := &typeConfigs.Items[i]
+		if !typeConfig.GetFederatedNamespaced() {
+			continue
+		}
+		fedObjects, err := listFederatedResources(client, typeConfig, namespace)
+		if err != nil {
+			return nil, err
+		}
+		for _, fedObject := range fedObjects {
+			node, err := newPropagationNode(typeConfig, fedObject)
+			if err != nil {
+				return nil, err
+			}
+			// The federated namespace of a namespace has the name of
+			// the namespace and is contained in it.
+			if typeConfig.IsNamespace() {
+				if fedObject.GetName() == namespace {
+					root.Resource = node.Resource
+				}
+				continue
+			}
+			root.Children = append(root.Children, node)
+		}
+	}
+
+	sort.Slice(root.Children, func(i, j int) bool {
+		a, b := root.Children[i].Resource, root.Children[j].Resource
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.Name < b.Name
+	})
+	return root, nil
+}
+
+func listFederatedResources(client genericclient.Client, typeConfig *fedv1b1.FederatedTypeConfig, namespace string) ([]unstructured.Unstructured, error) {
+	apiResource := typeConfig.GetFederatedType()
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   apiResource.Group,
+		Version: apiResource.Version,
+		Kind:    apiResource.Kind + "List",
+	})
+	err := client.List(context.TODO(), list, namespace)
+	if apierrors.IsNotFound(err) {
+		// The federated type of a disabled type may not be installed.
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to list %s in namespace %q", apiResource.Kind, namespace)
+	}
+	return list.Items, nil
+}
+
+func newPropagationNode(typeConfig *fedv1b1.FederatedTypeConfig, fedObject unstructured.Unstructured) (*PropagationNode, error) {
+	resource, err := status.DecodeGenericFederatedResource(&fedObject)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to read the status of %s %q", fedObject.GetKind(), utils.NewQualifiedName(&fedObject))
+	}
+	return &PropagationNode{
+		Resource:   *resource,
+		TargetName: utils.QualifiedNameForTarget(typeConfig, &fedObject),
+	}, nil
+}
@@ -0,0 +1,179 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package describe
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"sigs.k8s.io/kubefed/pkg/apis/core/common"
+	fedv1b1 "sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
+	"sigs.k8s.io/kubefed/pkg/controller/sync/status"
+	"sigs.k8s.io/kubefed/pkg/controller/utils"
+	"sigs.k8s.io/kubefed/test/common/fake"
+)
+
+func newTypeConfig(name, targetKind string, targetScope, federatedScope apiextv1.ResourceScope) *fedv1b1.FederatedTypeConfig {
+	return &fedv1b1.FederatedTypeConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "kube-federation-system",
+			Name:      name,
+		},
+		Spec: fedv1b1.FederatedTypeConfigSpec{
+			TargetType: fedv1b1.APIResource{
+				Version: "v1",
+				Kind:    targetKind,
+				Scope:   targetScope,
+			},
+			Propagation: fedv1b1.PropagationEnabled,
+			FederatedType: fedv1b1.APIResource{
+				Group:   "types.kubefed.io",
+				Version: "v1beta1",
+				Kind:    "Federated" + targetKind,
+				Scope:   federatedScope,
+			},
+		},
+	}
+}
+
+func newFederatedResource(kind, namespace, name string, clusterStatus map[string]string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("types.kubefed.io/v1beta1")
+	obj.SetKind(kind)
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	if clusterStatus != nil {
+		var clusters []interface{}
+		for clusterName, value := range clusterStatus {
+			clusters = append(clusters, map[string]interface{}{"name": clusterName, "status": value})
+		}
+		obj.Object["status"] = map[string]interface{}{
+			"conditions": []interface{}{
+				map[string]interface{}{"type": string(status.PropagationConditionType), "status": "True"},
+			},
+			"clusters": clusters,
+		}
+	}
+	return obj
+}
+
+func TestBuildNamespacePropagationGraph(t *testing.T) {
+	store := fake.NewStore()
+	client := fake.NewGenericClient(store)
+	typeConfigs := []*fedv1b1.FederatedTypeConfig{
+		newTypeConfig(common.NamespaceName, utils.NamespaceKind, apiextv1.ClusterScoped, apiextv1.NamespaceScoped),
+		newTypeConfig("configmaps", "ConfigMap", apiextv1.NamespaceScoped, apiextv1.NamespaceScoped),
+		newTypeConfig("secrets", "Secret", apiextv1.NamespaceScoped, apiextv1.NamespaceScoped),
+		newTypeConfig("clusterroles", "ClusterRole", apiextv1.ClusterScoped, apiextv1.ClusterScoped),
+	}
+	for _, typeConfig := range typeConfigs {
+		if err := client.Create(context.Background(), typeConfig); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	for _, obj := range []*unstructured.Unstructured{
+		newFederatedResource("FederatedNamespace", "foo", "foo", map[string]string{"cluster1": ""}),
+		newFederatedResource("FederatedConfigMap", "foo", "b", map[string]string{"cluster1": string(status.ApplyOverridesFailed)}),
+		newFederatedResource("FederatedConfigMap", "foo", "a", nil),
+		newFederatedResource("FederatedSecret", "foo", "a", nil),
+		newFederatedResource("FederatedClusterRole", "", "a", nil),
+		// Resources of other namespaces are not included.
+		newFederatedResource("FederatedNamespace", "bar", "bar", nil),
+		newFederatedResource("FederatedConfigMap", "bar", "a", nil),
+	} {
+		if _, err := store.Create(obj); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	root, err := BuildNamespacePropagationGraph(client, "kube-federation-system", "foo")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if root.Resource.Kind != "FederatedNamespace" || root.Resource.Name != "foo" {
+		t.Fatalf("Expected the root to be FederatedNamespace %q, got %s %q", "foo/foo", root.Resource.Kind, root.Resource.Name)
+	}
+	if expected := (utils.QualifiedName{Name: "foo"}); root.TargetName != expected {
+		t.Fatalf("Expected the target of the root to be %q, got %q", expected, root.TargetName)
+	}
+	if root.Resource.Status == nil || len(root.Resource.Status.Clusters) != 1 {
+		t.Fatalf("Expected the status of the root to be summarized, got %v", root.Resource.Status)
+	}
+	var children []utils.QualifiedName
+	for _, child := range root.Children {
+		if child.Resource.Namespace != "foo" {
+			t.Fatalf("Expected only resources of namespace %q, got %s %q", "foo", child.Resource.Kind, child.TargetName)
+		}
+		children = append(children, utils.QualifiedName{Namespace: child.Resource.Kind, Name: child.Resource.Name})
+	}
+	expectedChildren := []utils.QualifiedName{
+		{Namespace: "FederatedConfigMap", Name: "a"},
+		{Namespace: "FederatedConfigMap", Name: "b"},
+		{Namespace: "FederatedSecret", Name: "a"},
+	}
+	if !reflect.DeepEqual(expectedChildren, children) {
+		t.Fatalf("Expected children %v, got %v", expectedChildren, children)
+	}
+	clusterStatus := root.Children[1].Resource.Status.Clusters[0]
+	if clusterStatus.Name != "cluster1" || clusterStatus.Status != status.ApplyOverridesFailed {
+		t.Fatalf("Expected the cluster status of %q to be summarized, got %v", "foo/b", clusterStatus)
+	}
+
+	var out bytes.Buffer
+	if err := WritePropagationGraph(&out, root); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expectedOut := `FederatedNamespace "foo/foo": Propagation=True
+  - cluster1: OK
+  FederatedConfigMap "foo/a": no status
+  FederatedConfigMap "foo/b": Propagation=True
+    - cluster1: ApplyOverridesFailed
+  FederatedSecret "foo/a": no status
+`
+	if out.String() != expectedOut {
+		t.Fatalf("Expected output:\n%s\ngot:\n%s", expectedOut, out.String())
+	}
+}
+
+func TestBuildNamespacePropagationGraphForUnfederatedNamespace(t *testing.T) {
+	store := fake.NewStore()
+	client := fake.NewGenericClient(store)
+	if err := client.Create(context.Background(), newTypeConfig("configmaps", "ConfigMap", apiextv1.NamespaceScoped, apiextv1.NamespaceScoped)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := store.Create(newFederatedResource("FederatedConfigMap", "foo", "a", nil)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	root, err := BuildNamespacePropagationGraph(client, "kube-federation-system", "foo")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if root.Resource.Kind != utils.NamespaceKind || root.Resource.Status != nil {
+		t.Fatalf("Expected the root to be the unfederated namespace, got %s with status %v", root.Resource.Kind, root.Resource.Status)
+	}
+	if len(root.Children) != 1 {
+		t.Fatalf("Expected 1 child, got %d", len(root.Children))
+	}
+}
@@ -0,0 +1,554 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package describe
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sort"
+
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/rest"
+
+	"sigs.k8s.io/kubefed/pkg/apis/core/common"
+	fedv1a1 "sigs.k8s.io/kubefed/pkg/apis/core/v1alpha1"
+	fedv1b1 "sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
+	genericclient "sigs.k8s.io/kubefed/pkg/client/generic"
+	"sigs.k8s.io/kubefed/pkg/controller/sync"
+	"sigs.k8s.io/kubefed/pkg/controller/sync/status"
+	"sigs.k8s.io/kubefed/pkg/controller/sync/version"
+	"sigs.k8s.io/kubefed/pkg/controller/utils"
+)
+
+const (
+	// NamespaceNotFederated is the reason that a cluster is excluded
+	// from the placement of a namespaced resource whose namespace is
+	// not federated by a control plane deployed cluster-wide.
+	NamespaceNotFederated utils.PlacementExclusionReason = "NamespaceNotFederated"
+
+	// The formats in which an object report can be written.
+	OutputFormatJSON = "json"
+	OutputFormatYAML = "yaml"
+)
+
+// ObjectReport describes the propagation of a single federated
+// resource: its placement, its conditions and, for each member
+// cluster, its propagation status, the currency of its recorded
+// version and any divergence of the managed resource.
+type ObjectReport struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	// TargetKind and TargetName identify the resources managed in
+	// member clusters. The target of a federated namespace is not
+	// namespaced.
+	TargetKind string `json:"targetKind"`
+	TargetName string `json:"targetName"`
+	// ObservedGeneration is the generation of the resource last
+	// reconciled by the sync controller.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Generation is the current generation of the resource.
+	Generation int64                      `json:"generation,omitempty"`
+	Conditions []*status.GenericCondition `json:"conditions,omitempty"`
+	Placement  PlacementReport            `json:"placement"`
+	// Versions are nil if no versions have been recorded.
+	Versions *VersionReport `json:"versions,omitempty"`
+	// Clusters are the joined clusters and any other cluster that the
+	// status or the recorded versions of the resource refer to,
+	// ordered by name.
+	Clusters []ClusterReport `json:"clusters,omitempty"`
+}
+
+// PlacementReport lists the clusters selected and excluded by the
+// placement of a federated resource.
+type PlacementReport struct {
+	Selected []string          `json:"selected"`
+	Excluded []ExcludedCluster `json:"excluded,omitempty"`
+}
+
+// ExcludedCluster is a cluster excluded by the placement of a
+// federated resource.
+type ExcludedCluster struct {
+	Name   string                         `json:"name"`
+	Reason utils.PlacementExclusionReason `json:"reason"`
+}
+
+// VersionReport describes the versions recorded for a federated
+// resource by the sync controller.
+type VersionReport struct {
+	TemplateVersion string `json:"templateVersion"`
+	OverrideVersion string `json:"overrideVersion,omitempty"`
+	// Current is whether the recorded versions were recorded for the
+	// current template and overrides of the resource.
+	Current bool `json:"current"`
+}
+
+// ClusterReport describes the propagation of a federated resource to a
+// member cluster.
+type ClusterReport struct {
+	Name string `json:"name"`
+	// Joined is false for a cluster that is only referred to by the
+	// status or the recorded versions, e.g. because it was removed.
+	Joined bool `json:"joined"`
+	Ready  bool `json:"ready"`
+	// Status is the propagation status reported for the cluster, with
+	// OK for successful propagation. It is empty if none is reported.
+	Status        string             `json:"status,omitempty"`
+	ApplyResult   status.ApplyResult `json:"applyResult,omitempty"`
+	ApplyError    string             `json:"applyError,omitempty"`
+	RetainedUntil string             `json:"retainedUntil,omitempty"`
+	// RecordedVersion is the version of the managed resource recorded
+	// when it was last propagated, and ObservedVersion the version of
+	// the managed resource in the cluster.
+	RecordedVersion string `json:"recordedVersion,omitempty"`
+	ObservedVersion string `json:"observedVersion,omitempty"`
+	VersionMatches  bool   `json:"versionMatches"`
+	// Exists is nil if the managed resource was not retrieved, which
+	// is the case for clusters that are not ready.
+	Exists         *bool  `json:"exists,omitempty"`
+	RetrievalError string `json:"retrievalError,omitempty"`
+	// Conditions are those of the cluster in the status, e.g. the
+	// DriftDetected condition.
+	Conditions  []*status.GenericCondition `json:"conditions,omitempty"`
+	Divergences []Divergence               `json:"divergences,omitempty"`
+}
+
+// Divergence is a disagreement between the status, the recorded
+// version and the managed resource in a cluster.
+type Divergence struct {
+	Type    utils.DivergenceType `json:"type"`
+	Message string               `json:"message"`
+}
+
+// ClusterClientFunc returns a client for the given member cluster.
+type ClusterClientFunc func(cluster *fedv1b1.KubeFedCluster) (genericclient.Client, error)
+
+// DescribeObject returns a report of the propagation of the named
+// federated resource. The kind identifies either the federated type or
+// the target type of an enabled FederatedTypeConfig in
+// kubefedNamespace. The resource is only read, as are the managed
+// resources in member clusters.
+func DescribeObject(hostConfig *rest.Config, kubefedNamespace string, gvk schema.GroupVersionKind, qualifiedName utils.QualifiedName) (*ObjectReport, error) {
+	client, err := genericclient.New(hostConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to get kubefed clientset")
+	}
+	clusterClient := func(cluster *fedv1b1.KubeFedCluster) (genericclient.Client, error) {
+		clusterConfig, err := utils.BuildClusterConfig(cluster, client, kubefedNamespace)
+		if err != nil {
+			return nil, err
+		}
+		return genericclient.New(clusterConfig)
+	}
+	return BuildObjectReport(client, clusterClient, kubefedNamespace, gvk, qualifiedName)
+}
+
+// BuildObjectReport returns a report of the propagation of the named
+// federated resource like DescribeObject, accessing member clusters
+// with the clients returned by the given function.
+func BuildObjectReport(client genericclient.Client, clusterClient ClusterClientFunc, kubefedNamespace string, gvk schema.GroupVersionKind, qualifiedName utils.QualifiedName) (*ObjectReport, error) {
+	ctx := context.TODO()
+	typeConfigs := &fedv1b1.FederatedTypeConfigList{}
+	if err := client.List(ctx, typeConfigs, kubefedNamespace); err != nil {
+		return nil, errors.Wrapf(err, "Failed to list FederatedTypeConfigs in namespace %q", kubefedNamespace)
+	}
+	typeConfig, namespaceTypeConfig := findTypeConfigs(typeConfigs.Items, gvk)
+	if typeConfig == nil {
+		return nil, errors.Errorf("No FederatedTypeConfig in namespace %q federates %s", kubefedNamespace, gvk.GroupKind())
+	}
+	federatedType := typeConfig.GetFederatedType()
+	switch {
+	case typeConfig.IsNamespace():
+		// The federated namespace of a namespace has the name of the
+		// namespace and is contained in it.
+		qualifiedName.Namespace = qualifiedName.Name
+	case !typeConfig.GetFederatedNamespaced():
+		qualifiedName.Namespace = ""
+	}
+
+	fedObject, err := getFederatedResource(client, federatedType, qualifiedName)
+	if err != nil {
+		return nil, err
+	}
+	if fedObject == nil {
+		return nil, errors.Errorf("%s %q not found", federatedType.Kind, qualifiedName)
+	}
+	resource, err := status.DecodeGenericFederatedResource(fedObject)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to read the status of %s %q", federatedType.Kind, qualifiedName)
+	}
+
+	clusterList := &fedv1b1.KubeFedClusterList{}
+	if err := client.List(ctx, clusterList, kubefedNamespace); err != nil {
+		return nil, errors.Wrapf(err, "Failed to list KubeFedClusters in namespace %q", kubefedNamespace)
+	}
+	var clusters []*fedv1b1.KubeFedCluster
+	for i := range clusterList.Items {
+		clusters = append(clusters, &clusterList.Items[i])
+	}
+
+	targetName, err := targetQualifiedName(typeConfig, fedObject)
+	if err != nil {
+		return nil, err
+	}
+	report := &ObjectReport{
+		Kind:       federatedType.Kind,
+		Namespace:  qualifiedName.Namespace,
+		Name:       qualifiedName.Name,
+		TargetKind: typeConfig.GetTargetType().Kind,
+		TargetName: targetName.String(),
+		Generation: fedObject.GetGeneration(),
+	}
+	clusterStatuses := make(map[string]status.GenericClusterStatus)
+	if resource.Status != nil {
+		report.ObservedGeneration = resource.Status.ObservedGeneration
+		report.Conditions = resource.Status.Conditions
+		for _, clusterStatus := range resource.Status.Clusters {
+			clusterStatuses[clusterStatus.Name] = clusterStatus
+		}
+	}
+
+	placement, err := placementReport(client, kubefedNamespace, typeConfig, namespaceTypeConfig, fedObject, clusters)
+	if err != nil {
+		return nil, err
+	}
+	report.Placement = *placement
+
+	propagatedVersion, err := getPropagatedVersion(client, typeConfig, qualifiedName)
+	if err != nil {
+		return nil, err
+	}
+	recordedVersions := make(map[string]string)
+	if propagatedVersion != nil {
+		report.Versions, err = versionReport(fedObject, propagatedVersion)
+		if err != nil {
+			return nil, err
+		}
+		for _, clusterVersion := range propagatedVersion.ClusterVersions {
+			recordedVersions[clusterVersion.ClusterName] = clusterVersion.Version
+		}
+	}
+
+	clusterReports := make(map[string]*ClusterReport)
+	clusterObjects := make(map[string]*unstructured.Unstructured)
+	for _, cluster := range clusters {
+		clusterReport := &ClusterReport{
+			Name:   cluster.Name,
+			Joined: true,
+			Ready:  utils.IsClusterReady(&cluster.Status),
+		}
+		clusterReports[cluster.Name] = clusterReport
+		if !clusterReport.Ready {
+			continue
+		}
+		clusterObj, err := getClusterObject(clusterClient, cluster, typeConfig.GetTargetType(), targetName)
+		if err != nil {
+			clusterReport.RetrievalError = err.Error()
+			continue
+		}
+		exists := clusterObj != nil
+		clusterReport.Exists = &exists
+		if exists {
+			clusterReport.ObservedVersion = utils.ObjectVersion(clusterObj)
+		}
+		clusterObjects[cluster.Name] = clusterObj
+	}
+	clusterReportFor := func(clusterName string) *ClusterReport {
+		clusterReport, ok := clusterReports[clusterName]
+		if !ok {
+			clusterReport = &ClusterReport{Name: clusterName}
+			clusterReports[clusterName] = clusterReport
+		}
+		return clusterReport
+	}
+	for clusterName, clusterStatus := range clusterStatuses {
+		clusterReport := clusterReportFor(clusterName)
+		clusterReport.Status = string(clusterStatus.Status)
+		if clusterStatus.Status == status.ClusterPropagationOK {
+			clusterReport.Status = "OK"
+		}
+		clusterReport.ApplyResult = clusterStatus.ApplyResult
+		clusterReport.ApplyError = clusterStatus.ApplyError
+		clusterReport.RetainedUntil = clusterStatus.RetainedUntil
+		clusterReport.Conditions = clusterStatus.Conditions
+	}
+	for clusterName, recordedVersion := range recordedVersions {
+		clusterReport := clusterReportFor(clusterName)
+		clusterReport.RecordedVersion = recordedVersion
+		clusterReport.VersionMatches = len(clusterReport.ObservedVersion) > 0 && recordedVersion == clusterReport.ObservedVersion
+	}
+
+	consistency, err := utils.CheckConsistency(typeConfig, fedObject, propagatedVersion, clusterObjects)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to check the consistency of %s %q", federatedType.Kind, qualifiedName)
+	}
+	for _, divergence := range consistency.Divergences {
+		clusterReport := clusterReportFor(divergence.ClusterName)
+		clusterReport.Divergences = append(clusterReport.Divergences, Divergence{
+			Type:    divergence.Type,
+			Message: divergence.Message,
+		})
+	}
+
+	for _, clusterReport := range clusterReports {
+		report.Clusters = append(report.Clusters, *clusterReport)
+	}
+	sort.Slice(report.Clusters, func(i, j int) bool {
+		return report.Clusters[i].Name < report.Clusters[j].Name
+	})
+	return report, nil
+}
+
+// WriteObjectReport writes the given report in the given format, which
+// is either json or yaml.
+func WriteObjectReport(w io.Writer, report *ObjectReport, format string) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "Failed to encode the report")
+	}
+	switch format {
+	case OutputFormatJSON:
+		data = append(data, '\n')
+	case OutputFormatYAML:
+		data, err = yaml.JSONToYAML(data)
+		if err != nil {
+			return errors.Wrap(err, "Failed to encode the report")
+		}
+	default:
+		return errors.Errorf("Invalid output format %q, valid formats are %q and %q", format, OutputFormatJSON, OutputFormatYAML)
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// findTypeConfigs returns the type config whose federated or target
+// type has the group and kind of the given GVK, and the type config of
+// namespaces.
+func findTypeConfigs(typeConfigs []fedv1b1.FederatedTypeConfig, gvk schema.GroupVersionKind) (typeConfig, namespaceTypeConfig *fedv1b1.FederatedTypeConfig) {
+	for i := range typeConfigs {
+		candidate := &typeConfigs[i]
+		if candidate.IsNamespace() {
+			namespaceTypeConfig = candidate
+		}
+		if typeConfig != nil {
+			continue
+		}
+		for _, apiResource := range []metav1.APIResource{candidate.GetFederatedType(), candidate.GetTargetType()} {
+			if apiResource.Group == gvk.Group && apiResource.Kind == gvk.Kind {
+				typeConfig = candidate
+				break
+			}
+		}
+	}
+	return typeConfig, namespaceTypeConfig
+}
+
+// getFederatedResource returns the named resource of the given
+// federated type, or nil if it does not exist.
+func getFederatedResource(client genericclient.Client, apiResource metav1.APIResource, qualifiedName utils.QualifiedName) (*unstructured.Unstructured, error) {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   apiResource.Group,
+		Version: apiResource.Version,
+		Kind:    apiResource.Kind,
+	})
+	err := client.Get(context.TODO(), obj, qualifiedName.Namespace, qualifiedName.Name)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to retrieve %s %q", apiResource.Kind, qualifiedName)
+	}
+	return obj, nil
+}
+
+// targetQualifiedName returns the qualified name of the resources
+// managed in member clusters for the given federated resource.
+func targetQualifiedName(typeConfig *fedv1b1.FederatedTypeConfig, fedObject *unstructured.Unstructured) (utils.QualifiedName, error) {
+	qualifiedName := utils.QualifiedNameForTarget(typeConfig, fedObject)
+	nameTemplate := typeConfig.GetTargetNameTemplate()
+	if len(nameTemplate) == 0 {
+		return qualifiedName, nil
+	}
+	name, err := utils.ComputeTargetName(nameTemplate, fedObject.GetLabels())
+	if err != nil {
+		return qualifiedName, errors.Wrapf(err, "Failed to compute the target name of %s %q", fedObject.GetKind(), utils.NewQualifiedName(fedObject))
+	}
+	qualifiedName.Name = name
+	return qualifiedName, nil
+}
+
+// placementReport computes the placement of the given federated
+// resource as the sync controller does, apart from the stickiness of
+// placement, whose retained clusters are those reported in the status.
+func placementReport(client genericclient.Client, kubefedNamespace string, typeConfig, namespaceTypeConfig *fedv1b1.FederatedTypeConfig, fedObject *unstructured.Unstructured, clusters []*fedv1b1.KubeFedCluster) (*PlacementReport, error) {
+	var fedNamespace *unstructured.Unstructured
+	var selected sets.Set[string]
+	var err error
+	if typeConfig.GetNamespaced() {
+		if namespaceTypeConfig != nil {
+			fedNamespace, err = getFederatedResource(client, namespaceTypeConfig.GetFederatedType(), utils.QualifiedName{Namespace: fedObject.GetNamespace(), Name: fedObject.GetNamespace()})
+			if err != nil {
+				return nil, err
+			}
+		}
+		limitedScope, err := isLimitedScope(client, kubefedNamespace)
+		if err != nil {
+			return nil, err
+		}
+		if fedNamespace == nil && !limitedScope {
+			report := &PlacementReport{Selected: []string{}}
+			for _, cluster := range clusters {
+				report.Excluded = append(report.Excluded, ExcludedCluster{Name: cluster.Name, Reason: NamespaceNotFederated})
+			}
+			sortExcluded(report.Excluded)
+			return report, nil
+		}
+		selected, err = utils.ComputeNamespacedPlacement(fedObject, fedNamespace, clusters, limitedScope, false)
+	} else {
+		selected, err = utils.ComputePlacement(fedObject, clusters, false)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to compute placement")
+	}
+	retained, err := retainedClusterNames(fedObject)
+	if err != nil {
+		return nil, err
+	}
+	selected = selected.Union(retained)
+
+	exclusions, err := utils.PlacementExclusions(fedObject, fedNamespace, clusters, selected)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to explain placement")
+	}
+	report := &PlacementReport{Selected: sets.List(selected)}
+	for clusterName, reason := range exclusions {
+		report.Excluded = append(report.Excluded, ExcludedCluster{Name: clusterName, Reason: reason})
+	}
+	sortExcluded(report.Excluded)
+	return report, nil
+}
+
+func sortExcluded(excluded []ExcludedCluster) {
+	sort.Slice(excluded, func(i, j int) bool {
+		return excluded[i].Name < excluded[j].Name
+	})
+}
+
+// retainedClusterNames returns the names of the clusters reported in
+// the status of the given federated resource as retained by the
+// stickiness of its placement.
+func retainedClusterNames(fedObject *unstructured.Unstructured) (sets.Set[string], error) {
+	resource, err := status.DecodeGenericFederatedResource(fedObject)
+	if err != nil {
+		return nil, err
+	}
+	names := sets.New[string]()
+	if resource.Status == nil {
+		return names, nil
+	}
+	for _, cluster := range resource.Status.Clusters {
+		if len(cluster.RetainedUntil) > 0 {
+			names.Insert(cluster.Name)
+		}
+	}
+	return names, nil
+}
+
+// isLimitedScope returns whether the control plane in the given
+// namespace is limited to that namespace. A control plane without a
+// KubeFedConfig is assumed to be deployed cluster-wide.
+func isLimitedScope(client genericclient.Client, kubefedNamespace string) (bool, error) {
+	fedConfig := &fedv1b1.KubeFedConfig{}
+	err := client.Get(context.TODO(), fedConfig, kubefedNamespace, utils.KubeFedConfigName)
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrapf(err, "Error retrieving KubeFedConfig %q", utils.QualifiedName{Namespace: kubefedNamespace, Name: utils.KubeFedConfigName})
+	}
+	return fedConfig.Spec.Scope == apiextv1.NamespaceScoped, nil
+}
+
+// getPropagatedVersion returns the status of the propagated version
+// recorded for the named federated resource, or nil if none has been
+// recorded.
+func getPropagatedVersion(client genericclient.Client, typeConfig *fedv1b1.FederatedTypeConfig, qualifiedName utils.QualifiedName) (*fedv1a1.PropagatedVersionStatus, error) {
+	adapter := version.NewVersionAdapter(typeConfig.GetFederatedNamespaced())
+	versionName := utils.QualifiedName{
+		Namespace: qualifiedName.Namespace,
+		Name:      common.PropagatedVersionName(typeConfig.GetTargetType().Kind, qualifiedName.Name),
+	}
+	obj := adapter.NewObject()
+	err := client.Get(context.TODO(), obj, versionName.Namespace, versionName.Name)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to retrieve %s %q", adapter.TypeName(), versionName)
+	}
+	return adapter.GetStatus(obj), nil
+}
+
+func versionReport(fedObject *unstructured.Unstructured, propagatedVersion *fedv1a1.PropagatedVersionStatus) (*VersionReport, error) {
+	templateVersion, err := sync.GetTemplateHash(fedObject.Object)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to determine template version")
+	}
+	overrideVersion, err := sync.GetOverrideHash(fedObject)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to determine override version")
+	}
+	return &VersionReport{
+		TemplateVersion: propagatedVersion.TemplateVersion,
+		OverrideVersion: propagatedVersion.OverrideVersion,
+		Current:         templateVersion == propagatedVersion.TemplateVersion && overrideVersion == propagatedVersion.OverrideVersion,
+	}, nil
+}
+
+// getClusterObject returns the managed resource of the given target
+// name in the given cluster, or nil if it does not exist.
+func getClusterObject(clusterClient ClusterClientFunc, cluster *fedv1b1.KubeFedCluster, targetType metav1.APIResource, targetName utils.QualifiedName) (*unstructured.Unstructured, error) {
+	client, err := clusterClient(cluster)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to get a client for cluster %q", cluster.Name)
+	}
+	clusterName := utils.QualifiedNameForCluster(cluster.Name, targetName)
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   targetType.Group,
+		Version: targetType.Version,
+		Kind:    targetType.Kind,
+	})
+	err = client.Get(context.TODO(), obj, clusterName.Namespace, clusterName.Name)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to retrieve %s %q", targetType.Kind, clusterName)
+	}
+	return obj, nil
+}
@@ -0,0 +1,285 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package describe
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/kubefed/pkg/apis/core/common"
+	fedv1a1 "sigs.k8s.io/kubefed/pkg/apis/core/v1alpha1"
+	fedv1b1 "sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
+	genericclient "sigs.k8s.io/kubefed/pkg/client/generic"
+	"sigs.k8s.io/kubefed/pkg/controller/sync"
+	"sigs.k8s.io/kubefed/pkg/controller/sync/status"
+	"sigs.k8s.io/kubefed/pkg/controller/utils"
+	"sigs.k8s.io/kubefed/test/common/fake"
+)
+
+func newCluster(name string, ready bool, labels map[string]string) *fedv1b1.KubeFedCluster {
+	conditionStatus := corev1.ConditionTrue
+	if !ready {
+		conditionStatus = corev1.ConditionFalse
+	}
+	return &fedv1b1.KubeFedCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "kube-federation-system",
+			Name:      name,
+			Labels:    labels,
+		},
+		Status: fedv1b1.KubeFedClusterStatus{
+			Conditions: []fedv1b1.ClusterCondition{{Type: common.ClusterReady, Status: conditionStatus}},
+		},
+	}
+}
+
+func newConfigMap(namespace, name string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("v1")
+	obj.SetKind("ConfigMap")
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	obj.Object["data"] = map[string]interface{}{"key": "value"}
+	return obj
+}
+
+func TestBuildObjectReport(t *testing.T) {
+	ctx := context.Background()
+	hostStore := fake.NewStore()
+	client := fake.NewGenericClient(hostStore)
+	for _, obj := range []runtimeclient.Object{
+		newTypeConfig(common.NamespaceName, utils.NamespaceKind, apiextv1.ClusterScoped, apiextv1.NamespaceScoped),
+		newTypeConfig("configmaps", "ConfigMap", apiextv1.NamespaceScoped, apiextv1.NamespaceScoped),
+		newCluster("cluster1", true, map[string]string{"region": "east"}),
+		newCluster("cluster2", true, map[string]string{"region": "east"}),
+		newCluster("cluster3", false, map[string]string{"region": "east"}),
+		newCluster("cluster4", true, map[string]string{"region": "west"}),
+	} {
+		if err := client.Create(ctx, obj); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	fedNamespace := newFederatedResource("FederatedNamespace", "foo", "foo", map[string]string{"cluster1": ""})
+	if err := utils.SetClusterSelector(fedNamespace, map[string]string{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// The resource is current in cluster1, was modified out-of-band in
+	// cluster2, cannot be reached in cluster3 and remains reported for
+	// cluster5, which has been removed.
+	fedObject := newFederatedResource("FederatedConfigMap", "foo", "bar", map[string]string{
+		"cluster1": "",
+		"cluster2": string(status.UpdateFailed),
+		"cluster3": string(status.ClusterNotReady),
+		"cluster5": "",
+	})
+	if err := unstructured.SetNestedField(fedObject.Object, map[string]interface{}{"data": map[string]interface{}{"key": "value"}}, "spec", "template"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := utils.SetClusterSelector(fedObject, map[string]string{"region": "east"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	clusters, _, _ := unstructured.NestedSlice(fedObject.Object, "status", "clusters")
+	for _, rawCluster := range clusters {
+		cluster := rawCluster.(map[string]interface{})
+		if cluster["name"] == "cluster2" {
+			cluster["conditions"] = []interface{}{
+				map[string]interface{}{"type": string(status.DriftDetectedConditionType), "status": "True"},
+			}
+		}
+	}
+	if err := unstructured.SetNestedSlice(fedObject.Object, clusters, "status", "clusters"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := hostStore.Create(fedNamespace); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	createdFedObject, err := hostStore.Create(fedObject)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	clusterStores := map[string]*fake.Store{}
+	clusterVersions := map[string]string{}
+	for _, clusterName := range []string{"cluster1", "cluster2", "cluster3", "cluster4"} {
+		clusterStores[clusterName] = fake.NewStore()
+	}
+	for _, clusterName := range []string{"cluster1", "cluster2", "cluster3"} {
+		created, err := clusterStores[clusterName].Create(newConfigMap("foo", "bar"))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		clusterVersions[clusterName] = utils.ObjectVersion(created)
+	}
+	clusterVersions["cluster2"] = "rv:outdated"
+
+	templateVersion, err := sync.GetTemplateHash(fedObject.Object)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	overrideVersion, err := sync.GetOverrideHash(fedObject)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	propagatedVersion := &fedv1a1.PropagatedVersion{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "foo", Name: common.PropagatedVersionName("ConfigMap", "bar")},
+		Status: fedv1a1.PropagatedVersionStatus{
+			TemplateVersion: templateVersion,
+			OverrideVersion: overrideVersion,
+			ClusterVersions: []fedv1a1.ClusterObjectVersion{
+				{ClusterName: "cluster1", Version: clusterVersions["cluster1"]},
+				{ClusterName: "cluster2", Version: clusterVersions["cluster2"]},
+			},
+		},
+	}
+	if err := client.Create(ctx, propagatedVersion); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	clusterClient := func(cluster *fedv1b1.KubeFedCluster) (genericclient.Client, error) {
+		return fake.NewGenericClient(clusterStores[cluster.Name]), nil
+	}
+	gvk := schema.GroupVersionKind{Group: "types.kubefed.io", Version: "v1beta1", Kind: "FederatedConfigMap"}
+	report, err := BuildObjectReport(client, clusterClient, "kube-federation-system", gvk, utils.QualifiedName{Namespace: "foo", Name: "bar"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if report.Kind != "FederatedConfigMap" || report.TargetName != "foo/bar" {
+		t.Fatalf("Expected FederatedConfigMap with target %q, got %s with target %q", "foo/bar", report.Kind, report.TargetName)
+	}
+	expectedPlacement := PlacementReport{
+		Selected: []string{"cluster1", "cluster2", "cluster3"},
+		Excluded: []ExcludedCluster{{Name: "cluster4", Reason: utils.ClusterNotSelected}},
+	}
+	if !reflect.DeepEqual(expectedPlacement, report.Placement) {
+		t.Fatalf("Expected placement %v, got %v", expectedPlacement, report.Placement)
+	}
+	if report.Versions == nil || !report.Versions.Current {
+		t.Fatalf("Expected the recorded versions to be current, got %v", report.Versions)
+	}
+
+	exists, missing := true, false
+	expectedClusters := []ClusterReport{
+		{
+			Name: "cluster1", Joined: true, Ready: true, Status: "OK",
+			RecordedVersion: clusterVersions["cluster1"], ObservedVersion: clusterVersions["cluster1"], VersionMatches: true,
+			Exists: &exists,
+		},
+		{
+			Name: "cluster2", Joined: true, Ready: true, Status: string(status.UpdateFailed),
+			RecordedVersion: "rv:outdated", ObservedVersion: report.Clusters[1].ObservedVersion,
+			Exists:     &exists,
+			Conditions: []*status.GenericCondition{{Type: status.DriftDetectedConditionType, Status: corev1.ConditionTrue}},
+			Divergences: []Divergence{{
+				Type:    utils.VersionMismatch,
+				Message: report.Clusters[1].Divergences[0].Message,
+			}},
+		},
+		{Name: "cluster3", Joined: true, Status: string(status.ClusterNotReady)},
+		{Name: "cluster4", Joined: true, Ready: true, Exists: &missing},
+		{Name: "cluster5", Status: "OK"},
+	}
+	if !reflect.DeepEqual(expectedClusters, report.Clusters) {
+		actual, _ := json.MarshalIndent(report.Clusters, "", "  ")
+		t.Fatalf("Unexpected cluster reports:\n%s", actual)
+	}
+
+	// The report is machine-readable.
+	for _, format := range []string{OutputFormatJSON, OutputFormatYAML} {
+		var out bytes.Buffer
+		if err := WriteObjectReport(&out, report, format); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if format == OutputFormatJSON {
+			decoded := &ObjectReport{}
+			if err := json.Unmarshal(out.Bytes(), decoded); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(report.Placement, decoded.Placement) || len(decoded.Clusters) != len(report.Clusters) {
+				t.Fatalf("Expected the JSON report to decode to the report, got %v", decoded)
+			}
+		}
+	}
+	if err := WriteObjectReport(&bytes.Buffer{}, report, "table"); err == nil {
+		t.Fatalf("Expected an error for an unsupported format")
+	}
+
+	// Nothing is modified by describing the resource.
+	stored, err := hostStore.Get(fedObject.GroupVersionKind(), utils.NewQualifiedName(fedObject))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if stored.GetResourceVersion() != createdFedObject.GetResourceVersion() {
+		t.Fatalf("Expected the federated resource to be unchanged")
+	}
+}
+
+func TestBuildObjectReportForNamespace(t *testing.T) {
+	ctx := context.Background()
+	hostStore := fake.NewStore()
+	client := fake.NewGenericClient(hostStore)
+	for _, obj := range []runtimeclient.Object{
+		newTypeConfig(common.NamespaceName, utils.NamespaceKind, apiextv1.ClusterScoped, apiextv1.NamespaceScoped),
+		newCluster("cluster1", true, nil),
+	} {
+		if err := client.Create(ctx, obj); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	fedNamespace := newFederatedResource("FederatedNamespace", "foo", "foo", map[string]string{"cluster1": ""})
+	if err := utils.SetClusterNames(fedNamespace, []string{"cluster1"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := hostStore.Create(fedNamespace); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	clusterStore := fake.NewStore()
+	namespace := &unstructured.Unstructured{}
+	namespace.SetAPIVersion("v1")
+	namespace.SetKind(utils.NamespaceKind)
+	namespace.SetName("foo")
+	if _, err := clusterStore.Create(namespace); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	clusterClient := func(cluster *fedv1b1.KubeFedCluster) (genericclient.Client, error) {
+		return fake.NewGenericClient(clusterStore), nil
+	}
+
+	// The namespace is identified by its target type and name alone.
+	gvk := schema.GroupVersionKind{Version: "v1", Kind: utils.NamespaceKind}
+	report, err := BuildObjectReport(client, clusterClient, "kube-federation-system", gvk, utils.QualifiedName{Name: "foo"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if report.Namespace != "foo" || report.Name != "foo" || report.TargetName != "foo" {
+		t.Fatalf("Expected FederatedNamespace %q with target %q, got %q with target %q", "foo/foo", "foo", utils.QualifiedName{Namespace: report.Namespace, Name: report.Name}, report.TargetName)
+	}
+	if len(report.Clusters) != 1 || report.Clusters[0].Exists == nil || !*report.Clusters[0].Exists {
+		t.Fatalf("Expected the namespace to be found in cluster1, got %v", report.Clusters)
+	}
+}
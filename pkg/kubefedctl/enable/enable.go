@@ -398,6 +398,18 @@ func qualifiedAPIResourceName(resource metav1.APIResource) string {
 func federatedTypeCRD(typeConfig typeconfig.Interface, accessor schemaAccessor, shortNames []string) *apiextv1.CustomResourceDefinition {
 	templateSchema := accessor.templateSchema()
 	schema := federatedTypeValidationSchema(templateSchema)
+	if typeConfig.GetTargetType().Kind == ctlutil.NamespaceKind {
+		// Federated namespaces determine how their placement applies
+		// to the resources they contain.
+		specProperties := schema.OpenAPIV3Schema.Properties["spec"].Properties
+		specProperties[ctlutil.PlacementInheritanceField] = apiextv1.JSONSchemaProps{
+			Type: "string",
+			Enum: []apiextv1.JSON{
+				{Raw: []byte(`"Intersection"`)},
+				{Raw: []byte(`"Default"`)},
+			},
+		}
+	}
 	return CrdForAPIResource(typeConfig.GetFederatedType(), schema, shortNames)
 }
 
@@ -42,6 +42,14 @@ func federatedTypeValidationSchema(templateSchema map[string]v1.JSONSchemaProps)
 									"name": {
 										Type: "string",
 									},
+									"mode": {
+										Type: "string",
+										Enum: []v1.JSON{
+											{Raw: []byte(`"Propagate"`)},
+											{Raw: []byte(`"PlacementOnly"`)},
+											{Raw: []byte(`"Canary"`)},
+										},
+									},
 								},
 								Required: []string{
 									"name",
@@ -49,42 +57,74 @@ func federatedTypeValidationSchema(templateSchema map[string]v1.JSONSchemaProps)
 							},
 						},
 					},
-					"clusterSelector": {
+					"clusterSelector": labelSelectorSchema(),
+					// Clusters matching any of the selectors are
+					// selected in addition to the clusters otherwise
+					// selected.
+					"clusterSelectors": {
+						Type: "array",
+						Items: &v1.JSONSchemaPropsOrArray{
+							Schema: ptr.To(labelSelectorSchema()),
+						},
+					},
+					// The number of placed clusters that must be
+					// healthy for propagation to be considered
+					// successful. All placed clusters must be
+					// healthy if not set.
+					"minHealthyClusters": {
+						Type:    "integer",
+						Format:  "int32",
+						Minimum: ptr.To[float64](1),
+					},
+					// Limits placement to a number of the selected
+					// clusters, sampled by their capacity.
+					"sample": {
 						Type: "object",
 						Properties: map[string]v1.JSONSchemaProps{
-							"matchExpressions": {
-								Type: "array",
-								Items: &v1.JSONSchemaPropsOrArray{
-									Schema: &v1.JSONSchemaProps{
-										Type: "object",
-										Properties: map[string]v1.JSONSchemaProps{
-											"key": {
-												Type: "string",
-											},
-											"operator": {
-												Type: "string",
-											},
-											"values": {
-												Type: "array",
-												Items: &v1.JSONSchemaPropsOrArray{
-													Schema: &v1.JSONSchemaProps{
-														Type: "string",
-													},
-												},
-											},
-										},
-										Required: []string{
-											"key",
-											"operator",
-										},
-									},
-								},
+							"count": {
+								Type:    "integer",
+								Format:  "int32",
+								Minimum: ptr.To[float64](1),
 							},
-							"matchLabels": {
+						},
+						Required: []string{
+							"count",
+						},
+					},
+					// The number of seconds for which a cluster that
+					// is no longer selected remains placed.
+					"stickinessSeconds": {
+						Type:    "integer",
+						Format:  "int64",
+						Minimum: ptr.To[float64](1),
+					},
+					// Tolerations allow placement in clusters with
+					// matching taints.
+					"tolerations": {
+						Type: "array",
+						Items: &v1.JSONSchemaPropsOrArray{
+							Schema: &v1.JSONSchemaProps{
 								Type: "object",
-								AdditionalProperties: &v1.JSONSchemaPropsOrBool{
-									Schema: &v1.JSONSchemaProps{
+								Properties: map[string]v1.JSONSchemaProps{
+									"key": {
+										Type: "string",
+									},
+									"operator": {
 										Type: "string",
+										Enum: []v1.JSON{
+											{Raw: []byte(`"Equal"`)},
+											{Raw: []byte(`"Exists"`)},
+										},
+									},
+									"value": {
+										Type: "string",
+									},
+									"effect": {
+										Type: "string",
+										Enum: []v1.JSON{
+											{Raw: []byte(`"NoSchedule"`)},
+											{Raw: []byte(`"NoExecute"`)},
+										},
 									},
 								},
 							},
@@ -92,6 +132,28 @@ func federatedTypeValidationSchema(templateSchema map[string]v1.JSONSchemaProps)
 					},
 				},
 			},
+			// The number of seconds within which propagation must
+			// complete before the resource is reported as failed.
+			"propagationDeadlineSeconds": {
+				Type:    "integer",
+				Format:  "int64",
+				Minimum: ptr.To[float64](1),
+			},
+			// How the resources in clusters no longer selected by
+			// placement are drained before they are deleted,
+			// replacing the removal strategy of the type.
+			"removalStrategy": {
+				Type: "object",
+				Properties: map[string]v1.JSONSchemaProps{
+					"preDeletePatch": {
+						Type:                   "object",
+						XPreserveUnknownFields: ptr.To(true),
+					},
+					"drainDuration": {
+						Type: "string",
+					},
+				},
+			},
 			"overrides": {
 				Type: "array",
 				Items: &v1.JSONSchemaPropsOrArray{
@@ -107,6 +169,9 @@ func federatedTypeValidationSchema(templateSchema map[string]v1.JSONSchemaProps)
 									Schema: &v1.JSONSchemaProps{
 										Type: "object",
 										Properties: map[string]v1.JSONSchemaProps{
+											"clusterLocal": {
+												Type: "boolean",
+											},
 											"op": {
 												Type:    "string",
 												Pattern: "^(add|remove|replace)?$",
@@ -114,9 +179,39 @@ func federatedTypeValidationSchema(templateSchema map[string]v1.JSONSchemaProps)
 											"path": {
 												Type: "string",
 											},
+											"template": {
+												Type: "boolean",
+											},
 											"value": {
 												XPreserveUnknownFields: ptr.To(true),
 											},
+											"valueFrom": {
+												Type: "object",
+												Properties: map[string]v1.JSONSchemaProps{
+													"configMapKeyRef": {
+														Type: "object",
+														Properties: map[string]v1.JSONSchemaProps{
+															"name": {
+																Type: "string",
+															},
+															"key": {
+																Type: "string",
+															},
+														},
+													},
+													"secretKeyRef": {
+														Type: "object",
+														Properties: map[string]v1.JSONSchemaProps{
+															"name": {
+																Type: "string",
+															},
+															"key": {
+																Type: "string",
+															},
+														},
+													},
+												},
+											},
 										},
 										Required: []string{
 											"path",
@@ -152,6 +247,90 @@ func federatedTypeValidationSchema(templateSchema map[string]v1.JSONSchemaProps)
 	return schema
 }
 
+// labelSelectorSchema returns the schema of a label selector of
+// clusters.
+func labelSelectorSchema() v1.JSONSchemaProps {
+	return v1.JSONSchemaProps{
+		Type: "object",
+		Properties: map[string]v1.JSONSchemaProps{
+			"matchExpressions": {
+				Type: "array",
+				Items: &v1.JSONSchemaPropsOrArray{
+					Schema: &v1.JSONSchemaProps{
+						Type: "object",
+						Properties: map[string]v1.JSONSchemaProps{
+							"key": {
+								Type: "string",
+							},
+							"operator": {
+								Type: "string",
+							},
+							"values": {
+								Type: "array",
+								Items: &v1.JSONSchemaPropsOrArray{
+									Schema: &v1.JSONSchemaProps{
+										Type: "string",
+									},
+								},
+							},
+						},
+						Required: []string{
+							"key",
+							"operator",
+						},
+					},
+				},
+			},
+			"matchLabels": {
+				Type: "object",
+				AdditionalProperties: &v1.JSONSchemaPropsOrBool{
+					Schema: &v1.JSONSchemaProps{
+						Type: "string",
+					},
+				},
+			},
+		},
+	}
+}
+
+// conditionsSchema returns the schema of a list of status conditions.
+func conditionsSchema() v1.JSONSchemaProps {
+	return v1.JSONSchemaProps{
+		Type: "array",
+		Items: &v1.JSONSchemaPropsOrArray{
+			Schema: &v1.JSONSchemaProps{
+				Type: "object",
+				Properties: map[string]v1.JSONSchemaProps{
+					"type": {
+						Type: "string",
+					},
+					"status": {
+						Type: "string",
+					},
+					"reason": {
+						Type: "string",
+					},
+					"message": {
+						Type: "string",
+					},
+					"lastUpdateTime": {
+						Format: "date-time",
+						Type:   "string",
+					},
+					"lastTransitionTime": {
+						Format: "date-time",
+						Type:   "string",
+					},
+				},
+				Required: []string{
+					"type",
+					"status",
+				},
+			},
+		},
+	}
+}
+
 func ValidationSchema(specProps v1.JSONSchemaProps) *v1.CustomResourceValidation {
 	return &v1.CustomResourceValidation{
 		OpenAPIV3Schema: &v1.JSONSchemaProps{
@@ -171,44 +350,20 @@ func ValidationSchema(specProps v1.JSONSchemaProps) *v1.CustomResourceValidation
 				"status": {
 					Type: "object",
 					Properties: map[string]v1.JSONSchemaProps{
-						"conditions": {
+						"conditions": conditionsSchema(),
+						"clusters": {
 							Type: "array",
 							Items: &v1.JSONSchemaPropsOrArray{
 								Schema: &v1.JSONSchemaProps{
 									Type: "object",
 									Properties: map[string]v1.JSONSchemaProps{
-										"type": {
-											Type: "string",
-										},
-										"status": {
+										"name": {
 											Type: "string",
 										},
-										"reason": {
+										"applyResult": {
 											Type: "string",
 										},
-										"lastUpdateTime": {
-											Format: "date-time",
-											Type:   "string",
-										},
-										"lastTransitionTime": {
-											Format: "date-time",
-											Type:   "string",
-										},
-									},
-									Required: []string{
-										"type",
-										"status",
-									},
-								},
-							},
-						},
-						"clusters": {
-							Type: "array",
-							Items: &v1.JSONSchemaPropsOrArray{
-								Schema: &v1.JSONSchemaProps{
-									Type: "object",
-									Properties: map[string]v1.JSONSchemaProps{
-										"name": {
+										"applyError": {
 											Type: "string",
 										},
 										"status": {
@@ -218,6 +373,14 @@ func ValidationSchema(specProps v1.JSONSchemaProps) *v1.CustomResourceValidation
 											XPreserveUnknownFields: ptr.To(true),
 											Type:                   "object",
 										},
+										"readyEndpoints": {
+											Format: "int64",
+											Type:   "integer",
+										},
+										"retainedUntil": {
+											Type: "string",
+										},
+										"conditions": conditionsSchema(),
 									},
 									Required: []string{
 										"name",
@@ -229,6 +392,34 @@ func ValidationSchema(specProps v1.JSONSchemaProps) *v1.CustomResourceValidation
 							Format: "int64",
 							Type:   "integer",
 						},
+						"targetName": {
+							Type: "string",
+						},
+						"aggregatedStatus": {
+							XPreserveUnknownFields: ptr.To(true),
+							Type:                   "object",
+						},
+						"aggregatedMetrics": {
+							Type: "object",
+							AdditionalProperties: &v1.JSONSchemaPropsOrBool{
+								Schema: &v1.JSONSchemaProps{
+									Format: "int64",
+									Type:   "integer",
+								},
+							},
+						},
+						"unreportedMetricsClusters": {
+							Type: "array",
+							Items: &v1.JSONSchemaPropsOrArray{
+								Schema: &v1.JSONSchemaProps{
+									Type: "string",
+								},
+							},
+						},
+						"readyEndpoints": {
+							Format: "int64",
+							Type:   "integer",
+						},
 					},
 				},
 			},
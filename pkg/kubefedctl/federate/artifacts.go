@@ -0,0 +1,117 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package federate
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// KustomizationFileName is the name of the kustomization written
+	// alongside federated resources by WriteArtifactsToDir.
+	KustomizationFileName = "kustomization.yaml"
+
+	kustomizationAPIVersion = "kustomize.config.k8s.io/v1beta1"
+	kustomizationKind       = "Kustomization"
+)
+
+// WriteArtifactsToDir writes the federated resources of the given
+// artifacts to the given directory in a layout suitable for
+// kustomize. See WriteUnstructuredObjsToDir.
+func WriteArtifactsToDir(artifactsList []*Artifacts, dir string) error {
+	var federatedResources []*unstructured.Unstructured
+	for _, artifacts := range artifactsList {
+		federatedResources = append(federatedResources, artifacts.federatedResources...)
+	}
+	return WriteUnstructuredObjsToDir(federatedResources, dir)
+}
+
+// WriteUnstructuredObjsToDir writes each of the given objects to its
+// own file of the form <namespace>/<kind>-<name>.yaml under the given
+// directory, with cluster-scoped objects written to the directory
+// itself. A kustomization.yaml listing the written files in sorted
+// order is generated in the directory. No file is written if two of
+// the objects would be written to the same file.
+func WriteUnstructuredObjsToDir(unstructuredObjs []*unstructured.Unstructured, dir string) error {
+	resourcePaths := make([]string, 0, len(unstructuredObjs))
+	objsByPath := make(map[string]*unstructured.Unstructured, len(unstructuredObjs))
+	for _, obj := range unstructuredObjs {
+		resourcePath := artifactPath(obj)
+		if existing, ok := objsByPath[resourcePath]; ok {
+			return errors.Errorf("Both %s %q of %q and %s %q of %q would be written to %q",
+				existing.GetKind(), existing.GetName(), existing.GetAPIVersion(), obj.GetKind(), obj.GetName(), obj.GetAPIVersion(), resourcePath)
+		}
+		objsByPath[resourcePath] = obj
+		resourcePaths = append(resourcePaths, resourcePath)
+	}
+	for i, resourcePath := range resourcePaths {
+		if err := writeObjToFile(objsByPath[resourcePath], filepath.Join(dir, resourcePath)); err != nil {
+			return err
+		}
+		resourcePaths[i] = filepath.ToSlash(resourcePath)
+	}
+	sort.Strings(resourcePaths)
+
+	kustomization := map[string]interface{}{
+		"apiVersion": kustomizationAPIVersion,
+		"kind":       kustomizationKind,
+		"resources":  resourcePaths,
+	}
+	data, err := yaml.Marshal(kustomization)
+	if err != nil {
+		return errors.Wrap(err, "Error encoding kustomization to yaml")
+	}
+	kustomizationPath := filepath.Join(dir, KustomizationFileName)
+	if err := os.WriteFile(kustomizationPath, data, 0644); err != nil {
+		return errors.Wrapf(err, "Error writing %q", kustomizationPath)
+	}
+	return nil
+}
+
+// artifactPath returns the path relative to the output directory of
+// the file the given object is written to.
+func artifactPath(obj *unstructured.Unstructured) string {
+	fileName := fmt.Sprintf("%s-%s.yaml", strings.ToLower(obj.GetKind()), obj.GetName())
+	return filepath.Join(obj.GetNamespace(), fileName)
+}
+
+func writeObjToFile(obj *unstructured.Unstructured, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return errors.Wrapf(err, "Error creating directory for %q", path)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return errors.Wrapf(err, "Error creating %q", path)
+	}
+	if err := WriteUnstructuredObjsToYaml([]*unstructured.Unstructured{obj}, f); err != nil {
+		f.Close()
+		return errors.Wrapf(err, "Error writing %q", path)
+	}
+	if err := f.Close(); err != nil {
+		return errors.Wrapf(err, "Error closing %q", path)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package federate_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"sigs.k8s.io/kubefed/pkg/kubefedctl/federate"
+)
+
+func newFederatedObj(kind, namespace, name string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("types.kubefed.io/v1beta1")
+	obj.SetKind(kind)
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	return obj
+}
+
+func TestWriteUnstructuredObjsToDir(t *testing.T) {
+	dir := t.TempDir()
+	objs := []*unstructured.Unstructured{
+		newFederatedObj("FederatedNamespace", "foo", "foo"),
+		newFederatedObj("FederatedDeployment", "foo", "web"),
+		newFederatedObj("FederatedConfigMap", "bar", "config"),
+		newFederatedObj("FederatedClusterRole", "", "reader"),
+	}
+
+	err := federate.WriteUnstructuredObjsToDir(objs, dir)
+	require.NoError(t, err)
+
+	expectedPaths := map[string]*unstructured.Unstructured{
+		"foo/federatednamespace-foo.yaml":    objs[0],
+		"foo/federateddeployment-web.yaml":   objs[1],
+		"bar/federatedconfigmap-config.yaml": objs[2],
+		"federatedclusterrole-reader.yaml":   objs[3],
+	}
+	for path, expectedObj := range expectedPaths {
+		decoded, err := federate.DecodeUnstructuredFromFile(filepath.Join(dir, path))
+		require.NoError(t, err, "Expected %q to be readable", path)
+		require.Len(t, decoded, 1)
+		assert.Equal(t, expectedObj.GetKind(), decoded[0].GetKind())
+		assert.Equal(t, expectedObj.GetNamespace(), decoded[0].GetNamespace())
+		assert.Equal(t, expectedObj.GetName(), decoded[0].GetName())
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, federate.KustomizationFileName))
+	require.NoError(t, err)
+	expectedKustomization := `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+- bar/federatedconfigmap-config.yaml
+- federatedclusterrole-reader.yaml
+- foo/federateddeployment-web.yaml
+- foo/federatednamespace-foo.yaml
+`
+	assert.Equal(t, expectedKustomization, string(data))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	assert.Equal(t, []string{"bar", "federatedclusterrole-reader.yaml", "foo", federate.KustomizationFileName}, names)
+}
+
+func TestWriteUnstructuredObjsToDirRejectsSharedPath(t *testing.T) {
+	dir := t.TempDir()
+	otherGroupObj := newFederatedObj("FederatedDeployment", "foo", "web")
+	otherGroupObj.SetAPIVersion("example.io/v1")
+	objs := []*unstructured.Unstructured{
+		newFederatedObj("FederatedDeployment", "foo", "web"),
+		otherGroupObj,
+	}
+
+	err := federate.WriteUnstructuredObjsToDir(objs, dir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "foo/federateddeployment-web.yaml")
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "Expected no file to be written")
+}
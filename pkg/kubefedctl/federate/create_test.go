@@ -0,0 +1,255 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package federate
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
+
+	"sigs.k8s.io/kubefed/pkg/apis/core/typeconfig"
+	ctlutil "sigs.k8s.io/kubefed/pkg/controller/utils"
+)
+
+type fakeResourceClient struct {
+	client dynamic.Interface
+	gvr    schema.GroupVersionResource
+}
+
+func (c *fakeResourceClient) Resources(namespace string) dynamic.ResourceInterface {
+	return c.client.Resource(c.gvr).Namespace(namespace)
+}
+
+func (c *fakeResourceClient) Kind() string {
+	return "FederatedConfigMap"
+}
+
+func TestCreateFederatedResourcesIsIdempotent(t *testing.T) {
+	configMapResource := metav1.APIResource{Name: "configmaps", Version: "v1", Kind: "ConfigMap", Namespaced: true}
+	gvr := schema.GroupVersionResource{Group: "types.kubefed.io", Version: "v1beta1", Resource: "federatedconfigmaps"}
+
+	newArtifacts := func(value string) *Artifacts {
+		targetResources := []*unstructured.Unstructured{
+			newFixtureResource("v1", "ConfigMap", "my-ns", "a"),
+			newFixtureResource("v1", "ConfigMap", "my-ns", "b"),
+		}
+		for _, targetResource := range targetResources {
+			targetResource.Object["data"] = map[string]interface{}{"key": value}
+		}
+		return newFixtureArtifacts(t, configMapResource, true, targetResources...)
+	}
+
+	testCases := map[string]struct {
+		policy        AlreadyExistsPolicy
+		value         string
+		conflicts     int
+		expectedErr   bool
+		expectedValue string
+		expectUpdates int
+	}{
+		"unchanged resources are left unchanged when skipping": {
+			policy:        AlreadyExistsSkip,
+			value:         "v1",
+			expectedValue: "v1",
+		},
+		"changed resources are left unchanged when skipping": {
+			policy:        AlreadyExistsSkip,
+			value:         "v2",
+			expectedValue: "v1",
+		},
+		"unchanged resources are not updated when adopting": {
+			policy:        AlreadyExistsAdopt,
+			value:         "v1",
+			expectedValue: "v1",
+		},
+		"changed resources are updated when adopting": {
+			policy:        AlreadyExistsAdopt,
+			value:         "v2",
+			expectedValue: "v2",
+			expectUpdates: 2,
+		},
+		"conflicting updates are retried when adopting": {
+			policy:        AlreadyExistsAdopt,
+			value:         "v2",
+			conflicts:     2,
+			expectedValue: "v2",
+			expectUpdates: 4,
+		},
+		"existing resources are an error by default": {
+			value:         "v2",
+			expectedErr:   true,
+			expectedValue: "v1",
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			dynamicClient := fake.NewSimpleDynamicClient(runtime.NewScheme())
+			fedClient := &fakeResourceClient{client: dynamicClient, gvr: gvr}
+			federate := func(artifacts *Artifacts, opts CreateOptions) error {
+				for _, federatedResource := range artifacts.federatedResources {
+					_, err := createFederatedResource(fedClient, artifacts.typeConfig, federatedResource, opts)
+					if err != nil {
+						return err
+					}
+				}
+				return nil
+			}
+			require.NoError(t, federate(newArtifacts("v1"), CreateOptions{}))
+
+			// The sync controller adds a finalizer to a federated
+			// resource, and users may change its placement and
+			// overrides, which must all be retained.
+			existing, err := fedClient.Resources("my-ns").Get(context.Background(), "a", metav1.GetOptions{})
+			require.NoError(t, err)
+			existing.SetFinalizers([]string{"kubefed.io/sync-controller"})
+			placement := map[string]interface{}{"clusters": []interface{}{map[string]interface{}{"name": "cluster1"}}}
+			overrides := []interface{}{map[string]interface{}{"clusterName": "cluster1"}}
+			require.NoError(t, unstructured.SetNestedField(existing.Object, placement, "spec", "placement"))
+			require.NoError(t, unstructured.SetNestedSlice(existing.Object, overrides, "spec", "overrides"))
+			_, err = fedClient.Resources("my-ns").Update(context.Background(), existing, metav1.UpdateOptions{})
+			require.NoError(t, err)
+
+			updates := 0
+			conflicts := tc.conflicts
+			dynamicClient.PrependReactor("update", "*", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				updates++
+				if conflicts > 0 {
+					conflicts--
+					return true, nil, apierrors.NewConflict(gvr.GroupResource(), "a", nil)
+				}
+				return false, nil, nil
+			})
+
+			err = federate(newArtifacts(tc.value), CreateOptions{AlreadyExists: tc.policy, Attempts: tc.conflicts + 1})
+			if tc.expectedErr {
+				require.Error(t, err)
+				assert.True(t, apierrors.IsAlreadyExists(errors.Cause(err)), "Expected an AlreadyExists error, got %v", err)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectUpdates, updates)
+
+			for _, name := range []string{"a", "b"} {
+				federatedResource, err := fedClient.Resources("my-ns").Get(context.Background(), name, metav1.GetOptions{})
+				require.NoError(t, err)
+				value, _, err := unstructured.NestedString(federatedResource.Object, "spec", "template", "data", "key")
+				require.NoError(t, err)
+				assert.Equal(t, tc.expectedValue, value)
+			}
+			existing, err = fedClient.Resources("my-ns").Get(context.Background(), "a", metav1.GetOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, []string{"kubefed.io/sync-controller"}, existing.GetFinalizers())
+			assert.Equal(t, placement, existing.Object["spec"].(map[string]interface{})["placement"])
+			assert.Equal(t, overrides, existing.Object["spec"].(map[string]interface{})["overrides"])
+		})
+	}
+}
+
+func TestCreateResourcesContinuesOnError(t *testing.T) {
+	configMapResource := metav1.APIResource{Name: "configmaps", Version: "v1", Kind: "ConfigMap", Namespaced: true}
+	gvr := schema.GroupVersionResource{Group: "types.kubefed.io", Version: "v1beta1", Resource: "federatedconfigmaps"}
+
+	testCases := map[string]struct {
+		continueOnError  bool
+		expectedOutcomes map[string]CreateOutcome
+		expectedReport   string
+	}{
+		"creation stops at the first failure by default": {
+			expectedOutcomes: map[string]CreateOutcome{
+				"a":       CreateOutcomeCreated,
+				"invalid": CreateOutcomeFailed,
+			},
+		},
+		"creation continues past a failure": {
+			continueOnError: true,
+			expectedOutcomes: map[string]CreateOutcome{
+				"a":       CreateOutcomeCreated,
+				"invalid": CreateOutcomeFailed,
+				"c":       CreateOutcomeCreated,
+			},
+			expectedReport: `Created FederatedConfigMap "my-ns/a"
+Failed FederatedConfigMap "my-ns/invalid": Error creating federated resource "my-ns/invalid": FederatedConfigMap.types.kubefed.io "invalid" is invalid: spec.template: Invalid value: "": invalid template
+Created FederatedConfigMap "my-ns/c"
+2 created, 0 updated, 0 unchanged, 0 skipped, 1 failed
+`,
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			dynamicClient := fake.NewSimpleDynamicClient(runtime.NewScheme())
+			dynamicClient.PrependReactor("create", "*", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				obj := action.(clienttesting.CreateAction).GetObject().(*unstructured.Unstructured)
+				if obj.GetName() != "invalid" {
+					return false, nil, nil
+				}
+				return true, nil, apierrors.NewInvalid(schema.GroupKind{Group: gvr.Group, Kind: "FederatedConfigMap"}, obj.GetName(), field.ErrorList{
+					field.Invalid(field.NewPath("spec", "template"), "", "invalid template"),
+				})
+			})
+			fedClient := &fakeResourceClient{client: dynamicClient, gvr: gvr}
+			clientFunc := func(typeconfig.Interface) (ctlutil.ResourceClient, error) {
+				return fedClient, nil
+			}
+			artifacts := newFixtureArtifacts(t, configMapResource, true,
+				newFixtureResource("v1", "ConfigMap", "my-ns", "a"),
+				newFixtureResource("v1", "ConfigMap", "my-ns", "invalid"),
+				newFixtureResource("v1", "ConfigMap", "my-ns", "c"),
+			)
+
+			report, err := createArtifacts([]*Artifacts{artifacts}, nil, clientFunc, CreateOptions{ContinueOnError: tc.continueOnError})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "invalid template")
+
+			outcomes := make(map[string]CreateOutcome)
+			for _, result := range report.Results {
+				outcomes[result.Name.Name] = result.Outcome
+			}
+			assert.Equal(t, tc.expectedOutcomes, outcomes)
+			require.Len(t, report.Failed(), 1)
+			assert.Equal(t, "invalid", report.Failed()[0].Name.Name)
+
+			for _, name := range []string{"a", "c"} {
+				_, err := fedClient.Resources("my-ns").Get(context.Background(), name, metav1.GetOptions{})
+				if outcomes[name] == CreateOutcomeCreated {
+					assert.NoError(t, err, name)
+				} else {
+					assert.True(t, apierrors.IsNotFound(err), "Expected %q not to be created, got %v", name, err)
+				}
+			}
+
+			if len(tc.expectedReport) > 0 {
+				buf := &bytes.Buffer{}
+				require.NoError(t, WriteCreateReport(buf, report))
+				assert.Equal(t, tc.expectedReport, buf.String())
+			}
+		})
+	}
+}
@@ -25,12 +25,17 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/util/retry"
 	"k8s.io/klog/v2"
 
 	"sigs.k8s.io/kubefed/pkg/apis/core/typeconfig"
@@ -45,8 +50,64 @@ import (
 const (
 	createResourceRetryTimeout  = 10 * time.Second
 	createResourceRetryInterval = 1 * time.Second
+
+	// DefaultCreateAttempts is the default number of attempts to
+	// write a federated resource.
+	DefaultCreateAttempts = 5
+
+	// SkipFederationKey If a resource has this label or annotation
+	// with a value of "true", it will not be federated.
+	SkipFederationKey   = "core.kubefed.io/skip-federation"
+	SkipFederationValue = "true"
+)
+
+// AlreadyExistsPolicy determines how the creation of a federated
+// resource that already exists is handled.
+type AlreadyExistsPolicy string
+
+const (
+	// AlreadyExistsError fails the creation of the federated resource.
+	AlreadyExistsError AlreadyExistsPolicy = "error"
+	// AlreadyExistsSkip leaves the existing federated resource
+	// unchanged.
+	AlreadyExistsSkip AlreadyExistsPolicy = "skip"
+	// AlreadyExistsAdopt updates the template of the existing
+	// federated resource and adds the labels and annotations of the
+	// federated resource that would have been created. The placement,
+	// overrides and other metadata (e.g. the finalizers added by the
+	// sync controller) are retained.
+	AlreadyExistsAdopt AlreadyExistsPolicy = "adopt"
 )
 
+// CreateOptions configures the creation of federated resources.
+type CreateOptions struct {
+	DryRun bool
+	// AlreadyExists is the policy for a federated resource that
+	// already exists. Defaults to AlreadyExistsError.
+	AlreadyExists AlreadyExistsPolicy
+	// Attempts is the number of attempts to write a federated
+	// resource when a write fails with a conflict or a transient
+	// error. Defaults to DefaultCreateAttempts.
+	Attempts int
+	// ContinueOnError continues with the remaining federated
+	// resources when one fails to be created instead of stopping at
+	// the first failure.
+	ContinueOnError bool
+}
+
+func (o CreateOptions) backoff() wait.Backoff {
+	attempts := o.Attempts
+	if attempts < 1 {
+		attempts = DefaultCreateAttempts
+	}
+	return wait.Backoff{
+		Duration: 100 * time.Millisecond,
+		Factor:   2,
+		Jitter:   0.1,
+		Steps:    attempts,
+	}
+}
+
 var (
 	// Controller created resources should always be skipped while federating content
 	controllerCreatedAPIResourceNames = []string{
@@ -83,8 +144,14 @@ type federateResource struct {
 	outputYAML           bool
 	enableType           bool
 	federateContents     bool
+	remapOwnerRefs       bool
+	validate             bool
 	filename             string
+	outputDir            string
 	skipAPIResourceNames []string
+	alreadyExists        string
+	createAttempts       int
+	continueOnError      bool
 }
 
 func (j *federateResource) Bind(flags *pflag.FlagSet) {
@@ -92,7 +159,13 @@ func (j *federateResource) Bind(flags *pflag.FlagSet) {
 	flags.StringVarP(&j.output, "output", "o", "", "If provided, the resource that would be created in the API by the command is instead output to stdout in the provided format.  Valid format is ['yaml'].")
 	flags.BoolVarP(&j.enableType, "enable-type", "t", false, "If true, attempt to enable federation of the API type of the resource before creating the federated resource.")
 	flags.BoolVarP(&j.federateContents, "contents", "c", false, "Applicable only to namespaces. If provided, the command will federate all resources within the namespace after federating the namespace.")
+	flags.BoolVar(&j.validate, "validate", false, "Applicable only with '--contents'. If provided, the resources that would be created in the API by the command are instead validated with a server-side dry-run, and a report of the resources by kind and of any warnings and errors is output to stdout.")
+	flags.BoolVar(&j.remapOwnerRefs, "remap-owner-references", false, "Applicable only with '--contents'. If provided, owner references to resources that are also federated are recorded on the federated resources and resolved in each member cluster on propagation. Other owner references are dropped with a warning.")
 	flags.StringVarP(&j.filename, "filename", "f", "", "If specified, the provided yaml file will be used as the input for target resources to federate. This mode will only emit federated resource yaml to standard output. Other flag options if provided will be ignored.")
+	flags.StringVar(&j.outputDir, "output-dir", "", "If provided, the resources that would be created in the API by the command are instead written to the provided directory, one file per resource, along with a kustomization.yaml listing them.")
+	flags.StringVar(&j.alreadyExists, "already-exists", string(AlreadyExistsError), "The handling of a federated resource that already exists. Valid values are 'error' to fail, 'skip' to leave the existing resource unchanged and 'adopt' to update the existing resource from the target resource.")
+	flags.IntVar(&j.createAttempts, "create-attempts", DefaultCreateAttempts, "The number of attempts to write a federated resource when a write fails with a conflict or a transient error.")
+	flags.BoolVar(&j.continueOnError, "continue-on-error", false, "If provided, a failure to create a federated resource does not prevent the creation of the remaining ones, and a report of the outcome for each resource is output to stdout.")
 	flags.StringSliceVarP(&j.skipAPIResourceNames, "skip-api-resources", "s", []string{}, "Comma separated names of the api resources to skip when federating contents in a namespace. Name could be short name "+
 		"(e.g. 'deploy), kind (e.g. 'deployment'), plural name (e.g. 'deployments'), group qualified plural name (e.g. 'deployments.apps') or group name itself (e.g. 'apps') to skip the whole group.")
 }
@@ -105,6 +178,29 @@ func (j *federateResource) Complete(args []string) error {
 		return errors.Errorf("Invalid value for --output: %s", j.output)
 	}
 
+	switch AlreadyExistsPolicy(j.alreadyExists) {
+	case AlreadyExistsError, AlreadyExistsSkip, AlreadyExistsAdopt:
+	default:
+		return errors.Errorf("Invalid value for --already-exists: %s", j.alreadyExists)
+	}
+	if j.createAttempts < 1 {
+		return errors.Errorf("Invalid value for --create-attempts: %d", j.createAttempts)
+	}
+
+	if j.continueOnError && (j.outputYAML || len(j.outputDir) > 0) {
+		return errors.New("Flag '--continue-on-error' cannot be used with '--output [yaml]' or '--output-dir'")
+	}
+
+	if len(j.outputDir) > 0 {
+		if j.outputYAML {
+			return errors.New("Flag '--output-dir' cannot be used with '--output [yaml]'")
+		}
+		if j.enableType {
+			return errors.New("Flag '--enable-type' cannot be used with '--output-dir'")
+		}
+		j.outputYAML = true
+	}
+
 	if len(j.filename) > 0 {
 		if len(args) > 0 {
 			return errors.Errorf("Flag '--filename' does not take any args. Got args: %v", args)
@@ -126,6 +222,22 @@ func (j *federateResource) Complete(args []string) error {
 		return errors.New("Flag '--enable-type' cannot be used with '--output [yaml]'")
 	}
 
+	if j.remapOwnerRefs && !j.federateContents {
+		return errors.New("Flag '--remap-owner-references' can only be used with '--contents'")
+	}
+
+	if j.validate {
+		if !j.federateContents {
+			return errors.New("Flag '--validate' can only be used with '--contents'")
+		}
+		if j.enableType || j.outputYAML {
+			return errors.New("Flag '--validate' cannot be used with '--enable-type', '--output [yaml]' or '--output-dir'")
+		}
+		// Resources of types that are not enabled are validated
+		// against a generated type config.
+		j.outputYAML = true
+	}
+
 	return nil
 }
 
@@ -184,7 +296,11 @@ func (j *federateResource) Run(cmdOut io.Writer, config util.FedConfig) error {
 			return err
 		}
 
-		err = WriteUnstructuredObjsToYaml(federatedResources, cmdOut)
+		if len(j.outputDir) > 0 {
+			err = WriteUnstructuredObjsToDir(federatedResources, j.outputDir)
+		} else {
+			err = WriteUnstructuredObjsToYaml(federatedResources, cmdOut)
+		}
 		if err != nil {
 			return errors.Wrap(err, "Failed to write federated resources to YAML")
 		}
@@ -207,14 +323,36 @@ func (j *federateResource) Run(cmdOut io.Writer, config util.FedConfig) error {
 		return errors.New("Flag '--contents' can only be used with type 'namespaces'.")
 	}
 
+	if kind == ctlutil.NamespaceKind && j.validate {
+		report, err := ValidateNamespaceFederation(hostConfig, j.resourceName, j.KubeFedNamespace, j.skipAPIResourceNames)
+		if err != nil {
+			return err
+		}
+		if err := WriteNamespaceFederationReport(cmdOut, report); err != nil {
+			return errors.Wrap(err, "Failed to write validation report")
+		}
+		if !report.Valid() {
+			return errors.Errorf("Validation of the federation of namespace %q failed", j.resourceName)
+		}
+		return nil
+	}
+
 	if kind == ctlutil.NamespaceKind && j.federateContents {
-		containedArtifactsList, err := GetContainedArtifactsList(hostConfig, j.resourceName, j.KubeFedNamespace, j.skipAPIResourceNames, j.enableType, j.outputYAML)
+		containedArtifactsList, err := GetContainedArtifactsList(hostConfig, j.resourceName, j.KubeFedNamespace, j.skipAPIResourceNames, j.enableType, j.outputYAML, j.remapOwnerRefs)
 		if err != nil {
 			return err
 		}
 		artifactsList = append(artifactsList, containedArtifactsList...)
 	}
 
+	if len(j.outputDir) > 0 {
+		err = WriteArtifactsToDir(artifactsList, j.outputDir)
+		if err != nil {
+			return errors.Wrapf(err, "Failed to write federated resources to %q", j.outputDir)
+		}
+		return nil
+	}
+
 	if j.outputYAML {
 		for _, artifacts := range artifactsList {
 			err = WriteUnstructuredObjsToYaml(artifacts.federatedResources, cmdOut)
@@ -225,12 +363,30 @@ func (j *federateResource) Run(cmdOut io.Writer, config util.FedConfig) error {
 		return nil
 	}
 
-	return CreateResources(cmdOut, hostConfig, artifactsList, j.KubeFedNamespace, j.enableType, j.DryRun)
+	createOpts := CreateOptions{
+		DryRun:          j.DryRun,
+		AlreadyExists:   AlreadyExistsPolicy(j.alreadyExists),
+		Attempts:        j.createAttempts,
+		ContinueOnError: j.continueOnError,
+	}
+	if !j.continueOnError {
+		return CreateResources(cmdOut, hostConfig, artifactsList, j.KubeFedNamespace, j.enableType, createOpts)
+	}
+	report, err := CreateResourcesWithReport(cmdOut, hostConfig, artifactsList, j.KubeFedNamespace, j.enableType, createOpts)
+	if writeErr := WriteCreateReport(cmdOut, report); writeErr != nil {
+		return errors.Wrap(writeErr, "Failed to write creation report")
+	}
+	return err
 }
 
 func Resources(resources []*unstructured.Unstructured) ([]*unstructured.Unstructured, error) {
 	federatedResources := make([]*unstructured.Unstructured, 0, len(resources))
 	for _, targetResource := range resources {
+		if SkipFederation(targetResource) {
+			klog.Infof("Skipping %s %q because it opted out of federation", targetResource.GetKind(), ctlutil.NewQualifiedName(targetResource))
+			continue
+		}
+
 		// A Group, a Version and a Kind is sufficient for API Resource definition.
 		gvk := targetResource.GroupVersionKind()
 
@@ -432,33 +588,103 @@ func getNamespace(typeConfig typeconfig.Interface, qualifiedName ctlutil.Qualifi
 	return qualifiedName.Namespace
 }
 
-func CreateResources(cmdOut io.Writer, hostConfig *rest.Config, artifactsList []*Artifacts, namespace string, enableType, dryRun bool) error {
-	for _, artifacts := range artifactsList {
-		if enableType && !artifacts.typeConfigInstalled {
+// CreateResources creates the federated resources of the given
+// artifacts, enabling their types first if enableType is true. A
+// write that fails with a conflict or a transient error is retried,
+// and a federated resource that already exists is handled according
+// to the AlreadyExists policy so that federation can be re-run.
+func CreateResources(cmdOut io.Writer, hostConfig *rest.Config, artifactsList []*Artifacts, namespace string, enableType bool, opts CreateOptions) error {
+	_, err := CreateResourcesWithReport(cmdOut, hostConfig, artifactsList, namespace, enableType, opts)
+	return err
+}
+
+// CreateResourcesWithReport creates the federated resources of the
+// given artifacts like CreateResources, and returns a report of the
+// outcome for each federated resource. Creation stops at the first
+// failure unless ContinueOnError is set, in which case the failures
+// are aggregated in the returned error and the report records which
+// resources remain to be federated.
+func CreateResourcesWithReport(cmdOut io.Writer, hostConfig *rest.Config, artifactsList []*Artifacts, namespace string, enableType bool, opts CreateOptions) (*CreateReport, error) {
+	var enableTypeFunc func(typeconfig.Interface) error
+	if enableType {
+		enableTypeFunc = func(typeConfig typeconfig.Interface) error {
 			enableTypeDirective := enable.NewEnableTypeDirective()
-			enableTypeDirective.Name = artifacts.typeConfig.GetObjectMeta().Name
+			enableTypeDirective.Name = typeConfig.GetObjectMeta().Name
 			typeResources, err := enable.GetResources(hostConfig, enableTypeDirective)
 			if err != nil {
 				return err
 			}
-			err = enable.CreateResources(cmdOut, hostConfig, typeResources, namespace, dryRun)
-			if err != nil {
-				return err
-			}
+			return enable.CreateResources(cmdOut, hostConfig, typeResources, namespace, opts.DryRun)
 		}
+	}
+	clientFunc := func(typeConfig typeconfig.Interface) (ctlutil.ResourceClient, error) {
+		fedAPIResource := typeConfig.GetFederatedType()
+		fedClient, err := ctlutil.NewResourceClient(hostConfig, &fedAPIResource)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Error creating client for %s", fedAPIResource.Kind)
+		}
+		return fedClient, nil
+	}
+	return createArtifacts(artifactsList, enableTypeFunc, clientFunc, opts)
+}
 
-		err := CreateFederatedResources(hostConfig, artifacts.typeConfig, artifacts.federatedResources, dryRun)
+// createArtifacts creates the federated resources of the given
+// artifacts with the clients returned by clientFunc, first enabling
+// the types that are not installed with enableTypeFunc unless it is
+// nil.
+func createArtifacts(artifactsList []*Artifacts, enableTypeFunc func(typeconfig.Interface) error, clientFunc func(typeconfig.Interface) (ctlutil.ResourceClient, error), opts CreateOptions) (*CreateReport, error) {
+	report := &CreateReport{}
+	var errs []error
+	for _, artifacts := range artifactsList {
+		typeConfig := artifacts.typeConfig
+		var err error
+		if enableTypeFunc != nil && !artifacts.typeConfigInstalled {
+			err = enableTypeFunc(typeConfig)
+		}
+		var fedClient ctlutil.ResourceClient
+		if err == nil && len(artifacts.federatedResources) > 0 {
+			fedClient, err = clientFunc(typeConfig)
+		}
 		if err != nil {
-			return err
+			if !opts.ContinueOnError {
+				return report, err
+			}
+			// None of the federated resources of the type can be
+			// created.
+			for _, federatedResource := range artifacts.federatedResources {
+				report.record(typeConfig, federatedResource, CreateOutcomeFailed, err)
+			}
+			errs = append(errs, err)
+			continue
+		}
+
+		for _, federatedResource := range artifacts.federatedResources {
+			outcome, err := createFederatedResource(fedClient, typeConfig, federatedResource, opts)
+			report.record(typeConfig, federatedResource, outcome, err)
+			if err == nil {
+				continue
+			}
+			if !opts.ContinueOnError {
+				return report, err
+			}
+			errs = append(errs, err)
 		}
 	}
 
-	return nil
+	return report, utilerrors.NewAggregate(errs)
 }
 
-func CreateFederatedResources(hostConfig *rest.Config, typeConfig typeconfig.Interface, federatedResources []*unstructured.Unstructured, dryRun bool) error {
+func CreateFederatedResources(hostConfig *rest.Config, typeConfig typeconfig.Interface, federatedResources []*unstructured.Unstructured, opts CreateOptions) error {
+	if len(federatedResources) == 0 {
+		return nil
+	}
+	fedAPIResource := typeConfig.GetFederatedType()
+	fedClient, err := ctlutil.NewResourceClient(hostConfig, &fedAPIResource)
+	if err != nil {
+		return errors.Wrapf(err, "Error creating client for %s", fedAPIResource.Kind)
+	}
 	for _, federatedResource := range federatedResources {
-		err := CreateFederatedResource(hostConfig, typeConfig, federatedResource, dryRun)
+		_, err := createFederatedResource(fedClient, typeConfig, federatedResource, opts)
 		if err != nil {
 			return err
 		}
@@ -467,49 +693,149 @@ func CreateFederatedResources(hostConfig *rest.Config, typeConfig typeconfig.Int
 	return nil
 }
 
-func CreateFederatedResource(hostConfig *rest.Config, typeConfig typeconfig.Interface, federatedResource *unstructured.Unstructured, dryRun bool) error {
+func CreateFederatedResource(hostConfig *rest.Config, typeConfig typeconfig.Interface, federatedResource *unstructured.Unstructured, opts CreateOptions) error {
+	return CreateFederatedResources(hostConfig, typeConfig, []*unstructured.Unstructured{federatedResource}, opts)
+}
+
+func createFederatedResource(fedClient ctlutil.ResourceClient, typeConfig typeconfig.Interface, federatedResource *unstructured.Unstructured, opts CreateOptions) (CreateOutcome, error) {
 	if typeConfig.GetTargetType().Kind == ctlutil.NamespaceKind {
 		// TODO: irfanurrehman: Can a target namespace be federated into another namespace?
 		klog.Infof("Resource to federate is a namespace. Given namespace will itself be the container for the federated namespace")
 	}
 
-	fedAPIResource := typeConfig.GetFederatedType()
-	fedKind := fedAPIResource.Kind
-	fedClient, err := ctlutil.NewResourceClient(hostConfig, &fedAPIResource)
+	fedKind := typeConfig.GetFederatedType().Kind
+	qualifiedFedName := ctlutil.NewQualifiedName(federatedResource)
+	if opts.DryRun {
+		klog.Infof("Successfully created %s %q from %s", fedKind, qualifiedFedName, typeConfig.GetTargetType().Kind)
+		return CreateOutcomeCreated, nil
+	}
+
+	client := fedClient.Resources(federatedResource.GetNamespace())
+	var outcome CreateOutcome
+	// It might take a little while for the federated type to appear if the
+	// same is being enabled while or immediately before federating the resource.
+	err := wait.PollUntilContextTimeout(context.Background(), createResourceRetryInterval, createResourceRetryTimeout, true, func(ctx context.Context) (done bool, err error) {
+		err = retry.OnError(opts.backoff(), isRetriableWriteError, func() error {
+			var err error
+			outcome, err = writeFederatedResource(ctx, client, federatedResource, opts.AlreadyExists)
+			return err
+		})
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		return true, nil
+	})
 	if err != nil {
-		return errors.Wrapf(err, "Error creating client for %s", fedKind)
+		return CreateOutcomeFailed, errors.Wrapf(err, "Error creating federated resource %q", qualifiedFedName)
 	}
 
-	qualifiedFedName := ctlutil.NewQualifiedName(federatedResource)
-	if !dryRun {
-		// It might take a little while for the federated type to appear if the
-		// same is being enabled while or immediately before federating the resource.
-		err = wait.PollUntilContextTimeout(context.Background(), createResourceRetryInterval, createResourceRetryTimeout, true, func(ctx context.Context) (done bool, err error) {
-			_, err = fedClient.Resources(federatedResource.GetNamespace()).Create(context.Background(), federatedResource, metav1.CreateOptions{})
-			if apierrors.IsNotFound(err) {
-				return false, nil
-			}
-			if err != nil {
-				return false, err
-			}
-			return true, nil
-		})
+	klog.Infof("%s %s %q from %s", outcome.description(), fedKind, qualifiedFedName, typeConfig.GetTargetType().Kind)
+	return outcome, nil
+}
+
+// isRetriableWriteError indicates whether a write that failed with the
+// given error may succeed if attempted again.
+func isRetriableWriteError(err error) bool {
+	return apierrors.IsConflict(err) ||
+		apierrors.IsServerTimeout(err) ||
+		apierrors.IsTimeout(err) ||
+		apierrors.IsTooManyRequests(err)
+}
+
+// writeFederatedResource creates the given federated resource or
+// handles an existing federated resource according to the given
+// policy, and returns the outcome.
+func writeFederatedResource(ctx context.Context, client dynamic.ResourceInterface, federatedResource *unstructured.Unstructured, policy AlreadyExistsPolicy) (CreateOutcome, error) {
+	_, err := client.Create(ctx, federatedResource, metav1.CreateOptions{})
+	if err == nil {
+		return CreateOutcomeCreated, nil
+	}
+	if !apierrors.IsAlreadyExists(err) {
+		return CreateOutcomeFailed, err
+	}
+	switch policy {
+	case AlreadyExistsSkip:
+		return CreateOutcomeSkipped, nil
+	case AlreadyExistsAdopt:
+		existing, err := client.Get(ctx, federatedResource.GetName(), metav1.GetOptions{})
 		if err != nil {
-			return errors.Wrapf(err, "Error creating federated resource %q", qualifiedFedName)
+			return CreateOutcomeFailed, err
+		}
+		if !adoptFederatedResource(existing, federatedResource) {
+			return CreateOutcomeUnchanged, nil
 		}
+		_, err = client.Update(ctx, existing, metav1.UpdateOptions{})
+		if err != nil {
+			return CreateOutcomeFailed, err
+		}
+		return CreateOutcomeUpdated, nil
+	default:
+		return CreateOutcomeFailed, err
 	}
+}
 
-	klog.Infof("Successfully created %s %q from %s", fedKind, qualifiedFedName, typeConfig.GetTargetType().Kind)
-	return nil
+// adoptFederatedResource updates the existing federated resource with
+// the template, labels and annotations of the desired federated
+// resource, and returns whether the existing resource was changed. The
+// placement, overrides and other fields of the existing spec are
+// retained since they may have been changed after the resource was
+// created.
+func adoptFederatedResource(existing, desired *unstructured.Unstructured) bool {
+	changed := false
+	templatePath := []string{ctlutil.SpecField, ctlutil.TemplateField}
+	template, _, _ := unstructured.NestedFieldNoCopy(desired.Object, templatePath...)
+	existingTemplate, _, _ := unstructured.NestedFieldNoCopy(existing.Object, templatePath...)
+	if !equality.Semantic.DeepEqual(existingTemplate, template) {
+		if err := unstructured.SetNestedField(existing.Object, runtime.DeepCopyJSONValue(template), templatePath...); err != nil {
+			// The spec of the existing resource is not an object, so
+			// it is replaced.
+			existing.Object[ctlutil.SpecField] = runtime.DeepCopyJSONValue(desired.Object[ctlutil.SpecField])
+		}
+		changed = true
+	}
+	labels, labelsChanged := mergeStringMaps(existing.GetLabels(), desired.GetLabels())
+	if labelsChanged {
+		existing.SetLabels(labels)
+		changed = true
+	}
+	annotations, annotationsChanged := mergeStringMaps(existing.GetAnnotations(), desired.GetAnnotations())
+	if annotationsChanged {
+		existing.SetAnnotations(annotations)
+		changed = true
+	}
+	return changed
+}
+
+func mergeStringMaps(existing, desired map[string]string) (map[string]string, bool) {
+	changed := false
+	for key, value := range desired {
+		if existingValue, ok := existing[key]; ok && existingValue == value {
+			continue
+		}
+		if existing == nil {
+			existing = make(map[string]string)
+		}
+		existing[key] = value
+		changed = true
+	}
+	return existing, changed
 }
 
-func GetContainedArtifactsList(hostConfig *rest.Config, containerNamespace, kubefedNamespace string, skipAPIResourceNames []string, enableType, outputYAML bool) ([]*Artifacts, error) {
+// GetContainedArtifactsList returns the artifacts for federating the
+// resources contained in the given namespace. If remapOwnerReferences
+// is true, owner references between the contained resources are
+// recorded on their federated resources. See RemapOwnerReferences.
+func GetContainedArtifactsList(hostConfig *rest.Config, containerNamespace, kubefedNamespace string, skipAPIResourceNames []string, enableType, outputYAML, remapOwnerReferences bool) ([]*Artifacts, error) {
 	targetResourcesList, err := getResourcesInNamespace(hostConfig, containerNamespace, skipAPIResourceNames)
 	if err != nil {
 		return nil, err
 	}
 
 	var artifactsList []*Artifacts
+	var allTargetResources, allFederatedResources []*unstructured.Unstructured
 	for _, targetResources := range targetResourcesList {
 		apiResource := targetResources.apiResource
 		typeConfigInstalled, typeConfig, err := getTypeConfig(hostConfig, apiResource, kubefedNamespace, enableType, outputYAML)
@@ -524,6 +850,8 @@ func GetContainedArtifactsList(hostConfig *rest.Config, containerNamespace, kube
 			}
 
 			federatedResources = append(federatedResources, federatedResource)
+			allTargetResources = append(allTargetResources, targetResource)
+			allFederatedResources = append(allFederatedResources, federatedResource)
 		}
 		federateArtifacts := Artifacts{
 			typeConfigInstalled: typeConfigInstalled,
@@ -533,6 +861,12 @@ func GetContainedArtifactsList(hostConfig *rest.Config, containerNamespace, kube
 		artifactsList = append(artifactsList, &federateArtifacts)
 	}
 
+	if remapOwnerReferences {
+		if err := RemapOwnerReferences(allTargetResources, allFederatedResources); err != nil {
+			return nil, err
+		}
+	}
+
 	return artifactsList, nil
 }
 
@@ -20,9 +20,12 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 
+	ctlutil "sigs.k8s.io/kubefed/pkg/controller/utils"
 	"sigs.k8s.io/kubefed/pkg/kubefedctl/federate"
 )
 
@@ -79,3 +82,84 @@ func TestFederateResources(t *testing.T) {
 		assert.Equal(t, resource.Object["spec"], federatedSpec)
 	})
 }
+
+func TestFederateResourcesSkipFederation(t *testing.T) {
+	newConfigMap := func(name string) *unstructured.Unstructured {
+		resource := &unstructured.Unstructured{}
+		resource.SetGroupVersionKind(schema.GroupVersionKind{
+			Version: "v1",
+			Kind:    "ConfigMap",
+		})
+		resource.SetNamespace("testNS")
+		resource.SetName(name)
+		return resource
+	}
+
+	federated := newConfigMap("federated")
+	labeled := newConfigMap("labeled")
+	labeled.SetLabels(map[string]string{federate.SkipFederationKey: federate.SkipFederationValue})
+	annotated := newConfigMap("annotated")
+	annotated.SetAnnotations(map[string]string{federate.SkipFederationKey: federate.SkipFederationValue})
+	notSkipped := newConfigMap("not-skipped")
+	notSkipped.SetLabels(map[string]string{federate.SkipFederationKey: "false"})
+
+	federatedResources, err := federate.Resources([]*unstructured.Unstructured{federated, labeled, annotated, notSkipped})
+	assert.NoError(t, err, "Should not expect any error")
+	assert.Len(t, federatedResources, 2, "Should not federate resources that opted out of federation")
+	assert.Equal(t, "federated", federatedResources[0].GetName())
+	assert.Equal(t, "not-skipped", federatedResources[1].GetName())
+}
+
+func TestRemapOwnerReferences(t *testing.T) {
+	newResource := func(apiVersion, kind, name, uid string, ownerReferences ...metav1.OwnerReference) *unstructured.Unstructured {
+		resource := &unstructured.Unstructured{}
+		resource.SetAPIVersion(apiVersion)
+		resource.SetKind(kind)
+		resource.SetNamespace("testNS")
+		resource.SetName(name)
+		resource.SetUID(types.UID(uid))
+		resource.SetOwnerReferences(ownerReferences)
+		return resource
+	}
+	controller := true
+	deployment := newResource("apps/v1", "Deployment", "app", "deployment-uid")
+	hpa := newResource("autoscaling/v2", "HorizontalPodAutoscaler", "app", "hpa-uid", metav1.OwnerReference{
+		APIVersion: "apps/v1",
+		Kind:       "Deployment",
+		Name:       "app",
+		UID:        "deployment-uid",
+		Controller: &controller,
+	})
+	// The owner of the config map is not federated.
+	configMap := newResource("v1", "ConfigMap", "config", "configmap-uid", metav1.OwnerReference{
+		APIVersion: "example.io/v1",
+		Kind:       "Operator",
+		Name:       "operator",
+		UID:        "operator-uid",
+	})
+
+	targetResources := []*unstructured.Unstructured{deployment, hpa, configMap}
+	federatedResources, err := federate.Resources(targetResources)
+	assert.NoError(t, err, "Should not expect any error")
+	for _, federatedResource := range federatedResources {
+		_, ok, _ := unstructured.NestedFieldNoCopy(federatedResource.Object, "spec", "template", "metadata", "ownerReferences")
+		assert.False(t, ok, "Owner references should not appear in the template of a federated resource")
+	}
+
+	err = federate.RemapOwnerReferences(targetResources, federatedResources)
+	assert.NoError(t, err, "Should not expect any error")
+
+	ownerReferences, err := ctlutil.GetFederatedOwnerReferences(federatedResources[0])
+	assert.NoError(t, err, "Should not expect any error")
+	assert.Empty(t, ownerReferences, "An unowned resource should not have owner references")
+
+	ownerReferences, err = ctlutil.GetFederatedOwnerReferences(federatedResources[1])
+	assert.NoError(t, err, "Should not expect any error")
+	assert.Equal(t, []ctlutil.FederatedOwnerReference{
+		{APIVersion: "apps/v1", Kind: "Deployment", Name: "app", Controller: &controller},
+	}, ownerReferences, "An owner that is federated should be remapped")
+
+	ownerReferences, err = ctlutil.GetFederatedOwnerReferences(federatedResources[2])
+	assert.NoError(t, err, "Should not expect any error")
+	assert.Empty(t, ownerReferences, "An owner that is not federated should be dropped")
+}
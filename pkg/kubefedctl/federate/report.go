@@ -0,0 +1,121 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package federate
+
+import (
+	"fmt"
+	"io"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"sigs.k8s.io/kubefed/pkg/apis/core/typeconfig"
+	ctlutil "sigs.k8s.io/kubefed/pkg/controller/utils"
+)
+
+// CreateOutcome is the outcome of the creation of a federated
+// resource.
+type CreateOutcome string
+
+const (
+	CreateOutcomeCreated CreateOutcome = "Created"
+	// CreateOutcomeUpdated indicates that an existing federated
+	// resource was adopted.
+	CreateOutcomeUpdated CreateOutcome = "Updated"
+	// CreateOutcomeUnchanged indicates that an existing federated
+	// resource was adopted without requiring changes.
+	CreateOutcomeUnchanged CreateOutcome = "Unchanged"
+	// CreateOutcomeSkipped indicates that an existing federated
+	// resource was left unchanged.
+	CreateOutcomeSkipped CreateOutcome = "Skipped"
+	CreateOutcomeFailed  CreateOutcome = "Failed"
+)
+
+// description returns a description of the outcome for logging.
+func (o CreateOutcome) description() string {
+	switch o {
+	case CreateOutcomeCreated:
+		return "Successfully created"
+	case CreateOutcomeUpdated:
+		return "Successfully updated"
+	case CreateOutcomeUnchanged:
+		return "Found up-to-date"
+	case CreateOutcomeSkipped:
+		return "Skipped existing"
+	default:
+		return "Failed to create"
+	}
+}
+
+// CreateResult is the outcome of the creation of a single federated
+// resource.
+type CreateResult struct {
+	Kind    string
+	Name    ctlutil.QualifiedName
+	Outcome CreateOutcome
+	// Error is the cause of a failure.
+	Error error
+}
+
+// CreateReport records the outcome of the creation of each federated
+// resource, in the order in which creation was attempted. Resources
+// whose creation was not attempted are not recorded.
+type CreateReport struct {
+	Results []CreateResult
+}
+
+// Failed returns the results of the federated resources that failed
+// to be created.
+func (r *CreateReport) Failed() []CreateResult {
+	var failed []CreateResult
+	for _, result := range r.Results {
+		if result.Outcome == CreateOutcomeFailed {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+func (r *CreateReport) record(typeConfig typeconfig.Interface, federatedResource *unstructured.Unstructured, outcome CreateOutcome, err error) {
+	r.Results = append(r.Results, CreateResult{
+		Kind:    typeConfig.GetFederatedType().Kind,
+		Name:    ctlutil.NewQualifiedName(federatedResource),
+		Outcome: outcome,
+		Error:   err,
+	})
+}
+
+// WriteCreateReport writes a human-readable form of the given report,
+// listing the outcome for each federated resource followed by the
+// number of federated resources by outcome.
+func WriteCreateReport(w io.Writer, report *CreateReport) error {
+	counts := make(map[CreateOutcome]int)
+	for _, result := range report.Results {
+		counts[result.Outcome]++
+		var err error
+		if result.Error != nil {
+			_, err = fmt.Fprintf(w, "%s %s %q: %v\n", result.Outcome, result.Kind, result.Name, result.Error)
+		} else {
+			_, err = fmt.Fprintf(w, "%s %s %q\n", result.Outcome, result.Kind, result.Name)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "%d created, %d updated, %d unchanged, %d skipped, %d failed\n",
+		counts[CreateOutcomeCreated], counts[CreateOutcomeUpdated], counts[CreateOutcomeUnchanged], counts[CreateOutcomeSkipped], counts[CreateOutcomeFailed])
+	return err
+}
@@ -21,6 +21,8 @@ import (
 	"context"
 	"io"
 	"os"
+	"reflect"
+	"strings"
 
 	"github.com/pkg/errors"
 
@@ -28,6 +30,8 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation"
 	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
 	versionhelper "k8s.io/apimachinery/pkg/version"
@@ -41,7 +45,12 @@ import (
 	"sigs.k8s.io/kubefed/pkg/kubefedctl/util"
 )
 
-func RemoveUnwantedFields(resource *unstructured.Unstructured) error {
+// RemoveUnwantedFields removes the fields of the given resource that
+// are not part of the template of a federated resource. The fields at
+// the given dot-separated paths are also removed, which allows fields
+// whose values are not deterministic to be excluded when comparing a
+// template with its target resource.
+func RemoveUnwantedFields(resource *unstructured.Unstructured, ignorePaths ...string) error {
 	unstructured.RemoveNestedField(resource.Object, "apiVersion")
 	unstructured.RemoveNestedField(resource.Object, "kind")
 	unstructured.RemoveNestedField(resource.Object, "status")
@@ -61,6 +70,65 @@ func RemoveUnwantedFields(resource *unstructured.Unstructured) error {
 		}
 	}
 
+	for _, path := range ignorePaths {
+		unstructured.RemoveNestedField(resource.Object, strings.Split(path, ".")...)
+	}
+
+	return nil
+}
+
+// TemplateEqual returns whether the template of the given federated
+// resource is equal to the given target resource, ignoring the fields
+// that are not part of a template and those at the given dot-separated
+// paths. Neither resource is modified.
+func TemplateEqual(fedResource, targetResource *unstructured.Unstructured, ignorePaths ...string) (bool, error) {
+	template, ok, err := unstructured.NestedMap(fedResource.Object, ctlutil.SpecField, ctlutil.TemplateField)
+	if err != nil {
+		return false, errors.Wrap(err, "Failed to retrieve template")
+	}
+	if !ok {
+		return false, errors.New("Template is not present")
+	}
+	expectedResource := &unstructured.Unstructured{Object: template}
+	if err := RemoveUnwantedFields(expectedResource, ignorePaths...); err != nil {
+		return false, errors.Wrap(err, "Failed to remove unwanted fields from template")
+	}
+	actualResource := targetResource.DeepCopy()
+	if err := RemoveUnwantedFields(actualResource, ignorePaths...); err != nil {
+		return false, errors.Wrap(err, "Failed to remove unwanted fields from target resource")
+	}
+	return reflect.DeepEqual(expectedResource, actualResource), nil
+}
+
+// RemapOwnerReferences records on each of the given federated
+// resources the owner references of its target resource, which is at
+// the same index of targetResources. Only an owner that is one of the
+// target resources can be remapped, since the owner must be federated
+// along with the resources it owns to exist in member clusters. Such
+// an owner is recorded by name and resolved in each member cluster on
+// propagation. Other owner references, e.g. to a cluster-scoped owner
+// or to a resource that is not federated, are dropped with a warning.
+func RemapOwnerReferences(targetResources, federatedResources []*unstructured.Unstructured) error {
+	if len(targetResources) != len(federatedResources) {
+		return errors.Errorf("Expected %d federated resources, got %d", len(targetResources), len(federatedResources))
+	}
+	federatedUIDs := sets.New[types.UID]()
+	for _, targetResource := range targetResources {
+		federatedUIDs.Insert(targetResource.GetUID())
+	}
+	for i, targetResource := range targetResources {
+		var ownerReferences []ctlutil.FederatedOwnerReference
+		for _, ownerReference := range targetResource.GetOwnerReferences() {
+			if !federatedUIDs.Has(ownerReference.UID) {
+				klog.Warningf("Dropping the owner reference of %s %q to %s %q since the owner is not federated", targetResource.GetKind(), ctlutil.NewQualifiedName(targetResource), ownerReference.Kind, ownerReference.Name)
+				continue
+			}
+			ownerReferences = append(ownerReferences, ctlutil.NewFederatedOwnerReference(ownerReference))
+		}
+		if err := ctlutil.SetFederatedOwnerReferences(federatedResources[i], ownerReferences); err != nil {
+			return errors.Wrapf(err, "Failed to record owner references for %s %q", targetResource.GetKind(), ctlutil.NewQualifiedName(targetResource))
+		}
+	}
 	return nil
 }
 
@@ -77,6 +145,13 @@ func SetBasicMetaFields(resource *unstructured.Unstructured, apiResource metav1.
 	}
 }
 
+// SkipFederation checks whether the given resource has opted out of
+// federation with the skip-federation label or annotation.
+func SkipFederation(resource *unstructured.Unstructured) bool {
+	return resource.GetLabels()[SkipFederationKey] == SkipFederationValue ||
+		resource.GetAnnotations()[SkipFederationKey] == SkipFederationValue
+}
+
 func namespacedAPIResourceMap(config *rest.Config, skipAPIResourceNames []string) (map[string]metav1.APIResource, error) {
 	apiResourceLists, err := enable.GetServerPreferredResources(config)
 	if err != nil {
@@ -208,6 +283,10 @@ func getResourcesInNamespace(config *rest.Config, namespace string, skipAPIResou
 		targetResources := resources{apiResource: apiResource}
 		for _, item := range resourceList.Items {
 			resource := item
+			if SkipFederation(&resource) {
+				klog.Infof("Skipping resource %s of type %s because it opted out of federation", resource.GetName(), apiResource.Name)
+				continue
+			}
 			errors := validation.IsDNS1123Subdomain(resource.GetName())
 			if len(errors) == 0 {
 				targetResources.resources = append(targetResources.resources, &resource)
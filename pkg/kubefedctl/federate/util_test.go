@@ -0,0 +1,98 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package federate_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"sigs.k8s.io/kubefed/pkg/kubefedctl/federate"
+)
+
+func TestTemplateEqual(t *testing.T) {
+	// The token of the resource is generated anew whenever it is
+	// created, so it differs between the template and the resource
+	// created from it.
+	resource := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"name":      "name",
+				"namespace": "namespace",
+				"labels": map[string]interface{}{
+					"foo": "bar",
+				},
+			},
+			"spec": map[string]interface{}{
+				"audience": "example",
+				"token":    "generated-1",
+			},
+		},
+	}
+	resource.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   "example.io",
+		Kind:    "Credential",
+		Version: "v1",
+	})
+	federatedResources, err := federate.Resources([]*unstructured.Unstructured{resource})
+	require.NoError(t, err)
+	require.Len(t, federatedResources, 1)
+	fedResource := federatedResources[0]
+
+	recreated := resource.DeepCopy()
+	recreated.SetUID("uid")
+	require.NoError(t, unstructured.SetNestedField(recreated.Object, "generated-2", "spec", "token"))
+
+	testCases := map[string]struct {
+		targetResource *unstructured.Unstructured
+		ignorePaths    []string
+		expectedEqual  bool
+	}{
+		"equal to the federated resource": {
+			targetResource: resource,
+			expectedEqual:  true,
+		},
+		"a nondeterministic field differs by default": {
+			targetResource: recreated,
+		},
+		"a nondeterministic field is ignored": {
+			targetResource: recreated,
+			ignorePaths:    []string{"spec.token"},
+			expectedEqual:  true,
+		},
+		"other fields are still compared": {
+			targetResource: func() *unstructured.Unstructured {
+				obj := recreated.DeepCopy()
+				obj.SetLabels(map[string]string{"foo": "baz"})
+				return obj
+			}(),
+			ignorePaths: []string{"spec.token"},
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			original := tc.targetResource.DeepCopy()
+			equal, err := federate.TemplateEqual(fedResource, tc.targetResource, tc.ignorePaths...)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedEqual, equal)
+			assert.Equal(t, original, tc.targetResource, "Expected the target resource not to be modified")
+		})
+	}
+}
@@ -0,0 +1,181 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package federate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/rest"
+
+	"sigs.k8s.io/kubefed/pkg/apis/core/typeconfig"
+	ctlutil "sigs.k8s.io/kubefed/pkg/controller/utils"
+)
+
+// MaxFederatedResourceSize is the size in bytes above which a
+// federated resource is reported as too large to be stored. It
+// matches the default limit on the size of a request to etcd.
+const MaxFederatedResourceSize = 1536 * 1024
+
+// NamespaceFederationReport summarizes the validation of the
+// federation of a namespace and its contents.
+type NamespaceFederationReport struct {
+	Namespace string
+	// Counts is the number of resources that would be federated, by
+	// target kind.
+	Counts map[string]int
+	// Warnings do not prevent federation but may indicate that the
+	// result will not be as expected.
+	Warnings []string
+	// Errors prevent the federation of one or more resources.
+	Errors []string
+}
+
+// Valid indicates whether no errors were reported.
+func (r *NamespaceFederationReport) Valid() bool {
+	return len(r.Errors) == 0
+}
+
+func (r *NamespaceFederationReport) warnf(format string, args ...interface{}) {
+	r.Warnings = append(r.Warnings, fmt.Sprintf(format, args...))
+}
+
+func (r *NamespaceFederationReport) errorf(format string, args ...interface{}) {
+	r.Errors = append(r.Errors, fmt.Sprintf(format, args...))
+}
+
+// dryRunCreateFunc validates the creation of the given federated
+// resource without persisting it.
+type dryRunCreateFunc func(typeConfig typeconfig.Interface, federatedResource *unstructured.Unstructured) error
+
+// ValidateNamespaceFederation computes the federated resources for the
+// given namespace and its contents and validates them without
+// creating anything. Each federated resource of an enabled type is
+// created with server-side dry-run, so that the schema of the
+// federated type (including that of its template) and admission
+// control are applied and a federated resource that already exists is
+// reported as a conflict. Federated resources are also checked for
+// duplicate names and for exceeding MaxFederatedResourceSize.
+func ValidateNamespaceFederation(hostConfig *rest.Config, namespace, kubefedNamespace string, skipAPIResourceNames []string) (*NamespaceFederationReport, error) {
+	qualifiedName := ctlutil.QualifiedName{Name: namespace}
+	artifacts, err := GetFederateArtifacts(hostConfig, ctlutil.NamespaceName, kubefedNamespace, qualifiedName, false, true)
+	if err != nil {
+		return nil, err
+	}
+	containedArtifactsList, err := GetContainedArtifactsList(hostConfig, namespace, kubefedNamespace, skipAPIResourceNames, false, true, false)
+	if err != nil {
+		return nil, err
+	}
+	artifactsList := append([]*Artifacts{artifacts}, containedArtifactsList...)
+
+	dryRunCreate := func(typeConfig typeconfig.Interface, federatedResource *unstructured.Unstructured) error {
+		fedAPIResource := typeConfig.GetFederatedType()
+		fedClient, err := ctlutil.NewResourceClient(hostConfig, &fedAPIResource)
+		if err != nil {
+			return errors.Wrapf(err, "Error creating client for %s", fedAPIResource.Kind)
+		}
+		_, err = fedClient.Resources(federatedResource.GetNamespace()).Create(context.Background(), federatedResource, metav1.CreateOptions{DryRun: []string{metav1.DryRunAll}})
+		return err
+	}
+	return validateArtifacts(namespace, artifactsList, dryRunCreate), nil
+}
+
+func validateArtifacts(namespace string, artifactsList []*Artifacts, dryRunCreate dryRunCreateFunc) *NamespaceFederationReport {
+	report := &NamespaceFederationReport{
+		Namespace: namespace,
+		Counts:    make(map[string]int),
+	}
+	names := make(map[string]bool)
+	for _, artifacts := range artifactsList {
+		typeConfig := artifacts.typeConfig
+		targetKind := typeConfig.GetTargetType().Kind
+		fedKind := typeConfig.GetFederatedType().Kind
+		if len(artifacts.federatedResources) > 0 && !artifacts.typeConfigInstalled {
+			report.warnf("Federation of %s is not enabled; its federated resources cannot be created until it is", typeConfig.GetObjectMeta().Name)
+		}
+		for _, federatedResource := range artifacts.federatedResources {
+			report.Counts[targetKind]++
+			qualifiedName := ctlutil.NewQualifiedName(federatedResource)
+
+			key := fmt.Sprintf("%s/%s", fedKind, qualifiedName)
+			if names[key] {
+				report.errorf("%s %q would be created more than once", fedKind, qualifiedName)
+				continue
+			}
+			names[key] = true
+
+			data, err := json.Marshal(federatedResource.Object)
+			if err != nil {
+				report.errorf("Failed to encode %s %q: %v", fedKind, qualifiedName, err)
+				continue
+			}
+			if len(data) > MaxFederatedResourceSize {
+				report.errorf("%s %q is %d bytes, exceeding the maximum of %d bytes", fedKind, qualifiedName, len(data), MaxFederatedResourceSize)
+				continue
+			}
+
+			if !artifacts.typeConfigInstalled {
+				continue
+			}
+			err = dryRunCreate(typeConfig, federatedResource)
+			switch {
+			case apierrors.IsAlreadyExists(err):
+				report.errorf("%s %q already exists", fedKind, qualifiedName)
+			case err != nil:
+				report.errorf("%s %q failed validation: %v", fedKind, qualifiedName, err)
+			}
+		}
+	}
+	return report
+}
+
+// WriteNamespaceFederationReport writes a human-readable form of the
+// given report.
+func WriteNamespaceFederationReport(w io.Writer, report *NamespaceFederationReport) error {
+	if _, err := fmt.Fprintf(w, "Namespace %q:\n", report.Namespace); err != nil {
+		return err
+	}
+	kinds := make([]string, 0, len(report.Counts))
+	for kind := range report.Counts {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	for _, kind := range kinds {
+		if _, err := fmt.Fprintf(w, "  %s: %d\n", kind, report.Counts[kind]); err != nil {
+			return err
+		}
+	}
+	for _, warning := range report.Warnings {
+		if _, err := fmt.Fprintf(w, "Warning: %s\n", warning); err != nil {
+			return err
+		}
+	}
+	for _, err := range report.Errors {
+		if _, err := fmt.Fprintf(w, "Error: %s\n", err); err != nil {
+			return err
+		}
+	}
+	return nil
+}
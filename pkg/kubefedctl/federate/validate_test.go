@@ -0,0 +1,112 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package federate
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"sigs.k8s.io/kubefed/pkg/apis/core/typeconfig"
+	"sigs.k8s.io/kubefed/pkg/kubefedctl/enable"
+)
+
+func newFixtureArtifacts(t *testing.T, apiResource metav1.APIResource, installed bool, targetResources ...*unstructured.Unstructured) *Artifacts {
+	typeConfig := enable.GenerateTypeConfigForTarget(apiResource, enable.NewEnableTypeDirective())
+	artifacts := &Artifacts{
+		typeConfigInstalled: installed,
+		typeConfig:          typeConfig,
+	}
+	for _, targetResource := range targetResources {
+		federatedResource, err := FederatedResourceFromTargetResource(typeConfig, targetResource)
+		require.NoError(t, err)
+		artifacts.federatedResources = append(artifacts.federatedResources, federatedResource)
+	}
+	return artifacts
+}
+
+func newFixtureResource(apiVersion, kind, namespace, name string) *unstructured.Unstructured {
+	resource := &unstructured.Unstructured{}
+	resource.SetAPIVersion(apiVersion)
+	resource.SetKind(kind)
+	resource.SetNamespace(namespace)
+	resource.SetName(name)
+	return resource
+}
+
+func TestValidateArtifacts(t *testing.T) {
+	namespaceResource := metav1.APIResource{Name: "namespaces", Version: "v1", Kind: "Namespace"}
+	configMapResource := metav1.APIResource{Name: "configmaps", Version: "v1", Kind: "ConfigMap", Namespaced: true}
+	deploymentResource := metav1.APIResource{Name: "deployments", Group: "apps", Version: "v1", Kind: "Deployment", Namespaced: true}
+	secretResource := metav1.APIResource{Name: "secrets", Version: "v1", Kind: "Secret", Namespaced: true}
+
+	oversized := newFixtureResource("v1", "ConfigMap", "my-ns", "oversized")
+	oversized.Object["data"] = map[string]interface{}{
+		"key": strings.Repeat("x", MaxFederatedResourceSize),
+	}
+	artifactsList := []*Artifacts{
+		newFixtureArtifacts(t, namespaceResource, true, newFixtureResource("v1", "Namespace", "", "my-ns")),
+		newFixtureArtifacts(t, configMapResource, true,
+			newFixtureResource("v1", "ConfigMap", "my-ns", "a"),
+			newFixtureResource("v1", "ConfigMap", "my-ns", "b"),
+			oversized,
+		),
+		newFixtureArtifacts(t, deploymentResource, true, newFixtureResource("apps/v1", "Deployment", "my-ns", "existing")),
+		newFixtureArtifacts(t, secretResource, false, newFixtureResource("v1", "Secret", "my-ns", "a")),
+	}
+
+	var dryRunCreated []string
+	dryRunCreate := func(typeConfig typeconfig.Interface, federatedResource *unstructured.Unstructured) error {
+		dryRunCreated = append(dryRunCreated, federatedResource.GetKind()+"/"+federatedResource.GetName())
+		if federatedResource.GetName() == "existing" {
+			return apierrors.NewAlreadyExists(schema.GroupResource{Group: "types.kubefed.io", Resource: "federateddeployments"}, "existing")
+		}
+		return nil
+	}
+
+	report := validateArtifacts("my-ns", artifactsList, dryRunCreate)
+
+	assert.Equal(t, map[string]int{
+		"Namespace":  1,
+		"ConfigMap":  3,
+		"Deployment": 1,
+		"Secret":     1,
+	}, report.Counts, "The report should count the resources to federate by kind")
+	assert.False(t, report.Valid(), "The report should not be valid")
+	require.Len(t, report.Errors, 2)
+	assert.Contains(t, report.Errors[0], `FederatedConfigMap "my-ns/oversized" is`)
+	assert.Contains(t, report.Errors[1], `FederatedDeployment "my-ns/existing" already exists`)
+	require.Len(t, report.Warnings, 1)
+	assert.Contains(t, report.Warnings[0], "secrets is not enabled")
+	assert.Equal(t, []string{
+		"FederatedNamespace/my-ns",
+		"FederatedConfigMap/a",
+		"FederatedConfigMap/b",
+		"FederatedDeployment/existing",
+	}, dryRunCreated, "Only resources of enabled types within the size limit should be created with dry-run")
+
+	var out bytes.Buffer
+	require.NoError(t, WriteNamespaceFederationReport(&out, report))
+	assert.Contains(t, out.String(), "  ConfigMap: 3\n")
+}
@@ -27,9 +27,11 @@ import (
 	apiserverflag "k8s.io/component-base/cli/flag"
 	"k8s.io/klog/v2"
 
+	"sigs.k8s.io/kubefed/pkg/kubefedctl/describe"
 	"sigs.k8s.io/kubefed/pkg/kubefedctl/enable"
 	"sigs.k8s.io/kubefed/pkg/kubefedctl/federate"
 	"sigs.k8s.io/kubefed/pkg/kubefedctl/orphaning"
+	"sigs.k8s.io/kubefed/pkg/kubefedctl/propagation"
 	"sigs.k8s.io/kubefed/pkg/kubefedctl/util"
 )
 
@@ -62,10 +64,12 @@ func NewKubeFedCtlCommand(out io.Writer) *cobra.Command {
 	fedConfig := util.NewFedConfig(clientcmd.NewDefaultPathOptions())
 	rootCmd.AddCommand(enable.NewCmdTypeEnable(out, fedConfig))
 	rootCmd.AddCommand(NewCmdTypeDisable(out, fedConfig))
+	rootCmd.AddCommand(propagation.NewCmdPropagation(out, fedConfig))
 	rootCmd.AddCommand(federate.NewCmdFederateResource(out, fedConfig))
 	rootCmd.AddCommand(NewCmdJoin(out, fedConfig))
 	rootCmd.AddCommand(NewCmdUnjoin(out, fedConfig))
 	rootCmd.AddCommand(orphaning.NewCmdOrphaning(out, fedConfig))
+	rootCmd.AddCommand(describe.NewCmdDescribe(out, fedConfig))
 	rootCmd.AddCommand(NewCmdVersion(out))
 
 	return rootCmd
@@ -0,0 +1,159 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package propagation
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	fedv1b1 "sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
+	"sigs.k8s.io/kubefed/pkg/apis/core/v1beta1/validation"
+	genericclient "sigs.k8s.io/kubefed/pkg/client/generic"
+	ctlutil "sigs.k8s.io/kubefed/pkg/controller/utils"
+)
+
+// EnableOutcome is the outcome of enabling propagation for a
+// FederatedTypeConfig.
+type EnableOutcome string
+
+const (
+	// Enabled indicates that propagation was enabled.
+	Enabled EnableOutcome = "Enabled"
+	// AlreadyEnabled indicates that propagation was already enabled.
+	AlreadyEnabled EnableOutcome = "AlreadyEnabled"
+	// NotFound indicates that the FederatedTypeConfig does not exist.
+	NotFound EnableOutcome = "NotFound"
+	// Unresolved indicates that the given name could not be resolved
+	// to the name of a FederatedTypeConfig.
+	Unresolved EnableOutcome = "Unresolved"
+	// Invalid indicates that the FederatedTypeConfig is not valid, and
+	// that propagation was not enabled to avoid starting a sync
+	// controller for it.
+	Invalid EnableOutcome = "Invalid"
+	// Failed indicates that the FederatedTypeConfig could not be
+	// retrieved or patched.
+	Failed EnableOutcome = "Failed"
+)
+
+// EnableResult is the result of enabling propagation for a
+// FederatedTypeConfig.
+type EnableResult struct {
+	Name    ctlutil.QualifiedName
+	Outcome EnableOutcome
+	// Message describes why propagation was not enabled.
+	Message string
+}
+
+// Succeeded returns whether propagation is enabled for the
+// FederatedTypeConfig.
+func (r EnableResult) Succeeded() bool {
+	return r.Outcome == Enabled || r.Outcome == AlreadyEnabled
+}
+
+// ResolveNameFunc resolves a name that is not group qualified to the
+// name of the FederatedTypeConfig of its type.
+type ResolveNameFunc func(name string) (string, error)
+
+// EnablePropagation enables propagation for each of the named
+// FederatedTypeConfigs in the given namespace, and returns a result
+// per name in the order given. Names that are not group qualified are
+// resolved with the given function, if not nil. A FederatedTypeConfig
+// is only patched if its name could be resolved and it exists and is
+// valid, including its name being consistent with its target type, and
+// the failure for one name does not prevent propagation being enabled
+// for the others.
+func EnablePropagation(ctx context.Context, client genericclient.Client, namespace string, names []string, resolveName ResolveNameFunc) []EnableResult {
+	var results []EnableResult
+	seen := make(map[string]bool)
+	for _, name := range names {
+		if resolveName != nil && !strings.Contains(name, ".") {
+			resolvedName, err := resolveName(name)
+			if err != nil {
+				results = append(results, EnableResult{
+					Name:    ctlutil.QualifiedName{Namespace: namespace, Name: name},
+					Outcome: Unresolved,
+					Message: err.Error(),
+				})
+				continue
+			}
+			name = resolvedName
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		results = append(results, enableTypeConfigPropagation(ctx, client, ctlutil.QualifiedName{Namespace: namespace, Name: name}))
+	}
+	return results
+}
+
+func enableTypeConfigPropagation(ctx context.Context, client genericclient.Client, typeConfigName ctlutil.QualifiedName) EnableResult {
+	result := EnableResult{Name: typeConfigName}
+	typeConfig := &fedv1b1.FederatedTypeConfig{}
+	err := client.Get(ctx, typeConfig, typeConfigName.Namespace, typeConfigName.Name)
+	if apierrors.IsNotFound(err) {
+		result.Outcome = NotFound
+		result.Message = "FederatedTypeConfig does not exist"
+		return result
+	}
+	if err != nil {
+		result.Outcome = Failed
+		result.Message = errors.Wrap(err, "Error retrieving FederatedTypeConfig").Error()
+		return result
+	}
+	if errs := validation.ValidateFederatedTypeConfig(typeConfig, false); len(errs) > 0 {
+		result.Outcome = Invalid
+		result.Message = errs.ToAggregate().Error()
+		return result
+	}
+	if typeConfig.GetPropagationEnabled() {
+		result.Outcome = AlreadyEnabled
+		return result
+	}
+
+	patch := runtimeclient.MergeFrom(typeConfig.DeepCopy())
+	typeConfig.Spec.Propagation = fedv1b1.PropagationEnabled
+	if err := client.Patch(ctx, typeConfig, patch); err != nil {
+		result.Outcome = Failed
+		result.Message = errors.Wrap(err, "Error enabling propagation").Error()
+		return result
+	}
+	result.Outcome = Enabled
+	return result
+}
+
+// WriteEnableResults writes a human-readable form of the given
+// results.
+func WriteEnableResults(w io.Writer, results []EnableResult) error {
+	for _, result := range results {
+		line := fmt.Sprintf("FederatedTypeConfig %q: %s", result.Name, result.Outcome)
+		if len(result.Message) > 0 {
+			line = fmt.Sprintf("%s (%s)", line, strings.TrimSpace(result.Message))
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package propagation
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/pkg/errors"
+
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fedv1b1 "sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
+	ctlutil "sigs.k8s.io/kubefed/pkg/controller/utils"
+	"sigs.k8s.io/kubefed/test/common/fake"
+)
+
+func newTypeConfig(name, targetKind, pluralName string, propagation fedv1b1.PropagationMode) *fedv1b1.FederatedTypeConfig {
+	return &fedv1b1.FederatedTypeConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "kube-federation-system",
+			Name:      name,
+		},
+		Spec: fedv1b1.FederatedTypeConfigSpec{
+			TargetType: fedv1b1.APIResource{
+				Version:    "v1",
+				Kind:       targetKind,
+				PluralName: pluralName,
+				Scope:      apiextv1.NamespaceScoped,
+			},
+			Propagation: propagation,
+			FederatedType: fedv1b1.APIResource{
+				Group:      "types.kubefed.io",
+				Version:    "v1beta1",
+				Kind:       "Federated" + targetKind,
+				PluralName: "federated" + pluralName,
+				Scope:      apiextv1.NamespaceScoped,
+			},
+		},
+	}
+}
+
+func TestEnablePropagation(t *testing.T) {
+	client := fake.NewGenericClient(fake.NewStore())
+	typeConfigs := []*fedv1b1.FederatedTypeConfig{
+		newTypeConfig("configmaps", "ConfigMap", "configmaps", fedv1b1.PropagationDisabled),
+		newTypeConfig("secrets", "Secret", "secrets", fedv1b1.PropagationDisabled),
+		newTypeConfig("services", "Service", "services", fedv1b1.PropagationEnabled),
+		// The name is not consistent with the target type.
+		newTypeConfig("jobs.batch", "Job", "jobs", fedv1b1.PropagationDisabled),
+	}
+	for _, typeConfig := range typeConfigs {
+		if err := client.Create(context.Background(), typeConfig); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	// A name that cannot be resolved does not prevent propagation
+	// being enabled for the names that follow it.
+	resolveName := func(name string) (string, error) {
+		switch name {
+		case "cm":
+			return "configmaps", nil
+		case "widgets":
+			return "", errors.Errorf("Unable to find api resource named %q", name)
+		}
+		return name, nil
+	}
+	results := EnablePropagation(context.Background(), client, "kube-federation-system",
+		[]string{"widgets", "configmaps", "secrets", "services", "jobs.batch", "missing", "cm"}, resolveName)
+
+	expectedOutcomes := []struct {
+		name    string
+		outcome EnableOutcome
+	}{
+		{"widgets", Unresolved},
+		{"configmaps", Enabled},
+		{"secrets", Enabled},
+		{"services", AlreadyEnabled},
+		{"jobs.batch", Invalid},
+		{"missing", NotFound},
+	}
+	if len(results) != len(expectedOutcomes) {
+		t.Fatalf("Expected %d results, got %v", len(expectedOutcomes), results)
+	}
+	for i, expected := range expectedOutcomes {
+		result := results[i]
+		expectedName := ctlutil.QualifiedName{Namespace: "kube-federation-system", Name: expected.name}
+		if result.Name != expectedName || result.Outcome != expected.outcome {
+			t.Fatalf("Expected result %d to be %s for %q, got %s for %q", i, expected.outcome, expectedName, result.Outcome, result.Name)
+		}
+		succeeded := expected.outcome == Enabled || expected.outcome == AlreadyEnabled
+		if result.Succeeded() != succeeded {
+			t.Fatalf("Expected the result for %q to have succeeded %v", expectedName, succeeded)
+		}
+		if !succeeded && len(result.Message) == 0 {
+			t.Fatalf("Expected a message for the result for %q", expectedName)
+		}
+	}
+
+	expectedPropagation := map[string]bool{
+		"configmaps": true,
+		"secrets":    true,
+		"services":   true,
+		"jobs.batch": false,
+	}
+	propagation := make(map[string]bool)
+	for name := range expectedPropagation {
+		typeConfig := &fedv1b1.FederatedTypeConfig{}
+		if err := client.Get(context.Background(), typeConfig, "kube-federation-system", name); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		propagation[name] = typeConfig.GetPropagationEnabled()
+	}
+	if !reflect.DeepEqual(expectedPropagation, propagation) {
+		t.Fatalf("Expected propagation to be enabled for %v, got %v", expectedPropagation, propagation)
+	}
+}
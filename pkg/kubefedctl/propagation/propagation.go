@@ -0,0 +1,150 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package propagation
+
+import (
+	"context"
+	"io"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/kubefed/pkg/apis/core/typeconfig"
+	genericclient "sigs.k8s.io/kubefed/pkg/client/generic"
+	"sigs.k8s.io/kubefed/pkg/kubefedctl/enable"
+	"sigs.k8s.io/kubefed/pkg/kubefedctl/options"
+	"sigs.k8s.io/kubefed/pkg/kubefedctl/util"
+)
+
+var (
+	enablePropagationLong = `
+		Enables propagation of Kubernetes API types whose federation
+		has been enabled. Each named FederatedTypeConfig must exist and
+		be valid for propagation to be enabled for it, and a result is
+		reported for each of them, including names that could not be
+		resolved to a FederatedTypeConfig.
+
+		Current context is assumed to be a Kubernetes cluster hosting
+		the kubefed control plane. Please use the
+		--host-cluster-context flag otherwise.`
+
+	enablePropagationExample = `
+		# Enable propagation of the FederatedTypeConfigs named
+		'deployments.apps', 'configmaps' and 'secrets'
+		kubefedctl propagation enable deployments.apps configmaps secrets --host-cluster-context=cluster1`
+)
+
+type enablePropagation struct {
+	options.GlobalSubcommandOptions
+	names []string
+}
+
+// NewCmdPropagation is the head of the propagation sub commands.
+func NewCmdPropagation(cmdOut io.Writer, config util.FedConfig) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "propagation",
+		Short: "Manage the propagation of Kubernetes API types",
+		Long:  "Manage the propagation of Kubernetes API types",
+		Run: func(cmd *cobra.Command, args []string) {
+			err := cmd.Help()
+			if err != nil {
+				klog.Fatalf("Error: %v", err)
+			}
+		},
+	}
+	cmd.AddCommand(newCmdEnablePropagation(cmdOut, config))
+
+	return cmd
+}
+
+func newCmdEnablePropagation(cmdOut io.Writer, config util.FedConfig) *cobra.Command {
+	opts := &enablePropagation{}
+	cmd := &cobra.Command{
+		Use:     "enable NAME...",
+		Short:   "Enable propagation of one or more Kubernetes API types",
+		Long:    enablePropagationLong,
+		Example: enablePropagationExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			err := opts.Complete(args)
+			if err != nil {
+				klog.Fatalf("Error: %v", err)
+			}
+
+			err = opts.Run(cmdOut, config)
+			if err != nil {
+				klog.Fatalf("Error: %v", err)
+			}
+		},
+	}
+
+	flags := cmd.Flags()
+	opts.GlobalSubcommandBind(flags)
+	err := flags.MarkHidden("dry-run")
+	if err != nil {
+		klog.Fatalf("Error: %v", err)
+	}
+
+	return cmd
+}
+
+// Complete ensures that options are valid.
+func (o *enablePropagation) Complete(args []string) error {
+	if len(args) == 0 {
+		return errors.New("At least one NAME is required")
+	}
+	o.names = args
+	return nil
+}
+
+// Run is the implementation of the `propagation enable` command.
+func (o *enablePropagation) Run(cmdOut io.Writer, config util.FedConfig) error {
+	hostConfig, err := config.HostConfig(o.HostClusterContext, o.Kubeconfig)
+	if err != nil {
+		return errors.Wrap(err, "Failed to get host cluster config")
+	}
+	client, err := genericclient.New(hostConfig)
+	if err != nil {
+		return errors.Wrap(err, "Failed to get kubefed clientset")
+	}
+
+	// As for the disable command, a name that is not group qualified
+	// is resolved to that of the FederatedTypeConfig of its type.
+	resolveName := func(name string) (string, error) {
+		apiResource, err := enable.LookupAPIResource(hostConfig, name, "")
+		if err != nil {
+			return "", err
+		}
+		return typeconfig.GroupQualifiedName(*apiResource), nil
+	}
+
+	results := EnablePropagation(context.TODO(), client, o.KubeFedNamespace, o.names, resolveName)
+	if err := WriteEnableResults(cmdOut, results); err != nil {
+		return err
+	}
+	failed := 0
+	for _, result := range results {
+		if !result.Succeeded() {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return errors.Errorf("Failed to enable propagation for %d of %d FederatedTypeConfigs", failed, len(results))
+	}
+	return nil
+}
@@ -103,6 +103,20 @@ var (
 		}, []string{"controller"},
 	)
 
+	federatedObjects = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kubefed_federated_objects",
+			Help: "Number of federated objects of a kind in a specific phase.",
+		}, []string{"kind", "phase"},
+	)
+
+	applyResultsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kubefed_apply_results_total",
+			Help: "Number of times federated resources of a kind were applied to member clusters by result.",
+		}, []string{"kind", "result"},
+	)
+
 	ControllerRuntimeReconcileTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
 		Name: "controller_runtime_reconcile_total",
 		Help: "Total number of reconciliations per controller",
@@ -125,6 +139,17 @@ var (
 		Help: "Maximum number of concurrent reconciles per controller",
 	}, []string{"controller"})
 
+	ResourceClientRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "kubefed_resource_client_requests_total",
+		Help: "Number of requests made by instrumented resource clients by verb, resource and result",
+	}, []string{"verb", "resource", "result"})
+
+	ResourceClientRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "kubefed_resource_client_request_duration_seconds",
+		Help:    "Latency of requests made by instrumented resource clients by verb and resource",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"verb", "resource"})
+
 	ControllerRuntimeActiveWorkers = prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		Name: "controller_runtime_active_workers",
 		Help: "Number of currently used workers per controller",
@@ -141,6 +166,22 @@ const (
 	ClusterOffline  = "offline"
 )
 
+// Phases of federated objects derived from their propagation status.
+const (
+	FederatedObjectPropagating = "Propagating"
+	FederatedObjectHealthy     = "Healthy"
+	FederatedObjectFailed      = "Failed"
+	FederatedObjectPaused      = "Paused"
+)
+
+// FederatedObjectPhases are the phases federated objects are counted in.
+var FederatedObjectPhases = []string{
+	FederatedObjectPropagating,
+	FederatedObjectHealthy,
+	FederatedObjectFailed,
+	FederatedObjectPaused,
+}
+
 func RegisterAll() {
 	metrics.Registry.MustRegister(
 		// Register custom metrics
@@ -154,6 +195,10 @@ func RegisterAll() {
 		dispatchOperationDuration,
 		controllerRuntimeReconcileDuration,
 		controllerRuntimeReconcileDurationSummary,
+		federatedObjects,
+		applyResultsTotal,
+		ResourceClientRequestsTotal,
+		ResourceClientRequestDuration,
 	)
 }
 
@@ -247,3 +292,24 @@ func UpdateControllerRuntimeReconcileTime(controller string, duration time.Durat
 	}
 	ControllerRuntimeReconcileTime.WithLabelValues(controller).Observe(duration.Seconds())
 }
+
+// SetFederatedObjects records the number of federated objects of the
+// given kind in each phase. Phases absent from the counts are set to
+// zero so that objects leaving a phase are not reported as stale.
+func SetFederatedObjects(kind string, counts map[string]int) {
+	for _, phase := range FederatedObjectPhases {
+		federatedObjects.WithLabelValues(kind, phase).Set(float64(counts[phase]))
+	}
+}
+
+// DeleteFederatedObjects removes the series recorded for federated
+// objects of the given kind.
+func DeleteFederatedObjects(kind string) {
+	federatedObjects.DeletePartialMatch(prometheus.Labels{"kind": kind})
+}
+
+// RecordApplyResult records the result of applying a federated
+// resource of the given kind to a member cluster.
+func RecordApplyResult(kind, result string) {
+	applyResultsTotal.WithLabelValues(kind, result).Inc()
+}
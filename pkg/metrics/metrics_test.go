@@ -0,0 +1,74 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	// Registers the metrics of controller-runtime workqueues.
+	_ "sigs.k8s.io/controller-runtime/pkg/controller/priorityqueue"
+)
+
+func TestSetFederatedObjects(t *testing.T) {
+	kind := "FederatedConfigMap"
+
+	expectCount := func(phase string, expected float64) {
+		t.Helper()
+		if value := testutil.ToFloat64(federatedObjects.WithLabelValues(kind, phase)); value != expected {
+			t.Fatalf("Expected %v objects in phase %q, got %v", expected, phase, value)
+		}
+	}
+
+	SetFederatedObjects(kind, map[string]int{FederatedObjectPropagating: 2})
+	expectCount(FederatedObjectPropagating, 2)
+	expectCount(FederatedObjectHealthy, 0)
+
+	SetFederatedObjects(kind, map[string]int{FederatedObjectHealthy: 1, FederatedObjectFailed: 1})
+	expectCount(FederatedObjectPropagating, 0)
+	expectCount(FederatedObjectHealthy, 1)
+	expectCount(FederatedObjectFailed, 1)
+
+	DeleteFederatedObjects(kind)
+	if count := testutil.CollectAndCount(federatedObjects); count != 0 {
+		t.Fatalf("Expected no series after deletion, got %d", count)
+	}
+}
+
+func TestWorkqueueMetricsProviderSharesControllerRuntimeMetrics(t *testing.T) {
+	provider := NewWorkqueueMetricsProvider()
+	provider.NewAddsMetric("test-queue").Inc()
+	provider.NewDepthMetric("test-queue").Inc()
+
+	// The metrics are exposed by the registry in which controller-runtime
+	// registered the metrics of its workqueues.
+	expected := `
+# HELP workqueue_adds_total Total number of adds handled by workqueue
+# TYPE workqueue_adds_total counter
+workqueue_adds_total{controller="test-queue",name="test-queue"} 1
+# HELP workqueue_depth Current depth of workqueue by workqueue and priority
+# TYPE workqueue_depth gauge
+workqueue_depth{controller="test-queue",name="test-queue",priority=""} 1
+`
+	err := testutil.GatherAndCompare(metrics.Registry, strings.NewReader(expected), "workqueue_adds_total", "workqueue_depth")
+	if err != nil {
+		t.Fatalf("Unexpected workqueue metrics: %v", err)
+	}
+}
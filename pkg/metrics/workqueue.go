@@ -0,0 +1,164 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// The workqueue metrics are defined like those that controller-runtime
+// registers for its workqueues so that the queues of reconcile workers,
+// which are not client-go workqueues, are reported with the same
+// series. A collector must match the one registered by controller-runtime
+// for it to be shared.
+var (
+	workqueueDepth = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: metrics.WorkQueueSubsystem,
+		Name:      metrics.DepthKey,
+		Help:      "Current depth of workqueue by workqueue and priority",
+	}, []string{"name", "controller", "priority"})
+
+	workqueueAdds = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: metrics.WorkQueueSubsystem,
+		Name:      metrics.AddsKey,
+		Help:      "Total number of adds handled by workqueue",
+	}, []string{"name", "controller"})
+
+	workqueueLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Subsystem:                       metrics.WorkQueueSubsystem,
+		Name:                            metrics.QueueLatencyKey,
+		Help:                            "How long in seconds an item stays in workqueue before being requested",
+		Buckets:                         prometheus.ExponentialBuckets(10e-9, 10, 12),
+		NativeHistogramBucketFactor:     1.1,
+		NativeHistogramMaxBucketNumber:  100,
+		NativeHistogramMinResetDuration: 1 * time.Hour,
+	}, []string{"name", "controller"})
+
+	workqueueWorkDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Subsystem:                       metrics.WorkQueueSubsystem,
+		Name:                            metrics.WorkDurationKey,
+		Help:                            "How long in seconds processing an item from workqueue takes.",
+		Buckets:                         prometheus.ExponentialBuckets(10e-9, 10, 12),
+		NativeHistogramBucketFactor:     1.1,
+		NativeHistogramMaxBucketNumber:  100,
+		NativeHistogramMinResetDuration: 1 * time.Hour,
+	}, []string{"name", "controller"})
+
+	workqueueUnfinished = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: metrics.WorkQueueSubsystem,
+		Name:      metrics.UnfinishedWorkKey,
+		Help: "How many seconds of work has been done that " +
+			"is in progress and hasn't been observed by work_duration. Large " +
+			"values indicate stuck threads. One can deduce the number of stuck " +
+			"threads by observing the rate at which this increases.",
+	}, []string{"name", "controller"})
+
+	workqueueLongestRunningProcessor = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: metrics.WorkQueueSubsystem,
+		Name:      metrics.LongestRunningProcessorKey,
+		Help: "How many seconds has the longest running " +
+			"processor for workqueue been running.",
+	}, []string{"name", "controller"})
+
+	workqueueRetries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: metrics.WorkQueueSubsystem,
+		Name:      metrics.RetriesKey,
+		Help:      "Total number of retries handled by workqueue",
+	}, []string{"name", "controller"})
+
+	registerWorkqueueMetricsOnce sync.Once
+)
+
+// registerWorkqueueMetrics registers the workqueue metrics, or adopts
+// the collectors already registered by controller-runtime. It must
+// only be called once all packages have been initialized, since
+// controller-runtime registers its collectors on initialization and
+// fails if they are already registered.
+func registerWorkqueueMetrics() {
+	registerWorkqueueMetricsOnce.Do(func() {
+		workqueueDepth = registerOrExisting(workqueueDepth)
+		workqueueAdds = registerOrExisting(workqueueAdds)
+		workqueueLatency = registerOrExisting(workqueueLatency)
+		workqueueWorkDuration = registerOrExisting(workqueueWorkDuration)
+		workqueueUnfinished = registerOrExisting(workqueueUnfinished)
+		workqueueLongestRunningProcessor = registerOrExisting(workqueueLongestRunningProcessor)
+		workqueueRetries = registerOrExisting(workqueueRetries)
+	})
+}
+
+func registerOrExisting[T prometheus.Collector](collector T) T {
+	err := metrics.Registry.Register(collector)
+	if err == nil {
+		return collector
+	}
+	alreadyRegistered := prometheus.AlreadyRegisteredError{}
+	if errors.As(err, &alreadyRegistered) {
+		if existing, ok := alreadyRegistered.ExistingCollector.(T); ok {
+			return existing
+		}
+	}
+	klog.Errorf("Failed to register workqueue metrics: %v", err)
+	return collector
+}
+
+// WorkqueueMetricsProvider provides the workqueue metrics of the
+// queues of reconcile workers.
+type WorkqueueMetricsProvider struct{}
+
+var _ workqueue.MetricsProvider = WorkqueueMetricsProvider{}
+
+// NewWorkqueueMetricsProvider returns a provider of workqueue metrics,
+// registering the metrics on first use.
+func NewWorkqueueMetricsProvider() WorkqueueMetricsProvider {
+	registerWorkqueueMetrics()
+	return WorkqueueMetricsProvider{}
+}
+
+func (WorkqueueMetricsProvider) NewDepthMetric(name string) workqueue.GaugeMetric {
+	return workqueueDepth.WithLabelValues(name, name, "")
+}
+
+func (WorkqueueMetricsProvider) NewAddsMetric(name string) workqueue.CounterMetric {
+	return workqueueAdds.WithLabelValues(name, name)
+}
+
+func (WorkqueueMetricsProvider) NewLatencyMetric(name string) workqueue.HistogramMetric {
+	return workqueueLatency.WithLabelValues(name, name)
+}
+
+func (WorkqueueMetricsProvider) NewWorkDurationMetric(name string) workqueue.HistogramMetric {
+	return workqueueWorkDuration.WithLabelValues(name, name)
+}
+
+func (WorkqueueMetricsProvider) NewUnfinishedWorkSecondsMetric(name string) workqueue.SettableGaugeMetric {
+	return workqueueUnfinished.WithLabelValues(name, name)
+}
+
+func (WorkqueueMetricsProvider) NewLongestRunningProcessorSecondsMetric(name string) workqueue.SettableGaugeMetric {
+	return workqueueLongestRunningProcessor.WithLabelValues(name, name)
+}
+
+func (WorkqueueMetricsProvider) NewRetriesMetric(name string) workqueue.CounterMetric {
+	return workqueueRetries.WithLabelValues(name, name)
+}
@@ -18,7 +18,9 @@ package common
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"reflect"
 	"strings"
 	"time"
 
@@ -27,6 +29,7 @@ import (
 
 	appsv1 "k8s.io/api/apps/v1"
 	apiv1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -35,15 +38,16 @@ import (
 	"k8s.io/apimachinery/pkg/util/wait"
 	kubeclientset "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
+	"k8s.io/utils/ptr"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
-	"sigs.k8s.io/kubefed/pkg/apis/core/common"
 	"sigs.k8s.io/kubefed/pkg/apis/core/typeconfig"
 	fedv1a1 "sigs.k8s.io/kubefed/pkg/apis/core/v1alpha1"
 	"sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
 	genericclient "sigs.k8s.io/kubefed/pkg/client/generic"
 	"sigs.k8s.io/kubefed/pkg/controller/sync"
+	"sigs.k8s.io/kubefed/pkg/controller/sync/dispatch"
 	"sigs.k8s.io/kubefed/pkg/controller/sync/status"
 	versionmanager "sigs.k8s.io/kubefed/pkg/controller/sync/version"
 	"sigs.k8s.io/kubefed/pkg/controller/utils"
@@ -59,7 +63,7 @@ type FederatedTypeCrudTester struct {
 	typeConfig        typeconfig.Interface
 	targetIsNamespace bool
 	client            genericclient.Client
-	kubeConfig        *rest.Config
+	resourceClientFor ResourceClientFunc
 	testClusters      map[string]TestCluster
 	waitInterval      time.Duration
 	// KubeFed operations will use wait.ForeverTestTimeout.  Any
@@ -67,6 +71,10 @@ type FederatedTypeCrudTester struct {
 	// propagation latency.
 	clusterWaitTimeout time.Duration
 	clustersNamespace  string
+	// The key of the annotation listing the clusters a managed
+	// resource is propagated to, if the sync controller is configured
+	// to add it.
+	placementAnnotation string
 }
 
 type TestClusterConfig struct {
@@ -74,18 +82,58 @@ type TestClusterConfig struct {
 	IsPrimary bool
 }
 
+// NamespaceAPIResource is the API resource of namespaces.
+var NamespaceAPIResource = metav1.APIResource{Name: "namespaces", Version: "v1", Kind: utils.NamespaceKind}
+
+// EndpointSliceAPIResource is the API resource of EndpointSlices.
+var EndpointSliceAPIResource = utils.EndpointSliceAPIResource
+
 type TestCluster struct {
 	TestClusterConfig
 	Client utils.ResourceClient
+	// Client for the namespaces of the cluster.
+	NamespaceClient utils.ResourceClient
+	// Client for the EndpointSlices of the cluster.
+	EndpointSliceClient utils.ResourceClient
+}
+
+// ResourceClientFunc returns a client for the given API resource of
+// the host cluster.
+type ResourceClientFunc func(apiResource metav1.APIResource) (utils.ResourceClient, error)
+
+// NewFederatedTypeCrudTester returns a crud tester that accesses the
+// host cluster with clients created from the given kubeconfig. The
+// given rate limits apply to the clients the tester creates for the
+// host cluster and the test clusters.
+func NewFederatedTypeCrudTester(testLogger TestLogger, typeConfig typeconfig.Interface, kubeConfig *rest.Config, clientRateLimits utils.ClientRateLimits, testClusters map[string]TestCluster, clustersNamespace string, waitInterval, clusterWaitTimeout time.Duration) (*FederatedTypeCrudTester, error) {
+	kubeConfig = rest.CopyConfig(kubeConfig)
+	clientRateLimits.Apply(kubeConfig)
+	rateLimitedClusters := make(map[string]TestCluster, len(testClusters))
+	for clusterName, testCluster := range testClusters {
+		if testCluster.Config != nil {
+			testCluster.Config = rest.CopyConfig(testCluster.Config)
+			clientRateLimits.Apply(testCluster.Config)
+		}
+		rateLimitedClusters[clusterName] = testCluster
+	}
+	testClusters = rateLimitedClusters
+	resourceClientFor := func(apiResource metav1.APIResource) (utils.ResourceClient, error) {
+		return utils.NewResourceClient(kubeConfig, &apiResource, utils.WithRequestMetrics())
+	}
+	return NewFederatedTypeCrudTesterForClients(testLogger, typeConfig, genericclient.NewForConfigOrDie(kubeConfig), resourceClientFor, testClusters, clustersNamespace, waitInterval, clusterWaitTimeout)
 }
 
-func NewFederatedTypeCrudTester(testLogger TestLogger, typeConfig typeconfig.Interface, kubeConfig *rest.Config, testClusters map[string]TestCluster, clustersNamespace string, waitInterval, clusterWaitTimeout time.Duration) (*FederatedTypeCrudTester, error) {
+// NewFederatedTypeCrudTesterForClients returns a crud tester that
+// accesses the host cluster with the given clients rather than with
+// clients created from a kubeconfig. A test cluster without a config
+// is only accessed via its client and is assumed to be reachable.
+func NewFederatedTypeCrudTesterForClients(testLogger TestLogger, typeConfig typeconfig.Interface, client genericclient.Client, resourceClientFor ResourceClientFunc, testClusters map[string]TestCluster, clustersNamespace string, waitInterval, clusterWaitTimeout time.Duration) (*FederatedTypeCrudTester, error) {
 	return &FederatedTypeCrudTester{
 		tl:                 testLogger,
 		typeConfig:         typeConfig,
 		targetIsNamespace:  typeConfig.GetTargetType().Kind == utils.NamespaceKind,
-		client:             genericclient.NewForConfigOrDie(kubeConfig),
-		kubeConfig:         kubeConfig,
+		client:             client,
+		resourceClientFor:  resourceClientFor,
 		testClusters:       testClusters,
 		waitInterval:       waitInterval,
 		clusterWaitTimeout: clusterWaitTimeout,
@@ -116,13 +164,33 @@ func (c *FederatedTypeCrudTester) Create(targetObject *unstructured.Unstructured
 		c.tl.Fatalf("Error obtaining %s from %s %q: %v", fedKind, kind, qualifiedName, err)
 	}
 
+	if len(c.typeConfig.GetTargetNameTemplate()) > 0 {
+		// The target name is computed from the labels of the
+		// federated resource.
+		fedObject.SetLabels(targetObject.GetLabels())
+	}
+
 	fedObject = c.setAdditionalTestData(fedObject, overrides, selectors, targetObject.GetGenerateName())
+	for _, warning := range c.PlacementWarnings(fedObject) {
+		c.tl.Logf("Warning for %s %q: %s", fedKind, qualifiedName, warning)
+	}
 
 	return c.createResource(c.typeConfig.GetFederatedType(), fedObject)
 }
 
+// PlacementWarnings returns the advisory warnings for the placement of
+// the given federated resource against the test clusters, e.g. for a
+// cluster selector that matches none of them.
+func (c *FederatedTypeCrudTester) PlacementWarnings(fedObject *unstructured.Unstructured) []string {
+	warnings, err := utils.PlacementWarnings(fedObject, c.getClusters())
+	if err != nil {
+		c.tl.Fatalf("Error computing placement warnings for %s %q: %v", c.typeConfig.GetFederatedType().Kind, utils.NewQualifiedName(fedObject), err)
+	}
+	return warnings
+}
+
 func (c *FederatedTypeCrudTester) createResource(apiResource metav1.APIResource, desiredObj *unstructured.Unstructured) *unstructured.Unstructured {
-	createdObj, err := CreateResource(c.kubeConfig, apiResource, desiredObj)
+	createdObj, err := c.resourceClient(apiResource).Resources(desiredObj.GetNamespace()).Create(context.Background(), desiredObj, metav1.CreateOptions{})
 	if err != nil {
 		c.tl.Fatalf("Error creating resource: %v", err)
 	}
@@ -134,7 +202,7 @@ func (c *FederatedTypeCrudTester) createResource(apiResource metav1.APIResource,
 }
 
 func (c *FederatedTypeCrudTester) resourceClient(apiResource metav1.APIResource) utils.ResourceClient {
-	resourceClient, err := utils.NewResourceClient(c.kubeConfig, &apiResource)
+	resourceClient, err := c.resourceClientFor(apiResource)
 	if err != nil {
 		c.tl.Fatalf("Error creating resource client: %v", err)
 	}
@@ -221,9 +289,67 @@ func (c *FederatedTypeCrudTester) CheckUpdate(ctx context.Context, immediate boo
 	c.CheckPropagation(ctx, immediate, updatedFedObject)
 }
 
+// CheckOverridesRoundTrip verifies that the given overrides are
+// retrieved from the API with their structure intact after being set
+// on the federated resource, and that they are propagated to member
+// clusters.
+func (c *FederatedTypeCrudTester) CheckOverridesRoundTrip(ctx context.Context, immediate bool, fedObject *unstructured.Unstructured, overrides utils.OverridesMap) *unstructured.Unstructured {
+	apiResource := c.typeConfig.GetFederatedType()
+	kind := apiResource.Kind
+	qualifiedName := utils.NewQualifiedName(fedObject)
+
+	expectedOverrides, err := normalizeOverrides(overrides)
+	if err != nil {
+		c.tl.Fatalf("Error normalizing overrides: %v", err)
+	}
+
+	c.tl.Logf("Setting overrides of %s %q", kind, qualifiedName)
+	updatedFedObject, err := c.updateObject(ctx, apiResource, fedObject, func(obj *unstructured.Unstructured) {
+		if err := utils.SetOverrides(obj, overrides); err != nil {
+			c.tl.Fatalf("Error setting overrides of %s %q: %v", kind, qualifiedName, err)
+		}
+	})
+	if err != nil {
+		c.tl.Fatalf("Error updating %s %q: %v", kind, qualifiedName, err)
+	}
+
+	client := c.resourceClient(apiResource)
+	retrievedFedObject, err := client.Resources(qualifiedName.Namespace).Get(ctx, qualifiedName.Name, metav1.GetOptions{})
+	if err != nil {
+		c.tl.Fatalf("Error retrieving %s %q: %v", kind, qualifiedName, err)
+	}
+	retrievedOverrides, err := utils.GetOverrides(retrievedFedObject)
+	if err != nil {
+		c.tl.Fatalf("Error retrieving overrides of %s %q: %v", kind, qualifiedName, err)
+	}
+	if !reflect.DeepEqual(expectedOverrides, retrievedOverrides) {
+		c.tl.Fatalf("Expected overrides of %s %q to be %#v, got %#v", kind, qualifiedName, expectedOverrides, retrievedOverrides)
+	}
+
+	c.CheckPropagation(ctx, immediate, updatedFedObject)
+	return updatedFedObject
+}
+
+// normalizeOverrides returns the given overrides with values in the
+// form produced by decoding the overrides of a federated resource.
+func normalizeOverrides(overrides utils.OverridesMap) (utils.OverridesMap, error) {
+	content, err := json.Marshal(overrides)
+	if err != nil {
+		return nil, err
+	}
+	normalized := utils.OverridesMap{}
+	if err := json.Unmarshal(content, &normalized); err != nil {
+		return nil, err
+	}
+	return normalized, nil
+}
+
 // CheckPlacementChange verifies that a change in the list of clusters
 // in a placement resource has the desired impact on member cluster
-// state.
+// state. If a removal strategy applies to the federated resource, the
+// resource in the removed cluster is also verified to be drained
+// before it is deleted, which requires a drain duration longer than
+// the wait interval.
 func (c *FederatedTypeCrudTester) CheckPlacementChange(ctx context.Context, immediate bool, fedObject *unstructured.Unstructured) {
 	apiResource := c.typeConfig.GetFederatedType()
 	kind := apiResource.Kind
@@ -238,6 +364,7 @@ func (c *FederatedTypeCrudTester) CheckPlacementChange(ctx context.Context, imme
 	}
 
 	c.tl.Logf("Updating %s %q", kind, qualifiedName)
+	var removedClusterName string
 	updatedFedObject, err := c.updateObject(ctx, apiResource, fedObject, func(obj *unstructured.Unstructured) {
 		clusterNames, err := utils.GetClusterNames(obj)
 		if err != nil {
@@ -251,6 +378,7 @@ func (c *FederatedTypeCrudTester) CheckPlacementChange(ctx context.Context, imme
 			// cluster whose name was removed.
 			c.tl.Fatalf("Expected %d cluster names, got %d", len(clusterNames)-1, len(updatedClusterNames))
 		}
+		removedClusterName = sets.List(sets.New(clusterNames...).Difference(sets.New(updatedClusterNames...)))[0]
 		err = utils.SetClusterNames(obj, updatedClusterNames)
 		if err != nil {
 			c.tl.Fatalf("Error setting cluster names for %s %q: %v", kind, qualifiedName, err)
@@ -260,7 +388,410 @@ func (c *FederatedTypeCrudTester) CheckPlacementChange(ctx context.Context, imme
 		c.tl.Fatalf("Error updating %s %q: %v", kind, qualifiedName, err)
 	}
 
+	removalStrategy, err := utils.GetRemovalStrategy(updatedFedObject, c.typeConfig.GetRemovalStrategy())
+	if err != nil {
+		c.tl.Fatalf("Error retrieving the removal strategy of %s %q: %v", kind, qualifiedName, err)
+	}
+	// A namespace in the host cluster is unlabeled rather than
+	// deleted, and so is not drained.
+	if removalStrategy == nil || (c.targetIsNamespace && removedClusterName == c.getPrimaryClusterName()) {
+		c.CheckPropagation(ctx, immediate, updatedFedObject)
+		return
+	}
+
+	drainStarted := c.waitForDrain(ctx, immediate, updatedFedObject, removedClusterName, removalStrategy)
+	c.CheckPropagation(ctx, immediate, updatedFedObject)
+	if drained := time.Since(drainStarted); drained < removalStrategy.DrainDuration.Duration {
+		c.tl.Fatalf("Expected %s %q to be removed from cluster %q no earlier than %v after draining started, but it was removed after %v",
+			c.typeConfig.GetTargetType().Kind, c.targetName(updatedFedObject), removedClusterName, removalStrategy.DrainDuration.Duration, drained)
+	}
+}
+
+// waitForDrain waits for the resource in the named cluster to have
+// been drained by the given removal strategy without having been
+// deleted, and returns the time at which draining started.
+func (c *FederatedTypeCrudTester) waitForDrain(ctx context.Context, immediate bool, fedObject *unstructured.Unstructured, clusterName string, removalStrategy *v1beta1.RemovalStrategy) time.Time {
+	targetKind := c.typeConfig.GetTargetType().Kind
+	targetName := utils.QualifiedNameForCluster(clusterName, c.targetName(fedObject))
+	client := c.testClusters[clusterName].Client
+	var drainStarted time.Time
+	err := wait.PollUntilContextTimeout(ctx, c.waitInterval, c.clusterWaitTimeout, immediate, func(ctx context.Context) (bool, error) {
+		clusterObj, err := client.Resources(targetName.Namespace).Get(ctx, targetName.Name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return false, errors.New("the resource was deleted before it was drained")
+		}
+		if err != nil {
+			return false, nil
+		}
+		value, ok := clusterObj.GetAnnotations()[utils.DrainStartedAnnotation]
+		if !ok {
+			return false, nil
+		}
+		drainStarted, err = time.Parse(time.RFC3339, value)
+		if err != nil {
+			return false, errors.Wrapf(err, "invalid %q annotation", utils.DrainStartedAnnotation)
+		}
+		if removalStrategy.PreDeletePatch == nil {
+			return true, nil
+		}
+		// The pre-delete patch has been applied if applying it again
+		// leaves the resource unchanged.
+		original, err := clusterObj.MarshalJSON()
+		if err != nil {
+			return false, err
+		}
+		patched, err := jsonpatch.MergePatch(original, removalStrategy.PreDeletePatch.Raw)
+		if err != nil {
+			return false, err
+		}
+		return jsonpatch.Equal(original, patched), nil
+	})
+	if err != nil {
+		c.tl.Fatalf("Error waiting for %s %q in cluster %q to be drained: %v", targetKind, targetName, clusterName, err)
+	}
+	return drainStarted
+}
+
+// CheckClusterLocalOverride verifies that adding a cluster-local
+// override for the named cluster to the given federated resource
+// applies the override in that cluster without changing the override
+// version of the resource or updating the resources in the other
+// placed clusters.
+func (c *FederatedTypeCrudTester) CheckClusterLocalOverride(ctx context.Context, immediate bool, fedObject *unstructured.Unstructured, clusterName string) *unstructured.Unstructured {
+	apiResource := c.typeConfig.GetFederatedType()
+	kind := apiResource.Kind
+	qualifiedName := utils.NewQualifiedName(fedObject)
+
+	clusterNames, err := utils.GetClusterNames(fedObject)
+	if err != nil {
+		c.tl.Fatalf("Error retrieving cluster names for %s %q: %v", kind, qualifiedName, err)
+	}
+	var otherClusterNames []string
+	for _, placedClusterName := range clusterNames {
+		if placedClusterName != clusterName {
+			otherClusterNames = append(otherClusterNames, placedClusterName)
+		}
+	}
+	if len(otherClusterNames) == len(clusterNames) {
+		c.tl.Fatalf("Expected cluster %q to be placed for %s %q", clusterName, kind, qualifiedName)
+	}
+	versions := c.clusterObjectVersions(ctx, fedObject, otherClusterNames)
+	overrideVersion, err := sync.GetOverrideHash(fedObject)
+	if err != nil {
+		c.tl.Fatalf("Error computing override hash for %s %q: %v", kind, qualifiedName, err)
+	}
+
+	c.tl.Logf("Adding a cluster-local override for cluster %q to %s %q", clusterName, kind, qualifiedName)
+	updatedFedObject, err := c.updateObject(ctx, apiResource, fedObject, func(obj *unstructured.Unstructured) {
+		overrides, err := utils.GetOverrides(obj)
+		if err != nil {
+			c.tl.Fatalf("Error retrieving overrides of %s %q: %v", kind, qualifiedName, err)
+		}
+		overrides[clusterName] = append(overrides[clusterName], utils.ClusterOverride{
+			Op:           "add",
+			Path:         "/metadata/annotations",
+			Value:        map[string]interface{}{"crudtester-cluster-local": "true"},
+			ClusterLocal: true,
+		})
+		if err := utils.SetOverrides(obj, overrides); err != nil {
+			c.tl.Fatalf("Error setting overrides of %s %q: %v", kind, qualifiedName, err)
+		}
+	})
+	if err != nil {
+		c.tl.Fatalf("Error updating %s %q: %v", kind, qualifiedName, err)
+	}
+
+	updatedOverrideVersion, err := sync.GetOverrideHash(updatedFedObject)
+	if err != nil {
+		c.tl.Fatalf("Error computing override hash for %s %q: %v", kind, qualifiedName, err)
+	}
+	if updatedOverrideVersion != overrideVersion {
+		c.tl.Errorf("Expected the override version of %s %q not to change, but it changed from %q to %q", kind, qualifiedName, overrideVersion, updatedOverrideVersion)
+	}
+	c.CheckPropagation(ctx, immediate, updatedFedObject)
+
+	c.checkClusterObjectVersions(ctx, updatedFedObject, versions, "other")
+
+	return updatedFedObject
+}
+
+// clusterObjectVersions returns the versions of the resources managed
+// for the given federated resource in the named clusters.
+func (c *FederatedTypeCrudTester) clusterObjectVersions(ctx context.Context, fedObject *unstructured.Unstructured, clusterNames []string) map[string]string {
+	targetKind := c.typeConfig.GetTargetType().Kind
+	versions := make(map[string]string)
+	for _, clusterName := range clusterNames {
+		targetName := utils.QualifiedNameForCluster(clusterName, c.targetName(fedObject))
+		clusterObj, err := c.testClusters[clusterName].Client.Resources(targetName.Namespace).Get(ctx, targetName.Name, metav1.GetOptions{})
+		if err != nil {
+			c.tl.Fatalf("Error retrieving %s %q in cluster %q: %v", targetKind, targetName, clusterName, err)
+		}
+		versions[clusterName] = utils.ObjectVersion(clusterObj)
+	}
+	return versions
+}
+
+// checkClusterObjectVersions verifies that the resources managed for
+// the given federated resource were not updated since their versions
+// were retrieved.
+func (c *FederatedTypeCrudTester) checkClusterObjectVersions(ctx context.Context, fedObject *unstructured.Unstructured, versions map[string]string, description string) {
+	targetKind := c.typeConfig.GetTargetType().Kind
+	for clusterName, version := range c.clusterObjectVersions(ctx, fedObject, sets.StringKeySet(versions).List()) {
+		if version != versions[clusterName] {
+			targetName := utils.QualifiedNameForCluster(clusterName, c.targetName(fedObject))
+			c.tl.Errorf("Expected %s %q in %s cluster %q not to be updated, but its version changed from %q to %q", targetKind, targetName, description, clusterName, versions[clusterName], version)
+		}
+	}
+}
+
+// CheckTargetNameChange verifies that changing the value of a label
+// referenced by the target name template of the type results in
+// resources being propagated under the new name and the resources
+// propagated under the previous name being removed.
+func (c *FederatedTypeCrudTester) CheckTargetNameChange(ctx context.Context, immediate bool, fedObject *unstructured.Unstructured, labelKey, labelValue string) *unstructured.Unstructured {
+	apiResource := c.typeConfig.GetFederatedType()
+	kind := apiResource.Kind
+	qualifiedName := utils.NewQualifiedName(fedObject)
+	targetKind := c.typeConfig.GetTargetType().Kind
+
+	if len(c.typeConfig.GetTargetNameTemplate()) == 0 {
+		c.tl.Fatalf("%s does not define a target name template", kind)
+	}
+	previousName := c.targetName(fedObject)
+
+	c.tl.Logf("Updating label %q of %s %q to %q", labelKey, kind, qualifiedName, labelValue)
+	updatedFedObject, err := c.updateObject(ctx, apiResource, fedObject, func(obj *unstructured.Unstructured) {
+		labels := obj.GetLabels()
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		labels[labelKey] = labelValue
+		obj.SetLabels(labels)
+	})
+	if err != nil {
+		c.tl.Fatalf("Error updating %s %q: %v", kind, qualifiedName, err)
+	}
+	if c.targetName(updatedFedObject) == previousName {
+		c.tl.Fatalf("Expected the label change to result in a target name other than %q", previousName)
+	}
+
+	c.CheckPropagation(ctx, immediate, updatedFedObject)
+
+	for clusterName, testCluster := range c.testClusters {
+		previousClusterName := utils.QualifiedNameForCluster(clusterName, previousName)
+		c.tl.Logf("Waiting for renamed %s %q to be removed from cluster %q", targetKind, previousClusterName, clusterName)
+		err = c.waitForResourceDeletion(ctx, immediate, testCluster.Client, previousClusterName, func() bool {
+			return true
+		})
+		if err != nil {
+			c.tl.Fatalf("Failed to verify removal of renamed %s %q in cluster %q: %v", targetKind, previousClusterName, clusterName, err)
+		}
+	}
+
+	return updatedFedObject
+}
+
+// CheckPlacementOnly creates a federated resource that places the
+// named cluster with the PlacementOnly mode and verifies that the
+// cluster is reported in status without receiving the resource.
+func (c *FederatedTypeCrudTester) CheckPlacementOnly(ctx context.Context, immediate bool, targetObject *unstructured.Unstructured, overrides []interface{}, clusterName string) *unstructured.Unstructured {
+	qualifiedName := utils.NewQualifiedName(targetObject)
+	kind := c.typeConfig.GetTargetType().Kind
+	fedKind := c.typeConfig.GetFederatedType().Kind
+	fedObject, err := federate.FederatedResourceFromTargetResource(c.typeConfig, targetObject)
+	if err != nil {
+		c.tl.Fatalf("Error obtaining %s from %s %q: %v", fedKind, kind, qualifiedName, err)
+	}
+	fedObject = c.setAdditionalTestData(fedObject, overrides, nil, targetObject.GetGenerateName())
+
+	var clusters []interface{}
+	for name := range c.testClusters {
+		mode := utils.PropagatePlacementMode
+		if name == clusterName {
+			mode = utils.PlacementOnlyMode
+		}
+		clusters = append(clusters, map[string]interface{}{
+			utils.NameField: name,
+			utils.ModeField: mode,
+		})
+	}
+	err = unstructured.SetNestedSlice(fedObject.Object, clusters, utils.SpecField, utils.PlacementField, utils.ClustersField)
+	if err != nil {
+		c.tl.Fatalf("Error setting placement in %s %q: %v", fedKind, qualifiedName, err)
+	}
+
+	c.tl.Logf("Creating %s %q with placement-only cluster %q", fedKind, qualifiedName, clusterName)
+	fedObject = c.createResource(c.typeConfig.GetFederatedType(), fedObject)
+
+	c.CheckPropagation(ctx, immediate, fedObject)
+	return fedObject
+}
+
+// CheckNamespaceOptIn verifies that resources are only created in the
+// namespaces of member clusters that have opted in to management. The
+// sync controller is expected to be configured with a namespace opt-in
+// label that the namespace of the target object has in all clusters
+// but the named one. A federated resource placing all clusters is then
+// expected to be propagated to all clusters but the named one, for
+// which the status is expected to report that the namespace has not
+// opted in.
+func (c *FederatedTypeCrudTester) CheckNamespaceOptIn(ctx context.Context, immediate bool, targetObject *unstructured.Unstructured, blockedClusterName string) *unstructured.Unstructured {
+	qualifiedName := utils.NewQualifiedName(targetObject)
+	targetKind := c.typeConfig.GetTargetType().Kind
+	fedKind := c.typeConfig.GetFederatedType().Kind
+
+	if len(qualifiedName.Namespace) == 0 {
+		c.tl.Fatalf("%s %q is not namespaced", targetKind, qualifiedName)
+	}
+
+	fedObject, err := federate.FederatedResourceFromTargetResource(c.typeConfig, targetObject)
+	if err != nil {
+		c.tl.Fatalf("Error obtaining %s from %s %q: %v", fedKind, targetKind, qualifiedName, err)
+	}
+	fedObject = c.setAdditionalTestData(fedObject, nil, nil, targetObject.GetGenerateName())
+	fedObject = c.createResource(c.typeConfig.GetFederatedType(), fedObject)
+
+	c.tl.Logf("Waiting for %s %q to report that propagation to cluster %q is blocked", fedKind, qualifiedName, blockedClusterName)
+	err = wait.PollUntilContextTimeout(ctx, c.waitInterval, c.clusterWaitTimeout, immediate, func(ctx context.Context) (bool, error) {
+		resource, err := GetGenericResource(c.client, fedObject.GroupVersionKind(), qualifiedName)
+		if err != nil {
+			return false, err
+		}
+		if resource.Status == nil || resource.Status.ObservedGeneration != fedObject.GetGeneration() {
+			return false, nil
+		}
+		clusterStatus := make(map[string]status.PropagationStatus)
+		for _, cluster := range resource.Status.Clusters {
+			clusterStatus[cluster.Name] = cluster.Status
+		}
+		for clusterName := range c.testClusters {
+			expected := status.ClusterPropagationOK
+			if clusterName == blockedClusterName {
+				expected = status.NamespaceNotOptedIn
+			}
+			if actual, ok := clusterStatus[clusterName]; !ok || actual != expected {
+				return false, nil
+			}
+		}
+		return true, nil
+	})
+	if err != nil {
+		c.tl.Fatalf("Error waiting for %s %q to report that propagation to cluster %q is blocked: %v", fedKind, qualifiedName, blockedClusterName, err)
+	}
+
+	for clusterName, testCluster := range c.testClusters {
+		targetName := utils.QualifiedNameForCluster(clusterName, c.targetName(fedObject))
+		_, err := testCluster.Client.Resources(targetName.Namespace).Get(ctx, targetName.Name, metav1.GetOptions{})
+		switch {
+		case clusterName == blockedClusterName:
+			if !apierrors.IsNotFound(err) {
+				c.tl.Fatalf("Expected %s %q not to be created in cluster %q: %v", targetKind, targetName, clusterName, err)
+			}
+		case err != nil:
+			c.tl.Fatalf("Error retrieving %s %q in cluster %q: %v", targetKind, targetName, clusterName, err)
+		}
+	}
+	return fedObject
+}
+
+// CheckCoOwnership verifies that only the included fields of the type
+// are managed for resources in member clusters. The given dot-separated
+// field, which must not be included and would otherwise be reset to the
+// template, is set to the given value in each member cluster to
+// simulate another controller, the template of the federated resource
+// is updated with the provided function, and the value is then
+// expected to survive propagation of the update.
+func (c *FederatedTypeCrudTester) CheckCoOwnership(ctx context.Context, immediate bool, fedObject *unstructured.Unstructured, coOwnedField string, coOwnedValue interface{}, mutateTemplateFunc func(template map[string]interface{})) *unstructured.Unstructured {
+	apiResource := c.typeConfig.GetFederatedType()
+	kind := apiResource.Kind
+	qualifiedName := utils.NewQualifiedName(fedObject)
+	targetKind := c.typeConfig.GetTargetType().Kind
+
+	includedFields := c.typeConfig.GetIncludedFields()
+	if len(includedFields) == 0 {
+		c.tl.Fatalf("%s does not define included fields", kind)
+	}
+	for _, includedField := range includedFields {
+		if includedField == coOwnedField || strings.HasPrefix(coOwnedField, includedField+".") {
+			c.tl.Fatalf("Field %q is included for %s and cannot be co-owned", coOwnedField, kind)
+		}
+	}
+
+	fields := strings.Split(coOwnedField, ".")
+	targetName := c.targetName(fedObject)
+	for clusterName, testCluster := range c.testClusters {
+		clusterTargetName := utils.QualifiedNameForCluster(clusterName, targetName)
+		c.tl.Logf("Setting field %q of %s %q in cluster %q as another controller would", coOwnedField, targetKind, clusterTargetName, clusterName)
+		err := wait.PollUntilContextTimeout(ctx, c.waitInterval, wait.ForeverTestTimeout, immediate, func(ctx context.Context) (bool, error) {
+			clusterObj, err := testCluster.Client.Resources(clusterTargetName.Namespace).Get(ctx, clusterTargetName.Name, metav1.GetOptions{})
+			if err != nil {
+				c.tl.Logf("Error retrieving %s %q in cluster %q: %v", targetKind, clusterTargetName, clusterName, err)
+				return false, nil
+			}
+			if err := unstructured.SetNestedField(clusterObj.Object, coOwnedValue, fields...); err != nil {
+				return false, err
+			}
+			_, err = testCluster.Client.Resources(clusterTargetName.Namespace).Update(ctx, clusterObj, metav1.UpdateOptions{})
+			if err != nil {
+				c.tl.Logf("Will retry setting field %q of %s %q in cluster %q after error: %v", coOwnedField, targetKind, clusterTargetName, clusterName, err)
+				return false, nil
+			}
+			return true, nil
+		})
+		if err != nil {
+			c.tl.Fatalf("Failed to set field %q of %s %q in cluster %q: %v", coOwnedField, targetKind, clusterTargetName, clusterName, err)
+		}
+	}
+
+	c.tl.Logf("Updating the template of %s %q", kind, qualifiedName)
+	updatedFedObject, err := c.updateObject(ctx, apiResource, fedObject, func(obj *unstructured.Unstructured) {
+		template, _, err := unstructured.NestedMap(obj.Object, utils.SpecField, utils.TemplateField)
+		if err != nil {
+			c.tl.Fatalf("Error retrieving template of %s %q: %v", kind, qualifiedName, err)
+		}
+		mutateTemplateFunc(template)
+		if err := unstructured.SetNestedMap(obj.Object, template, utils.SpecField, utils.TemplateField); err != nil {
+			c.tl.Fatalf("Error setting template of %s %q: %v", kind, qualifiedName, err)
+		}
+	})
+	if err != nil {
+		c.tl.Fatalf("Error updating %s %q: %v", kind, qualifiedName, err)
+	}
+
 	c.CheckPropagation(ctx, immediate, updatedFedObject)
+
+	for clusterName, testCluster := range c.testClusters {
+		clusterTargetName := utils.QualifiedNameForCluster(clusterName, targetName)
+		clusterObj, err := testCluster.Client.Resources(clusterTargetName.Namespace).Get(ctx, clusterTargetName.Name, metav1.GetOptions{})
+		if err != nil {
+			c.tl.Fatalf("Error retrieving %s %q in cluster %q: %v", targetKind, clusterTargetName, clusterName, err)
+		}
+		value, _, err := unstructured.NestedFieldNoCopy(clusterObj.Object, fields...)
+		if err != nil {
+			c.tl.Fatalf("Error retrieving field %q of %s %q in cluster %q: %v", coOwnedField, targetKind, clusterTargetName, clusterName, err)
+		}
+		if !reflect.DeepEqual(value, coOwnedValue) {
+			c.tl.Fatalf("Expected field %q of %s %q in cluster %q to retain value %v, got %v", coOwnedField, targetKind, clusterTargetName, clusterName, coOwnedValue, value)
+		}
+	}
+
+	return updatedFedObject
+}
+
+// targetName returns the name of the resources expected in member
+// clusters for the given federated resource.
+func (c *FederatedTypeCrudTester) targetName(fedObject *unstructured.Unstructured) utils.QualifiedName {
+	qualifiedName := utils.QualifiedNameForTarget(c.typeConfig, fedObject)
+	nameTemplate := c.typeConfig.GetTargetNameTemplate()
+	if len(nameTemplate) == 0 {
+		return qualifiedName
+	}
+	name, err := utils.ComputeTargetName(nameTemplate, fedObject.GetLabels())
+	if err != nil {
+		c.tl.Fatalf("Error computing target name for %s %q: %v", c.typeConfig.GetFederatedType().Kind, qualifiedName, err)
+	}
+	qualifiedName.Name = name
+	return qualifiedName
 }
 
 func (c *FederatedTypeCrudTester) CheckDelete(ctx context.Context, immediate bool, fedObject *unstructured.Unstructured, orphanDependents bool) {
@@ -322,59 +853,1550 @@ func (c *FederatedTypeCrudTester) CheckDelete(ctx context.Context, immediate boo
 		if apierrors.IsNotFound(err) {
 			return true, nil
 		}
-		return false, err
+		return false, err
+	})
+	if err != nil {
+		c.tl.Fatalf("Error deleting %s %q: %v", federatedKind, qualifiedName, err)
+	}
+
+	// The propagated version of a federated resource is removed by
+	// the sync controller regardless of whether managed resources
+	// are orphaned.
+	versionName := PropagatedVersionQualifiedName(c.typeConfig, qualifiedName)
+	err = WaitForVersionDeletion(ctx, c.client, c.typeConfig, versionName, c.waitInterval, waitTimeout)
+	if err != nil {
+		c.tl.Fatalf("Timed out waiting for the propagated version %q of %s %q to be removed", versionName, federatedKind, qualifiedName)
+	}
+
+	qualifiedName = c.targetName(fedObject)
+	name = qualifiedName.Name
+
+	targetKind := c.typeConfig.GetTargetType().Kind
+
+	// TODO(marun) Consider using informer to detect expected deletion state.
+	var stateMsg = "unlabeled"
+	if deletingInCluster {
+		stateMsg = "not present"
+	}
+	clusters, err := utils.ComputePlacement(fedObject, c.getClusters(), false)
+	if err != nil {
+		c.tl.Fatalf("Couldn't retrieve clusters for %s/%s: %v", federatedKind, name, err)
+	}
+	for clusterName, testCluster := range c.testClusters {
+		if !clusters.Has(clusterName) {
+			continue
+		}
+		namespace = utils.QualifiedNameForCluster(clusterName, qualifiedName).Namespace
+		err = wait.PollUntilContextTimeout(ctx, c.waitInterval, waitTimeout, immediate, func(ctx context.Context) (bool, error) {
+			obj, err := testCluster.Client.Resources(namespace).Get(context.Background(), name, metav1.GetOptions{})
+			switch {
+			case !deletingInCluster && apierrors.IsNotFound(err):
+				return false, errors.Errorf("%s %q was unexpectedly deleted from cluster %q", targetKind, qualifiedName, clusterName)
+			case deletingInCluster && err == nil:
+				if c.targetIsNamespace && clusterName == c.getPrimaryClusterName() {
+					// A namespace in the host cluster should have the
+					// managed label removed instead of being deleted.
+					return !utils.HasManagedLabel(obj), nil
+				}
+				// Continue checking for deletion or label removal
+				return false, nil
+			case !deletingInCluster && err == nil:
+				return !utils.HasManagedLabel(obj), nil
+			case err != nil && !apierrors.IsNotFound(err):
+				c.tl.Errorf("Error while checking whether %s %q is %s in cluster %q: %v", targetKind, qualifiedName, stateMsg, clusterName, err)
+				// This error may be recoverable
+				return false, nil
+			default:
+				return true, nil
+			}
+		})
+		if err != nil {
+			c.tl.Fatalf("Failed to confirm whether %s %q is %s in cluster %q: %v", targetKind, qualifiedName, stateMsg, clusterName, err)
+		}
+	}
+}
+
+// CheckRelease verifies that requesting the release of the given
+// federated resource removes the markers of management by KubeFed
+// from the resources in its placed clusters while leaving their
+// content unchanged, and that the resources persist unchanged once the
+// federated resource is deleted.
+func (c *FederatedTypeCrudTester) CheckRelease(ctx context.Context, immediate bool, fedObject *unstructured.Unstructured) {
+	apiResource := c.typeConfig.GetFederatedType()
+	federatedKind := apiResource.Kind
+	qualifiedName := utils.NewQualifiedName(fedObject)
+	targetKind := c.typeConfig.GetTargetType().Kind
+	targetQualifiedName := c.targetName(fedObject)
+
+	selectedClusters, err := utils.ComputePlacement(fedObject, c.getClusters(), false)
+	if err != nil {
+		c.tl.Fatalf("Error computing placement of %s %q: %v", federatedKind, qualifiedName, err)
+	}
+	placementOnlyClusters, err := utils.GetPlacementOnlyClusterNames(fedObject)
+	if err != nil {
+		c.tl.Fatalf("Error retrieving placement-only cluster names for %s %q: %v", federatedKind, qualifiedName, err)
+	}
+	propagatedClusters := selectedClusters.Difference(placementOnlyClusters)
+	contents := make(map[string]map[string]interface{})
+	for _, clusterName := range sets.List(propagatedClusters) {
+		targetName := utils.QualifiedNameForCluster(clusterName, targetQualifiedName)
+		clusterObj, err := c.testClusters[clusterName].Client.Resources(targetName.Namespace).Get(ctx, targetName.Name, metav1.GetOptions{})
+		if err != nil {
+			c.tl.Fatalf("Error retrieving %s %q in cluster %q: %v", targetKind, targetName, clusterName, err)
+		}
+		contents[clusterName] = releasedContent(clusterObj)
+	}
+
+	c.tl.Logf("Requesting the release of %s %q", federatedKind, qualifiedName)
+	_, err = c.updateObject(ctx, apiResource, fedObject, func(obj *unstructured.Unstructured) {
+		annotations := obj.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[utils.ReleaseAnnotation] = utils.ReleasedValue
+		obj.SetAnnotations(annotations)
+	})
+	if err != nil {
+		c.tl.Fatalf("Error updating %s %q: %v", federatedKind, qualifiedName, err)
+	}
+
+	released := make(map[string]*unstructured.Unstructured)
+	for clusterName, content := range contents {
+		targetName := utils.QualifiedNameForCluster(clusterName, targetQualifiedName)
+		c.tl.Logf("Waiting for %s %q in cluster %q to be released", targetKind, targetName, clusterName)
+		var clusterObj *unstructured.Unstructured
+		err := wait.PollUntilContextTimeout(ctx, c.waitInterval, wait.ForeverTestTimeout, immediate, func(ctx context.Context) (bool, error) {
+			var err error
+			clusterObj, err = c.testClusters[clusterName].Client.Resources(targetName.Namespace).Get(ctx, targetName.Name, metav1.GetOptions{})
+			if err != nil {
+				return false, err
+			}
+			return !c.hasManagedMetadata(clusterObj), nil
+		})
+		if err != nil {
+			c.tl.Fatalf("Failed to verify the release of %s %q in cluster %q: %v", targetKind, targetName, clusterName, err)
+		}
+		if !reflect.DeepEqual(content, releasedContent(clusterObj)) {
+			c.tl.Fatalf("Expected the content of %s %q in cluster %q to be unchanged by its release", targetKind, targetName, clusterName)
+		}
+		released[clusterName] = clusterObj
+	}
+
+	// The propagated version no longer describes managed resources.
+	versionName := PropagatedVersionQualifiedName(c.typeConfig, qualifiedName)
+	err = WaitForVersionDeletion(ctx, c.client, c.typeConfig, versionName, c.waitInterval, wait.ForeverTestTimeout)
+	if err != nil {
+		c.tl.Fatalf("Timed out waiting for the propagated version %q of released %s %q to be removed", versionName, federatedKind, qualifiedName)
+	}
+
+	c.tl.Logf("Deleting released %s %q", federatedKind, qualifiedName)
+	resourceClient := c.resourceClient(apiResource)
+	err = resourceClient.Resources(qualifiedName.Namespace).Delete(ctx, qualifiedName.Name, metav1.DeleteOptions{})
+	if err != nil {
+		c.tl.Fatalf("Error deleting %s %q: %v", federatedKind, qualifiedName, err)
+	}
+	err = wait.PollUntilContextTimeout(ctx, c.waitInterval, wait.ForeverTestTimeout, true, func(ctx context.Context) (bool, error) {
+		_, err := resourceClient.Resources(qualifiedName.Namespace).Get(ctx, qualifiedName.Name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	})
+	if err != nil {
+		c.tl.Fatalf("Error deleting %s %q: %v", federatedKind, qualifiedName, err)
+	}
+
+	for clusterName, releasedObj := range released {
+		targetName := utils.QualifiedNameForCluster(clusterName, targetQualifiedName)
+		clusterObj, err := c.testClusters[clusterName].Client.Resources(targetName.Namespace).Get(ctx, targetName.Name, metav1.GetOptions{})
+		if err != nil {
+			c.tl.Fatalf("Expected released %s %q to persist in cluster %q: %v", targetKind, targetName, clusterName, err)
+		}
+		if clusterObj.GetResourceVersion() != releasedObj.GetResourceVersion() {
+			c.tl.Fatalf("Expected released %s %q in cluster %q not to be updated after deletion of %s %q", targetKind, targetName, clusterName, federatedKind, qualifiedName)
+		}
+	}
+}
+
+// hasManagedMetadata returns whether the given cluster object has any
+// of the markers of management by KubeFed.
+func (c *FederatedTypeCrudTester) hasManagedMetadata(clusterObj *unstructured.Unstructured) bool {
+	unmarkedObj := clusterObj.DeepCopy()
+	utils.RemoveManagedMetadata(unmarkedObj, nil, nil, c.placementAnnotation)
+	return !reflect.DeepEqual(unmarkedObj.GetLabels(), clusterObj.GetLabels()) ||
+		!reflect.DeepEqual(unmarkedObj.GetAnnotations(), clusterObj.GetAnnotations())
+}
+
+// releasedContent returns the content of the given cluster object
+// that release is expected to leave unchanged.
+func releasedContent(clusterObj *unstructured.Unstructured) map[string]interface{} {
+	content := clusterObj.DeepCopy().Object
+	delete(content, "metadata")
+	delete(content, utils.StatusField)
+	return content
+}
+
+// CheckDeletionBlocked verifies that deletion of a federated resource
+// requiring deletion confirmation retains its managed resources until
+// the deletion is confirmed, after which the managed resources are
+// expected to be removed.
+func (c *FederatedTypeCrudTester) CheckDeletionBlocked(ctx context.Context, immediate bool, fedObject *unstructured.Unstructured) {
+	apiResource := c.typeConfig.GetFederatedType()
+	federatedKind := apiResource.Kind
+	qualifiedName := utils.NewQualifiedName(fedObject)
+	resourceClient := c.resourceClient(apiResource)
+
+	c.tl.Logf("Requiring deletion confirmation for %s %q", federatedKind, qualifiedName)
+	fedObject, err := c.updateObject(ctx, apiResource, fedObject, utils.RequireDeletionConfirmation)
+	if err != nil {
+		c.tl.Fatalf("Error requiring deletion confirmation for %s %q: %v", federatedKind, qualifiedName, err)
+	}
+
+	c.tl.Logf("Deleting %s %q without confirmation", federatedKind, qualifiedName)
+	err = resourceClient.Resources(qualifiedName.Namespace).Delete(context.Background(), qualifiedName.Name, metav1.DeleteOptions{})
+	if err != nil {
+		c.tl.Fatalf("Error deleting %s %q: %v", federatedKind, qualifiedName, err)
+	}
+
+	c.tl.Logf("Waiting for the status of %s %q to indicate %s", federatedKind, qualifiedName, status.DeletionBlocked)
+	fedObject, err = c.waitForCondition(ctx, immediate, fedObject, status.PropagationConditionType, func(condition *status.GenericCondition) bool {
+		return condition.Reason == status.DeletionBlocked
+	})
+	if err != nil {
+		c.tl.Fatalf("Error waiting for %s %q to have propagation status %s: %v", federatedKind, qualifiedName, status.DeletionBlocked, err)
+	}
+
+	targetKind := c.typeConfig.GetTargetType().Kind
+	targetQualifiedName := c.targetName(fedObject)
+	clusters, err := utils.ComputePlacement(fedObject, c.getClusters(), false)
+	if err != nil {
+		c.tl.Fatalf("Couldn't retrieve clusters for %s %q: %v", federatedKind, qualifiedName, err)
+	}
+	for clusterName, testCluster := range c.testClusters {
+		if !clusters.Has(clusterName) {
+			continue
+		}
+		targetName := utils.QualifiedNameForCluster(clusterName, targetQualifiedName)
+		obj, err := testCluster.Client.Resources(targetName.Namespace).Get(ctx, targetName.Name, metav1.GetOptions{})
+		if err != nil {
+			c.tl.Fatalf("Expected %s %q to be retained in cluster %q: %v", targetKind, targetName, clusterName, err)
+		}
+		if obj.GetDeletionTimestamp() != nil {
+			c.tl.Fatalf("Expected %s %q in cluster %q not to be deleted before confirmation", targetKind, targetName, clusterName)
+		}
+	}
+
+	c.tl.Logf("Confirming deletion of %s %q", federatedKind, qualifiedName)
+	fedObject, err = c.updateObject(ctx, apiResource, fedObject, utils.ConfirmDeletion)
+	if err != nil {
+		c.tl.Fatalf("Error confirming deletion of %s %q: %v", federatedKind, qualifiedName, err)
+	}
+
+	c.CheckDelete(ctx, immediate, fedObject, false)
+}
+
+// CheckDeletionGracePeriod verifies that deletion of the given
+// federated resource with the given deletion grace period retains its
+// managed resources while marking them as pending deletion, and that
+// recreating the federated resource within the grace period cancels
+// their deletion. The grace period must exceed the duration of the
+// check. Returns the recreated federated resource, which is recreated
+// without the grace period so that it can be deleted immediately.
+func (c *FederatedTypeCrudTester) CheckDeletionGracePeriod(ctx context.Context, immediate bool, fedObject *unstructured.Unstructured, gracePeriod time.Duration) *unstructured.Unstructured {
+	apiResource := c.typeConfig.GetFederatedType()
+	federatedKind := apiResource.Kind
+	qualifiedName := utils.NewQualifiedName(fedObject)
+	resourceClient := c.resourceClient(apiResource)
+	targetKind := c.typeConfig.GetTargetType().Kind
+	targetQualifiedName := c.targetName(fedObject)
+
+	c.tl.Logf("Setting a deletion grace period of %v for %s %q", gracePeriod, federatedKind, qualifiedName)
+	fedObject, err := c.updateObject(ctx, apiResource, fedObject, func(obj *unstructured.Unstructured) {
+		annotations := obj.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[utils.DeletionGracePeriodAnnotation] = gracePeriod.String()
+		obj.SetAnnotations(annotations)
+	})
+	if err != nil {
+		c.tl.Fatalf("Error updating %s %q: %v", federatedKind, qualifiedName, err)
+	}
+
+	selectedClusters, err := utils.ComputePlacement(fedObject, c.getClusters(), false)
+	if err != nil {
+		c.tl.Fatalf("Error computing placement of %s %q: %v", federatedKind, qualifiedName, err)
+	}
+	placementOnlyClusters, err := utils.GetPlacementOnlyClusterNames(fedObject)
+	if err != nil {
+		c.tl.Fatalf("Error retrieving placement-only cluster names for %s %q: %v", federatedKind, qualifiedName, err)
+	}
+	uids := make(map[string]string)
+	for _, clusterName := range sets.List(selectedClusters.Difference(placementOnlyClusters)) {
+		targetName := utils.QualifiedNameForCluster(clusterName, targetQualifiedName)
+		clusterObj, err := c.testClusters[clusterName].Client.Resources(targetName.Namespace).Get(ctx, targetName.Name, metav1.GetOptions{})
+		if err != nil {
+			c.tl.Fatalf("Error retrieving %s %q in cluster %q: %v", targetKind, targetName, clusterName, err)
+		}
+		uids[clusterName] = string(clusterObj.GetUID())
+	}
+
+	c.tl.Logf("Deleting %s %q", federatedKind, qualifiedName)
+	err = resourceClient.Resources(qualifiedName.Namespace).Delete(ctx, qualifiedName.Name, metav1.DeleteOptions{})
+	if err != nil {
+		c.tl.Fatalf("Error deleting %s %q: %v", federatedKind, qualifiedName, err)
+	}
+	err = wait.PollUntilContextTimeout(ctx, c.waitInterval, wait.ForeverTestTimeout, true, func(ctx context.Context) (bool, error) {
+		_, err := resourceClient.Resources(qualifiedName.Namespace).Get(ctx, qualifiedName.Name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	})
+	if err != nil {
+		c.tl.Fatalf("Error deleting %s %q: %v", federatedKind, qualifiedName, err)
+	}
+
+	for clusterName := range uids {
+		c.tl.Logf("Waiting for %s %q in cluster %q to be pending deletion", targetKind, targetQualifiedName, clusterName)
+		c.waitForClusterAnnotation(ctx, immediate, fedObject, clusterName, utils.DeletionPendingAnnotation, true)
+	}
+
+	c.tl.Logf("Recreating %s %q within its deletion grace period", federatedKind, qualifiedName)
+	recreatedObj := fedObject.DeepCopy()
+	recreatedObj.SetUID("")
+	recreatedObj.SetResourceVersion("")
+	recreatedObj.SetGeneration(0)
+	recreatedObj.SetCreationTimestamp(metav1.Time{})
+	recreatedObj.SetDeletionTimestamp(nil)
+	recreatedObj.SetFinalizers(nil)
+	annotations := recreatedObj.GetAnnotations()
+	delete(annotations, utils.DeletionGracePeriodAnnotation)
+	recreatedObj.SetAnnotations(annotations)
+	delete(recreatedObj.Object, utils.StatusField)
+	recreatedObj = c.createResource(apiResource, recreatedObj)
+
+	for clusterName, uid := range uids {
+		c.tl.Logf("Waiting for the deletion of %s %q in cluster %q to be cancelled", targetKind, targetQualifiedName, clusterName)
+		c.waitForClusterAnnotation(ctx, immediate, recreatedObj, clusterName, utils.DeletionPendingAnnotation, false)
+		targetName := utils.QualifiedNameForCluster(clusterName, targetQualifiedName)
+		clusterObj, err := c.testClusters[clusterName].Client.Resources(targetName.Namespace).Get(ctx, targetName.Name, metav1.GetOptions{})
+		if err != nil {
+			c.tl.Fatalf("Expected %s %q to be retained in cluster %q: %v", targetKind, targetName, clusterName, err)
+		}
+		if string(clusterObj.GetUID()) != uid {
+			c.tl.Fatalf("Expected %s %q in cluster %q to be retained rather than recreated", targetKind, targetName, clusterName)
+		}
+	}
+	return recreatedObj
+}
+
+// CheckUnplacedOverrides verifies that an override for a cluster that
+// is not selected by placement results in a warning condition that is
+// cleared once the override is removed.
+func (c *FederatedTypeCrudTester) CheckUnplacedOverrides(ctx context.Context, immediate bool, fedObject *unstructured.Unstructured) *unstructured.Unstructured {
+	apiResource := c.typeConfig.GetFederatedType()
+	kind := apiResource.Kind
+	qualifiedName := utils.NewQualifiedName(fedObject)
+	const unplacedClusterName = "crudtester-unplaced-cluster"
+
+	c.tl.Logf("Adding an override for unplaced cluster %q to %s %q", unplacedClusterName, kind, qualifiedName)
+	updatedFedObject, err := c.updateObject(ctx, apiResource, fedObject, func(obj *unstructured.Unstructured) {
+		overrides, _, err := unstructured.NestedSlice(obj.Object, utils.SpecField, utils.OverridesField)
+		if err != nil {
+			c.tl.Fatalf("Error retrieving overrides of %s %q: %v", kind, qualifiedName, err)
+		}
+		overrides = append(overrides, map[string]interface{}{
+			utils.ClusterNameField: unplacedClusterName,
+			utils.ClusterOverridesField: []interface{}{
+				map[string]interface{}{
+					utils.PathField:  "/metadata/labels/crudtester-unplaced",
+					utils.ValueField: "true",
+				},
+			},
+		})
+		if err := unstructured.SetNestedSlice(obj.Object, overrides, utils.SpecField, utils.OverridesField); err != nil {
+			c.tl.Fatalf("Error setting overrides of %s %q: %v", kind, qualifiedName, err)
+		}
+	})
+	if err != nil {
+		c.tl.Fatalf("Error updating %s %q: %v", kind, qualifiedName, err)
+	}
+
+	c.tl.Logf("Waiting for the %s condition of %s %q to indicate %s", status.OverridesPlacedConditionType, kind, qualifiedName, status.UnplacedOverrideClusters)
+	updatedFedObject, err = c.waitForCondition(ctx, immediate, updatedFedObject, status.OverridesPlacedConditionType, func(condition *status.GenericCondition) bool {
+		return condition.Status == apiv1.ConditionFalse && condition.Reason == status.UnplacedOverrideClusters &&
+			strings.Contains(condition.Message, unplacedClusterName)
+	})
+	if err != nil {
+		c.tl.Fatalf("Error waiting for %s %q to warn of unplaced override clusters: %v", kind, qualifiedName, err)
+	}
+
+	c.tl.Logf("Removing the override for unplaced cluster %q from %s %q", unplacedClusterName, kind, qualifiedName)
+	updatedFedObject, err = c.updateObject(ctx, apiResource, updatedFedObject, func(obj *unstructured.Unstructured) {
+		overrides, _, err := unstructured.NestedSlice(obj.Object, utils.SpecField, utils.OverridesField)
+		if err != nil {
+			c.tl.Fatalf("Error retrieving overrides of %s %q: %v", kind, qualifiedName, err)
+		}
+		var retainedOverrides []interface{}
+		for _, override := range overrides {
+			if clusterName, _, _ := unstructured.NestedString(override.(map[string]interface{}), utils.ClusterNameField); clusterName != unplacedClusterName {
+				retainedOverrides = append(retainedOverrides, override)
+			}
+		}
+		if err := unstructured.SetNestedSlice(obj.Object, retainedOverrides, utils.SpecField, utils.OverridesField); err != nil {
+			c.tl.Fatalf("Error setting overrides of %s %q: %v", kind, qualifiedName, err)
+		}
+	})
+	if err != nil {
+		c.tl.Fatalf("Error updating %s %q: %v", kind, qualifiedName, err)
+	}
+
+	updatedFedObject, err = c.waitForCondition(ctx, immediate, updatedFedObject, status.OverridesPlacedConditionType, func(condition *status.GenericCondition) bool {
+		return condition.Status == apiv1.ConditionTrue
+	})
+	if err != nil {
+		c.tl.Fatalf("Error waiting for the %s condition of %s %q to be cleared: %v", status.OverridesPlacedConditionType, kind, qualifiedName, err)
+	}
+
+	return updatedFedObject
+}
+
+// CheckOverrideRemoval verifies that a field set in the named cluster
+// by an override reverts to its template value once the override is
+// removed. An annotation is overridden since annotations are retained
+// from the cluster object when a resource is updated.
+func (c *FederatedTypeCrudTester) CheckOverrideRemoval(ctx context.Context, immediate bool, fedObject *unstructured.Unstructured, clusterName string) *unstructured.Unstructured {
+	apiResource := c.typeConfig.GetFederatedType()
+	kind := apiResource.Kind
+	qualifiedName := utils.NewQualifiedName(fedObject)
+	const overrideAnnotationKey = "crudtester-override"
+
+	c.tl.Logf("Adding an annotation override for cluster %q to %s %q", clusterName, kind, qualifiedName)
+	updatedFedObject, err := c.updateObject(ctx, apiResource, fedObject, func(obj *unstructured.Unstructured) {
+		overrides, err := utils.GetOverrides(obj)
+		if err != nil {
+			c.tl.Fatalf("Error retrieving overrides of %s %q: %v", kind, qualifiedName, err)
+		}
+		overrides[clusterName] = append(overrides[clusterName], utils.ClusterOverride{
+			Op:    "add",
+			Path:  "/metadata/annotations",
+			Value: map[string]interface{}{overrideAnnotationKey: "true"},
+		})
+		if err := utils.SetOverrides(obj, overrides); err != nil {
+			c.tl.Fatalf("Error setting overrides of %s %q: %v", kind, qualifiedName, err)
+		}
+	})
+	if err != nil {
+		c.tl.Fatalf("Error updating %s %q: %v", kind, qualifiedName, err)
+	}
+	c.waitForClusterAnnotation(ctx, immediate, updatedFedObject, clusterName, overrideAnnotationKey, true)
+
+	c.tl.Logf("Removing the annotation override for cluster %q from %s %q", clusterName, kind, qualifiedName)
+	updatedFedObject, err = c.updateObject(ctx, apiResource, updatedFedObject, func(obj *unstructured.Unstructured) {
+		overrides, err := utils.GetOverrides(obj)
+		if err != nil {
+			c.tl.Fatalf("Error retrieving overrides of %s %q: %v", kind, qualifiedName, err)
+		}
+		var retainedOverrides utils.ClusterOverrides
+		for _, override := range overrides[clusterName] {
+			if override.Path != "/metadata/annotations" {
+				retainedOverrides = append(retainedOverrides, override)
+			}
+		}
+		overrides[clusterName] = retainedOverrides
+		if err := utils.SetOverrides(obj, overrides); err != nil {
+			c.tl.Fatalf("Error setting overrides of %s %q: %v", kind, qualifiedName, err)
+		}
+	})
+	if err != nil {
+		c.tl.Fatalf("Error updating %s %q: %v", kind, qualifiedName, err)
+	}
+	c.waitForClusterAnnotation(ctx, immediate, updatedFedObject, clusterName, overrideAnnotationKey, false)
+
+	return updatedFedObject
+}
+
+// CheckTemplatedOverrides verifies that a templated override of the
+// given federated resource is evaluated against the parameters of each
+// of the two named clusters, in which the resource is expected to be
+// placed, so that the resources managed in the clusters diverge. The
+// override and the parameters are removed before returning.
+func (c *FederatedTypeCrudTester) CheckTemplatedOverrides(ctx context.Context, immediate bool, fedObject *unstructured.Unstructured, firstClusterName, secondClusterName string) *unstructured.Unstructured {
+	apiResource := c.typeConfig.GetFederatedType()
+	kind := apiResource.Kind
+	qualifiedName := utils.NewQualifiedName(fedObject)
+	const endpointAnnotationKey = "crudtester-endpoint"
+	clusterParameters := map[string]map[string]string{
+		firstClusterName:  {"dbHost": "db.first.example.com"},
+		secondClusterName: {"dbHost": "db.second.example.com"},
+	}
+
+	for clusterName, parameters := range clusterParameters {
+		c.tl.Logf("Setting the parameters of cluster %q to %v", clusterName, parameters)
+		c.setClusterParameters(ctx, immediate, clusterName, parameters)
+	}
+
+	c.tl.Logf("Adding templated overrides to %s %q", kind, qualifiedName)
+	updatedFedObject, err := c.updateObject(ctx, apiResource, fedObject, func(obj *unstructured.Unstructured) {
+		overrides, err := utils.GetOverrides(obj)
+		if err != nil {
+			c.tl.Fatalf("Error retrieving overrides of %s %q: %v", kind, qualifiedName, err)
+		}
+		for clusterName := range clusterParameters {
+			overrides[clusterName] = append(overrides[clusterName], utils.ClusterOverride{
+				Op:       "add",
+				Path:     "/metadata/annotations",
+				Value:    map[string]interface{}{endpointAnnotationKey: "{{ .Params.dbHost }}:5432"},
+				Template: true,
+			})
+		}
+		if err := utils.SetOverrides(obj, overrides); err != nil {
+			c.tl.Fatalf("Error setting overrides of %s %q: %v", kind, qualifiedName, err)
+		}
+	})
+	if err != nil {
+		c.tl.Fatalf("Error updating %s %q: %v", kind, qualifiedName, err)
+	}
+	c.CheckPropagation(ctx, immediate, updatedFedObject)
+
+	targetKind := c.typeConfig.GetTargetType().Kind
+	for clusterName, parameters := range clusterParameters {
+		targetName := utils.QualifiedNameForCluster(clusterName, c.targetName(updatedFedObject))
+		clusterObj, err := c.testClusters[clusterName].Client.Resources(targetName.Namespace).Get(ctx, targetName.Name, metav1.GetOptions{})
+		if err != nil {
+			c.tl.Fatalf("Error retrieving %s %q in cluster %q: %v", targetKind, targetName, clusterName, err)
+		}
+		expectedEndpoint := parameters["dbHost"] + ":5432"
+		if endpoint := clusterObj.GetAnnotations()[endpointAnnotationKey]; endpoint != expectedEndpoint {
+			c.tl.Fatalf("Expected annotation %q of %s %q in cluster %q to be %q, got %q", endpointAnnotationKey, targetKind, targetName, clusterName, expectedEndpoint, endpoint)
+		}
+	}
+
+	c.tl.Logf("Removing the templated overrides from %s %q", kind, qualifiedName)
+	updatedFedObject, err = c.updateObject(ctx, apiResource, updatedFedObject, func(obj *unstructured.Unstructured) {
+		overrides, err := utils.GetOverrides(obj)
+		if err != nil {
+			c.tl.Fatalf("Error retrieving overrides of %s %q: %v", kind, qualifiedName, err)
+		}
+		for clusterName := range clusterParameters {
+			var retainedOverrides utils.ClusterOverrides
+			for _, override := range overrides[clusterName] {
+				if !override.Template {
+					retainedOverrides = append(retainedOverrides, override)
+				}
+			}
+			overrides[clusterName] = retainedOverrides
+		}
+		if err := utils.SetOverrides(obj, overrides); err != nil {
+			c.tl.Fatalf("Error setting overrides of %s %q: %v", kind, qualifiedName, err)
+		}
+	})
+	if err != nil {
+		c.tl.Fatalf("Error updating %s %q: %v", kind, qualifiedName, err)
+	}
+	for clusterName := range clusterParameters {
+		c.waitForClusterAnnotation(ctx, immediate, updatedFedObject, clusterName, endpointAnnotationKey, false)
+	}
+
+	for clusterName := range clusterParameters {
+		c.tl.Logf("Removing the parameters of cluster %q", clusterName)
+		c.setClusterParameters(ctx, immediate, clusterName, nil)
+	}
+	return updatedFedObject
+}
+
+// waitForClusterAnnotation waits for the resource of the given
+// federated resource in the named cluster to have or not have the
+// given annotation.
+func (c *FederatedTypeCrudTester) waitForClusterAnnotation(ctx context.Context, immediate bool, fedObject *unstructured.Unstructured, clusterName, key string, expected bool) {
+	targetKind := c.typeConfig.GetTargetType().Kind
+	targetName := utils.QualifiedNameForCluster(clusterName, c.targetName(fedObject))
+	client := c.testClusters[clusterName].Client
+	err := wait.PollUntilContextTimeout(ctx, c.waitInterval, c.clusterWaitTimeout, immediate, func(ctx context.Context) (bool, error) {
+		clusterObj, err := client.Resources(targetName.Namespace).Get(ctx, targetName.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, nil
+		}
+		_, ok := clusterObj.GetAnnotations()[key]
+		return ok == expected, nil
+	})
+	if err != nil {
+		c.tl.Fatalf("Error waiting for the presence of annotation %q on %s %q in cluster %q to be %v: %v", key, targetKind, targetName, clusterName, expected, err)
+	}
+}
+
+// CheckClusterMetadataMerge verifies that a label and an annotation
+// added to the resource in the named cluster survive the propagation
+// of an update that declares a label and an annotation, and that only
+// the declared ones are removed once they are no longer declared.
+// Requires the MetadataMerge feature to be enabled for the type.
+func (c *FederatedTypeCrudTester) CheckClusterMetadataMerge(ctx context.Context, immediate bool, fedObject *unstructured.Unstructured, clusterName string) *unstructured.Unstructured {
+	apiResource := c.typeConfig.GetFederatedType()
+	kind := apiResource.Kind
+	qualifiedName := utils.NewQualifiedName(fedObject)
+	targetKind := c.typeConfig.GetTargetType().Kind
+	const addedKey = "crudtester-added"
+	const declaredKey = "crudtester-declared"
+	declaredPath := "/metadata/annotations/" + declaredKey
+
+	targetName := utils.QualifiedNameForCluster(clusterName, c.targetName(fedObject))
+	c.tl.Logf("Adding label and annotation %q to %s %q in cluster %q", addedKey, targetKind, targetName, clusterName)
+	client := c.testClusters[clusterName].Client
+	err := wait.PollUntilContextTimeout(ctx, c.waitInterval, wait.ForeverTestTimeout, immediate, func(ctx context.Context) (bool, error) {
+		clusterObj, err := client.Resources(targetName.Namespace).Get(ctx, targetName.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		labels := clusterObj.GetLabels()
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		labels[addedKey] = "true"
+		clusterObj.SetLabels(labels)
+		annotations := clusterObj.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[addedKey] = "true"
+		clusterObj.SetAnnotations(annotations)
+		_, err = client.Resources(targetName.Namespace).Update(ctx, clusterObj, metav1.UpdateOptions{})
+		if apierrors.IsConflict(err) {
+			return false, nil
+		}
+		return err == nil, err
+	})
+	if err != nil {
+		c.tl.Fatalf("Error modifying %s %q in cluster %q: %v", targetKind, targetName, clusterName, err)
+	}
+
+	c.tl.Logf("Declaring label and annotation %q for cluster %q in %s %q", declaredKey, clusterName, kind, qualifiedName)
+	updatedFedObject, err := c.updateObject(ctx, apiResource, fedObject, func(obj *unstructured.Unstructured) {
+		err := unstructured.SetNestedField(obj.Object, "true", utils.SpecField, utils.TemplateField, "metadata", "labels", declaredKey)
+		if err != nil {
+			c.tl.Fatalf("Error setting template label of %s %q: %v", kind, qualifiedName, err)
+		}
+		overrides, err := utils.GetOverrides(obj)
+		if err != nil {
+			c.tl.Fatalf("Error retrieving overrides of %s %q: %v", kind, qualifiedName, err)
+		}
+		overrides[clusterName] = append(overrides[clusterName], utils.ClusterOverride{
+			Op:    "add",
+			Path:  declaredPath,
+			Value: "true",
+		})
+		if err := utils.SetOverrides(obj, overrides); err != nil {
+			c.tl.Fatalf("Error setting overrides of %s %q: %v", kind, qualifiedName, err)
+		}
+	})
+	if err != nil {
+		c.tl.Fatalf("Error updating %s %q: %v", kind, qualifiedName, err)
+	}
+	c.CheckPropagation(ctx, immediate, updatedFedObject)
+	c.waitForClusterMetadata(ctx, immediate, updatedFedObject, clusterName, "added and declared metadata", func(labels, annotations map[string]string) bool {
+		return labels[addedKey] == "true" && annotations[addedKey] == "true" &&
+			labels[declaredKey] == "true" && annotations[declaredKey] == "true"
+	})
+
+	c.tl.Logf("Removing label and annotation %q for cluster %q from %s %q", declaredKey, clusterName, kind, qualifiedName)
+	updatedFedObject, err = c.updateObject(ctx, apiResource, updatedFedObject, func(obj *unstructured.Unstructured) {
+		unstructured.RemoveNestedField(obj.Object, utils.SpecField, utils.TemplateField, "metadata", "labels", declaredKey)
+		overrides, err := utils.GetOverrides(obj)
+		if err != nil {
+			c.tl.Fatalf("Error retrieving overrides of %s %q: %v", kind, qualifiedName, err)
+		}
+		var retainedOverrides utils.ClusterOverrides
+		for _, override := range overrides[clusterName] {
+			if override.Path != declaredPath {
+				retainedOverrides = append(retainedOverrides, override)
+			}
+		}
+		overrides[clusterName] = retainedOverrides
+		if err := utils.SetOverrides(obj, overrides); err != nil {
+			c.tl.Fatalf("Error setting overrides of %s %q: %v", kind, qualifiedName, err)
+		}
+	})
+	if err != nil {
+		c.tl.Fatalf("Error updating %s %q: %v", kind, qualifiedName, err)
+	}
+	c.CheckPropagation(ctx, immediate, updatedFedObject)
+	c.waitForClusterMetadata(ctx, immediate, updatedFedObject, clusterName, "added metadata only", func(labels, annotations map[string]string) bool {
+		_, labelDeclared := labels[declaredKey]
+		_, annotationDeclared := annotations[declaredKey]
+		return labels[addedKey] == "true" && annotations[addedKey] == "true" &&
+			!labelDeclared && !annotationDeclared
+	})
+
+	return updatedFedObject
+}
+
+// waitForClusterMetadata waits for the labels and annotations of the
+// resource in the named cluster to satisfy the given function.
+func (c *FederatedTypeCrudTester) waitForClusterMetadata(ctx context.Context, immediate bool, fedObject *unstructured.Unstructured, clusterName, description string, metadataFunc func(labels, annotations map[string]string) bool) {
+	targetKind := c.typeConfig.GetTargetType().Kind
+	targetName := utils.QualifiedNameForCluster(clusterName, c.targetName(fedObject))
+	client := c.testClusters[clusterName].Client
+	err := wait.PollUntilContextTimeout(ctx, c.waitInterval, c.clusterWaitTimeout, immediate, func(ctx context.Context) (bool, error) {
+		clusterObj, err := client.Resources(targetName.Namespace).Get(ctx, targetName.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, nil
+		}
+		return metadataFunc(clusterObj.GetLabels(), clusterObj.GetAnnotations()), nil
+	})
+	if err != nil {
+		c.tl.Fatalf("Error waiting for %s on %s %q in cluster %q: %v", description, targetKind, targetName, clusterName, err)
+	}
+}
+
+// CheckMaintenance verifies that an update of the federated resource
+// is not propagated to a cluster in maintenance, and that propagation
+// resumes once maintenance is ended.
+func (c *FederatedTypeCrudTester) CheckMaintenance(ctx context.Context, immediate bool, fedObject *unstructured.Unstructured, clusterName string) *unstructured.Unstructured {
+	apiResource := c.typeConfig.GetFederatedType()
+	kind := apiResource.Kind
+	qualifiedName := utils.NewQualifiedName(fedObject)
+	targetKind := c.typeConfig.GetTargetType().Kind
+	const maintenanceLabelKey = "crudtester-maintenance"
+
+	c.tl.Logf("Starting maintenance of cluster %q", clusterName)
+	c.setClusterMaintenance(ctx, immediate, clusterName, true)
+
+	c.tl.Logf("Updating the template of %s %q", kind, qualifiedName)
+	updatedFedObject, err := c.updateObject(ctx, apiResource, fedObject, func(obj *unstructured.Unstructured) {
+		err := unstructured.SetNestedField(obj.Object, "true", utils.SpecField, utils.TemplateField, "metadata", "labels", maintenanceLabelKey)
+		if err != nil {
+			c.tl.Fatalf("Error setting template label of %s %q: %v", kind, qualifiedName, err)
+		}
+	})
+	if err != nil {
+		c.tl.Fatalf("Error updating %s %q: %v", kind, qualifiedName, err)
+	}
+
+	c.tl.Logf("Waiting for %s %q to have %s status for cluster %q", kind, qualifiedName, status.Maintenance, clusterName)
+	err = wait.PollUntilContextTimeout(ctx, c.waitInterval, wait.ForeverTestTimeout, immediate, func(ctx context.Context) (bool, error) {
+		resource, err := GetGenericResource(c.client, updatedFedObject.GroupVersionKind(), qualifiedName)
+		if err != nil {
+			return false, err
+		}
+		if resource.Status == nil || resource.Status.ObservedGeneration != updatedFedObject.GetGeneration() {
+			return false, nil
+		}
+		for _, cluster := range resource.Status.Clusters {
+			if cluster.Name == clusterName {
+				return cluster.Status == status.Maintenance, nil
+			}
+		}
+		return false, nil
+	})
+	if err != nil {
+		c.tl.Fatalf("Error waiting for %s %q to have %s status for cluster %q: %v", kind, qualifiedName, status.Maintenance, clusterName, err)
+	}
+
+	targetName := utils.QualifiedNameForCluster(clusterName, c.targetName(updatedFedObject))
+	clusterObj, err := c.testClusters[clusterName].Client.Resources(targetName.Namespace).Get(ctx, targetName.Name, metav1.GetOptions{})
+	if err != nil {
+		c.tl.Fatalf("Expected %s %q to be retained in cluster %q in maintenance: %v", targetKind, targetName, clusterName, err)
+	}
+	if _, ok := clusterObj.GetLabels()[maintenanceLabelKey]; ok {
+		c.tl.Fatalf("Expected the update of %s %q not to be propagated to cluster %q in maintenance", kind, qualifiedName, clusterName)
+	}
+
+	c.tl.Logf("Ending maintenance of cluster %q", clusterName)
+	c.setClusterMaintenance(ctx, immediate, clusterName, false)
+
+	c.CheckPropagation(ctx, immediate, updatedFedObject)
+	return updatedFedObject
+}
+
+// CheckDriftDetection verifies that an out-of-band modification of the
+// resource in the named cluster is reported by the DriftDetected
+// condition of the cluster, and that the condition is cleared once the
+// desired content is propagated again. The cluster is kept in
+// maintenance while it is modified so that reconciliation does not
+// correct the modification before drift detection observes it. Drift
+// detection is expected to be enabled for the type.
+func (c *FederatedTypeCrudTester) CheckDriftDetection(ctx context.Context, immediate bool, fedObject *unstructured.Unstructured, clusterName string) *unstructured.Unstructured {
+	apiResource := c.typeConfig.GetFederatedType()
+	kind := apiResource.Kind
+	qualifiedName := utils.NewQualifiedName(fedObject)
+	targetKind := c.typeConfig.GetTargetType().Kind
+	const driftLabelKey = "crudtester-drift"
+	driftedPath := "~/metadata/labels/" + driftLabelKey
+
+	c.tl.Logf("Adding label %q to the template of %s %q", driftLabelKey, kind, qualifiedName)
+	updatedFedObject, err := c.updateObject(ctx, apiResource, fedObject, func(obj *unstructured.Unstructured) {
+		err := unstructured.SetNestedField(obj.Object, "expected", utils.SpecField, utils.TemplateField, "metadata", "labels", driftLabelKey)
+		if err != nil {
+			c.tl.Fatalf("Error setting template label of %s %q: %v", kind, qualifiedName, err)
+		}
+	})
+	if err != nil {
+		c.tl.Fatalf("Error updating %s %q: %v", kind, qualifiedName, err)
+	}
+	c.CheckPropagation(ctx, immediate, updatedFedObject)
+
+	c.tl.Logf("Starting maintenance of cluster %q", clusterName)
+	c.setClusterMaintenance(ctx, immediate, clusterName, true)
+
+	targetName := utils.QualifiedNameForCluster(clusterName, c.targetName(updatedFedObject))
+	c.tl.Logf("Modifying %s %q in cluster %q out-of-band", targetKind, targetName, clusterName)
+	client := c.testClusters[clusterName].Client
+	err = wait.PollUntilContextTimeout(ctx, c.waitInterval, wait.ForeverTestTimeout, immediate, func(ctx context.Context) (bool, error) {
+		clusterObj, err := client.Resources(targetName.Namespace).Get(ctx, targetName.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		labels := clusterObj.GetLabels()
+		labels[driftLabelKey] = "modified"
+		clusterObj.SetLabels(labels)
+		_, err = client.Resources(targetName.Namespace).Update(ctx, clusterObj, metav1.UpdateOptions{})
+		if apierrors.IsConflict(err) {
+			return false, nil
+		}
+		return err == nil, err
+	})
+	if err != nil {
+		c.tl.Fatalf("Error modifying %s %q in cluster %q: %v", targetKind, targetName, clusterName, err)
+	}
+
+	c.tl.Logf("Waiting for drift of %s %q to be detected in cluster %q", kind, qualifiedName, clusterName)
+	c.waitForClusterDriftCondition(ctx, immediate, updatedFedObject, clusterName, func(condition *status.GenericCondition) bool {
+		return condition != nil && condition.Status == apiv1.ConditionTrue && strings.Contains(condition.Message, driftedPath)
+	})
+
+	c.tl.Logf("Ending maintenance of cluster %q", clusterName)
+	c.setClusterMaintenance(ctx, immediate, clusterName, false)
+
+	c.tl.Logf("Removing label %q from the template of %s %q", driftLabelKey, kind, qualifiedName)
+	updatedFedObject, err = c.updateObject(ctx, apiResource, updatedFedObject, func(obj *unstructured.Unstructured) {
+		unstructured.RemoveNestedField(obj.Object, utils.SpecField, utils.TemplateField, "metadata", "labels", driftLabelKey)
+	})
+	if err != nil {
+		c.tl.Fatalf("Error updating %s %q: %v", kind, qualifiedName, err)
+	}
+	c.CheckPropagation(ctx, immediate, updatedFedObject)
+
+	c.tl.Logf("Waiting for drift of %s %q to be cleared in cluster %q", kind, qualifiedName, clusterName)
+	c.waitForClusterDriftCondition(ctx, immediate, updatedFedObject, clusterName, func(condition *status.GenericCondition) bool {
+		return condition == nil || condition.Status != apiv1.ConditionTrue
+	})
+	return updatedFedObject
+}
+
+// waitForClusterDriftCondition waits for the DriftDetected condition
+// of the named cluster, which is nil if the cluster has no such
+// condition, to satisfy the given function.
+func (c *FederatedTypeCrudTester) waitForClusterDriftCondition(ctx context.Context, immediate bool, fedObject *unstructured.Unstructured, clusterName string, conditionFunc func(*status.GenericCondition) bool) {
+	kind := fedObject.GetKind()
+	qualifiedName := utils.NewQualifiedName(fedObject)
+	err := wait.PollUntilContextTimeout(ctx, c.waitInterval, wait.ForeverTestTimeout, immediate, func(ctx context.Context) (bool, error) {
+		resource, err := GetGenericResource(c.client, fedObject.GroupVersionKind(), qualifiedName)
+		if err != nil {
+			return false, err
+		}
+		if resource.Status == nil {
+			return false, nil
+		}
+		for _, cluster := range resource.Status.Clusters {
+			if cluster.Name != clusterName {
+				continue
+			}
+			var driftCondition *status.GenericCondition
+			for _, condition := range cluster.Conditions {
+				if condition.Type == status.DriftDetectedConditionType {
+					driftCondition = condition
+				}
+			}
+			return conditionFunc(driftCondition), nil
+		}
+		return false, nil
+	})
+	if err != nil {
+		c.tl.Fatalf("Error waiting for the %s condition of %s %q in cluster %q: %v", status.DriftDetectedConditionType, kind, qualifiedName, clusterName, err)
+	}
+}
+
+// CheckClusterTaint verifies that placement honours the taints of the
+// named cluster, in which the given federated resource is expected to
+// be placed. A NoSchedule taint is expected to retain the existing
+// placement of the given federated resource in the cluster, a
+// NoExecute taint to remove the resource from the cluster, and a
+// matching toleration to place the resource in the cluster again. The
+// taint is removed before returning while the toleration is retained.
+func (c *FederatedTypeCrudTester) CheckClusterTaint(ctx context.Context, immediate bool, fedObject *unstructured.Unstructured, clusterName string) *unstructured.Unstructured {
+	apiResource := c.typeConfig.GetFederatedType()
+	kind := apiResource.Kind
+	qualifiedName := utils.NewQualifiedName(fedObject)
+	const taintKey = "crudtester-taint"
+	const taintLabelKey = "crudtester-taint-effect"
+
+	updatedFedObject := fedObject
+	for _, effect := range []apiv1.TaintEffect{apiv1.TaintEffectNoSchedule, apiv1.TaintEffectNoExecute} {
+		c.tl.Logf("Tainting cluster %q with effect %s", clusterName, effect)
+		c.setClusterTaints(ctx, immediate, clusterName, []apiv1.Taint{{Key: taintKey, Effect: effect}})
+
+		// The template is updated so that the federated resource is
+		// reconciled with the taint.
+		c.tl.Logf("Updating the template of %s %q", kind, qualifiedName)
+		var err error
+		updatedFedObject, err = c.updateObject(ctx, apiResource, updatedFedObject, func(obj *unstructured.Unstructured) {
+			err := unstructured.SetNestedField(obj.Object, string(effect), utils.SpecField, utils.TemplateField, "metadata", "labels", taintLabelKey)
+			if err != nil {
+				c.tl.Fatalf("Error setting template label of %s %q: %v", kind, qualifiedName, err)
+			}
+		})
+		if err != nil {
+			c.tl.Fatalf("Error updating %s %q: %v", kind, qualifiedName, err)
+		}
+
+		selectedClusters, err := utils.ComputePlacement(updatedFedObject, c.getClusters(), false)
+		if err != nil {
+			c.tl.Fatalf("Error computing placement of %s %q: %v", kind, qualifiedName, err)
+		}
+		switch placed := selectedClusters.Has(clusterName); {
+		case effect == apiv1.TaintEffectNoSchedule && !placed:
+			c.tl.Fatalf("Expected the placement of %s %q in cluster %q to be retained with a %s taint", kind, qualifiedName, clusterName, effect)
+		case effect == apiv1.TaintEffectNoExecute && placed:
+			c.tl.Fatalf("Expected the placement of %s %q in cluster %q to be removed with a %s taint", kind, qualifiedName, clusterName, effect)
+		}
+		c.CheckPropagation(ctx, immediate, updatedFedObject)
+	}
+
+	c.tl.Logf("Tolerating the taint of cluster %q for %s %q", clusterName, kind, qualifiedName)
+	updatedFedObject, err := c.updateObject(ctx, apiResource, updatedFedObject, func(obj *unstructured.Unstructured) {
+		tolerations := []apiv1.Toleration{{Key: taintKey, Operator: apiv1.TolerationOpExists}}
+		if err := utils.SetTolerations(obj, tolerations); err != nil {
+			c.tl.Fatalf("Error setting tolerations of %s %q: %v", kind, qualifiedName, err)
+		}
+	})
+	if err != nil {
+		c.tl.Fatalf("Error updating %s %q: %v", kind, qualifiedName, err)
+	}
+	c.CheckPropagation(ctx, immediate, updatedFedObject)
+
+	c.tl.Logf("Removing the taint of cluster %q", clusterName)
+	c.setClusterTaints(ctx, immediate, clusterName, nil)
+	return updatedFedObject
+}
+
+// CheckClusterSelectors verifies that a federated resource placed by
+// multiple cluster selectors is placed in the union of the clusters
+// matching any of them. Each of the two named clusters is labeled to
+// match a different selector, and the clusters of the placement are
+// emptied so that only the selectors determine placement. The labels
+// are removed and the clusters of the placement restored before
+// returning.
+func (c *FederatedTypeCrudTester) CheckClusterSelectors(ctx context.Context, immediate bool, fedObject *unstructured.Unstructured, firstClusterName, secondClusterName string) *unstructured.Unstructured {
+	apiResource := c.typeConfig.GetFederatedType()
+	kind := apiResource.Kind
+	qualifiedName := utils.NewQualifiedName(fedObject)
+	clusterLabels := map[string]map[string]string{
+		firstClusterName:  {"crudtester-region": "us"},
+		secondClusterName: {"crudtester-tier": "edge"},
+	}
+
+	clusterNames, err := utils.GetClusterNames(fedObject)
+	if err != nil {
+		c.tl.Fatalf("Error retrieving cluster names for %s %q: %v", kind, qualifiedName, err)
+	}
+
+	for clusterName, selectorLabels := range clusterLabels {
+		c.tl.Logf("Labeling cluster %q with %v", clusterName, selectorLabels)
+		c.setClusterLabels(ctx, immediate, clusterName, selectorLabels)
+	}
+
+	c.tl.Logf("Placing %s %q by multiple cluster selectors", kind, qualifiedName)
+	updatedFedObject, err := c.updateObject(ctx, apiResource, fedObject, func(obj *unstructured.Unstructured) {
+		if err := utils.SetClusterNames(obj, []string{}); err != nil {
+			c.tl.Fatalf("Error setting cluster names for %s %q: %v", kind, qualifiedName, err)
+		}
+		selectors := []map[string]string{clusterLabels[firstClusterName], clusterLabels[secondClusterName]}
+		if err := utils.SetClusterSelectors(obj, selectors); err != nil {
+			c.tl.Fatalf("Error setting cluster selectors for %s %q: %v", kind, qualifiedName, err)
+		}
+	})
+	if err != nil {
+		c.tl.Fatalf("Error updating %s %q: %v", kind, qualifiedName, err)
+	}
+
+	selectedClusters, err := utils.ComputePlacement(updatedFedObject, c.getClusters(), false)
+	if err != nil {
+		c.tl.Fatalf("Error computing placement of %s %q: %v", kind, qualifiedName, err)
+	}
+	if expectedClusters := sets.New(firstClusterName, secondClusterName); !selectedClusters.Equal(expectedClusters) {
+		c.tl.Fatalf("Expected %s %q to be placed in clusters %v, got %v", kind, qualifiedName, sets.List(expectedClusters), sets.List(selectedClusters))
+	}
+	c.CheckPropagation(ctx, immediate, updatedFedObject)
+
+	c.tl.Logf("Restoring the placement of %s %q", kind, qualifiedName)
+	updatedFedObject, err = c.updateObject(ctx, apiResource, updatedFedObject, func(obj *unstructured.Unstructured) {
+		if err := utils.SetClusterNames(obj, clusterNames); err != nil {
+			c.tl.Fatalf("Error setting cluster names for %s %q: %v", kind, qualifiedName, err)
+		}
+		if err := utils.SetClusterSelectors(obj, nil); err != nil {
+			c.tl.Fatalf("Error removing cluster selectors for %s %q: %v", kind, qualifiedName, err)
+		}
+	})
+	if err != nil {
+		c.tl.Fatalf("Error updating %s %q: %v", kind, qualifiedName, err)
+	}
+	c.CheckPropagation(ctx, immediate, updatedFedObject)
+
+	for clusterName := range clusterLabels {
+		c.tl.Logf("Removing the labels of cluster %q", clusterName)
+		c.setClusterLabels(ctx, immediate, clusterName, nil)
+	}
+	return updatedFedObject
+}
+
+// CheckPlacementStickiness verifies that the named cluster remains
+// placed for the given stickiness after it is no longer selected by the
+// placement of the given federated object, that its resource is kept
+// rather than recreated if the cluster is selected again within that
+// time, and that the resource is removed once the stickiness has
+// elapsed.
+func (c *FederatedTypeCrudTester) CheckPlacementStickiness(ctx context.Context, immediate bool, fedObject *unstructured.Unstructured, clusterName string, stickiness time.Duration) *unstructured.Unstructured {
+	apiResource := c.typeConfig.GetFederatedType()
+	kind := apiResource.Kind
+	qualifiedName := utils.NewQualifiedName(fedObject)
+	selectorLabels := map[string]string{"crudtester-sticky": "true"}
+
+	clusterNames, err := utils.GetClusterNames(fedObject)
+	if err != nil {
+		c.tl.Fatalf("Error retrieving cluster names for %s %q: %v", kind, qualifiedName, err)
+	}
+
+	for testClusterName := range c.testClusters {
+		c.tl.Logf("Labeling cluster %q with %v", testClusterName, selectorLabels)
+		c.setClusterLabels(ctx, immediate, testClusterName, selectorLabels)
+	}
+
+	c.tl.Logf("Placing %s %q by cluster selector with a stickiness of %v", kind, qualifiedName, stickiness)
+	updatedFedObject, err := c.updateObject(ctx, apiResource, fedObject, func(obj *unstructured.Unstructured) {
+		if err := utils.SetClusterNames(obj, []string{}); err != nil {
+			c.tl.Fatalf("Error setting cluster names for %s %q: %v", kind, qualifiedName, err)
+		}
+		if err := utils.SetClusterSelectors(obj, []map[string]string{selectorLabels}); err != nil {
+			c.tl.Fatalf("Error setting cluster selectors for %s %q: %v", kind, qualifiedName, err)
+		}
+		if err := utils.SetPlacementStickiness(obj, ptr.To(int64(stickiness/time.Second))); err != nil {
+			c.tl.Fatalf("Error setting placement stickiness for %s %q: %v", kind, qualifiedName, err)
+		}
+	})
+	if err != nil {
+		c.tl.Fatalf("Error updating %s %q: %v", kind, qualifiedName, err)
+	}
+	c.CheckPropagation(ctx, immediate, updatedFedObject)
+	allClusters := sets.KeySet(c.testClusters)
+	versions := c.clusterObjectVersions(ctx, updatedFedObject, []string{clusterName})
+
+	c.tl.Logf("Removing the labels of cluster %q", clusterName)
+	c.setClusterLabels(ctx, immediate, clusterName, nil)
+	c.waitForRetention(ctx, immediate, updatedFedObject, clusterName, true)
+	c.checkPropagationToClusters(ctx, immediate, updatedFedObject, allClusters)
+
+	c.tl.Logf("Labeling cluster %q with %v again", clusterName, selectorLabels)
+	c.setClusterLabels(ctx, immediate, clusterName, selectorLabels)
+	c.waitForRetention(ctx, immediate, updatedFedObject, clusterName, false)
+	c.checkPropagationToClusters(ctx, immediate, updatedFedObject, allClusters)
+	c.checkClusterObjectVersions(ctx, updatedFedObject, versions, "retained")
+
+	c.tl.Logf("Removing the labels of cluster %q until its stickiness elapses", clusterName)
+	unlabeled := time.Now()
+	c.setClusterLabels(ctx, immediate, clusterName, nil)
+	c.waitForRetention(ctx, immediate, updatedFedObject, clusterName, true)
+	c.checkPropagationToClusters(ctx, immediate, updatedFedObject, allClusters.Clone().Delete(clusterName))
+	// The retention is recorded with a precision of a second.
+	if elapsed := time.Since(unlabeled); elapsed < stickiness-time.Second {
+		c.tl.Fatalf("Expected %s %q to be removed from cluster %q no earlier than %v after the cluster was no longer selected, but it was removed after %v",
+			c.typeConfig.GetTargetType().Kind, c.targetName(updatedFedObject), clusterName, stickiness, elapsed)
+	}
+
+	c.tl.Logf("Restoring the placement of %s %q", kind, qualifiedName)
+	updatedFedObject, err = c.updateObject(ctx, apiResource, updatedFedObject, func(obj *unstructured.Unstructured) {
+		if err := utils.SetClusterNames(obj, clusterNames); err != nil {
+			c.tl.Fatalf("Error setting cluster names for %s %q: %v", kind, qualifiedName, err)
+		}
+		if err := utils.SetClusterSelectors(obj, nil); err != nil {
+			c.tl.Fatalf("Error removing cluster selectors for %s %q: %v", kind, qualifiedName, err)
+		}
+		if err := utils.SetPlacementStickiness(obj, nil); err != nil {
+			c.tl.Fatalf("Error removing placement stickiness for %s %q: %v", kind, qualifiedName, err)
+		}
+	})
+	if err != nil {
+		c.tl.Fatalf("Error updating %s %q: %v", kind, qualifiedName, err)
+	}
+	c.CheckPropagation(ctx, immediate, updatedFedObject)
+
+	for testClusterName := range c.testClusters {
+		c.tl.Logf("Removing the labels of cluster %q", testClusterName)
+		c.setClusterLabels(ctx, immediate, testClusterName, nil)
+	}
+	return updatedFedObject
+}
+
+// waitForRetention waits for the federated status of the given object
+// to report whether the named cluster is retained by the stickiness of
+// placement.
+func (c *FederatedTypeCrudTester) waitForRetention(ctx context.Context, immediate bool, fedObject *unstructured.Unstructured, clusterName string, retained bool) {
+	kind := c.typeConfig.GetFederatedType().Kind
+	qualifiedName := utils.NewQualifiedName(fedObject)
+	err := wait.PollUntilContextTimeout(ctx, c.waitInterval, c.clusterWaitTimeout, immediate, func(ctx context.Context) (bool, error) {
+		resource, err := GetGenericResource(c.client, fedObject.GroupVersionKind(), qualifiedName)
+		if err != nil || resource.Status == nil {
+			return false, nil
+		}
+		for _, cluster := range resource.Status.Clusters {
+			if cluster.Name == clusterName {
+				return (len(cluster.RetainedUntil) > 0) == retained, nil
+			}
+		}
+		return false, nil
+	})
+	if err != nil {
+		c.tl.Fatalf("Timed out waiting for the status of %s %q to report cluster %q as retained=%t: %v", kind, qualifiedName, clusterName, retained, err)
+	}
+}
+
+// CheckClusterRemoval verifies that deletion of the KubeFedCluster of
+// the named cluster, to which the given federated object is expected
+// to be propagated, removes the cluster from the federated status and
+// the propagated version of the object. The cluster is expected to be
+// pruned from the clusters listed in the placement of the object if
+// pruned is true, and to remain listed otherwise. The cluster is not
+// joined again and is no longer a test cluster once removed.
+func (c *FederatedTypeCrudTester) CheckClusterRemoval(ctx context.Context, immediate bool, fedObject *unstructured.Unstructured, clusterName string, pruned bool) *unstructured.Unstructured {
+	kind := c.typeConfig.GetFederatedType().Kind
+	qualifiedName := utils.NewQualifiedName(fedObject)
+	versionName := PropagatedVersionQualifiedName(c.typeConfig, qualifiedName)
+	adapter := versionmanager.NewVersionAdapter(c.typeConfig.GetFederatedNamespaced())
+
+	c.tl.Logf("Deleting cluster %q", clusterName)
+	cluster := &v1beta1.KubeFedCluster{}
+	if err := c.client.Get(ctx, cluster, c.clustersNamespace, clusterName); err != nil {
+		c.tl.Fatalf("Error retrieving cluster %q: %v", clusterName, err)
+	}
+	if err := c.client.Delete(ctx, cluster, c.clustersNamespace, clusterName); err != nil {
+		c.tl.Fatalf("Error deleting cluster %q: %v", clusterName, err)
+	}
+	delete(c.testClusters, clusterName)
+
+	var reference string
+	updatedFedObject := fedObject
+	err := wait.PollUntilContextTimeout(ctx, c.waitInterval, c.clusterWaitTimeout, immediate, func(ctx context.Context) (bool, error) {
+		obj, err := c.resourceClient(c.typeConfig.GetFederatedType()).Resources(qualifiedName.Namespace).Get(ctx, qualifiedName.Name, metav1.GetOptions{})
+		if err != nil {
+			c.tl.Logf("Error retrieving %s %q: %v", kind, qualifiedName, err)
+			return false, nil
+		}
+		updatedFedObject = obj
+		resource, err := status.DecodeGenericFederatedResource(obj)
+		if err != nil {
+			return false, err
+		}
+		if resource.Status != nil {
+			for _, clusterStatus := range resource.Status.Clusters {
+				if clusterStatus.Name == clusterName {
+					reference = "status"
+					return false, nil
+				}
+			}
+		}
+		versionObj := adapter.NewObject()
+		if err := c.client.Get(ctx, versionObj, versionName.Namespace, versionName.Name); err != nil {
+			c.tl.Logf("Error retrieving %s %q: %v", adapter.TypeName(), versionName, err)
+			return false, nil
+		}
+		if len(c.versionForCluster(adapter.GetStatus(versionObj), clusterName)) > 0 {
+			reference = "propagated version"
+			return false, nil
+		}
+		clusterNames, err := utils.GetClusterNames(obj)
+		if err != nil {
+			return false, err
+		}
+		if listed := sets.New[string](clusterNames...).Has(clusterName); listed == pruned {
+			reference = fmt.Sprintf("placement (expected listed=%t)", !pruned)
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		c.tl.Fatalf("Timed out waiting for the references of %s %q to removed cluster %q to be cleaned up, last found in its %s: %v", kind, qualifiedName, clusterName, reference, err)
+	}
+	c.CheckPropagation(ctx, immediate, updatedFedObject)
+	return updatedFedObject
+}
+
+// CheckPlacementInheritance verifies that the placement of the
+// federated namespace containing the given federated object, placed
+// only to the given cluster for the duration of the check, constrains
+// the placement of the object with the Intersection placement
+// inheritance and does not with the Default placement inheritance.
+// The object is expected to be placed to the given cluster and at
+// least one other, and the namespace to exist in those clusters
+// regardless of the placement of the federated namespace.
+func (c *FederatedTypeCrudTester) CheckPlacementInheritance(ctx context.Context, immediate bool, fedObject *unstructured.Unstructured, fedNamespaceAPIResource metav1.APIResource, namespaceClusterName string) *unstructured.Unstructured {
+	kind := c.typeConfig.GetFederatedType().Kind
+	qualifiedName := utils.NewQualifiedName(fedObject)
+	namespaceName := utils.QualifiedName{Namespace: fedObject.GetNamespace(), Name: fedObject.GetNamespace()}
+
+	fedNamespace, err := c.resourceClient(fedNamespaceAPIResource).Resources(namespaceName.Namespace).Get(ctx, namespaceName.Name, metav1.GetOptions{})
+	if err != nil {
+		c.tl.Fatalf("Error retrieving %s %q: %v", fedNamespaceAPIResource.Kind, namespaceName, err)
+	}
+	namespaceClusterNames, err := utils.GetClusterNames(fedNamespace)
+	if err != nil {
+		c.tl.Fatalf("Error retrieving cluster names for %s %q: %v", fedNamespaceAPIResource.Kind, namespaceName, err)
+	}
+	inheritance, inheritanceSet, err := unstructured.NestedString(fedNamespace.Object, utils.SpecField, utils.PlacementInheritanceField)
+	if err != nil {
+		c.tl.Fatalf("Error retrieving placement inheritance for %s %q: %v", fedNamespaceAPIResource.Kind, namespaceName, err)
+	}
+
+	resourceClusters, err := utils.ComputePlacement(fedObject, c.getClusters(), false)
+	if err != nil {
+		c.tl.Fatalf("Error computing placement of %s %q: %v", kind, qualifiedName, err)
+	}
+	if !resourceClusters.Has(namespaceClusterName) || resourceClusters.Len() < 2 {
+		c.tl.Fatalf("Expected %s %q to be placed to cluster %q and at least one other, got %v", kind, qualifiedName, namespaceClusterName, sets.List(resourceClusters))
+	}
+
+	expectedClusters := map[string]sets.Set[string]{
+		utils.IntersectionPlacementInheritance: sets.New(namespaceClusterName),
+		utils.DefaultPlacementInheritance:      resourceClusters,
+	}
+	for _, mode := range []string{utils.IntersectionPlacementInheritance, utils.DefaultPlacementInheritance} {
+		c.tl.Logf("Placing %s %q to cluster %q with %s placement inheritance", fedNamespaceAPIResource.Kind, namespaceName, namespaceClusterName, mode)
+		fedNamespace, err = c.updateObject(ctx, fedNamespaceAPIResource, fedNamespace, func(obj *unstructured.Unstructured) {
+			if err := utils.SetClusterNames(obj, []string{namespaceClusterName}); err != nil {
+				c.tl.Fatalf("Error setting cluster names for %s %q: %v", fedNamespaceAPIResource.Kind, namespaceName, err)
+			}
+			if err := utils.SetPlacementInheritance(obj, mode); err != nil {
+				c.tl.Fatalf("Error setting placement inheritance for %s %q: %v", fedNamespaceAPIResource.Kind, namespaceName, err)
+			}
+		})
+		if err != nil {
+			c.tl.Fatalf("Error updating %s %q: %v", fedNamespaceAPIResource.Kind, namespaceName, err)
+		}
+
+		selectedClusters, err := utils.ComputeNamespacedPlacement(fedObject, fedNamespace, c.getClusters(), false, false)
+		if err != nil {
+			c.tl.Fatalf("Error computing placement of %s %q: %v", kind, qualifiedName, err)
+		}
+		if !selectedClusters.Equal(expectedClusters[mode]) {
+			c.tl.Fatalf("Expected %s %q to be placed in clusters %v with %s placement inheritance, got %v", kind, qualifiedName, sets.List(expectedClusters[mode]), mode, sets.List(selectedClusters))
+		}
+		c.checkPropagationToClusters(ctx, immediate, fedObject, selectedClusters)
+	}
+
+	c.tl.Logf("Restoring the placement of %s %q", fedNamespaceAPIResource.Kind, namespaceName)
+	fedNamespace, err = c.updateObject(ctx, fedNamespaceAPIResource, fedNamespace, func(obj *unstructured.Unstructured) {
+		if err := utils.SetClusterNames(obj, namespaceClusterNames); err != nil {
+			c.tl.Fatalf("Error setting cluster names for %s %q: %v", fedNamespaceAPIResource.Kind, namespaceName, err)
+		}
+		if !inheritanceSet {
+			unstructured.RemoveNestedField(obj.Object, utils.SpecField, utils.PlacementInheritanceField)
+		} else if err := utils.SetPlacementInheritance(obj, inheritance); err != nil {
+			c.tl.Fatalf("Error setting placement inheritance for %s %q: %v", fedNamespaceAPIResource.Kind, namespaceName, err)
+		}
+	})
+	if err != nil {
+		c.tl.Fatalf("Error updating %s %q: %v", fedNamespaceAPIResource.Kind, namespaceName, err)
+	}
+	selectedClusters, err := utils.ComputeNamespacedPlacement(fedObject, fedNamespace, c.getClusters(), false, false)
+	if err != nil {
+		c.tl.Fatalf("Error computing placement of %s %q: %v", kind, qualifiedName, err)
+	}
+	c.checkPropagationToClusters(ctx, immediate, fedObject, selectedClusters)
+	return fedObject
+}
+
+// CheckMinHealthyClusters verifies that propagation of the given
+// federated object is reported as successful once all but the given
+// lagging cluster are healthy, when placement requires that many
+// healthy clusters. The lagging cluster is kept from becoming healthy
+// by an override that the API of the cluster rejects.
+func (c *FederatedTypeCrudTester) CheckMinHealthyClusters(ctx context.Context, immediate bool, fedObject *unstructured.Unstructured, laggingClusterName string) *unstructured.Unstructured {
+	apiResource := c.typeConfig.GetFederatedType()
+	kind := apiResource.Kind
+	qualifiedName := utils.NewQualifiedName(fedObject)
+	minHealthyClusters := int64(len(c.testClusters) - 1)
+
+	originalOverrides, err := utils.GetOverrides(fedObject)
+	if err != nil {
+		c.tl.Fatalf("Error reading overrides of %s %q: %v", kind, qualifiedName, err)
+	}
+
+	c.tl.Logf("Requiring %d healthy clusters for %s %q while cluster %q is lagging", minHealthyClusters, kind, qualifiedName, laggingClusterName)
+	updatedFedObject, err := c.updateObject(ctx, apiResource, fedObject, func(obj *unstructured.Unstructured) {
+		err := unstructured.SetNestedField(obj.Object, minHealthyClusters, utils.SpecField, utils.PlacementField, utils.MinHealthyClustersField)
+		if err != nil {
+			c.tl.Fatalf("Error setting minimum healthy clusters of %s %q: %v", kind, qualifiedName, err)
+		}
+		overrides, err := utils.GetOverrides(obj)
+		if err != nil {
+			c.tl.Fatalf("Error reading overrides of %s %q: %v", kind, qualifiedName, err)
+		}
+		// A label value may not start with a dash.
+		overrides[laggingClusterName] = append(overrides[laggingClusterName], utils.ClusterOverride{
+			Op:    "add",
+			Path:  "/metadata/labels/crudtester-lagging",
+			Value: "-invalid",
+		})
+		if err := utils.SetOverrides(obj, overrides); err != nil {
+			c.tl.Fatalf("Error setting overrides of %s %q: %v", kind, qualifiedName, err)
+		}
+	})
+	if err != nil {
+		c.tl.Fatalf("Error updating %s %q: %v", kind, qualifiedName, err)
+	}
+
+	c.tl.Logf("Waiting for propagation of %s %q to be successful without cluster %q", kind, qualifiedName, laggingClusterName)
+	var waitingForError error
+	err = wait.PollUntilContextTimeout(ctx, c.waitInterval, wait.ForeverTestTimeout, immediate, func(ctx context.Context) (bool, error) {
+		waitingForError = c.checkMinHealthyClustersStatus(updatedFedObject, laggingClusterName, int(minHealthyClusters))
+		return waitingForError == nil, nil
+	})
+	if err != nil {
+		c.tl.Fatalf("Error waiting for propagation of %s %q to be successful without cluster %q: %v", kind, qualifiedName, laggingClusterName, waitingForError)
+	}
+
+	c.tl.Logf("Removing the override that is lagging cluster %q", laggingClusterName)
+	updatedFedObject, err = c.updateObject(ctx, apiResource, updatedFedObject, func(obj *unstructured.Unstructured) {
+		if err := utils.SetOverrides(obj, originalOverrides); err != nil {
+			c.tl.Fatalf("Error setting overrides of %s %q: %v", kind, qualifiedName, err)
+		}
+	})
+	if err != nil {
+		c.tl.Fatalf("Error updating %s %q: %v", kind, qualifiedName, err)
+	}
+
+	c.CheckPropagation(ctx, immediate, updatedFedObject)
+	return updatedFedObject
+}
+
+// checkMinHealthyClustersStatus checks that the status of the given
+// federated object reflects K-of-N propagation: the Propagation
+// condition is True with at least the given number of OK clusters,
+// while the AllClustersPropagated condition is False due to the
+// lagging cluster.
+func (c *FederatedTypeCrudTester) checkMinHealthyClustersStatus(fedObject *unstructured.Unstructured, laggingClusterName string, minHealthyClusters int) error {
+	federatedKind := fedObject.GetKind()
+	qualifiedName := utils.NewQualifiedName(fedObject)
+
+	resource, err := GetGenericResource(c.client, fedObject.GroupVersionKind(), qualifiedName)
+	if err != nil {
+		return err
+	}
+	fedStatus := resource.Status
+	if fedStatus == nil || fedStatus.ObservedGeneration != fedObject.GetGeneration() {
+		return errors.Errorf("Waiting for status.observedGeneration to match metadata.generation for %s %q", federatedKind, qualifiedName)
+	}
+	if conditionStatus(fedStatus, status.PropagationConditionType) != apiv1.ConditionTrue {
+		return errors.Errorf("Waiting for the propagated condition of %s %q to have status True", federatedKind, qualifiedName)
+	}
+	if conditionStatus(fedStatus, status.AllClustersPropagatedConditionType) != apiv1.ConditionFalse {
+		return errors.Errorf("Waiting for the %s condition of %s %q to have status False", status.AllClustersPropagatedConditionType, federatedKind, qualifiedName)
+	}
+
+	healthyClusters := 0
+	for _, cluster := range fedStatus.Clusters {
+		if cluster.Status != status.ClusterPropagationOK {
+			continue
+		}
+		if cluster.Name == laggingClusterName {
+			return errors.Errorf("Expected cluster %q to be lagging for %s %q", laggingClusterName, federatedKind, qualifiedName)
+		}
+		healthyClusters++
+	}
+	if healthyClusters < minHealthyClusters {
+		return errors.Errorf("Waiting for %d clusters to be healthy for %s %q, got %d", minHealthyClusters, federatedKind, qualifiedName, healthyClusters)
+	}
+	return nil
+}
+
+// conditionStatus returns the status of the condition of the given
+// type, or an empty status if the condition is not present.
+func conditionStatus(fedStatus *status.GenericFederatedStatus, conditionType status.ConditionType) apiv1.ConditionStatus {
+	for _, condition := range fedStatus.Conditions {
+		if condition.Type == conditionType {
+			return condition.Status
+		}
+	}
+	return ""
+}
+
+// setClusterMaintenance adds or removes the maintenance annotation of
+// the named KubeFedCluster.
+func (c *FederatedTypeCrudTester) setClusterMaintenance(ctx context.Context, immediate bool, clusterName string, maintenance bool) {
+	err := wait.PollUntilContextTimeout(ctx, c.waitInterval, wait.ForeverTestTimeout, immediate, func(ctx context.Context) (bool, error) {
+		cluster := &v1beta1.KubeFedCluster{}
+		if err := c.client.Get(ctx, cluster, c.clustersNamespace, clusterName); err != nil {
+			c.tl.Logf("Error retrieving cluster %q: %v", clusterName, err)
+			return false, nil
+		}
+		annotations := cluster.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		if maintenance {
+			annotations[utils.MaintenanceAnnotation] = utils.MaintenanceValue
+		} else {
+			delete(annotations, utils.MaintenanceAnnotation)
+		}
+		cluster.SetAnnotations(annotations)
+		if err := c.client.Update(ctx, cluster); err != nil {
+			c.tl.Logf("Will retry updating cluster %q after error: %v", clusterName, err)
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		c.tl.Fatalf("Failed to update the maintenance annotation of cluster %q: %v", clusterName, err)
+	}
+}
+
+// setClusterLabels sets the given labels on the named KubeFedCluster,
+// or removes the labels added by the crudtester if none are given.
+func (c *FederatedTypeCrudTester) setClusterLabels(ctx context.Context, immediate bool, clusterName string, clusterLabels map[string]string) {
+	err := wait.PollUntilContextTimeout(ctx, c.waitInterval, wait.ForeverTestTimeout, immediate, func(ctx context.Context) (bool, error) {
+		cluster := &v1beta1.KubeFedCluster{}
+		if err := c.client.Get(ctx, cluster, c.clustersNamespace, clusterName); err != nil {
+			c.tl.Logf("Error retrieving cluster %q: %v", clusterName, err)
+			return false, nil
+		}
+		updatedLabels := cluster.GetLabels()
+		if updatedLabels == nil {
+			updatedLabels = map[string]string{}
+		}
+		for key := range updatedLabels {
+			if strings.HasPrefix(key, "crudtester-") {
+				delete(updatedLabels, key)
+			}
+		}
+		for key, value := range clusterLabels {
+			updatedLabels[key] = value
+		}
+		cluster.SetLabels(updatedLabels)
+		if err := c.client.Update(ctx, cluster); err != nil {
+			c.tl.Logf("Will retry updating cluster %q after error: %v", clusterName, err)
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		c.tl.Fatalf("Failed to update the labels of cluster %q: %v", clusterName, err)
+	}
+}
+
+// setClusterParameters replaces the parameters of the named
+// KubeFedCluster.
+func (c *FederatedTypeCrudTester) setClusterParameters(ctx context.Context, immediate bool, clusterName string, parameters map[string]string) {
+	err := wait.PollUntilContextTimeout(ctx, c.waitInterval, wait.ForeverTestTimeout, immediate, func(ctx context.Context) (bool, error) {
+		cluster := &v1beta1.KubeFedCluster{}
+		if err := c.client.Get(ctx, cluster, c.clustersNamespace, clusterName); err != nil {
+			c.tl.Logf("Error retrieving cluster %q: %v", clusterName, err)
+			return false, nil
+		}
+		cluster.Spec.Parameters = parameters
+		if err := c.client.Update(ctx, cluster); err != nil {
+			c.tl.Logf("Will retry updating cluster %q after error: %v", clusterName, err)
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		c.tl.Fatalf("Failed to update the parameters of cluster %q: %v", clusterName, err)
+	}
+}
+
+// setClusterTaints replaces the taints of the named KubeFedCluster.
+func (c *FederatedTypeCrudTester) setClusterTaints(ctx context.Context, immediate bool, clusterName string, taints []apiv1.Taint) {
+	err := wait.PollUntilContextTimeout(ctx, c.waitInterval, wait.ForeverTestTimeout, immediate, func(ctx context.Context) (bool, error) {
+		cluster := &v1beta1.KubeFedCluster{}
+		if err := c.client.Get(ctx, cluster, c.clustersNamespace, clusterName); err != nil {
+			c.tl.Logf("Error retrieving cluster %q: %v", clusterName, err)
+			return false, nil
+		}
+		cluster.Spec.Taints = taints
+		if err := c.client.Update(ctx, cluster); err != nil {
+			c.tl.Logf("Will retry updating cluster %q after error: %v", clusterName, err)
+			return false, nil
+		}
+		return true, nil
 	})
 	if err != nil {
-		c.tl.Fatalf("Error deleting %s %q: %v", federatedKind, qualifiedName, err)
+		c.tl.Fatalf("Failed to update the taints of cluster %q: %v", clusterName, err)
 	}
+}
 
-	if c.targetIsNamespace {
-		namespace = ""
-		qualifiedName = utils.QualifiedName{Name: name}
-	}
+// waitForCondition waits until the condition of the given type of the
+// federated resource satisfies the given function, and returns the
+// latest form of the federated resource.
+func (c *FederatedTypeCrudTester) waitForCondition(ctx context.Context, immediate bool, fedObject *unstructured.Unstructured, conditionType status.ConditionType, conditionFunc func(*status.GenericCondition) bool) (*unstructured.Unstructured, error) {
+	qualifiedName := utils.NewQualifiedName(fedObject)
+	resourceClient := c.resourceClient(c.typeConfig.GetFederatedType())
+	err := wait.PollUntilContextTimeout(ctx, c.waitInterval, wait.ForeverTestTimeout, immediate, func(ctx context.Context) (bool, error) {
+		obj, err := resourceClient.Resources(qualifiedName.Namespace).Get(ctx, qualifiedName.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		fedObject = obj
+		resource, err := status.DecodeGenericFederatedResource(fedObject)
+		if err != nil {
+			return false, err
+		}
+		if resource.Status == nil {
+			return false, nil
+		}
+		for _, condition := range resource.Status.Conditions {
+			if condition.Type == conditionType {
+				return conditionFunc(condition), nil
+			}
+		}
+		return false, nil
+	})
+	return fedObject, err
+}
 
-	targetKind := c.typeConfig.GetTargetType().Kind
+// ExpectPlacementAnnotation configures the tester to expect managed
+// resources in member clusters to have the annotation with the given
+// key listing the clusters they are propagated to, matching the
+// placement annotation configured for the sync controller.
+func (c *FederatedTypeCrudTester) ExpectPlacementAnnotation(key string) {
+	c.placementAnnotation = key
+}
 
-	// TODO(marun) Consider using informer to detect expected deletion state.
-	var stateMsg = "unlabeled"
-	if deletingInCluster {
-		stateMsg = "not present"
-	}
-	clusters, err := utils.ComputePlacement(fedObject, c.getClusters(), false)
-	if err != nil {
-		c.tl.Fatalf("Couldn't retrieve clusters for %s/%s: %v", federatedKind, name, err)
-	}
-	for clusterName, testCluster := range c.testClusters {
-		if !clusters.Has(clusterName) {
-			continue
+// CheckApplyResult waits until the status of the federated resource
+// reports the given result of applying it to each of the given
+// clusters during the most recent reconcile.
+func (c *FederatedTypeCrudTester) CheckApplyResult(ctx context.Context, immediate bool, fedObject *unstructured.Unstructured, clusterNames []string, expected status.ApplyResult) {
+	federatedKind := c.typeConfig.GetFederatedType().Kind
+	qualifiedName := utils.NewQualifiedName(fedObject)
+
+	applyResults := make(map[string]status.ApplyResult)
+	err := wait.PollUntilContextTimeout(ctx, c.waitInterval, wait.ForeverTestTimeout, immediate, func(ctx context.Context) (bool, error) {
+		resource, err := GetGenericResource(c.client, fedObject.GroupVersionKind(), qualifiedName)
+		if err != nil {
+			return false, err
 		}
-		namespace = utils.QualifiedNameForCluster(clusterName, qualifiedName).Namespace
-		err = wait.PollUntilContextTimeout(ctx, c.waitInterval, waitTimeout, immediate, func(ctx context.Context) (bool, error) {
-			obj, err := testCluster.Client.Resources(namespace).Get(context.Background(), name, metav1.GetOptions{})
-			switch {
-			case !deletingInCluster && apierrors.IsNotFound(err):
-				return false, errors.Errorf("%s %q was unexpectedly deleted from cluster %q", targetKind, qualifiedName, clusterName)
-			case deletingInCluster && err == nil:
-				if c.targetIsNamespace && clusterName == c.getPrimaryClusterName() {
-					// A namespace in the host cluster should have the
-					// managed label removed instead of being deleted.
-					return !utils.HasManagedLabel(obj), nil
-				}
-				// Continue checking for deletion or label removal
-				return false, nil
-			case !deletingInCluster && err == nil:
-				return !utils.HasManagedLabel(obj), nil
-			case err != nil && !apierrors.IsNotFound(err):
-				c.tl.Errorf("Error while checking whether %s %q is %s in cluster %q: %v", targetKind, qualifiedName, stateMsg, clusterName, err)
-				// This error may be recoverable
+		applyResults = make(map[string]status.ApplyResult)
+		if resource.Status != nil {
+			for _, cluster := range resource.Status.Clusters {
+				applyResults[cluster.Name] = cluster.ApplyResult
+			}
+		}
+		for _, clusterName := range clusterNames {
+			if applyResults[clusterName] != expected {
 				return false, nil
-			default:
-				return true, nil
 			}
-		})
-		if err != nil {
-			c.tl.Fatalf("Failed to confirm whether %s %q is %s in cluster %q: %v", targetKind, qualifiedName, stateMsg, clusterName, err)
 		}
+		return true, nil
+	})
+	if err != nil {
+		c.tl.Fatalf("Timed out waiting for the apply result of %s %q to be %q in clusters %v, got %v", federatedKind, qualifiedName, expected, clusterNames, applyResults)
 	}
 }
 
@@ -439,15 +2461,10 @@ func (c *FederatedTypeCrudTester) CheckReplicaSet(ctx context.Context, immediate
 }
 
 func (c *FederatedTypeCrudTester) getClusters() []*v1beta1.KubeFedCluster {
-	genericClient, err := genericclient.New(c.kubeConfig)
-	if err != nil {
-		c.tl.Fatalf("Failed to get kubefed clientset: %v", err)
-	}
-
 	var fedClusters []*v1beta1.KubeFedCluster
 	for cluster := range c.testClusters {
 		clusterResource := &v1beta1.KubeFedCluster{}
-		err = genericClient.Get(context.Background(), clusterResource, c.clustersNamespace, cluster)
+		err := c.client.Get(context.Background(), clusterResource, c.clustersNamespace, cluster)
 		if err != nil {
 			c.tl.Fatalf("Cannot get cluster %s: %v", cluster, err)
 		}
@@ -456,6 +2473,24 @@ func (c *FederatedTypeCrudTester) getClusters() []*v1beta1.KubeFedCluster {
 	return fedClusters
 }
 
+// UnreachableClusters returns the connectivity preflight errors of
+// the test clusters that cannot be reached with their credentials,
+// keyed by cluster name.
+func (c *FederatedTypeCrudTester) UnreachableClusters() map[string]error {
+	unreachableClusters := make(map[string]error)
+	for clusterName, testCluster := range c.testClusters {
+		if testCluster.Config == nil {
+			continue
+		}
+		err := utils.PreflightCluster(testCluster.Config, utils.DefaultPreflightTimeout)
+		if err != nil {
+			c.tl.Logf("Cluster %q failed the connectivity preflight: %v", clusterName, err)
+			unreachableClusters[clusterName] = err
+		}
+	}
+	return unreachableClusters
+}
+
 // CheckPropagation checks propagation for the crud tester's clients
 func (c *FederatedTypeCrudTester) CheckPropagation(ctx context.Context, immediate bool, fedObject *unstructured.Unstructured) {
 	federatedKind := c.typeConfig.GetFederatedType().Kind
@@ -465,6 +2500,20 @@ func (c *FederatedTypeCrudTester) CheckPropagation(ctx context.Context, immediat
 	if err != nil {
 		c.tl.Fatalf("Error retrieving cluster names for %s %q: %v", federatedKind, qualifiedName, err)
 	}
+	c.checkPropagationToClusters(ctx, immediate, fedObject, selectedClusters)
+}
+
+// checkPropagationToClusters verifies that the given federated object
+// has been propagated to the given selected clusters and removed from
+// the other test clusters.
+func (c *FederatedTypeCrudTester) checkPropagationToClusters(ctx context.Context, immediate bool, fedObject *unstructured.Unstructured, selectedClusters sets.Set[string]) {
+	federatedKind := c.typeConfig.GetFederatedType().Kind
+	qualifiedName := utils.NewQualifiedName(fedObject)
+
+	placementOnlyClusters, err := utils.GetPlacementOnlyClusterNames(fedObject)
+	if err != nil {
+		c.tl.Fatalf("Error retrieving placement-only cluster names for %s %q: %v", federatedKind, qualifiedName, err)
+	}
 
 	templateVersion, err := sync.GetTemplateHash(fedObject.Object)
 	if err != nil {
@@ -481,14 +2530,52 @@ func (c *FederatedTypeCrudTester) CheckPropagation(ctx context.Context, immediat
 		c.tl.Fatalf("Error reading cluster overrides for %s %q: %v", federatedKind, qualifiedName, err)
 	}
 
+	propagatedClusters := selectedClusters.Difference(placementOnlyClusters)
+
+	clustersByName := make(map[string]*v1beta1.KubeFedCluster)
+	for _, cluster := range c.getClusters() {
+		clustersByName[cluster.Name] = cluster
+	}
+
 	targetKind := c.typeConfig.GetTargetType().Kind
 
+	unreachableClusters := c.UnreachableClusters()
+
 	// TODO(marun) run checks in parallel
 	primaryClusterName := c.getPrimaryClusterName()
+	targetQualifiedName := c.targetName(fedObject)
 	for clusterName, testCluster := range c.testClusters {
-		targetName := utils.QualifiedNameForCluster(clusterName, qualifiedName)
+		targetName := utils.QualifiedNameForCluster(clusterName, targetQualifiedName)
+
+		placementOnly := selectedClusters.Has(clusterName) && placementOnlyClusters.Has(clusterName)
+		objExpected := selectedClusters.Has(clusterName) && !placementOnly
+
+		if err, ok := unreachableClusters[clusterName]; ok {
+			// The resource cannot be verified in an unreachable
+			// cluster, but a cluster it is expected in must be
+			// reported as unreachable rather than as propagated.
+			if !objExpected {
+				c.tl.Logf("Unable to verify the absence of %s %q in unreachable cluster %q: %v", targetKind, targetName, clusterName, err)
+				continue
+			}
+			c.tl.Logf("Waiting for %s %q to report cluster %q as %s: %v", federatedKind, qualifiedName, clusterName, status.ClusterNotReachable, err)
+			c.waitForClusterStatus(ctx, immediate, fedObject, clusterName, status.ClusterNotReachable)
+			continue
+		}
 
-		objExpected := selectedClusters.Has(clusterName)
+		// Templated overrides are expected to be evaluated against the
+		// data of the cluster the resource is propagated to.
+		clusterOverrides := overridesMap[clusterName]
+		if objExpected && clusterOverrides.Templated() {
+			clusterOverrides, err = utils.ExpandOverrideTemplates(clusterOverrides, utils.NewOverrideTemplateData(clustersByName[clusterName]))
+			if err != nil {
+				c.tl.Fatalf("Error evaluating overrides of cluster %q for %s %q: %v", clusterName, federatedKind, qualifiedName, err)
+			}
+		}
+		clusterOverrideVersion, err := sync.GetClusterOverrideHash(clusterOverrides)
+		if err != nil {
+			c.tl.Fatalf("Error computing override hash of cluster %q for %s %q: %v", clusterName, federatedKind, qualifiedName, err)
+		}
 
 		operation := "to be deleted from"
 		if objExpected {
@@ -497,9 +2584,17 @@ func (c *FederatedTypeCrudTester) CheckPropagation(ctx context.Context, immediat
 		c.tl.Logf("Waiting for %s %q %s cluster %q", targetKind, targetName, operation, clusterName)
 
 		switch {
+		case placementOnly:
+			// A placement-only cluster is expected not to have
+			// received the resource.
+			c.tl.Logf("Checking that %s %q was not propagated to placement-only cluster %q", targetKind, targetName, clusterName)
+			_, err := testCluster.Client.Resources(targetName.Namespace).Get(ctx, targetName.Name, metav1.GetOptions{})
+			if !apierrors.IsNotFound(err) {
+				c.tl.Fatalf("Expected %s %q not to be propagated to placement-only cluster %q: %v", targetKind, targetName, clusterName, err)
+			}
 		case objExpected:
-			err = c.waitForResource(ctx, immediate, testCluster.Client, targetName, overridesMap[clusterName], func() string {
-				version, _ := c.expectedVersion(ctx, immediate, qualifiedName, templateVersion, overrideVersion, clusterName)
+			err = c.waitForResource(ctx, immediate, testCluster.Client, clusterName, targetName, clusterOverrides, propagatedClusters, func() string {
+				version, _ := c.expectedVersion(ctx, immediate, qualifiedName, templateVersion, overrideVersion, clusterOverrideVersion, clusterName)
 				return version
 			})
 			switch {
@@ -512,7 +2607,7 @@ func (c *FederatedTypeCrudTester) CheckPropagation(ctx context.Context, immediat
 			c.checkHostNamespaceUnlabeled(ctx, immediate, testCluster.Client, targetName, targetKind, clusterName)
 		default:
 			err = c.waitForResourceDeletion(ctx, immediate, testCluster.Client, targetName, func() bool {
-				version, ok := c.expectedVersion(ctx, immediate, qualifiedName, templateVersion, overrideVersion, clusterName)
+				version, ok := c.expectedVersion(ctx, immediate, qualifiedName, templateVersion, overrideVersion, clusterOverrideVersion, clusterName)
 				return version == "" && ok
 			})
 			// Once resource deletion is complete, wait for the status to reflect the deletion
@@ -531,7 +2626,7 @@ func (c *FederatedTypeCrudTester) CheckPropagation(ctx context.Context, immediat
 		waitInterval := 1 * time.Second
 		var waitingForError error
 		err = wait.PollUntilContextTimeout(context.Background(), waitInterval, c.clusterWaitTimeout, true, func(ctx context.Context) (done bool, err error) {
-			ok, err := c.checkFederatedStatus(fedObject, clusterName, objExpected)
+			ok, err := c.checkFederatedStatus(fedObject, clusterName, objExpected, placementOnly)
 			if err != nil {
 				// Logging lots of waiting messages would clutter the
 				// logs.  Instead, track the most recent message
@@ -553,9 +2648,65 @@ func (c *FederatedTypeCrudTester) CheckPropagation(ctx context.Context, immediat
 	}
 }
 
+// CheckConsistency verifies that the PropagatedVersion, the federated
+// status and the managed resources in the reachable test clusters of
+// the given federated resource do not diverge.
+func (c *FederatedTypeCrudTester) CheckConsistency(ctx context.Context, fedObject *unstructured.Unstructured) {
+	federatedKind := fedObject.GetKind()
+	qualifiedName := utils.NewQualifiedName(fedObject)
+
+	// Retrieve the resource from the API to ensure the latest status
+	// is considered.
+	latestFedObject := &unstructured.Unstructured{}
+	latestFedObject.SetGroupVersionKind(fedObject.GroupVersionKind())
+	if err := c.client.Get(ctx, latestFedObject, qualifiedName.Namespace, qualifiedName.Name); err != nil {
+		c.tl.Fatalf("Error retrieving %s %q: %v", federatedKind, qualifiedName, err)
+	}
+
+	versionName := PropagatedVersionQualifiedName(c.typeConfig, qualifiedName)
+	adapter := versionmanager.NewVersionAdapter(c.typeConfig.GetFederatedNamespaced())
+	var propagatedVersion *fedv1a1.PropagatedVersionStatus
+	versionObj := adapter.NewObject()
+	err := c.client.Get(ctx, versionObj, versionName.Namespace, versionName.Name)
+	switch {
+	case err == nil:
+		propagatedVersion = adapter.GetStatus(versionObj)
+	case !apierrors.IsNotFound(err):
+		c.tl.Fatalf("Error retrieving %s %q: %v", adapter.TypeName(), versionName, err)
+	}
+
+	targetKind := c.typeConfig.GetTargetType().Kind
+	targetQualifiedName := c.targetName(latestFedObject)
+	unreachableClusters := c.UnreachableClusters()
+	clusterObjects := make(map[string]*unstructured.Unstructured)
+	for clusterName, testCluster := range c.testClusters {
+		if _, ok := unreachableClusters[clusterName]; ok {
+			continue
+		}
+		targetName := utils.QualifiedNameForCluster(clusterName, targetQualifiedName)
+		clusterObj, err := testCluster.Client.Resources(targetName.Namespace).Get(ctx, targetName.Name, metav1.GetOptions{})
+		switch {
+		case apierrors.IsNotFound(err):
+			clusterObjects[clusterName] = nil
+		case err != nil:
+			c.tl.Fatalf("Error retrieving %s %q in cluster %q: %v", targetKind, targetName, clusterName, err)
+		default:
+			clusterObjects[clusterName] = clusterObj
+		}
+	}
+
+	report, err := utils.CheckConsistency(c.typeConfig, latestFedObject, propagatedVersion, clusterObjects)
+	if err != nil {
+		c.tl.Fatalf("Error checking the consistency of %s %q: %v", federatedKind, qualifiedName, err)
+	}
+	for _, divergence := range report.Divergences {
+		c.tl.Errorf("%s %q diverges in cluster %q (%s): %s", federatedKind, qualifiedName, divergence.ClusterName, divergence.Type, divergence.Message)
+	}
+}
+
 // checkFederatedStatus ensures that the federated resource status
 // reflects the expected propagation state.
-func (c *FederatedTypeCrudTester) checkFederatedStatus(fedObject *unstructured.Unstructured, clusterName string, objExpected bool) (bool, error) {
+func (c *FederatedTypeCrudTester) checkFederatedStatus(fedObject *unstructured.Unstructured, clusterName string, objExpected, placementOnly bool) (bool, error) {
 	federatedKind := fedObject.GetKind()
 	qualifiedName := utils.NewQualifiedName(fedObject)
 
@@ -575,6 +2726,21 @@ func (c *FederatedTypeCrudTester) checkFederatedStatus(fedObject *unstructured.U
 		return false, errors.Errorf("Waiting for status.observedGeneration to match metadata.generation for %s %q", federatedKind, qualifiedName)
 	}
 
+	if len(c.typeConfig.GetTargetNameTemplate()) > 0 {
+		expectedName := c.targetName(fedObject).Name
+		if fedStatus.TargetName != expectedName {
+			return false, errors.Errorf("Waiting for status.targetName of %s %q to be %q", federatedKind, qualifiedName, expectedName)
+		}
+	}
+
+	// Propagation that did not complete within the deadline of the
+	// resource is reported as a failure rather than waited for.
+	for _, condition := range fedStatus.Conditions {
+		if condition.Type == status.FailedConditionType && condition.Status == apiv1.ConditionTrue && condition.Reason == status.PropagationTimeout {
+			return false, errors.Errorf("Propagation of %s %q failed: %s", federatedKind, qualifiedName, condition.Message)
+		}
+	}
+
 	// Check that aggregate status is ok
 	conditionTrue := false
 	for _, condition := range fedStatus.Conditions {
@@ -589,8 +2755,31 @@ func (c *FederatedTypeCrudTester) checkFederatedStatus(fedObject *unstructured.U
 		return false, errors.Errorf("Waiting for the propagated condition of %s %q to have status True", federatedKind, qualifiedName)
 	}
 
+	// The Propagation condition may be True before all clusters are
+	// OK when placement specifies a minimum number of healthy
+	// clusters, in which case all clusters are only known to be OK
+	// once the AllClustersPropagated condition is True.
+	minHealthyClusters, err := utils.GetMinHealthyClusters(fedObject)
+	if err != nil {
+		return false, err
+	}
+	if minHealthyClusters != nil && conditionStatus(fedStatus, status.AllClustersPropagatedConditionType) != apiv1.ConditionTrue {
+		return false, errors.Errorf("Waiting for the %s condition of %s %q to have status True", status.AllClustersPropagatedConditionType, federatedKind, qualifiedName)
+	}
+
 	// Check that the cluster status is correct
-	if objExpected {
+	if placementOnly {
+		clusterStatusPlacementOnly := false
+		for _, cluster := range fedStatus.Clusters {
+			if cluster.Name == clusterName && cluster.Status == status.PlacementOnly {
+				clusterStatusPlacementOnly = true
+				break
+			}
+		}
+		if !clusterStatusPlacementOnly {
+			return false, errors.Errorf("Waiting for %s %q to have %s status for cluster %q", federatedKind, qualifiedName, status.PlacementOnly, clusterName)
+		}
+	} else if objExpected {
 		clusterStatusOK := false
 		for _, cluster := range fedStatus.Clusters {
 			if cluster.Name == clusterName && cluster.Status == status.ClusterPropagationOK {
@@ -616,6 +2805,40 @@ func (c *FederatedTypeCrudTester) checkFederatedStatus(fedObject *unstructured.U
 	return true, nil
 }
 
+// waitForClusterStatus waits for the status of the given federated
+// resource to report the given propagation status for the named
+// cluster.
+func (c *FederatedTypeCrudTester) waitForClusterStatus(ctx context.Context, immediate bool, fedObject *unstructured.Unstructured, clusterName string, expectedStatus status.PropagationStatus) {
+	federatedKind := fedObject.GetKind()
+	qualifiedName := utils.NewQualifiedName(fedObject)
+	var lastErr error
+	err := wait.PollUntilContextTimeout(ctx, c.waitInterval, c.clusterWaitTimeout, immediate, func(ctx context.Context) (bool, error) {
+		resource, err := GetGenericResource(c.client, fedObject.GroupVersionKind(), qualifiedName)
+		if err != nil {
+			lastErr = err
+			return false, nil
+		}
+		if resource.Status == nil {
+			lastErr = errors.New("status is not yet available")
+			return false, nil
+		}
+		for _, cluster := range resource.Status.Clusters {
+			if cluster.Name == clusterName {
+				if cluster.Status == expectedStatus {
+					return true, nil
+				}
+				lastErr = errors.Errorf("cluster has status %q", cluster.Status)
+				return false, nil
+			}
+		}
+		lastErr = errors.New("cluster is missing from status")
+		return false, nil
+	})
+	if err != nil {
+		c.tl.Fatalf("Timeout waiting for %s %q to have %s status for cluster %q: %v", federatedKind, qualifiedName, expectedStatus, clusterName, lastErr)
+	}
+}
+
 func (c *FederatedTypeCrudTester) checkHostNamespaceUnlabeled(ctx context.Context, immediate bool, client utils.ResourceClient, qualifiedName utils.QualifiedName, targetKind, clusterName string) {
 	// A namespace in the host cluster should end up unlabeled instead of
 	// deleted when it is not targeted by placement.
@@ -634,7 +2857,7 @@ func (c *FederatedTypeCrudTester) checkHostNamespaceUnlabeled(ctx context.Contex
 	}
 }
 
-func (c *FederatedTypeCrudTester) waitForResource(ctx context.Context, immediate bool, client utils.ResourceClient, qualifiedName utils.QualifiedName, expectedOverrides utils.ClusterOverrides, expectedVersionFunc func() string) error {
+func (c *FederatedTypeCrudTester) waitForResource(ctx context.Context, immediate bool, client utils.ResourceClient, clusterName string, qualifiedName utils.QualifiedName, expectedOverrides utils.ClusterOverrides, propagatedClusters sets.Set[string], expectedVersionFunc func() string) error {
 	err := wait.PollUntilContextTimeout(ctx, c.waitInterval, c.clusterWaitTimeout, immediate, func(ctx context.Context) (done bool, err error) {
 		expectedVersion := expectedVersionFunc()
 		if len(expectedVersion) == 0 {
@@ -651,6 +2874,12 @@ func (c *FederatedTypeCrudTester) waitForResource(ctx context.Context, immediate
 				c.tl.Errorf("Expected resource to be labeled with %q", fmt.Sprintf("%s: %s", utils.ManagedByKubeFedLabelKey, utils.ManagedByKubeFedLabelValue))
 				return false, nil
 			}
+			// The placement annotation is not part of the template, so
+			// the resource may match the expected version before the
+			// annotation reflects a change of placement.
+			if len(c.placementAnnotation) > 0 && clusterObj.GetAnnotations()[c.placementAnnotation] != utils.PlacementAnnotationValue(propagatedClusters) {
+				return false, nil
+			}
 
 			// Validate that the expected override was applied
 			if len(expectedOverrides) > 0 {
@@ -659,15 +2888,25 @@ func (c *FederatedTypeCrudTester) waitForResource(ctx context.Context, immediate
 				if err = utils.ApplyJSONPatch(expectedClusterObject, expectedOverrides); err != nil {
 					c.tl.Fatalf("Failed to apply json patch: %v", err)
 				}
+				// The placement annotation is added after overrides are
+				// applied and takes precedence over them.
+				if len(c.placementAnnotation) > 0 {
+					utils.AddPlacementAnnotation(expectedClusterObject, c.placementAnnotation, propagatedClusters)
+				}
 
-				// Kubernetes 1.21 introduced a label kubernetes.io/metadata.name to all namespaces so regardless of what we
-				// override we should always add this label here to this check.
-				if expectedClusterObject.GetObjectKind().GroupVersionKind() == apiv1.SchemeGroupVersion.WithKind("Namespace") {
-					labels := expectedClusterObject.GetLabels()
-					labels[apiv1.LabelMetadataName] = expectedClusterObject.GetName()
-					expectedClusterObject.SetLabels(labels)
+				// Only the included fields of a co-owned resource are
+				// expected to reflect the overrides.
+				if includedFields := c.typeConfig.GetIncludedFields(); len(includedFields) > 0 {
+					expectedClusterObject, err = utils.ApplyIncludedFields(expectedClusterObject, clusterObj, includedFields)
+					if err != nil {
+						c.tl.Fatalf("Failed to apply included fields: %v", err)
+					}
 				}
 
+				// Metadata populated in the member cluster is retained
+				// regardless of the overrides.
+				utils.RetainServerManagedMetadata(expectedClusterObject, clusterObj, c.typeConfig.GetServerManagedMetadata())
+
 				expectedClusterObjectJSON, err := expectedClusterObject.MarshalJSON()
 				if err != nil {
 					c.tl.Fatalf("Failed to marshal expected cluster object to json: %v", err)
@@ -679,7 +2918,13 @@ func (c *FederatedTypeCrudTester) waitForResource(ctx context.Context, immediate
 				}
 
 				if !jsonpatch.Equal(expectedClusterObjectJSON, clusterObjectJSON) {
-					c.tl.Errorf("Cluster object is not as expected. expected: %s, actual: %s", expectedClusterObjectJSON, clusterObjectJSON)
+					// Only the divergent fields are reported to
+					// pinpoint the cause of the mismatch.
+					if changes := dispatch.UpdateChanges(clusterObj, expectedClusterObject); len(changes) > 0 {
+						c.tl.Errorf("Cluster object %q in cluster %q is not as expected. Fields differing from the expected object:\n%s", qualifiedName, clusterName, utils.FormatFieldChanges(changes))
+					} else {
+						c.tl.Errorf("Cluster object %q in cluster %q is not as expected. expected: %s, actual: %s", qualifiedName, clusterName, expectedClusterObjectJSON, clusterObjectJSON)
+					}
 					return false, nil
 				}
 			}
@@ -739,16 +2984,13 @@ func (c *FederatedTypeCrudTester) updateObject(ctx context.Context, apiResource
 	return updatedObj, err
 }
 
-// expectedVersion retrieves the version of the resource expected in the named cluster
-func (c *FederatedTypeCrudTester) expectedVersion(ctx context.Context, immediate bool, qualifiedName utils.QualifiedName, templateVersion, overrideVersion, clusterName string) (string, bool) {
-	targetKind := c.typeConfig.GetTargetType().Kind
-	versionName := utils.QualifiedName{
-		Namespace: qualifiedName.Namespace,
-		Name:      common.PropagatedVersionName(targetKind, qualifiedName.Name),
-	}
-	if c.targetIsNamespace {
-		versionName.Namespace = qualifiedName.Name
-	}
+// expectedVersion retrieves the version of the resource expected in
+// the named cluster. Since cluster-local overrides are excluded from
+// the override version, a version recorded with the overrides of its
+// cluster is only expected if those overrides match
+// clusterOverrideVersion.
+func (c *FederatedTypeCrudTester) expectedVersion(ctx context.Context, immediate bool, qualifiedName utils.QualifiedName, templateVersion, overrideVersion, clusterOverrideVersion, clusterName string) (string, bool) {
+	versionName := PropagatedVersionQualifiedName(c.typeConfig, qualifiedName)
 
 	loggedWaiting := false
 	adapter := versionmanager.NewVersionAdapter(c.typeConfig.GetFederatedNamespaced())
@@ -780,6 +3022,15 @@ func (c *FederatedTypeCrudTester) expectedVersion(ctx context.Context, immediate
 		return "", false
 	}
 
+	for _, clusterVersion := range version.ClusterVersions {
+		if clusterVersion.ClusterName != clusterName {
+			continue
+		}
+		if len(clusterVersion.OverrideVersion) > 0 && clusterVersion.OverrideVersion != clusterOverrideVersion {
+			return "", false
+		}
+	}
+
 	return c.versionForCluster(version, clusterName), true
 }
 
@@ -883,8 +3134,7 @@ func (c *FederatedTypeCrudTester) getRemoteStatus(ctx context.Context, immediate
 			return false, nil
 		}
 
-		resource := &status.GenericFederatedResource{}
-		err = utils.UnstructuredToInterface(fedObj, resource)
+		resource, err := status.DecodeGenericFederatedResource(fedObj)
 		if err != nil {
 			return false, err
 		}
@@ -911,6 +3161,188 @@ func (c *FederatedTypeCrudTester) getRemoteStatus(ctx context.Context, immediate
 	return remoteStatusObj, nil
 }
 
+// CheckRemoteStatusRemoval verifies that removing a cluster from the
+// placement of the given federated resource removes the status of the
+// cluster, including its remote status, from the status of the
+// federated resource, while the remaining clusters keep reporting
+// their remote status. Remote status collection must be enabled for
+// the type.
+func (c *FederatedTypeCrudTester) CheckRemoteStatusRemoval(ctx context.Context, immediate bool, fedObject *unstructured.Unstructured) {
+	apiResource := c.typeConfig.GetFederatedType()
+	kind := apiResource.Kind
+	qualifiedName := utils.NewQualifiedName(fedObject)
+
+	clusterNames, err := utils.GetClusterNames(fedObject)
+	if err != nil {
+		c.tl.Fatalf("Error retrieving cluster names for %s %q: %v", kind, qualifiedName, err)
+	}
+	for _, clusterName := range clusterNames {
+		if _, err := c.getRemoteStatus(ctx, immediate, fedObject, clusterName); err != nil {
+			c.tl.Fatalf("Error waiting for the remote status of %s %q for cluster %q: %v", kind, qualifiedName, clusterName, err)
+		}
+	}
+
+	c.CheckPlacementChange(ctx, immediate, fedObject)
+
+	resourceClient := c.resourceClient(apiResource)
+	updatedFedObject, err := resourceClient.Resources(qualifiedName.Namespace).Get(ctx, qualifiedName.Name, metav1.GetOptions{})
+	if err != nil {
+		c.tl.Fatalf("Error retrieving %s %q: %v", kind, qualifiedName, err)
+	}
+	remainingClusterNames, err := utils.GetClusterNames(updatedFedObject)
+	if err != nil {
+		c.tl.Fatalf("Error retrieving cluster names for %s %q: %v", kind, qualifiedName, err)
+	}
+	remaining := sets.New(remainingClusterNames...)
+	removed := sets.New(clusterNames...).Difference(remaining)
+
+	c.tl.Logf("Waiting for the status of clusters %v to be removed from %s %q", sets.List(removed), kind, qualifiedName)
+	err = wait.PollUntilContextTimeout(ctx, c.waitInterval, c.clusterWaitTimeout, immediate, func(ctx context.Context) (bool, error) {
+		fedObj, err := resourceClient.Resources(qualifiedName.Namespace).Get(ctx, qualifiedName.Name, metav1.GetOptions{})
+		if err != nil {
+			c.tl.Logf("An unexpected error occurred while polling for the status of %s %q: %v", kind, qualifiedName, err)
+			return false, nil
+		}
+		resource, err := status.DecodeGenericFederatedResource(fedObj)
+		if err != nil {
+			return false, err
+		}
+		reported := sets.New[string]()
+		if resource.Status != nil {
+			for _, cluster := range resource.Status.Clusters {
+				if removed.Has(cluster.Name) {
+					c.tl.Logf("Status of removed cluster %q remains", cluster.Name)
+					return false, nil
+				}
+				if cluster.RemoteStatus != nil {
+					reported.Insert(cluster.Name)
+				}
+			}
+		}
+		if missing := remaining.Difference(reported); missing.Len() > 0 {
+			c.tl.Logf("Remote status of clusters %v is missing", sets.List(missing))
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		c.tl.Fatalf("Timed out waiting for the status of clusters %v to be removed from %s %q: %v", sets.List(removed), kind, qualifiedName, err)
+	}
+}
+
+// CheckReadyEndpoints creates an EndpointSlice of the service managed
+// for the given federated resource in each of the given clusters,
+// with the given number of ready endpoints and an endpoint that is not
+// ready, and verifies that the federated status reports the ready
+// endpoints of each cluster and their total. Raw resource status
+// collection must be enabled for the type, whose target must be
+// Service.
+func (c *FederatedTypeCrudTester) CheckReadyEndpoints(ctx context.Context, immediate bool, fedObject *unstructured.Unstructured, readyEndpoints map[string]int) *unstructured.Unstructured {
+	apiResource := c.typeConfig.GetFederatedType()
+	kind := apiResource.Kind
+	qualifiedName := utils.NewQualifiedName(fedObject)
+
+	var expectedTotal int64
+	for clusterName, ready := range readyEndpoints {
+		targetName := utils.QualifiedNameForCluster(clusterName, c.targetName(fedObject))
+		endpointSlice := newEndpointSlice(targetName, ready)
+		c.tl.Logf("Creating EndpointSlice %q with %d ready endpoints in cluster %q", utils.NewQualifiedName(endpointSlice), ready, clusterName)
+		client := c.testClusters[clusterName].EndpointSliceClient.Resources(targetName.Namespace)
+		if _, err := client.Create(ctx, endpointSlice, metav1.CreateOptions{}); err != nil {
+			c.tl.Fatalf("Error creating EndpointSlice %q in cluster %q: %v", utils.NewQualifiedName(endpointSlice), clusterName, err)
+		}
+		defer func(clusterName, name string) {
+			if err := client.Delete(ctx, name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+				c.tl.Errorf("Error deleting EndpointSlice %q in cluster %q: %v", name, clusterName, err)
+			}
+		}(clusterName, endpointSlice.GetName())
+		expectedTotal += int64(ready)
+	}
+
+	// The ready endpoints are collected when the federated resource is
+	// next reconciled.
+	c.tl.Logf("Updating the template of %s %q", kind, qualifiedName)
+	updatedFedObject, err := c.updateObject(ctx, apiResource, fedObject, func(obj *unstructured.Unstructured) {
+		err := unstructured.SetNestedField(obj.Object, "true", utils.SpecField, utils.TemplateField, "metadata", "labels", "crudtester-endpoints")
+		if err != nil {
+			c.tl.Fatalf("Error setting template label of %s %q: %v", kind, qualifiedName, err)
+		}
+	})
+	if err != nil {
+		c.tl.Fatalf("Error updating %s %q: %v", kind, qualifiedName, err)
+	}
+	c.CheckPropagation(ctx, immediate, updatedFedObject)
+
+	c.tl.Logf("Waiting for the ready endpoints of %s %q to total %d", kind, qualifiedName, expectedTotal)
+	var waitingForError error
+	err = wait.PollUntilContextTimeout(ctx, c.waitInterval, c.clusterWaitTimeout, immediate, func(ctx context.Context) (bool, error) {
+		waitingForError = c.checkReadyEndpoints(updatedFedObject, readyEndpoints, expectedTotal)
+		return waitingForError == nil, nil
+	})
+	if err != nil {
+		c.tl.Fatalf("Timed out waiting for the ready endpoints of %s %q: %v", kind, qualifiedName, waitingForError)
+	}
+	return updatedFedObject
+}
+
+// checkReadyEndpoints returns an error if the federated status does
+// not report the given ready endpoints of each cluster and the given
+// total.
+func (c *FederatedTypeCrudTester) checkReadyEndpoints(fedObject *unstructured.Unstructured, readyEndpoints map[string]int, expectedTotal int64) error {
+	resource, err := GetGenericResource(c.client, fedObject.GroupVersionKind(), utils.NewQualifiedName(fedObject))
+	if err != nil {
+		return err
+	}
+	if resource.Status == nil {
+		return errors.New("the status is not yet set")
+	}
+	if total := resource.Status.ReadyEndpoints; total == nil || *total != expectedTotal {
+		return errors.Errorf("expected %d ready endpoints in total, got %v", expectedTotal, formatOptionalInt(total))
+	}
+	for clusterName, ready := range readyEndpoints {
+		var actual *int64
+		for _, cluster := range resource.Status.Clusters {
+			if cluster.Name == clusterName {
+				actual = cluster.ReadyEndpoints
+			}
+		}
+		if actual == nil || *actual != int64(ready) {
+			return errors.Errorf("expected %d ready endpoints in cluster %q, got %v", ready, clusterName, formatOptionalInt(actual))
+		}
+	}
+	return nil
+}
+
+func formatOptionalInt(value *int64) string {
+	if value == nil {
+		return "none"
+	}
+	return fmt.Sprintf("%d", *value)
+}
+
+// newEndpointSlice returns an EndpointSlice of the named service with
+// the given number of ready endpoints and an endpoint that is not
+// ready.
+func newEndpointSlice(serviceName utils.QualifiedName, ready int) *unstructured.Unstructured {
+	var endpoints []interface{}
+	for i := 0; i <= ready; i++ {
+		endpoints = append(endpoints, map[string]interface{}{
+			"addresses":  []interface{}{fmt.Sprintf("10.0.0.%d", i+1)},
+			"conditions": map[string]interface{}{"ready": i < ready},
+		})
+	}
+	endpointSlice := &unstructured.Unstructured{Object: map[string]interface{}{
+		"addressType": "IPv4",
+		"endpoints":   endpoints,
+	}}
+	endpointSlice.SetAPIVersion("discovery.k8s.io/v1")
+	endpointSlice.SetKind(EndpointSliceAPIResource.Kind)
+	endpointSlice.SetNamespace(serviceName.Namespace)
+	endpointSlice.SetName(serviceName.Name + "-crudtester")
+	endpointSlice.SetLabels(map[string]string{discoveryv1.LabelServiceName: serviceName.Name})
+	return endpointSlice
+}
+
 func (c *FederatedTypeCrudTester) CheckStatusCreated(ctx context.Context, immediate bool, qualifiedName utils.QualifiedName) {
 	if !c.typeConfig.GetStatusEnabled() {
 		return
@@ -952,8 +3384,7 @@ func GetGenericResource(client genericclient.Client, gvk schema.GroupVersionKind
 		return nil, errors.Wrapf(err, "Failed to retrieve federated resource from the API")
 	}
 
-	resource := &status.GenericFederatedResource{}
-	err = utils.UnstructuredToInterface(fedObject, resource)
+	resource, err := status.DecodeGenericFederatedResource(fedObject)
 	if err != nil {
 		return nil, errors.Wrapf(err, "Failed to unmarshall federated resource to generic resource struct")
 	}
@@ -0,0 +1,92 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/kubefed/pkg/apis/core/typeconfig"
+	"sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
+	"sigs.k8s.io/kubefed/pkg/client/generic"
+	"sigs.k8s.io/kubefed/pkg/controller/utils"
+	"sigs.k8s.io/kubefed/test/common"
+)
+
+// Environment holds the stores backing the host cluster and the
+// member clusters of a crud tester created by
+// NewFederatedTypeCrudTester. Since no controllers run against the
+// stores, a test is responsible for simulating the propagation that
+// the crud tester waits for.
+type Environment struct {
+	HostStore     *Store
+	ClusterStores map[string]*Store
+}
+
+// HostClient returns a generic client for the host cluster.
+func (e *Environment) HostClient() generic.Client {
+	return NewGenericClient(e.HostStore)
+}
+
+// ClusterClient returns a client for the given API resource of the
+// named member cluster.
+func (e *Environment) ClusterClient(clusterName string, apiResource metav1.APIResource) utils.ResourceClient {
+	return NewResourceClient(e.ClusterStores[clusterName], apiResource)
+}
+
+// NewFederatedTypeCrudTester returns a crud tester for the given type
+// whose host cluster and member clusters are backed by in-memory
+// stores. A KubeFedCluster is created in the host store for each of
+// the named clusters.
+func NewFederatedTypeCrudTester(testLogger common.TestLogger, typeConfig typeconfig.Interface, clusterNames []string, clustersNamespace string, waitInterval, clusterWaitTimeout time.Duration) (*common.FederatedTypeCrudTester, *Environment, error) {
+	env := &Environment{
+		HostStore:     NewStore(),
+		ClusterStores: make(map[string]*Store),
+	}
+	hostClient := NewGenericClient(env.HostStore)
+	targetAPIResource := typeConfig.GetTargetType()
+	testClusters := make(map[string]common.TestCluster)
+	for _, clusterName := range clusterNames {
+		cluster := &v1beta1.KubeFedCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: clustersNamespace,
+				Name:      clusterName,
+			},
+		}
+		if err := hostClient.Create(context.Background(), cluster); err != nil {
+			return nil, nil, errors.Wrapf(err, "Error creating KubeFedCluster %q", clusterName)
+		}
+		env.ClusterStores[clusterName] = NewStore()
+		testClusters[clusterName] = common.TestCluster{
+			Client:              env.ClusterClient(clusterName, targetAPIResource),
+			NamespaceClient:     env.ClusterClient(clusterName, common.NamespaceAPIResource),
+			EndpointSliceClient: env.ClusterClient(clusterName, common.EndpointSliceAPIResource),
+		}
+	}
+	resourceClientFor := func(apiResource metav1.APIResource) (utils.ResourceClient, error) {
+		return NewResourceClient(env.HostStore, apiResource), nil
+	}
+	crudTester, err := common.NewFederatedTypeCrudTesterForClients(testLogger, typeConfig, hostClient, resourceClientFor, testClusters, clustersNamespace, waitInterval, clusterWaitTimeout)
+	if err != nil {
+		return nil, nil, err
+	}
+	return crudTester, env, nil
+}
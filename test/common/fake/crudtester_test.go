@@ -0,0 +1,271 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+
+	"sigs.k8s.io/kubefed/pkg/apis/core/common"
+	fedv1a1 "sigs.k8s.io/kubefed/pkg/apis/core/v1alpha1"
+	"sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
+	"sigs.k8s.io/kubefed/pkg/controller/sync"
+	"sigs.k8s.io/kubefed/pkg/controller/sync/status"
+	"sigs.k8s.io/kubefed/pkg/controller/utils"
+	"sigs.k8s.io/kubefed/test/common/fake"
+)
+
+func newConfigMapTypeConfig() *v1beta1.FederatedTypeConfig {
+	return &v1beta1.FederatedTypeConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "configmaps",
+		},
+		Spec: v1beta1.FederatedTypeConfigSpec{
+			TargetType: v1beta1.APIResource{
+				Version:    "v1",
+				Kind:       "ConfigMap",
+				PluralName: "configmaps",
+				Scope:      "Namespaced",
+			},
+			FederatedType: v1beta1.APIResource{
+				Group:      "types.kubefed.io",
+				Version:    "v1beta1",
+				Kind:       "FederatedConfigMap",
+				PluralName: "federatedconfigmaps",
+				Scope:      "Namespaced",
+			},
+			Propagation: v1beta1.PropagationEnabled,
+		},
+	}
+}
+
+// propagate stands in for the sync controller by propagating the
+// template of each federated resource observed by the given watch to
+// the member clusters of its placement, removing it from the others,
+// and recording the result in the host cluster.
+func propagate(t *testing.T, env *fake.Environment, typeConfig *v1beta1.FederatedTypeConfig, w watch.Interface) {
+	ctx := context.Background()
+	hostClient := env.HostClient()
+	fedClient := fake.NewResourceClient(env.HostStore, typeConfig.GetFederatedType())
+	targetAPIResource := typeConfig.GetTargetType()
+
+	for event := range w.ResultChan() {
+		fedObject := event.Object.(*unstructured.Unstructured)
+		if event.Type == watch.Deleted {
+			continue
+		}
+		observedGeneration, _, _ := unstructured.NestedInt64(fedObject.Object, utils.StatusField, "observedGeneration")
+		if observedGeneration == fedObject.GetGeneration() {
+			continue
+		}
+		clusterList := &v1beta1.KubeFedClusterList{}
+		if err := hostClient.List(ctx, clusterList, ""); err != nil {
+			t.Errorf("Error listing clusters: %v", err)
+			return
+		}
+		var clusters []*v1beta1.KubeFedCluster
+		for i := range clusterList.Items {
+			clusters = append(clusters, &clusterList.Items[i])
+		}
+		selectedClusterNames, err := utils.ComputePlacement(fedObject, clusters, false)
+		if err != nil {
+			t.Errorf("Error computing placement: %v", err)
+			return
+		}
+		overridesMap, err := utils.GetOverrides(fedObject)
+		if err != nil {
+			t.Errorf("Error reading overrides: %v", err)
+			return
+		}
+
+		var clusterVersions []fedv1a1.ClusterObjectVersion
+		var clusterStatuses []interface{}
+		for _, clusterName := range sets.List(selectedClusterNames) {
+			template, _, _ := unstructured.NestedMap(fedObject.Object, utils.SpecField, utils.TemplateField)
+			clusterObj := &unstructured.Unstructured{Object: template}
+			clusterObj.SetAPIVersion(targetAPIResource.Version)
+			clusterObj.SetKind(targetAPIResource.Kind)
+			clusterObj.SetNamespace(fedObject.GetNamespace())
+			clusterObj.SetName(fedObject.GetName())
+			utils.AddManagedLabel(clusterObj)
+			if err := utils.ApplyJSONPatch(clusterObj, overridesMap[clusterName]); err != nil {
+				t.Errorf("Error applying overrides for cluster %q: %v", clusterName, err)
+				return
+			}
+
+			client := env.ClusterClient(clusterName, targetAPIResource).Resources(fedObject.GetNamespace())
+			propagatedObj, err := client.Create(ctx, clusterObj, metav1.CreateOptions{})
+			if apierrors.IsAlreadyExists(err) {
+				propagatedObj, err = client.Get(ctx, clusterObj.GetName(), metav1.GetOptions{})
+				if err == nil && !objectCurrent(clusterObj, propagatedObj) {
+					propagatedObj, err = client.Update(ctx, clusterObj, metav1.UpdateOptions{})
+				}
+			}
+			if err != nil {
+				t.Errorf("Error propagating to cluster %q: %v", clusterName, err)
+				return
+			}
+			clusterOverrideVersion, err := sync.GetClusterOverrideHash(overridesMap[clusterName])
+			if err != nil {
+				t.Errorf("Error computing override version for cluster %q: %v", clusterName, err)
+				return
+			}
+			clusterVersions = append(clusterVersions, fedv1a1.ClusterObjectVersion{
+				ClusterName:     clusterName,
+				Version:         utils.ObjectVersion(propagatedObj),
+				OverrideVersion: clusterOverrideVersion,
+			})
+			clusterStatuses = append(clusterStatuses, map[string]interface{}{"name": clusterName})
+		}
+
+		for _, cluster := range clusters {
+			if selectedClusterNames.Has(cluster.Name) {
+				continue
+			}
+			client := env.ClusterClient(cluster.Name, targetAPIResource).Resources(fedObject.GetNamespace())
+			err := client.Delete(ctx, fedObject.GetName(), metav1.DeleteOptions{})
+			if err != nil && !apierrors.IsNotFound(err) {
+				t.Errorf("Error removing from cluster %q: %v", cluster.Name, err)
+				return
+			}
+		}
+
+		templateVersion, err := sync.GetTemplateHash(fedObject.Object)
+		if err != nil {
+			t.Errorf("Error computing template version: %v", err)
+			return
+		}
+		overrideVersion, err := sync.GetOverrideHash(fedObject)
+		if err != nil {
+			t.Errorf("Error computing override version: %v", err)
+			return
+		}
+		version := &fedv1a1.PropagatedVersion{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: fedObject.GetNamespace(),
+				Name:      common.PropagatedVersionName(targetAPIResource.Kind, fedObject.GetName()),
+			},
+			Status: fedv1a1.PropagatedVersionStatus{
+				TemplateVersion: templateVersion,
+				OverrideVersion: overrideVersion,
+				ClusterVersions: clusterVersions,
+			},
+		}
+		err = hostClient.Create(ctx, version)
+		if apierrors.IsAlreadyExists(err) {
+			existingVersion := &fedv1a1.PropagatedVersion{}
+			err = hostClient.Get(ctx, existingVersion, version.Namespace, version.Name)
+			if err == nil {
+				existingVersion.Status = version.Status
+				err = hostClient.UpdateStatus(ctx, existingVersion)
+			}
+		}
+		if err != nil {
+			t.Errorf("Error recording propagated version: %v", err)
+			return
+		}
+
+		conditions := []interface{}{
+			map[string]interface{}{
+				"type":   string(status.PropagationConditionType),
+				"status": "True",
+			},
+		}
+		fedObject.Object[utils.StatusField] = map[string]interface{}{
+			"observedGeneration": fedObject.GetGeneration(),
+			"conditions":         conditions,
+			"clusters":           clusterStatuses,
+		}
+		_, err = fedClient.Resources(fedObject.GetNamespace()).UpdateStatus(ctx, fedObject, metav1.UpdateOptions{})
+		if apierrors.IsConflict(err) {
+			// The resource changed while it was propagated and will be
+			// propagated again for the event of that change.
+			continue
+		}
+		if err != nil {
+			t.Errorf("Error updating status: %v", err)
+			return
+		}
+	}
+}
+
+// objectCurrent returns whether the given existing resource has the
+// metadata and data of the given desired resource.
+func objectCurrent(desiredObj, existingObj *unstructured.Unstructured) bool {
+	return utils.ObjectMetaObjEquivalent(desiredObj, existingObj) && reflect.DeepEqual(desiredObj.Object["data"], existingObj.Object["data"])
+}
+
+func newConfigMap() *unstructured.Unstructured {
+	targetObject := &unstructured.Unstructured{}
+	targetObject.SetAPIVersion("v1")
+	targetObject.SetKind("ConfigMap")
+	targetObject.SetNamespace("foo")
+	targetObject.SetName("bar")
+	targetObject.Object["data"] = map[string]interface{}{"key": "value"}
+	return targetObject
+}
+
+func TestCheckCreateWithFakes(t *testing.T) {
+	typeConfig := newConfigMapTypeConfig()
+	crudTester, env, err := fake.NewFederatedTypeCrudTester(t, typeConfig, []string{"cluster1", "cluster2"}, "kube-federation-system", 10*time.Millisecond, wait.ForeverTestTimeout)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	fedClient := fake.NewResourceClient(env.HostStore, typeConfig.GetFederatedType())
+	w, err := fedClient.Resources("").Watch(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer w.Stop()
+	go propagate(t, env, typeConfig, w)
+
+	fedObject := crudTester.CheckCreate(context.Background(), true, newConfigMap(), nil, nil)
+
+	for _, clusterName := range []string{"cluster1", "cluster2"} {
+		clusterObj, err := env.ClusterClient(clusterName, typeConfig.GetTargetType()).Resources("foo").Get(context.Background(), "bar", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Expected %s %q to be propagated to cluster %q: %v", fedObject.GetKind(), utils.NewQualifiedName(fedObject), clusterName, err)
+		}
+		if value, _, _ := unstructured.NestedString(clusterObj.Object, "data", "key"); value != "value" {
+			t.Fatalf("Expected the data of the template to be propagated to cluster %q", clusterName)
+		}
+	}
+}
+
+func TestPlacementWarningForSelectorMatchingNoCluster(t *testing.T) {
+	typeConfig := newConfigMapTypeConfig()
+	crudTester, _, err := fake.NewFederatedTypeCrudTester(t, typeConfig, []string{"cluster1"}, "kube-federation-system", 10*time.Millisecond, wait.ForeverTestTimeout)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	fedObject := crudTester.Create(newConfigMap(), nil, map[string]string{"regoin": "us-east"})
+
+	if warnings := crudTester.PlacementWarnings(fedObject); len(warnings) == 0 {
+		t.Fatalf("Expected a warning for a cluster selector that matches no cluster")
+	}
+}
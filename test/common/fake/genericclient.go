@@ -0,0 +1,215 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"context"
+	"strings"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/pkg/errors"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+	"sigs.k8s.io/kubefed/pkg/client/generic"
+	"sigs.k8s.io/kubefed/pkg/client/generic/scheme"
+	"sigs.k8s.io/kubefed/pkg/controller/utils"
+)
+
+type genericClient struct {
+	store *Store
+}
+
+// NewGenericClient returns a generic client backed by the given
+// store. Typed objects are stored in unstructured form so that they
+// can also be accessed with a client returned by NewResourceClient.
+func NewGenericClient(store *Store) generic.Client {
+	return &genericClient{store: store}
+}
+
+var _ generic.Client = &genericClient{}
+
+func (c *genericClient) Create(ctx context.Context, obj runtimeclient.Object) error {
+	uns, err := toUnstructured(obj)
+	if err != nil {
+		return err
+	}
+	created, err := c.store.Create(uns)
+	if err != nil {
+		return err
+	}
+	return fromUnstructured(created, obj)
+}
+
+func (c *genericClient) Get(ctx context.Context, obj runtimeclient.Object, namespace, name string) error {
+	gvk, err := gvkForObject(obj)
+	if err != nil {
+		return err
+	}
+	stored, err := c.store.Get(gvk, utils.QualifiedName{Namespace: namespace, Name: name})
+	if err != nil {
+		return err
+	}
+	return fromUnstructured(stored, obj)
+}
+
+func (c *genericClient) Update(ctx context.Context, obj runtimeclient.Object) error {
+	return c.update(obj, false)
+}
+
+func (c *genericClient) UpdateStatus(ctx context.Context, obj runtimeclient.Object) error {
+	return c.update(obj, true)
+}
+
+func (c *genericClient) update(obj runtimeclient.Object, statusOnly bool) error {
+	uns, err := toUnstructured(obj)
+	if err != nil {
+		return err
+	}
+	updated, err := c.store.Update(uns, statusOnly)
+	if err != nil {
+		return err
+	}
+	return fromUnstructured(updated, obj)
+}
+
+func (c *genericClient) Delete(ctx context.Context, obj runtimeclient.Object, namespace, name string, opts ...runtimeclient.DeleteOption) error {
+	gvk, err := gvkForObject(obj)
+	if err != nil {
+		return err
+	}
+	deleteOptions := &runtimeclient.DeleteOptions{}
+	deleteOptions.ApplyOptions(opts)
+	return c.store.Delete(gvk, utils.QualifiedName{Namespace: namespace, Name: name}, deleteOptions.Preconditions)
+}
+
+func (c *genericClient) List(ctx context.Context, obj runtimeclient.ObjectList, namespace string, opts ...runtimeclient.ListOption) error {
+	listGVK, err := gvkForObject(obj)
+	if err != nil {
+		return err
+	}
+	gvk := listGVK.GroupVersion().WithKind(strings.TrimSuffix(listGVK.Kind, "List"))
+	listOptions := &runtimeclient.ListOptions{}
+	listOptions.ApplyOptions(opts)
+
+	items := []interface{}{}
+	for _, item := range c.store.List(gvk, namespace, listOptions.LabelSelector) {
+		items = append(items, item.Object)
+	}
+	list := &unstructured.Unstructured{Object: map[string]interface{}{"items": items}}
+	list.SetGroupVersionKind(listGVK)
+	if uns, ok := obj.(*unstructured.UnstructuredList); ok {
+		return list.EachListItem(func(item runtime.Object) error {
+			uns.Items = append(uns.Items, *item.(*unstructured.Unstructured))
+			return nil
+		})
+	}
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(list.Object, obj)
+}
+
+func (c *genericClient) Patch(ctx context.Context, obj runtimeclient.Object, patch runtimeclient.Patch, opts ...runtimeclient.PatchOption) error {
+	gvk, err := gvkForObject(obj)
+	if err != nil {
+		return err
+	}
+	data, err := patch.Data(obj)
+	if err != nil {
+		return err
+	}
+	stored, err := c.store.Get(gvk, utils.QualifiedName{Namespace: obj.GetNamespace(), Name: obj.GetName()})
+	if err != nil {
+		return err
+	}
+	patched, err := applyPatch(stored, patch.Type(), data)
+	if err != nil {
+		return err
+	}
+	updated, err := c.store.Update(patched, false)
+	if err != nil {
+		return err
+	}
+	return fromUnstructured(updated, obj)
+}
+
+// applyPatch returns a copy of the given object with the given merge
+// or JSON patch applied.
+func applyPatch(obj *unstructured.Unstructured, patchType types.PatchType, data []byte) (*unstructured.Unstructured, error) {
+	original, err := obj.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	var patchedJSON []byte
+	switch patchType {
+	case types.MergePatchType:
+		patchedJSON, err = jsonpatch.MergePatch(original, data)
+	case types.JSONPatchType:
+		var patch jsonpatch.Patch
+		patch, err = jsonpatch.DecodePatch(data)
+		if err == nil {
+			patchedJSON, err = patch.Apply(original)
+		}
+	default:
+		return nil, errors.Errorf("Unsupported patch type %q", patchType)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "Error applying patch")
+	}
+	patched := &unstructured.Unstructured{}
+	if err := patched.UnmarshalJSON(patchedJSON); err != nil {
+		return nil, err
+	}
+	return patched, nil
+}
+
+func gvkForObject(obj runtime.Object) (schema.GroupVersionKind, error) {
+	if uns, ok := obj.(runtime.Unstructured); ok {
+		return uns.GetObjectKind().GroupVersionKind(), nil
+	}
+	return apiutil.GVKForObject(obj, scheme.Scheme)
+}
+
+func toUnstructured(obj runtimeclient.Object) (*unstructured.Unstructured, error) {
+	gvk, err := gvkForObject(obj)
+	if err != nil {
+		return nil, err
+	}
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	uns := &unstructured.Unstructured{Object: content}
+	uns.SetGroupVersionKind(gvk)
+	return uns, nil
+}
+
+func fromUnstructured(uns *unstructured.Unstructured, obj runtimeclient.Object) error {
+	if target, ok := obj.(*unstructured.Unstructured); ok {
+		target.Object = uns.Object
+		return nil
+	}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(uns.Object, obj); err != nil {
+		return err
+	}
+	// Typed objects do not retain their kind when converted.
+	obj.GetObjectKind().SetGroupVersionKind(uns.GroupVersionKind())
+	return nil
+}
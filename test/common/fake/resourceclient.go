@@ -0,0 +1,203 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+
+	"sigs.k8s.io/kubefed/pkg/controller/utils"
+)
+
+type resourceClient struct {
+	store      *Store
+	gvk        schema.GroupVersionKind
+	namespaced bool
+}
+
+// NewResourceClient returns a client for the given API resource that
+// is backed by the given store.
+func NewResourceClient(store *Store, apiResource metav1.APIResource) utils.ResourceClient {
+	return &resourceClient{
+		store: store,
+		gvk: schema.GroupVersionKind{
+			Group:   apiResource.Group,
+			Version: apiResource.Version,
+			Kind:    apiResource.Kind,
+		},
+		namespaced: apiResource.Namespaced,
+	}
+}
+
+func (c *resourceClient) Resources(namespace string) dynamic.ResourceInterface {
+	if !c.namespaced {
+		namespace = ""
+	}
+	return &resourceInterface{resourceClient: c, namespace: namespace}
+}
+
+func (c *resourceClient) Kind() string {
+	return c.gvk.Kind
+}
+
+type resourceInterface struct {
+	*resourceClient
+	namespace string
+}
+
+var _ dynamic.ResourceInterface = &resourceInterface{}
+
+func (r *resourceInterface) qualifiedName(name string) utils.QualifiedName {
+	return utils.QualifiedName{Namespace: r.namespace, Name: name}
+}
+
+// prepare returns a copy of the given object with the kind and
+// namespace of the interface. As for an object sent to an API
+// server, the copy is made by serializing the object so that typed
+// values (e.g. overrides set by utils.SetOverrides) are accepted.
+func (r *resourceInterface) prepare(obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	content, err := obj.MarshalJSON()
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to serialize object")
+	}
+	prepared := &unstructured.Unstructured{}
+	if err := prepared.UnmarshalJSON(content); err != nil {
+		return nil, errors.Wrap(err, "Failed to deserialize object")
+	}
+	prepared.SetGroupVersionKind(r.gvk)
+	prepared.SetNamespace(r.namespace)
+	return prepared, nil
+}
+
+func (r *resourceInterface) Create(ctx context.Context, obj *unstructured.Unstructured, options metav1.CreateOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	if len(subresources) > 0 {
+		return nil, errors.Errorf("Subresources are not supported for create: %v", subresources)
+	}
+	prepared, err := r.prepare(obj)
+	if err != nil {
+		return nil, err
+	}
+	return r.store.Create(prepared)
+}
+
+func (r *resourceInterface) Update(ctx context.Context, obj *unstructured.Unstructured, options metav1.UpdateOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	statusOnly, err := isStatusSubresource(subresources)
+	if err != nil {
+		return nil, err
+	}
+	prepared, err := r.prepare(obj)
+	if err != nil {
+		return nil, err
+	}
+	return r.store.Update(prepared, statusOnly)
+}
+
+func (r *resourceInterface) UpdateStatus(ctx context.Context, obj *unstructured.Unstructured, options metav1.UpdateOptions) (*unstructured.Unstructured, error) {
+	prepared, err := r.prepare(obj)
+	if err != nil {
+		return nil, err
+	}
+	return r.store.Update(prepared, true)
+}
+
+func (r *resourceInterface) Delete(ctx context.Context, name string, options metav1.DeleteOptions, subresources ...string) error {
+	if len(subresources) > 0 {
+		return errors.Errorf("Subresources are not supported for delete: %v", subresources)
+	}
+	return r.store.Delete(r.gvk, r.qualifiedName(name), options.Preconditions)
+}
+
+func (r *resourceInterface) DeleteCollection(ctx context.Context, options metav1.DeleteOptions, listOptions metav1.ListOptions) error {
+	list, err := r.List(ctx, listOptions)
+	if err != nil {
+		return err
+	}
+	for _, obj := range list.Items {
+		if err := r.store.Delete(r.gvk, utils.NewQualifiedName(&obj), options.Preconditions); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *resourceInterface) Get(ctx context.Context, name string, options metav1.GetOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	if _, err := isStatusSubresource(subresources); err != nil {
+		return nil, err
+	}
+	return r.store.Get(r.gvk, r.qualifiedName(name))
+}
+
+func (r *resourceInterface) List(ctx context.Context, opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+	selector, err := labels.Parse(opts.LabelSelector)
+	if err != nil {
+		return nil, err
+	}
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(r.gvk.GroupVersion().WithKind(r.gvk.Kind + "List"))
+	for _, obj := range r.store.List(r.gvk, r.namespace, selector) {
+		list.Items = append(list.Items, *obj)
+	}
+	return list, nil
+}
+
+func (r *resourceInterface) Watch(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error) {
+	return r.store.Watch(r.gvk, r.namespace)
+}
+
+func (r *resourceInterface) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, options metav1.PatchOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	statusOnly, err := isStatusSubresource(subresources)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := r.store.Get(r.gvk, r.qualifiedName(name))
+	if err != nil {
+		return nil, err
+	}
+	patched, err := applyPatch(obj, pt, data)
+	if err != nil {
+		return nil, err
+	}
+	return r.store.Update(patched, statusOnly)
+}
+
+func (r *resourceInterface) Apply(ctx context.Context, name string, obj *unstructured.Unstructured, options metav1.ApplyOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	return nil, errors.New("Apply is not supported")
+}
+
+func (r *resourceInterface) ApplyStatus(ctx context.Context, name string, obj *unstructured.Unstructured, options metav1.ApplyOptions) (*unstructured.Unstructured, error) {
+	return nil, errors.New("Apply is not supported")
+}
+
+func isStatusSubresource(subresources []string) (bool, error) {
+	switch {
+	case len(subresources) == 0:
+		return false, nil
+	case len(subresources) == 1 && subresources[0] == utils.StatusField:
+		return true, nil
+	default:
+		return false, errors.Errorf("Unsupported subresources: %v", subresources)
+	}
+}
@@ -0,0 +1,269 @@
+/*
+Copyright 2024 The CodeFuture Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/apimachinery/pkg/watch"
+
+	"sigs.k8s.io/kubefed/pkg/controller/utils"
+)
+
+const watchQueueLength = 100
+
+// Store is an in-memory substitute for the API of a cluster. Objects
+// are stored in unstructured form keyed by kind and qualified name,
+// and all objects are treated as having a status subresource: an
+// update ignores changes to status and a status update ignores
+// changes to anything else.
+type Store struct {
+	sync.Mutex
+	objects         map[schema.GroupVersionKind]map[utils.QualifiedName]*unstructured.Unstructured
+	resourceVersion int64
+	broadcaster     *watch.Broadcaster
+}
+
+// NewStore returns an empty store.
+func NewStore() *Store {
+	return &Store{
+		objects:     make(map[schema.GroupVersionKind]map[utils.QualifiedName]*unstructured.Unstructured),
+		broadcaster: watch.NewBroadcaster(watchQueueLength, watch.WaitIfChannelFull),
+	}
+}
+
+// Watch returns a watch of changes to objects of the given kind in
+// the given namespace, or in all namespaces if the namespace is
+// empty. Only changes made after the watch is started are observed.
+func (s *Store) Watch(gvk schema.GroupVersionKind, namespace string) (watch.Interface, error) {
+	w, err := s.broadcaster.Watch()
+	if err != nil {
+		return nil, err
+	}
+	return watch.Filter(w, func(in watch.Event) (watch.Event, bool) {
+		obj, ok := in.Object.(*unstructured.Unstructured)
+		if !ok {
+			return in, false
+		}
+		return in, obj.GroupVersionKind() == gvk && (len(namespace) == 0 || obj.GetNamespace() == namespace)
+	}), nil
+}
+
+// Get returns a copy of the stored object of the given kind and name.
+func (s *Store) Get(gvk schema.GroupVersionKind, qualifiedName utils.QualifiedName) (*unstructured.Unstructured, error) {
+	s.Lock()
+	defer s.Unlock()
+	obj, ok := s.objects[gvk][qualifiedName]
+	if !ok {
+		return nil, notFound(gvk, qualifiedName.Name)
+	}
+	return obj.DeepCopy(), nil
+}
+
+// List returns copies of the stored objects of the given kind in the
+// given namespace, or in all namespaces if the namespace is empty,
+// that match the given selector.
+func (s *Store) List(gvk schema.GroupVersionKind, namespace string, selector labels.Selector) []*unstructured.Unstructured {
+	s.Lock()
+	defer s.Unlock()
+	var objs []*unstructured.Unstructured
+	for qualifiedName, obj := range s.objects[gvk] {
+		if len(namespace) > 0 && qualifiedName.Namespace != namespace {
+			continue
+		}
+		if selector != nil && !selector.Matches(labels.Set(obj.GetLabels())) {
+			continue
+		}
+		objs = append(objs, obj.DeepCopy())
+	}
+	return objs
+}
+
+// Create stores the given object, generating a name if the object
+// has a generate name but no name, and returns a copy of the stored
+// object.
+func (s *Store) Create(obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	obj = obj.DeepCopy()
+	gvk := obj.GroupVersionKind()
+	if len(obj.GetName()) == 0 && len(obj.GetGenerateName()) > 0 {
+		obj.SetName(obj.GetGenerateName() + utilrand.String(5))
+	}
+	if len(obj.GetName()) == 0 {
+		return nil, apierrors.NewBadRequest("name or generateName is required")
+	}
+	qualifiedName := utils.NewQualifiedName(obj)
+
+	s.Lock()
+	if _, ok := s.objects[gvk][qualifiedName]; ok {
+		s.Unlock()
+		return nil, apierrors.NewAlreadyExists(groupResource(gvk), qualifiedName.Name)
+	}
+	obj.SetUID(types.UID(utilrand.String(16)))
+	obj.SetCreationTimestamp(metav1.Now())
+	obj.SetGeneration(1)
+	obj.SetResourceVersion(s.nextResourceVersion())
+	if s.objects[gvk] == nil {
+		s.objects[gvk] = make(map[utils.QualifiedName]*unstructured.Unstructured)
+	}
+	s.objects[gvk][qualifiedName] = obj
+	obj = obj.DeepCopy()
+	s.Unlock()
+
+	s.notify(watch.Added, obj)
+	return obj, nil
+}
+
+// Update replaces the stored object with the given object and
+// returns a copy of the result. A non-empty resource version that
+// does not match the stored object results in a conflict. If
+// statusOnly is true, only the status of the stored object is
+// replaced. An object whose deletion is pending is removed once its
+// last finalizer is removed.
+func (s *Store) Update(obj *unstructured.Unstructured, statusOnly bool) (*unstructured.Unstructured, error) {
+	gvk := obj.GroupVersionKind()
+	qualifiedName := utils.NewQualifiedName(obj)
+
+	s.Lock()
+	stored, ok := s.objects[gvk][qualifiedName]
+	if !ok {
+		s.Unlock()
+		return nil, notFound(gvk, qualifiedName.Name)
+	}
+	if rv := obj.GetResourceVersion(); len(rv) > 0 && rv != stored.GetResourceVersion() {
+		s.Unlock()
+		return nil, apierrors.NewConflict(groupResource(gvk), qualifiedName.Name,
+			errors.New("the object has been modified; please apply your changes to the latest version and try again"))
+	}
+
+	var updated *unstructured.Unstructured
+	if statusOnly {
+		updated = stored.DeepCopy()
+		if status, ok := obj.Object[utils.StatusField]; ok {
+			updated.Object[utils.StatusField] = runtime.DeepCopyJSONValue(status)
+		} else {
+			delete(updated.Object, utils.StatusField)
+		}
+	} else {
+		updated = obj.DeepCopy()
+		if status, ok := stored.Object[utils.StatusField]; ok {
+			updated.Object[utils.StatusField] = runtime.DeepCopyJSONValue(status)
+		} else {
+			delete(updated.Object, utils.StatusField)
+		}
+		// Fields maintained by the API cannot be changed by an update.
+		updated.SetUID(stored.GetUID())
+		updated.SetCreationTimestamp(stored.GetCreationTimestamp())
+		updated.SetDeletionTimestamp(stored.GetDeletionTimestamp())
+		updated.SetGeneration(stored.GetGeneration())
+		if !reflect.DeepEqual(withoutMetadataAndStatus(stored), withoutMetadataAndStatus(updated)) {
+			updated.SetGeneration(stored.GetGeneration() + 1)
+		}
+	}
+	updated.SetResourceVersion(s.nextResourceVersion())
+
+	eventType := watch.Modified
+	if updated.GetDeletionTimestamp() != nil && len(updated.GetFinalizers()) == 0 {
+		delete(s.objects[gvk], qualifiedName)
+		eventType = watch.Deleted
+	} else {
+		s.objects[gvk][qualifiedName] = updated
+	}
+	updated = updated.DeepCopy()
+	s.Unlock()
+
+	s.notify(eventType, updated)
+	return updated, nil
+}
+
+// Delete removes the stored object of the given kind and name. An
+// object with finalizers is instead marked for deletion and removed
+// once its finalizers have been removed. Preconditions that do not
+// match the stored object result in a conflict.
+func (s *Store) Delete(gvk schema.GroupVersionKind, qualifiedName utils.QualifiedName, preconditions *metav1.Preconditions) error {
+	s.Lock()
+	stored, ok := s.objects[gvk][qualifiedName]
+	if !ok {
+		s.Unlock()
+		return notFound(gvk, qualifiedName.Name)
+	}
+	if preconditions != nil && ((preconditions.UID != nil && *preconditions.UID != stored.GetUID()) ||
+		(preconditions.ResourceVersion != nil && *preconditions.ResourceVersion != stored.GetResourceVersion())) {
+		s.Unlock()
+		return apierrors.NewConflict(groupResource(gvk), qualifiedName.Name,
+			errors.New("the preconditions of the deletion do not match the object"))
+	}
+	eventType := watch.Deleted
+	if len(stored.GetFinalizers()) > 0 {
+		if stored.GetDeletionTimestamp() == nil {
+			now := metav1.Now()
+			stored.SetDeletionTimestamp(&now)
+			stored.SetResourceVersion(s.nextResourceVersion())
+		}
+		eventType = watch.Modified
+	} else {
+		delete(s.objects[gvk], qualifiedName)
+	}
+	obj := stored.DeepCopy()
+	s.Unlock()
+
+	s.notify(eventType, obj)
+	return nil
+}
+
+// nextResourceVersion must be called with the lock held.
+func (s *Store) nextResourceVersion() string {
+	s.resourceVersion++
+	return strconv.FormatInt(s.resourceVersion, 10)
+}
+
+// notify must be called without the lock held since delivery of the
+// event may block on a watcher that is accessing the store.
+func (s *Store) notify(eventType watch.EventType, obj *unstructured.Unstructured) {
+	_ = s.broadcaster.Action(eventType, obj)
+}
+
+func withoutMetadataAndStatus(obj *unstructured.Unstructured) map[string]interface{} {
+	content := make(map[string]interface{}, len(obj.Object))
+	for key, value := range obj.Object {
+		if key == utils.MetadataField || key == utils.StatusField {
+			continue
+		}
+		content[key] = value
+	}
+	return content
+}
+
+func groupResource(gvk schema.GroupVersionKind) schema.GroupResource {
+	return schema.GroupResource{Group: gvk.Group, Resource: strings.ToLower(gvk.Kind)}
+}
+
+func notFound(gvk schema.GroupVersionKind, name string) error {
+	return apierrors.NewNotFound(groupResource(gvk), name)
+}
@@ -27,6 +27,10 @@ import (
 	kubeclientset "k8s.io/client-go/kubernetes"
 	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 
+	"sigs.k8s.io/kubefed/pkg/apis/core/common"
+	"sigs.k8s.io/kubefed/pkg/apis/core/typeconfig"
+	genericclient "sigs.k8s.io/kubefed/pkg/client/generic"
+	versionmanager "sigs.k8s.io/kubefed/pkg/controller/sync/version"
 	"sigs.k8s.io/kubefed/pkg/controller/utils"
 )
 
@@ -70,3 +74,31 @@ func WaitForNamespaceOrDie(tl TestLogger, client kubeclientset.Interface, cluste
 			namespace, clusterName, err)
 	}
 }
+
+// PropagatedVersionQualifiedName returns the name of the propagated
+// version recorded by the sync controller for the named federated
+// resource. Propagated versions are stored in the namespace of the
+// federated resource, which for a federated namespace is the
+// namespace itself.
+func PropagatedVersionQualifiedName(typeConfig typeconfig.Interface, fedName utils.QualifiedName) utils.QualifiedName {
+	return utils.QualifiedName{
+		Namespace: fedName.Namespace,
+		Name:      common.PropagatedVersionName(typeConfig.GetTargetType().Kind, fedName.Name),
+	}
+}
+
+// WaitForVersionDeletion waits for the named propagated version of a
+// federated type to be removed. The version adapter for the type
+// determines whether a namespaced or cluster-scoped version is
+// expected.
+func WaitForVersionDeletion(ctx context.Context, client genericclient.Client, typeConfig typeconfig.Interface, versionName utils.QualifiedName, interval, timeout time.Duration) error {
+	adapter := versionmanager.NewVersionAdapter(typeConfig.GetFederatedNamespaced())
+	return wait.PollUntilContextTimeout(ctx, interval, timeout, true, func(ctx context.Context) (bool, error) {
+		err := client.Get(ctx, adapter.NewObject(), versionName.Namespace, versionName.Name)
+		if errors.IsNotFound(err) {
+			return true, nil
+		}
+		// Errors other than NotFound may be recoverable
+		return false, nil
+	})
+}
@@ -23,7 +23,9 @@ import (
 	"time"
 
 	apiv1 "k8s.io/api/core/v1"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/util/wait"
 	kubeclientset "k8s.io/client-go/kubernetes"
@@ -33,6 +35,7 @@ import (
 	genericclient "sigs.k8s.io/kubefed/pkg/client/generic"
 	"sigs.k8s.io/kubefed/pkg/controller/sync/status"
 	"sigs.k8s.io/kubefed/pkg/controller/utils"
+	"sigs.k8s.io/kubefed/pkg/features"
 	"sigs.k8s.io/kubefed/pkg/kubefedctl/federate"
 	"sigs.k8s.io/kubefed/test/common"
 	"sigs.k8s.io/kubefed/test/e2e/framework"
@@ -66,6 +69,80 @@ var _ = Describe("Federated", func() {
 				crudTester.CheckLifecycle(ctx, immediate, targetObject, overrides, nil)
 			})
 
+			It("should report the result of applying the resource to each cluster", func() {
+				typeConfig, testObjectsFunc := getCrudTestInput(f, tl, typeConfigName, fixture)
+				crudTester, targetObject, overrides := initCrudTest(f, tl, f.KubeFedSystemNamespace(), typeConfig, testObjectsFunc)
+				fedObject := crudTester.CheckCreate(ctx, immediate, targetObject, overrides, nil)
+
+				var clusterNames []string
+				for key := range crudTester.TestClusters() {
+					clusterNames = append(clusterNames, key)
+				}
+
+				// Writing the status of the federated resource triggers
+				// another reconcile that finds the managed resources
+				// current.
+				By("Waiting for a reconcile that leaves the managed resources unchanged")
+				crudTester.CheckApplyResult(ctx, immediate, fedObject, clusterNames, status.ApplyUnchanged)
+
+				crudTester.CheckDelete(ctx, immediate, fedObject, false)
+			})
+
+			if typeConfigName == "deployments.apps" {
+				It("should only manage the included fields of a co-owned resource", func() {
+					if !framework.TestContext.InMemoryControllers {
+						framework.Skipf("Included fields require a type config that is only configured for in-memory controllers")
+					}
+
+					typeConfig, testObjectsFunc := getCrudTestInput(f, tl, typeConfigName, fixture)
+					tc := typeConfig.(*v1beta1.FederatedTypeConfig).DeepCopy()
+					tc.Spec.IncludedFields = []string{"spec.replicas"}
+					crudTester, targetObject, overrides := initCrudTest(f, tl, f.KubeFedSystemNamespace(), tc, testObjectsFunc)
+
+					fedObject := crudTester.CheckCreate(ctx, immediate, targetObject, overrides, nil)
+
+					By("Updating the replicas of the federated deployment")
+					fedObject = crudTester.CheckCoOwnership(ctx, immediate, fedObject, "spec.minReadySeconds", int64(5), func(template map[string]interface{}) {
+						replicas, _, _ := unstructured.NestedInt64(template, "spec", "replicas")
+						if err := unstructured.SetNestedField(template, replicas+1, "spec", "replicas"); err != nil {
+							tl.Fatalf("Error setting replicas: %v", err)
+						}
+					})
+
+					crudTester.CheckDelete(ctx, immediate, fedObject, false)
+				})
+			}
+
+			if typeConfigName == "services" {
+				It("should report the ready endpoints of the service in each cluster", func() {
+					if !framework.TestContext.InMemoryControllers {
+						framework.Skipf("Collection of ready endpoints requires a type config that is only configured for in-memory controllers")
+					}
+
+					typeConfig, testObjectsFunc := getCrudTestInput(f, tl, typeConfigName, fixture)
+					tc := typeConfig.(*v1beta1.FederatedTypeConfig).DeepCopy()
+					statusCollection := v1beta1.StatusCollectionEnabled
+					tc.Spec.StatusCollection = &statusCollection
+					tc.Spec.FeatureGates = []v1beta1.FeatureGatesConfig{
+						{Name: string(features.RawResourceStatusCollection), Configuration: v1beta1.ConfigurationEnabled},
+					}
+					crudTester, targetObject, overrides := initCrudTest(f, tl, f.KubeFedSystemNamespace(), tc, testObjectsFunc)
+					fedObject := crudTester.CheckCreate(ctx, immediate, targetObject, overrides, nil)
+
+					// Each cluster reports a different number of ready
+					// endpoints.
+					readyEndpoints := make(map[string]int)
+					for clusterName := range crudTester.TestClusters() {
+						readyEndpoints[clusterName] = len(readyEndpoints) + 1
+					}
+
+					By("Creating EndpointSlices of the service in each cluster")
+					fedObject = crudTester.CheckReadyEndpoints(ctx, immediate, fedObject, readyEndpoints)
+
+					crudTester.CheckDelete(ctx, immediate, fedObject, false)
+				})
+			}
+
 			for _, remoteStatusTypeName := range containedTypeNames {
 				if typeConfigName == remoteStatusTypeName {
 
@@ -90,6 +167,18 @@ var _ = Describe("Federated", func() {
 							crudTester.CheckDelete(ctx, immediate, fedObject, false)
 						}()
 					})
+
+					It("should remove the remote status of a cluster removed from placement", func() {
+						typeConfig, testObjectsFunc := getCrudTestInput(f, tl, typeConfigName, fixture)
+						crudTester, targetObject, overrides := initCrudTest(f, tl, f.KubeFedSystemNamespace(), typeConfig, testObjectsFunc)
+						fedObject := crudTester.CheckCreate(ctx, immediate, targetObject, overrides, nil)
+						defer func() {
+							crudTester.CheckDelete(ctx, immediate, fedObject, false)
+						}()
+
+						By("Checking that the status of a cluster removed from placement is removed from the federated resource")
+						crudTester.CheckRemoteStatusRemoval(ctx, immediate, fedObject)
+					})
 				}
 			}
 
@@ -142,6 +231,474 @@ var _ = Describe("Federated", func() {
 				}
 			})
 
+			It("should only propagate to the namespaces of member clusters that have opted in to management", func() {
+				if !framework.TestContext.InMemoryControllers {
+					framework.Skipf("Namespace opt-in requires a controller configuration that is only used for in-memory controllers")
+				}
+				if framework.TestContext.LimitedScope {
+					framework.Skipf("Labeling the test namespace in member clusters requires the namespace to be federated")
+				}
+
+				typeConfig, testObjectsFunc := getCrudTestInput(f, tl, typeConfigName, fixture)
+				controllerConfig := f.ControllerConfig()
+				controllerConfig.NamespaceOptInLabel = "kubefed-e2e-opt-in"
+				crudTester, targetObject, _ := initCrudTestWithControllerConfig(f, tl, f.KubeFedSystemNamespace(), typeConfig, testObjectsFunc, true, controllerConfig)
+
+				blockedClusterName := ""
+				for key := range crudTester.TestClusters() {
+					blockedClusterName = key
+					break
+				}
+
+				By(fmt.Sprintf("Labeling the test namespace to opt in to management in all clusters but %q", blockedClusterName))
+				labelTestNamespace(ctx, immediate, f, tl, controllerConfig.NamespaceOptInLabel, blockedClusterName)
+
+				fedObject := crudTester.CheckNamespaceOptIn(ctx, immediate, targetObject, blockedClusterName)
+
+				crudTester.CheckDelete(ctx, immediate, fedObject, false)
+			})
+
+			It("should annotate managed resources with the clusters they are propagated to", func() {
+				if !framework.TestContext.InMemoryControllers {
+					framework.Skipf("The placement annotation requires a controller configuration that is only used for in-memory controllers")
+				}
+
+				typeConfig, testObjectsFunc := getCrudTestInput(f, tl, typeConfigName, fixture)
+				controllerConfig := f.ControllerConfig()
+				controllerConfig.PlacementAnnotation = "kubefed-e2e-placement"
+				crudTester, targetObject, overrides := initCrudTestWithControllerConfig(f, tl, f.KubeFedSystemNamespace(), typeConfig, testObjectsFunc, true, controllerConfig)
+				if len(crudTester.TestClusters()) < 2 {
+					framework.Skipf("Updating the placement annotation on placement change requires at least 2 clusters")
+				}
+				crudTester.ExpectPlacementAnnotation(controllerConfig.PlacementAnnotation)
+				fedObject := crudTester.CheckCreate(ctx, immediate, targetObject, overrides, nil)
+
+				By("Removing a cluster from placement")
+				crudTester.CheckPlacementChange(ctx, immediate, fedObject)
+
+				crudTester.CheckDelete(ctx, immediate, fedObject, false)
+			})
+
+			It("should only constrain placement to the placement of the federated namespace with intersection placement inheritance", func() {
+				if !framework.TestContext.InMemoryControllers {
+					framework.Skipf("Placement inheritance requires the namespace sync controller to be stopped, which is only possible for in-memory controllers")
+				}
+				if framework.TestContext.LimitedScope {
+					framework.Skipf("Placement inheritance requires a federated namespace, which is not used by a namespace-scoped control plane")
+				}
+
+				typeConfig, testObjectsFunc := getCrudTestInput(f, tl, typeConfigName, fixture)
+				// The test namespace is not propagated by the namespace
+				// sync controller so that narrowing the placement of the
+				// federated namespace does not remove the namespace from
+				// member clusters.
+				crudTester, targetObject, overrides := initCrudTestWithPropagation(f, tl, f.KubeFedSystemNamespace(), typeConfig, testObjectsFunc, false)
+				if len(crudTester.TestClusters()) < 2 {
+					framework.Skipf("Placement inheritance requires at least 2 clusters")
+				}
+				createdNamespaces := createTestNamespaceInClusters(f, tl)
+				defer func() {
+					for _, kubeClient := range createdNamespaces {
+						framework.DeleteNamespace(kubeClient, f.TestNamespaceName())
+					}
+				}()
+				f.EnsureTestFederatedNamespace(true)
+
+				client := genericclient.NewForConfigOrDie(f.KubeConfig())
+				namespaceTypeConfig, err := common.GetTypeConfig(client, utils.NamespaceName, f.KubeFedSystemNamespace())
+				if err != nil {
+					tl.Fatalf("Error retrieving federatedtypeconfig %q: %v", utils.NamespaceName, err)
+				}
+
+				fedObject := crudTester.CheckCreate(ctx, immediate, targetObject, overrides, nil)
+
+				namespaceClusterName := ""
+				for key := range crudTester.TestClusters() {
+					namespaceClusterName = key
+					break
+				}
+
+				By(fmt.Sprintf("Placing the federated namespace only to cluster %q", namespaceClusterName))
+				fedObject = crudTester.CheckPlacementInheritance(ctx, immediate, fedObject, namespaceTypeConfig.GetFederatedType(), namespaceClusterName)
+
+				crudTester.CheckDelete(ctx, immediate, fedObject, false)
+			})
+
+			It("should apply a cluster-local override without updating the resources in other clusters", func() {
+				typeConfig, testObjectsFunc := getCrudTestInput(f, tl, typeConfigName, fixture)
+				crudTester, targetObject, overrides := initCrudTest(f, tl, f.KubeFedSystemNamespace(), typeConfig, testObjectsFunc)
+				if len(crudTester.TestClusters()) < 2 {
+					framework.Skipf("Checking the resources in other clusters requires at least 2 clusters")
+				}
+				fedObject := crudTester.CheckCreate(ctx, immediate, targetObject, overrides, nil)
+
+				clusterName := ""
+				for key := range crudTester.TestClusters() {
+					clusterName = key
+					break
+				}
+
+				By(fmt.Sprintf("Adding a cluster-local override for cluster %q", clusterName))
+				fedObject = crudTester.CheckClusterLocalOverride(ctx, immediate, fedObject, clusterName)
+
+				crudTester.CheckDelete(ctx, immediate, fedObject, false)
+			})
+
+			It("should not diverge from its propagated version and status once propagated", func() {
+				typeConfig, testObjectsFunc := getCrudTestInput(f, tl, typeConfigName, fixture)
+				crudTester, targetObject, overrides := initCrudTest(f, tl, f.KubeFedSystemNamespace(), typeConfig, testObjectsFunc)
+				fedObject := crudTester.CheckCreate(ctx, immediate, targetObject, overrides, nil)
+
+				By("Checking the consistency of the propagated version, the status and the managed resources")
+				crudTester.CheckConsistency(ctx, fedObject)
+
+				crudTester.CheckDelete(ctx, immediate, fedObject, false)
+			})
+
+			It("should clean up the references of objects to a removed cluster", func() {
+				if !framework.TestContext.InMemoryControllers {
+					framework.Skipf("Pruning removed clusters from placement requires a controller configuration that is only used for in-memory controllers")
+				}
+
+				typeConfig, testObjectsFunc := getCrudTestInput(f, tl, typeConfigName, fixture)
+				controllerConfig := f.ControllerConfig()
+				controllerConfig.PruneRemovedClusters = true
+				crudTester, targetObject, overrides := initCrudTestWithControllerConfig(f, tl, f.KubeFedSystemNamespace(), typeConfig, testObjectsFunc, true, controllerConfig)
+				if len(crudTester.TestClusters()) < 2 {
+					framework.Skipf("Removing a cluster requires at least 2 clusters")
+				}
+				fedObject := crudTester.CheckCreate(ctx, immediate, targetObject, overrides, nil)
+
+				clusterName := ""
+				for key := range crudTester.TestClusters() {
+					clusterName = key
+					break
+				}
+
+				// Other tests depend on the removed cluster, so it is
+				// joined again once the check completes.
+				client := genericclient.NewForConfigOrDie(f.KubeConfig())
+				cluster := &v1beta1.KubeFedCluster{}
+				if err := client.Get(ctx, cluster, f.KubeFedSystemNamespace(), clusterName); err != nil {
+					tl.Fatalf("Error retrieving cluster %q: %v", clusterName, err)
+				}
+				defer rejoinCluster(ctx, f, tl, client, cluster)
+
+				By(fmt.Sprintf("Removing cluster %q", clusterName))
+				fedObject = crudTester.CheckClusterRemoval(ctx, immediate, fedObject, clusterName, true)
+
+				crudTester.CheckDelete(ctx, immediate, fedObject, false)
+			})
+
+			It("should propagate resources named from labels and rename them when labels change", func() {
+				if !framework.TestContext.InMemoryControllers {
+					framework.Skipf("Label-derived target names require a type config that is only configured for in-memory controllers")
+				}
+
+				const tenantLabel = "crudtester-tenant"
+
+				typeConfig, testObjectsFunc := getCrudTestInput(f, tl, typeConfigName, fixture)
+				tc := typeConfig.(*v1beta1.FederatedTypeConfig).DeepCopy()
+				tc.Spec.TargetNameTemplate = fmt.Sprintf(`{{ index . %q }}-config`, tenantLabel)
+				crudTester, targetObject, overrides := initCrudTest(f, tl, f.KubeFedSystemNamespace(), tc, testObjectsFunc)
+
+				labels := targetObject.GetLabels()
+				if labels == nil {
+					labels = map[string]string{}
+				}
+				labels[tenantLabel] = "tenant-a"
+				targetObject.SetLabels(labels)
+
+				By("Creating a federated resource whose target name is derived from a label")
+				fedObject := crudTester.CheckCreate(ctx, immediate, targetObject, overrides, nil)
+
+				By("Changing the label the target name is derived from")
+				fedObject = crudTester.CheckTargetNameChange(ctx, immediate, fedObject, tenantLabel, "tenant-b")
+
+				crudTester.CheckDelete(ctx, immediate, fedObject, false)
+			})
+
+			It("should report a placement-only cluster in status without propagating to it", func() {
+				typeConfig, testObjectsFunc := getCrudTestInput(f, tl, typeConfigName, fixture)
+				crudTester, targetObject, overrides := initCrudTest(f, tl, f.KubeFedSystemNamespace(), typeConfig, testObjectsFunc)
+
+				clusterName := ""
+				for key := range crudTester.TestClusters() {
+					clusterName = key
+					break
+				}
+
+				By(fmt.Sprintf("Creating a federated resource with placement-only cluster %q", clusterName))
+				fedObject := crudTester.CheckPlacementOnly(ctx, immediate, targetObject, overrides, clusterName)
+
+				crudTester.CheckDelete(ctx, immediate, fedObject, false)
+			})
+
+			It("should pause propagation to a cluster in maintenance", func() {
+				typeConfig, testObjectsFunc := getCrudTestInput(f, tl, typeConfigName, fixture)
+				crudTester, targetObject, overrides := initCrudTest(f, tl, f.KubeFedSystemNamespace(), typeConfig, testObjectsFunc)
+				fedObject := crudTester.CheckCreate(ctx, immediate, targetObject, overrides, nil)
+
+				clusterName := ""
+				for key := range crudTester.TestClusters() {
+					clusterName = key
+					break
+				}
+
+				By(fmt.Sprintf("Updating the federated resource while cluster %q is in maintenance", clusterName))
+				fedObject = crudTester.CheckMaintenance(ctx, immediate, fedObject, clusterName)
+
+				crudTester.CheckDelete(ctx, immediate, fedObject, false)
+			})
+
+			It("should honour the taints of a cluster in placement", func() {
+				typeConfig, testObjectsFunc := getCrudTestInput(f, tl, typeConfigName, fixture)
+				crudTester, targetObject, overrides := initCrudTest(f, tl, f.KubeFedSystemNamespace(), typeConfig, testObjectsFunc)
+				fedObject := crudTester.CheckCreate(ctx, immediate, targetObject, overrides, nil)
+
+				clusterName := ""
+				for key := range crudTester.TestClusters() {
+					clusterName = key
+					break
+				}
+
+				By(fmt.Sprintf("Tainting cluster %q with and without a matching toleration", clusterName))
+				fedObject = crudTester.CheckClusterTaint(ctx, immediate, fedObject, clusterName)
+
+				crudTester.CheckDelete(ctx, immediate, fedObject, false)
+			})
+
+			It("should release managed resources while leaving them in member clusters", func() {
+				typeConfig, testObjectsFunc := getCrudTestInput(f, tl, typeConfigName, fixture)
+				crudTester, targetObject, overrides := initCrudTest(f, tl, f.KubeFedSystemNamespace(), typeConfig, testObjectsFunc)
+				fedObject := crudTester.CheckCreate(ctx, immediate, targetObject, overrides, nil)
+
+				By("Releasing the managed resources and deleting the federated resource")
+				crudTester.CheckRelease(ctx, immediate, fedObject)
+			})
+
+			It("should place a resource in the clusters matching any of multiple selectors", func() {
+				typeConfig, testObjectsFunc := getCrudTestInput(f, tl, typeConfigName, fixture)
+				crudTester, targetObject, overrides := initCrudTest(f, tl, f.KubeFedSystemNamespace(), typeConfig, testObjectsFunc)
+				if len(crudTester.TestClusters()) < 2 {
+					framework.Skipf("Placing by multiple cluster selectors requires at least 2 clusters")
+				}
+				fedObject := crudTester.CheckCreate(ctx, immediate, targetObject, overrides, nil)
+
+				var clusterNames []string
+				for key := range crudTester.TestClusters() {
+					clusterNames = append(clusterNames, key)
+				}
+
+				By(fmt.Sprintf("Selecting clusters %q and %q by different cluster selectors", clusterNames[0], clusterNames[1]))
+				fedObject = crudTester.CheckClusterSelectors(ctx, immediate, fedObject, clusterNames[0], clusterNames[1])
+
+				crudTester.CheckDelete(ctx, immediate, fedObject, false)
+			})
+
+			It("should retain a cluster that is no longer selected for the stickiness of placement", func() {
+				typeConfig, testObjectsFunc := getCrudTestInput(f, tl, typeConfigName, fixture)
+				crudTester, targetObject, overrides := initCrudTest(f, tl, f.KubeFedSystemNamespace(), typeConfig, testObjectsFunc)
+				fedObject := crudTester.CheckCreate(ctx, immediate, targetObject, overrides, nil)
+
+				clusterName := ""
+				for key := range crudTester.TestClusters() {
+					clusterName = key
+					break
+				}
+				By(fmt.Sprintf("Deselecting cluster %q from a placement with stickiness", clusterName))
+				fedObject = crudTester.CheckPlacementStickiness(ctx, immediate, fedObject, clusterName, 10*time.Second)
+
+				crudTester.CheckDelete(ctx, immediate, fedObject, false)
+			})
+
+			It("should evaluate templated overrides against the parameters of each cluster", func() {
+				typeConfig, testObjectsFunc := getCrudTestInput(f, tl, typeConfigName, fixture)
+				crudTester, targetObject, overrides := initCrudTest(f, tl, f.KubeFedSystemNamespace(), typeConfig, testObjectsFunc)
+				if len(crudTester.TestClusters()) < 2 {
+					framework.Skipf("Evaluating templated overrides for different clusters requires at least 2 clusters")
+				}
+				fedObject := crudTester.CheckCreate(ctx, immediate, targetObject, overrides, nil)
+
+				var clusterNames []string
+				for key := range crudTester.TestClusters() {
+					clusterNames = append(clusterNames, key)
+				}
+
+				By(fmt.Sprintf("Overriding with a template evaluated for clusters %q and %q", clusterNames[0], clusterNames[1]))
+				fedObject = crudTester.CheckTemplatedOverrides(ctx, immediate, fedObject, clusterNames[0], clusterNames[1])
+
+				crudTester.CheckDelete(ctx, immediate, fedObject, false)
+			})
+
+			It("should report out-of-band modifications of a managed resource as drift", func() {
+				if !framework.TestContext.InMemoryControllers {
+					framework.Skipf("Drift detection requires a type config that is only configured for in-memory controllers")
+				}
+
+				typeConfig, testObjectsFunc := getCrudTestInput(f, tl, typeConfigName, fixture)
+				tc := typeConfig.(*v1beta1.FederatedTypeConfig).DeepCopy()
+				tc.Spec.DriftDetectionInterval = &metav1.Duration{Duration: time.Second}
+				crudTester, targetObject, overrides := initCrudTest(f, tl, f.KubeFedSystemNamespace(), tc, testObjectsFunc)
+				fedObject := crudTester.CheckCreate(ctx, immediate, targetObject, overrides, nil)
+
+				clusterName := ""
+				for key := range crudTester.TestClusters() {
+					clusterName = key
+					break
+				}
+
+				By(fmt.Sprintf("Modifying the managed resource in cluster %q", clusterName))
+				fedObject = crudTester.CheckDriftDetection(ctx, immediate, fedObject, clusterName)
+
+				crudTester.CheckDelete(ctx, immediate, fedObject, false)
+			})
+
+			It("should preserve labels and annotations added to a managed resource in a member cluster", func() {
+				if !framework.TestContext.InMemoryControllers {
+					framework.Skipf("Merging of metadata requires a type config that is only configured for in-memory controllers")
+				}
+
+				typeConfig, testObjectsFunc := getCrudTestInput(f, tl, typeConfigName, fixture)
+				tc := typeConfig.(*v1beta1.FederatedTypeConfig).DeepCopy()
+				tc.Spec.FeatureGates = []v1beta1.FeatureGatesConfig{
+					{Name: string(features.MetadataMerge), Configuration: v1beta1.ConfigurationEnabled},
+				}
+				crudTester, targetObject, overrides := initCrudTest(f, tl, f.KubeFedSystemNamespace(), tc, testObjectsFunc)
+				fedObject := crudTester.CheckCreate(ctx, immediate, targetObject, overrides, nil)
+
+				clusterName := ""
+				for key := range crudTester.TestClusters() {
+					clusterName = key
+					break
+				}
+
+				By(fmt.Sprintf("Adding metadata to the managed resource in cluster %q", clusterName))
+				fedObject = crudTester.CheckClusterMetadataMerge(ctx, immediate, fedObject, clusterName)
+
+				crudTester.CheckDelete(ctx, immediate, fedObject, false)
+			})
+
+			It("should drain a managed resource before removing it from a cluster no longer selected", func() {
+				if !framework.TestContext.InMemoryControllers {
+					framework.Skipf("Draining requires a type config that is only configured for in-memory controllers")
+				}
+
+				typeConfig, testObjectsFunc := getCrudTestInput(f, tl, typeConfigName, fixture)
+				tc := typeConfig.(*v1beta1.FederatedTypeConfig).DeepCopy()
+				tc.Spec.RemovalStrategy = &v1beta1.RemovalStrategy{
+					PreDeletePatch: &apiextv1.JSON{Raw: []byte(`{"metadata": {"annotations": {"crudtester-draining": "true"}}}`)},
+					DrainDuration:  metav1.Duration{Duration: 5 * time.Second},
+				}
+				crudTester, targetObject, overrides := initCrudTest(f, tl, f.KubeFedSystemNamespace(), tc, testObjectsFunc)
+				if len(crudTester.TestClusters()) < 2 {
+					framework.Skipf("Draining on placement shrink requires at least 2 clusters")
+				}
+				fedObject := crudTester.CheckCreate(ctx, immediate, targetObject, overrides, nil)
+
+				By("Removing a cluster from placement")
+				crudTester.CheckPlacementChange(ctx, immediate, fedObject)
+
+				crudTester.CheckDelete(ctx, immediate, fedObject, false)
+			})
+
+			It("should report propagation as successful once the minimum number of clusters are healthy", func() {
+				typeConfig, testObjectsFunc := getCrudTestInput(f, tl, typeConfigName, fixture)
+				crudTester, targetObject, overrides := initCrudTest(f, tl, f.KubeFedSystemNamespace(), typeConfig, testObjectsFunc)
+				if len(crudTester.TestClusters()) < 2 {
+					framework.Skipf("Requiring a minimum number of healthy clusters requires at least 2 clusters")
+				}
+				fedObject := crudTester.CheckCreate(ctx, immediate, targetObject, overrides, nil)
+
+				clusterName := ""
+				for key := range crudTester.TestClusters() {
+					clusterName = key
+					break
+				}
+
+				By(fmt.Sprintf("Requiring all clusters but lagging cluster %q to be healthy", clusterName))
+				fedObject = crudTester.CheckMinHealthyClusters(ctx, immediate, fedObject, clusterName)
+
+				crudTester.CheckDelete(ctx, immediate, fedObject, false)
+			})
+
+			It("should retrieve overrides from the API with their structure intact", func() {
+				typeConfig, testObjectsFunc := getCrudTestInput(f, tl, typeConfigName, fixture)
+				crudTester, targetObject, overrides := initCrudTest(f, tl, f.KubeFedSystemNamespace(), typeConfig, testObjectsFunc)
+				fedObject := crudTester.CheckCreate(ctx, immediate, targetObject, overrides, nil)
+
+				roundTripOverrides := utils.OverridesMap{}
+				for clusterName := range crudTester.TestClusters() {
+					roundTripOverrides[clusterName] = utils.ClusterOverrides{
+						{
+							Op:   "add",
+							Path: "/metadata/annotations",
+							Value: map[string]interface{}{
+								"crudtester-round-trip": clusterName,
+							},
+						},
+						{
+							Op:    "add",
+							Path:  "/metadata/labels/crudtester-round-trip",
+							Value: "true",
+						},
+					}
+				}
+
+				By("Setting overrides with nested values and ops")
+				fedObject = crudTester.CheckOverridesRoundTrip(ctx, immediate, fedObject, roundTripOverrides)
+
+				crudTester.CheckDelete(ctx, immediate, fedObject, false)
+			})
+
+			It("should revert a field set by an override once the override is removed", func() {
+				typeConfig, testObjectsFunc := getCrudTestInput(f, tl, typeConfigName, fixture)
+				crudTester, targetObject, overrides := initCrudTest(f, tl, f.KubeFedSystemNamespace(), typeConfig, testObjectsFunc)
+				fedObject := crudTester.CheckCreate(ctx, immediate, targetObject, overrides, nil)
+
+				clusterName := ""
+				for key := range crudTester.TestClusters() {
+					clusterName = key
+					break
+				}
+
+				By(fmt.Sprintf("Adding and removing an override for cluster %q", clusterName))
+				fedObject = crudTester.CheckOverrideRemoval(ctx, immediate, fedObject, clusterName)
+
+				crudTester.CheckDelete(ctx, immediate, fedObject, false)
+			})
+
+			It("should warn of overrides for clusters not selected by placement", func() {
+				typeConfig, testObjectsFunc := getCrudTestInput(f, tl, typeConfigName, fixture)
+				crudTester, targetObject, overrides := initCrudTest(f, tl, f.KubeFedSystemNamespace(), typeConfig, testObjectsFunc)
+				fedObject := crudTester.CheckCreate(ctx, immediate, targetObject, overrides, nil)
+
+				By("Adding and removing an override for an unplaced cluster")
+				fedObject = crudTester.CheckUnplacedOverrides(ctx, immediate, fedObject)
+
+				crudTester.CheckDelete(ctx, immediate, fedObject, false)
+			})
+
+			It("should retain managed resources until deletion is confirmed", func() {
+				typeConfig, testObjectsFunc := getCrudTestInput(f, tl, typeConfigName, fixture)
+				crudTester, targetObject, overrides := initCrudTest(f, tl, f.KubeFedSystemNamespace(), typeConfig, testObjectsFunc)
+				fedObject := crudTester.CheckCreate(ctx, immediate, targetObject, overrides, nil)
+
+				By("Deleting the federated resource before and after confirming deletion")
+				crudTester.CheckDeletionBlocked(ctx, immediate, fedObject)
+			})
+
+			It("should retain managed resources when recreated within the deletion grace period", func() {
+				typeConfig, testObjectsFunc := getCrudTestInput(f, tl, typeConfigName, fixture)
+				crudTester, targetObject, overrides := initCrudTest(f, tl, f.KubeFedSystemNamespace(), typeConfig, testObjectsFunc)
+				fedObject := crudTester.CheckCreate(ctx, immediate, targetObject, overrides, nil)
+
+				By("Deleting and recreating the federated resource within its deletion grace period")
+				fedObject = crudTester.CheckDeletionGracePeriod(ctx, immediate, fedObject, 10*time.Minute)
+
+				crudTester.CheckDelete(ctx, immediate, fedObject, false)
+			})
+
 			It("should have the managed label removed if not managed", func() {
 				typeConfig, testObjectsFunc := getCrudTestInput(f, tl, typeConfigName, fixture)
 				crudTester, targetObject, _ := initCrudTest(f, tl, f.KubeFedSystemNamespace(), typeConfig, testObjectsFunc)
@@ -326,8 +883,18 @@ func initCrudTestWithPropagation(f framework.KubeFedFramework, tl common.TestLog
 	typeConfig typeconfig.Interface, testObjectsFunc testObjectsAccessor,
 	ensureNamespacePropagation bool) (
 	*common.FederatedTypeCrudTester, *unstructured.Unstructured, []interface{}) {
+	return initCrudTestWithControllerConfig(f, tl, clustersNamespace, typeConfig, testObjectsFunc, ensureNamespacePropagation, nil)
+}
+
+// initCrudTestWithControllerConfig initializes crud testing with an
+// in-memory sync controller configured with the given controller
+// configuration, or the configuration of the framework if nil.
+func initCrudTestWithControllerConfig(f framework.KubeFedFramework, tl common.TestLogger, clustersNamespace string,
+	typeConfig typeconfig.Interface, testObjectsFunc testObjectsAccessor,
+	ensureNamespacePropagation bool, controllerConfig *utils.ControllerConfig) (
+	*common.FederatedTypeCrudTester, *unstructured.Unstructured, []interface{}) {
 	// Initialize in-memory controllers if configuration requires
-	fixture := f.SetUpSyncControllerFixture(typeConfig)
+	fixture := f.SetUpSyncControllerFixtureWithConfig(typeConfig, controllerConfig)
 	f.RegisterFixture(fixture)
 
 	if typeConfig.GetNamespaced() && ensureNamespacePropagation {
@@ -344,7 +911,7 @@ func initCrudTestWithPropagation(f framework.KubeFedFramework, tl common.TestLog
 	targetAPIResource := typeConfig.GetTargetType()
 
 	testClusters := f.ClusterDynamicClients(&targetAPIResource, userAgent)
-	crudTester, err := common.NewFederatedTypeCrudTester(tl, typeConfig, kubeConfig, testClusters, clustersNamespace, framework.PollInterval, framework.TestContext.SingleCallTimeout)
+	crudTester, err := common.NewFederatedTypeCrudTester(tl, typeConfig, kubeConfig, framework.TestClientRateLimits, testClusters, clustersNamespace, framework.PollInterval, framework.TestContext.SingleCallTimeout)
 	if err != nil {
 		tl.Fatalf("Error creating crudtester for %q: %v", federatedKind, err)
 	}
@@ -367,3 +934,84 @@ func initCrudTestWithPropagation(f framework.KubeFedFramework, tl common.TestLog
 
 	return crudTester, targetObject, overrides
 }
+
+// labelTestNamespace labels the test namespace in all member clusters
+// but the excluded one with the given label set to "true" via the
+// federated namespace, and waits for the label to be propagated.
+func labelTestNamespace(ctx context.Context, immediate bool, f framework.KubeFedFramework, tl common.TestLogger, label, excludedClusterName string) {
+	fedNamespace := f.EnsureTestFederatedNamespace(true)
+	qualifiedName := utils.NewQualifiedName(fedNamespace)
+
+	err := unstructured.SetNestedStringMap(fedNamespace.Object, map[string]string{label: "true"}, utils.SpecField, utils.TemplateField, "metadata", "labels")
+	if err != nil {
+		tl.Fatalf("Error setting the labels of %s %q: %v", fedNamespace.GetKind(), qualifiedName, err)
+	}
+	overrides := utils.OverridesMap{
+		excludedClusterName: utils.ClusterOverrides{
+			{Op: "remove", Path: fmt.Sprintf("/metadata/labels/%s", label)},
+		},
+	}
+	if err := utils.SetOverrides(fedNamespace, overrides); err != nil {
+		tl.Fatalf("Error setting the overrides of %s %q: %v", fedNamespace.GetKind(), qualifiedName, err)
+	}
+	client := genericclient.NewForConfigOrDie(f.KubeConfig())
+	if err := client.Update(ctx, fedNamespace); err != nil {
+		tl.Fatalf("Error updating %s %q: %v", fedNamespace.GetKind(), qualifiedName, err)
+	}
+
+	for clusterName, kubeClient := range f.ClusterKubeClients("test-namespace-label") {
+		expected := clusterName != excludedClusterName
+		err := wait.PollUntilContextTimeout(ctx, framework.PollInterval, framework.TestContext.SingleCallTimeout, immediate, func(ctx context.Context) (bool, error) {
+			namespace, err := kubeClient.CoreV1().Namespaces().Get(ctx, qualifiedName.Name, metav1.GetOptions{})
+			if err != nil {
+				tl.Logf("Error retrieving namespace %q in cluster %q: %v", qualifiedName.Name, clusterName, err)
+				return false, nil
+			}
+			return (namespace.Labels[label] == "true") == expected, nil
+		})
+		if err != nil {
+			tl.Fatalf("Timed out waiting for the label %q of namespace %q in cluster %q to be set=%t: %v", label, qualifiedName.Name, clusterName, expected, err)
+		}
+	}
+}
+
+// createTestNamespaceInClusters creates the test namespace in the
+// member clusters it does not already exist in, and returns the
+// clients of those clusters.
+func createTestNamespaceInClusters(f framework.KubeFedFramework, tl common.TestLogger) map[string]kubeclientset.Interface {
+	createdNamespaces := make(map[string]kubeclientset.Interface)
+	for clusterName, kubeClient := range f.ClusterKubeClients("test-namespace-creation") {
+		namespace := &apiv1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: f.TestNamespaceName(),
+			},
+		}
+		_, err := kubeClient.CoreV1().Namespaces().Create(context.Background(), namespace, metav1.CreateOptions{})
+		switch {
+		case apierrors.IsAlreadyExists(err):
+		case err != nil:
+			tl.Fatalf("Error creating namespace %q in cluster %q: %v", namespace.Name, clusterName, err)
+		default:
+			createdNamespaces[clusterName] = kubeClient
+		}
+	}
+	return createdNamespaces
+}
+
+// rejoinCluster recreates the given removed KubeFedCluster and waits
+// for all clusters to be ready.
+func rejoinCluster(ctx context.Context, f framework.KubeFedFramework, tl common.TestLogger, client genericclient.Client, removedCluster *v1beta1.KubeFedCluster) {
+	cluster := &v1beta1.KubeFedCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        removedCluster.Name,
+			Namespace:   removedCluster.Namespace,
+			Labels:      removedCluster.Labels,
+			Annotations: removedCluster.Annotations,
+		},
+		Spec: removedCluster.Spec,
+	}
+	if err := client.Create(ctx, cluster); err != nil {
+		tl.Fatalf("Error recreating cluster %q: %v", cluster.Name, err)
+	}
+	framework.WaitForClusterReadiness(tl, client, f.KubeFedSystemNamespace(), framework.PollInterval, framework.TestContext.SingleCallTimeout)
+}
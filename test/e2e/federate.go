@@ -21,7 +21,6 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
-	"reflect"
 
 	"github.com/pborman/uuid"
 	"github.com/pkg/errors"
@@ -47,6 +46,14 @@ type testResources struct {
 	typeConfig     typeconfig.Interface
 }
 
+// templateIgnorePaths are the dot-separated paths of the fields of
+// target resources, keyed by target kind, that are excluded when
+// comparing a target resource with the template of its federated
+// resource. Fields whose values are not deterministic, e.g. generated
+// tokens, would otherwise fail the comparison spuriously. No fields
+// are excluded by default.
+var templateIgnorePaths = map[string][]string{}
+
 var _ = ginkgo.Describe("Federate ", func() {
 	f := framework.NewKubeFedFramework("federate-resource")
 	tl := framework.NewE2ELogger()
@@ -112,13 +119,13 @@ var _ = ginkgo.Describe("Federate ", func() {
 
 			var artifactsList []*federate.Artifacts
 			artifactsList = append(artifactsList, artifacts)
-			err = federate.CreateResources(nil, kubeConfig, artifactsList, typeNamespace, false, false)
+			err = federate.CreateResources(nil, kubeConfig, artifactsList, typeNamespace, false, federate.CreateOptions{})
 			if err != nil {
 				tl.Fatalf("Error creating %s %q: %v", fedKind, testResourceName, err)
 			}
 
 			ginkgo.By("Comparing the test resource and the templates of target resource for equality")
-			validateTemplateEquality(tl, fedResourceFromAPI(tl, typeConfig, kubeConfig, testResourceName), createdTargetResource, kind, fedKind)
+			validateTemplateEquality(tl, fedResourceFromAPI(tl, typeConfig, kubeConfig, testResourceName), createdTargetResource, kind, fedKind, templateIgnorePaths[kind]...)
 		})
 	}
 
@@ -146,6 +153,18 @@ var _ = ginkgo.Describe("Federate ", func() {
 		namespaceTestResource := targetNamespaceTestResources(tl, client, kubeConfig, systemNamespace, testNamespace, namespaceTypeName)
 		createdTargetResources = append(createdTargetResources, namespaceTestResource)
 
+		// A resource that opted out of federation should not result
+		// in a federated resource.
+		skippedTypeConfig := targetTestResources[0].typeConfig
+		skippedResource := targetTestResources[0].targetResource.DeepCopy()
+		skippedResource.SetName("")
+		skippedResource.SetGenerateName("skip-federation-")
+		skippedResource.SetLabels(map[string]string{federate.SkipFederationKey: federate.SkipFederationValue})
+		skippedResource, err = getTargetClient(tl, skippedTypeConfig, kubeConfig).Resources(testNamespace).Create(context.Background(), skippedResource, metav1.CreateOptions{})
+		if err != nil {
+			tl.Fatalf("Error creating %s that opted out of federation: %v", skippedTypeConfig.GetTargetType().Kind, err)
+		}
+
 		namespaceTypeConfig := namespaceTestResource.typeConfig
 		namespaceKind := namespaceTypeConfig.GetTargetType().Kind
 		namespaceResourceName := utils.NewQualifiedName(namespaceTestResource.targetResource)
@@ -162,19 +181,26 @@ var _ = ginkgo.Describe("Federate ", func() {
 
 		skipAPIResourceNames := []string{"pods", "replicasets.extensions"}
 		// Artifacts for the contained resources
-		containedArtifactsList, err := federate.GetContainedArtifactsList(kubeConfig, testNamespace, systemNamespace, skipAPIResourceNames, false, false)
+		containedArtifactsList, err := federate.GetContainedArtifactsList(kubeConfig, testNamespace, systemNamespace, skipAPIResourceNames, false, false, false)
 		if err != nil {
 			tl.Fatalf("Error getting contained artifacts: %v", err)
 		}
 		artifactsList = append(artifactsList, containedArtifactsList...)
 
-		err = federate.CreateResources(nil, kubeConfig, artifactsList, systemNamespace, false, false)
+		err = federate.CreateResources(nil, kubeConfig, artifactsList, systemNamespace, false, federate.CreateOptions{})
 		if err != nil {
 			tl.Fatalf("Error creating resources: %v", err)
 		}
 
 		ginkgo.By("Comparing the test resources with the templates of corresponding federated resources for equality")
 		validateResourcesEqualityFromAPI(tl, createdTargetResources, kubeConfig)
+
+		ginkgo.By("Checking that a resource that opted out of federation was not federated")
+		skippedResourceName := utils.NewQualifiedName(skippedResource)
+		_, err = getFedClient(tl, skippedTypeConfig, kubeConfig).Resources(testNamespace).Get(context.Background(), skippedResourceName.Name, metav1.GetOptions{})
+		if !apierrors.IsNotFound(err) {
+			tl.Fatalf("Expected %s %q not to be federated: %v", skippedTypeConfig.GetTargetType().Kind, skippedResourceName, err)
+		}
 	})
 
 	ginkgo.It("input yaml from a file, should emit equivalent federated resources", func() {
@@ -232,7 +258,8 @@ func validateResourcesEquality(tl common.TestLogger, targetResources []testResou
 		targetResource := t.targetResource
 		for _, federatedResource := range federatedResources {
 			if targetResource.GetName() == federatedResource.GetName() {
-				validateTemplateEquality(tl, federatedResource, targetResource, t.typeConfig.GetTargetType().Kind, t.typeConfig.GetFederatedType().Kind)
+				kind := t.typeConfig.GetTargetType().Kind
+				validateTemplateEquality(tl, federatedResource, targetResource, kind, t.typeConfig.GetFederatedType().Kind, templateIgnorePaths[kind]...)
 				count++
 			}
 		}
@@ -252,31 +279,27 @@ func validateResourcesEqualityFromAPI(tl common.TestLogger, testResources []test
 			testResourceName.Namespace = testResourceName.Name
 		}
 		fedResource := fedResourceFromAPI(tl, typeConfig, kubeConfig, testResourceName)
-		validateTemplateEquality(tl, fedResource, targetResource, kind, typeConfig.GetFederatedType().Kind)
+		validateTemplateEquality(tl, fedResource, targetResource, kind, typeConfig.GetFederatedType().Kind, templateIgnorePaths[kind]...)
 	}
 }
 
-func validateTemplateEquality(tl common.TestLogger, fedResource, targetResource *unstructured.Unstructured, kind, fedKind string) {
+// validateTemplateEquality verifies that the template of the given
+// federated resource is equal to the given target resource, ignoring
+// the fields at the given dot-separated paths.
+func validateTemplateEquality(tl common.TestLogger, fedResource, targetResource *unstructured.Unstructured, kind, fedKind string, ignorePaths ...string) {
 	qualifiedName := utils.NewQualifiedName(fedResource)
-	templateMap, ok, err := unstructured.NestedFieldCopy(fedResource.Object, utils.SpecField, utils.TemplateField)
-	if err != nil || !ok {
-		tl.Fatalf("Error retrieving template from %s %q", fedKind, qualifiedName)
-	}
-
-	expectedResource := &unstructured.Unstructured{}
-	expectedResource.Object = templateMap.(map[string]interface{})
-	if err = federate.RemoveUnwantedFields(expectedResource); err != nil {
-		tl.Fatalf("Failed to remove unwanted fields from expected resource: %v", err)
-	}
-	if err = federate.RemoveUnwantedFields(targetResource); err != nil {
-		tl.Fatalf("Failed to remove unwanted fields from target resource: %v", err)
-	}
+	targetResource = targetResource.DeepCopy()
 	if kind == utils.NamespaceKind {
 		unstructured.RemoveNestedField(targetResource.Object, "spec", "finalizers")
 	}
 
-	if !reflect.DeepEqual(expectedResource, targetResource) {
-		tl.Fatalf("Federated object template and target object don't match for %s %q; expected: %v, target: %v", fedKind, qualifiedName, expectedResource, targetResource)
+	equal, err := federate.TemplateEqual(fedResource, targetResource, ignorePaths...)
+	if err != nil {
+		tl.Fatalf("Error comparing the template of %s %q with its target resource: %v", fedKind, qualifiedName, err)
+	}
+	if !equal {
+		template, _, _ := unstructured.NestedMap(fedResource.Object, utils.SpecField, utils.TemplateField)
+		tl.Fatalf("Federated object template and target object don't match for %s %q; template: %v, target: %v", fedKind, qualifiedName, template, targetResource)
 	}
 }
 
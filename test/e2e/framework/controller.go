@@ -44,7 +44,7 @@ func NewSyncControllerFixture(ctx context.Context, immediate bool, tl common.Tes
 	f := &ControllerFixture{
 		stopChan: make(chan struct{}),
 	}
-	err := sync.StartKubeFedSyncController(ctx, immediate, controllerConfig, f.stopChan, typeConfig, namespacePlacement)
+	_, err := sync.StartKubeFedSyncController(ctx, immediate, controllerConfig, f.stopChan, typeConfig, namespacePlacement)
 	if err != nil {
 		tl.Fatalf("Error starting sync controller: %v", err)
 	}
@@ -70,7 +70,7 @@ func NewFederatedTypeConfigControllerFixture(tl common.TestLogger, config *utils
 		stopChan: make(chan struct{}),
 	}
 
-	err := federatedtypeconfig.StartController(config, f.stopChan)
+	_, err := federatedtypeconfig.StartController(config, f.stopChan)
 	if err != nil {
 		tl.Fatalf("Error starting federatedtypeconfig controller: %v", err)
 	}
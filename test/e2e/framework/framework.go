@@ -67,8 +67,9 @@ type KubeFedFrameworkImpl interface {
 	TestNamespaceName() string
 
 	// Internal method that accepts the namespace placement api
-	// resource to support namespaced controllers.
-	setUpSyncControllerFixture(typeConfig typeconfig.Interface, namespacePlacement *metav1.APIResource) TestFixture
+	// resource to support namespaced controllers. The controller
+	// configuration defaults to ControllerConfig() if nil.
+	setUpSyncControllerFixture(typeConfig typeconfig.Interface, namespacePlacement *metav1.APIResource, controllerConfig *utils.ControllerConfig) TestFixture
 }
 
 // KubeFedFramework provides an interface to a test control plane so
@@ -85,6 +86,10 @@ type KubeFedFramework interface {
 	// controller for tests that require it.
 	SetUpSyncControllerFixture(typeConfig typeconfig.Interface) TestFixture
 
+	// Setup a sync controller with the given configuration if
+	// necessary and return the fixture.
+	SetUpSyncControllerFixtureWithConfig(typeConfig typeconfig.Interface, controllerConfig *utils.ControllerConfig) TestFixture
+
 	// Ensure propagation of the test namespace to member clusters
 	EnsureTestNamespacePropagation()
 
@@ -197,14 +202,18 @@ func (f *frameworkWrapper) TestNamespaceName() string {
 // setUpSyncControllerFixture is not intended to be called on the
 // wrapper.  It's only implemented by the concrete frameworks to avoid
 // having callers pass in the namespacePlacement arg.
-func (f *frameworkWrapper) setUpSyncControllerFixture(typeConfig typeconfig.Interface, namespacePlacement *metav1.APIResource) TestFixture {
+func (f *frameworkWrapper) setUpSyncControllerFixture(typeConfig typeconfig.Interface, namespacePlacement *metav1.APIResource, controllerConfig *utils.ControllerConfig) TestFixture {
 	return nil
 }
 
 func (f *frameworkWrapper) SetUpSyncControllerFixture(typeConfig typeconfig.Interface) TestFixture {
+	return f.SetUpSyncControllerFixtureWithConfig(typeConfig, nil)
+}
+
+func (f *frameworkWrapper) SetUpSyncControllerFixtureWithConfig(typeConfig typeconfig.Interface, controllerConfig *utils.ControllerConfig) TestFixture {
 	namespaceTypeConfig := f.namespaceTypeConfigOrDie()
 	fedNamespaceAPIResource := namespaceTypeConfig.GetFederatedType()
-	return f.framework().setUpSyncControllerFixture(typeConfig, &fedNamespaceAPIResource)
+	return f.framework().setUpSyncControllerFixture(typeConfig, &fedNamespaceAPIResource, controllerConfig)
 }
 
 func (f *frameworkWrapper) RegisterFixture(fixture TestFixture) {
@@ -225,7 +234,7 @@ func (f *frameworkWrapper) EnsureTestNamespacePropagation() {
 
 	// Start the namespace sync controller to propagate the namespace
 	namespaceTypeConfig := f.namespaceTypeConfigOrDie()
-	fixture := f.framework().setUpSyncControllerFixture(namespaceTypeConfig, nil)
+	fixture := f.framework().setUpSyncControllerFixture(namespaceTypeConfig, nil, nil)
 	f.RegisterFixture(fixture)
 }
 
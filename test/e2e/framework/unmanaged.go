@@ -36,6 +36,7 @@ import (
 	"sigs.k8s.io/kubefed/pkg/apis/core/typeconfig"
 	fedv1b1 "sigs.k8s.io/kubefed/pkg/apis/core/v1beta1"
 	genericclient "sigs.k8s.io/kubefed/pkg/client/generic"
+	"sigs.k8s.io/kubefed/pkg/controller/federatedtypeconfig"
 	"sigs.k8s.io/kubefed/pkg/controller/utils"
 	"sigs.k8s.io/kubefed/pkg/features"
 	"sigs.k8s.io/kubefed/test/common"
@@ -52,6 +53,10 @@ const (
 	testConfigBurst = 100
 )
 
+// TestClientRateLimits are the rate limits of the clients of e2e
+// tests.
+var TestClientRateLimits = utils.ClientRateLimits{QPS: testConfigQPS, Burst: testConfigBurst}
+
 var (
 	clusterControllerFixture *ControllerFixture
 	// The client and set of deleted namespaces is used on suite
@@ -233,11 +238,21 @@ func (f *UnmanagedFramework) ClusterDynamicClients(apiResource *metav1.APIResour
 		if err != nil {
 			Failf("Error creating a resource client in cluster %q for kind %q: %v", clusterName, apiResource.Kind, err)
 		}
+		namespaceClient, err := utils.NewResourceClient(clusterConfig.Config, &common.NamespaceAPIResource)
+		if err != nil {
+			Failf("Error creating a resource client in cluster %q for namespaces: %v", clusterName, err)
+		}
+		endpointSliceClient, err := utils.NewResourceClient(clusterConfig.Config, &common.EndpointSliceAPIResource)
+		if err != nil {
+			Failf("Error creating a resource client in cluster %q for EndpointSlices: %v", clusterName, err)
+		}
 		// Check if this cluster is the same name as the host cluster name to
 		// make it the primary cluster.
 		testClusters[clusterName] = common.TestCluster{
-			TestClusterConfig: clusterConfig,
-			Client:            client,
+			TestClusterConfig:   clusterConfig,
+			Client:              client,
+			NamespaceClient:     namespaceClient,
+			EndpointSliceClient: endpointSliceClient,
 		}
 	}
 	return testClusters
@@ -315,12 +330,19 @@ func (f *UnmanagedFramework) inMemoryTargetNamespace() string {
 	return metav1.NamespaceAll
 }
 
-func (f *UnmanagedFramework) setUpSyncControllerFixture(typeConfig typeconfig.Interface, namespacePlacement *metav1.APIResource) TestFixture {
+func (f *UnmanagedFramework) setUpSyncControllerFixture(typeConfig typeconfig.Interface, namespacePlacement *metav1.APIResource, controllerConfig *utils.ControllerConfig) TestFixture {
 	// Hybrid setup where just the sync controller is run, and we do not rely on
 	// the already deployed (unmanaged) controller manager. Only do this if
 	// in-memory-controllers is true.
 	if TestContext.InMemoryControllers {
-		controllerConfig := f.ControllerConfig()
+		if controllerConfig == nil {
+			controllerConfig = f.ControllerConfig()
+		}
+		// Like the federated type config controller, settings of
+		// the type take precedence.
+		if tc, ok := typeConfig.(*fedv1b1.FederatedTypeConfig); ok {
+			controllerConfig = federatedtypeconfig.ControllerConfigForType(controllerConfig, tc)
+		}
 		// Namespaces are cluster scoped so all namespaces must be targeted
 		if typeConfig.GetTargetType().Kind == utils.NamespaceKind {
 			controllerConfig.TargetNamespace = metav1.NamespaceAll
@@ -395,8 +417,7 @@ func loadConfig(configPath, context string) (*restclient.Config, *clientcmdapi.C
 	if err != nil {
 		return nil, nil, errors.Errorf("error creating default client config: %v", err.Error())
 	}
-	cfg.QPS = testConfigQPS
-	cfg.Burst = testConfigBurst
+	TestClientRateLimits.Apply(cfg)
 	return cfg, c, nil
 }
 
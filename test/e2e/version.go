@@ -153,6 +153,12 @@ func (r *testVersionedResource) TemplateVersion() (string, error) {
 func (r *testVersionedResource) OverrideVersion() (string, error) {
 	return r.overrideVersion, nil
 }
+func (r *testVersionedResource) ClusterOverrideVersion(string) (string, error) {
+	return "", nil
+}
+func (r *testVersionedResource) HasUnversionedOverrides() (bool, error) {
+	return false, nil
+}
 
 func newTestVersionAdapter(kubeClient kubeclientset.Interface, namespaced bool) testVersionAdapter {
 	adapter := version.NewVersionAdapter(namespaced)